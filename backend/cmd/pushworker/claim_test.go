@@ -0,0 +1,152 @@
+package main
+
+import (
+	"context"
+	"crypto/ecdh"
+	"crypto/rand"
+	"encoding/base64"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	webpush "github.com/SherClockHolmes/webpush-go"
+	"github.com/aman1117/backend/internal/config"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/internal/services"
+	"github.com/aman1117/backend/internal/testutil"
+	"github.com/aman1117/backend/pkg/models"
+	"go.uber.org/zap"
+	"golang.org/x/time/rate"
+)
+
+// fakePushService counts pushes and answers each with status
+type fakePushService struct {
+	*httptest.Server
+	pushes atomic.Int32
+	status atomic.Int32
+}
+
+func newFakePushService(t *testing.T) *fakePushService {
+	t.Helper()
+	s := &fakePushService{}
+	s.status.Store(http.StatusCreated)
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.pushes.Add(1)
+		w.WriteHeader(int(s.status.Load()))
+	}))
+	t.Cleanup(s.Close)
+	return s
+}
+
+// newTestSubscription stores a subscription with real browser keys so payloads encrypt
+func newTestSubscription(t *testing.T, repo *repository.PushRepository, userID uint, endpoint string) *models.PushSubscription {
+	t.Helper()
+	key, err := ecdh.P256().GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate subscription key: %v", err)
+	}
+	auth := make([]byte, 16)
+	rand.Read(auth)
+
+	sub := &models.PushSubscription{
+		UserID:   userID,
+		Endpoint: endpoint,
+		P256dh:   base64.RawURLEncoding.EncodeToString(key.PublicKey().Bytes()),
+		Auth:     base64.RawURLEncoding.EncodeToString(auth),
+		Status:   models.PushSubscriptionStatusActive,
+	}
+	if err := repo.UpsertSubscription(sub); err != nil {
+		t.Fatalf("store subscription: %v", err)
+	}
+	return sub
+}
+
+// newSendingWorker builds a worker that can send pushes but has no Service Bus connection,
+// standing in for one worker process
+func newSendingWorker(t *testing.T, repo *repository.PushRepository) *Worker {
+	t.Helper()
+	private, public, err := webpush.GenerateVAPIDKeys()
+	if err != nil {
+		t.Fatalf("generate VAPID keys: %v", err)
+	}
+	cfg := &config.Config{}
+	cfg.WebPush.VapidPublicKey = public
+	cfg.WebPush.VapidPrivateKey = private
+	cfg.WebPush.VapidSubject = "mailto:test@example.com"
+	return &Worker{
+		cfg:         cfg,
+		pushRepo:    repo,
+		rateLimiter: rate.NewLimiter(rate.Inf, 0),
+		log:         zap.NewNop().Sugar(),
+	}
+}
+
+func TestClaimSendPreventsDuplicateAfterCrash(t *testing.T) {
+	repo := repository.NewPushRepository(testutil.OpenDB(t))
+	push := newFakePushService(t)
+	ctx := context.Background()
+	log := zap.NewNop().Sugar()
+
+	const userID uint = 7
+	sub := newTestSubscription(t, repo, userID, push.URL+"/crash")
+	msg := &services.PushMessage{MessageID: "crash-msg", UserID: userID, NotificationType: "like", Title: "New like"}
+
+	// First process: the push goes out, then the worker dies before its delivery
+	// logs are flushed, so the only record of the send is the claim
+	var lostLogs []*models.PushDeliveryLog
+	done, _ := newSendingWorker(t, repo).sendToSubscription(ctx, msg, sub, &lostLogs, log)
+	if !done || push.pushes.Load() != 1 {
+		t.Fatalf("first attempt: done = %v, pushes = %d, want true and 1", done, push.pushes.Load())
+	}
+	if sent, err := repo.CheckIdempotency(msg.MessageID, sub.ID); err != nil || sent {
+		t.Fatalf("delivery log written before the crash (sent = %v, err = %v)", sent, err)
+	}
+
+	// Restarted process receives the redelivered message
+	var logs []*models.PushDeliveryLog
+	done, _ = newSendingWorker(t, repo).sendToSubscription(ctx, msg, sub, &logs, log)
+	if !done {
+		t.Error("redelivery after the crash was not treated as finished for the subscription")
+	}
+	if got := push.pushes.Load(); got != 1 {
+		t.Errorf("push service received %d pushes, want 1", got)
+	}
+	if len(logs) != 0 {
+		t.Errorf("redelivery logged %d attempts, want none", len(logs))
+	}
+}
+
+func TestClaimSendReleasedForRetryableFailure(t *testing.T) {
+	repo := repository.NewPushRepository(testutil.OpenDB(t))
+	push := newFakePushService(t)
+	ctx := context.Background()
+	log := zap.NewNop().Sugar()
+
+	const userID uint = 8
+	sub := newTestSubscription(t, repo, userID, push.URL+"/retry")
+	msg := &services.PushMessage{MessageID: "retry-msg", UserID: userID, NotificationType: "like", Title: "New like"}
+
+	w := newSendingWorker(t, repo)
+	for attempt, status := range []int{http.StatusServiceUnavailable, http.StatusCreated} {
+		push.status.Store(int32(status))
+		var logs []*models.PushDeliveryLog
+		done, _ := w.sendToSubscription(ctx, msg, sub, &logs, log)
+		if want := status == http.StatusCreated; done != want {
+			t.Fatalf("attempt %d (%d): done = %v, want %v", attempt+1, status, done, want)
+		}
+		w.flushDeliveryLogs(logs, log)
+	}
+	if got := push.pushes.Load(); got != 2 {
+		t.Errorf("push service received %d pushes, want 2 (failure then retry)", got)
+	}
+
+	// Once delivered, a further redelivery is caught by the delivery log
+	var logs []*models.PushDeliveryLog
+	if done, _ := w.sendToSubscription(ctx, msg, sub, &logs, log); !done {
+		t.Error("redelivery after success was not treated as finished")
+	}
+	if got := push.pushes.Load(); got != 2 {
+		t.Errorf("push service received %d pushes after redelivery, want 2", got)
+	}
+}
@@ -6,8 +6,10 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"net/http"
 	"os"
 	"os/signal"
+	"strconv"
 	"sync"
 	"syscall"
 	"time"
@@ -21,16 +23,43 @@ import (
 	"github.com/aman1117/backend/internal/services"
 	"github.com/aman1117/backend/pkg/models"
 	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 	"golang.org/x/time/rate"
 )
 
+// Retry scheduling for retryable push failures (429 / 5xx / network)
+const (
+	maxDeliveryAttempts = 5
+	retryBaseDelay      = 5 * time.Second  // Delay before the first retry, doubled per attempt
+	retryMaxDelay       = 15 * time.Minute // Cap for the exponential backoff
+	retryAfterMaxDelay  = 1 * time.Hour    // Cap for a push service's Retry-After
+
+	maxPendingDeliveryLogs = 1000 // Logs kept in memory after failed flushes before dropping
+)
+
+// Shutdown timing
+const (
+	drainGracePeriod = 20 * time.Second // Time Stop gives workers to finish buffered and in-flight messages
+	settleTimeout    = 5 * time.Second  // Bound for a single Complete/Abandon/retry enqueue
+)
+
+// messageReceiver is the part of *azservicebus.Receiver the worker uses
+type messageReceiver interface {
+	ReceiveMessages(ctx context.Context, maxMessages int, options *azservicebus.ReceiveMessagesOptions) ([]*azservicebus.ReceivedMessage, error)
+	CompleteMessage(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.CompleteMessageOptions) error
+	AbandonMessage(ctx context.Context, message *azservicebus.ReceivedMessage, options *azservicebus.AbandonMessageOptions) error
+	Close(ctx context.Context) error
+}
+
 // Worker handles push notification delivery
 type Worker struct {
 	cfg         *config.Config
 	pushRepo    *repository.PushRepository
 	sbClient    *azservicebus.Client
-	sbReceiver  *azservicebus.Receiver
+	sbReceiver  messageReceiver
+	sbSender    *azservicebus.Sender // Re-enqueues retries with a scheduled enqueue time
 	rateLimiter *rate.Limiter
 	wg          sync.WaitGroup
 	stopCh      chan struct{}
@@ -39,6 +68,19 @@ type Worker struct {
 	mu          sync.RWMutex
 	msgCh       chan *azservicebus.ReceivedMessage // Channel for distributing messages to workers
 	log         *zap.SugaredLogger                 // Logger with service context
+
+	// handle processes one message; processMessage outside of tests
+	handle     func(ctx context.Context, msg *azservicebus.ReceivedMessage, log *zap.SugaredLogger)
+	drainGrace time.Duration // Time Stop gives workers before abandoning what is left
+
+	// Receiving stops as soon as Stop is called; processing is only cancelled once the
+	// drain grace period runs out, so in-flight messages are settled rather than dropped
+	recvCancel context.CancelFunc
+	procCancel context.CancelFunc
+
+	// Delivery logs whose batch insert failed, retried on the next flush and in Stop
+	logMu       sync.Mutex
+	pendingLogs []*models.PushDeliveryLog
 }
 
 // NewWorker creates a new push notification worker
@@ -56,17 +98,29 @@ func NewWorker(cfg *config.Config, pushRepo *repository.PushRepository) (*Worker
 		return nil, fmt.Errorf("failed to create Service Bus receiver: %w", err)
 	}
 
-	return &Worker{
+	// Create sender for scheduled retries
+	sbSender, err := sbClient.NewSender(cfg.AzureServiceBus.QueueName, nil)
+	if err != nil {
+		sbReceiver.Close(context.Background())
+		sbClient.Close(context.Background())
+		return nil, fmt.Errorf("failed to create Service Bus sender: %w", err)
+	}
+
+	w := &Worker{
 		cfg:         cfg,
 		pushRepo:    pushRepo,
 		sbClient:    sbClient,
 		sbReceiver:  sbReceiver,
+		sbSender:    sbSender,
 		rateLimiter: rate.NewLimiter(rate.Limit(cfg.PushWorker.SendRateLimit), cfg.PushWorker.SendRateLimit),
 		stopCh:      make(chan struct{}),
 		readyCh:     make(chan struct{}),
 		msgCh:       make(chan *azservicebus.ReceivedMessage, cfg.PushWorker.MaxConcurrent*2), // Buffered channel
 		log:         logger.Sugar.With("service", "pushworker"),
-	}, nil
+		drainGrace:  drainGracePeriod,
+	}
+	w.handle = w.processMessage
+	return w, nil
 }
 
 // Start begins processing messages
@@ -79,14 +133,19 @@ func (w *Worker) Start(ctx context.Context) {
 	w.mu.Unlock()
 	close(w.readyCh) // We are not using this for now, but could be useful in future
 
+	recvCtx, recvCancel := context.WithCancel(ctx)
+	procCtx, procCancel := context.WithCancel(context.WithoutCancel(ctx))
+	w.recvCancel = recvCancel
+	w.procCancel = procCancel
+
 	// Start the message receiver goroutine (single receiver, distributes to workers via channel)
 	w.wg.Add(1)
-	go w.receiveLoop(ctx)
+	go w.receiveLoop(recvCtx)
 
 	// Start worker goroutines that process messages from the channel
 	for i := 0; i < w.cfg.PushWorker.MaxConcurrent; i++ {
 		w.wg.Add(1)
-		go w.processLoop(ctx, i)
+		go w.processLoop(procCtx, i)
 	}
 
 	w.log.Infow("Push worker started",
@@ -95,12 +154,17 @@ func (w *Worker) Start(ctx context.Context) {
 	)
 }
 
-// Stop gracefully stops the worker
+// Stop gracefully stops the worker. Receiving stops immediately; messages already
+// pulled from Service Bus are processed within the drain grace period, and any left
+// after it are abandoned explicitly so they are redelivered without waiting for lock expiry.
 func (w *Worker) Stop(ctx context.Context) {
 	w.log.Info("Stopping push worker...")
 
-	// Signal workers to stop
+	// Stop receiving; the receiver closes msgCh and workers drain what is buffered
 	close(w.stopCh)
+	if w.recvCancel != nil {
+		w.recvCancel()
+	}
 
 	// Wait for in-flight work
 	done := make(chan struct{})
@@ -109,17 +173,28 @@ func (w *Worker) Stop(ctx context.Context) {
 		close(done)
 	}()
 
+	grace := time.NewTimer(w.drainGrace)
+	defer grace.Stop()
+
 	select {
 	case <-done:
 		w.log.Info("All workers stopped gracefully")
+	case <-grace.C:
+		w.stopDraining(ctx, done)
 	case <-ctx.Done():
-		w.log.Warn("Shutdown timeout, some workers may not have finished")
+		w.stopDraining(ctx, done)
 	}
 
+	// Write any delivery logs still held from failed flushes
+	w.flushDeliveryLogs(nil, w.log)
+
 	// Close Service Bus connections
 	if w.sbReceiver != nil {
 		w.sbReceiver.Close(ctx)
 	}
+	if w.sbSender != nil {
+		w.sbSender.Close(ctx)
+	}
 	if w.sbClient != nil {
 		w.sbClient.Close(ctx)
 	}
@@ -129,6 +204,27 @@ func (w *Worker) Stop(ctx context.Context) {
 	w.mu.Unlock()
 }
 
+// stopDraining cancels processing after the grace period so workers settle their
+// current message and abandon the rest of the buffer, then waits briefly for them
+func (w *Worker) stopDraining(ctx context.Context, done <-chan struct{}) {
+	w.log.Warnw("Drain grace period over, abandoning remaining messages", "buffered", len(w.msgCh))
+	if w.procCancel != nil {
+		w.procCancel()
+	}
+
+	settle := time.NewTimer(settleTimeout)
+	defer settle.Stop()
+
+	select {
+	case <-done:
+		w.log.Info("All workers stopped after abandoning remaining messages")
+	case <-settle.C:
+		w.log.Warn("Shutdown timeout, some workers may not have finished")
+	case <-ctx.Done():
+		w.log.Warn("Shutdown timeout, some workers may not have finished")
+	}
+}
+
 // IsReady returns true if the worker is ready to process messages
 func (w *Worker) IsReady() bool {
 	w.mu.RLock()
@@ -166,12 +262,16 @@ func (w *Worker) receiveLoop(ctx context.Context) {
 			continue
 		}
 
+		messagesReceived.Add(float64(len(messages)))
+
 		// Distribute messages to workers via channel
-		for _, msg := range messages {
+		for i, msg := range messages {
 			select {
 			case <-w.stopCh:
+				w.release(messages[i:], log)
 				return
 			case <-ctx.Done():
+				w.release(messages[i:], log)
 				return
 			case w.msgCh <- msg:
 				// Message sent to worker
@@ -187,43 +287,104 @@ func (w *Worker) processLoop(ctx context.Context, workerID int) {
 	log := w.log.With("worker_id", workerID)
 	log.Info("Worker started")
 
-	for {
-		select {
-		case <-w.stopCh:
-			log.Info("Worker stopping")
-			return
-		case <-ctx.Done():
-			log.Info("Worker context cancelled")
-			return
-		case msg, ok := <-w.msgCh:
-			if !ok {
-				log.Info("Message channel closed, worker exiting")
-				return
-			}
-			w.processMessage(ctx, msg, log)
+	// Runs until the receiver closes msgCh, so buffered messages are drained on shutdown.
+	// Once processing is cancelled, whatever is left is abandoned instead of processed.
+	for msg := range w.msgCh {
+		if ctx.Err() != nil {
+			w.release([]*azservicebus.ReceivedMessage{msg}, log)
+			continue
 		}
+		w.handle(ctx, msg, log)
+	}
+	log.Info("Message channel closed, worker exiting")
+}
+
+// release abandons messages that were received but won't be processed by this instance
+func (w *Worker) release(messages []*azservicebus.ReceivedMessage, log *zap.SugaredLogger) {
+	if len(messages) == 0 {
+		return
+	}
+	log.Infow("Releasing unprocessed messages", "count", len(messages))
+	for _, msg := range messages {
+		w.abandon(context.Background(), msg)
+	}
+}
+
+// deadLetter persists a message the worker is giving up on, then completes it.
+// If the record can't be written the message is abandoned so it isn't lost.
+func (w *Worker) deadLetter(ctx context.Context, msg *azservicebus.ReceivedMessage, pushMsg *services.PushMessage, reason, errMsg string, log *zap.SugaredLogger) {
+	record := &models.PushDeadLetter{
+		MessageID:     msg.MessageID,
+		Body:          string(msg.Body),
+		Reason:        reason,
+		Error:         errMsg,
+		DeliveryCount: msg.DeliveryCount,
+	}
+	if pushMsg != nil {
+		record.UserID = pushMsg.UserID
+		record.NotificationType = pushMsg.NotificationType
+		if pushMsg.MessageID != "" {
+			record.MessageID = pushMsg.MessageID
+		}
+	}
+
+	if err := w.pushRepo.CreateDeadLetter(record); err != nil {
+		log.Errorw("Failed to persist dead-lettered push message, abandoning",
+			"message_id", record.MessageID,
+			"reason", reason,
+			"error", err,
+		)
+		w.abandon(ctx, msg)
+		return
 	}
+
+	messagesDeadLettered.WithLabelValues(reason).Inc()
+	w.complete(ctx, msg)
+}
+
+// settleContext bounds a settlement call without inheriting cancellation, so a message
+// being processed at shutdown is still completed or abandoned explicitly
+func settleContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.WithoutCancel(ctx), settleTimeout)
+}
+
+// complete removes a message from the queue
+func (w *Worker) complete(ctx context.Context, msg *azservicebus.ReceivedMessage) {
+	ctx, cancel := settleContext(ctx)
+	defer cancel()
+	messagesCompleted.Inc()
+	w.sbReceiver.CompleteMessage(ctx, msg, nil)
+}
+
+// abandon releases a message for immediate redelivery
+func (w *Worker) abandon(ctx context.Context, msg *azservicebus.ReceivedMessage) {
+	ctx, cancel := settleContext(ctx)
+	defer cancel()
+	messagesAbandoned.Inc()
+	w.sbReceiver.AbandonMessage(ctx, msg, nil)
 }
 
 // processMessage handles a single push notification message
 func (w *Worker) processMessage(ctx context.Context, msg *azservicebus.ReceivedMessage, log *zap.SugaredLogger) {
+	messagesProcessed.Inc()
+
 	var pushMsg services.PushMessage
 	if err := json.Unmarshal(msg.Body, &pushMsg); err != nil {
 		log.Errorw("Failed to unmarshal message", "error", err)
-		// Complete the message to remove from queue (can't process invalid messages)
-		w.sbReceiver.CompleteMessage(ctx, msg, nil)
+		// Can't process invalid messages - keep a copy, then remove from queue
+		w.deadLetter(ctx, msg, nil, models.PushDeadLetterUnmarshal, err.Error(), log)
 		return
 	}
 
-	// Check delivery count - abandon permanently if too many retries
-	deliveryCount := msg.DeliveryCount
-	if deliveryCount > 5 {
+	// Check delivery count - abandon permanently if too many retries.
+	// Scheduled retries are new messages, so their attempts are carried in the body.
+	deliveryCount := msg.DeliveryCount + uint32(pushMsg.RetryAttempt)
+	if deliveryCount > maxDeliveryAttempts {
 		log.Warnw("Message exceeded max delivery attempts, dead-lettering",
 			"message_id", pushMsg.MessageID,
 			"delivery_count", deliveryCount,
 		)
-		// Dead letter the message (or complete to discard)
-		w.sbReceiver.CompleteMessage(ctx, msg, nil)
+		w.deadLetter(ctx, msg, &pushMsg, models.PushDeadLetterMaxDeliveries, "", log)
 		return
 	}
 
@@ -240,14 +401,13 @@ func (w *Worker) processMessage(ctx context.Context, msg *azservicebus.ReceivedM
 	pref, err := w.pushRepo.GetOrCreatePreference(pushMsg.UserID)
 	if err != nil {
 		log.Errorw("Failed to get user preferences", "error", err)
-		w.sbReceiver.AbandonMessage(ctx, msg, nil)
+		w.abandon(ctx, msg)
 		return
 	}
 
-	// NOTE: Per-notification-type preferences are intentionally disabled.
-	// We want to send all push notifications to everyone who has push enabled.
-	// The IsTypeEnabled check below is commented out but kept for future use
-	// when we want to respect individual notification type preferences.
+	// NOTE: Per-notification-type preferences and quiet hours are enforced by the
+	// publisher before enqueuing. The IsTypeEnabled check below stays commented out
+	// so preference changes made after a message was queued don't drop it here.
 	//
 	// if pushMsg.NotificationType != string(models.NotifTypePhotoUploaded) {
 	// 	if !pref.IsTypeEnabled(pushMsg.NotificationType) {
@@ -260,7 +420,7 @@ func (w *Worker) processMessage(ctx context.Context, msg *azservicebus.ReceivedM
 	// 2. Check quiet hours
 	if pref.IsInQuietHours(time.Now()) {
 		log.Infow("Push skipped", "reason", "quiet_hours")
-		w.sbReceiver.AbandonMessage(ctx, msg, nil)
+		w.abandon(ctx, msg)
 		return
 	}
 
@@ -272,7 +432,7 @@ func (w *Worker) processMessage(ctx context.Context, msg *azservicebus.ReceivedM
 			// Continue anyway
 		} else if isDupe {
 			log.Infow("Push skipped", "reason", "dedupe")
-			w.sbReceiver.CompleteMessage(ctx, msg, nil)
+			w.complete(ctx, msg)
 			return
 		}
 	}
@@ -281,44 +441,156 @@ func (w *Worker) processMessage(ctx context.Context, msg *azservicebus.ReceivedM
 	subscriptions, err := w.pushRepo.GetActiveSubscriptionsByUserID(pushMsg.UserID)
 	if err != nil {
 		log.Errorw("Failed to get subscriptions", "error", err)
-		w.sbReceiver.AbandonMessage(ctx, msg, nil)
+		w.abandon(ctx, msg)
 		return
 	}
 
 	if len(subscriptions) == 0 {
 		log.Infow("No active subscriptions for user")
-		w.sbReceiver.CompleteMessage(ctx, msg, nil)
+		w.complete(ctx, msg)
 		return
 	}
 
 	log.Infow("Sending to subscriptions", "count", len(subscriptions))
 
-	// 5. Send to each subscription
+	// 5. Send to each subscription, buffering delivery logs for one insert
 	allSucceeded := true
+	var retryAfter time.Duration
+	deliveryLogs := make([]*models.PushDeliveryLog, 0, len(subscriptions))
 	for _, sub := range subscriptions {
-		success := w.sendToSubscription(ctx, &pushMsg, &sub, log)
+		success, subRetryAfter := w.sendToSubscription(ctx, &pushMsg, &sub, &deliveryLogs, log)
 		if !success {
 			allSucceeded = false
+			if subRetryAfter > retryAfter {
+				retryAfter = subRetryAfter
+			}
 		}
 	}
 
-	// 6. Complete or abandon message
+	// Flush before settling the message so a retry's idempotency check sees these sends
+	w.flushDeliveryLogs(deliveryLogs, log)
+
+	// 6. Complete or schedule a retry
 	if allSucceeded {
-		w.sbReceiver.CompleteMessage(ctx, msg, nil)
+		w.complete(ctx, msg)
 	} else {
 		// At least one failed with retryable error
-		w.sbReceiver.AbandonMessage(ctx, msg, nil)
+		w.scheduleRetry(ctx, msg, &pushMsg, retryAfter, log)
+	}
+}
+
+// flushDeliveryLogs writes logs, plus any left over from earlier failed flushes, in one
+// batch insert. On failure they are kept in memory (bounded) for the next flush.
+func (w *Worker) flushDeliveryLogs(logs []*models.PushDeliveryLog, log *zap.SugaredLogger) {
+	w.logMu.Lock()
+	defer w.logMu.Unlock()
+
+	batch := append(w.pendingLogs, logs...)
+	w.pendingLogs = nil
+	if len(batch) == 0 {
+		return
+	}
+
+	if err := w.pushRepo.CreateDeliveryLogsBatch(batch); err != nil {
+		if len(batch) > maxPendingDeliveryLogs {
+			log.Warnw("Dropping oldest pending delivery logs", "dropped", len(batch)-maxPendingDeliveryLogs)
+			batch = batch[len(batch)-maxPendingDeliveryLogs:]
+		}
+		log.Errorw("Failed to write delivery logs", "count", len(batch), "error", err)
+		w.pendingLogs = batch
+	}
+}
+
+// retryDelay returns the exponential backoff for the given attempt (1-based),
+// raised to the push service's Retry-After when that is longer
+func retryDelay(attempt int, retryAfter time.Duration) time.Duration {
+	delay := retryBaseDelay
+	for i := 1; i < attempt && delay < retryMaxDelay; i++ {
+		delay *= 2
 	}
+	if delay > retryMaxDelay {
+		delay = retryMaxDelay
+	}
+	if retryAfter > retryAfterMaxDelay {
+		retryAfter = retryAfterMaxDelay
+	}
+	if retryAfter > delay {
+		delay = retryAfter
+	}
+	return delay
 }
 
-// sendToSubscription sends a push notification to a single subscription
-func (w *Worker) sendToSubscription(ctx context.Context, pushMsg *services.PushMessage, sub *models.PushSubscription, log *zap.SugaredLogger) bool {
+// scheduleRetry re-enqueues the message with a scheduled enqueue time and completes the
+// original, so retries back off instead of being redelivered immediately by Abandon.
+// Subscriptions that already succeeded are skipped on retry by the idempotency check.
+func (w *Worker) scheduleRetry(ctx context.Context, msg *azservicebus.ReceivedMessage, pushMsg *services.PushMessage, retryAfter time.Duration, log *zap.SugaredLogger) {
+	attempt := int(msg.DeliveryCount) + pushMsg.RetryAttempt
+	delay := retryDelay(attempt, retryAfter)
+	enqueueAt := time.Now().Add(delay)
+
+	// Don't schedule past the push's own TTL - the push service would drop it anyway
+	if pushMsg.TTLSeconds > 0 && enqueueAt.After(pushMsg.CreatedAt.Add(time.Duration(pushMsg.TTLSeconds)*time.Second)) {
+		log.Infow("Push expires before next retry, dropping", "retry_in", delay)
+		w.complete(ctx, msg)
+		return
+	}
+
+	retryMsg := *pushMsg
+	retryMsg.RetryAttempt = attempt
+	body, err := json.Marshal(retryMsg)
+	if err != nil {
+		w.abandon(ctx, msg)
+		return
+	}
+
+	messageID := fmt.Sprintf("%s-r%d", pushMsg.MessageID, attempt)
+	sendCtx, cancel := settleContext(ctx)
+	defer cancel()
+	if err := w.sbSender.SendMessage(sendCtx, &azservicebus.Message{
+		Body:                  body,
+		MessageID:             &messageID,
+		Subject:               msg.Subject,
+		TimeToLive:            msg.TimeToLive,
+		ApplicationProperties: msg.ApplicationProperties,
+		ScheduledEnqueueTime:  &enqueueAt,
+	}, nil); err != nil {
+		// Fall back to immediate redelivery rather than losing the message
+		log.Warnw("Failed to schedule push retry, abandoning", "error", err)
+		w.abandon(ctx, msg)
+		return
+	}
+
+	log.Infow("Push retry scheduled", "attempt", attempt, "retry_in", delay)
+	retriesScheduled.Inc()
+	w.complete(ctx, msg)
+}
+
+// parseRetryAfter reads a Retry-After header given as seconds or an HTTP date
+func parseRetryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(header); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if at, err := http.ParseTime(header); err == nil {
+		if d := time.Until(at); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
+// sendToSubscription sends a push notification to a single subscription, appending the
+// delivery attempt to logs. Returns whether delivery is finished for this subscription
+// and, for 429s, the push service's requested Retry-After.
+func (w *Worker) sendToSubscription(ctx context.Context, pushMsg *services.PushMessage, sub *models.PushSubscription, logs *[]*models.PushDeliveryLog, log *zap.SugaredLogger) (bool, time.Duration) {
 	log = log.With("subscription_id", sub.ID)
 
 	// Rate limit
 	if err := w.rateLimiter.Wait(ctx); err != nil {
 		log.Warnw("Rate limiter cancelled", "error", err)
-		return false
+		return false, 0
 	}
 
 	// Check idempotency
@@ -327,12 +599,29 @@ func (w *Worker) sendToSubscription(ctx context.Context, pushMsg *services.PushM
 		log.Warnw("Idempotency check failed", "error", err)
 	} else if alreadySent {
 		log.Debugw("Already sent to this subscription, skipping")
-		return true
+		return true, 0
+	}
+
+	// Claim the send before making it. Delivery is at-least-once up to here and
+	// at-most-once from here on: if the worker dies after the claim but before the
+	// claim is released or the log is written, the redelivered message skips this
+	// subscription rather than risk a duplicate push. A lost push is preferable to a
+	// duplicate since the notification is still in the in-app inbox. If the claim
+	// can't be written we fall back to sending (at-least-once).
+	claimed, err := w.pushRepo.ClaimSend(pushMsg.MessageID, sub.ID, pushMsg.UserID)
+	if err != nil {
+		log.Warnw("Send claim failed, sending without claim", "error", err)
+	} else if !claimed {
+		log.Infow("Send already claimed by an earlier attempt, skipping")
+		return true, 0
 	}
 
 	// Build payload
 	payload := buildPushPayload(pushMsg)
 
+	// Sign with the key the subscription was created under
+	vapidKey := w.cfg.WebPush.KeyPair(sub.VapidKeyID)
+
 	// Send push notification
 	start := time.Now()
 
@@ -344,12 +633,17 @@ func (w *Worker) sendToSubscription(ctx context.Context, pushMsg *services.PushM
 		},
 	}, &webpush.Options{
 		Subscriber:      w.cfg.WebPush.VapidSubject,
-		VAPIDPublicKey:  w.cfg.WebPush.VapidPublicKey,
-		VAPIDPrivateKey: w.cfg.WebPush.VapidPrivateKey,
+		VAPIDPublicKey:  vapidKey.PublicKey,
+		VAPIDPrivateKey: vapidKey.PrivateKey,
 		TTL:             pushMsg.TTLSeconds,
 	})
 
 	duration := time.Since(start)
+	if err != nil {
+		observeDelivery(0, duration)
+	} else {
+		observeDelivery(resp.StatusCode, duration)
+	}
 
 	// Log delivery attempt
 	deliveryLog := &models.PushDeliveryLog{
@@ -365,9 +659,10 @@ func (w *Worker) sendToSubscription(ctx context.Context, pushMsg *services.PushM
 		log.Errorw("Failed to send push notification", "error", err)
 		deliveryLog.StatusCode = 0
 		deliveryLog.Error = err.Error()
-		w.pushRepo.CreateDeliveryLog(deliveryLog)
+		*logs = append(*logs, deliveryLog)
 		w.pushRepo.IncrementSubscriptionFailure(sub.ID, 5) // Max 5 failures
-		return false
+		w.releaseClaim(pushMsg, sub, log)
+		return false, 0
 	}
 
 	defer resp.Body.Close()
@@ -383,38 +678,48 @@ func (w *Worker) sendToSubscription(ctx context.Context, pushMsg *services.PushM
 	case statusCode >= 200 && statusCode < 300:
 		// Success
 		w.pushRepo.UpdateSubscriptionSuccess(sub.ID)
-		w.pushRepo.CreateDeliveryLog(deliveryLog)
-		return true
+		*logs = append(*logs, deliveryLog)
+		return true, 0
 
 	case statusCode == 404 || statusCode == 410:
 		// Subscription is gone - mark as dead
 		log.Infow("Subscription gone, marking as dead")
 		w.pushRepo.MarkSubscriptionGone(sub.ID)
 		deliveryLog.Error = "subscription_gone"
-		w.pushRepo.CreateDeliveryLog(deliveryLog)
-		return true // Don't retry for this subscription
+		*logs = append(*logs, deliveryLog)
+		return true, 0 // Don't retry for this subscription
 
 	case statusCode == 429:
-		// Rate limited - retry later
-		log.Warnw("Rate limited by push service")
+		// Rate limited - retry later, no sooner than the push service asks
+		retryAfter := parseRetryAfter(resp.Header.Get("Retry-After"))
+		log.Warnw("Rate limited by push service", "retry_after", retryAfter)
 		deliveryLog.Error = "rate_limited"
-		w.pushRepo.CreateDeliveryLog(deliveryLog)
-		return false
+		*logs = append(*logs, deliveryLog)
+		w.releaseClaim(pushMsg, sub, log)
+		return false, retryAfter
 
 	case statusCode >= 500:
 		// Server error - retry
 		log.Warnw("Push service server error", "status_code", statusCode)
 		deliveryLog.Error = fmt.Sprintf("server_error_%d", statusCode)
-		w.pushRepo.CreateDeliveryLog(deliveryLog)
-		return false
+		*logs = append(*logs, deliveryLog)
+		w.releaseClaim(pushMsg, sub, log)
+		return false, parseRetryAfter(resp.Header.Get("Retry-After"))
 
 	default:
 		// Other client error (400, 401, 403, etc.)
 		log.Errorw("Push client error", "status_code", statusCode)
 		deliveryLog.Error = fmt.Sprintf("client_error_%d", statusCode)
-		w.pushRepo.CreateDeliveryLog(deliveryLog)
+		*logs = append(*logs, deliveryLog)
 		w.pushRepo.IncrementSubscriptionFailure(sub.ID, 5)
-		return true // Don't retry, likely auth/encryption issue
+		return true, 0 // Don't retry, likely auth/encryption issue
+	}
+}
+
+// releaseClaim drops the send claim after a retryable failure so the retry can send
+func (w *Worker) releaseClaim(pushMsg *services.PushMessage, sub *models.PushSubscription, log *zap.SugaredLogger) {
+	if err := w.pushRepo.ReleaseSendClaim(pushMsg.MessageID, sub.ID); err != nil {
+		log.Warnw("Failed to release send claim, retry will skip this subscription", "error", err)
 	}
 }
 
@@ -503,6 +808,10 @@ func main() {
 		return c.Status(503).SendString("Not Ready")
 	})
 
+	// Prometheus metrics
+	registerMetrics()
+	app.Get("/metrics", adaptor.HTTPHandler(promhttp.Handler()))
+
 	// Start HTTP server
 	port := cfg.Server.Port
 	if port == "" {
@@ -530,8 +839,8 @@ func main() {
 	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer shutdownCancel()
 
-	cancel() // Cancel worker context
 	worker.Stop(shutdownCtx)
+	cancel() // Cancel worker context
 
 	if err := app.Shutdown(); err != nil {
 		log.Warnw("Error shutting down health server", "error", err)
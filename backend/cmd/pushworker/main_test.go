@@ -0,0 +1,222 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
+	"github.com/aman1117/backend/internal/config"
+	"go.uber.org/zap"
+)
+
+// fakeReceiver hands out one batch, then blocks like an idle queue until its context ends
+type fakeReceiver struct {
+	mu                sync.Mutex
+	batch             []*azservicebus.ReceivedMessage
+	receives          int
+	stopped           bool // Set once Stop has been called
+	receivesAfterStop int  // Receives made with a live context after Stop
+	completed         map[string]int
+	abandoned         map[string]int
+}
+
+func newFakeReceiver(n int) *fakeReceiver {
+	r := &fakeReceiver{completed: map[string]int{}, abandoned: map[string]int{}}
+	for i := 1; i <= n; i++ {
+		r.batch = append(r.batch, &azservicebus.ReceivedMessage{MessageID: fmt.Sprintf("msg-%d", i)})
+	}
+	return r
+}
+
+func (r *fakeReceiver) ReceiveMessages(ctx context.Context, _ int, _ *azservicebus.ReceiveMessagesOptions) ([]*azservicebus.ReceivedMessage, error) {
+	r.mu.Lock()
+	r.receives++
+	if r.stopped && ctx.Err() == nil {
+		r.receivesAfterStop++
+	}
+	batch := r.batch
+	r.batch = nil
+	r.mu.Unlock()
+
+	if len(batch) > 0 && ctx.Err() == nil {
+		return batch, nil
+	}
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func (r *fakeReceiver) CompleteMessage(_ context.Context, msg *azservicebus.ReceivedMessage, _ *azservicebus.CompleteMessageOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.completed[msg.MessageID]++
+	return nil
+}
+
+func (r *fakeReceiver) AbandonMessage(_ context.Context, msg *azservicebus.ReceivedMessage, _ *azservicebus.AbandonMessageOptions) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.abandoned[msg.MessageID]++
+	return nil
+}
+
+func (r *fakeReceiver) Close(context.Context) error { return nil }
+
+func (r *fakeReceiver) receiveCount() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.receives
+}
+
+func (r *fakeReceiver) markStopped() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.stopped = true
+}
+
+// newTestWorker builds a worker around recv without Service Bus or database connections
+func newTestWorker(recv *fakeReceiver, concurrency int, drainGrace time.Duration) *Worker {
+	cfg := &config.Config{}
+	cfg.PushWorker.MaxConcurrent = concurrency
+	return &Worker{
+		cfg:        cfg,
+		sbReceiver: recv,
+		stopCh:     make(chan struct{}),
+		readyCh:    make(chan struct{}),
+		msgCh:      make(chan *azservicebus.ReceivedMessage, concurrency*2),
+		log:        zap.NewNop().Sugar(),
+		drainGrace: drainGrace,
+	}
+}
+
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(5 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+// stopWorker calls Stop as main does on SIGTERM and returns once Stop has stopped receiving
+func stopWorker(t *testing.T, w *Worker, recv *fakeReceiver) <-chan struct{} {
+	t.Helper()
+	recv.markStopped()
+	stopped := make(chan struct{})
+	go func() {
+		w.Stop(context.Background())
+		close(stopped)
+	}()
+	<-w.stopCh
+	return stopped
+}
+
+func waitStopped(t *testing.T, stopped <-chan struct{}, within time.Duration) {
+	t.Helper()
+	select {
+	case <-stopped:
+	case <-time.After(within):
+		t.Fatalf("Stop did not return within %v", within)
+	}
+}
+
+func TestStopDrainsInFlightMessages(t *testing.T) {
+	const concurrency = 2
+	recv := newFakeReceiver(4) // Two in flight, two buffered
+	w := newTestWorker(recv, concurrency, 5*time.Second)
+
+	var (
+		mu        sync.Mutex
+		inFlight  int
+		cancelled []string
+	)
+	proceed := make(chan struct{})
+	w.handle = func(ctx context.Context, msg *azservicebus.ReceivedMessage, _ *zap.SugaredLogger) {
+		mu.Lock()
+		inFlight++
+		mu.Unlock()
+		<-proceed // Still sending when Stop is called
+		if ctx.Err() != nil {
+			mu.Lock()
+			cancelled = append(cancelled, msg.MessageID)
+			mu.Unlock()
+		}
+		w.complete(ctx, msg)
+	}
+
+	w.Start(context.Background())
+	waitFor(t, "both workers to pick up a message", func() bool {
+		mu.Lock()
+		defer mu.Unlock()
+		return inFlight == concurrency
+	})
+	// A second receive means the whole first batch has been handed to msgCh
+	waitFor(t, "the batch to be buffered", func() bool { return recv.receiveCount() >= 2 })
+
+	stopped := stopWorker(t, w, recv)
+	close(proceed)
+	waitStopped(t, stopped, 2*time.Second)
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	for _, id := range []string{"msg-1", "msg-2", "msg-3", "msg-4"} {
+		if got := recv.completed[id]; got != 1 {
+			t.Errorf("%s completed %d times, want 1", id, got)
+		}
+	}
+	if len(recv.abandoned) != 0 {
+		t.Errorf("abandoned %v during a drain within the grace period, want none", recv.abandoned)
+	}
+	if recv.receivesAfterStop != 0 {
+		t.Errorf("%d receives started after Stop, want 0", recv.receivesAfterStop)
+	}
+	if len(cancelled) != 0 {
+		t.Errorf("messages %v were processed with a cancelled context during the grace period", cancelled)
+	}
+	if w.IsReady() {
+		t.Error("worker still reports ready after Stop")
+	}
+}
+
+func TestStopAbandonsBufferedMessagesAfterGracePeriod(t *testing.T) {
+	recv := newFakeReceiver(3) // One in flight, two buffered
+	w := newTestWorker(recv, 1, 50*time.Millisecond)
+
+	started := make(chan struct{}, 3)
+	w.handle = func(ctx context.Context, msg *azservicebus.ReceivedMessage, _ *zap.SugaredLogger) {
+		started <- struct{}{}
+		<-ctx.Done() // A send that outlives the grace period
+		w.complete(ctx, msg)
+	}
+
+	w.Start(context.Background())
+	<-started
+	waitFor(t, "the batch to be buffered", func() bool { return recv.receiveCount() >= 2 })
+
+	stopped := stopWorker(t, w, recv)
+	waitStopped(t, stopped, settleTimeout)
+
+	recv.mu.Lock()
+	defer recv.mu.Unlock()
+	if got := recv.completed["msg-1"]; got != 1 {
+		t.Errorf("in-flight msg-1 completed %d times, want 1", got)
+	}
+	for _, id := range []string{"msg-2", "msg-3"} {
+		if got := recv.abandoned[id]; got != 1 {
+			t.Errorf("buffered %s abandoned %d times, want 1", id, got)
+		}
+		if got := recv.completed[id]; got != 0 {
+			t.Errorf("buffered %s completed %d times after the grace period, want 0", id, got)
+		}
+	}
+	if recv.receivesAfterStop != 0 {
+		t.Errorf("%d receives started after Stop, want 0", recv.receivesAfterStop)
+	}
+	if len(started) != 0 {
+		t.Errorf("%d buffered messages were processed after the grace period", len(started))
+	}
+}
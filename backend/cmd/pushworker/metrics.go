@@ -0,0 +1,65 @@
+package main
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Prometheus metrics exposed on /metrics
+var (
+	messagesReceived = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pushworker_messages_received_total",
+		Help: "Messages received from Service Bus.",
+	})
+	messagesProcessed = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pushworker_messages_processed_total",
+		Help: "Messages handled by a worker goroutine.",
+	})
+	messagesCompleted = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pushworker_messages_completed_total",
+		Help: "Messages completed (removed from the queue), including those replaced by a scheduled retry.",
+	})
+	messagesAbandoned = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pushworker_messages_abandoned_total",
+		Help: "Messages abandoned for immediate redelivery.",
+	})
+	messagesDeadLettered = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pushworker_messages_dead_lettered_total",
+		Help: "Messages the worker gave up on, by reason.",
+	}, []string{"reason"})
+	retriesScheduled = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "pushworker_retries_scheduled_total",
+		Help: "Retries re-enqueued with a backoff delay.",
+	})
+	deliveries = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "pushworker_deliveries_total",
+		Help: "Push service responses by HTTP status code (0 for network errors).",
+	}, []string{"status_code"})
+	sendDuration = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "pushworker_send_duration_seconds",
+		Help:    "Time spent sending a single push to the push service.",
+		Buckets: prometheus.DefBuckets,
+	})
+)
+
+// registerMetrics registers the worker's metrics with the default registry
+func registerMetrics() {
+	prometheus.MustRegister(
+		messagesReceived,
+		messagesProcessed,
+		messagesCompleted,
+		messagesAbandoned,
+		messagesDeadLettered,
+		retriesScheduled,
+		deliveries,
+		sendDuration,
+	)
+}
+
+// observeDelivery records one send attempt's status code and duration
+func observeDelivery(statusCode int, duration time.Duration) {
+	deliveries.WithLabelValues(strconv.Itoa(statusCode)).Inc()
+	sendDuration.Observe(duration.Seconds())
+}
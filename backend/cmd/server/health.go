@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/aman1117/backend/pkg/redis"
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// readinessTimeout bounds each dependency check so a hung dependency fails readiness quickly.
+// Checks run concurrently with their own deadline, so one slow dependency neither eats
+// into another's budget nor makes the probe take longer than readinessTimeout.
+const readinessTimeout = 2 * time.Second
+
+// dependencyCheck pings one dependency, returning nil when it is healthy
+type dependencyCheck func(ctx context.Context) error
+
+// registerHealthChecks adds the liveness (/healthz) and readiness (/readyz) probes.
+// Readiness pings the database and, if configured, Redis; a failing dependency returns
+// 503 with its name and error so rollouts don't route traffic to a broken pod.
+func registerHealthChecks(app *fiber.App, db *gorm.DB, redisConfigured bool) {
+	dependencies := map[string]dependencyCheck{
+		"database": func(ctx context.Context) error {
+			return db.WithContext(ctx).Exec("SELECT 1").Error
+		},
+	}
+	if redisConfigured {
+		dependencies["redis"] = func(ctx context.Context) error {
+			if !redis.IsAvailable() {
+				return errors.New("not connected")
+			}
+			return redis.Get().Ping(ctx).Err()
+		}
+	}
+
+	app.Get("/healthz", func(c *fiber.Ctx) error {
+		return c.JSON(fiber.Map{"status": "ok"})
+	})
+
+	app.Get("/readyz", func(c *fiber.Ctx) error {
+		checks, ready := runDependencyChecks(dependencies)
+		if !ready {
+			return c.Status(fiber.StatusServiceUnavailable).JSON(fiber.Map{
+				"status": "unavailable",
+				"checks": checks,
+			})
+		}
+		return c.JSON(fiber.Map{
+			"status": "ok",
+			"checks": checks,
+		})
+	})
+}
+
+// runDependencyChecks runs every check concurrently, each under its own readinessTimeout.
+// Returns each dependency's status ("ok" or the error) and whether all of them passed.
+func runDependencyChecks(dependencies map[string]dependencyCheck) (fiber.Map, bool) {
+	var (
+		mu     sync.Mutex
+		wg     sync.WaitGroup
+		checks = fiber.Map{}
+		ready  = true
+	)
+	for name, check := range dependencies {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), readinessTimeout)
+			defer cancel()
+			err := check(ctx)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err != nil {
+				checks[name] = err.Error()
+				ready = false
+			} else {
+				checks[name] = "ok"
+			}
+		}()
+	}
+	wg.Wait()
+	return checks, ready
+}
@@ -34,6 +34,10 @@ package main
 
 import (
 	"context"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 	"time"
 
 	"github.com/aman1117/backend/internal/config"
@@ -108,7 +112,7 @@ func main() {
 
 	// Initialize push publisher (optional - for Web Push notifications)
 	if cfg.AzureServiceBus.ConnectionString != "" {
-		if err := services.InitPushPublisher(cfg); err != nil {
+		if err := services.InitPushPublisher(cfg, c.PushRepo); err != nil {
 			log.Warnf("Push publisher initialization failed: %v", err)
 			log.Warn("Web Push notifications are disabled")
 		} else {
@@ -125,18 +129,21 @@ func main() {
 	}
 
 	// Setup cron jobs
-	setupCronJobs(c, log)
+	cronScheduler := setupCronJobs(c, log)
 
 	// Create Fiber app
 	app := fiber.New(fiber.Config{
 		ReadTimeout:  cfg.Server.ReadTimeout,
 		WriteTimeout: cfg.Server.WriteTimeout,
+		// Fiber's 4MB default would reject uploads the image limits allow
+		BodyLimit: cfg.MaxRequestBodyBytes(),
 	})
 
 	// Setup middleware
 	app.Use(middleware.RequestLogger)
+	log.Infow("CORS allowlist", "origins", cfg.Server.CORSAllowedOrigins)
 	app.Use(cors.New(cors.Config{
-		AllowOrigins:     "*",
+		AllowOrigins:     strings.Join(cfg.Server.CORSAllowedOrigins, ","),
 		AllowMethods:     "*",
 		AllowHeaders:     "*",
 		ExposeHeaders:    "*",
@@ -147,18 +154,67 @@ func main() {
 	// Controlled by PPROF_ENABLED env var; token protection via PPROF_TOKEN
 	observability.RegisterPprof(app, cfg.Pprof)
 
+	// Kubernetes liveness/readiness probes (no auth, no rate limit)
+	registerHealthChecks(app, db, cfg.Redis.URL != "")
+
 	// Setup routes
 	c.Router.Setup(app)
 
 	// Start server
 	addr := "0.0.0.0:" + cfg.Server.Port
 	log.Infof("Server starting on http://localhost:%s", cfg.Server.Port)
-	if err := app.Listen(addr); err != nil {
-		log.Fatalf("Failed to start server: %v", err)
+	go func() {
+		if err := app.Listen(addr); err != nil {
+			log.Fatalf("Failed to start server: %v", err)
+		}
+	}()
+
+	// Wait for shutdown signal
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
+	<-sigCh
+
+	log.Info("Shutdown signal received")
+	shutdown(app, cronScheduler, cfg.Server.ShutdownTimeout, log)
+	log.Info("Server shutdown complete")
+}
+
+// shutdown stops accepting requests, lets in-flight requests and running cron jobs finish
+// within timeout, then flushes the push publisher and closes Redis and the database
+func shutdown(app *fiber.App, cronScheduler *cron.Cron, timeout time.Duration, log *zap.SugaredLogger) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	// Stop scheduling new cron runs; the returned context is done once running jobs finish
+	cronDone := cronScheduler.Stop()
+
+	log.Info("Stopping HTTP server")
+	if err := app.ShutdownWithContext(ctx); err != nil {
+		log.Warnf("HTTP server shutdown did not complete cleanly: %v", err)
+	}
+
+	log.Info("Waiting for running cron jobs")
+	select {
+	case <-cronDone.Done():
+		log.Info("Cron jobs stopped")
+	case <-ctx.Done():
+		log.Warn("Timed out waiting for cron jobs to finish")
+	}
+
+	if publisher := services.GetPushPublisher(); publisher != nil {
+		if err := publisher.Close(ctx); err != nil {
+			log.Warnf("Push publisher close failed: %v", err)
+		}
+	}
+	if err := redis.Close(); err != nil {
+		log.Warnf("Redis close failed: %v", err)
+	}
+	if err := database.Close(); err != nil {
+		log.Warnf("Database close failed: %v", err)
 	}
 }
 
-func setupCronJobs(c *container.Container, log *zap.SugaredLogger) {
+func setupCronJobs(c *container.Container, log *zap.SugaredLogger) *cron.Cron {
 	loc, err := time.LoadLocation(constants.TimezoneIST)
 	if err != nil {
 		log.Fatalf("Failed to load timezone: %v", err)
@@ -169,8 +225,8 @@ func setupCronJobs(c *container.Container, log *zap.SugaredLogger) {
 		cron.WithSeconds(),
 	)
 
-	// Midnight cron job for streak processing
-	_, err = cronScheduler.AddFunc("0 0 0 * * *", func() {
+	// Hourly cron job for streak processing; each timezone is processed once after its local midnight
+	_, err = cronScheduler.AddFunc("0 0 * * * *", func() {
 		if err := c.CronService.RunDailyJob(context.Background()); err != nil {
 			log.Errorf("Daily job failed: %v", err)
 		} else {
@@ -181,9 +237,9 @@ func setupCronJobs(c *container.Container, log *zap.SugaredLogger) {
 		log.Fatalf("Failed to add daily cron job: %v", err)
 	}
 
-	// 10 PM IST cron job for streak reminders (push + in-app notifications)
-	// Reminds users who haven't logged today while they still have time
-	_, err = cronScheduler.AddFunc("0 0 22 * * *", func() {
+	// Hourly cron job for streak reminders (push + in-app notifications)
+	// Reminds users who haven't logged today once it is 10 PM in their timezone
+	_, err = cronScheduler.AddFunc("0 0 * * * *", func() {
 		if err := c.CronService.SendStreakReminders(context.Background()); err != nil {
 			log.Errorf("Streak reminder job failed: %v", err)
 		} else {
@@ -224,6 +280,56 @@ func setupCronJobs(c *container.Container, log *zap.SugaredLogger) {
 		log.Fatalf("Failed to add follow tombstone cleanup cron job: %v", err)
 	}
 
+	// 4:30 AM IST cron job for expired refresh token cleanup
+	_, err = cronScheduler.AddFunc("0 30 4 * * *", func() {
+		deleted, err := c.RefreshTokenService.CleanupExpired()
+		if err != nil {
+			log.Errorf("Refresh token cleanup failed: %v", err)
+		} else {
+			log.Infof("Refresh token cleanup completed, deleted %d tokens and sessions", deleted)
+		}
+	})
+	if err != nil {
+		log.Fatalf("Failed to add refresh token cleanup cron job: %v", err)
+	}
+
+	// 5 AM IST cron job for follow counter drift repair
+	_, err = cronScheduler.AddFunc("0 0 5 * * *", func() {
+		if err := c.CronService.RepairFollowCounters(context.Background()); err != nil {
+			log.Errorf("Follow counter repair job failed: %v", err)
+		} else {
+			log.Info("Follow counter repair job completed successfully")
+		}
+	})
+	if err != nil {
+		log.Fatalf("Failed to add follow counter repair cron job: %v", err)
+	}
+
+	// 5:30 AM IST cron job for orphaned story photo blob cleanup
+	_, err = cronScheduler.AddFunc("0 30 5 * * *", func() {
+		if err := c.CronService.CleanupOrphanedBlobs(context.Background()); err != nil {
+			log.Errorf("Orphan blob cleanup job failed: %v", err)
+		} else {
+			log.Info("Orphan blob cleanup job completed successfully")
+		}
+	})
+	if err != nil {
+		log.Fatalf("Failed to add orphan blob cleanup cron job: %v", err)
+	}
+
+	// Monday 9 AM IST cron job for the weekly progress digest email
+	_, err = cronScheduler.AddFunc("0 0 9 * * MON", func() {
+		if err := c.CronService.SendWeeklyDigests(context.Background()); err != nil {
+			log.Errorf("Weekly digest job failed: %v", err)
+		} else {
+			log.Info("Weekly digest job completed successfully")
+		}
+	})
+	if err != nil {
+		log.Fatalf("Failed to add weekly digest cron job: %v", err)
+	}
+
 	cronScheduler.Start()
 	log.Info("Cron jobs scheduled")
+	return cronScheduler
 }
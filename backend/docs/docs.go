@@ -846,7 +846,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "Retrieve all badges for a user by username (badges are always public)",
+                "description": "Retrieve all badges for a user by username. Private accounts are only visible to approved followers.",
                 "consumes": [
                     "application/json"
                 ],
@@ -886,6 +886,12 @@ const docTemplate = `{
                         "schema": {
                             "$ref": "#/definitions/dto.ErrorResponse"
                         }
+                    },
+                    "403": {
+                        "description": "Private account or blocked",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ErrorResponse"
+                        }
                     }
                 }
             }
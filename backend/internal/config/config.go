@@ -4,10 +4,13 @@ package config
 
 import (
 	"fmt"
+	"net/url"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/aman1117/backend/internal/constants"
 	"github.com/joho/godotenv"
 )
 
@@ -25,6 +28,18 @@ type Config struct {
 	// JWT configuration
 	JWT JWTConfig
 
+	// Login brute-force protection
+	Login LoginConfig
+
+	// Two-factor authentication (TOTP)
+	TwoFactor TwoFactorConfig
+
+	// Username rules
+	Username UsernameConfig
+
+	// Per route group rate limits
+	RateLimit RateLimitConfig
+
 	// Azure Blob Storage configuration
 	AzureStorage AzureStorageConfig
 
@@ -40,6 +55,12 @@ type Config struct {
 	// Follow system configuration
 	Follow FollowConfig
 
+	// Stories (activity photos) configuration
+	Story StoryConfig
+
+	// Notification retention configuration
+	Notification NotificationConfig
+
 	// Email configuration
 	Email EmailConfig
 
@@ -59,6 +80,13 @@ type ServerConfig struct {
 	FrontendURL  string
 	ReadTimeout  time.Duration
 	WriteTimeout time.Duration
+
+	// ShutdownTimeout bounds how long in-flight requests and running cron jobs get on SIGTERM
+	ShutdownTimeout time.Duration
+
+	// CORSAllowedOrigins are the browser origins allowed to call the API, from
+	// CORS_ALLOWED_ORIGINS. Defaults to "*" in development and to FrontendURL elsewhere.
+	CORSAllowedOrigins []string
 }
 
 // DatabaseConfig holds database connection configuration
@@ -91,11 +119,66 @@ type JWTConfig struct {
 	RefreshTokenTTL time.Duration
 }
 
+// LoginConfig holds per-account login lockout thresholds.
+// After MaxFailedAttempts consecutive wrong passwords within FailureWindow the account
+// is locked for LockoutDuration; a successful login resets the counter.
+type LoginConfig struct {
+	MaxFailedAttempts int           // Wrong passwords before lockout (default 5, 0 disables)
+	FailureWindow     time.Duration // How long failed attempts are remembered (default 15m)
+	LockoutDuration   time.Duration // How long the account stays locked (default 15m)
+}
+
+// TwoFactorConfig holds TOTP two-factor configuration
+type TwoFactorConfig struct {
+	EncryptionKey string // Key material for encrypting TOTP secrets (falls back to the JWT secret)
+	Issuer        string // Account issuer shown in authenticator apps
+}
+
+// UsernameConfig holds username rules
+type UsernameConfig struct {
+	Reserved []string // Extra reserved usernames, added to the built-in list
+}
+
+// RateLimitRule allows Max requests per key within any sliding Window
+type RateLimitRule struct {
+	Max    int
+	Window time.Duration
+}
+
+// RateLimitConfig holds the rate limit for each route group. Each rule is read from
+// RATE_LIMIT_<GROUP>_MAX and RATE_LIMIT_<GROUP>_WINDOW.
+type RateLimitConfig struct {
+	Auth         RateLimitRule // Login/register/refresh, per IP
+	Password     RateLimitRule // Password reset, per IP
+	API          RateLimitRule // General API, per user (IP when unauthenticated)
+	Upload       RateLimitRule // Photo uploads, per user
+	Follow       RateLimitRule // Follow actions, per user
+	Autocomplete RateLimitRule // Search-as-you-type, per user
+	Comment      RateLimitRule // Comment creation, per user
+	CommentLike  RateLimitRule // Comment likes, per user
+	PushTest     RateLimitRule // Test push notifications, per user
+}
+
+// ImageLimit caps an image upload's file size and pixel dimensions.
+// Dimensions are read from the image header, before the full decode.
+type ImageLimit struct {
+	MaxBytes  int64
+	MaxWidth  int
+	MaxHeight int
+}
+
 // AzureStorageConfig holds Azure Blob Storage configuration
 type AzureStorageConfig struct {
 	AccountName      string
 	ConnectionString string
 	ContainerName    string
+
+	// ProfilePicLimit caps profile picture uploads (PROFILE_PIC_MAX_SIZE_MB, PROFILE_PIC_MAX_WIDTH/HEIGHT)
+	ProfilePicLimit ImageLimit
+
+	// SASExpiry is the lifetime of the read-only SAS URLs minted for story photos.
+	// 0 returns plain blob URLs. Requires an account-key connection string.
+	SASExpiry time.Duration
 }
 
 // AzureServiceBusConfig holds Azure Service Bus configuration
@@ -110,6 +193,36 @@ type WebPushConfig struct {
 	VapidPrivateKey string
 	VapidSubject    string // Usually "mailto:you@example.com"
 	VapidKeyID      string // Identifier for key rotation support
+
+	// Retired keypairs by key ID. Subscriptions are bound to the key they were created
+	// with, so keep a rotated-out key here until its subscriptions have re-registered.
+	VapidPreviousKeys map[string]VapidKeyPair
+}
+
+// VapidKeyPair is a VAPID public/private keypair
+type VapidKeyPair struct {
+	PublicKey  string
+	PrivateKey string
+}
+
+// KeyPair returns the keypair a subscription created under keyID must be sent with.
+// Unknown or empty key IDs (subscriptions from before rotation) use the active key.
+func (c *WebPushConfig) KeyPair(keyID string) VapidKeyPair {
+	if keyID != "" && keyID != c.VapidKeyID {
+		if pair, ok := c.VapidPreviousKeys[keyID]; ok {
+			return pair
+		}
+	}
+	return VapidKeyPair{PublicKey: c.VapidPublicKey, PrivateKey: c.VapidPrivateKey}
+}
+
+// IsKnownKeyID reports whether keyID is the active key or a retained previous key
+func (c *WebPushConfig) IsKnownKeyID(keyID string) bool {
+	if keyID == c.VapidKeyID {
+		return true
+	}
+	_, ok := c.VapidPreviousKeys[keyID]
+	return ok
 }
 
 // PushWorkerConfig holds push worker configuration
@@ -127,11 +240,39 @@ type FollowConfig struct {
 	TombstoneRetentionDays int // Days to keep REMOVED edges (default 7)
 }
 
+// StoryConfig holds story (activity photo) window configuration.
+// Both windows are in IST calendar days relative to today (see ActivityPhotoService).
+type StoryConfig struct {
+	UploadWindowDays int // Days back a photo_date may be when uploading (default 7)
+	VisibleDays      int // Days back a photo_date stays visible to non-owners (default 7)
+	UploadsPerHour   int // Photo uploads allowed per user per hour (default 20)
+
+	// ImageFormat is the story output encoding: jpeg or webp (lossless). Load falls back to
+	// jpeg with a warning for formats without an encoder, such as avif.
+	ImageFormat string
+
+	// ImageLimit caps story photo uploads (STORY_MAX_SIZE_MB, STORY_MAX_WIDTH/HEIGHT)
+	ImageLimit ImageLimit
+}
+
+// NotificationConfig holds notification retention configuration.
+// Types listed in RetentionDaysByType use that retention whether read or not;
+// all other types fall back to the read/unread defaults.
+type NotificationConfig struct {
+	ReadRetentionDays   int            // Days to keep read notifications (default 30)
+	UnreadRetentionDays int            // Days to keep unread notifications (default 90)
+	RetentionDaysByType map[string]int // Per-type overrides, e.g. "new_follower=180,streak_at_risk=7"
+}
+
 // EmailConfig holds email service configuration
 type EmailConfig struct {
 	ResendAPIKey string
 	FromAddress  string
 	FromName     string
+
+	// RequireVerified blocks following, story uploads/replies and comments until the
+	// user verifies their email address
+	RequireVerified bool
 }
 
 // AxiomConfig holds Axiom logging configuration
@@ -163,6 +304,8 @@ func Load() (*Config, error) {
 			FrontendURL:  getEnvWithDefault("FRONTEND_BASE_URL", "http://localhost:5173"),
 			ReadTimeout:  getDurationFromEnv("SERVER_READ_TIMEOUT", 30*time.Second),
 			WriteTimeout: getDurationFromEnv("SERVER_WRITE_TIMEOUT", 30*time.Second),
+
+			ShutdownTimeout: getDurationFromEnv("SERVER_SHUTDOWN_TIMEOUT", 30*time.Second),
 		},
 
 		Database: DatabaseConfig{
@@ -185,14 +328,43 @@ func Load() (*Config, error) {
 
 		JWT: JWTConfig{
 			SecretKey:       getEnvRequired("JWT_SECRET_KEY"),
-			AccessTokenTTL:  getDurationMinutesFromEnv("TTL_ACCESS_TOKEN", 24*60), // 24 hours default
+			AccessTokenTTL:  getDurationMinutesFromEnv("TTL_ACCESS_TOKEN", 15), // Short-lived; clients renew with the refresh token
 			RefreshTokenTTL: getDurationMinutesFromEnv("TTL_REFRESH_TOKEN", 7*24*60),
 		},
 
+		Login: LoginConfig{
+			MaxFailedAttempts: getIntFromEnv("LOGIN_MAX_FAILED_ATTEMPTS", 5),
+			FailureWindow:     getDurationFromEnv("LOGIN_FAILURE_WINDOW", 15*time.Minute),
+			LockoutDuration:   getDurationFromEnv("LOGIN_LOCKOUT_DURATION", 15*time.Minute),
+		},
+
+		TwoFactor: TwoFactorConfig{
+			EncryptionKey: os.Getenv("TOTP_ENCRYPTION_KEY"),
+			Issuer:        getEnvWithDefault("TOTP_ISSUER", "Growth Tracker"),
+		},
+
+		Username: UsernameConfig{
+			Reserved: getListFromEnv("RESERVED_USERNAMES"),
+		},
+
+		RateLimit: RateLimitConfig{
+			Auth:         getRateLimitRuleFromEnv("AUTH", constants.RateLimitAuthMaxRequests, constants.RateLimitAuthWindow),
+			Password:     getRateLimitRuleFromEnv("PASSWORD", constants.RateLimitPasswordMaxRequests, constants.RateLimitPasswordWindow),
+			API:          getRateLimitRuleFromEnv("API", constants.RateLimitAPIMaxRequests, constants.RateLimitAPIWindow),
+			Upload:       getRateLimitRuleFromEnv("UPLOAD", constants.RateLimitUploadMaxRequests, constants.RateLimitUploadWindow),
+			Follow:       getRateLimitRuleFromEnv("FOLLOW", constants.RateLimitFollowMaxRequests, constants.RateLimitFollowWindow),
+			Autocomplete: getRateLimitRuleFromEnv("AUTOCOMPLETE", constants.RateLimitAutocompleteMaxRequests, constants.RateLimitAutocompleteWindow),
+			Comment:      getRateLimitRuleFromEnv("COMMENT", constants.RateLimitCommentMaxRequests, constants.RateLimitCommentWindow),
+			CommentLike:  getRateLimitRuleFromEnv("COMMENT_LIKE", constants.RateLimitCommentLikeMaxRequests, constants.RateLimitCommentLikeWindow),
+			PushTest:     getRateLimitRuleFromEnv("PUSH_TEST", constants.RateLimitPushTestMaxRequests, constants.RateLimitPushTestWindow),
+		},
+
 		AzureStorage: AzureStorageConfig{
 			AccountName:      os.Getenv("AZURE_STORAGE_ACCOUNT_NAME"),
 			ConnectionString: os.Getenv("AZURE_STORAGE_CONNECTION_STRING"),
 			ContainerName:    getEnvWithDefault("AZURE_STORAGE_CONTAINER", "profile-pictures"),
+			ProfilePicLimit:  getImageLimitFromEnv("PROFILE_PIC", constants.MaxProfilePicSize),
+			SASExpiry:        getDurationFromEnv("AZURE_STORAGE_SAS_EXPIRY", constants.DefaultStorySASExpiry),
 		},
 
 		AzureServiceBus: AzureServiceBusConfig{
@@ -205,6 +377,8 @@ func Load() (*Config, error) {
 			VapidPrivateKey: os.Getenv("VAPID_PRIVATE_KEY"),
 			VapidSubject:    getEnvWithDefault("VAPID_SUBJECT", "mailto:aman@amancodes.dev"),
 			VapidKeyID:      getEnvWithDefault("VAPID_KEY_ID", "default"),
+			// Format: "keyId=publicKey:privateKey,keyId2=publicKey:privateKey"
+			VapidPreviousKeys: getVapidKeysFromEnv("VAPID_PREVIOUS_KEYS"),
 		},
 
 		PushWorker: PushWorkerConfig{
@@ -220,10 +394,27 @@ func Load() (*Config, error) {
 			TombstoneRetentionDays: getIntFromEnv("FOLLOW_TOMBSTONE_RETENTION_DAYS", 7),
 		},
 
+		Story: StoryConfig{
+			UploadWindowDays: getIntFromEnv("STORY_UPLOAD_WINDOW_DAYS", 7),
+			VisibleDays:      getIntFromEnv("STORY_VISIBLE_DAYS", 7),
+			UploadsPerHour:   getIntFromEnv("STORY_UPLOADS_PER_HOUR", 20),
+			ImageFormat:      getEnvWithDefault("STORY_IMAGE_FORMAT", "jpeg"),
+			ImageLimit:       getImageLimitFromEnv("STORY", constants.MaxStoryPhotoSize),
+		},
+
+		Notification: NotificationConfig{
+			ReadRetentionDays:   getIntFromEnv("NOTIF_READ_RETENTION_DAYS", 30),
+			UnreadRetentionDays: getIntFromEnv("NOTIF_UNREAD_RETENTION_DAYS", 90),
+			RetentionDaysByType: getIntMapFromEnv("NOTIF_RETENTION_DAYS_BY_TYPE",
+				"new_follower=180,follow_request=180,follow_accepted=180,streak_at_risk=7"),
+		},
+
 		Email: EmailConfig{
 			ResendAPIKey: os.Getenv("RESEND_API_KEY"),
 			FromAddress:  getEnvWithDefault("EMAIL_FROM_ADDRESS", "noreply@example.com"),
 			FromName:     getEnvWithDefault("EMAIL_FROM_NAME", "Growth Tracker"),
+
+			RequireVerified: getBoolFromEnv("REQUIRE_VERIFIED_EMAIL", false),
 		},
 
 		Axiom: AxiomConfig{
@@ -238,10 +429,59 @@ func Load() (*Config, error) {
 		},
 	}
 
+	config.Story.ImageFormat = parseStoryImageFormat(config.Story.ImageFormat)
+
+	origins, err := parseCORSOrigins(getListFromEnv("CORS_ALLOWED_ORIGINS"), config.Server.FrontendURL, config.IsDevelopment())
+	if err != nil {
+		return nil, err
+	}
+	config.Server.CORSAllowedOrigins = origins
+
 	AppConfig = config
 	return config, nil
 }
 
+// parseStoryImageFormat normalizes STORY_IMAGE_FORMAT. Formats without an encoder in this
+// build (avif) or unknown names fall back to jpeg with a warning rather than failing startup.
+func parseStoryImageFormat(format string) string {
+	switch normalized := strings.ToLower(strings.TrimSpace(format)); normalized {
+	case "", "jpeg", "jpg":
+		return "jpeg"
+	case "webp":
+		return normalized
+	default:
+		fmt.Printf("Warning: STORY_IMAGE_FORMAT %q has no encoder available, falling back to jpeg\n", format)
+		return "jpeg"
+	}
+}
+
+// parseCORSOrigins validates the CORS allowlist. Each entry must be a bare origin
+// (scheme://host[:port]); "*" is only accepted in development. An empty list falls back
+// to "*" in development and to the frontend URL everywhere else.
+func parseCORSOrigins(origins []string, frontendURL string, development bool) ([]string, error) {
+	if len(origins) == 0 {
+		if development {
+			return []string{"*"}, nil
+		}
+		origins = []string{strings.TrimRight(frontendURL, "/")}
+	}
+
+	for _, origin := range origins {
+		if origin == "*" {
+			if !development {
+				return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS: \"*\" is only allowed in development")
+			}
+			continue
+		}
+		u, err := url.Parse(origin)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+			u.Path != "" || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+			return nil, fmt.Errorf("CORS_ALLOWED_ORIGINS: invalid origin %q (expected scheme://host[:port])", origin)
+		}
+	}
+	return origins, nil
+}
+
 // DSN returns the PostgreSQL connection string
 func (c *DatabaseConfig) DSN() string {
 	// If full URL is provided, use it
@@ -256,6 +496,13 @@ func (c *DatabaseConfig) DSN() string {
 	)
 }
 
+// MaxRequestBodyBytes is the largest request body the server accepts: the larger of the story
+// and profile picture upload limits, plus multipart overhead
+func (c *Config) MaxRequestBodyBytes() int {
+	maxImage := max(c.Story.ImageLimit.MaxBytes, c.AzureStorage.ProfilePicLimit.MaxBytes)
+	return int(maxImage) + constants.MultipartOverhead
+}
+
 // IsProduction returns true if running in production environment
 func (c *Config) IsProduction() bool {
 	return c.Env == "production"
@@ -312,6 +559,69 @@ func getDurationMinutesFromEnv(key string, defaultMinutes int) time.Duration {
 	return time.Duration(defaultMinutes) * time.Minute
 }
 
+// getIntMapFromEnv parses "key=int,key=int" pairs; malformed pairs are skipped
+func getIntMapFromEnv(key, defaultValue string) map[string]int {
+	result := make(map[string]int)
+	for _, pair := range strings.Split(getEnvWithDefault(key, defaultValue), ",") {
+		name, value, ok := strings.Cut(strings.TrimSpace(pair), "=")
+		if !ok {
+			continue
+		}
+		if intVal, err := strconv.Atoi(strings.TrimSpace(value)); err == nil {
+			result[strings.TrimSpace(name)] = intVal
+		}
+	}
+	return result
+}
+
+// getRateLimitRuleFromEnv reads RATE_LIMIT_<group>_MAX and RATE_LIMIT_<group>_WINDOW
+func getRateLimitRuleFromEnv(group string, defaultMax int, defaultWindow time.Duration) RateLimitRule {
+	return RateLimitRule{
+		Max:    getIntFromEnv("RATE_LIMIT_"+group+"_MAX", defaultMax),
+		Window: getDurationFromEnv("RATE_LIMIT_"+group+"_WINDOW", defaultWindow),
+	}
+}
+
+// getImageLimitFromEnv reads <prefix>_MAX_SIZE_MB, <prefix>_MAX_WIDTH and <prefix>_MAX_HEIGHT
+func getImageLimitFromEnv(prefix string, defaultMaxBytes int64) ImageLimit {
+	maxBytes := defaultMaxBytes
+	if mb := getIntFromEnv(prefix+"_MAX_SIZE_MB", 0); mb > 0 {
+		maxBytes = int64(mb) * 1024 * 1024
+	}
+	return ImageLimit{
+		MaxBytes:  maxBytes,
+		MaxWidth:  getIntFromEnv(prefix+"_MAX_WIDTH", constants.DefaultMaxImageDimension),
+		MaxHeight: getIntFromEnv(prefix+"_MAX_HEIGHT", constants.DefaultMaxImageDimension),
+	}
+}
+
+// getListFromEnv parses a comma-separated list, skipping empty entries
+func getListFromEnv(key string) []string {
+	var result []string
+	for _, item := range strings.Split(os.Getenv(key), ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+func getVapidKeysFromEnv(key string) map[string]VapidKeyPair {
+	result := make(map[string]VapidKeyPair)
+	for _, entry := range strings.Split(os.Getenv(key), ",") {
+		keyID, pair, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		public, private, ok := strings.Cut(pair, ":")
+		if !ok || public == "" || private == "" {
+			continue
+		}
+		result[strings.TrimSpace(keyID)] = VapidKeyPair{PublicKey: public, PrivateKey: private}
+	}
+	return result
+}
+
 func getBoolFromEnv(key string, defaultValue bool) bool {
 	value := os.Getenv(key)
 	if value == "" {
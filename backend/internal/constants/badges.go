@@ -1,30 +1,46 @@
 // Package constants defines all application-wide constants including badge definitions.
 package constants
 
-// Badge represents a streak badge definition
+// Badge families
+const (
+	BadgeFamilyStreak = "streak" // Threshold is the longest streak in days
+	BadgeFamilyHours  = "hours"  // Threshold is cumulative logged hours
+)
+
+// Badge represents a badge definition
 type Badge struct {
 	Key       string // Unique identifier
 	Name      string // Display name
 	Icon      string // Lucide icon name
 	Color     string // Hex color
-	Threshold int    // Days required to earn
+	Threshold int    // Days (streak family) or hours (hours family) required to earn
+	Family    string // BadgeFamilyStreak or BadgeFamilyHours
 }
 
 // Badge definitions ordered by threshold (ascending)
 var Badges = []Badge{
-	{Key: "first_step", Name: "First Step", Icon: "Footprints", Color: "#22c55e", Threshold: 1},
-	{Key: "spark_starter", Name: "Spark Starter", Icon: "Zap", Color: "#3b82f6", Threshold: 7},
-	{Key: "flame_keeper", Name: "Flame Keeper", Icon: "Flame", Color: "#f97316", Threshold: 15},
-	{Key: "iron_will", Name: "Iron Will", Icon: "Shield", Color: "#64748b", Threshold: 30},
-	{Key: "diamond_mind", Name: "Diamond Mind", Icon: "Gem", Color: "#06b6d4", Threshold: 90},
-	{Key: "titan", Name: "Titan", Icon: "Crown", Color: "#a855f7", Threshold: 120},
-	{Key: "legendary", Name: "Legendary", Icon: "Star", Color: "#eab308", Threshold: 360},
+	{Key: "first_step", Name: "First Step", Icon: "Footprints", Color: "#22c55e", Threshold: 1, Family: BadgeFamilyStreak},
+	{Key: "spark_starter", Name: "Spark Starter", Icon: "Zap", Color: "#3b82f6", Threshold: 7, Family: BadgeFamilyStreak},
+	{Key: "flame_keeper", Name: "Flame Keeper", Icon: "Flame", Color: "#f97316", Threshold: 15, Family: BadgeFamilyStreak},
+	{Key: "iron_will", Name: "Iron Will", Icon: "Shield", Color: "#64748b", Threshold: 30, Family: BadgeFamilyStreak},
+	{Key: "diamond_mind", Name: "Diamond Mind", Icon: "Gem", Color: "#06b6d4", Threshold: 90, Family: BadgeFamilyStreak},
+	{Key: "titan", Name: "Titan", Icon: "Crown", Color: "#a855f7", Threshold: 120, Family: BadgeFamilyStreak},
+	{Key: "legendary", Name: "Legendary", Icon: "Star", Color: "#eab308", Threshold: 360, Family: BadgeFamilyStreak},
+}
+
+// HourBadges are earned for cumulative logged hours, ordered by threshold (ascending)
+var HourBadges = []Badge{
+	{Key: "hours_100", Name: "Centurion", Icon: "Clock", Color: "#14b8a6", Threshold: 100, Family: BadgeFamilyHours},
+	{Key: "hours_500", Name: "Deep Worker", Icon: "Hourglass", Color: "#8b5cf6", Threshold: 500, Family: BadgeFamilyHours},
+	{Key: "hours_1000", Name: "Thousand Hours", Icon: "Timer", Color: "#ec4899", Threshold: 1000, Family: BadgeFamilyHours},
+	{Key: "hours_5000", Name: "Master Craft", Icon: "Medal", Color: "#f59e0b", Threshold: 5000, Family: BadgeFamilyHours},
+	{Key: "hours_10000", Name: "Ten Thousand", Icon: "Trophy", Color: "#ef4444", Threshold: 10000, Family: BadgeFamilyHours},
 }
 
 // BadgeMap provides quick lookup by key
 var BadgeMap = func() map[string]Badge {
 	m := make(map[string]Badge)
-	for _, b := range Badges {
+	for _, b := range GetAllBadges() {
 		m[b.Key] = b
 	}
 	return m
@@ -41,6 +57,17 @@ func GetEligibleBadgeKeys(longestStreak int) []string {
 	return keys
 }
 
+// GetEligibleHourBadgeKeys returns all hour badge keys the user qualifies for based on total logged hours
+func GetEligibleHourBadgeKeys(totalHours float64) []string {
+	var keys []string
+	for _, badge := range HourBadges {
+		if totalHours >= float64(badge.Threshold) {
+			keys = append(keys, badge.Key)
+		}
+	}
+	return keys
+}
+
 // GetBadgeByKey returns a badge by its key, or nil if not found
 func GetBadgeByKey(key string) *Badge {
 	if badge, ok := BadgeMap[key]; ok {
@@ -49,12 +76,14 @@ func GetBadgeByKey(key string) *Badge {
 	return nil
 }
 
-// GetAllBadges returns all badge definitions
+// GetAllBadges returns all badge definitions, streak family first
 func GetAllBadges() []Badge {
-	return Badges
+	all := make([]Badge, 0, len(Badges)+len(HourBadges))
+	all = append(all, Badges...)
+	return append(all, HourBadges...)
 }
 
-// GetNextBadge returns the next badge to earn based on longest streak, or nil if all earned
+// GetNextBadge returns the next streak badge to earn based on longest streak, or nil if all earned
 func GetNextBadge(longestStreak int) *Badge {
 	for _, badge := range Badges {
 		if longestStreak < badge.Threshold {
@@ -13,6 +13,9 @@ const (
 // Timezone constants
 const (
 	TimezoneIST = "Asia/Kolkata"
+
+	// StreakReminderLocalHour is the local hour from which streak reminders are sent
+	StreakReminderLocalHour = 22
 )
 
 // Validation constants
@@ -20,18 +23,40 @@ const (
 	UsernameMinLength = 3
 	UsernameMaxLength = 20
 	PasswordMinLength = 8
+	PasswordMaxBytes  = 72 // bcrypt rejects anything longer
 	BioMaxLength      = 150
 	NoteMaxLength     = 500
 )
 
+// Username change constants
+const (
+	UsernameChangeCooldown = 30 * 24 * time.Hour // Minimum time between username changes
+	UsernameReleaseGrace   = 30 * 24 * time.Hour // How long an old username stays reserved for its previous owner
+)
+
 // File upload constants
 const (
 	MaxProfilePicSize = 5 * 1024 * 1024 // 5MB
+	MaxStoryPhotoSize = 5 * 1024 * 1024 // 5MB
+
+	// Room on top of the largest image for the multipart boundaries, part headers and form fields
+	MultipartOverhead = 1024 * 1024 // 1MB
+
+	// Profile pictures are square: full image and thumbnail edge in pixels, encoder quality
+	ProfilePicFullSize  = 1080
+	ProfilePicThumbSize = 200
+	ProfilePicQuality   = 85
+
+	// Largest width or height accepted before decoding; a 5MB file can still decode to
+	// hundreds of MB of pixels
+	DefaultMaxImageDimension = 8192
 )
 
 // Activity constants
 const (
 	MaxDailyHours = 24.0
+
+	MaxBulkActivities = 50 // Activities accepted in one bulk log request
 )
 
 // Custom tile constants
@@ -57,6 +82,52 @@ const (
 	// Resend verification rate limiting
 	VerifyResendCooldown = 2 * time.Minute
 	VerifyResendPrefix   = "verify_cooldown:"
+
+	// Email change tokens; only the most recent request per user is honoured
+	EmailChangeTokenPrefix   = "email_change:"
+	EmailChangePendingPrefix = "email_change_pending:"
+	EmailChangeTokenTTL      = 1 * time.Hour
+)
+
+// Refresh token constants
+const (
+	RefreshTokenByteLen   = 32
+	TokenVersionPrefix    = "token_ver:" // token_ver:{userID} -> current token version
+	TokenVersionCacheTTL  = 10 * time.Minute
+	RefreshTokenRetention = 24 * time.Hour // Expired refresh tokens are purged after this grace period
+)
+
+// Session constants
+const (
+	SessionStatusPrefix    = "session_active:" // session_active:{sessionID} -> "1" active, "0" revoked
+	SessionStatusCacheTTL  = 10 * time.Minute
+	SessionSeenPrefix      = "session_seen:" // Throttles last_seen_at writes to one per interval
+	SessionSeenInterval    = 5 * time.Minute
+	SessionUserAgentMaxLen = 255
+)
+
+// Two-factor constants
+const (
+	TOTPSkewSteps       = 1            // Accept codes one 30s step either side of now
+	TOTPUsedPrefix      = "totp_used:" // totp_used:{userID}:{step} -> set once a code has been used
+	BackupCodeCount     = 10
+	BackupCodeByteLen   = 5 // 5 random bytes -> 10 hex chars, shown as xxxxx-xxxxx
+	TwoFactorCodeMaxLen = 32
+)
+
+// Login lockout constants; thresholds come from config.LoginConfig
+const (
+	LoginFailuresPrefix = "login_failures:" // login_failures:{userID} -> consecutive failed attempts
+	LoginLockPrefix     = "login_lock:"     // login_lock:{userID} -> set while the account is locked
+)
+
+// RateLimitPrefix namespaces the Redis sliding-window sets: ratelimit:{group}:{key}
+const RateLimitPrefix = "ratelimit:"
+
+// Request tracing constants
+const (
+	TraceIDHeader       = "X-Request-ID" // Read from the request if present, always set on the response
+	TraceIDHeaderMaxLen = 64             // Longer incoming IDs are replaced with a generated one
 )
 
 // Likes cache constants
@@ -65,6 +136,13 @@ const (
 	LikesCacheTTL    = 4 * time.Hour
 )
 
+// Last logged cache constants
+const (
+	LastLoggedCachePrefix = "last_logged:" // last_logged:{userID} -> YYYY-MM-DD, or "none" if never logged
+	LastLoggedCacheTTL    = 24 * time.Hour
+	LastLoggedNone        = "none"
+)
+
 // Story likes cache constants
 const (
 	StoryLikeCountCachePrefix = "story_like_cnt:" // Cache for like counts: story_like_cnt:{photoID}
@@ -72,6 +150,149 @@ const (
 	StoryLikeCacheTTL         = 5 * time.Minute   // Short TTL for like counts
 )
 
+// Story window defaults (overridable via config)
+const (
+	DefaultStoryUploadWindowDays = 7
+	DefaultStoryVisibleDays      = 7
+	DefaultStoryUploadsPerHour   = 20 // Per-user photo upload budget (token bucket, refills evenly)
+
+	StoryUploadLimiterPruneSize = 10000 // Idle upload buckets are pruned once this many users are tracked
+)
+
+// Story photo blob constants
+const (
+	ActivityPhotoBlobPrefix = "activity-photos/" // Blob name prefix of every story photo and thumbnail
+	OrphanBlobGracePeriod   = 24 * time.Hour     // Blobs younger than this may belong to an upload still being saved
+
+	DefaultStorySASExpiry = 1 * time.Hour // Lifetime of signed story photo URLs (overridable via config)
+
+	BlobBatchDeleteMaxSize = 256 // Azure Blob Batch accepts at most 256 sub-requests
+)
+
+// Blob upload retry constants
+const (
+	BlobUploadMaxAttempts    = 3                      // Total tries per blob, including the first
+	BlobUploadAttemptTimeout = 30 * time.Second       // Deadline for a single upload attempt
+	BlobUploadRetryBaseDelay = 500 * time.Millisecond // Backoff before the second attempt; doubles after each retry
+)
+
+// Story archive constants
+const (
+	StoryArchiveMaxDays = 90 // Max days (inclusive) per archive request
+)
+
+// StreakMilestones are the streak lengths that trigger a milestone notification
+var StreakMilestones = []int{7, 30, 50, 100, 150, 200, 365, 500, 1000}
+
+// IsStreakMilestone reports whether a streak length is a milestone
+func IsStreakMilestone(count int) bool {
+	for _, m := range StreakMilestones {
+		if m == count {
+			return true
+		}
+	}
+	return false
+}
+
+// StreakMilestoneAggregateType is the activity type label used for the any-activity streak
+const StreakMilestoneAggregateType = "daily"
+
+// Analytics constants
+const (
+	WeekAnalyticsMaxOffset = 104 // Max weeks back (~2 years) reachable via week_offset
+
+	WeekdayInsightDefaultLookbackDays = 90  // Default window for the weekday insight
+	WeekdayInsightMaxLookbackDays     = 365 // Max window for the weekday insight
+
+	WeeklyDigestTopActivities = 3 // Activities listed in the weekly digest email
+
+	TrendingActivitiesDays  = 7  // Window for /trending/activities, including today
+	TrendingActivitiesLimit = 10 // Activity types returned by /trending/activities
+)
+
+// Push delivery stats constants
+const (
+	PushStatsMaxDays = 30 // Max (and default) window for /me/push/stats
+)
+
+// Activity export constants
+const (
+	ActivityExportFlushEvery = 500 // CSV rows buffered before flushing to the client
+)
+
+// User search constants
+const (
+	UserSearchDefaultPageSize = 20
+	UserSearchMaxPageSize     = 50
+)
+
+// Activity note search constants
+const (
+	ActivitySearchMinQueryLen     = 2   // Shortest note search query accepted
+	ActivitySearchMaxQueryLen     = 100 // Longest note search query accepted
+	ActivitySearchDefaultPageSize = 20
+	ActivitySearchMaxPageSize     = 50
+)
+
+// Streak series constants
+const (
+	StreakSeriesMaxDays = 366 // Max days (inclusive) per streak series request
+)
+
+// Streak leaderboard constants
+const (
+	StreakLeaderboardDefaultLimit = 20
+	StreakLeaderboardMaxLimit     = 100
+)
+
+// Profile view constants
+const (
+	ProfileViewThrottle           = time.Hour // Repeat views within this window don't refresh viewed_at
+	ProfileViewersDefaultPageSize = 20
+	ProfileViewersMaxPageSize     = 50
+)
+
+// Admin cron log constants
+const (
+	CronLogsDefaultLimit = 50
+	CronLogsMaxLimit     = 200
+)
+
+// Verification request constants
+const (
+	VerificationJustificationMinLen     = 20
+	VerificationJustificationMaxLen     = 1000
+	VerificationReviewNoteMaxLen        = 500
+	VerificationRequestsDefaultPageSize = 20
+	VerificationRequestsMaxPageSize     = 50
+)
+
+// Recent search constants
+const (
+	RecentSearchMaxPerUser = 20 // Rows kept per user; suggestions show at most 10
+	RecentSearchMaxAgeDays = 90 // Rows older than this are dropped on the next save
+)
+
+// Autocomplete constants
+const (
+	AutocompleteDefaultLimit = 12
+	AutocompleteMaxLimit     = 20
+)
+
+// Mutual-based suggestion constants
+const (
+	MutualSuggestionsDefaultLimit = 10
+	MutualSuggestionsMaxLimit     = 30
+)
+
+// Story reply constants
+const (
+	StoryReplyMaxLength     = 500           // Max characters per reply
+	StoryReplyRateLimit     = 5             // Max replies per sender per photo within the window
+	StoryReplyRateWindow    = 1 * time.Hour // Rolling window for the per-photo reply limit
+	StoryReplyPreviewLength = 100           // Characters shown in the notification preview
+)
+
 // Notification constants
 const (
 	// Redis keys
@@ -92,6 +313,9 @@ const (
 	// Rate limiting
 	NotifMaxPerHour = 50 // Max notifications per user per hour
 
+	// Max notifications replayed from the database when a WebSocket resumes from a seq
+	NotifReplayMaxCount = 100
+
 	// WebSocket settings
 	WSMaxConnsPerUser = 5                // Max concurrent WebSocket connections per user
 	WSPingInterval    = 30 * time.Second // Heartbeat ping interval
@@ -112,17 +336,29 @@ const (
 	ErrCodeInvalidPassword    = "INVALID_PASSWORD"
 	ErrCodeTokenExpired       = "TOKEN_EXPIRED"
 	ErrCodeTokenGenFailed     = "TOKEN_GENERATION_FAILED"
+	ErrCodeInvalidRefresh     = "INVALID_REFRESH_TOKEN"
+	ErrCodeAccountLocked      = "ACCOUNT_LOCKED"
+	ErrCodeSessionNotFound    = "SESSION_NOT_FOUND"
+	ErrCodeTwoFactorRequired  = "TWO_FACTOR_REQUIRED"
+	ErrCodeInvalidTwoFactor   = "INVALID_TWO_FACTOR_CODE"
+	ErrCodeTwoFactorEnabled   = "TWO_FACTOR_ALREADY_ENABLED"
+	ErrCodeTwoFactorDisabled  = "TWO_FACTOR_NOT_ENABLED"
+	ErrCodeTwoFactorNoSecret  = "TWO_FACTOR_NOT_ENROLLED"
 
 	// Validation errors
 	ErrCodeInvalidRequest     = "INVALID_REQUEST"
 	ErrCodeMissingFields      = "MISSING_FIELDS"
 	ErrCodeInvalidDate        = "INVALID_DATE"
 	ErrCodeInvalidDateRange   = "INVALID_DATE_RANGE"
+	ErrCodeInvalidTimezone    = "INVALID_TIMEZONE"
 	ErrCodeInvalidActivity    = "INVALID_ACTIVITY"
 	ErrCodeInvalidUsernameLen = "INVALID_USERNAME_LENGTH"
 	ErrCodeInvalidUsernameFmt = "INVALID_USERNAME_FORMAT"
 	ErrCodePasswordTooShort   = "PASSWORD_TOO_SHORT"
 	ErrCodeWeakPassword       = "WEAK_PASSWORD"
+	ErrCodePasswordTooLong    = "PASSWORD_TOO_LONG"
+	ErrCodeCommonPassword     = "COMMON_PASSWORD"
+	ErrCodePasswordSameAsUser = "PASSWORD_SAME_AS_ACCOUNT"
 	ErrCodePasswordMismatch   = "PASSWORD_MISMATCH"
 	ErrCodeBioTooLong         = "BIO_TOO_LONG"
 	ErrCodeHoursExceeded      = "HOURS_EXCEEDED"
@@ -134,12 +370,24 @@ const (
 	ErrCodeUserNotFound         = "USER_NOT_FOUND"
 	ErrCodeUserExists           = "USER_EXISTS"
 	ErrCodeUsernameTaken        = "USERNAME_TAKEN"
+	ErrCodeUsernameCooldown     = "USERNAME_CHANGE_COOLDOWN"
+	ErrCodeUsernameReserved     = "USERNAME_RESERVED"
 	ErrCodeNotAuthorized        = "NOT_AUTHORIZED"
+	ErrCodeAdminRequired        = "ADMIN_REQUIRED"
 	ErrCodeAccountPrivate       = "ACCOUNT_PRIVATE"
 	ErrCodeStreakNotFound       = "STREAK_NOT_FOUND"
 	ErrCodeNotificationNotFound = "NOTIFICATION_NOT_FOUND"
+	ErrCodeActivityNotFound     = "ACTIVITY_NOT_FOUND"
+	ErrCodeCustomTileNotFound   = "CUSTOM_TILE_NOT_FOUND"
 	ErrCodeConflict             = "CONFLICT"
 
+	// Verification request errors
+	ErrCodeVerificationPending         = "VERIFICATION_REQUEST_PENDING"
+	ErrCodeVerificationRequestNotFound = "VERIFICATION_REQUEST_NOT_FOUND"
+
+	// Admin errors
+	ErrCodeUnknownCronJob = "UNKNOWN_CRON_JOB"
+
 	// Follow errors
 	ErrCodeCannotFollowSelf    = "CANNOT_FOLLOW_SELF"
 	ErrCodeAlreadyFollowing    = "ALREADY_FOLLOWING"
@@ -148,6 +396,11 @@ const (
 	ErrCodeNoFollowRequest     = "NO_FOLLOW_REQUEST"
 	ErrCodeFollowLimitExceeded = "FOLLOW_LIMIT_EXCEEDED"
 	ErrCodeDailyLimitExceeded  = "DAILY_LIMIT_EXCEEDED"
+	ErrCodeBlocked             = "USER_BLOCKED"
+	ErrCodeCannotBlockSelf     = "CANNOT_BLOCK_SELF"
+	ErrCodeNotBlocked          = "NOT_BLOCKED"
+	ErrCodeCannotCloseFriend   = "CANNOT_CLOSE_FRIEND"
+	ErrCodeNotCloseFriend      = "NOT_CLOSE_FRIEND"
 
 	// Configuration errors
 	ErrCodeConfigError = "CONFIG_ERROR"
@@ -174,6 +427,12 @@ const (
 	ErrCodeInvalidVerifyToken   = "INVALID_VERIFY_TOKEN"
 	ErrCodeAlreadyVerified      = "ALREADY_VERIFIED"
 	ErrCodeVerifyResendCooldown = "VERIFY_RESEND_COOLDOWN"
+	ErrCodeEmailNotVerified     = "EMAIL_NOT_VERIFIED"
+
+	// Email change errors
+	ErrCodeEmailTaken              = "EMAIL_TAKEN"
+	ErrCodeSameEmail               = "SAME_EMAIL"
+	ErrCodeInvalidEmailChangeToken = "INVALID_EMAIL_CHANGE_TOKEN"
 )
 
 // HTTP status messages
@@ -182,18 +441,36 @@ const (
 	MsgUserCreated       = "User created successfully."
 	MsgUsernameUpdated   = "Username updated successfully"
 	MsgPrivacyUpdated    = "Privacy setting updated"
+	MsgTimezoneUpdated   = "Timezone updated"
 	MsgBioUpdated        = "Bio updated successfully"
 	MsgPasswordChanged   = "Password changed successfully"
+	MsgAccountDeleted    = "Account deleted successfully"
+	MsgDeactivated       = "Account deactivated"
+	MsgReactivated       = "Account reactivated"
 	MsgPasswordReset     = "Password updated successfully. You can now log in with your new password."
 	MsgPasswordResetSent = "If an account exists with this email, a password reset link has been sent."
 	MsgActivityUpdated   = "Activity updated successfully"
+	MsgActivityDeleted   = "Activity deleted successfully"
 	MsgTileConfigSaved   = "Tile configuration saved successfully"
+	MsgTileOrderSaved    = "Tile order saved successfully"
 	MsgProfilePicDeleted = "Profile picture deleted successfully"
 
 	// Email verification messages
 	MsgEmailVerified         = "Your email has been verified successfully."
 	MsgVerificationEmailSent = "Verification email sent. Please check your inbox."
 	MsgVerificationPending   = "Please verify your email address to access all features."
+
+	// Email change messages
+	MsgEmailChangeSent = "Verification email sent to your new address. Please check your inbox."
+	MsgEmailChanged    = "Your email address has been updated."
+
+	// Session messages
+	MsgLoggedOut           = "Logged out successfully"
+	MsgLoggedOutEverywhere = "Logged out of all devices"
+	MsgSessionRevoked      = "Device logged out"
+
+	// Two-factor messages
+	MsgTwoFactorDisabled = "Two-factor authentication disabled"
 )
 
 // Rate limiting constants
@@ -221,6 +498,10 @@ const (
 	// Autocomplete endpoints - lenient limits for rapid typing
 	RateLimitAutocompleteWindow      = 1 * time.Minute
 	RateLimitAutocompleteMaxRequests = 60 // 60 requests per minute (1 per second average)
+
+	// Test push endpoint - each call sends a real push
+	RateLimitPushTestWindow      = 1 * time.Minute
+	RateLimitPushTestMaxRequests = 3
 )
 
 // Follow system constants
@@ -232,6 +513,9 @@ const (
 	FollowCountCacheTTL    = 5 * time.Minute // Cache TTL for follow counts
 	FollowRelCacheTTL      = 5 * time.Minute // Cache TTL for relationship states
 
+	// Pub/Sub channel per user carrying live follow-count changes to their WebSockets
+	FollowCountChannelPrefix = "follow_cnt:channel:"
+
 	// Follow limits (defaults, can be overridden by config)
 	DefaultMaxFollowsPerMinute    = 60
 	DefaultMaxFollowsPerDay       = 5000
@@ -241,11 +525,34 @@ const (
 	// Pagination
 	FollowListDefaultLimit = 20
 	FollowListMaxLimit     = 100
+	FollowedByDefaultLimit = 3 // "Followed by alice, bob and N others" preview
+
+	// Batch operations
+	FollowBatchMaxSize = 100 // Max IDs per batch request (lookup, bulk accept/decline)
+
+	// Counter drift repair (cron)
+	FollowCounterRepairBatchSize = 500                // Counters scanned per page
+	FollowCounterStaleAfter      = 7 * 24 * time.Hour // Counters not updated within this window are re-verified
+	FollowCounterDriftThreshold  = 0                  // Max tolerated |cached - actual| before a counter is repaired
+)
+
+// Streak freeze constants
+const (
+	StreakFreezeEarnInterval = 30 // One freeze is earned for every N consecutive days
+	StreakFreezeMaxBalance   = 3  // Freezes stop accruing once the balance reaches this
 )
 
 // Rate limiting error codes
 const (
 	ErrCodeRateLimitExceeded = "RATE_LIMIT_EXCEEDED"
+	ErrCodeUploadRateLimited = "UPLOAD_RATE_LIMITED"
+)
+
+// Upload error codes
+const (
+	ErrCodeStorageUnavailable = "STORAGE_UNAVAILABLE"
+	ErrCodeInvalidImage       = "INVALID_IMAGE"
+	ErrCodeImageTooLarge      = "IMAGE_TOO_LARGE"
 )
 
 // Comment system constants
@@ -300,6 +607,7 @@ const (
 	MsgRateLimitAutocomplete = "Too many search requests. Please slow down."
 	MsgRateLimitComment      = "Too many comments. Please slow down."
 	MsgRateLimitCommentLike  = "Too many like actions. Please slow down."
+	MsgRateLimitPushTest     = "Too many test notifications. Please wait a minute."
 )
 
 // Allowed file extensions for profile pictures
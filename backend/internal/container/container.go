@@ -16,60 +16,77 @@ type Container struct {
 	Config *config.Config
 
 	// Repositories
-	UserRepo           *repository.UserRepository
-	ActivityRepo       *repository.ActivityRepository
-	StreakRepo         *repository.StreakRepository
-	TileConfigRepo     *repository.TileConfigRepository
-	LikeRepo           *repository.LikeRepository
-	BadgeRepo          *repository.BadgeRepository
-	NotificationRepo   *repository.NotificationRepository
-	PushRepo           *repository.PushRepository
-	FollowRepo         *repository.FollowRepository
-	CronJobLogRepo     *repository.CronJobLogRepository
-	ActivityPhotoRepo  *repository.ActivityPhotoRepository
-	RecentSearchRepo   *repository.RecentSearchRepository
-	CommentRepo        *repository.CommentRepository
-	CommentLikeRepo    *repository.CommentLikeRepository
-	CommentMentionRepo *repository.CommentMentionRepository
-	CommentDedupeRepo  *repository.CommentDedupeRepository
+	UserRepo                *repository.UserRepository
+	ActivityRepo            *repository.ActivityRepository
+	StreakRepo              *repository.StreakRepository
+	TileConfigRepo          *repository.TileConfigRepository
+	CustomActivityTypeRepo  *repository.CustomActivityTypeRepository
+	LikeRepo                *repository.LikeRepository
+	BadgeRepo               *repository.BadgeRepository
+	NotificationRepo        *repository.NotificationRepository
+	PushRepo                *repository.PushRepository
+	FollowRepo              *repository.FollowRepository
+	CronJobLogRepo          *repository.CronJobLogRepository
+	ActivityPhotoRepo       *repository.ActivityPhotoRepository
+	RecentSearchRepo        *repository.RecentSearchRepository
+	CommentRepo             *repository.CommentRepository
+	CommentLikeRepo         *repository.CommentLikeRepository
+	CommentMentionRepo      *repository.CommentMentionRepository
+	CommentDedupeRepo       *repository.CommentDedupeRepository
+	ProfileViewRepo         *repository.ProfileViewRepository
+	VerificationRequestRepo *repository.VerificationRequestRepository
+	RefreshTokenRepo        *repository.RefreshTokenRepository
+	SessionRepo             *repository.SessionRepository
+	TwoFactorRepo           *repository.TwoFactorRepository
 
 	// Services
-	AuthService              *services.AuthService
-	ProfileService           *services.ProfileService
-	ActivityService          *services.ActivityService
-	StreakService            *services.StreakService
-	AnalyticsService         *services.AnalyticsService
-	TileConfigService        *services.TileConfigService
-	EmailService             *services.EmailService
-	CronService              *services.CronService
-	BlobService              *services.BlobService
-	BadgeService             *services.BadgeService
-	NotificationService      *services.NotificationService
-	FollowService            *services.FollowService
-	ActivityPhotoService     *services.ActivityPhotoService
-	SearchSuggestionsService *services.SearchSuggestionsService
-	CommentService           *services.CommentService
+	AuthService                *services.AuthService
+	ProfileService             *services.ProfileService
+	ActivityService            *services.ActivityService
+	StreakService              *services.StreakService
+	AnalyticsService           *services.AnalyticsService
+	TileConfigService          *services.TileConfigService
+	EmailService               *services.EmailService
+	CronService                *services.CronService
+	ProfilePicService          *services.ProfilePicService
+	BadgeService               *services.BadgeService
+	NotificationService        *services.NotificationService
+	FollowService              *services.FollowService
+	ActivityPhotoService       *services.ActivityPhotoService
+	SearchSuggestionsService   *services.SearchSuggestionsService
+	CommentService             *services.CommentService
+	ExportService              *services.ExportService
+	ProfileViewService         *services.ProfileViewService
+	VerificationRequestService *services.VerificationRequestService
+	RefreshTokenService        *services.RefreshTokenService
+	SessionService             *services.SessionService
+	TwoFactorService           *services.TwoFactorService
 
 	// Handlers
-	TokenService             *handlers.TokenService
-	AuthHandler              *handlers.AuthHandler
-	ProfileHandler           *handlers.ProfileHandler
-	ActivityHandler          *handlers.ActivityHandler
-	StreakHandler            *handlers.StreakHandler
-	AnalyticsHandler         *handlers.AnalyticsHandler
-	TileConfigHandler        *handlers.TileConfigHandler
-	PasswordResetHandler     *handlers.PasswordResetHandler
-	VerificationHandler      *handlers.VerificationHandler
-	BlobHandler              *handlers.BlobHandler
-	LikeHandler              *handlers.LikeHandler
-	BadgeHandler             *handlers.BadgeHandler
-	NotificationHandler      *handlers.NotificationHandler
-	NotificationWSHandler    *handlers.NotificationWSHandler
-	PushHandler              *handlers.PushHandler
-	FollowHandler            *handlers.FollowHandler
-	ActivityPhotoHandler     *handlers.ActivityPhotoHandler
-	SearchSuggestionsHandler *handlers.SearchSuggestionsHandler
-	CommentHandler           *handlers.CommentHandler
+	TokenService               *handlers.TokenService
+	AuthHandler                *handlers.AuthHandler
+	ProfileHandler             *handlers.ProfileHandler
+	ActivityHandler            *handlers.ActivityHandler
+	StreakHandler              *handlers.StreakHandler
+	AnalyticsHandler           *handlers.AnalyticsHandler
+	TileConfigHandler          *handlers.TileConfigHandler
+	PasswordResetHandler       *handlers.PasswordResetHandler
+	VerificationHandler        *handlers.VerificationHandler
+	BlobHandler                *handlers.BlobHandler
+	LikeHandler                *handlers.LikeHandler
+	BadgeHandler               *handlers.BadgeHandler
+	NotificationHandler        *handlers.NotificationHandler
+	NotificationWSHandler      *handlers.NotificationWSHandler
+	PushHandler                *handlers.PushHandler
+	FollowHandler              *handlers.FollowHandler
+	ActivityPhotoHandler       *handlers.ActivityPhotoHandler
+	SearchSuggestionsHandler   *handlers.SearchSuggestionsHandler
+	CommentHandler             *handlers.CommentHandler
+	ExportHandler              *handlers.ExportHandler
+	VerificationRequestHandler *handlers.VerificationRequestHandler
+	SessionHandler             *handlers.SessionHandler
+	TwoFactorHandler           *handlers.TwoFactorHandler
+	AdminHandler               *handlers.AdminHandler
 
 	// Router
 	Router *routes.Router
@@ -84,6 +101,7 @@ func New(cfg *config.Config, db *gorm.DB) (*Container, error) {
 	c.ActivityRepo = repository.NewActivityRepository(db)
 	c.StreakRepo = repository.NewStreakRepository(db)
 	c.TileConfigRepo = repository.NewTileConfigRepository(db)
+	c.CustomActivityTypeRepo = repository.NewCustomActivityTypeRepository(db)
 	c.LikeRepo = repository.NewLikeRepository(db)
 	c.BadgeRepo = repository.NewBadgeRepository(db)
 	c.NotificationRepo = repository.NewNotificationRepository(db)
@@ -96,19 +114,23 @@ func New(cfg *config.Config, db *gorm.DB) (*Container, error) {
 	c.CommentLikeRepo = repository.NewCommentLikeRepository(db)
 	c.CommentMentionRepo = repository.NewCommentMentionRepository(db)
 	c.CommentDedupeRepo = repository.NewCommentDedupeRepository(db)
+	c.ProfileViewRepo = repository.NewProfileViewRepository(db)
+	c.VerificationRequestRepo = repository.NewVerificationRequestRepository(db)
+	c.RefreshTokenRepo = repository.NewRefreshTokenRepository(db)
+	c.SessionRepo = repository.NewSessionRepository(db)
+	c.TwoFactorRepo = repository.NewTwoFactorRepository(db)
 
 	// Initialize services
-	c.AuthService = services.NewAuthService(c.UserRepo)
 	c.ProfileService = services.NewProfileService(c.UserRepo, c.FollowRepo)
-	c.StreakService = services.NewStreakService(c.StreakRepo)
+	c.StreakService = services.NewStreakService(c.StreakRepo, c.ActivityRepo, c.UserRepo)
 	c.NotificationService = services.NewNotificationService(c.NotificationRepo)
-	c.ActivityService = services.NewActivityService(c.ActivityRepo, c.StreakService, c.UserRepo, c.FollowRepo, c.NotificationService)
+	c.BadgeService = services.NewBadgeService(c.BadgeRepo, c.UserRepo, c.ActivityRepo, c.NotificationService)
+	c.ActivityService = services.NewActivityService(c.ActivityRepo, c.StreakService, c.UserRepo, c.FollowRepo, c.NotificationService, c.BadgeService, c.CustomActivityTypeRepo)
 	c.AnalyticsService = services.NewAnalyticsService(c.ActivityRepo, c.StreakRepo, c.UserRepo)
-	c.TileConfigService = services.NewTileConfigService(c.TileConfigRepo, c.UserRepo, c.ActivityPhotoRepo)
-	c.BlobService = services.NewBlobService(c.UserRepo, &cfg.AzureStorage)
-	c.BadgeService = services.NewBadgeService(c.BadgeRepo, c.UserRepo)
 	c.FollowService = services.NewFollowService(c.FollowRepo, c.UserRepo, &cfg.Follow)
 	c.SearchSuggestionsService = services.NewSearchSuggestionsService(c.RecentSearchRepo)
+	c.ProfileViewService = services.NewProfileViewService(c.ProfileViewRepo, c.UserRepo)
+	c.VerificationRequestService = services.NewVerificationRequestService(c.VerificationRequestRepo, c.UserRepo, c.NotificationService)
 	c.CommentService = services.NewCommentService(
 		c.CommentRepo,
 		c.CommentLikeRepo,
@@ -128,27 +150,48 @@ func New(cfg *config.Config, db *gorm.DB) (*Container, error) {
 			c.FollowRepo,
 			c.NotificationService,
 			&cfg.AzureStorage,
+			&cfg.Story,
 		)
 		if err == nil {
 			c.ActivityPhotoService = photoSvc
 		}
 	}
 
+	// Initialize profile picture service (optional - requires blob storage)
+	if cfg.AzureStorage.ConnectionString != "" {
+		profilePicSvc, err := services.NewProfilePicService(c.UserRepo, &cfg.AzureStorage)
+		if err == nil {
+			c.ProfilePicService = profilePicSvc
+		}
+	}
+
+	// Tile config service uses the optional photo service to delete blobs when purging a custom tile
+	c.TileConfigService = services.NewTileConfigService(c.TileConfigRepo, c.UserRepo, c.ActivityPhotoRepo, c.ActivityRepo, c.StreakService, c.ActivityPhotoService)
+
+	// Export service uses the optional photo service to sign exported photo URLs
+	c.ExportService = services.NewExportService(c.UserRepo, c.ActivityRepo, c.StreakRepo, c.BadgeRepo, c.FollowRepo, c.NotificationRepo, c.ActivityPhotoRepo, c.ActivityPhotoService)
+
 	// Initialize email service (optional - uses SMTP fallback for local dev)
 	emailSvc, err := services.NewEmailService(&cfg.Email, cfg.Server.FrontendURL)
 	if err == nil {
 		c.EmailService = emailSvc
 	}
 
+	// Auth service uses the optional photo and email services for account deletion and email changes
+	c.TwoFactorService = services.NewTwoFactorService(c.TwoFactorRepo, c.UserRepo, &cfg.TwoFactor, cfg.JWT.SecretKey)
+	c.AuthService = services.NewAuthService(c.UserRepo, c.ActivityPhotoService, c.EmailService, &cfg.Login, c.TwoFactorService, &cfg.Username)
+
 	// Initialize cron service
-	c.CronService = services.NewCronService(c.UserRepo, c.StreakRepo, c.CronJobLogRepo, c.StreakService, c.EmailService, c.NotificationService)
+	c.CronService = services.NewCronService(c.UserRepo, c.StreakRepo, c.CronJobLogRepo, c.StreakService, c.EmailService, c.NotificationService, c.FollowService, &cfg.Notification, c.AnalyticsService, c.BadgeRepo, c.BadgeService, c.ActivityPhotoService)
 
-	// Initialize token service
-	c.TokenService = handlers.NewTokenService(&cfg.JWT)
+	// Initialize token services
+	c.SessionService = services.NewSessionService(c.SessionRepo)
+	c.RefreshTokenService = services.NewRefreshTokenService(c.RefreshTokenRepo, c.UserRepo, c.SessionService, cfg.JWT.RefreshTokenTTL)
+	c.TokenService = handlers.NewTokenService(&cfg.JWT, c.RefreshTokenService, c.SessionService)
 
 	// Initialize handlers
-	c.AuthHandler = handlers.NewAuthHandler(c.AuthService, c.TokenService, c.ProfileService, c.EmailService)
-	c.ProfileHandler = handlers.NewProfileHandler(c.ProfileService, c.AuthService, c.FollowService, c.StreakService, c.SearchSuggestionsService)
+	c.AuthHandler = handlers.NewAuthHandler(c.AuthService, c.TokenService, c.RefreshTokenService, c.SessionService, c.ProfileService, c.EmailService)
+	c.ProfileHandler = handlers.NewProfileHandler(c.ProfileService, c.AuthService, c.FollowService, c.StreakService, c.SearchSuggestionsService, c.ProfileViewService)
 	c.ActivityHandler = handlers.NewActivityHandler(c.ActivityService, c.AuthService, c.ProfileService)
 	c.StreakHandler = handlers.NewStreakHandler(c.StreakService, c.AuthService, c.ProfileService, c.BadgeService)
 	c.AnalyticsHandler = handlers.NewAnalyticsHandler(c.AnalyticsService, c.AuthService, c.ProfileService)
@@ -156,20 +199,22 @@ func New(cfg *config.Config, db *gorm.DB) (*Container, error) {
 	c.PasswordResetHandler = handlers.NewPasswordResetHandler(c.AuthService, c.EmailService)
 	c.VerificationHandler = handlers.NewVerificationHandler(c.UserRepo, c.EmailService)
 	c.LikeHandler = handlers.NewLikeHandler(c.LikeRepo, c.AuthService, c.ProfileService, c.NotificationService)
-	c.BadgeHandler = handlers.NewBadgeHandler(c.BadgeService, c.AuthService)
+	c.BadgeHandler = handlers.NewBadgeHandler(c.BadgeService, c.AuthService, c.FollowService)
 	c.NotificationHandler = handlers.NewNotificationHandler(c.NotificationService)
 	c.NotificationWSHandler = handlers.NewNotificationWSHandler(c.NotificationService, c.TokenService)
 	c.PushHandler = handlers.NewPushHandler(c.PushRepo, cfg)
 	c.FollowHandler = handlers.NewFollowHandler(c.FollowService, c.UserRepo, c.NotificationService)
 	c.SearchSuggestionsHandler = handlers.NewSearchSuggestionsHandler(c.SearchSuggestionsService)
 	c.CommentHandler = handlers.NewCommentHandler(c.CommentService, c.ProfileService, c.AuthService)
+	c.ExportHandler = handlers.NewExportHandler(c.ExportService)
+	c.VerificationRequestHandler = handlers.NewVerificationRequestHandler(c.VerificationRequestService)
+	c.SessionHandler = handlers.NewSessionHandler(c.SessionService)
+	c.TwoFactorHandler = handlers.NewTwoFactorHandler(c.TwoFactorService)
+	c.AdminHandler = handlers.NewAdminHandler(c.CronService)
 
-	// Initialize blob handler (optional)
-	if cfg.AzureStorage.ConnectionString != "" {
-		blobHandler, err := handlers.NewBlobHandler(c.BlobService, &cfg.AzureStorage)
-		if err == nil {
-			c.BlobHandler = blobHandler
-		}
+	// Initialize blob handler (optional - requires profile picture service)
+	if c.ProfilePicService != nil {
+		c.BlobHandler = handlers.NewBlobHandler(c.ProfilePicService)
 	}
 
 	// Initialize activity photo handler (optional - requires photo service)
@@ -197,7 +242,15 @@ func New(cfg *config.Config, db *gorm.DB) (*Container, error) {
 		c.ActivityPhotoHandler,
 		c.SearchSuggestionsHandler,
 		c.CommentHandler,
+		c.ExportHandler,
+		c.VerificationRequestHandler,
+		c.SessionHandler,
+		c.TwoFactorHandler,
+		c.AdminHandler,
 		c.TokenService,
+		c.UserRepo,
+		cfg.Email.RequireVerified,
+		&cfg.RateLimit,
 	)
 
 	return c, nil
@@ -63,29 +63,77 @@ func AutoMigrate() error {
 		return fmt.Errorf("database not initialized")
 	}
 
-	return db.AutoMigrate(
+	return Migrate(db)
+}
+
+// Migrate creates or updates the tables for all models on conn
+func Migrate(conn *gorm.DB) error {
+	if err := conn.AutoMigrate(
 		&models.User{},
 		&models.Activity{},
 		&models.Streak{},
+		&models.StreakFreeze{},
 		&models.TileConfig{},
 		&models.Like{},
 		&models.UserBadge{},
 		&models.Notification{},
 		&models.NotificationDedupe{},
+		&models.NotificationSequence{},
 		&models.PushSubscription{},
 		&models.PushPreference{},
 		&models.PushDeliveryLog{},
+		&models.PushDeadLetter{},
+		&models.PushSendClaim{},
 		&models.FollowEdgeByFollower{},
 		&models.FollowEdgeByFollowee{},
 		&models.FollowCounter{},
+		&models.UserBlock{},
+		&models.CloseFriend{},
 		&models.CronJobLog{},
 		&models.ActivityPhoto{},
 		&models.StoryView{},
+		&models.ProfileView{},
+		&models.VerificationRequest{},
+		&models.Session{},
+		&models.RefreshToken{},
+		&models.TwoFactorBackupCode{},
+		&models.UsernameHistory{},
+		&models.StoryReply{},
 		&models.Comment{},
 		&models.CommentLike{},
 		&models.CommentMention{},
 		&models.CommentDedupe{},
-	)
+		&models.CustomActivityType{},
+	); err != nil {
+		return err
+	}
+
+	if err := backfillCustomActivityTypes(conn); err != nil {
+		return err
+	}
+	return archiveOrphanedCustomActivities(conn)
+}
+
+// backfillCustomActivityTypes creates the custom activity types of custom tiles saved
+// before the table existed. Existing rows are left alone, so it is safe on every start.
+func backfillCustomActivityTypes(conn *gorm.DB) error {
+	return conn.Exec(`INSERT INTO custom_activity_types (user_id, tile_id, name, icon, color, created_at, updated_at)
+		SELECT tc.user_id, tile->>'id', tile->>'name', tile->>'icon', tile->>'color', NOW(), NOW()
+		FROM tile_configs tc
+		CROSS JOIN LATERAL jsonb_array_elements(tc.config->'customTiles') AS tile
+		WHERE jsonb_typeof(tc.config->'customTiles') = 'array'
+		ON CONFLICT (user_id, tile_id) DO NOTHING`).Error
+}
+
+// archiveOrphanedCustomActivities archives activities of custom tiles deleted before
+// archiving existed, so they are left out of analytics like tiles deleted since
+func archiveOrphanedCustomActivities(conn *gorm.DB) error {
+	return conn.Exec(`UPDATE activities a SET archived_at = NOW()
+		WHERE a.name LIKE 'custom:%' AND a.archived_at IS NULL
+		AND NOT EXISTS (
+			SELECT 1 FROM custom_activity_types t
+			WHERE t.user_id = a.user_id AND a.name = 'custom:' || t.tile_id
+		)`).Error
 }
 
 // Close closes the database connection
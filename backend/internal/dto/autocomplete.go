@@ -47,3 +47,18 @@ type SearchSuggestionsResponse struct {
 	Recent   []SearchSuggestionUser `json:"recent"`
 	Trending []SearchSuggestionUser `json:"trending"`
 }
+
+// MutualSuggestionUser represents a suggested user followed by people the viewer follows
+// @Description Suggested user with the number of mutual connections
+type MutualSuggestionUser struct {
+	SearchSuggestionUser
+	IsPrivate   bool `json:"isPrivate" example:"false"`
+	MutualCount int  `json:"mutualCount" example:"4"`
+}
+
+// MutualSuggestionsResponse represents the "people you may know" response
+// @Description Friend-of-friend suggestions ranked by mutual connections
+type MutualSuggestionsResponse struct {
+	Success     bool                   `json:"success" example:"true"`
+	Suggestions []MutualSuggestionUser `json:"suggestions"`
+}
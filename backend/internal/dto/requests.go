@@ -19,6 +19,29 @@ type RegisterRequest struct {
 type LoginRequest struct {
 	Identifier string `json:"identifier" example:"john_doe"` // Can be email or username
 	Password   string `json:"password" example:"SecurePass123"`
+
+	// TwoFactorCode is the authenticator code or a backup code; required when the
+	// account has two-factor enabled (login first returns TWO_FACTOR_REQUIRED)
+	TwoFactorCode string `json:"two_factor_code,omitempty" example:"123456"`
+}
+
+// RefreshTokenRequest carries a refresh token for /auth/refresh and /auth/logout
+// @Description Refresh token request
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" example:"9f86d081884c7d65..."`
+}
+
+// TwoFactorCodeRequest carries an authenticator code
+// @Description Two-factor code
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// DisableTwoFactorRequest confirms turning two-factor off
+// @Description Password and a current authenticator or backup code
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" example:"SecurePass123"`
+	Code     string `json:"code" example:"123456"`
 }
 
 // ForgotPasswordRequest represents the forgot password request body
@@ -41,6 +64,19 @@ type VerifyEmailRequest struct {
 	Token string `json:"token" example:"abc123def456"`
 }
 
+// ChangeEmailRequest represents the email change request body
+// @Description Request an email change; a confirmation link is sent to the new address
+type ChangeEmailRequest struct {
+	NewEmail string `json:"new_email" example:"new@example.com"`
+	Password string `json:"password" example:"MyPass123"`
+}
+
+// ConfirmEmailChangeRequest represents the email change confirmation body
+// @Description Confirm an email change with the token from the confirmation email
+type ConfirmEmailChangeRequest struct {
+	Token string `json:"token" example:"abc123def456"`
+}
+
 // ChangePasswordRequest represents the change password request body
 // @Description Change password for authenticated user
 type ChangePasswordRequest struct {
@@ -48,6 +84,18 @@ type ChangePasswordRequest struct {
 	NewPassword     string `json:"new_password" example:"NewPass123"`
 }
 
+// DeleteAccountRequest represents the account deletion request body
+// @Description Permanently delete the authenticated user's account
+type DeleteAccountRequest struct {
+	Password string `json:"password" example:"MyPass123"`
+}
+
+// DeactivateAccountRequest represents the account deactivation request body
+// @Description Temporarily hide the authenticated user's account
+type DeactivateAccountRequest struct {
+	Password string `json:"password" example:"MyPass123"`
+}
+
 // ==================== User Profile DTOs ====================
 
 // UpdateUsernameRequest represents the username update request body
@@ -80,6 +128,20 @@ type CreateActivityRequest struct {
 	Note     *string             `json:"note,omitempty" example:"Worked on Go project"`
 }
 
+// BulkActivityItem is one activity in a bulk log request
+type BulkActivityItem struct {
+	Name  models.ActivityName `json:"name" example:"study"`
+	Hours float32             `json:"hours" example:"2.5"`
+	Note  *string             `json:"note,omitempty" example:"Worked on Go project"`
+}
+
+// BulkActivitiesRequest represents a request to log several activities for one date
+// @Description Create or update several activities on one date
+type BulkActivitiesRequest struct {
+	Date       string             `json:"date" example:"2026-01-04"` // Format: YYYY-MM-DD
+	Activities []BulkActivityItem `json:"activities"`
+}
+
 // GetActivitiesRequest represents the request to fetch activities
 // @Description Get activities for a date range
 type GetActivitiesRequest struct {
@@ -111,7 +173,30 @@ type GetStreakRequest struct {
 // @Description Get weekly analytics starting from a specific Monday
 type GetWeekAnalyticsRequest struct {
 	Username  string `json:"username" example:"john_doe"`
-	WeekStart string `json:"week_start" example:"2025-12-30"` // Format: YYYY-MM-DD (Monday of the week)
+	WeekStart string `json:"week_start" example:"2025-12-30"` // Format: YYYY-MM-DD (Monday of the week); ignored when ?week_offset= is set
+}
+
+// GetMonthAnalyticsRequest represents the request for monthly analytics
+// @Description Get analytics for a calendar month
+type GetMonthAnalyticsRequest struct {
+	Username string `json:"username" example:"john_doe"`
+	Year     int    `json:"year" example:"2026"`
+	Month    int    `json:"month" example:"1"` // 1-12
+}
+
+// GetYearAnalyticsRequest represents the request for yearly analytics
+// @Description Get analytics for a calendar year
+type GetYearAnalyticsRequest struct {
+	Username string `json:"username" example:"john_doe"`
+	Year     int    `json:"year" example:"2026"`
+}
+
+// GetWeekdayAnalyticsRequest represents the request for the weekday insight
+// @Description Get average hours per weekday over a lookback window
+type GetWeekdayAnalyticsRequest struct {
+	Username        string `json:"username" example:"john_doe"`
+	LookbackDays    *int   `json:"lookback_days,omitempty" example:"90"` // 1-365, defaults to 90 when omitted
+	IncludeZeroDays bool   `json:"include_zero_days" example:"false"`    // Count days with no activity as 0 hours
 }
 
 // ==================== User Search DTOs ====================
@@ -120,6 +205,8 @@ type GetWeekAnalyticsRequest struct {
 // @Description Search for users by username
 type SearchUsersRequest struct {
 	Username string `json:"username" example:"john"`
+	Page     int    `json:"page,omitempty" example:"1"`       // Default 1
+	PageSize int    `json:"page_size,omitempty" example:"20"` // Default 20, max 50
 }
 
 // ==================== Tile Config DTOs ====================
@@ -134,6 +221,11 @@ type SaveTileConfigRequest struct {
 	Config models.JSONB `json:"config"`
 }
 
+// ReorderTilesRequest represents the request to reorder dashboard tiles
+type ReorderTilesRequest struct {
+	Order []string `json:"order"`
+}
+
 // ==================== Like DTOs ====================
 
 // LikeDayRequest represents the request to like/unlike a user's day
@@ -204,6 +296,13 @@ type RelationshipLookupRequest struct {
 	TargetIDs []uint `json:"target_ids" example:"1,2,3"`
 }
 
+// BatchFollowRequestActionRequest represents a bulk accept/decline of follow requests
+// @Description Accept or decline multiple incoming follow requests at once
+type BatchFollowRequestActionRequest struct {
+	Action       string `json:"action" example:"accept"` // accept, decline
+	RequesterIDs []uint `json:"requester_ids" example:"1,2,3"`
+}
+
 // FollowListRequest represents a request to get follow lists
 // @Description Get paginated followers/following list
 type FollowListRequest struct {
@@ -211,6 +310,44 @@ type FollowListRequest struct {
 	Limit  int    `json:"limit,omitempty" example:"20"`
 }
 
+// ==================== Story DTOs ====================
+
+// StoryReplyRequest represents a reply to a story photo
+// @Description Reply to a story photo (sent privately to the owner)
+type StoryReplyRequest struct {
+	Text string `json:"text" example:"Nice run!"`
+}
+
+// StoryReactionRequest represents an emoji reaction to a story photo
+// @Description Story reaction (one of ❤️ 🔥 👏 💪 😍 🎉; defaults to ❤️)
+type StoryReactionRequest struct {
+	Emoji string `json:"emoji" example:"🔥"`
+}
+
+// UpdateTimezoneRequest represents the timezone update request body
+// @Description Timezone update request
+type UpdateTimezoneRequest struct {
+	Timezone string `json:"timezone" example:"America/New_York"`
+}
+
+// UpdateGhostModeRequest represents the ghost mode update request body
+// @Description Story ghost mode update request
+type UpdateGhostModeRequest struct {
+	Enabled bool `json:"enabled" example:"true"`
+}
+
+// UpdateWeeklyDigestRequest represents the weekly digest email setting request body
+// @Description Weekly digest email setting
+type UpdateWeeklyDigestRequest struct {
+	Enabled bool `json:"enabled" example:"false"`
+}
+
+// UpdateProfileViewPrivacyRequest represents the profile view privacy request body
+// @Description Hide your visits from other users' profile viewer lists
+type UpdateProfileViewPrivacyRequest struct {
+	Hidden bool `json:"hidden" example:"true"`
+}
+
 // ==================== Comment DTOs ====================
 
 // CreateCommentRequest represents the request to create a comment on a day
@@ -230,3 +367,18 @@ type CreateReplyRequest struct {
 type EditCommentRequest struct {
 	Body string `json:"body" example:"Updated comment text"`
 }
+
+// ==================== Verification Request DTOs ====================
+
+// SubmitVerificationRequest represents a request for the verified badge
+// @Description Ask an admin to verify your account
+type SubmitVerificationRequest struct {
+	Justification string `json:"justification" example:"I run a public study group with 5k members and share my daily logs here."`
+}
+
+// ReviewVerificationRequest represents an admin decision on a verification request
+// @Description Approve or deny a pending verification request
+type ReviewVerificationRequest struct {
+	Approve bool    `json:"approve" example:"true"`
+	Note    *string `json:"note,omitempty" example:"Confirmed via linked community page"`
+}
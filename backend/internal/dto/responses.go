@@ -15,6 +15,22 @@ type SuccessResponse struct {
 	Message string `json:"message,omitempty" example:"Operation completed successfully"`
 }
 
+// ActivityUpdatedResponse represents the result of logging an activity
+// @Description Activity saved, with the day's streak and any badges it unlocked
+type ActivityUpdatedResponse struct {
+	Success bool       `json:"success" example:"true"`
+	Message string     `json:"message,omitempty" example:"Activity updated successfully"`
+	Streak  *StreakDTO `json:"streak,omitempty"`
+}
+
+// BulkActivitiesResponse represents the result of logging several activities for a day
+// @Description Activities saved, with the day's streak and any badges they unlocked
+type BulkActivitiesResponse struct {
+	Success    bool          `json:"success" example:"true"`
+	Activities []ActivityDTO `json:"activities"`
+	Streak     *StreakDTO    `json:"streak,omitempty"`
+}
+
 // ErrorResponse represents a generic error response
 // @Description Generic error response
 type ErrorResponse struct {
@@ -40,6 +56,13 @@ type LoginResponse struct {
 	TokenType   string `json:"token_type" example:"Bearer"`
 	ExpiresAt   string `json:"expires_at" example:"2026-01-05T12:00:00Z"`
 	ExpiresIn   int    `json:"expires_in" example:"86400"` // seconds
+
+	// IsDeactivated tells the client to offer reactivation after login
+	IsDeactivated bool `json:"is_deactivated,omitempty" example:"false"`
+
+	// RefreshToken is exchanged at /auth/refresh for a new access token
+	RefreshToken     string `json:"refresh_token,omitempty" example:"9f86d081884c7d65..."`
+	RefreshExpiresAt string `json:"refresh_expires_at,omitempty" example:"2026-01-12T12:00:00Z"`
 }
 
 // TokenValidationResponse represents the token validation response
@@ -106,6 +129,13 @@ type UsernameUpdateResponse struct {
 	NewUsername string `json:"new_username" example:"new_username"`
 }
 
+// UsernameCooldownResponse is returned when the username was changed too recently
+// @Description Username change on cooldown
+type UsernameCooldownResponse struct {
+	ErrorResponse
+	NextAllowedAt string `json:"next_allowed_at" example:"2026-02-04T10:00:00Z"`
+}
+
 // PrivacyResponse represents the privacy setting response
 // @Description Privacy setting result
 type PrivacyResponse struct {
@@ -147,6 +177,38 @@ type ActivitiesResponse struct {
 	Data    []ActivityDTO `json:"data"`
 }
 
+// ProfileViewersResponse represents a page of users who viewed the caller's profile
+// @Description Paginated list of profile viewers, most recent first
+type ProfileViewersResponse struct {
+	Success  bool                   `json:"success" example:"true"`
+	Viewers  []models.ProfileViewer `json:"viewers"`
+	Total    int64                  `json:"total" example:"8"`
+	Page     int                    `json:"page" example:"1"`
+	PageSize int                    `json:"page_size" example:"20"`
+	HasMore  bool                   `json:"has_more" example:"false"`
+}
+
+// UserSearchResponse represents a page of user search results
+// @Description Paginated list of users matching a username search
+type UserSearchResponse struct {
+	Success  bool      `json:"success" example:"true"`
+	Data     []UserDTO `json:"data"`
+	Page     int       `json:"page" example:"1"`
+	PageSize int       `json:"page_size" example:"20"`
+	HasMore  bool      `json:"has_more" example:"true"`
+}
+
+// ActivitySearchResponse represents a paginated activity note search result
+// @Description Paginated list of activities whose note matches the query
+type ActivitySearchResponse struct {
+	Success    bool          `json:"success" example:"true"`
+	Activities []ActivityDTO `json:"activities"`
+	Total      int64         `json:"total" example:"12"`
+	Page       int           `json:"page" example:"1"`
+	PageSize   int           `json:"page_size" example:"20"`
+	HasMore    bool          `json:"has_more" example:"false"`
+}
+
 // ==================== Streak DTOs ====================
 
 // StreakDTO represents streak data for API responses
@@ -166,6 +228,78 @@ type StreakResponse struct {
 	Data    StreakDTO `json:"data"`
 }
 
+// StreakFreezeDTO represents a user's streak freeze balance
+// @Description Streak freeze balance
+type StreakFreezeDTO struct {
+	Available   int     `json:"available" example:"2"`
+	MaxBalance  int     `json:"max_balance" example:"3"`
+	LastUsedAt  *string `json:"last_used_at,omitempty" example:"2026-01-05T08:30:00Z"`
+	LastUsedFor *string `json:"last_used_for,omitempty" example:"2026-01-04"`
+}
+
+// StreakFreezeResponse represents the streak freeze response
+// @Description Streak freeze balance response
+type StreakFreezeResponse struct {
+	Success bool            `json:"success" example:"true"`
+	Data    StreakFreezeDTO `json:"data"`
+}
+
+// AccountDeletionSummary describes what was removed when an account was deleted
+// @Description Per-table row counts removed by account deletion
+type AccountDeletionSummary struct {
+	RowsDeleted      map[string]int64 `json:"rows_deleted"`
+	CountersAdjusted int64            `json:"counters_adjusted" example:"12"`
+	BlobsScheduled   int              `json:"blobs_scheduled" example:"4"`
+}
+
+// DeleteAccountResponse represents the account deletion response
+// @Description Account deletion result
+type DeleteAccountResponse struct {
+	Success bool                   `json:"success" example:"true"`
+	Message string                 `json:"message" example:"Account deleted successfully"`
+	Data    AccountDeletionSummary `json:"data"`
+}
+
+// StreakSeriesPoint represents the streak on a single day
+// @Description Daily streak data point
+type StreakSeriesPoint struct {
+	Date    string `json:"date" example:"2026-01-04"`
+	Current int    `json:"current" example:"7"`
+	Longest int    `json:"longest" example:"30"`
+}
+
+// StreakSeriesResponse represents a daily streak series
+// @Description Streak series response
+type StreakSeriesResponse struct {
+	Success bool                `json:"success" example:"true"`
+	Data    []StreakSeriesPoint `json:"data"`
+}
+
+// StreakLeaderboardEntryDTO represents a followee's position on the streak leaderboard
+// @Description Streak leaderboard entry
+type StreakLeaderboardEntryDTO struct {
+	Rank          int     `json:"rank" example:"1"`
+	UserID        uint    `json:"user_id" example:"42"`
+	Username      string  `json:"username" example:"johndoe"`
+	ProfilePic    *string `json:"profile_pic"`
+	IsVerified    bool    `json:"is_verified" example:"false"`
+	CurrentStreak int     `json:"current_streak" example:"12"`
+}
+
+// StreakLeaderboardResponse represents the streak leaderboard response
+// @Description Streak leaderboard among followed users
+type StreakLeaderboardResponse struct {
+	Success bool                        `json:"success" example:"true"`
+	Data    []StreakLeaderboardEntryDTO `json:"data"`
+}
+
+// ActivityStreaksResponse represents per-activity streaks
+// @Description Streaks keyed by activity name
+type ActivityStreaksResponse struct {
+	Success bool                               `json:"success" example:"true"`
+	Data    map[models.ActivityName]StreakInfo `json:"data"`
+}
+
 // ==================== Analytics DTOs ====================
 
 // DayActivityBreakdown represents a single activity in a day
@@ -210,11 +344,90 @@ type WeekAnalyticsResponse struct {
 	PercentageChange      float32           `json:"percentage_change" example:"11.84"`
 	PercentageVsCurrent   float32           `json:"percentage_vs_current" example:"0"`
 	IsCurrentWeek         bool              `json:"is_current_week" example:"true"`
+	WeekStart             string            `json:"week_start" example:"2025-12-29"`
 	Streak                StreakInfo        `json:"streak"`
 	DailyBreakdown        []DayAnalytics    `json:"daily_breakdown"`
 	ActivitySummary       []ActivitySummary `json:"activity_summary"`
 }
 
+// BestDay represents the day with the most logged hours in a period
+// @Description Best day in a period
+type BestDay struct {
+	Date       string  `json:"date" example:"2026-01-14"`
+	TotalHours float32 `json:"total_hours" example:"14.5"`
+}
+
+// MonthlyTotal represents total hours logged in a single month
+// @Description Monthly total within a year
+type MonthlyTotal struct {
+	Month      int     `json:"month" example:"1"`
+	TotalHours float32 `json:"total_hours" example:"180.5"`
+	ActiveDays int     `json:"active_days" example:"22"`
+}
+
+// MonthAnalyticsResponse represents the monthly analytics response
+// @Description Monthly analytics data
+type MonthAnalyticsResponse struct {
+	Success         bool              `json:"success" example:"true"`
+	Year            int               `json:"year" example:"2026"`
+	Month           int               `json:"month" example:"1"`
+	IsCurrentMonth  bool              `json:"is_current_month" example:"true"`
+	DaysCounted     int               `json:"days_counted" example:"16"` // Elapsed days (partial for the current month)
+	TotalHours      float32           `json:"total_hours" example:"180.5"`
+	ActiveDays      int               `json:"active_days" example:"14"`
+	BestDay         *BestDay          `json:"best_day,omitempty"`
+	ActivitySummary []ActivitySummary `json:"activity_summary"`
+}
+
+// YearAnalyticsResponse represents the yearly analytics response
+// @Description Yearly analytics data
+type YearAnalyticsResponse struct {
+	Success         bool              `json:"success" example:"true"`
+	Year            int               `json:"year" example:"2026"`
+	IsCurrentYear   bool              `json:"is_current_year" example:"true"`
+	DaysCounted     int               `json:"days_counted" example:"16"` // Elapsed days (partial for the current year)
+	TotalHours      float32           `json:"total_hours" example:"1820.5"`
+	ActiveDays      int               `json:"active_days" example:"250"`
+	BestDay         *BestDay          `json:"best_day,omitempty"`
+	MonthlyTotals   []MonthlyTotal    `json:"monthly_totals"`
+	ActivitySummary []ActivitySummary `json:"activity_summary"`
+}
+
+// TrendingActivityDTO represents one activity type's platform-wide totals
+type TrendingActivityDTO struct {
+	Name       models.ActivityName `json:"name" example:"study"`
+	TotalHours float64             `json:"total_hours" example:"1520.5"`
+	EntryCount int64               `json:"entry_count" example:"840"`
+	UserCount  int64               `json:"user_count" example:"212"`
+}
+
+// TrendingActivitiesResponse represents the trending activity types response
+// @Description Most-logged activity types across public users over a recent window
+type TrendingActivitiesResponse struct {
+	Success    bool                  `json:"success" example:"true"`
+	Days       int                   `json:"days" example:"7"`
+	Since      string                `json:"since" example:"2026-01-01"`
+	Activities []TrendingActivityDTO `json:"activities"`
+}
+
+// WeekdayAverage represents average hours logged on a weekday
+// @Description Average hours for a weekday
+type WeekdayAverage struct {
+	Weekday      string  `json:"weekday" example:"Tue"`
+	AverageHours float32 `json:"average_hours" example:"9.25"`
+	SampleCount  int     `json:"sample_count" example:"12"` // Days that contributed to the average
+}
+
+// WeekdayAnalyticsResponse represents the weekday insight response
+// @Description Average hours per weekday, Monday first
+type WeekdayAnalyticsResponse struct {
+	Success         bool             `json:"success" example:"true"`
+	LookbackDays    int              `json:"lookback_days" example:"90"`
+	IncludeZeroDays bool             `json:"include_zero_days" example:"false"`
+	BestWeekday     string           `json:"best_weekday,omitempty" example:"Tue"`
+	Weekdays        []WeekdayAverage `json:"weekdays"`
+}
+
 // ==================== Tile Config DTOs ====================
 
 // TileConfigResponse represents the tile configuration response
@@ -223,6 +436,13 @@ type TileConfigResponse struct {
 	Data    models.JSONB `json:"data"`
 }
 
+// DeleteCustomTileResponse represents the result of deleting a custom tile
+type DeleteCustomTileResponse struct {
+	Success           bool  `json:"success"`
+	Purged            bool  `json:"purged"`
+	ActivitiesDeleted int64 `json:"activities_deleted"`
+}
+
 // ==================== Like DTOs ====================
 
 // LikerDTO represents a user who liked a day
@@ -263,6 +483,7 @@ type BadgeDTO struct {
 	Icon      string `json:"icon" example:"Zap"`
 	Color     string `json:"color" example:"#3b82f6"`
 	Threshold int    `json:"threshold" example:"7"`
+	Family    string `json:"family" example:"streak"` // streak (threshold in days) or hours
 	Earned    bool   `json:"earned" example:"true"`
 	EarnedAt  string `json:"earned_at,omitempty" example:"2026-01-04"`
 }
@@ -304,6 +525,7 @@ type NotificationDTO struct {
 	Metadata  map[string]interface{} `json:"metadata,omitempty"`
 	ReadAt    *string                `json:"read_at,omitempty" example:"2026-01-05T12:00:00Z"`
 	CreatedAt string                 `json:"created_at" example:"2026-01-05T10:00:00Z"`
+	Seq       int64                  `json:"seq" example:"42"`
 }
 
 // NotificationsResponse represents paginated notifications list
@@ -317,6 +539,34 @@ type NotificationsResponse struct {
 	HasMore       bool              `json:"has_more" example:"true"`
 }
 
+// NotificationActorDTO represents a user who triggered a grouped notification
+type NotificationActorDTO struct {
+	ID       uint   `json:"id" example:"7"`
+	Username string `json:"username" example:"john_doe"`
+	Avatar   string `json:"avatar,omitempty"`
+}
+
+// GroupedNotificationDTO represents consecutive notifications collapsed into one entry.
+// Title, body, metadata and created_at come from the most recent member; read_at is
+// only set when every member is read.
+type GroupedNotificationDTO struct {
+	NotificationDTO
+	NotificationIDs []uint                 `json:"notification_ids"`
+	Actors          []NotificationActorDTO `json:"actors"`
+	Count           int                    `json:"count" example:"3"`
+}
+
+// GroupedNotificationsResponse represents a paginated, grouped notifications list
+// @Description Paginated list of grouped notifications (pagination counts raw notifications)
+type GroupedNotificationsResponse struct {
+	Success       bool                     `json:"success" example:"true"`
+	Notifications []GroupedNotificationDTO `json:"notifications"`
+	Total         int64                    `json:"total" example:"25"`
+	Page          int                      `json:"page" example:"1"`
+	PageSize      int                      `json:"page_size" example:"20"`
+	HasMore       bool                     `json:"has_more" example:"true"`
+}
+
 // UnreadCountResponse represents the unread notification count
 // @Description Unread notification count
 type UnreadCountResponse struct {
@@ -340,6 +590,7 @@ func NotificationToDTO(n *models.Notification) NotificationDTO {
 		Body:      n.Body,
 		Metadata:  n.Metadata,
 		CreatedAt: n.CreatedAt.Format("2006-01-02T15:04:05Z"),
+		Seq:       n.Seq,
 	}
 	if n.ReadAt != nil {
 		readAt := n.ReadAt.Format("2006-01-02T15:04:05Z")
@@ -357,6 +608,28 @@ func NotificationsToDTOs(notifications []models.Notification) []NotificationDTO
 	return dtos
 }
 
+// NotificationGroupsToDTOs converts notification groups to DTOs
+func NotificationGroupsToDTOs(groups []models.NotificationGroup) []GroupedNotificationDTO {
+	dtos := make([]GroupedNotificationDTO, len(groups))
+	for i, g := range groups {
+		base := NotificationToDTO(&g.Latest)
+		if g.Unread {
+			base.ReadAt = nil
+		}
+		actors := make([]NotificationActorDTO, len(g.Actors))
+		for j, a := range g.Actors {
+			actors[j] = NotificationActorDTO{ID: a.ID, Username: a.Username, Avatar: a.Avatar}
+		}
+		dtos[i] = GroupedNotificationDTO{
+			NotificationDTO: base,
+			NotificationIDs: g.IDs,
+			Actors:          actors,
+			Count:           len(g.IDs),
+		}
+	}
+	return dtos
+}
+
 // ==================== Push Notification Response DTOs ====================
 
 // VapidPublicKeyResponse represents the VAPID public key response
@@ -374,6 +647,26 @@ type PushSubscriptionResponse struct {
 	Message string `json:"message" example:"Subscription registered successfully"`
 }
 
+// PushTestResponse represents the result of queuing a test push
+// @Description Test push queued for delivery
+type PushTestResponse struct {
+	Success       bool   `json:"success" example:"true"`
+	MessageID     string `json:"message_id" example:"push-1736071200000000000-0"`
+	Subscriptions int    `json:"subscriptions" example:"2"`
+}
+
+// PushStatsResponse represents the user's push delivery outcomes over a recent window
+// @Description Push delivery counts by outcome
+type PushStatsResponse struct {
+	Success         bool       `json:"success" example:"true"`
+	Days            int        `json:"days" example:"30"`
+	Delivered       int64      `json:"delivered" example:"42"`
+	Gone            int64      `json:"gone" example:"1"`
+	RateLimited     int64      `json:"rate_limited" example:"0"`
+	Failed          int64      `json:"failed" example:"2"`
+	LastDeliveredAt *time.Time `json:"last_delivered_at"`
+}
+
 // PushPreferenceDTO represents push preferences for API responses
 // @Description Push notification preferences
 type PushPreferenceDTO struct {
@@ -472,11 +765,43 @@ type FollowRequestListResponse struct {
 	HasMore    bool               `json:"has_more" example:"true"`
 }
 
+// RelationshipDirectionDTO represents both directions of a relationship
+// @Description Outgoing (viewer -> target) and incoming (target -> viewer) states
+type RelationshipDirectionDTO struct {
+	OutgoingState string `json:"outgoing_state" example:"FOLLOWING"` // FOLLOWING, REQUESTED, NONE
+	IncomingState string `json:"incoming_state" example:"FOLLOWING"` // FOLLOWING, REQUESTED, NONE ("Follows you" when FOLLOWING)
+}
+
 // RelationshipLookupResponse represents relationship states for multiple users
 // @Description Batch relationship lookup result
 type RelationshipLookupResponse struct {
-	Success       bool            `json:"success" example:"true"`
-	Relationships map[uint]string `json:"relationships"` // userID -> "FOLLOWING", "REQUESTED", "NONE"
+	Success       bool                              `json:"success" example:"true"`
+	Relationships map[uint]string                   `json:"relationships"` // userID -> "FOLLOWING", "REQUESTED", "NONE"
+	Directions    map[uint]RelationshipDirectionDTO `json:"directions"`    // userID -> outgoing/incoming states
+}
+
+// BatchFollowRequestResult represents the outcome for a single requester in a batch
+// @Description Per-requester batch result
+type BatchFollowRequestResult struct {
+	Success   bool   `json:"success" example:"true"`
+	ErrorCode string `json:"error_code,omitempty" example:"NO_FOLLOW_REQUEST"`
+}
+
+// BatchFollowRequestActionResponse represents the result of a bulk accept/decline
+// @Description Bulk follow request action result
+type BatchFollowRequestActionResponse struct {
+	Success   bool                              `json:"success" example:"true"`
+	Action    string                            `json:"action" example:"accept"`
+	Processed int                               `json:"processed" example:"3"`
+	Results   map[uint]BatchFollowRequestResult `json:"results"` // requesterID -> result
+}
+
+// CancelAllRequestsResponse represents the result of cancelling all outgoing requests
+// @Description Bulk cancel outgoing follow requests result
+type CancelAllRequestsResponse struct {
+	Success   bool   `json:"success" example:"true"`
+	Cancelled int    `json:"cancelled" example:"5"`
+	Message   string `json:"message" example:"Follow requests cancelled"`
 }
 
 // FollowCountsDTO represents follow counts for a user
@@ -487,6 +812,13 @@ type FollowCountsDTO struct {
 	PendingRequestsCount int64 `json:"pending_requests_count,omitempty" example:"3"`
 }
 
+// FollowCountsEventDTO is pushed over WebSocket when a user's follow counts change
+type FollowCountsEventDTO struct {
+	UserID         uint  `json:"user_id" example:"1"`
+	FollowersCount int64 `json:"followers_count" example:"150"`
+	FollowingCount int64 `json:"following_count" example:"75"`
+}
+
 // FollowCountsResponse represents the follow counts response
 // @Description Follow counts retrieval result
 type FollowCountsResponse struct {
@@ -503,6 +835,14 @@ type MutualsResponse struct {
 	HasMore    bool            `json:"has_more" example:"true"`
 }
 
+// FollowedByResponse represents followers of a user that the viewer also follows
+// @Description Social proof list ("Followed by alice, bob and 3 others")
+type FollowedByResponse struct {
+	Success bool            `json:"success" example:"true"`
+	Users   []FollowUserDTO `json:"users"`
+	Total   int64           `json:"total" example:"5"`
+}
+
 // ==================== Comment DTOs ====================
 
 // MentionDTO represents an @mention in a comment
@@ -560,3 +900,121 @@ type CommentActionResponse struct {
 	Comment *CommentDTO `json:"comment,omitempty"`
 	Message string      `json:"message,omitempty" example:"Comment created successfully"`
 }
+
+// ==================== Verification Request DTOs ====================
+
+// VerificationRequestResponse represents a single verification request
+// @Description A verification request and its review status
+type VerificationRequestResponse struct {
+	Success bool                        `json:"success" example:"true"`
+	Request *models.VerificationRequest `json:"request"`
+}
+
+// VerificationRequestsResponse represents a page of verification requests (admin review queue)
+// @Description Paginated verification requests, oldest first
+type VerificationRequestsResponse struct {
+	Success  bool                                 `json:"success" example:"true"`
+	Requests []models.VerificationRequestWithUser `json:"requests"`
+	Total    int64                                `json:"total" example:"3"`
+	Page     int                                  `json:"page" example:"1"`
+	PageSize int                                  `json:"page_size" example:"20"`
+	HasMore  bool                                 `json:"has_more" example:"false"`
+}
+
+// ==================== Admin DTOs ====================
+
+// CronJobLogDTO represents one run of a cron job.
+// Only one replica claims each (job, date); SkipCount and LastSkippedBy show the replicas that lost the claim.
+type CronJobLogDTO struct {
+	ID            uint       `json:"id" example:"42"`
+	JobName       string     `json:"job_name" example:"daily_streak"`
+	JobDate       string     `json:"job_date" example:"2026-01-29"`
+	Status        string     `json:"status" example:"completed"`
+	StartedAt     time.Time  `json:"started_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+	UsersCount    int        `json:"users_count" example:"1250"`
+	InstanceID    string     `json:"instance_id" example:"backend-7d9f8c-abcde"`
+	SkipCount     int        `json:"skip_count" example:"2"`
+	LastSkippedBy string     `json:"last_skipped_by,omitempty" example:"backend-7d9f8c-fghij"`
+	LastSkippedAt *time.Time `json:"last_skipped_at,omitempty"`
+	Error         string     `json:"error,omitempty"`
+}
+
+// CronJobLogsResponse represents recent cron job runs
+// @Description Recent cron job runs, most recent first
+type CronJobLogsResponse struct {
+	Success bool            `json:"success" example:"true"`
+	Logs    []CronJobLogDTO `json:"logs"`
+}
+
+// CronJobLogToDTO converts a cron job log to its API form
+func CronJobLogToDTO(log *models.CronJobLog) CronJobLogDTO {
+	dto := CronJobLogDTO{
+		ID:            log.ID,
+		JobName:       log.JobName,
+		JobDate:       log.JobDate.Format("2006-01-02"),
+		Status:        log.Status,
+		StartedAt:     log.StartedAt,
+		UsersCount:    log.UsersCount,
+		InstanceID:    log.InstanceID,
+		SkipCount:     log.SkipCount,
+		LastSkippedBy: log.LastSkippedBy,
+		LastSkippedAt: log.LastSkippedAt,
+		Error:         log.Error,
+	}
+	if !log.CompletedAt.IsZero() {
+		completedAt := log.CompletedAt
+		dto.CompletedAt = &completedAt
+	}
+	return dto
+}
+
+// CronRunResponse represents the outcome of a manually triggered cron job
+// @Description Result of a manual cron job run. Runs lists each timezone bucket's job log; a run claimed by another replica or an earlier run is reported as-is, not repeated.
+type CronRunResponse struct {
+	Success      bool            `json:"success" example:"true"`
+	Job          string          `json:"job" example:"daily_streak"`
+	Runs         []CronJobLogDTO `json:"runs,omitempty"`
+	DeletedCount int64           `json:"deleted_count,omitempty" example:"0"`
+	Error        string          `json:"error,omitempty"`
+}
+
+// SessionDTO is a logged-in device in the session list
+type SessionDTO struct {
+	ID         uint      `json:"id" example:"12"`
+	UserAgent  string    `json:"user_agent" example:"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X)"`
+	IPAddress  string    `json:"ip_address" example:"203.0.113.7"`
+	CreatedAt  time.Time `json:"created_at"`
+	LastSeenAt time.Time `json:"last_seen_at"`
+	Current    bool      `json:"current" example:"true"` // The session making this request
+}
+
+// SessionsResponse lists the user's active sessions
+// @Description Logged-in devices
+type SessionsResponse struct {
+	Success  bool         `json:"success" example:"true"`
+	Sessions []SessionDTO `json:"sessions"`
+}
+
+// TwoFactorStatusResponse reports the user's two-factor state
+// @Description Two-factor status
+type TwoFactorStatusResponse struct {
+	Success              bool  `json:"success" example:"true"`
+	Enabled              bool  `json:"enabled" example:"true"`
+	BackupCodesRemaining int64 `json:"backup_codes_remaining" example:"8"`
+}
+
+// TwoFactorEnrollResponse carries a new TOTP secret to add to an authenticator app
+// @Description Pending two-factor enrollment; confirm it with a code from the app
+type TwoFactorEnrollResponse struct {
+	Success    bool   `json:"success" example:"true"`
+	Secret     string `json:"secret" example:"JBSWY3DPEHPK3PXPJBSWY3DPEHPK3PXP"`
+	OTPAuthURL string `json:"otpauth_url" example:"otpauth://totp/Growth%20Tracker:john_doe?secret=..."`
+}
+
+// TwoFactorBackupCodesResponse returns backup codes; they are only shown once
+// @Description Single-use backup codes
+type TwoFactorBackupCodesResponse struct {
+	Success     bool     `json:"success" example:"true"`
+	BackupCodes []string `json:"backup_codes" example:"a1b2c-3d4e5"`
+}
@@ -1,13 +1,22 @@
 package handlers
 
 import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/aman1117/backend/internal/constants"
 	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/internal/response"
 	"github.com/aman1117/backend/internal/services"
+	"github.com/aman1117/backend/internal/validator"
 	"github.com/aman1117/backend/pkg/models"
 	"github.com/gofiber/fiber/v2"
 )
@@ -36,7 +45,7 @@ func NewActivityHandler(activitySvc *services.ActivityService, authSvc *services
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.CreateActivityRequest true "Activity details"
-// @Success 200 {object} dto.SuccessResponse "Activity updated successfully"
+// @Success 200 {object} dto.ActivityUpdatedResponse "Activity updated, with streak and new badges"
 // @Failure 400 {object} dto.ErrorResponse "Validation error"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
 // @Router /create-activity [post]
@@ -72,16 +81,138 @@ func (h *ActivityHandler) CreateActivity(c *fiber.Ctx) error {
 
 	log := logger.LogWithContext(getTraceID(c), userID)
 
-	if err := h.activitySvc.CreateOrUpdateActivity(userID, req.Activity, req.Hours, date, req.Note); err != nil {
+	streak, err := h.activitySvc.CreateOrUpdateActivity(c.Context(), userID, req.Activity, req.Hours, date, req.Note)
+	if err != nil {
 		if err.Error() == "total hours cannot be more than 24" {
 			return response.BadRequest(c, "Total hours cannot be more than 24", constants.ErrCodeHoursExceeded)
 		}
+		if err.Error() == "unknown custom activity" {
+			return response.BadRequest(c, "Custom activity type is not defined", constants.ErrCodeInvalidActivity)
+		}
 		log.Errorw("Failed to create/update activity", "error", err)
 		return response.BadRequest(c, "Failed to update activity", constants.ErrCodeUpdateFailed)
 	}
 
 	log.Debugw("Activity updated", "activity", req.Activity, "hours", req.Hours, "date", req.Date)
-	return response.Success(c, constants.MsgActivityUpdated)
+	return response.JSON(c, dto.ActivityUpdatedResponse{
+		Success: true,
+		Message: constants.MsgActivityUpdated,
+		Streak:  streak,
+	})
+}
+
+// BulkLogActivities logs several activities for one date at once
+// @Summary Log several activities for a day
+// @Description Create or update several activities on one date in a single transaction. The 24-hour cap applies to the day's combined total, and either every activity is saved or none is.
+// @Tags Activities
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.BulkActivitiesRequest true "Date and activities"
+// @Success 200 {object} dto.BulkActivitiesResponse "Saved activities, with streak and new badges"
+// @Failure 400 {object} dto.ErrorResponse "Validation error or total hours exceeded"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/activities/bulk [post]
+func (h *ActivityHandler) BulkLogActivities(c *fiber.Ctx) error {
+	var req dto.BulkActivitiesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+
+	if len(req.Activities) == 0 {
+		return response.BadRequest(c, "At least one activity is required", constants.ErrCodeMissingFields)
+	}
+	if len(req.Activities) > constants.MaxBulkActivities {
+		return response.BadRequest(c, fmt.Sprintf("At most %d activities can be logged at once", constants.MaxBulkActivities), constants.ErrCodeInvalidRequest)
+	}
+
+	date, err := time.Parse(constants.DateFormat, req.Date)
+	if err != nil {
+		return response.BadRequest(c, "Invalid date format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+	}
+	date = date.Truncate(24 * time.Hour)
+
+	entries := make([]repository.ActivityEntry, 0, len(req.Activities))
+	seen := make(map[models.ActivityName]bool, len(req.Activities))
+	for _, item := range req.Activities {
+		if !item.Name.IsValid() {
+			return response.BadRequest(c, "Invalid activity name", constants.ErrCodeInvalidActivity)
+		}
+		if seen[item.Name] {
+			return response.BadRequest(c, "Each activity can only appear once", constants.ErrCodeInvalidRequest)
+		}
+		seen[item.Name] = true
+		if item.Hours < 0 || item.Hours > constants.MaxDailyHours {
+			return response.BadRequest(c, "Hours must be between 0 and 24", constants.ErrCodeInvalidRequest)
+		}
+		if valErr := validator.ValidateNote(item.Note); valErr != nil {
+			return response.BadRequest(c, valErr.Message, valErr.ErrorCode)
+		}
+		entries = append(entries, repository.ActivityEntry{Name: item.Name, Hours: item.Hours, Note: item.Note})
+	}
+
+	userID := getUserID(c)
+	log := logger.LogWithContext(getTraceID(c), userID)
+
+	saved, streak, err := h.activitySvc.LogActivitiesForDay(c.Context(), userID, date, entries)
+	if err != nil {
+		if errors.Is(err, repository.ErrDailyHoursExceeded) {
+			return response.BadRequest(c, "Total hours cannot be more than 24", constants.ErrCodeHoursExceeded)
+		}
+		if err.Error() == "unknown custom activity" {
+			return response.BadRequest(c, "Custom activity type is not defined", constants.ErrCodeInvalidActivity)
+		}
+		log.Errorw("Failed to bulk log activities", "error", err)
+		return response.BadRequest(c, "Failed to update activities", constants.ErrCodeUpdateFailed)
+	}
+
+	log.Debugw("Activities bulk logged", "count", len(saved), "date", req.Date)
+	return response.JSON(c, dto.BulkActivitiesResponse{
+		Success:    true,
+		Activities: toActivityDTOs(saved, true),
+		Streak:     streak,
+	})
+}
+
+// DeleteActivity removes one of the current user's activities
+// @Summary Delete an activity
+// @Description Remove an activity logged on a date. If the day drops under 24 hours, followers' day-completed notifications are withdrawn; if no activities remain, the day no longer counts towards the streak.
+// @Tags Activities
+// @Produce json
+// @Security BearerAuth
+// @Param name query string true "Activity name"
+// @Param date query string true "Date (YYYY-MM-DD)"
+// @Success 200 {object} dto.SuccessResponse "Activity deleted"
+// @Failure 400 {object} dto.ErrorResponse "Validation error"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Activity not found"
+// @Failure 500 {object} dto.ErrorResponse "Server error"
+// @Router /me/activities [delete]
+func (h *ActivityHandler) DeleteActivity(c *fiber.Ctx) error {
+	name := models.ActivityName(c.Query("name"))
+	if !name.IsValid() {
+		return response.BadRequest(c, "Invalid activity name", constants.ErrCodeInvalidActivity)
+	}
+
+	date, err := time.Parse(constants.DateFormat, c.Query("date"))
+	if err != nil {
+		return response.BadRequest(c, "Invalid date format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+	}
+	date = date.Truncate(24 * time.Hour)
+
+	userID := getUserID(c)
+	log := logger.LogWithContext(getTraceID(c), userID)
+
+	if err := h.activitySvc.DeleteActivity(userID, name, date); err != nil {
+		if errors.Is(err, repository.ErrActivityNotFound) {
+			return response.NotFound(c, "Activity not found", constants.ErrCodeActivityNotFound)
+		}
+		log.Errorw("Failed to delete activity", "error", err)
+		return response.InternalError(c, "Failed to delete activity", constants.ErrCodeDeleteFailed)
+	}
+
+	log.Debugw("Activity deleted", "activity", name, "date", c.Query("date"))
+	return response.Success(c, constants.MsgActivityDeleted)
 }
 
 // GetActivities handles activity retrieval
@@ -235,3 +366,142 @@ func toActivityDTOs(activities []models.Activity, includeNotes bool) []dto.Activ
 	}
 	return result
 }
+
+// SearchActivityNotes searches the current user's activity notes
+// @Summary Search activity notes
+// @Description Case-insensitive search over your own activity notes, newest first
+// @Tags Activities
+// @Produce json
+// @Security BearerAuth
+// @Param q query string true "Text to search for (2-100 characters)"
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Items per page (default 20, max 50)"
+// @Success 200 {object} dto.ActivitySearchResponse "Matching activities"
+// @Failure 400 {object} dto.ErrorResponse "Invalid query"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Server error"
+// @Router /me/activities/search [get]
+func (h *ActivityHandler) SearchActivityNotes(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	query := strings.TrimSpace(c.Query("q"))
+	queryLen := utf8.RuneCountInString(query)
+	if queryLen < constants.ActivitySearchMinQueryLen || queryLen > constants.ActivitySearchMaxQueryLen {
+		return response.BadRequest(c, fmt.Sprintf("Search query must be %d-%d characters",
+			constants.ActivitySearchMinQueryLen, constants.ActivitySearchMaxQueryLen), constants.ErrCodeInvalidInput)
+	}
+
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", constants.ActivitySearchDefaultPageSize)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = constants.ActivitySearchDefaultPageSize
+	}
+	if pageSize > constants.ActivitySearchMaxPageSize {
+		pageSize = constants.ActivitySearchMaxPageSize
+	}
+
+	activities, total, err := h.activitySvc.SearchNotes(userID, query, page, pageSize)
+	if err != nil {
+		logger.LogWithContext(traceID, userID).Errorw("Failed to search activity notes", "error", err)
+		return response.InternalError(c, "Failed to search activities", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, dto.ActivitySearchResponse{
+		Success:    true,
+		Activities: toActivityDTOs(activities, true),
+		Total:      total,
+		Page:       page,
+		PageSize:   pageSize,
+		HasMore:    int64(page*pageSize) < total,
+	})
+}
+
+// ExportActivities streams the current user's activity log as CSV
+// @Summary Export activities as CSV
+// @Description Download all of your activities (date, activity_name, hours, note) ordered by date, optionally within a date range
+// @Tags Activities
+// @Produce text/csv
+// @Security BearerAuth
+// @Param start query string false "Start date (YYYY-MM-DD)"
+// @Param end query string false "End date (YYYY-MM-DD)"
+// @Success 200 {file} file "CSV file"
+// @Failure 400 {object} dto.ErrorResponse "Invalid date range"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/activities/export [get]
+func (h *ActivityHandler) ExportActivities(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	var startDate, endDate *time.Time
+	if raw := c.Query("start"); raw != "" {
+		d, err := time.Parse(constants.DateFormat, raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid start date format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+		}
+		startDate = &d
+	}
+	if raw := c.Query("end"); raw != "" {
+		d, err := time.Parse(constants.DateFormat, raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid end date format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+		}
+		endDate = &d
+	}
+	if startDate != nil && endDate != nil && endDate.Before(*startDate) {
+		return response.BadRequest(c, "end date must not be before start date", constants.ErrCodeInvalidDateRange)
+	}
+
+	filename := fmt.Sprintf("activities-%s.csv", time.Now().Format(constants.DateFormat))
+	c.Set(fiber.HeaderContentType, "text/csv; charset=utf-8")
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// Rows are written as they are read; headers are already sent, so a mid-stream
+	// failure can only be logged and results in a truncated file.
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		writer := csv.NewWriter(w)
+		_ = writer.Write([]string{"date", "activity_name", "hours", "note"})
+
+		rowCount := 0
+		err := h.activitySvc.StreamActivities(userID, startDate, endDate, func(a models.Activity) error {
+			note := ""
+			if a.Note != nil {
+				note = *a.Note
+			}
+			if err := writer.Write([]string{
+				a.ActivityDate.Format(constants.DateFormat),
+				string(a.Name),
+				strconv.FormatFloat(float64(a.DurationHours), 'f', 2, 32),
+				note,
+			}); err != nil {
+				return err
+			}
+			rowCount++
+			if rowCount%constants.ActivityExportFlushEvery == 0 {
+				writer.Flush()
+				if err := writer.Error(); err != nil {
+					return err
+				}
+				return w.Flush()
+			}
+			return nil
+		})
+		writer.Flush()
+		if err == nil {
+			err = writer.Error()
+		}
+		if err == nil {
+			err = w.Flush()
+		}
+		if err != nil {
+			logger.LogWithContext(traceID, userID).Errorw("Activity export failed", "rows_written", rowCount, "error", err)
+			return
+		}
+		logger.LogWithContext(traceID, userID).Infow("Activity export completed", "rows_written", rowCount)
+	})
+
+	return nil
+}
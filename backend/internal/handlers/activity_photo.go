@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/internal/response"
 	"github.com/aman1117/backend/internal/services"
+	"github.com/aman1117/backend/pkg/models"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -33,7 +38,8 @@ func NewActivityPhotoHandler(
 
 // UploadPhoto handles activity photo uploads
 // @Summary Upload activity photo
-// @Description Upload a photo for an activity on a specific date (max 5MB, within 7 days)
+// @Description Upload a photo for an activity on a specific date (max 5MB, within the upload window, default 7 days).
+// @Description Uploads are throttled per user (default 20 per hour, configurable via STORY_UPLOADS_PER_HOUR); exceeding it returns 429 with UPLOAD_RATE_LIMITED.
 // @Tags Activity Photos
 // @Accept multipart/form-data
 // @Produce json
@@ -41,10 +47,14 @@ func NewActivityPhotoHandler(
 // @Param image formData file true "Image file (JPEG, PNG, WebP)"
 // @Param activity_name formData string true "Activity name"
 // @Param photo_date formData string true "Photo date (YYYY-MM-DD)"
+// @Param visibility formData string false "Visibility: all_followers (default) or close_friends"
+// @Param replace formData bool false "Replace an existing photo for this activity and date instead of failing"
 // @Success 200 {object} map[string]interface{} "Photo uploaded successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error or duplicate"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
 // @Failure 409 {object} dto.ErrorResponse "Photo already exists"
+// @Failure 429 {object} dto.ErrorResponse "Upload rate limit exceeded"
+// @Failure 503 {object} dto.ErrorResponse "Photo storage unavailable"
 // @Router /activity-photo [post]
 func (h *ActivityPhotoHandler) UploadPhoto(c *fiber.Ctx) error {
 	userID := getUserID(c)
@@ -57,6 +67,8 @@ func (h *ActivityPhotoHandler) UploadPhoto(c *fiber.Ctx) error {
 	activityIcon := c.FormValue("activity_icon")
 	activityColor := c.FormValue("activity_color")
 	activityLabel := c.FormValue("activity_label")
+	visibility := models.PhotoVisibility(c.FormValue("visibility"))
+	replace := c.FormValue("replace") == "true"
 
 	if activityName == "" || photoDateStr == "" {
 		logger.LogWithContext(traceID, userID).Warnw("Photo upload failed - missing fields")
@@ -86,11 +98,27 @@ func (h *ActivityPhotoHandler) UploadPhoto(c *fiber.Ctx) error {
 	defer src.Close()
 
 	// Upload photo with optional custom tile metadata
-	photo, err := h.photoSvc.Upload(c.Context(), userID, activityName, photoDate, src, file, activityIcon, activityColor, activityLabel)
+	photo, err := h.photoSvc.Upload(c.Context(), userID, activityName, photoDate, src, file, activityIcon, activityColor, activityLabel, visibility, replace)
 	if err != nil {
 		if err.Error() == "photo already exists for this activity on this date" {
 			return response.Conflict(c, "Photo already exists for this activity on this date", constants.ErrCodeConflict)
 		}
+		if errors.Is(err, services.ErrUploadRateLimited) {
+			logger.LogWithContext(traceID, userID).Warnw("Photo upload rate limited")
+			return response.Error(c, fiber.StatusTooManyRequests, "Too many photo uploads, try again later", constants.ErrCodeUploadRateLimited)
+		}
+		if errors.Is(err, services.ErrStorageUnavailable) {
+			logger.LogWithContext(traceID, userID).Errorw("Photo upload failed - storage unavailable", "error", err)
+			return response.Error(c, fiber.StatusServiceUnavailable, "Photo storage is temporarily unavailable, please try again", constants.ErrCodeStorageUnavailable)
+		}
+		if errors.Is(err, services.ErrImageTooLarge) {
+			logger.LogWithContext(traceID, userID).Warnw("Photo upload rejected - image too large", "error", err)
+			return response.BadRequest(c, err.Error(), constants.ErrCodeImageTooLarge)
+		}
+		if errors.Is(err, services.ErrInvalidImage) {
+			logger.LogWithContext(traceID, userID).Warnw("Photo upload rejected - invalid image", "error", err)
+			return response.BadRequest(c, err.Error(), constants.ErrCodeInvalidImage)
+		}
 		logger.LogWithContext(traceID, userID).Errorw("Photo upload failed", "error", err)
 		return response.BadRequest(c, err.Error(), constants.ErrCodeInvalidRequest)
 	}
@@ -146,9 +174,37 @@ func (h *ActivityPhotoHandler) DeletePhoto(c *fiber.Ctx) error {
 	return response.Success(c, "Photo deleted successfully")
 }
 
+// DeleteAllPhotos deletes every photo the current user has uploaded
+// @Summary Delete all my stories
+// @Description Delete the current user's entire story history, including views, likes and replies
+// @Tags Activity Photos
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Number of photos deleted"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Delete failed"
+// @Router /me/stories [delete]
+func (h *ActivityPhotoHandler) DeleteAllPhotos(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	deleted, err := h.photoSvc.DeleteAllForUser(c.Context(), userID)
+	if err != nil {
+		logger.LogWithContext(traceID, userID).Errorw("Bulk photo deletion failed", "error", err)
+		return response.InternalError(c, "Failed to delete stories", constants.ErrCodeDeleteFailed)
+	}
+
+	logger.LogWithContext(traceID, userID).Infow("All activity photos deleted", "count", deleted)
+	return response.JSON(c, fiber.Map{
+		"success": true,
+		"deleted": deleted,
+	})
+}
+
 // GetPhotos retrieves activity photos for a user and date
 // @Summary Get activity photos
 // @Description Get photos for a user on a specific date. Records views for other users' photos.
+// @Description Photo and thumbnail URLs are time-limited signed URLs when SAS signing is enabled.
 // @Tags Activity Photos
 // @Produce json
 // @Security BearerAuth
@@ -192,16 +248,22 @@ func (h *ActivityPhotoHandler) GetPhotos(c *fiber.Ctx) error {
 	}
 
 	// Get photos
-	photos, err := h.photoSvc.GetByUserAndDate(c.Context(), uint(targetUserID), photoDate)
+	photos, err := h.photoSvc.GetVisibleByUserAndDate(c.Context(), viewerID, uint(targetUserID), photoDate)
 	if err != nil {
 		logger.LogWithContext(traceID, viewerID).Errorw("Failed to get photos", "error", err)
 		return response.InternalError(c, "Failed to get photos", constants.ErrCodeFetchFailed)
 	}
 
-	// Record views for other users' photos
-	if viewerID != uint(targetUserID) {
+	// Record views for other users' photos in a single batch
+	if viewerID != uint(targetUserID) && len(photos) > 0 {
+		photoIDs := make([]uint, 0, len(photos))
 		for _, photo := range photos {
-			_ = h.photoSvc.RecordView(c.Context(), viewerID, photo.ID)
+			if photo.UserID != viewerID {
+				photoIDs = append(photoIDs, photo.ID)
+			}
+		}
+		if err := h.photoSvc.RecordViewsBatch(c.Context(), viewerID, photoIDs); err != nil {
+			logger.LogWithContext(traceID, viewerID).Warnw("Failed to record photo views", "error", err)
 		}
 	}
 
@@ -211,14 +273,63 @@ func (h *ActivityPhotoHandler) GetPhotos(c *fiber.Ctx) error {
 	})
 }
 
+// GetStoryArchive retrieves the current user's photos across a date range
+// @Summary Get story archive
+// @Description Get the current user's photos between start and end (inclusive, max 90 days), grouped by date
+// @Tags Activity Photos
+// @Produce json
+// @Security BearerAuth
+// @Param start query string true "Start date (YYYY-MM-DD)"
+// @Param end query string true "End date (YYYY-MM-DD)"
+// @Success 200 {object} map[string]interface{} "Archive grouped by date"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /activity-photos/archive [get]
+func (h *ActivityPhotoHandler) GetStoryArchive(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+	if startStr == "" || endStr == "" {
+		return response.BadRequest(c, "start and end are required", constants.ErrCodeMissingFields)
+	}
+
+	start, err := time.Parse(constants.DateFormat, startStr)
+	if err != nil {
+		return response.BadRequest(c, "Invalid start date format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+	}
+	end, err := time.Parse(constants.DateFormat, endStr)
+	if err != nil {
+		return response.BadRequest(c, "Invalid end date format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+	}
+
+	days, err := h.photoSvc.GetByUserAndDateRange(c.Context(), userID, userID, start, end)
+	if err != nil {
+		if err.Error() == "end date must not be before start date" ||
+			strings.HasPrefix(err.Error(), "date range cannot exceed") {
+			return response.BadRequest(c, err.Error(), constants.ErrCodeInvalidDate)
+		}
+		logger.LogWithContext(traceID, userID).Errorw("Failed to get story archive", "error", err)
+		return response.InternalError(c, "Failed to get story archive", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success": true,
+		"days":    days,
+	})
+}
+
 // GetFollowingStories retrieves story groups from followed users
 // @Summary Get following users' stories
-// @Description Get photo stories from users the current user follows for a specific date
+// @Description Get photo stories from users the current user follows for a specific date.
+// @Description Photo and thumbnail URLs are time-limited signed URLs when SAS signing is enabled.
 // @Tags Activity Photos
 // @Produce json
 // @Security BearerAuth
 // @Param date query string true "Date (YYYY-MM-DD)"
 // @Param limit query int false "Max users to return (default 20)"
+// @Param cursor query string false "Pagination cursor (next_cursor from the previous page)"
 // @Success 200 {object} map[string]interface{} "Story groups"
 // @Failure 400 {object} dto.ErrorResponse "Invalid request"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
@@ -244,15 +355,31 @@ func (h *ActivityPhotoHandler) GetFollowingStories(c *fiber.Ctx) error {
 		}
 	}
 
-	stories, err := h.photoSvc.GetFollowingStories(c.Context(), viewerID, photoDate, limit)
+	var cursor *repository.StoryFeedCursor
+	if decoded := decodeCursor(c.Query("cursor")); decoded != nil {
+		cursor = &repository.StoryFeedCursor{
+			LatestUpload: decoded.CreatedAt,
+			UserID:       decoded.UserID,
+		}
+	}
+
+	stories, hasMore, err := h.photoSvc.GetFollowingStories(c.Context(), viewerID, photoDate, limit, cursor)
 	if err != nil {
 		logger.LogWithContext(traceID, viewerID).Errorw("Failed to get following stories", "error", err)
 		return response.InternalError(c, "Failed to get stories", constants.ErrCodeFetchFailed)
 	}
 
+	var nextCursor string
+	if hasMore && len(stories) > 0 {
+		last := stories[len(stories)-1]
+		nextCursor = encodeCursor(last.LatestUploadAt, last.UserID)
+	}
+
 	return response.JSON(c, fiber.Map{
-		"success": true,
-		"stories": stories,
+		"success":     true,
+		"stories":     stories,
+		"next_cursor": nextCursor,
+		"has_more":    hasMore,
 	})
 }
 
@@ -299,6 +426,62 @@ func (h *ActivityPhotoHandler) RecordView(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateGhostMode toggles story ghost mode for the current user
+// @Summary Update story ghost mode
+// @Description When enabled, your story views are no longer recorded. Applies going forward only; past views are kept.
+// @Tags Activity Photos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateGhostModeRequest true "Ghost mode setting"
+// @Success 200 {object} map[string]interface{} "Ghost mode updated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/ghost-mode [put]
+func (h *ActivityPhotoHandler) UpdateGhostMode(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	var req dto.UpdateGhostModeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+
+	if err := h.photoSvc.HideFromViewers(c.Context(), userID, req.Enabled); err != nil {
+		logger.LogWithContext(traceID, userID).Errorw("Ghost mode update failed", "error", err)
+		return response.InternalError(c, "Failed to update ghost mode", constants.ErrCodeUpdateFailed)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success":    true,
+		"ghost_mode": req.Enabled,
+	})
+}
+
+// GetGhostMode returns the current user's story ghost mode setting
+// @Summary Get story ghost mode
+// @Description Get whether story views are currently being recorded for you
+// @Tags Activity Photos
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Ghost mode setting"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/ghost-mode [get]
+func (h *ActivityPhotoHandler) GetGhostMode(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	enabled, err := h.photoSvc.IsGhostMode(c.Context(), userID)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to get ghost mode", "error", err)
+		return response.InternalError(c, "Failed to get ghost mode", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success":    true,
+		"ghost_mode": enabled,
+	})
+}
+
 // GetPhotoViewers retrieves viewers of a photo
 // @Summary Get photo viewers
 // @Description Get list of users who viewed a photo (owner only)
@@ -307,7 +490,8 @@ func (h *ActivityPhotoHandler) RecordView(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Photo ID"
 // @Param limit query int false "Max results (default 20)"
-// @Param offset query int false "Offset for pagination"
+// @Param offset query int false "Offset for pagination (ignored when cursor is set)"
+// @Param cursor query string false "Pagination cursor (next_cursor from the previous page)"
 // @Success 200 {object} map[string]interface{} "Viewers list"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
 // @Failure 403 {object} dto.ErrorResponse "Not photo owner"
@@ -324,48 +508,154 @@ func (h *ActivityPhotoHandler) GetPhotoViewers(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid photo ID", constants.ErrCodeInvalidRequest)
 	}
 
-	limit := 20
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 100 {
-			limit = parsed
+	limit, offset := parseLimitOffset(c, 20, 100)
+
+	viewers, total, err := h.photoSvc.GetViewers(c.Context(), uint(photoID), userID, limit, offset, decodeCursor(c.Query("cursor")))
+	if err != nil {
+		if err.Error() == "photo not found" {
+			return response.NotFound(c, "Photo not found", constants.ErrCodeNotificationNotFound)
+		}
+		if err.Error() == "not authorized to view photo viewers" {
+			return response.Forbidden(c, "Only the photo owner can view viewers", constants.ErrCodeNotAuthorized)
 		}
+		logger.LogWithContext(traceID, userID).Errorw("Failed to get photo viewers", "error", err)
+		return response.InternalError(c, "Failed to get viewers", constants.ErrCodeFetchFailed)
 	}
 
-	offset := 0
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed >= 0 {
-			offset = parsed
-		}
+	var nextCursor string
+	if len(viewers) == limit {
+		last := viewers[len(viewers)-1]
+		nextCursor = encodeCursor(last.ViewedAt, last.UserID)
 	}
 
-	viewers, total, err := h.photoSvc.GetViewers(c.Context(), uint(photoID), userID, limit, offset)
+	return response.JSON(c, fiber.Map{
+		"success":     true,
+		"viewers":     viewers,
+		"total":       total,
+		"next_cursor": nextCursor,
+	})
+}
+
+// GetPhotoNonViewers retrieves followers who have not viewed a photo
+// @Summary Get photo non-viewers
+// @Description Get list of followers who have not viewed a photo, most recent followers first (owner only)
+// @Tags Activity Photos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Photo ID"
+// @Param limit query int false "Max results (default 20)"
+// @Param offset query int false "Offset for pagination (ignored when cursor is set)"
+// @Param cursor query string false "Pagination cursor (next_cursor from the previous page)"
+// @Success 200 {object} map[string]interface{} "Non-viewers list"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Not photo owner"
+// @Failure 404 {object} dto.ErrorResponse "Photo not found"
+// @Router /activity-photo/{id}/non-viewers [get]
+func (h *ActivityPhotoHandler) GetPhotoNonViewers(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	// Parse photo ID
+	photoIDStr := c.Params("id")
+	photoID, err := strconv.ParseUint(photoIDStr, 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid photo ID", constants.ErrCodeInvalidRequest)
+	}
+
+	limit, offset := parseLimitOffset(c, 20, 100)
+
+	nonViewers, total, err := h.photoSvc.GetNonViewers(c.Context(), uint(photoID), userID, limit, offset, decodeCursor(c.Query("cursor")))
 	if err != nil {
 		if err.Error() == "photo not found" {
 			return response.NotFound(c, "Photo not found", constants.ErrCodeNotificationNotFound)
 		}
 		if err.Error() == "not authorized to view photo viewers" {
-			return response.Forbidden(c, "Only the photo owner can view viewers", constants.ErrCodeNotAuthorized)
+			return response.Forbidden(c, "Only the photo owner can view non-viewers", constants.ErrCodeNotAuthorized)
 		}
-		logger.LogWithContext(traceID, userID).Errorw("Failed to get photo viewers", "error", err)
-		return response.InternalError(c, "Failed to get viewers", constants.ErrCodeFetchFailed)
+		logger.LogWithContext(traceID, userID).Errorw("Failed to get photo non-viewers", "error", err)
+		return response.InternalError(c, "Failed to get non-viewers", constants.ErrCodeFetchFailed)
+	}
+
+	var nextCursor string
+	if len(nonViewers) == limit {
+		last := nonViewers[len(nonViewers)-1]
+		nextCursor = encodeCursor(last.FollowedAt, last.UserID)
 	}
 
 	return response.JSON(c, fiber.Map{
-		"success": true,
-		"viewers": viewers,
-		"total":   total,
+		"success":     true,
+		"non_viewers": nonViewers,
+		"total":       total,
+		"next_cursor": nextCursor,
+	})
+}
+
+// GetPhotoLikers retrieves users who reacted to a photo
+// @Summary Get photo likers
+// @Description Get list of users who reacted to a photo, with their emoji, most recent first (owner only)
+// @Tags Activity Photos
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Photo ID"
+// @Param limit query int false "Max results (default 20)"
+// @Param offset query int false "Offset for pagination (ignored when cursor is set)"
+// @Param cursor query string false "Pagination cursor (next_cursor from the previous page)"
+// @Success 200 {object} map[string]interface{} "Likers list"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Not photo owner"
+// @Failure 404 {object} dto.ErrorResponse "Photo not found"
+// @Router /activity-photo/{id}/likers [get]
+func (h *ActivityPhotoHandler) GetPhotoLikers(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	// Parse photo ID
+	photoIDStr := c.Params("id")
+	photoID, err := strconv.ParseUint(photoIDStr, 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid photo ID", constants.ErrCodeInvalidRequest)
+	}
+
+	limit, offset := parseLimitOffset(c, 20, 100)
+
+	likers, total, err := h.photoSvc.GetPhotoLikers(c.Context(), uint(photoID), userID, limit, offset, decodeCursor(c.Query("cursor")))
+	if err != nil {
+		if err.Error() == "photo not found" {
+			return response.NotFound(c, "Photo not found", constants.ErrCodeNotificationNotFound)
+		}
+		if err.Error() == "not authorized to view photo likers" {
+			return response.Forbidden(c, "Only the photo owner can view likers", constants.ErrCodeNotAuthorized)
+		}
+		logger.LogWithContext(traceID, userID).Errorw("Failed to get photo likers", "error", err)
+		return response.InternalError(c, "Failed to get likers", constants.ErrCodeFetchFailed)
+	}
+
+	var nextCursor string
+	if len(likers) == limit {
+		last := likers[len(likers)-1]
+		nextCursor = encodeCursor(last.LikedAt, last.UserID)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success":     true,
+		"likers":      likers,
+		"total":       total,
+		"next_cursor": nextCursor,
 	})
 }
 
-// LikePhoto handles liking a photo
+// LikePhoto handles liking (reacting to) a photo
 // @Summary Like a photo
-// @Description Like a story photo (also records view)
+// @Description React to a story photo with an emoji (also records view). Without a body the reaction is ❤️.
+// @Description Reacting again with a different emoji replaces the earlier reaction.
 // @Tags Activity Photos
+// @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param id path int true "Photo ID"
+// @Param body body dto.StoryReactionRequest false "Reaction emoji"
 // @Success 200 {object} map[string]interface{} "Photo liked"
-// @Failure 400 {object} dto.ErrorResponse "Cannot like own photo"
+// @Failure 400 {object} dto.ErrorResponse "Cannot like own photo or unsupported reaction"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
 // @Failure 403 {object} dto.ErrorResponse "Must follow user"
 // @Failure 404 {object} dto.ErrorResponse "Photo not found"
@@ -381,8 +671,23 @@ func (h *ActivityPhotoHandler) LikePhoto(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid photo ID", constants.ErrCodeInvalidRequest)
 	}
 
-	err = h.photoSvc.LikePhoto(c.Context(), userID, uint(photoID))
+	// An empty body keeps the old plain like (❤️)
+	emoji := models.DefaultStoryReaction
+	if len(c.Body()) > 0 {
+		var req dto.StoryReactionRequest
+		if err := c.BodyParser(&req); err != nil {
+			return response.BadRequest(c, "Invalid request body", constants.ErrCodeInvalidRequest)
+		}
+		if req.Emoji != "" {
+			emoji = req.Emoji
+		}
+	}
+
+	err = h.photoSvc.ReactToPhoto(c.Context(), userID, uint(photoID), emoji)
 	if err != nil {
+		if errors.Is(err, services.ErrInvalidReaction) {
+			return response.BadRequest(c, "Unsupported reaction", constants.ErrCodeInvalidInput)
+		}
 		if err.Error() == "photo not found" {
 			return response.NotFound(c, "Photo not found", constants.ErrCodeNotificationNotFound)
 		}
@@ -396,9 +701,68 @@ func (h *ActivityPhotoHandler) LikePhoto(c *fiber.Ctx) error {
 		return response.InternalError(c, "Failed to like photo", constants.ErrCodeServerError)
 	}
 
-	logger.LogWithContext(traceID, userID).Infow("Photo liked", "photo_id", photoID)
+	logger.LogWithContext(traceID, userID).Infow("Photo liked", "photo_id", photoID, "emoji", emoji)
 	return response.JSON(c, fiber.Map{
 		"success": true,
+		"emoji":   emoji,
+	})
+}
+
+// ReplyToPhoto handles replying to a story photo
+// @Summary Reply to a story
+// @Description Send a short reply to the owner of a story photo (max 500 characters)
+// @Tags Activity Photos
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Photo ID"
+// @Param body body dto.StoryReplyRequest true "Reply text"
+// @Success 200 {object} map[string]interface{} "Reply sent"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Must follow user"
+// @Failure 404 {object} dto.ErrorResponse "Photo not found"
+// @Failure 429 {object} dto.ErrorResponse "Too many replies"
+// @Router /activity-photo/{id}/reply [post]
+func (h *ActivityPhotoHandler) ReplyToPhoto(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	// Parse photo ID
+	photoIDStr := c.Params("id")
+	photoID, err := strconv.ParseUint(photoIDStr, 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid photo ID", constants.ErrCodeInvalidRequest)
+	}
+
+	var req dto.StoryReplyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.BadRequest(c, "Invalid request body", constants.ErrCodeInvalidRequest)
+	}
+
+	reply, err := h.photoSvc.ReplyToPhoto(c.Context(), userID, uint(photoID), req.Text)
+	if err != nil {
+		switch err.Error() {
+		case "reply text is required":
+			return response.BadRequest(c, "Reply text is required", constants.ErrCodeMissingFields)
+		case "reply text is too long":
+			return response.BadRequest(c, "Reply must be 500 characters or less", constants.ErrCodeInvalidInput)
+		case "photo not found":
+			return response.NotFound(c, "Photo not found", constants.ErrCodeNotificationNotFound)
+		case "cannot reply to own photo":
+			return response.BadRequest(c, "Cannot reply to your own photo", constants.ErrCodeInvalidRequest)
+		case "must follow user to reply to their photos":
+			return response.Forbidden(c, "You must follow this user to reply to their photos", constants.ErrCodeNotAuthorized)
+		case "too many replies to this photo":
+			return response.Error(c, fiber.StatusTooManyRequests, "Too many replies to this photo, try again later", constants.ErrCodeRateLimitExceeded)
+		}
+		logger.LogWithContext(traceID, userID).Errorw("Failed to reply to photo", "error", err, "photo_id", photoID)
+		return response.InternalError(c, "Failed to send reply", constants.ErrCodeServerError)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success": true,
+		"reply":   reply,
 	})
 }
 
@@ -447,7 +811,8 @@ func (h *ActivityPhotoHandler) UnlikePhoto(c *fiber.Ctx) error {
 // @Security BearerAuth
 // @Param id path int true "Photo ID"
 // @Param limit query int false "Max results (default 20)"
-// @Param offset query int false "Offset for pagination"
+// @Param offset query int false "Offset for pagination (ignored when cursor is set)"
+// @Param cursor query string false "Pagination cursor (next_cursor from the previous page)"
 // @Success 200 {object} map[string]interface{} "Interactions list"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
 // @Failure 403 {object} dto.ErrorResponse "Not photo owner"
@@ -464,21 +829,9 @@ func (h *ActivityPhotoHandler) GetPhotoInteractions(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid photo ID", constants.ErrCodeInvalidRequest)
 	}
 
-	limit := 20
-	if limitStr := c.Query("limit"); limitStr != "" {
-		if parsed, err := strconv.Atoi(limitStr); err == nil && parsed > 0 && parsed <= 100 {
-			limit = parsed
-		}
-	}
+	limit, offset := parseLimitOffset(c, 20, 100)
 
-	offset := 0
-	if offsetStr := c.Query("offset"); offsetStr != "" {
-		if parsed, err := strconv.Atoi(offsetStr); err == nil && parsed >= 0 {
-			offset = parsed
-		}
-	}
-
-	interactions, total, err := h.photoSvc.GetPhotoInteractions(c.Context(), uint(photoID), userID, limit, offset)
+	interactions, total, err := h.photoSvc.GetPhotoInteractions(c.Context(), uint(photoID), userID, limit, offset, decodeCursor(c.Query("cursor")))
 	if err != nil {
 		if err.Error() == "photo not found" {
 			return response.NotFound(c, "Photo not found", constants.ErrCodeNotificationNotFound)
@@ -490,16 +843,24 @@ func (h *ActivityPhotoHandler) GetPhotoInteractions(c *fiber.Ctx) error {
 		return response.InternalError(c, "Failed to get interactions", constants.ErrCodeFetchFailed)
 	}
 
+	var nextCursor string
+	if len(interactions) == limit {
+		last := interactions[len(interactions)-1]
+		nextCursor = encodeCursor(last.SortTime(), last.UserID)
+	}
+
 	return response.JSON(c, fiber.Map{
 		"success":      true,
 		"interactions": interactions,
 		"total":        total,
+		"next_cursor":  nextCursor,
 	})
 }
 
 // GetPhotoLikeStatus retrieves like status and count for a photo
 // @Summary Get photo like status
-// @Description Get whether current user has liked a photo and total like count
+// @Description Get whether current user has liked a photo, their reaction, the total like count
+// @Description and reaction counts grouped by emoji
 // @Tags Activity Photos
 // @Produce json
 // @Security BearerAuth
@@ -533,9 +894,23 @@ func (h *ActivityPhotoHandler) GetPhotoLikeStatus(c *fiber.Ctx) error {
 		return response.InternalError(c, "Failed to get like count", constants.ErrCodeFetchFailed)
 	}
 
+	// Reactions are read from the database; the like count above may come from cache
+	reaction, err := h.photoSvc.GetReaction(c.Context(), userID, uint(photoID))
+	if err != nil {
+		logger.LogWithContext(traceID, userID).Errorw("Failed to get reaction", "error", err, "photo_id", photoID)
+		return response.InternalError(c, "Failed to get like status", constants.ErrCodeFetchFailed)
+	}
+	reactions, err := h.photoSvc.GetReactionCounts(c.Context(), uint(photoID))
+	if err != nil {
+		logger.LogWithContext(traceID, userID).Errorw("Failed to get reaction counts", "error", err, "photo_id", photoID)
+		return response.InternalError(c, "Failed to get like status", constants.ErrCodeFetchFailed)
+	}
+
 	return response.JSON(c, fiber.Map{
 		"success":    true,
 		"liked":      liked,
 		"like_count": likeCount,
+		"reaction":   reaction,
+		"reactions":  reactions,
 	})
 }
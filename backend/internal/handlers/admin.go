@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/dto"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/response"
+	"github.com/aman1117/backend/internal/services"
+	"github.com/aman1117/backend/pkg/models"
+	"github.com/gofiber/fiber/v2"
+)
+
+// AdminHandler handles operator endpoints (mounted behind RequireAdmin)
+type AdminHandler struct {
+	cronSvc *services.CronService
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(cronSvc *services.CronService) *AdminHandler {
+	return &AdminHandler{cronSvc: cronSvc}
+}
+
+// GetCronLogs returns recent cron job runs
+// @Summary Get cron job history (admin)
+// @Description Recent cron job runs, most recent first. Per-timezone runs (e.g. daily_streak@America/New_York) are included with their base job. instance_id is the replica that claimed the run; skip_count and last_skipped_by show replicas that lost the claim.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param job_name query string false "Job name (e.g. daily_streak, streak_reminder); all jobs if omitted"
+// @Param limit query int false "Max runs to return (default 50, max 200)"
+// @Success 200 {object} dto.CronJobLogsResponse "Cron job runs"
+// @Failure 403 {object} dto.ErrorResponse "Not an admin"
+// @Router /admin/cron-logs [get]
+func (h *AdminHandler) GetCronLogs(c *fiber.Ctx) error {
+	jobName := strings.TrimSpace(c.Query("job_name"))
+	limit := c.QueryInt("limit", constants.CronLogsDefaultLimit)
+	if limit < 1 {
+		limit = constants.CronLogsDefaultLimit
+	}
+	if limit > constants.CronLogsMaxLimit {
+		limit = constants.CronLogsMaxLimit
+	}
+
+	logs, err := h.cronSvc.GetJobHistory(jobName, limit)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), getUserID(c)).Errorw("Failed to get cron job logs", "job_name", jobName, "error", err)
+		return response.InternalError(c, "Failed to get cron job logs", constants.ErrCodeFetchFailed)
+	}
+
+	out := make([]dto.CronJobLogDTO, 0, len(logs))
+	for i := range logs {
+		out = append(out, dto.CronJobLogToDTO(&logs[i]))
+	}
+
+	return response.JSON(c, dto.CronJobLogsResponse{
+		Success: true,
+		Logs:    out,
+	})
+}
+
+// RunCronJob runs a cron job on demand
+// @Summary Run a cron job now (admin)
+// @Description Runs daily_streak, streak_reminder or notification_cleanup immediately. Job claims still apply: a run that already completed for the date is reported, not repeated, while failed runs are retried. date (YYYY-MM-DD) is only supported for daily_streak.
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param job path string true "Job name" Enums(daily_streak, streak_reminder, notification_cleanup)
+// @Param date query string false "Date to process (daily_streak only, YYYY-MM-DD)"
+// @Success 200 {object} dto.CronRunResponse "Run summary"
+// @Failure 400 {object} dto.ErrorResponse "Unknown job or invalid date"
+// @Failure 403 {object} dto.ErrorResponse "Not an admin"
+// @Failure 500 {object} dto.CronRunResponse "Job failed"
+// @Router /admin/cron/{job}/run [post]
+func (h *AdminHandler) RunCronJob(c *fiber.Ctx) error {
+	job := c.Params("job")
+	log := logger.LogWithContext(getTraceID(c), getUserID(c))
+
+	var date *time.Time
+	if raw := c.Query("date"); raw != "" {
+		if job != models.CronJobDailyStreak {
+			return response.BadRequest(c, "date is only supported for daily_streak", constants.ErrCodeInvalidInput)
+		}
+		parsed, err := time.Parse(constants.DateFormat, raw)
+		if err != nil {
+			return response.BadRequest(c, "Invalid date format, expected YYYY-MM-DD", constants.ErrCodeInvalidDate)
+		}
+		date = &parsed
+	}
+
+	summary, err := h.cronSvc.RunJobNow(context.Background(), job, date)
+	if errors.Is(err, services.ErrUnknownCronJob) {
+		return response.BadRequest(c, "Unknown cron job", constants.ErrCodeUnknownCronJob)
+	}
+
+	resp := dto.CronRunResponse{Success: err == nil, Job: job}
+	if summary != nil {
+		resp.DeletedCount = summary.Deleted
+		for i := range summary.Runs {
+			resp.Runs = append(resp.Runs, dto.CronJobLogToDTO(&summary.Runs[i]))
+		}
+	}
+	if err != nil {
+		log.Errorw("Manual cron job run failed", "job", job, "error", err)
+		resp.Error = err.Error()
+		return c.Status(fiber.StatusInternalServerError).JSON(resp)
+	}
+
+	log.Infow("Manual cron job run completed", "job", job, "runs", len(resp.Runs), "deleted", resp.DeletedCount)
+	return response.JSON(c, resp)
+}
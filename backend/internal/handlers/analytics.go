@@ -1,6 +1,9 @@
 package handlers
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
 	"time"
 
 	"github.com/aman1117/backend/internal/constants"
@@ -8,6 +11,7 @@ import (
 	"github.com/aman1117/backend/internal/logger"
 	"github.com/aman1117/backend/internal/response"
 	"github.com/aman1117/backend/internal/services"
+	"github.com/aman1117/backend/pkg/redis"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -35,6 +39,7 @@ func NewAnalyticsHandler(analyticsSvc *services.AnalyticsService, authSvc *servi
 // @Produce json
 // @Security BearerAuth
 // @Param request body dto.GetWeekAnalyticsRequest true "Username and week start date"
+// @Param week_offset query int false "Weeks before the current IST week (0 = this week, max 104); overrides week_start"
 // @Success 200 {object} dto.WeekAnalyticsResponse "Weekly analytics data"
 // @Failure 400 {object} dto.ErrorResponse "Validation error or user not found"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
@@ -46,10 +51,24 @@ func (h *AnalyticsHandler) GetWeekAnalytics(c *fiber.Ctx) error {
 		return response.InvalidRequest(c)
 	}
 
+	// week_offset pages back from the current week; otherwise week_start is required
+	weekOffset := -1
+	if raw := c.Query("week_offset"); raw != "" {
+		offset, err := strconv.Atoi(raw)
+		if err != nil || offset < 0 || offset > constants.WeekAnalyticsMaxOffset {
+			return response.BadRequest(c, fmt.Sprintf("week_offset must be between 0 and %d", constants.WeekAnalyticsMaxOffset), constants.ErrCodeInvalidRequest)
+		}
+		weekOffset = offset
+	}
+
 	// Parse week start date
-	weekStart, err := time.Parse(constants.DateFormat, req.WeekStart)
-	if err != nil {
-		return response.BadRequest(c, "Invalid week_start format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+	var weekStart time.Time
+	if weekOffset < 0 {
+		var err error
+		weekStart, err = time.Parse(constants.DateFormat, req.WeekStart)
+		if err != nil {
+			return response.BadRequest(c, "Invalid week_start format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+		}
 	}
 
 	currentUserID := getUserID(c)
@@ -69,7 +88,12 @@ func (h *AnalyticsHandler) GetWeekAnalytics(c *fiber.Ctx) error {
 	}
 
 	// Get analytics
-	analytics, err := h.analyticsSvc.GetWeekAnalytics(user.ID, weekStart)
+	var analytics *dto.WeekAnalyticsResponse
+	if weekOffset >= 0 {
+		analytics, err = h.analyticsSvc.GetWeekAnalyticsByOffset(user.ID, weekOffset)
+	} else {
+		analytics, err = h.analyticsSvc.GetWeekAnalytics(user.ID, weekStart)
+	}
 	if err != nil {
 		logger.Sugar.Errorw("Analytics fetch failed", "user_id", user.ID, "error", err)
 		return response.InternalError(c, "Failed to fetch analytics", constants.ErrCodeFetchFailed)
@@ -77,3 +101,189 @@ func (h *AnalyticsHandler) GetWeekAnalytics(c *fiber.Ctx) error {
 
 	return response.JSON(c, analytics)
 }
+
+// GetMonthAnalytics handles monthly analytics retrieval
+// @Summary Get monthly analytics
+// @Description Retrieve total hours, per-activity summary, best day and active-day count for a calendar month (IST)
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.GetMonthAnalyticsRequest true "Username, year and month"
+// @Success 200 {object} dto.MonthAnalyticsResponse "Monthly analytics data"
+// @Failure 400 {object} dto.ErrorResponse "Validation error or user not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Private account"
+// @Router /get-month-analytics [post]
+func (h *AnalyticsHandler) GetMonthAnalytics(c *fiber.Ctx) error {
+	var req dto.GetMonthAnalyticsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	if req.Month < 1 || req.Month > 12 || req.Year < 1 {
+		return response.BadRequest(c, "Invalid year or month", constants.ErrCodeInvalidDate)
+	}
+
+	currentUserID := getUserID(c)
+	traceID := getTraceID(c)
+
+	// Find target user
+	user, err := h.authSvc.GetUserByUsername(req.Username)
+	if err != nil || user == nil {
+		logger.LogWithContext(traceID, currentUserID).Warnw("Analytics fetch failed - user not found", "target_username", req.Username)
+		return response.UserNotFound(c)
+	}
+
+	// Check privacy
+	if !h.profileSvc.CanViewProfile(user, currentUserID) {
+		logger.LogWithContext(traceID, currentUserID).Debugw("Analytics access denied - private account", "target_username", req.Username)
+		return response.PrivateAccount(c)
+	}
+
+	analytics, err := h.analyticsSvc.AggregateMonth(user.ID, req.Year, req.Month)
+	if err != nil {
+		if err.Error() == "period is in the future" {
+			return response.BadRequest(c, "Month is in the future", constants.ErrCodeInvalidDate)
+		}
+		logger.Sugar.Errorw("Monthly analytics fetch failed", "user_id", user.ID, "error", err)
+		return response.InternalError(c, "Failed to fetch analytics", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, analytics)
+}
+
+// GetYearAnalytics handles yearly analytics retrieval
+// @Summary Get yearly analytics
+// @Description Retrieve total hours, monthly totals, per-activity summary, best day and active-day count for a calendar year (IST)
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.GetYearAnalyticsRequest true "Username and year"
+// @Success 200 {object} dto.YearAnalyticsResponse "Yearly analytics data"
+// @Failure 400 {object} dto.ErrorResponse "Validation error or user not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Private account"
+// @Router /get-year-analytics [post]
+func (h *AnalyticsHandler) GetYearAnalytics(c *fiber.Ctx) error {
+	var req dto.GetYearAnalyticsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	if req.Year < 1 {
+		return response.BadRequest(c, "Invalid year", constants.ErrCodeInvalidDate)
+	}
+
+	currentUserID := getUserID(c)
+	traceID := getTraceID(c)
+
+	// Find target user
+	user, err := h.authSvc.GetUserByUsername(req.Username)
+	if err != nil || user == nil {
+		logger.LogWithContext(traceID, currentUserID).Warnw("Analytics fetch failed - user not found", "target_username", req.Username)
+		return response.UserNotFound(c)
+	}
+
+	// Check privacy
+	if !h.profileSvc.CanViewProfile(user, currentUserID) {
+		logger.LogWithContext(traceID, currentUserID).Debugw("Analytics access denied - private account", "target_username", req.Username)
+		return response.PrivateAccount(c)
+	}
+
+	analytics, err := h.analyticsSvc.AggregateYear(user.ID, req.Year)
+	if err != nil {
+		if err.Error() == "period is in the future" {
+			return response.BadRequest(c, "Year is in the future", constants.ErrCodeInvalidDate)
+		}
+		logger.Sugar.Errorw("Yearly analytics fetch failed", "user_id", user.ID, "error", err)
+		return response.InternalError(c, "Failed to fetch analytics", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, analytics)
+}
+
+// GetWeekdayAnalytics handles the "most productive day of week" insight
+// @Summary Get weekday insight
+// @Description Average total hours per weekday (IST) over a lookback window, Monday first
+// @Tags Analytics
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.GetWeekdayAnalyticsRequest true "Username, lookback window and zero-day flag"
+// @Success 200 {object} dto.WeekdayAnalyticsResponse "Weekday averages"
+// @Failure 400 {object} dto.ErrorResponse "Validation error or user not found"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Private account"
+// @Router /get-weekday-analytics [post]
+func (h *AnalyticsHandler) GetWeekdayAnalytics(c *fiber.Ctx) error {
+	var req dto.GetWeekdayAnalyticsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	lookbackDays := constants.WeekdayInsightDefaultLookbackDays
+	if req.LookbackDays != nil {
+		lookbackDays = *req.LookbackDays
+	}
+	if lookbackDays < 1 || lookbackDays > constants.WeekdayInsightMaxLookbackDays {
+		return response.BadRequest(c, fmt.Sprintf("lookback_days must be between 1 and %d", constants.WeekdayInsightMaxLookbackDays), constants.ErrCodeInvalidDateRange)
+	}
+
+	currentUserID := getUserID(c)
+	traceID := getTraceID(c)
+
+	// Find target user
+	user, err := h.authSvc.GetUserByUsername(req.Username)
+	if err != nil || user == nil {
+		logger.LogWithContext(traceID, currentUserID).Warnw("Analytics fetch failed - user not found", "target_username", req.Username)
+		return response.UserNotFound(c)
+	}
+
+	// Check privacy
+	if !h.profileSvc.CanViewProfile(user, currentUserID) {
+		logger.LogWithContext(traceID, currentUserID).Debugw("Analytics access denied - private account", "target_username", req.Username)
+		return response.PrivateAccount(c)
+	}
+
+	analytics, err := h.analyticsSvc.AggregateByWeekday(user.ID, lookbackDays, req.IncludeZeroDays)
+	if err != nil {
+		logger.Sugar.Errorw("Weekday analytics fetch failed", "user_id", user.ID, "error", err)
+		return response.InternalError(c, "Failed to fetch analytics", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, analytics)
+}
+
+// GetTrendingActivities returns the most popular activity types right now
+// @Summary Get trending activities
+// @Description Top activity types by total hours logged across public accounts over the last 7 days (IST). Cached for a few minutes.
+// @Tags Analytics
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TrendingActivitiesResponse "Trending activity types"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Server error"
+// @Router /trending/activities [get]
+func (h *AnalyticsHandler) GetTrendingActivities(c *fiber.Ctx) error {
+	log := logger.LogWithContext(getTraceID(c), getUserID(c))
+
+	// The result is the same for everyone, so a single cache entry serves all users
+	if cached, err := redis.GetTrendingCache(c.Context(), redis.TrendingActivitiesCacheKey); err == nil && cached != "" {
+		var cachedResponse dto.TrendingActivitiesResponse
+		if err := json.Unmarshal([]byte(cached), &cachedResponse); err == nil {
+			return response.JSON(c, cachedResponse)
+		}
+		log.Warnw("Failed to unmarshal trending activities cache", "error", err)
+	}
+
+	trending, err := h.analyticsSvc.GetTrendingActivities()
+	if err != nil {
+		log.Errorw("Trending activities fetch failed", "error", err)
+		return response.InternalError(c, "Failed to fetch trending activities", constants.ErrCodeFetchFailed)
+	}
+
+	if cacheData, err := json.Marshal(trending); err == nil {
+		_ = redis.SetTrendingCache(c.Context(), redis.TrendingActivitiesCacheKey, string(cacheData))
+	}
+
+	return response.JSON(c, trending)
+}
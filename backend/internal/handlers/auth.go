@@ -3,11 +3,16 @@ package handlers
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
 	"time"
 
 	"github.com/aman1117/backend/internal/constants"
 	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/internal/response"
 	"github.com/aman1117/backend/internal/services"
 	"github.com/aman1117/backend/internal/validator"
@@ -19,15 +24,19 @@ import (
 type AuthHandler struct {
 	authSvc    *services.AuthService
 	tokenSvc   *TokenService
+	refreshSvc *services.RefreshTokenService
+	sessionSvc *services.SessionService
 	profileSvc *services.ProfileService
 	emailSvc   *services.EmailService
 }
 
 // NewAuthHandler creates a new AuthHandler
-func NewAuthHandler(authSvc *services.AuthService, tokenSvc *TokenService, profileSvc *services.ProfileService, emailSvc *services.EmailService) *AuthHandler {
+func NewAuthHandler(authSvc *services.AuthService, tokenSvc *TokenService, refreshSvc *services.RefreshTokenService, sessionSvc *services.SessionService, profileSvc *services.ProfileService, emailSvc *services.EmailService) *AuthHandler {
 	return &AuthHandler{
 		authSvc:    authSvc,
 		tokenSvc:   tokenSvc,
+		refreshSvc: refreshSvc,
+		sessionSvc: sessionSvc,
 		profileSvc: profileSvc,
 		emailSvc:   emailSvc,
 	}
@@ -66,6 +75,10 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	req.Username = validator.SanitizeUsername(req.Username)
 
 	if err := h.authSvc.Register(req.Email, req.Username, req.Password); err != nil {
+		var verr *validator.ValidationError
+		if errors.As(err, &verr) {
+			return response.BadRequest(c, verr.Message, verr.ErrorCode)
+		}
 		logger.Sugar.Warnw("Registration failed", "email", req.Email, "username", req.Username, "error", err)
 		return response.BadRequest(c, "Could not create user (maybe email/username already used)", constants.ErrCodeUserExists)
 	}
@@ -107,13 +120,15 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 
 // Login handles user login
 // @Summary User login
-// @Description Authenticate user with email/username and password, returns JWT token
+// @Description Authenticate user with email/username and password, returns a JWT access token and a refresh token
 // @Tags Authentication
 // @Accept json
 // @Produce json
 // @Param request body dto.LoginRequest true "Login credentials"
 // @Success 200 {object} dto.LoginResponse "Login successful with JWT token"
 // @Failure 400 {object} dto.ErrorResponse "Invalid credentials"
+// @Failure 401 {object} dto.ErrorResponse "Two-factor code required (retry with two_factor_code)"
+// @Failure 429 {object} dto.ErrorResponse "Account temporarily locked after repeated failed attempts (Retry-After header set)"
 // @Router /login [post]
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req dto.LoginRequest
@@ -125,8 +140,24 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return response.MissingFields(c)
 	}
 
-	user, err := h.authSvc.Authenticate(req.Identifier, req.Password)
+	user, err := h.authSvc.Authenticate(c.Context(), req.Identifier, req.Password, req.TwoFactorCode)
 	if err != nil {
+		var locked *services.AccountLockedError
+		if errors.As(err, &locked) {
+			retryAfter := int(math.Ceil(locked.RetryAfter.Seconds()))
+			logger.Sugar.Warnw("Login attempt on locked account", "identifier", req.Identifier, "retry_after", retryAfter)
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfter))
+			return response.Error(c, fiber.StatusTooManyRequests,
+				fmt.Sprintf("Too many failed login attempts. Try again in %d minutes.", int(math.Ceil(locked.RetryAfter.Minutes()))),
+				constants.ErrCodeAccountLocked)
+		}
+		if errors.Is(err, services.ErrTwoFactorRequired) {
+			return response.Unauthorized(c, "Two-factor code required", constants.ErrCodeTwoFactorRequired)
+		}
+		if errors.Is(err, services.ErrInvalidTwoFactorCode) {
+			logger.Sugar.Warnw("Invalid two-factor code attempt", "identifier", req.Identifier)
+			return response.BadRequest(c, "Invalid two-factor code", constants.ErrCodeInvalidTwoFactor)
+		}
 		if err.Error() == "user not found" {
 			logger.Sugar.Warnw("Login attempt with non-existent user", "identifier", req.Identifier)
 			return response.BadRequest(c, "Invalid credentials", constants.ErrCodeInvalidCredentials)
@@ -135,12 +166,24 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid password", constants.ErrCodeInvalidPassword)
 	}
 
-	token, exp, expiresIn, err := h.tokenSvc.Generate(user)
+	session, err := h.sessionSvc.Start(user.ID, c.Get(fiber.HeaderUserAgent), c.IP())
+	if err != nil {
+		logger.LogWithFullContext(getTraceID(c), user.ID, user.Username).Errorw("Session creation failed", "error", err)
+		return response.BadRequest(c, "Failed to generate token", constants.ErrCodeTokenGenFailed)
+	}
+
+	token, exp, expiresIn, err := h.tokenSvc.Generate(user, session.ID)
 	if err != nil {
 		logger.LogWithFullContext(getTraceID(c), user.ID, user.Username).Errorw("Token generation failed", "error", err)
 		return response.BadRequest(c, "Failed to generate token", constants.ErrCodeTokenGenFailed)
 	}
 
+	refreshToken, refreshExp, err := h.refreshSvc.Issue(user.ID, session.ID)
+	if err != nil {
+		logger.LogWithFullContext(getTraceID(c), user.ID, user.Username).Errorw("Refresh token generation failed", "error", err)
+		return response.BadRequest(c, "Failed to generate token", constants.ErrCodeTokenGenFailed)
+	}
+
 	logger.LogWithFullContext(getTraceID(c), user.ID, user.Username).Info("User logged in")
 
 	return response.JSON(c, dto.LoginResponse{
@@ -149,12 +192,113 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 		TokenType:   "Bearer",
 		ExpiresAt:   exp.UTC().Format(time.RFC3339),
 		ExpiresIn:   expiresIn,
+
+		IsDeactivated: user.IsDeactivated,
+
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExp.UTC().Format(time.RFC3339),
 	})
 }
 
+// Refresh exchanges a refresh token for a new access token
+// @Summary Refresh access token
+// @Description Exchange a refresh token for a new access token. The refresh token is rotated: the old one stops working and a new one is returned. Replaying a rotated token revokes all of the user's refresh tokens.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} dto.LoginResponse "New access and refresh tokens"
+// @Failure 401 {object} dto.ErrorResponse "Invalid, expired or revoked refresh token"
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	if req.RefreshToken == "" {
+		return response.MissingFields(c)
+	}
+
+	rotated, err := h.refreshSvc.Rotate(c.Context(), req.RefreshToken, c.IP())
+	if err != nil {
+		if errors.Is(err, services.ErrInvalidRefreshToken) {
+			return response.Unauthorized(c, "Invalid or expired refresh token", constants.ErrCodeInvalidRefresh)
+		}
+		logger.Sugar.Errorw("Refresh token rotation failed", "trace_id", getTraceID(c), "error", err)
+		return response.InternalError(c, "Failed to refresh token", constants.ErrCodeTokenGenFailed)
+	}
+
+	user := rotated.User
+	token, exp, expiresIn, err := h.tokenSvc.Generate(user, rotated.SessionID)
+	if err != nil {
+		logger.LogWithFullContext(getTraceID(c), user.ID, user.Username).Errorw("Token generation failed", "error", err)
+		return response.InternalError(c, "Failed to generate token", constants.ErrCodeTokenGenFailed)
+	}
+
+	return response.JSON(c, dto.LoginResponse{
+		Success:     true,
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   exp.UTC().Format(time.RFC3339),
+		ExpiresIn:   expiresIn,
+
+		IsDeactivated: user.IsDeactivated,
+
+		RefreshToken:     rotated.RefreshToken,
+		RefreshExpiresAt: rotated.ExpiresAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// Logout ends the session of a refresh token
+// @Summary Log out
+// @Description End the session the given refresh token belongs to. Its refresh and access tokens stop working.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.RefreshTokenRequest true "Refresh token"
+// @Success 200 {object} dto.SuccessResponse "Logged out"
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	var req dto.RefreshTokenRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	if req.RefreshToken == "" {
+		return response.MissingFields(c)
+	}
+
+	if err := h.refreshSvc.Revoke(c.Context(), req.RefreshToken); err != nil {
+		logger.Sugar.Errorw("Failed to revoke refresh token", "trace_id", getTraceID(c), "error", err)
+		return response.InternalError(c, "Failed to log out", constants.ErrCodeUpdateFailed)
+	}
+
+	return response.Success(c, constants.MsgLoggedOut)
+}
+
+// LogoutAll invalidates every access and refresh token of the user
+// @Summary Log out everywhere
+// @Description Invalidate all access tokens and refresh tokens of the authenticated user, on every device
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse "Logged out of all devices"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /auth/logout-all [post]
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	if err := h.refreshSvc.LogoutEverywhere(c.Context(), userID); err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to log out everywhere", "error", err)
+		return response.InternalError(c, "Failed to log out", constants.ErrCodeUpdateFailed)
+	}
+
+	logger.LogWithContext(getTraceID(c), userID).Info("User logged out everywhere")
+	return response.Success(c, constants.MsgLoggedOutEverywhere)
+}
+
 // UpdateUsername handles username update requests
 // @Summary Update username
-// @Description Update the authenticated user's username
+// @Description Update the authenticated user's username. Allowed once every 30 days; the old username stays reserved for you for 30 days.
 // @Tags Profile
 // @Accept json
 // @Produce json
@@ -163,6 +307,7 @@ func (h *AuthHandler) Login(c *fiber.Ctx) error {
 // @Success 200 {object} dto.UsernameUpdateResponse "Username updated successfully"
 // @Failure 400 {object} dto.ErrorResponse "Validation error or username taken"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 429 {object} dto.UsernameCooldownResponse "Username changed too recently"
 // @Router /update-username [post]
 func (h *AuthHandler) UpdateUsername(c *fiber.Ctx) error {
 	userID := getUserID(c)
@@ -184,6 +329,21 @@ func (h *AuthHandler) UpdateUsername(c *fiber.Ctx) error {
 
 	log := logger.LogWithContext(getTraceID(c), userID)
 	if err := h.authSvc.UpdateUsername(userID, newUsername); err != nil {
+		var cooldown *repository.UsernameCooldownError
+		if errors.As(err, &cooldown) {
+			return c.Status(fiber.StatusTooManyRequests).JSON(dto.UsernameCooldownResponse{
+				ErrorResponse: dto.ErrorResponse{
+					Success:   false,
+					Error:     fmt.Sprintf("You can change your username again on %s", cooldown.NextAllowedAt.UTC().Format("January 2, 2006")),
+					ErrorCode: constants.ErrCodeUsernameCooldown,
+				},
+				NextAllowedAt: cooldown.NextAllowedAt.UTC().Format(time.RFC3339),
+			})
+		}
+		var verr *validator.ValidationError
+		if errors.As(err, &verr) {
+			return response.BadRequest(c, verr.Message, verr.ErrorCode)
+		}
 		log.Warnw("Username update failed", "new_username", newUsername, "error", err)
 		return response.BadRequest(c, "Username already taken or update failed", constants.ErrCodeUsernameTaken)
 	}
@@ -235,6 +395,10 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 			log.Warn("Invalid current password for password change")
 			return response.BadRequest(c, "Current password is incorrect", constants.ErrCodeInvalidPassword)
 		}
+		var verr *validator.ValidationError
+		if errors.As(err, &verr) {
+			return response.BadRequest(c, verr.Message, verr.ErrorCode)
+		}
 		log.Errorw("Failed to change password", "error", err)
 		return response.InternalError(c, "Failed to update password", constants.ErrCodeUpdateFailed)
 	}
@@ -243,6 +407,218 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 	return response.Success(c, constants.MsgPasswordChanged)
 }
 
+// DeleteAccount permanently deletes the authenticated user's account
+// @Summary Delete account
+// @Description Permanently delete the authenticated user's account and all associated data. Requires the current password.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.DeleteAccountRequest true "Current password"
+// @Success 200 {object} dto.DeleteAccountResponse "Account deleted with a summary of removed data"
+// @Failure 400 {object} dto.ErrorResponse "Missing or incorrect password"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /me/account [delete]
+func (h *AuthHandler) DeleteAccount(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	log := logger.LogWithFullContext(getTraceID(c), userID, getUsername(c))
+
+	var req dto.DeleteAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+
+	if req.Password == "" {
+		return response.MissingFields(c)
+	}
+
+	summary, err := h.authSvc.DeleteAccount(userID, req.Password)
+	if err != nil {
+		switch err.Error() {
+		case "invalid password":
+			log.Warn("Invalid password for account deletion")
+			return response.BadRequest(c, "Password is incorrect", constants.ErrCodeInvalidPassword)
+		case "user not found":
+			return response.UserNotFound(c)
+		}
+		log.Errorw("Failed to delete account", "error", err)
+		return response.InternalError(c, "Failed to delete account", constants.ErrCodeDeleteFailed)
+	}
+
+	log.Infow("Account deleted", "rows_deleted", summary.RowsDeleted, "blobs_scheduled", summary.BlobsScheduled)
+	return response.JSON(c, dto.DeleteAccountResponse{
+		Success: true,
+		Message: constants.MsgAccountDeleted,
+		Data:    *summary,
+	})
+}
+
+// ChangeEmail starts an email change for the authenticated user
+// @Summary Request email change
+// @Description Verify the password and send a confirmation link to the new email address. The email is only changed once the link is used.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ChangeEmailRequest true "New email and current password"
+// @Success 200 {object} dto.SuccessResponse "Confirmation email sent"
+// @Failure 400 {object} dto.ErrorResponse "Validation error, incorrect password or email taken"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /auth/change-email [post]
+func (h *AuthHandler) ChangeEmail(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	log := logger.LogWithFullContext(getTraceID(c), userID, getUsername(c))
+
+	var req dto.ChangeEmailRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+
+	if req.NewEmail == "" || req.Password == "" {
+		return response.MissingFields(c)
+	}
+
+	if err := validator.ValidateEmail(req.NewEmail); err != nil {
+		return response.BadRequest(c, err.Message, err.ErrorCode)
+	}
+	req.NewEmail = validator.SanitizeEmail(req.NewEmail)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := h.authSvc.RequestEmailChange(ctx, userID, req.NewEmail, req.Password); err != nil {
+		switch {
+		case err.Error() == "invalid password":
+			log.Warn("Invalid password for email change")
+			return response.BadRequest(c, "Password is incorrect", constants.ErrCodeInvalidPassword)
+		case err.Error() == "email unchanged":
+			return response.BadRequest(c, "New email is the same as the current one", constants.ErrCodeSameEmail)
+		case errors.Is(err, repository.ErrEmailTaken):
+			return response.BadRequest(c, "Email is already in use", constants.ErrCodeEmailTaken)
+		case err.Error() == "user not found":
+			return response.UserNotFound(c)
+		}
+		log.Errorw("Failed to request email change", "error", err)
+		return response.InternalError(c, "Failed to send confirmation email", constants.ErrCodeServerError)
+	}
+
+	log.Infow("Email change requested", "new_email", req.NewEmail)
+	return response.Success(c, constants.MsgEmailChangeSent)
+}
+
+// ConfirmEmailChange applies a pending email change using the token from the confirmation email
+// @Summary Confirm email change
+// @Description Apply a pending email change. The new address is marked verified.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Param request body dto.ConfirmEmailChangeRequest true "Confirmation token"
+// @Success 200 {object} dto.SuccessResponse "Email updated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid or expired token, or email taken"
+// @Failure 500 {object} dto.ErrorResponse "Internal server error"
+// @Router /auth/confirm-email-change [post]
+func (h *AuthHandler) ConfirmEmailChange(c *fiber.Ctx) error {
+	var req dto.ConfirmEmailChangeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+
+	if req.Token == "" {
+		return response.BadRequest(c, "Confirmation token is required", constants.ErrCodeMissingToken)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	user, err := h.authSvc.ConfirmEmailChange(ctx, req.Token)
+	if err != nil {
+		switch {
+		case err.Error() == "invalid or expired token":
+			return response.BadRequest(c, "Invalid or expired confirmation link", constants.ErrCodeInvalidEmailChangeToken)
+		case errors.Is(err, repository.ErrEmailTaken):
+			return response.BadRequest(c, "Email is already in use by another account", constants.ErrCodeEmailTaken)
+		case err.Error() == "user not found":
+			return response.BadRequest(c, "User not found", constants.ErrCodeUserNotFound)
+		}
+		logger.Sugar.Errorw("Failed to confirm email change", "error", err)
+		return response.ServerError(c)
+	}
+
+	logger.LogWithUserID(user.ID).Infow("Email changed", "username", user.Username)
+	return response.Success(c, constants.MsgEmailChanged)
+}
+
+// DeactivateAccount hides the authenticated user's account without deleting data
+// @Summary Deactivate account
+// @Description Temporarily hide the authenticated user's profile, stories and list entries from others. Requires the current password. Login keeps working so the account can be reactivated.
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.DeactivateAccountRequest true "Current password"
+// @Success 200 {object} dto.SuccessResponse "Account deactivated"
+// @Failure 400 {object} dto.ErrorResponse "Missing or incorrect password"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Router /me/deactivate [post]
+func (h *AuthHandler) DeactivateAccount(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	log := logger.LogWithFullContext(getTraceID(c), userID, getUsername(c))
+
+	var req dto.DeactivateAccountRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+
+	if req.Password == "" {
+		return response.MissingFields(c)
+	}
+
+	if err := h.authSvc.Deactivate(userID, req.Password); err != nil {
+		switch err.Error() {
+		case "invalid password":
+			log.Warn("Invalid password for account deactivation")
+			return response.BadRequest(c, "Password is incorrect", constants.ErrCodeInvalidPassword)
+		case "user not found":
+			return response.UserNotFound(c)
+		}
+		log.Errorw("Failed to deactivate account", "error", err)
+		return response.InternalError(c, "Failed to deactivate account", constants.ErrCodeUpdateFailed)
+	}
+
+	log.Info("Account deactivated")
+	return response.Success(c, constants.MsgDeactivated)
+}
+
+// ReactivateAccount restores a deactivated account's visibility
+// @Summary Reactivate account
+// @Description Make a deactivated account visible to other users again
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SuccessResponse "Account reactivated"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Router /me/reactivate [post]
+func (h *AuthHandler) ReactivateAccount(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	log := logger.LogWithFullContext(getTraceID(c), userID, getUsername(c))
+
+	if err := h.authSvc.Reactivate(userID); err != nil {
+		if err.Error() == "user not found" {
+			return response.UserNotFound(c)
+		}
+		log.Errorw("Failed to reactivate account", "error", err)
+		return response.InternalError(c, "Failed to reactivate account", constants.ErrCodeUpdateFailed)
+	}
+
+	log.Info("Account reactivated")
+	return response.Success(c, constants.MsgReactivated)
+}
+
 // ==================== Helper functions ====================
 
 func getUserID(c *fiber.Ctx) uint {
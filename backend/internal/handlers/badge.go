@@ -1,25 +1,31 @@
 package handlers
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/aman1117/backend/internal/constants"
 	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
 	"github.com/aman1117/backend/internal/response"
 	"github.com/aman1117/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
 )
 
 // BadgeHandler handles badge-related requests
 type BadgeHandler struct {
-	badgeSvc *services.BadgeService
-	authSvc  *services.AuthService
+	badgeSvc  *services.BadgeService
+	authSvc   *services.AuthService
+	followSvc *services.FollowService
 }
 
 // NewBadgeHandler creates a new BadgeHandler
-func NewBadgeHandler(badgeSvc *services.BadgeService, authSvc *services.AuthService) *BadgeHandler {
+func NewBadgeHandler(badgeSvc *services.BadgeService, authSvc *services.AuthService, followSvc *services.FollowService) *BadgeHandler {
 	return &BadgeHandler{
-		badgeSvc: badgeSvc,
-		authSvc:  authSvc,
+		badgeSvc:  badgeSvc,
+		authSvc:   authSvc,
+		followSvc: followSvc,
 	}
 }
 
@@ -49,9 +55,9 @@ func (h *BadgeHandler) GetBadges(c *fiber.Ctx) error {
 	})
 }
 
-// GetBadgesByUsername handles getting another user's badges (always public)
+// GetBadgesByUsername handles getting another user's badges by username
 // @Summary Get user badges by username
-// @Description Retrieve all badges for a user by username (badges are always public)
+// @Description Retrieve all badges for a user by username. Private accounts are only visible to approved followers.
 // @Tags Badges
 // @Accept json
 // @Produce json
@@ -60,6 +66,7 @@ func (h *BadgeHandler) GetBadges(c *fiber.Ctx) error {
 // @Success 200 {object} dto.BadgesResponse "User badges"
 // @Failure 400 {object} dto.ErrorResponse "User not found"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Private account or blocked"
 // @Router /badges/user [post]
 func (h *BadgeHandler) GetBadgesByUsername(c *fiber.Ctx) error {
 	var req dto.GetBadgesByUsernameRequest
@@ -68,19 +75,22 @@ func (h *BadgeHandler) GetBadgesByUsername(c *fiber.Ctx) error {
 	}
 
 	userID := getUserID(c)
-	traceID := getTraceID(c)
+	log := logger.LogWithContext(getTraceID(c), userID)
 
 	// Find target user
 	targetUser, err := h.authSvc.GetUserByUsername(req.Username)
 	if err != nil || targetUser == nil {
-		logger.LogWithContext(traceID, userID).Warnw("Badge fetch failed - user not found", "target_username", req.Username)
+		log.Warnw("Badge fetch failed - user not found", "target_username", req.Username)
 		return response.UserNotFound(c)
 	}
 
-	// Get badges (always public, no privacy check)
+	if err := h.followSvc.CheckProfileAccess(c.Context(), userID, targetUser.ID); err != nil {
+		return badgeAccessError(c, log, err, targetUser.ID)
+	}
+
 	badges, err := h.badgeSvc.GetUserBadges(targetUser.ID)
 	if err != nil {
-		logger.LogWithContext(traceID, userID).Errorw("Failed to get badges", "error", err, "target_username", req.Username)
+		log.Errorw("Failed to get badges", "error", err, "target_username", req.Username)
 		return response.InternalError(c, "Failed to get badges", constants.ErrCodeServerError)
 	}
 
@@ -89,3 +99,56 @@ func (h *BadgeHandler) GetBadgesByUsername(c *fiber.Ctx) error {
 		Badges:  badges,
 	})
 }
+
+// GetUserBadges handles getting another user's earned badges by ID
+// @Summary Get a user's earned badges
+// @Description Retrieve the badges a user has earned, with earned_at. Unearned badges and next-badge progress are not included. Private accounts are only visible to approved followers.
+// @Tags Badges
+// @Produce json
+// @Security BearerAuth
+// @Param userId path int true "User ID"
+// @Success 200 {object} dto.BadgesResponse "Earned badges"
+// @Failure 400 {object} dto.ErrorResponse "Invalid user ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Private account or blocked"
+// @Failure 404 {object} dto.ErrorResponse "User not found"
+// @Router /users/{userId}/badges [get]
+func (h *BadgeHandler) GetUserBadges(c *fiber.Ctx) error {
+	viewerID := getUserID(c)
+	log := logger.LogWithContext(getTraceID(c), viewerID)
+
+	targetID, err := strconv.ParseUint(c.Params("userId"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid user ID", constants.ErrCodeInvalidRequest)
+	}
+
+	if err := h.followSvc.CheckProfileAccess(c.Context(), viewerID, uint(targetID)); err != nil {
+		return badgeAccessError(c, log, err, uint(targetID))
+	}
+
+	badges, err := h.badgeSvc.GetEarnedBadges(uint(targetID))
+	if err != nil {
+		log.Errorw("Failed to get badges", "error", err, "target_user_id", targetID)
+		return response.InternalError(c, "Failed to get badges", constants.ErrCodeServerError)
+	}
+
+	return response.JSON(c, dto.BadgesResponse{
+		Success: true,
+		Badges:  badges,
+	})
+}
+
+// badgeAccessError responds to a failed profile access check for a user's badges
+func badgeAccessError(c *fiber.Ctx, log *zap.SugaredLogger, err error, targetID uint) error {
+	errMsg := err.Error()
+	switch {
+	case strings.Contains(errMsg, constants.ErrCodeAccountPrivate):
+		return response.Error(c, fiber.StatusForbidden, "This account is private", constants.ErrCodeAccountPrivate)
+	case strings.Contains(errMsg, constants.ErrCodeBlocked):
+		return response.Error(c, fiber.StatusForbidden, "You cannot view this user's badges", constants.ErrCodeBlocked)
+	case strings.Contains(errMsg, constants.ErrCodeUserNotFound):
+		return response.Error(c, fiber.StatusNotFound, "User not found", constants.ErrCodeUserNotFound)
+	}
+	log.Errorw("Failed to check badge access", "error", err, "target_user_id", targetID)
+	return response.InternalError(c, "Failed to get badges", constants.ErrCodeServerError)
+}
@@ -0,0 +1,56 @@
+package handlers
+
+import (
+	"bufio"
+	"fmt"
+	"time"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ExportHandler handles account data export requests
+type ExportHandler struct {
+	exportSvc *services.ExportService
+}
+
+// NewExportHandler creates a new ExportHandler
+func NewExportHandler(exportSvc *services.ExportService) *ExportHandler {
+	return &ExportHandler{exportSvc: exportSvc}
+}
+
+// ExportAccount streams a JSON dump of the current user's account data
+// @Summary Export account data
+// @Description Download your profile, activities, streaks, badges, follows, story photos and notifications as JSON. Other users appear only by user ID; story views, likes and replies are included as counts.
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {file} file "JSON export"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/export [get]
+func (h *ExportHandler) ExportAccount(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	filename := fmt.Sprintf("account-export-%s.json", time.Now().Format(constants.DateFormat))
+	c.Set(fiber.HeaderContentType, fiber.MIMEApplicationJSONCharsetUTF8)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, filename))
+
+	// Sections are written as they are read; a mid-stream failure can only be logged
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		start := time.Now()
+		if err := h.exportSvc.WriteAccountExport(userID, w); err != nil {
+			logger.LogWithContext(traceID, userID).Errorw("Account export failed", "error", err)
+			return
+		}
+		if err := w.Flush(); err != nil {
+			logger.LogWithContext(traceID, userID).Errorw("Account export flush failed", "error", err)
+			return
+		}
+		logger.LogWithContext(traceID, userID).Infow("Account export completed", "duration_ms", time.Since(start).Milliseconds())
+	})
+
+	return nil
+}
@@ -3,8 +3,6 @@ package handlers
 
 import (
 	"context"
-	"encoding/base64"
-	"encoding/json"
 	"strconv"
 	"strings"
 	"time"
@@ -80,6 +78,9 @@ func (h *FollowHandler) FollowUser(c *fiber.Ctx) error {
 		if strings.Contains(errMsg, constants.ErrCodeCannotFollowSelf) {
 			return response.Error(c, fiber.StatusBadRequest, "Cannot follow yourself", constants.ErrCodeCannotFollowSelf)
 		}
+		if strings.Contains(errMsg, constants.ErrCodeBlocked) {
+			return response.Error(c, fiber.StatusForbidden, "You cannot follow this user", constants.ErrCodeBlocked)
+		}
 		if strings.Contains(errMsg, constants.ErrCodeUserNotFound) {
 			return response.Error(c, fiber.StatusNotFound, "User not found", constants.ErrCodeUserNotFound)
 		}
@@ -191,6 +192,38 @@ func (h *FollowHandler) CancelFollowRequest(c *fiber.Ctx) error {
 	})
 }
 
+// CancelAllFollowRequests handles POST /api/me/follow-requests/outgoing/cancel-all
+// @Summary Cancel all outgoing follow requests
+// @Description Cancel every pending follow request sent by the current user
+// @Tags Follow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.CancelAllRequestsResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /me/follow-requests/outgoing/cancel-all [post]
+func (h *FollowHandler) CancelAllFollowRequests(c *fiber.Ctx) error {
+	viewerID := getUserIDFromContext(c)
+	if viewerID == 0 {
+		return response.Unauthorized(c, "Authentication required", constants.ErrCodeUnauthorized)
+	}
+
+	cancelled, err := h.followSvc.CancelAllPendingRequests(context.Background(), viewerID)
+	if err != nil {
+		logger.Sugar.Errorw("Failed to cancel all follow requests",
+			"viewer_id", viewerID,
+			"error", err,
+		)
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to cancel requests", constants.ErrCodeServerError)
+	}
+
+	return c.JSON(dto.CancelAllRequestsResponse{
+		Success:   true,
+		Cancelled: cancelled,
+		Message:   "Follow requests cancelled",
+	})
+}
+
 // ==================== Request Management ====================
 
 // GetIncomingRequests handles GET /api/me/follow-requests/incoming
@@ -255,6 +288,68 @@ func (h *FollowHandler) GetIncomingRequests(c *fiber.Ctx) error {
 	})
 }
 
+// GetOutgoingRequests handles GET /api/me/follow-requests/outgoing
+// @Summary Get outgoing follow requests
+// @Description Get paginated list of follow requests sent by the current user that are still pending
+// @Tags Follow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Number of results" default(20)
+// @Success 200 {object} dto.FollowRequestListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /me/follow-requests/outgoing [get]
+func (h *FollowHandler) GetOutgoingRequests(c *fiber.Ctx) error {
+	viewerID := getUserIDFromContext(c)
+	if viewerID == 0 {
+		return response.Unauthorized(c, "Authentication required", constants.ErrCodeUnauthorized)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	cursor := decodeCursor(c.Query("cursor"))
+
+	edges, hasMore, err := h.followSvc.GetPendingOutgoingRequests(context.Background(), viewerID, limit, cursor)
+	if err != nil {
+		logger.Sugar.Errorw("Failed to get outgoing follow requests",
+			"viewer_id", viewerID,
+			"error", err,
+		)
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get requests", constants.ErrCodeServerError)
+	}
+
+	// Fetch target user details for each request
+	requests := make([]dto.FollowRequestDTO, 0, len(edges))
+	for _, edge := range edges {
+		user, err := h.userRepo.FindByID(edge.FolloweeID)
+		if err != nil || user == nil {
+			continue
+		}
+		requests = append(requests, dto.FollowRequestDTO{
+			ID:              user.ID,
+			Username:        user.Username,
+			ProfilePic:      user.ProfilePic,
+			ProfilePicThumb: user.ProfilePicThumb,
+			Bio:             user.Bio,
+			IsVerified:      user.IsVerified,
+			RequestedAt:     edge.CreatedAt.Format(time.RFC3339),
+		})
+	}
+
+	var nextCursor string
+	if hasMore && len(edges) > 0 {
+		lastEdge := edges[len(edges)-1]
+		nextCursor = encodeCursor(lastEdge.CreatedAt, lastEdge.FolloweeID)
+	}
+
+	return c.JSON(dto.FollowRequestListResponse{
+		Success:    true,
+		Requests:   requests,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	})
+}
+
 // AcceptFollowRequest handles POST /api/me/follow-requests/:requesterId/accept
 // @Summary Accept a follow request
 // @Description Accept an incoming follow request
@@ -342,6 +437,100 @@ func (h *FollowHandler) DeclineFollowRequest(c *fiber.Ctx) error {
 	})
 }
 
+// BatchFollowRequests handles POST /api/me/follow-requests/batch
+// @Summary Accept or decline follow requests in bulk
+// @Description Accept or decline up to 100 incoming follow requests at once. Duplicate IDs are collapsed; requests that are no longer pending are reported as NO_FOLLOW_REQUEST and the caller's own ID as CANNOT_FOLLOW_SELF.
+// @Tags Follow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param body body dto.BatchFollowRequestActionRequest true "Action and requester IDs"
+// @Success 200 {object} dto.BatchFollowRequestActionResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /me/follow-requests/batch [post]
+func (h *FollowHandler) BatchFollowRequests(c *fiber.Ctx) error {
+	viewerID := getUserIDFromContext(c)
+	if viewerID == 0 {
+		return response.Unauthorized(c, "Authentication required", constants.ErrCodeUnauthorized)
+	}
+
+	var req dto.BatchFollowRequestActionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid request body", constants.ErrCodeInvalidRequest)
+	}
+
+	var accept bool
+	switch req.Action {
+	case "accept":
+		accept = true
+	case "decline":
+		accept = false
+	default:
+		return response.Error(c, fiber.StatusBadRequest, "Action must be 'accept' or 'decline'", constants.ErrCodeInvalidRequest)
+	}
+
+	// Deduplicate before capping so repeated IDs don't eat into the batch size.
+	// The caller's own ID can never have a pending request and is reported separately.
+	results := make(map[uint]dto.BatchFollowRequestResult, len(req.RequesterIDs))
+	requesterIDs := make([]uint, 0, len(req.RequesterIDs))
+	for _, id := range req.RequesterIDs {
+		if _, seen := results[id]; seen {
+			continue
+		}
+		if id == viewerID {
+			results[id] = dto.BatchFollowRequestResult{
+				Success:   false,
+				ErrorCode: constants.ErrCodeCannotFollowSelf,
+			}
+			continue
+		}
+		if len(requesterIDs) >= constants.FollowBatchMaxSize {
+			break
+		}
+		results[id] = dto.BatchFollowRequestResult{
+			Success:   false,
+			ErrorCode: constants.ErrCodeNoFollowRequest,
+		}
+		requesterIDs = append(requesterIDs, id)
+	}
+
+	if len(requesterIDs) == 0 {
+		return c.JSON(dto.BatchFollowRequestActionResponse{
+			Success: true,
+			Action:  req.Action,
+			Results: results,
+		})
+	}
+
+	transitioned, err := h.followSvc.ResolvePendingRequests(context.Background(), viewerID, requesterIDs, accept)
+	if err != nil {
+		logger.Sugar.Errorw("Failed to resolve follow requests in batch",
+			"viewer_id", viewerID,
+			"action", req.Action,
+			"count", len(requesterIDs),
+			"error", err,
+		)
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to process requests", constants.ErrCodeServerError)
+	}
+
+	for _, id := range transitioned {
+		results[id] = dto.BatchFollowRequestResult{Success: true}
+	}
+
+	// Only notify requesters whose requests actually transitioned
+	if accept && len(transitioned) > 0 {
+		go h.sendAcceptedNotifications(viewerID, transitioned)
+	}
+
+	return c.JSON(dto.BatchFollowRequestActionResponse{
+		Success:   true,
+		Action:    req.Action,
+		Processed: len(transitioned),
+		Results:   results,
+	})
+}
+
 // RemoveFollower handles DELETE /api/me/followers/:followerId
 // @Summary Remove a follower
 // @Description Remove a user from your followers list
@@ -384,6 +573,250 @@ func (h *FollowHandler) RemoveFollower(c *fiber.Ctx) error {
 	})
 }
 
+// ==================== Blocking ====================
+
+// BlockUser handles POST /api/users/:targetId/block
+// @Summary Block a user
+// @Description Block a user. Removes follow relationships in both directions and prevents either user from following the other or viewing stories.
+// @Tags Follow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param targetId path int true "Target User ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/{targetId}/block [post]
+func (h *FollowHandler) BlockUser(c *fiber.Ctx) error {
+	viewerID := getUserIDFromContext(c)
+	if viewerID == 0 {
+		return response.Unauthorized(c, "Authentication required", constants.ErrCodeUnauthorized)
+	}
+
+	targetID, err := strconv.ParseUint(c.Params("targetId"), 10, 32)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid target user ID", constants.ErrCodeInvalidRequest)
+	}
+
+	if err := h.followSvc.Block(context.Background(), viewerID, uint(targetID)); err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, constants.ErrCodeCannotBlockSelf) {
+			return response.Error(c, fiber.StatusBadRequest, "Cannot block yourself", constants.ErrCodeCannotBlockSelf)
+		}
+		if strings.Contains(errMsg, constants.ErrCodeUserNotFound) {
+			return response.Error(c, fiber.StatusNotFound, "User not found", constants.ErrCodeUserNotFound)
+		}
+		logger.Sugar.Errorw("Failed to block user",
+			"viewer_id", viewerID,
+			"target_id", targetID,
+			"error", err,
+		)
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to block user", constants.ErrCodeServerError)
+	}
+
+	return c.JSON(dto.SuccessResponse{
+		Success: true,
+		Message: "User blocked",
+	})
+}
+
+// UnblockUser handles DELETE /api/users/:targetId/block
+// @Summary Unblock a user
+// @Description Unblock a previously blocked user. Previous follow relationships are not restored.
+// @Tags Follow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param targetId path int true "Target User ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /users/{targetId}/block [delete]
+func (h *FollowHandler) UnblockUser(c *fiber.Ctx) error {
+	viewerID := getUserIDFromContext(c)
+	if viewerID == 0 {
+		return response.Unauthorized(c, "Authentication required", constants.ErrCodeUnauthorized)
+	}
+
+	targetID, err := strconv.ParseUint(c.Params("targetId"), 10, 32)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid target user ID", constants.ErrCodeInvalidRequest)
+	}
+
+	if err := h.followSvc.Unblock(context.Background(), viewerID, uint(targetID)); err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, constants.ErrCodeNotBlocked) {
+			return response.Error(c, fiber.StatusBadRequest, "User is not blocked", constants.ErrCodeNotBlocked)
+		}
+		logger.Sugar.Errorw("Failed to unblock user",
+			"viewer_id", viewerID,
+			"target_id", targetID,
+			"error", err,
+		)
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to unblock user", constants.ErrCodeServerError)
+	}
+
+	return c.JSON(dto.SuccessResponse{
+		Success: true,
+		Message: "User unblocked",
+	})
+}
+
+// GetBlockedUsers handles GET /api/me/blocked
+// @Summary Get blocked users
+// @Description Get paginated list of users blocked by the current user
+// @Tags Follow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param cursor query string false "Pagination cursor"
+// @Param limit query int false "Number of results" default(20)
+// @Success 200 {object} dto.FollowListResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /me/blocked [get]
+func (h *FollowHandler) GetBlockedUsers(c *fiber.Ctx) error {
+	viewerID := getUserIDFromContext(c)
+	if viewerID == 0 {
+		return response.Unauthorized(c, "Authentication required", constants.ErrCodeUnauthorized)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "20"))
+	cursor := decodeCursor(c.Query("cursor"))
+
+	blocks, hasMore, err := h.followSvc.GetBlockedUsers(context.Background(), viewerID, limit, cursor)
+	if err != nil {
+		logger.Sugar.Errorw("Failed to get blocked users",
+			"viewer_id", viewerID,
+			"error", err,
+		)
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get blocked users", constants.ErrCodeServerError)
+	}
+
+	// Fetch user details
+	users := make([]dto.FollowUserDTO, 0, len(blocks))
+	for _, block := range blocks {
+		user, err := h.userRepo.FindByID(block.BlockedID)
+		if err != nil || user == nil {
+			continue
+		}
+		users = append(users, dto.FollowUserDTO{
+			ID:              user.ID,
+			Username:        user.Username,
+			ProfilePic:      user.ProfilePic,
+			ProfilePicThumb: user.ProfilePicThumb,
+			IsPrivate:       user.IsPrivate,
+			IsVerified:      user.IsVerified,
+		})
+	}
+
+	var nextCursor string
+	if hasMore && len(blocks) > 0 {
+		lastBlock := blocks[len(blocks)-1]
+		nextCursor = encodeCursor(lastBlock.CreatedAt, lastBlock.BlockedID)
+	}
+
+	return c.JSON(dto.FollowListResponse{
+		Success:    true,
+		Users:      users,
+		NextCursor: nextCursor,
+		HasMore:    hasMore,
+	})
+}
+
+// ==================== Close Friends ====================
+
+// AddCloseFriend handles POST /api/me/close-friends/:userId
+// @Summary Add a close friend
+// @Description Add a user to the current user's close friends list. Close-friends-only stories are visible to them.
+// @Tags Follow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userId path int true "User ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /me/close-friends/{userId} [post]
+func (h *FollowHandler) AddCloseFriend(c *fiber.Ctx) error {
+	viewerID := getUserIDFromContext(c)
+	if viewerID == 0 {
+		return response.Unauthorized(c, "Authentication required", constants.ErrCodeUnauthorized)
+	}
+
+	friendID, err := strconv.ParseUint(c.Params("userId"), 10, 32)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", constants.ErrCodeInvalidRequest)
+	}
+
+	if err := h.followSvc.AddCloseFriend(context.Background(), viewerID, uint(friendID)); err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, constants.ErrCodeCannotCloseFriend) {
+			return response.Error(c, fiber.StatusBadRequest, "Cannot add yourself to close friends", constants.ErrCodeCannotCloseFriend)
+		}
+		if strings.Contains(errMsg, constants.ErrCodeBlocked) {
+			return response.Error(c, fiber.StatusForbidden, "Cannot add this user to close friends", constants.ErrCodeBlocked)
+		}
+		if strings.Contains(errMsg, constants.ErrCodeUserNotFound) {
+			return response.Error(c, fiber.StatusNotFound, "User not found", constants.ErrCodeUserNotFound)
+		}
+		logger.Sugar.Errorw("Failed to add close friend",
+			"viewer_id", viewerID,
+			"friend_id", friendID,
+			"error", err,
+		)
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to add close friend", constants.ErrCodeServerError)
+	}
+
+	return c.JSON(dto.SuccessResponse{
+		Success: true,
+		Message: "Added to close friends",
+	})
+}
+
+// RemoveCloseFriend handles DELETE /api/me/close-friends/:userId
+// @Summary Remove a close friend
+// @Description Remove a user from the current user's close friends list
+// @Tags Follow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userId path int true "User ID"
+// @Success 200 {object} dto.SuccessResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Router /me/close-friends/{userId} [delete]
+func (h *FollowHandler) RemoveCloseFriend(c *fiber.Ctx) error {
+	viewerID := getUserIDFromContext(c)
+	if viewerID == 0 {
+		return response.Unauthorized(c, "Authentication required", constants.ErrCodeUnauthorized)
+	}
+
+	friendID, err := strconv.ParseUint(c.Params("userId"), 10, 32)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", constants.ErrCodeInvalidRequest)
+	}
+
+	if err := h.followSvc.RemoveCloseFriend(context.Background(), viewerID, uint(friendID)); err != nil {
+		if strings.Contains(err.Error(), constants.ErrCodeNotCloseFriend) {
+			return response.Error(c, fiber.StatusBadRequest, "User is not in close friends", constants.ErrCodeNotCloseFriend)
+		}
+		logger.Sugar.Errorw("Failed to remove close friend",
+			"viewer_id", viewerID,
+			"friend_id", friendID,
+			"error", err,
+		)
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to remove close friend", constants.ErrCodeServerError)
+	}
+
+	return c.JSON(dto.SuccessResponse{
+		Success: true,
+		Message: "Removed from close friends",
+	})
+}
+
 // ==================== List Operations ====================
 
 // GetFollowers handles GET /api/users/:userId/followers
@@ -396,6 +829,7 @@ func (h *FollowHandler) RemoveFollower(c *fiber.Ctx) error {
 // @Param userId path int true "User ID"
 // @Param cursor query string false "Pagination cursor"
 // @Param limit query int false "Number of results" default(20)
+// @Param q query string false "Filter by username substring"
 // @Success 200 {object} dto.FollowListResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 403 {object} dto.ErrorResponse
@@ -413,13 +847,17 @@ func (h *FollowHandler) GetFollowers(c *fiber.Ctx) error {
 
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 	cursor := decodeCursor(c.Query("cursor"))
+	query := strings.TrimSpace(c.Query("q"))
 
-	edges, hasMore, err := h.followSvc.GetFollowers(context.Background(), viewerID, uint(targetID), limit, cursor)
+	edges, hasMore, err := h.followSvc.GetFollowers(context.Background(), viewerID, uint(targetID), query, limit, cursor)
 	if err != nil {
 		errMsg := err.Error()
 		if strings.Contains(errMsg, constants.ErrCodeAccountPrivate) {
 			return response.Error(c, fiber.StatusForbidden, "This account is private", constants.ErrCodeAccountPrivate)
 		}
+		if strings.Contains(errMsg, constants.ErrCodeBlocked) {
+			return response.Error(c, fiber.StatusForbidden, "You cannot view this user's lists", constants.ErrCodeBlocked)
+		}
 		if strings.Contains(errMsg, constants.ErrCodeUserNotFound) {
 			return response.Error(c, fiber.StatusNotFound, "User not found", constants.ErrCodeUserNotFound)
 		}
@@ -469,6 +907,7 @@ func (h *FollowHandler) GetFollowers(c *fiber.Ctx) error {
 // @Param userId path int true "User ID"
 // @Param cursor query string false "Pagination cursor"
 // @Param limit query int false "Number of results" default(20)
+// @Param q query string false "Filter by username substring"
 // @Success 200 {object} dto.FollowListResponse
 // @Failure 401 {object} dto.ErrorResponse
 // @Failure 403 {object} dto.ErrorResponse
@@ -486,13 +925,17 @@ func (h *FollowHandler) GetFollowing(c *fiber.Ctx) error {
 
 	limit, _ := strconv.Atoi(c.Query("limit", "20"))
 	cursor := decodeCursor(c.Query("cursor"))
+	query := strings.TrimSpace(c.Query("q"))
 
-	edges, hasMore, err := h.followSvc.GetFollowing(context.Background(), viewerID, uint(targetID), limit, cursor)
+	edges, hasMore, err := h.followSvc.GetFollowing(context.Background(), viewerID, uint(targetID), query, limit, cursor)
 	if err != nil {
 		errMsg := err.Error()
 		if strings.Contains(errMsg, constants.ErrCodeAccountPrivate) {
 			return response.Error(c, fiber.StatusForbidden, "This account is private", constants.ErrCodeAccountPrivate)
 		}
+		if strings.Contains(errMsg, constants.ErrCodeBlocked) {
+			return response.Error(c, fiber.StatusForbidden, "You cannot view this user's lists", constants.ErrCodeBlocked)
+		}
 		if strings.Contains(errMsg, constants.ErrCodeUserNotFound) {
 			return response.Error(c, fiber.StatusNotFound, "User not found", constants.ErrCodeUserNotFound)
 		}
@@ -536,7 +979,7 @@ func (h *FollowHandler) GetFollowing(c *fiber.Ctx) error {
 
 // LookupRelationships handles POST /api/relationships/lookup
 // @Summary Lookup relationship states
-// @Description Batch lookup relationship states for multiple users
+// @Description Batch lookup relationship states for multiple users, including both directions (outgoing_state, incoming_state)
 // @Tags Follow
 // @Accept json
 // @Produce json
@@ -561,28 +1004,35 @@ func (h *FollowHandler) LookupRelationships(c *fiber.Ctx) error {
 		return c.JSON(dto.RelationshipLookupResponse{
 			Success:       true,
 			Relationships: make(map[uint]string),
+			Directions:    make(map[uint]dto.RelationshipDirectionDTO),
 		})
 	}
 
 	// Limit batch size
-	if len(req.TargetIDs) > 100 {
-		req.TargetIDs = req.TargetIDs[:100]
+	if len(req.TargetIDs) > constants.FollowBatchMaxSize {
+		req.TargetIDs = req.TargetIDs[:constants.FollowBatchMaxSize]
 	}
 
-	relationships, err := h.followSvc.LookupRelationships(context.Background(), viewerID, req.TargetIDs)
+	pairs, err := h.followSvc.LookupRelationshipPairs(context.Background(), viewerID, req.TargetIDs)
 	if err != nil {
 		return response.Error(c, fiber.StatusInternalServerError, "Failed to lookup relationships", constants.ErrCodeServerError)
 	}
 
-	// Convert to string map for JSON
-	result := make(map[uint]string)
-	for id, state := range relationships {
-		result[id] = string(state)
+	// Convert to string maps for JSON
+	result := make(map[uint]string, len(pairs))
+	directions := make(map[uint]dto.RelationshipDirectionDTO, len(pairs))
+	for id, pair := range pairs {
+		result[id] = string(pair.Combined())
+		directions[id] = dto.RelationshipDirectionDTO{
+			OutgoingState: string(pair.Outgoing),
+			IncomingState: string(pair.Incoming),
+		}
 	}
 
 	return c.JSON(dto.RelationshipLookupResponse{
 		Success:       true,
 		Relationships: result,
+		Directions:    directions,
 	})
 }
 
@@ -662,6 +1112,61 @@ func (h *FollowHandler) GetMutuals(c *fiber.Ctx) error {
 	})
 }
 
+// GetFollowedBy handles GET /api/users/:userId/followed-by
+// @Summary Get followers of a user that you follow
+// @Description Get users who follow the target user and whom the viewer follows, with a total count for "+N others"
+// @Tags Follow
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param userId path int true "Target User ID"
+// @Param limit query int false "Number of users to return" default(3)
+// @Success 200 {object} dto.FollowedByResponse
+// @Failure 400 {object} dto.ErrorResponse
+// @Failure 401 {object} dto.ErrorResponse
+// @Failure 403 {object} dto.ErrorResponse
+// @Failure 404 {object} dto.ErrorResponse
+// @Router /users/{userId}/followed-by [get]
+func (h *FollowHandler) GetFollowedBy(c *fiber.Ctx) error {
+	viewerID := getUserIDFromContext(c)
+	if viewerID == 0 {
+		return response.Unauthorized(c, "Authentication required", constants.ErrCodeUnauthorized)
+	}
+
+	targetID, err := strconv.ParseUint(c.Params("userId"), 10, 32)
+	if err != nil {
+		return response.Error(c, fiber.StatusBadRequest, "Invalid user ID", constants.ErrCodeInvalidRequest)
+	}
+
+	limit, _ := strconv.Atoi(c.Query("limit", "3"))
+
+	users, total, err := h.followSvc.GetFollowedByMutuals(context.Background(), viewerID, uint(targetID), limit)
+	if err != nil {
+		errMsg := err.Error()
+		if strings.Contains(errMsg, constants.ErrCodeAccountPrivate) {
+			return response.Error(c, fiber.StatusForbidden, "This account is private", constants.ErrCodeAccountPrivate)
+		}
+		if strings.Contains(errMsg, constants.ErrCodeBlocked) {
+			return response.Error(c, fiber.StatusForbidden, "You cannot view this user's lists", constants.ErrCodeBlocked)
+		}
+		if strings.Contains(errMsg, constants.ErrCodeUserNotFound) {
+			return response.Error(c, fiber.StatusNotFound, "User not found", constants.ErrCodeUserNotFound)
+		}
+		logger.Sugar.Errorw("Failed to get followed-by list",
+			"viewer_id", viewerID,
+			"target_id", targetID,
+			"error", err,
+		)
+		return response.Error(c, fiber.StatusInternalServerError, "Failed to get followed-by list", constants.ErrCodeServerError)
+	}
+
+	return c.JSON(dto.FollowedByResponse{
+		Success: true,
+		Users:   users,
+		Total:   total,
+	})
+}
+
 // GetFollowCounts handles GET /api/users/:userId/follow-counts
 // @Summary Get follow counts
 // @Description Get followers and following counts for a user
@@ -795,36 +1300,30 @@ func (h *FollowHandler) sendAcceptedNotification(c *fiber.Ctx, viewerID, request
 	h.notifSvc.NotifyFollowAccepted(ctx, requesterID, viewerID, viewer.Username, avatar)
 }
 
-// ==================== Cursor Helpers ====================
-
-type cursorData struct {
-	CreatedAt time.Time `json:"c"`
-	UserID    uint      `json:"u"`
-}
-
-func encodeCursor(createdAt time.Time, userID uint) string {
-	data := cursorData{CreatedAt: createdAt, UserID: userID}
-	bytes, _ := json.Marshal(data)
-	return base64.URLEncoding.EncodeToString(bytes)
-}
-
-func decodeCursor(cursor string) *repository.FollowListCursor {
-	if cursor == "" {
-		return nil
+// sendAcceptedNotifications sends accepted notifications for a batch of requesters
+func (h *FollowHandler) sendAcceptedNotifications(viewerID uint, requesterIDs []uint) {
+	if h.notifSvc == nil {
+		return
 	}
 
-	bytes, err := base64.URLEncoding.DecodeString(cursor)
-	if err != nil {
-		return nil
+	viewer, err := h.userRepo.FindByID(viewerID)
+	if err != nil || viewer == nil {
+		return
 	}
 
-	var data cursorData
-	if err := json.Unmarshal(bytes, &data); err != nil {
-		return nil
+	var avatar string
+	if viewer.ProfilePic != nil {
+		avatar = *viewer.ProfilePic
 	}
 
-	return &repository.FollowListCursor{
-		CreatedAt: data.CreatedAt,
-		UserID:    data.UserID,
+	ctx := context.Background()
+	for _, requesterID := range requesterIDs {
+		if err := h.notifSvc.NotifyFollowAccepted(ctx, requesterID, viewerID, viewer.Username, avatar); err != nil {
+			logger.Sugar.Warnw("Failed to send follow accepted notification",
+				"viewer_id", viewerID,
+				"requester_id", requesterID,
+				"error", err,
+			)
+		}
 	}
 }
@@ -2,12 +2,14 @@ package handlers
 
 import (
 	"strconv"
+	"strings"
 
 	"github.com/aman1117/backend/internal/constants"
 	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
 	"github.com/aman1117/backend/internal/response"
 	"github.com/aman1117/backend/internal/services"
+	"github.com/aman1117/backend/pkg/models"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -32,7 +34,10 @@ func NewNotificationHandler(notifSvc *services.NotificationService) *Notificatio
 // @Security BearerAuth
 // @Param page query int false "Page number (default: 1)"
 // @Param page_size query int false "Page size (default: 20, max: 50)"
-// @Success 200 {object} dto.NotificationsResponse "Notifications list"
+// @Param type query string false "Comma-separated notification types to include (unknown types are ignored, but at least one must be known)"
+// @Param group query bool false "Collapse consecutive same-type notifications about the same entity (default: false)"
+// @Success 200 {object} dto.NotificationsResponse "Notifications list (dto.GroupedNotificationsResponse when group=true)"
+// @Failure 400 {object} dto.ErrorResponse "No known notification type in the type filter"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
 // @Router /notifications [get]
 func (h *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
@@ -54,12 +59,39 @@ func (h *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
 		pageSize = 50 // Max page size
 	}
 
+	group := c.QueryBool("group", false)
+	rawTypes := c.Query("type")
+	types := parseNotificationTypes(rawTypes)
+	if rawTypes != "" && len(types) == 0 {
+		// Falling through to "no filter" would return every type the client asked to exclude
+		return response.BadRequest(c, "type must include at least one known notification type", constants.ErrCodeInvalidInput)
+	}
+
 	log.Infow("GetNotifications request",
 		"page", page,
 		"page_size", pageSize,
+		"group", group,
+		"types", types,
 	)
 
-	notifications, total, err := h.notifSvc.GetByUserID(c.Context(), userID, page, pageSize)
+	if group {
+		groups, total, err := h.notifSvc.GetGroupedByUserID(c.Context(), userID, types, page, pageSize)
+		if err != nil {
+			log.Errorw("Failed to get grouped notifications", "error", err)
+			return response.InternalError(c, "Failed to get notifications", constants.ErrCodeFetchFailed)
+		}
+
+		return c.JSON(dto.GroupedNotificationsResponse{
+			Success:       true,
+			Notifications: dto.NotificationGroupsToDTOs(groups),
+			Total:         total,
+			Page:          page,
+			PageSize:      pageSize,
+			HasMore:       int64(page*pageSize) < total,
+		})
+	}
+
+	notifications, total, err := h.notifSvc.GetByUserID(c.Context(), userID, types, page, pageSize)
 	if err != nil {
 		log.Errorw("Failed to get notifications", "error", err)
 		return response.InternalError(c, "Failed to get notifications", constants.ErrCodeFetchFailed)
@@ -77,6 +109,23 @@ func (h *NotificationHandler) GetNotifications(c *fiber.Ctx) error {
 	})
 }
 
+// parseNotificationTypes parses a comma-separated type filter, dropping unknown and duplicate types
+func parseNotificationTypes(raw string) []models.NotificationType {
+	if raw == "" {
+		return nil
+	}
+	var types []models.NotificationType
+	seen := make(map[models.NotificationType]bool)
+	for _, part := range strings.Split(raw, ",") {
+		t := models.NotificationType(strings.TrimSpace(part))
+		if t.IsValid() && !seen[t] {
+			seen[t] = true
+			types = append(types, t)
+		}
+	}
+	return types
+}
+
 // GetUnreadCount returns the unread notification count
 // @Summary Get unread count
 // @Description Get the count of unread notifications
@@ -137,6 +186,45 @@ func (h *NotificationHandler) MarkAsRead(c *fiber.Ctx) error {
 	})
 }
 
+// MarkAsUnread marks a single notification as unread
+// @Summary Mark notification as unread
+// @Description Mark a specific notification as unread so it can be revisited later. Already-unread notifications are left unchanged.
+// @Tags Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Notification ID"
+// @Success 200 {object} dto.NotificationActionResponse "Success"
+// @Failure 400 {object} dto.ErrorResponse "Invalid ID"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Not found"
+// @Router /notifications/{id}/unread [post]
+func (h *NotificationHandler) MarkAsUnread(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	log := logger.LogWithContext(getTraceID(c), userID)
+
+	// Parse notification ID from path
+	idStr := c.Params("id")
+	id, err := strconv.ParseUint(idStr, 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid notification ID", constants.ErrCodeInvalidRequest)
+	}
+
+	log.Infow("MarkAsUnread request", "notification_id", id)
+
+	if err := h.notifSvc.MarkAsUnread(c.Context(), uint(id), userID); err != nil {
+		log.Errorw("Failed to mark notification as unread", "notification_id", id, "error", err)
+		if err.Error() == "notification not found" {
+			return response.NotFound(c, "Notification not found", constants.ErrCodeNotificationNotFound)
+		}
+		return response.InternalError(c, "Failed to mark as unread", constants.ErrCodeUpdateFailed)
+	}
+
+	return c.JSON(dto.NotificationActionResponse{
+		Success: true,
+		Message: "Notification marked as unread",
+	})
+}
+
 // MarkAllAsRead marks all notifications as read
 // @Summary Mark all as read
 // @Description Mark all notifications as read for the current user
@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/gofiber/fiber/v2"
+)
+
+// ==================== Pagination Helpers ====================
+//
+// List endpoints page with an opaque keyset cursor (?cursor=, returned as next_cursor)
+// where the list has a stable (timestamp, user ID) order, which does not skip or repeat
+// rows when new ones arrive between pages. Older endpoints that also accept ?offset= keep
+// it for existing clients; a cursor takes precedence when both are sent.
+
+type cursorData struct {
+	CreatedAt time.Time `json:"c"`
+	UserID    uint      `json:"u"`
+}
+
+func encodeCursor(createdAt time.Time, userID uint) string {
+	data := cursorData{CreatedAt: createdAt, UserID: userID}
+	bytes, _ := json.Marshal(data)
+	return base64.URLEncoding.EncodeToString(bytes)
+}
+
+func decodeCursor(cursor string) *repository.ListCursor {
+	if cursor == "" {
+		return nil
+	}
+
+	bytes, err := base64.URLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil
+	}
+
+	var data cursorData
+	if err := json.Unmarshal(bytes, &data); err != nil {
+		return nil
+	}
+
+	return &repository.ListCursor{
+		CreatedAt: data.CreatedAt,
+		UserID:    data.UserID,
+	}
+}
+
+// parseLimitOffset reads ?limit= (1..maxLimit, otherwise defaultLimit) and ?offset= (>= 0)
+func parseLimitOffset(c *fiber.Ctx, defaultLimit, maxLimit int) (limit, offset int) {
+	limit = defaultLimit
+	if parsed, err := strconv.Atoi(c.Query("limit")); err == nil && parsed > 0 && parsed <= maxLimit {
+		limit = parsed
+	}
+	if parsed, err := strconv.Atoi(c.Query("offset")); err == nil && parsed >= 0 {
+		offset = parsed
+	}
+	return limit, offset
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"github.com/aman1117/backend/internal/constants"
@@ -117,10 +118,21 @@ func (h *PasswordResetHandler) ResetPassword(c *fiber.Ctx) error {
 		return response.BadRequest(c, err.Message, err.ErrorCode)
 	}
 
-	// Validate and consume token
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	// Check the password against the account before consuming the token, so a rejected
+	// password (e.g. same as the username) doesn't burn the reset link
+	if pendingUserID, err := redis.ValidateResetToken(ctx, req.Token); err == nil && pendingUserID > 0 {
+		if err := h.authSvc.CheckNewPassword(pendingUserID, req.NewPassword); err != nil {
+			var verr *validator.ValidationError
+			if errors.As(err, &verr) {
+				return response.BadRequest(c, verr.Message, verr.ErrorCode)
+			}
+		}
+	}
+
+	// Validate and consume token
 	userID, err := redis.ConsumeResetToken(ctx, req.Token)
 	if err != nil {
 		logger.Sugar.Errorw("Error consuming reset token", "error", err)
@@ -133,6 +145,10 @@ func (h *PasswordResetHandler) ResetPassword(c *fiber.Ctx) error {
 
 	// Update password
 	if err := h.authSvc.ResetPassword(userID, req.NewPassword); err != nil {
+		var verr *validator.ValidationError
+		if errors.As(err, &verr) {
+			return response.BadRequest(c, verr.Message, verr.ErrorCode)
+		}
 		logger.LogWithUserID(userID).Errorw("Error updating password", "error", err)
 		return response.InternalError(c, "Failed to update password", constants.ErrCodeUpdateFailed)
 	}
@@ -1,7 +1,6 @@
 package handlers
 
 import (
-	"encoding/json"
 	"strconv"
 	"strings"
 	"time"
@@ -13,7 +12,6 @@ import (
 	"github.com/aman1117/backend/internal/services"
 	"github.com/aman1117/backend/internal/validator"
 	"github.com/aman1117/backend/pkg/models"
-	"github.com/aman1117/backend/pkg/redis"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
 )
@@ -25,16 +23,18 @@ type ProfileHandler struct {
 	followSvc  *services.FollowService
 	streakSvc  *services.StreakService
 	searchSvc  *services.SearchSuggestionsService
+	viewSvc    *services.ProfileViewService
 }
 
 // NewProfileHandler creates a new ProfileHandler
-func NewProfileHandler(profileSvc *services.ProfileService, authSvc *services.AuthService, followSvc *services.FollowService, streakSvc *services.StreakService, searchSvc *services.SearchSuggestionsService) *ProfileHandler {
+func NewProfileHandler(profileSvc *services.ProfileService, authSvc *services.AuthService, followSvc *services.FollowService, streakSvc *services.StreakService, searchSvc *services.SearchSuggestionsService, viewSvc *services.ProfileViewService) *ProfileHandler {
 	return &ProfileHandler{
 		profileSvc: profileSvc,
 		authSvc:    authSvc,
 		followSvc:  followSvc,
 		streakSvc:  streakSvc,
 		searchSvc:  searchSvc,
+		viewSvc:    viewSvc,
 	}
 }
 
@@ -96,6 +96,221 @@ func (h *ProfileHandler) GetPrivacy(c *fiber.Ctx) error {
 	})
 }
 
+// UpdateTimezone handles timezone updates
+// @Summary Update timezone
+// @Description Set the IANA timezone used for streak day boundaries and reminders
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateTimezoneRequest true "Timezone"
+// @Success 200 {object} map[string]interface{} "Timezone updated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid timezone"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /update-timezone [post]
+func (h *ProfileHandler) UpdateTimezone(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	var req dto.UpdateTimezoneRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	req.Timezone = strings.TrimSpace(req.Timezone)
+
+	log := logger.LogWithContext(getTraceID(c), userID)
+	if err := h.profileSvc.UpdateTimezone(userID, req.Timezone); err != nil {
+		if err.Error() == "invalid timezone" {
+			return response.BadRequest(c, "Invalid timezone, use an IANA name like America/New_York", constants.ErrCodeInvalidTimezone)
+		}
+		log.Errorw("Timezone update failed", "error", err)
+		return response.InternalError(c, "Failed to update timezone", constants.ErrCodeUpdateFailed)
+	}
+
+	log.Infow("Timezone updated", "timezone", req.Timezone)
+	return response.JSON(c, fiber.Map{
+		"success":  true,
+		"message":  constants.MsgTimezoneUpdated,
+		"timezone": req.Timezone,
+	})
+}
+
+// GetTimezone handles timezone retrieval
+// @Summary Get timezone
+// @Description Get the timezone used for streak day boundaries
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Timezone"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /get-timezone [get]
+func (h *ProfileHandler) GetTimezone(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	timezone, err := h.profileSvc.GetTimezone(userID)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to get timezone", "error", err)
+		return response.InternalError(c, "Failed to get timezone", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success":  true,
+		"timezone": timezone,
+	})
+}
+
+// UpdateWeeklyDigest handles the weekly digest email setting
+// @Summary Update weekly digest setting
+// @Description Turn the Monday weekly progress email on or off
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateWeeklyDigestRequest true "Weekly digest setting"
+// @Success 200 {object} map[string]interface{} "Weekly digest setting updated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/weekly-digest [put]
+func (h *ProfileHandler) UpdateWeeklyDigest(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	var req dto.UpdateWeeklyDigestRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+
+	if err := h.profileSvc.SetWeeklyDigest(userID, req.Enabled); err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Weekly digest setting update failed", "error", err)
+		return response.InternalError(c, "Failed to update weekly digest setting", constants.ErrCodeUpdateFailed)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success":       true,
+		"weekly_digest": req.Enabled,
+	})
+}
+
+// GetWeeklyDigest returns the weekly digest email setting
+// @Summary Get weekly digest setting
+// @Description Get whether the Monday weekly progress email is enabled
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Weekly digest setting"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/weekly-digest [get]
+func (h *ProfileHandler) GetWeeklyDigest(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	enabled, err := h.profileSvc.IsWeeklyDigestEnabled(userID)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to get weekly digest setting", "error", err)
+		return response.InternalError(c, "Failed to get weekly digest setting", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success":       true,
+		"weekly_digest": enabled,
+	})
+}
+
+// GetProfileViewers lists who viewed the current user's profile
+// @Summary Get profile viewers
+// @Description Users who viewed your profile, most recent first. Viewers who hide their profile views, deactivated accounts and blocked users are left out.
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Items per page (default 20, max 50)"
+// @Success 200 {object} dto.ProfileViewersResponse "Profile viewers"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Server error"
+// @Router /me/profile-viewers [get]
+func (h *ProfileHandler) GetProfileViewers(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", constants.ProfileViewersDefaultPageSize)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = constants.ProfileViewersDefaultPageSize
+	}
+	if pageSize > constants.ProfileViewersMaxPageSize {
+		pageSize = constants.ProfileViewersMaxPageSize
+	}
+
+	viewers, total, err := h.viewSvc.GetViewers(userID, page, pageSize)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to get profile viewers", "error", err)
+		return response.InternalError(c, "Failed to get profile viewers", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, dto.ProfileViewersResponse{
+		Success:  true,
+		Viewers:  viewers,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  int64(page*pageSize) < total,
+	})
+}
+
+// UpdateProfileViewPrivacy handles the profile view opt-out setting
+// @Summary Update profile view privacy
+// @Description When hidden, your visits are not recorded and you don't appear in anyone's profile viewer list
+// @Tags Profile
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.UpdateProfileViewPrivacyRequest true "Profile view privacy setting"
+// @Success 200 {object} map[string]interface{} "Profile view privacy updated"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/profile-view-privacy [put]
+func (h *ProfileHandler) UpdateProfileViewPrivacy(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	var req dto.UpdateProfileViewPrivacyRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+
+	if err := h.viewSvc.SetHideProfileViews(userID, req.Hidden); err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Profile view privacy update failed", "error", err)
+		return response.InternalError(c, "Failed to update profile view privacy", constants.ErrCodeUpdateFailed)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success": true,
+		"hidden":  req.Hidden,
+	})
+}
+
+// GetProfileViewPrivacy returns the profile view opt-out setting
+// @Summary Get profile view privacy
+// @Description Get whether your visits are hidden from profile viewer lists
+// @Tags Profile
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} map[string]interface{} "Profile view privacy setting"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/profile-view-privacy [get]
+func (h *ProfileHandler) GetProfileViewPrivacy(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	hidden, err := h.viewSvc.IsHidingProfileViews(userID)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to get profile view privacy", "error", err)
+		return response.InternalError(c, "Failed to get profile view privacy", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, fiber.Map{
+		"success": true,
+		"hidden":  hidden,
+	})
+}
+
 // UpdateBio handles bio updates
 // @Summary Update bio
 // @Description Update user bio (max 150 characters)
@@ -222,6 +437,9 @@ func (h *ProfileHandler) GetUserProfile(c *fiber.Ctx) error {
 	if err != nil || user == nil {
 		return response.NotFound(c, "User not found", constants.ErrCodeUserNotFound)
 	}
+	if user.IsDeactivated && viewerID != user.ID {
+		return response.NotFound(c, "User not found", constants.ErrCodeUserNotFound)
+	}
 
 	// Save recent search asynchronously (throttled to 60s)
 	// Use a detached goroutine with its own timeout since the request context may be canceled
@@ -234,6 +452,15 @@ func (h *ProfileHandler) GetUserProfile(c *fiber.Ctx) error {
 		}(viewerID, uint(targetID))
 	}
 
+	// Record the profile view asynchronously (throttled per viewer/target)
+	if h.viewSvc != nil && viewerID != uint(targetID) && viewerID != 0 {
+		go func(viewerID, targetID uint) {
+			if err := h.viewSvc.RecordView(viewerID, targetID); err != nil {
+				logger.Sugar.Warnw("Failed to record profile view", "viewer_id", viewerID, "target_id", targetID, "error", err)
+			}
+		}(viewerID, uint(targetID))
+	}
+
 	// Get follow counts
 	var followersCount, followingCount int64
 	if h.followSvc != nil {
@@ -268,11 +495,13 @@ func (h *ProfileHandler) GetUserProfile(c *fiber.Ctx) error {
 		resp.Bio = user.Bio
 	}
 
-	// Only show last_logged_at if viewer can see private info and streak exists
+	// Only show last_logged_at if viewer can see private info and the user has logged hours
 	if canViewPrivateInfo && h.streakSvc != nil {
-		streak, err := h.streakSvc.GetLatestActiveStreak(uint(targetID))
-		if err == nil && streak != nil {
-			resp.LastLoggedAt = &streak.ActivityDate
+		lastLogged, err := h.streakSvc.GetLastLoggedAt(c.Context(), uint(targetID))
+		if err != nil {
+			logger.LogWithContext(getTraceID(c), viewerID).Warnw("Failed to get last logged date", "target_id", targetID, "error", err)
+		} else {
+			resp.LastLoggedAt = lastLogged
 		}
 	}
 
@@ -281,13 +510,13 @@ func (h *ProfileHandler) GetUserProfile(c *fiber.Ctx) error {
 
 // SearchUsers handles user search requests
 // @Summary Search users
-// @Description Search for users by username (excludes private accounts)
+// @Description Search for users by username, most-followed first. Results are paginated with page/page_size in the body.
 // @Tags Users
 // @Accept json
 // @Produce json
 // @Security BearerAuth
-// @Param request body dto.SearchUsersRequest true "Search query"
-// @Success 200 {object} dto.DataResponse{data=[]dto.UserDTO} "List of users"
+// @Param request body dto.SearchUsersRequest true "Search query and page"
+// @Success 200 {object} dto.UserSearchResponse "Page of users"
 // @Failure 400 {object} dto.ErrorResponse "Invalid request"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
 // @Router /users [post]
@@ -300,7 +529,19 @@ func (h *ProfileHandler) SearchUsers(c *fiber.Ctx) error {
 		return response.InvalidRequest(c)
 	}
 
-	users, err := h.profileSvc.SearchUsers(req.Username)
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	pageSize := req.PageSize
+	if pageSize < 1 {
+		pageSize = constants.UserSearchDefaultPageSize
+	}
+	if pageSize > constants.UserSearchMaxPageSize {
+		pageSize = constants.UserSearchMaxPageSize
+	}
+
+	users, hasMore, err := h.profileSvc.SearchUsers(req.Username, page, pageSize)
 	if err != nil {
 		logger.LogWithContext(traceID, currentUserID).Errorw("User search failed", "query", req.Username, "error", err)
 		return response.BadRequest(c, "Failed to find users", constants.ErrCodeFetchFailed)
@@ -327,8 +568,14 @@ func (h *ProfileHandler) SearchUsers(c *fiber.Ctx) error {
 		}
 	}
 
-	logger.LogWithContext(traceID, currentUserID).Debugw("User search completed", "query", req.Username, "found", len(users))
-	return response.Data(c, sanitizeUsersWithFollowing(users, followingSet))
+	logger.LogWithContext(traceID, currentUserID).Debugw("User search completed", "query", req.Username, "found", len(users), "page", page)
+	return response.JSON(c, dto.UserSearchResponse{
+		Success:  true,
+		Data:     sanitizeUsersWithFollowing(users, followingSet),
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  hasMore,
+	})
 }
 
 // AutocompleteUsers handles user autocomplete requests
@@ -365,36 +612,17 @@ func (h *ProfileHandler) AutocompleteUsers(c *fiber.Ctx) error {
 	}
 
 	// Parse limit parameter
-	limit := 12
+	limit := constants.AutocompleteDefaultLimit
 	if limitStr := c.Query("limit"); limitStr != "" {
 		if parsedLimit, err := strconv.Atoi(limitStr); err == nil {
-			if parsedLimit >= 1 && parsedLimit <= 20 {
+			if parsedLimit >= 1 && parsedLimit <= constants.AutocompleteMaxLimit {
 				limit = parsedLimit
 			}
 		}
 	}
 
-	// Normalize query for cache key (lowercase)
-	cacheKey := strings.ToLower(query)
-
-	// Try to get from cache
-	if cached, err := redis.GetAutocompleteCache(c.Context(), cacheKey); err == nil && cached != "" {
-		var cachedResponse dto.AutocompleteResponse
-		if err := json.Unmarshal([]byte(cached), &cachedResponse); err == nil {
-			// Update requestID for this request (cache hit)
-			cachedResponse.RequestID = requestID
-			log.Debugw("Autocomplete cache hit",
-				"query", query,
-				"request_id", requestID,
-				"results", len(cachedResponse.Suggestions),
-				"duration_ms", time.Since(start).Milliseconds(),
-			)
-			return response.JSON(c, cachedResponse)
-		}
-	}
-
-	// Cache miss - query database
-	results, err := h.profileSvc.AutocompleteUsers(query, limit)
+	// Blocked users are filtered in the query; the service caches results for viewers without blocks
+	results, err := h.profileSvc.AutocompleteUsers(c.Context(), userID, query, limit)
 	if err != nil {
 		log.Errorw("Autocomplete query failed",
 			"query", query,
@@ -426,13 +654,6 @@ func (h *ProfileHandler) AutocompleteUsers(c *fiber.Ctx) error {
 		Suggestions: suggestions,
 	}
 
-	// Cache the response (without requestID, we'll add it on cache hit)
-	cacheResp := resp
-	cacheResp.RequestID = "" // Don't cache the request ID
-	if cacheData, err := json.Marshal(cacheResp); err == nil {
-		_ = redis.SetAutocompleteCache(c.Context(), cacheKey, string(cacheData))
-	}
-
 	duration := time.Since(start)
 	log.Infow("Autocomplete completed",
 		"query", query,
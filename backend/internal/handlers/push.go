@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"time"
 
 	"github.com/aman1117/backend/internal/config"
@@ -9,6 +10,7 @@ import (
 	"github.com/aman1117/backend/internal/logger"
 	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/internal/response"
+	"github.com/aman1117/backend/internal/services"
 	"github.com/aman1117/backend/pkg/models"
 	"github.com/gofiber/fiber/v2"
 )
@@ -104,10 +106,15 @@ func (h *PushHandler) RegisterSubscription(c *fiber.Ctx) error {
 		return response.BadRequest(c, "Invalid push endpoint URL", constants.ErrCodeInvalidInput)
 	}
 
-	// Validate VAPID key ID matches current key
-	if req.KeyID != "" && req.KeyID != h.config.WebPush.VapidKeyID {
-		log.Warnw("VAPID key ID mismatch", "provided", req.KeyID, "expected", h.config.WebPush.VapidKeyID)
-		// Allow registration but use current key ID
+	// Bind the subscription to the key the client subscribed with. A client still on a
+	// retired key keeps working until it re-subscribes with the active one.
+	keyID := h.config.WebPush.VapidKeyID
+	if req.KeyID != "" && req.KeyID != keyID {
+		if h.config.WebPush.IsKnownKeyID(req.KeyID) {
+			keyID = req.KeyID
+		} else {
+			log.Warnw("Unknown VAPID key ID, using current key", "provided", req.KeyID, "expected", keyID)
+		}
 	}
 
 	log.Infow("Registering push subscription",
@@ -122,7 +129,7 @@ func (h *PushHandler) RegisterSubscription(c *fiber.Ctx) error {
 		Endpoint:   req.Subscription.Endpoint,
 		P256dh:     req.Subscription.Keys.P256dh,
 		Auth:       req.Subscription.Keys.Auth,
-		VapidKeyID: h.config.WebPush.VapidKeyID,
+		VapidKeyID: keyID,
 		Status:     models.PushSubscriptionStatusActive,
 		UserAgent:  truncateString(req.Device.UserAgent, MaxUserAgentLength),
 		Platform:   truncateString(req.Device.Platform, MaxPlatformLength),
@@ -220,6 +227,87 @@ func (h *PushHandler) GetPreferences(c *fiber.Ctx) error {
 	})
 }
 
+// SendTestPush queues a test push to all of the user's active subscriptions
+// @Summary Send test push
+// @Description Queue a fixed test notification for the authenticated user. It is delivered by the push worker through the normal path (push enabled and quiet hours still apply), so it verifies subscriptions end-to-end. Limited to 3 per minute.
+// @Tags Push Notifications
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.PushTestResponse "Test push queued"
+// @Failure 400 {object} dto.ErrorResponse "No active subscriptions"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 429 {object} dto.ErrorResponse "Rate limited"
+// @Failure 503 {object} dto.ErrorResponse "Push not configured"
+// @Router /push/test [post]
+func (h *PushHandler) SendTestPush(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	log := logger.LogWithContext(getTraceID(c), userID)
+
+	publisher := services.GetPushPublisher()
+	if publisher == nil || !publisher.IsAvailable() {
+		return response.ServiceUnavailable(c, "Push notifications are not configured")
+	}
+
+	subs, err := h.pushRepo.GetActiveSubscriptionsByUserID(userID)
+	if err != nil {
+		log.Errorw("Failed to get push subscriptions", "error", err)
+		return response.InternalError(c, "Failed to get subscriptions", constants.ErrCodeDatabaseError)
+	}
+	if len(subs) == 0 {
+		return response.BadRequest(c, "No active push subscriptions for this account", constants.ErrCodeInvalidRequest)
+	}
+
+	messageID, err := publisher.PublishTestPush(c.Context(), userID)
+	if err != nil {
+		log.Errorw("Failed to queue test push", "error", err)
+		return response.InternalError(c, "Failed to queue test push", constants.ErrCodeServerError)
+	}
+
+	log.Infow("Test push queued", "message_id", messageID, "subscriptions", len(subs))
+	return c.JSON(dto.PushTestResponse{
+		Success:       true,
+		MessageID:     messageID,
+		Subscriptions: len(subs),
+	})
+}
+
+// GetDeliveryStats returns the user's push delivery outcomes over a recent window
+// @Summary Get push delivery stats
+// @Description Count the authenticated user's push delivery attempts by outcome (delivered, gone, rate_limited, failed) over the last N days, to diagnose missing notifications. Gone means the browser subscription expired or was revoked.
+// @Tags Push Notifications
+// @Produce json
+// @Security BearerAuth
+// @Param days query int false "Window in days (1-30, default 30)"
+// @Success 200 {object} dto.PushStatsResponse "Delivery stats"
+// @Failure 400 {object} dto.ErrorResponse "Invalid window"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/push/stats [get]
+func (h *PushHandler) GetDeliveryStats(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	log := logger.LogWithContext(getTraceID(c), userID)
+
+	days := c.QueryInt("days", constants.PushStatsMaxDays)
+	if days < 1 || days > constants.PushStatsMaxDays {
+		return response.BadRequest(c, fmt.Sprintf("days must be between 1 and %d", constants.PushStatsMaxDays), constants.ErrCodeInvalidInput)
+	}
+
+	stats, err := h.pushRepo.GetDeliveryStats(userID, time.Now().AddDate(0, 0, -days))
+	if err != nil {
+		log.Errorw("Failed to get push delivery stats", "error", err)
+		return response.InternalError(c, "Failed to get delivery stats", constants.ErrCodeDatabaseError)
+	}
+
+	return c.JSON(dto.PushStatsResponse{
+		Success:         true,
+		Days:            days,
+		Delivered:       stats.Delivered,
+		Gone:            stats.Gone,
+		RateLimited:     stats.RateLimited,
+		Failed:          stats.Failed,
+		LastDeliveredAt: stats.LastDeliveredAt,
+	})
+}
+
 // UpdatePreferences updates the user's push notification preferences
 // @Summary Update push preferences
 // @Description Update push notification preferences for the authenticated user
@@ -307,13 +395,14 @@ func (h *PushHandler) UpdatePreferences(c *fiber.Ctx) error {
 
 // RunCleanup performs cleanup of stale push data (admin endpoint)
 // @Summary Cleanup stale push data
-// @Description Cleanup stale subscriptions, gone subscriptions, and old delivery logs
+// @Description Cleanup stale subscriptions, gone subscriptions, old delivery logs and old send claims
 // @Tags Push Notifications
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Success 200 {object} dto.CleanupResponse "Cleanup results"
 // @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Not an admin"
 // @Router /push/cleanup [post]
 func (h *PushHandler) RunCleanup(c *fiber.Ctx) error {
 	log := logger.LogWithContext(getTraceID(c), 0)
@@ -337,10 +426,17 @@ func (h *PushHandler) RunCleanup(c *fiber.Ctx) error {
 		log.Errorw("Failed to cleanup old delivery logs", "error", err)
 	}
 
+	// Cleanup send claims older than 7 days (far beyond any push TTL)
+	claimsCount, err := h.pushRepo.CleanupOldSendClaims(7)
+	if err != nil {
+		log.Errorw("Failed to cleanup old send claims", "error", err)
+	}
+
 	log.Infow("Push cleanup completed",
 		"stale_subscriptions", staleCount,
 		"gone_subscriptions", goneCount,
 		"old_logs", logsCount,
+		"old_claims", claimsCount,
 	)
 
 	return c.JSON(fiber.Map{
@@ -348,6 +444,7 @@ func (h *PushHandler) RunCleanup(c *fiber.Ctx) error {
 		"stale_subscriptions_cleaned": staleCount,
 		"gone_subscriptions_deleted":  goneCount,
 		"old_logs_deleted":            logsCount,
+		"old_claims_deleted":          claimsCount,
 	})
 }
 
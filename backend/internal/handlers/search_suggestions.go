@@ -149,6 +149,55 @@ func (h *SearchSuggestionsHandler) GetSearchSuggestions(c *fiber.Ctx) error {
 	})
 }
 
+// GetMutualSuggestions returns "people you may know" suggestions for the authenticated user
+// @Summary Get mutual-based suggestions
+// @Description Users followed by many of the people you follow, ranked by the number of mutual connections. Excludes users you follow or have requested, and blocked users.
+// @Tags Search
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Max results (1-30, default 10)"
+// @Success 200 {object} dto.MutualSuggestionsResponse "Suggested users"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Server error"
+// @Router /me/suggestions/mutual-based [get]
+func (h *SearchSuggestionsHandler) GetMutualSuggestions(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+	log := logger.LogWithContext(traceID, userID)
+
+	// Validate user ID
+	if userID == 0 {
+		return response.BadRequest(c, "Invalid user context", constants.ErrCodeInvalidRequest)
+	}
+
+	results, err := h.searchSvc.GetMutualSuggestions(userID, c.QueryInt("limit", constants.MutualSuggestionsDefaultLimit))
+	if err != nil {
+		log.Errorw("Failed to get mutual suggestions", "error", err)
+		return response.InternalError(c, "Failed to get suggestions", constants.ErrCodeFetchFailed)
+	}
+
+	suggestions := make([]dto.MutualSuggestionUser, 0, len(results))
+	for _, r := range results {
+		suggestions = append(suggestions, dto.MutualSuggestionUser{
+			SearchSuggestionUser: dto.SearchSuggestionUser{
+				ID:              r.ID,
+				Username:        r.Username,
+				ProfilePic:      r.ProfilePic,
+				ProfilePicThumb: r.ProfilePicThumb,
+				IsVerified:      r.IsVerified,
+				FollowersCount:  r.FollowersCount,
+			},
+			IsPrivate:   r.IsPrivate,
+			MutualCount: r.MutualCount,
+		})
+	}
+
+	return response.JSON(c, dto.MutualSuggestionsResponse{
+		Success:     true,
+		Suggestions: suggestions,
+	})
+}
+
 // DeleteRecentSearch removes a specific recent search
 // @Summary Delete a recent search
 // @Description Remove a specific user from recent searches
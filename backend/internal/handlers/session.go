@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"strconv"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/dto"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/response"
+	"github.com/aman1117/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// SessionHandler handles the logged-in device list and remote logout
+type SessionHandler struct {
+	sessionSvc *services.SessionService
+}
+
+// NewSessionHandler creates a new SessionHandler
+func NewSessionHandler(sessionSvc *services.SessionService) *SessionHandler {
+	return &SessionHandler{sessionSvc: sessionSvc}
+}
+
+// ListSessions returns the user's logged-in devices
+// @Summary List sessions
+// @Description List devices currently logged in to your account, most recently used first. The session making the request is marked current.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.SessionsResponse "Active sessions"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/sessions [get]
+func (h *SessionHandler) ListSessions(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	currentID := getSessionID(c)
+
+	sessions, err := h.sessionSvc.List(userID)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to list sessions", "error", err)
+		return response.InternalError(c, "Failed to list sessions", constants.ErrCodeFetchFailed)
+	}
+
+	items := make([]dto.SessionDTO, 0, len(sessions))
+	for _, s := range sessions {
+		items = append(items, dto.SessionDTO{
+			ID:         s.ID,
+			UserAgent:  s.UserAgent,
+			IPAddress:  s.IPAddress,
+			CreatedAt:  s.CreatedAt,
+			LastSeenAt: s.LastSeenAt,
+			Current:    s.ID == currentID,
+		})
+	}
+
+	return response.JSON(c, dto.SessionsResponse{
+		Success:  true,
+		Sessions: items,
+	})
+}
+
+// RevokeSession logs out one of the user's devices
+// @Summary Revoke a session
+// @Description Log out a device. Its refresh token stops working immediately and its access token is rejected on the next request.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Session ID"
+// @Success 200 {object} dto.SuccessResponse "Session revoked"
+// @Failure 400 {object} dto.ErrorResponse "Invalid session ID"
+// @Failure 404 {object} dto.ErrorResponse "Session not found"
+// @Router /me/sessions/{id} [delete]
+func (h *SessionHandler) RevokeSession(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	sessionID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid session ID", constants.ErrCodeInvalidRequest)
+	}
+
+	revoked, err := h.sessionSvc.Revoke(c.Context(), userID, uint(sessionID))
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to revoke session", "session_id", sessionID, "error", err)
+		return response.InternalError(c, "Failed to revoke session", constants.ErrCodeUpdateFailed)
+	}
+	if !revoked {
+		return response.NotFound(c, "Session not found", constants.ErrCodeSessionNotFound)
+	}
+
+	logger.LogWithContext(getTraceID(c), userID).Infow("Session revoked", "session_id", sessionID)
+	return response.Success(c, constants.MsgSessionRevoked)
+}
+
+func getSessionID(c *fiber.Ctx) uint {
+	sessionID, _ := c.Locals("session_id").(uint)
+	return sessionID
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"strings"
 	"time"
 
 	"github.com/aman1117/backend/internal/constants"
@@ -81,7 +82,7 @@ func (h *StreakHandler) GetStreak(c *fiber.Ctx) error {
 	// Check for new badges (only for own streak)
 	var newBadges []dto.BadgeDTO
 	if user.ID == currentUserID && h.badgeSvc != nil {
-		newBadges, _ = h.badgeSvc.CheckAndAwardBadges(user.ID, streak.Longest)
+		newBadges, _ = h.badgeSvc.CheckAndAwardBadges(c.Context(), user.ID, streak.Longest)
 	}
 
 	return response.JSON(c, dto.StreakResponse{
@@ -95,3 +96,154 @@ func (h *StreakHandler) GetStreak(c *fiber.Ctx) error {
 		},
 	})
 }
+
+// GetStreakFreezes handles streak freeze balance retrieval
+// @Summary Get streak freezes
+// @Description Get the current user's remaining streak freezes and when the last one was used
+// @Tags Streaks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.StreakFreezeResponse "Streak freeze balance"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Server error"
+// @Router /streak-freezes [get]
+func (h *StreakHandler) GetStreakFreezes(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	freeze, err := h.streakSvc.GetFreezes(userID)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to fetch streak freezes", "error", err)
+		return response.InternalError(c, "Failed to fetch streak freezes", constants.ErrCodeStreakError)
+	}
+
+	data := dto.StreakFreezeDTO{
+		Available:  freeze.Available,
+		MaxBalance: constants.StreakFreezeMaxBalance,
+	}
+	if freeze.LastUsedAt != nil {
+		usedAt := freeze.LastUsedAt.Format(time.RFC3339)
+		data.LastUsedAt = &usedAt
+	}
+	if freeze.LastUsedFor != nil {
+		usedFor := freeze.LastUsedFor.Format(constants.DateFormat)
+		data.LastUsedFor = &usedFor
+	}
+
+	return response.JSON(c, dto.StreakFreezeResponse{
+		Success: true,
+		Data:    data,
+	})
+}
+
+// GetActivityStreaks handles per-activity streak retrieval
+// @Summary Get streaks by activity
+// @Description Get the current user's current and longest streak for each activity type
+// @Tags Streaks
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.ActivityStreaksResponse "Streaks keyed by activity name"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Server error"
+// @Router /streaks/by-activity [get]
+func (h *StreakHandler) GetActivityStreaks(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	streaks, err := h.streakSvc.GetActivityStreaks(userID)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to fetch activity streaks", "error", err)
+		return response.InternalError(c, "Failed to fetch activity streaks", constants.ErrCodeStreakError)
+	}
+
+	return response.JSON(c, dto.ActivityStreaksResponse{
+		Success: true,
+		Data:    streaks,
+	})
+}
+
+// GetStreakSeries handles daily streak series retrieval for charting
+// @Summary Get streak series
+// @Description Get one streak data point per day between start and end (inclusive, max 366 days). Defaults to the current user.
+// @Tags Streaks
+// @Produce json
+// @Security BearerAuth
+// @Param start query string true "Start date (YYYY-MM-DD)"
+// @Param end query string true "End date (YYYY-MM-DD)"
+// @Param username query string false "Username (defaults to current user)"
+// @Success 200 {object} dto.StreakSeriesResponse "Streak series"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 403 {object} dto.ErrorResponse "Private account"
+// @Router /streaks/series [get]
+func (h *StreakHandler) GetStreakSeries(c *fiber.Ctx) error {
+	currentUserID := getUserID(c)
+	traceID := getTraceID(c)
+
+	startStr := c.Query("start")
+	endStr := c.Query("end")
+	if startStr == "" || endStr == "" {
+		return response.BadRequest(c, "start and end are required", constants.ErrCodeMissingFields)
+	}
+
+	start, err := time.Parse(constants.DateFormat, startStr)
+	if err != nil {
+		return response.BadRequest(c, "Invalid start date format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+	}
+	end, err := time.Parse(constants.DateFormat, endStr)
+	if err != nil {
+		return response.BadRequest(c, "Invalid end date format, use YYYY-MM-DD", constants.ErrCodeInvalidDate)
+	}
+
+	targetID := currentUserID
+	if username := c.Query("username"); username != "" {
+		user, err := h.authSvc.GetUserByUsername(username)
+		if err != nil || user == nil {
+			return response.UserNotFound(c)
+		}
+		if !h.profileSvc.CanViewProfile(user, currentUserID) {
+			return response.PrivateAccount(c)
+		}
+		targetID = user.ID
+	}
+
+	series, err := h.streakSvc.GetStreakSeries(targetID, start, end)
+	if err != nil {
+		if err.Error() == "end date must not be before start date" ||
+			strings.HasPrefix(err.Error(), "date range cannot exceed") {
+			return response.BadRequest(c, err.Error(), constants.ErrCodeInvalidDateRange)
+		}
+		logger.LogWithContext(traceID, currentUserID).Errorw("Failed to fetch streak series", "error", err)
+		return response.InternalError(c, "Failed to fetch streak series", constants.ErrCodeStreakError)
+	}
+
+	return response.JSON(c, dto.StreakSeriesResponse{
+		Success: true,
+		Data:    series,
+	})
+}
+
+// GetFollowingLeaderboard handles the streak leaderboard among followed users
+// @Summary Get following streak leaderboard
+// @Description Rank the users you follow by their current streak, highest first
+// @Tags Streaks
+// @Produce json
+// @Security BearerAuth
+// @Param limit query int false "Number of results (max 100)" default(20)
+// @Success 200 {object} dto.StreakLeaderboardResponse "Leaderboard"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 500 {object} dto.ErrorResponse "Server error"
+// @Router /streaks/leaderboard [get]
+func (h *StreakHandler) GetFollowingLeaderboard(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	limit := c.QueryInt("limit", constants.StreakLeaderboardDefaultLimit)
+
+	entries, err := h.streakSvc.GetFollowingLeaderboard(userID, limit)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to fetch streak leaderboard", "error", err)
+		return response.InternalError(c, "Failed to fetch streak leaderboard", constants.ErrCodeStreakError)
+	}
+
+	return response.JSON(c, dto.StreakLeaderboardResponse{
+		Success: true,
+		Data:    entries,
+	})
+}
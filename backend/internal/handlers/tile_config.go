@@ -1,9 +1,12 @@
 package handlers
 
 import (
+	"errors"
+
 	"github.com/aman1117/backend/internal/constants"
 	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/internal/response"
 	"github.com/aman1117/backend/internal/services"
 	"github.com/gofiber/fiber/v2"
@@ -27,7 +30,7 @@ func NewTileConfigHandler(tileConfigSvc *services.TileConfigService, authSvc *se
 
 // GetConfig handles tile config retrieval for the current user
 // @Summary Get tile configuration
-// @Description Get dashboard tile configuration for authenticated user
+// @Description Get dashboard tile configuration for authenticated user. Users who never saved a config get the default layout.
 // @Tags Tile Config
 // @Produce json
 // @Security BearerAuth
@@ -37,13 +40,10 @@ func NewTileConfigHandler(tileConfigSvc *services.TileConfigService, authSvc *se
 func (h *TileConfigHandler) GetConfig(c *fiber.Ctx) error {
 	userID := getUserID(c)
 
-	config, err := h.tileConfigSvc.GetConfig(userID)
-	if err != nil || config == nil {
-		// No config found - return null config (not an error)
-		return response.JSON(c, dto.TileConfigResponse{
-			Success: true,
-			Data:    nil,
-		})
+	config, err := h.tileConfigSvc.GetOrCreateDefault(userID, true)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Tile config fetch failed", "error", err)
+		return response.InternalError(c, "Failed to get tile configuration", constants.ErrCodeFetchFailed)
 	}
 
 	return response.JSON(c, dto.TileConfigResponse{
@@ -54,7 +54,7 @@ func (h *TileConfigHandler) GetConfig(c *fiber.Ctx) error {
 
 // GetConfigByUsername handles tile config retrieval for another user
 // @Summary Get tile config by username
-// @Description Get dashboard tile configuration for another user
+// @Description Get dashboard tile configuration for another user. Users who never saved a config get the default layout.
 // @Tags Tile Config
 // @Accept json
 // @Produce json
@@ -90,12 +90,11 @@ func (h *TileConfigHandler) GetConfigByUsername(c *fiber.Ctx) error {
 		return response.PrivateAccount(c)
 	}
 
-	config, err := h.tileConfigSvc.GetConfig(user.ID)
-	if err != nil || config == nil {
-		return response.JSON(c, dto.TileConfigResponse{
-			Success: true,
-			Data:    nil,
-		})
+	// Don't write a row on another user's behalf just because their profile was viewed
+	config, err := h.tileConfigSvc.GetOrCreateDefault(user.ID, false)
+	if err != nil {
+		logger.LogWithContext(traceID, currentUserID).Errorw("Tile config fetch failed", "target_user_id", user.ID, "error", err)
+		return response.InternalError(c, "Failed to get tile configuration", constants.ErrCodeFetchFailed)
 	}
 
 	return response.JSON(c, dto.TileConfigResponse{
@@ -104,6 +103,77 @@ func (h *TileConfigHandler) GetConfigByUsername(c *fiber.Ctx) error {
 	})
 }
 
+// ReorderTiles handles replacing only the tile order
+// @Summary Reorder tiles
+// @Description Replace the dashboard tile order without resending the rest of the tile configuration. Entries must be predefined activities or the user's custom tiles.
+// @Tags Tile Config
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.ReorderTilesRequest true "Tile keys in display order"
+// @Success 200 {object} dto.SuccessResponse "Order saved"
+// @Failure 400 {object} dto.ErrorResponse "Invalid order"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/tiles/order [patch]
+func (h *TileConfigHandler) ReorderTiles(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	var req dto.ReorderTilesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	if len(req.Order) == 0 {
+		return response.BadRequest(c, "Order is required", constants.ErrCodeMissingFields)
+	}
+
+	if err := h.tileConfigSvc.ReorderTiles(userID, req.Order); err != nil {
+		if validationErr, ok := err.(*services.TileConfigValidationError); ok {
+			return response.BadRequest(c, validationErr.Message, validationErr.Code)
+		}
+
+		logger.LogWithContext(traceID, userID).Errorw("Tile reorder failed", "error", err)
+		return response.InternalError(c, "Failed to save tile order", constants.ErrCodeSaveFailed)
+	}
+
+	return response.Success(c, constants.MsgTileOrderSaved)
+}
+
+// DeleteCustomTile handles removing one of the user's custom tiles
+// @Summary Delete a custom tile
+// @Description Remove a custom tile from the tile configuration. By default its logged activities and story photos are kept as history, archived so analytics leave them out; with purge=true they are deleted and streaks are recomputed without them. A purge also clears the history of a tile deleted earlier.
+// @Tags Tile Config
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "Custom tile ID (UUID)"
+// @Param purge query bool false "Also delete the tile's activities and photos (default false)"
+// @Success 200 {object} dto.DeleteCustomTileResponse "Tile deleted"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Failure 404 {object} dto.ErrorResponse "Custom tile not found"
+// @Router /me/tiles/custom/{id} [delete]
+func (h *TileConfigHandler) DeleteCustomTile(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+	tileID := c.Params("id")
+	purge := c.QueryBool("purge", false)
+
+	deleted, err := h.tileConfigSvc.DeleteCustomTile(c.Context(), userID, tileID, purge)
+	if err != nil {
+		if errors.Is(err, repository.ErrCustomTileNotFound) {
+			return response.NotFound(c, "Custom tile not found", constants.ErrCodeCustomTileNotFound)
+		}
+		logger.LogWithContext(traceID, userID).Errorw("Custom tile delete failed", "tile_id", tileID, "purge", purge, "error", err)
+		return response.InternalError(c, "Failed to delete custom tile", constants.ErrCodeDeleteFailed)
+	}
+
+	logger.LogWithContext(traceID, userID).Infow("Custom tile deleted", "tile_id", tileID, "purge", purge, "activities_deleted", deleted)
+	return response.JSON(c, dto.DeleteCustomTileResponse{
+		Success:           true,
+		Purged:            purge,
+		ActivitiesDeleted: deleted,
+	})
+}
+
 // SaveConfig handles tile config saving
 // @Summary Save tile configuration
 // @Description Save dashboard tile configuration
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/aman1117/backend/internal/config"
+	"github.com/aman1117/backend/internal/services"
 	"github.com/aman1117/backend/pkg/models"
 	"github.com/golang-jwt/jwt/v5"
 )
@@ -13,6 +15,10 @@ import (
 type Claims struct {
 	UserID   uint   `json:"user_id"`
 	Username string `json:"username"`
+	// TokenVersion must match the user's current version; "logout everywhere" bumps it
+	TokenVersion int `json:"ver"`
+	// SessionID ties the token to a login session so revoking the session invalidates it
+	SessionID uint `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -20,26 +26,30 @@ type Claims struct {
 type TokenService struct {
 	secretKey  string
 	accessTTL  time.Duration
-	refreshTTL time.Duration
+	refreshSvc *services.RefreshTokenService
+	sessionSvc *services.SessionService
 }
 
 // NewTokenService creates a new TokenService
-func NewTokenService(cfg *config.JWTConfig) *TokenService {
+func NewTokenService(cfg *config.JWTConfig, refreshSvc *services.RefreshTokenService, sessionSvc *services.SessionService) *TokenService {
 	return &TokenService{
 		secretKey:  cfg.SecretKey,
 		accessTTL:  cfg.AccessTokenTTL,
-		refreshTTL: cfg.RefreshTokenTTL,
+		refreshSvc: refreshSvc,
+		sessionSvc: sessionSvc,
 	}
 }
 
-// Generate generates a new JWT token for a user
-func (s *TokenService) Generate(user *models.User) (string, time.Time, int, error) {
+// Generate generates a new JWT token for a user's login session
+func (s *TokenService) Generate(user *models.User, sessionID uint) (string, time.Time, int, error) {
 	now := time.Now()
 	exp := now.Add(s.accessTTL)
 
 	claims := &Claims{
-		UserID:   user.ID,
-		Username: user.Username,
+		UserID:       user.ID,
+		Username:     user.Username,
+		TokenVersion: user.TokenVersion,
+		SessionID:    sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ExpiresAt: jwt.NewNumericDate(exp),
 			IssuedAt:  jwt.NewNumericDate(now),
@@ -60,7 +70,10 @@ func (s *TokenService) Generate(user *models.User) (string, time.Time, int, erro
 	return signed, exp, expiresIn, nil
 }
 
-// Parse parses and validates a JWT token
+// Parse parses and validates a JWT token, rejecting tokens issued before the
+// user's last "logout everywhere" or belonging to a revoked session. Tokens issued
+// before sessions existed carry no session ID; logging out cannot reach them, so they
+// are rejected and the client has to refresh.
 func (s *TokenService) Parse(tokenStr string) (*Claims, error) {
 	claims := &Claims{}
 
@@ -79,5 +92,30 @@ func (s *TokenService) Parse(tokenStr string) (*Claims, error) {
 		return nil, errors.New("invalid token")
 	}
 
+	if s.refreshSvc != nil {
+		version, err := s.refreshSvc.TokenVersion(context.Background(), claims.UserID)
+		if err != nil {
+			return nil, err
+		}
+		if version != claims.TokenVersion {
+			return nil, errors.New("token has been revoked")
+		}
+	}
+
+	if s.sessionSvc != nil {
+		if claims.SessionID == 0 {
+			return nil, errors.New("token has no session")
+		}
+		ctx := context.Background()
+		active, err := s.sessionSvc.IsActive(ctx, claims.SessionID)
+		if err != nil {
+			return nil, err
+		}
+		if !active {
+			return nil, errors.New("session has been revoked")
+		}
+		s.sessionSvc.Touch(ctx, claims.SessionID, "", false)
+	}
+
 	return claims, nil
 }
@@ -0,0 +1,156 @@
+package handlers
+
+import (
+	"errors"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/dto"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/response"
+	"github.com/aman1117/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// TwoFactorHandler handles TOTP two-factor enrollment and removal
+type TwoFactorHandler struct {
+	tfaSvc *services.TwoFactorService
+}
+
+// NewTwoFactorHandler creates a new TwoFactorHandler
+func NewTwoFactorHandler(tfaSvc *services.TwoFactorService) *TwoFactorHandler {
+	return &TwoFactorHandler{tfaSvc: tfaSvc}
+}
+
+// GetStatus reports whether two-factor is enabled
+// @Summary Get two-factor status
+// @Description Whether two-factor authentication is enabled and how many backup codes are left
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TwoFactorStatusResponse "Two-factor status"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /auth/2fa [get]
+func (h *TwoFactorHandler) GetStatus(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	enabled, remaining, err := h.tfaSvc.Status(userID)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to get two-factor status", "error", err)
+		return response.InternalError(c, "Failed to get two-factor status", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, dto.TwoFactorStatusResponse{
+		Success:              true,
+		Enabled:              enabled,
+		BackupCodesRemaining: remaining,
+	})
+}
+
+// Enroll starts two-factor enrollment
+// @Summary Start two-factor enrollment
+// @Description Generate a TOTP secret and otpauth:// URL to add to an authenticator app. Two-factor is not enforced until the enrollment is confirmed with a code. Calling this again replaces a pending secret.
+// @Tags Authentication
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.TwoFactorEnrollResponse "Secret and otpauth URL"
+// @Failure 409 {object} dto.ErrorResponse "Two-factor already enabled"
+// @Router /auth/2fa/enroll [post]
+func (h *TwoFactorHandler) Enroll(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	enrollment, err := h.tfaSvc.Enroll(userID)
+	if err != nil {
+		if errors.Is(err, services.ErrTwoFactorAlreadyEnabled) {
+			return response.Conflict(c, "Two-factor authentication is already enabled", constants.ErrCodeTwoFactorEnabled)
+		}
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to start two-factor enrollment", "error", err)
+		return response.InternalError(c, "Failed to start two-factor enrollment", constants.ErrCodeUpdateFailed)
+	}
+
+	return response.JSON(c, dto.TwoFactorEnrollResponse{
+		Success:    true,
+		Secret:     enrollment.Secret,
+		OTPAuthURL: enrollment.URL,
+	})
+}
+
+// ConfirmEnrollment verifies the first code and enables two-factor
+// @Summary Confirm two-factor enrollment
+// @Description Verify a code from the authenticator app to enable two-factor. Returns single-use backup codes, which are only shown this once.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.TwoFactorCodeRequest true "Authenticator code"
+// @Success 200 {object} dto.TwoFactorBackupCodesResponse "Two-factor enabled"
+// @Failure 400 {object} dto.ErrorResponse "Invalid code or enrollment not started"
+// @Failure 409 {object} dto.ErrorResponse "Two-factor already enabled"
+// @Router /auth/2fa/verify [post]
+func (h *TwoFactorHandler) ConfirmEnrollment(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	var req dto.TwoFactorCodeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	if req.Code == "" {
+		return response.MissingFields(c)
+	}
+
+	codes, err := h.tfaSvc.ConfirmEnrollment(c.Context(), userID, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrInvalidTwoFactorCode):
+			return response.BadRequest(c, "Invalid two-factor code", constants.ErrCodeInvalidTwoFactor)
+		case errors.Is(err, services.ErrTwoFactorNotEnrolled):
+			return response.BadRequest(c, "Start two-factor enrollment first", constants.ErrCodeTwoFactorNoSecret)
+		case errors.Is(err, services.ErrTwoFactorAlreadyEnabled):
+			return response.Conflict(c, "Two-factor authentication is already enabled", constants.ErrCodeTwoFactorEnabled)
+		}
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to confirm two-factor enrollment", "error", err)
+		return response.InternalError(c, "Failed to enable two-factor authentication", constants.ErrCodeUpdateFailed)
+	}
+
+	return response.JSON(c, dto.TwoFactorBackupCodesResponse{
+		Success:     true,
+		BackupCodes: codes,
+	})
+}
+
+// Disable turns two-factor off
+// @Summary Disable two-factor
+// @Description Turn off two-factor authentication. Requires the password and a current authenticator or backup code.
+// @Tags Authentication
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.DisableTwoFactorRequest true "Password and code"
+// @Success 200 {object} dto.SuccessResponse "Two-factor disabled"
+// @Failure 400 {object} dto.ErrorResponse "Incorrect password, invalid code, or two-factor not enabled"
+// @Router /auth/2fa/disable [post]
+func (h *TwoFactorHandler) Disable(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	var req dto.DisableTwoFactorRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	if req.Password == "" || req.Code == "" {
+		return response.MissingFields(c)
+	}
+
+	if err := h.tfaSvc.Disable(c.Context(), userID, req.Password, req.Code); err != nil {
+		switch {
+		case errors.Is(err, services.ErrIncorrectPassword):
+			return response.BadRequest(c, "Incorrect password", constants.ErrCodeInvalidPassword)
+		case errors.Is(err, services.ErrInvalidTwoFactorCode):
+			return response.BadRequest(c, "Invalid two-factor code", constants.ErrCodeInvalidTwoFactor)
+		case errors.Is(err, services.ErrTwoFactorNotEnabled):
+			return response.BadRequest(c, "Two-factor authentication is not enabled", constants.ErrCodeTwoFactorDisabled)
+		}
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to disable two-factor", "error", err)
+		return response.InternalError(c, "Failed to disable two-factor authentication", constants.ErrCodeUpdateFailed)
+	}
+
+	return response.Success(c, constants.MsgTwoFactorDisabled)
+}
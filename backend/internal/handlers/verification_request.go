@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/dto"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/internal/response"
+	"github.com/aman1117/backend/internal/services"
+	"github.com/gofiber/fiber/v2"
+)
+
+// VerificationRequestHandler handles verified-badge requests and their admin review
+type VerificationRequestHandler struct {
+	requestSvc *services.VerificationRequestService
+}
+
+// NewVerificationRequestHandler creates a new VerificationRequestHandler
+func NewVerificationRequestHandler(requestSvc *services.VerificationRequestService) *VerificationRequestHandler {
+	return &VerificationRequestHandler{requestSvc: requestSvc}
+}
+
+// SubmitRequest handles a user's request for the verified badge
+// @Summary Request verification
+// @Description Submit a request for the verified badge with a short justification. Only one request can be pending at a time.
+// @Tags Verification
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body dto.SubmitVerificationRequest true "Justification"
+// @Success 201 {object} dto.VerificationRequestResponse "Request submitted"
+// @Failure 400 {object} dto.ErrorResponse "Invalid justification or already verified"
+// @Failure 409 {object} dto.ErrorResponse "A request is already pending"
+// @Router /me/verification-request [post]
+func (h *VerificationRequestHandler) SubmitRequest(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	var req dto.SubmitVerificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+
+	justification := strings.TrimSpace(req.Justification)
+	if n := utf8.RuneCountInString(justification); n < constants.VerificationJustificationMinLen || n > constants.VerificationJustificationMaxLen {
+		return response.BadRequest(c, fmt.Sprintf("Justification must be between %d and %d characters",
+			constants.VerificationJustificationMinLen, constants.VerificationJustificationMaxLen), constants.ErrCodeInvalidInput)
+	}
+
+	created, err := h.requestSvc.Submit(userID, justification)
+	if err != nil {
+		switch {
+		case errors.Is(err, services.ErrAlreadyVerified):
+			return response.BadRequest(c, "Your account is already verified", constants.ErrCodeAlreadyVerified)
+		case errors.Is(err, repository.ErrVerificationRequestPending):
+			return response.Conflict(c, "You already have a verification request pending", constants.ErrCodeVerificationPending)
+		}
+		logger.LogWithContext(traceID, userID).Errorw("Failed to submit verification request", "error", err)
+		return response.InternalError(c, "Failed to submit verification request", constants.ErrCodeCreateFailed)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(dto.VerificationRequestResponse{
+		Success: true,
+		Request: created,
+	})
+}
+
+// GetMyRequest returns the status of the user's latest verification request
+// @Summary Get my verification request
+// @Description Get your most recent verification request and its status (request is null if you never submitted one)
+// @Tags Verification
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} dto.VerificationRequestResponse "Latest request"
+// @Failure 401 {object} dto.ErrorResponse "Unauthorized"
+// @Router /me/verification-request [get]
+func (h *VerificationRequestHandler) GetMyRequest(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	latest, err := h.requestSvc.GetLatest(userID)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to get verification request", "error", err)
+		return response.InternalError(c, "Failed to get verification request", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, dto.VerificationRequestResponse{
+		Success: true,
+		Request: latest,
+	})
+}
+
+// ListPending returns the admin review queue
+// @Summary List pending verification requests (admin)
+// @Description Pending verification requests, oldest first
+// @Tags Admin
+// @Produce json
+// @Security BearerAuth
+// @Param page query int false "Page number (default 1)"
+// @Param page_size query int false "Items per page (default 20, max 50)"
+// @Success 200 {object} dto.VerificationRequestsResponse "Pending requests"
+// @Failure 403 {object} dto.ErrorResponse "Not an admin"
+// @Router /admin/verification-requests [get]
+func (h *VerificationRequestHandler) ListPending(c *fiber.Ctx) error {
+	userID := getUserID(c)
+
+	page := c.QueryInt("page", 1)
+	pageSize := c.QueryInt("page_size", constants.VerificationRequestsDefaultPageSize)
+	if page < 1 {
+		page = 1
+	}
+	if pageSize < 1 {
+		pageSize = constants.VerificationRequestsDefaultPageSize
+	}
+	if pageSize > constants.VerificationRequestsMaxPageSize {
+		pageSize = constants.VerificationRequestsMaxPageSize
+	}
+
+	requests, total, err := h.requestSvc.ListPending(page, pageSize)
+	if err != nil {
+		logger.LogWithContext(getTraceID(c), userID).Errorw("Failed to list verification requests", "error", err)
+		return response.InternalError(c, "Failed to list verification requests", constants.ErrCodeFetchFailed)
+	}
+
+	return response.JSON(c, dto.VerificationRequestsResponse{
+		Success:  true,
+		Requests: requests,
+		Total:    total,
+		Page:     page,
+		PageSize: pageSize,
+		HasMore:  int64(page*pageSize) < total,
+	})
+}
+
+// ReviewRequest approves or denies a pending verification request
+// @Summary Review a verification request (admin)
+// @Description Approve (sets the verified badge and notifies the user) or deny a pending request
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "Verification request ID"
+// @Param request body dto.ReviewVerificationRequest true "Decision"
+// @Success 200 {object} dto.VerificationRequestResponse "Reviewed request"
+// @Failure 400 {object} dto.ErrorResponse "Invalid request"
+// @Failure 403 {object} dto.ErrorResponse "Not an admin"
+// @Failure 404 {object} dto.ErrorResponse "Request not found or already reviewed"
+// @Router /admin/verification-requests/{id}/review [post]
+func (h *VerificationRequestHandler) ReviewRequest(c *fiber.Ctx) error {
+	userID := getUserID(c)
+	traceID := getTraceID(c)
+
+	requestID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return response.BadRequest(c, "Invalid request ID", constants.ErrCodeInvalidRequest)
+	}
+
+	var req dto.ReviewVerificationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return response.InvalidRequest(c)
+	}
+	if req.Note != nil {
+		note := strings.TrimSpace(*req.Note)
+		if utf8.RuneCountInString(note) > constants.VerificationReviewNoteMaxLen {
+			return response.BadRequest(c, fmt.Sprintf("Note must be at most %d characters", constants.VerificationReviewNoteMaxLen), constants.ErrCodeInvalidInput)
+		}
+		req.Note = &note
+		if note == "" {
+			req.Note = nil
+		}
+	}
+
+	reviewed, err := h.requestSvc.Review(c.Context(), userID, uint(requestID), req.Approve, req.Note)
+	if err != nil {
+		if errors.Is(err, repository.ErrVerificationRequestNotFound) {
+			return response.NotFound(c, "Verification request not found or already reviewed", constants.ErrCodeVerificationRequestNotFound)
+		}
+		logger.LogWithContext(traceID, userID).Errorw("Failed to review verification request", "request_id", requestID, "error", err)
+		return response.InternalError(c, "Failed to review verification request", constants.ErrCodeUpdateFailed)
+	}
+
+	return response.JSON(c, dto.VerificationRequestResponse{
+		Success: true,
+		Request: reviewed,
+	})
+}
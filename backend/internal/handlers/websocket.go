@@ -3,6 +3,8 @@ package handlers
 import (
 	"context"
 	"encoding/json"
+	"strconv"
+	"strings"
 	"sync"
 	"time"
 
@@ -10,6 +12,7 @@ import (
 	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
 	"github.com/aman1117/backend/internal/services"
+	"github.com/aman1117/backend/pkg/models"
 	"github.com/gofiber/contrib/websocket"
 	"github.com/gofiber/fiber/v2"
 	"github.com/google/uuid"
@@ -31,6 +34,7 @@ const (
 	WSTypeConnected       = "connected"
 	WSTypeError           = "error"
 	WSTypePendingDelivery = "pending_delivery"
+	WSTypeFollowCounts    = "follow_counts"
 )
 
 // NotificationWSHandler handles WebSocket connections for real-time notifications
@@ -80,6 +84,17 @@ func (h *NotificationWSHandler) UpgradeMiddleware() fiber.Handler {
 		c.Locals("user_id", claims.UserID)
 		c.Locals("username", claims.Username)
 
+		// Reconnecting clients send the last notification seq they saw
+		if lastSeq := c.Query("last_seq"); lastSeq != "" {
+			seq, err := strconv.ParseInt(lastSeq, 10, 64)
+			if err != nil || seq < 0 {
+				return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+					"error": "Invalid last_seq",
+				})
+			}
+			c.Locals("last_seq", seq)
+		}
+
 		return c.Next()
 	}
 }
@@ -118,8 +133,13 @@ func (h *NotificationWSHandler) HandleConnection() fiber.Handler {
 			"user_id":       userID,
 		})
 
-		// Deliver any pending notifications
-		h.deliverPendingNotifications(ctx, c, userID, log)
+		// Deliver missed notifications: replay from the DB when the client resumes
+		// from a seq, otherwise fall back to the Redis pending queue
+		if lastSeq, ok := c.Locals("last_seq").(int64); ok {
+			h.replayNotifications(ctx, c, userID, lastSeq, log)
+		} else {
+			h.deliverPendingNotifications(ctx, c, userID, log)
+		}
 
 		// Subscribe to Redis pub/sub for this user
 		pubsub := h.notifSvc.SubscribeToNotifications(ctx, userID)
@@ -218,9 +238,13 @@ func (h *NotificationWSHandler) forwardPubSubMessage(c *websocket.Conn, msg *gor
 	c.SetWriteDeadline(time.Now().Add(constants.WSWriteTimeout))
 
 	// Create WebSocket message wrapper
-	// msg.Payload is already the JSON string of the notification
+	// msg.Payload is already the JSON string of the notification or count event
+	msgType := WSTypeNotification
+	if strings.HasPrefix(msg.Channel, constants.FollowCountChannelPrefix) {
+		msgType = WSTypeFollowCounts
+	}
 	wsMsg := WSMessage{
-		Type:    WSTypeNotification,
+		Type:    msgType,
 		Payload: json.RawMessage(msg.Payload),
 	}
 
@@ -231,8 +255,24 @@ func (h *NotificationWSHandler) forwardPubSubMessage(c *websocket.Conn, msg *gor
 	}
 
 	if err := c.WriteMessage(websocket.TextMessage, data); err != nil {
-		log.Warnw("Failed to send notification via WebSocket", "error", err)
+		log.Warnw("Failed to forward pub/sub message via WebSocket", "type", msgType, "error", err)
+	}
+}
+
+// replayNotifications sends every notification newer than lastSeq on reconnect
+func (h *NotificationWSHandler) replayNotifications(ctx context.Context, c *websocket.Conn, userID uint, lastSeq int64, log *zap.SugaredLogger) {
+	missed, err := h.notifSvc.GetNotificationsSince(ctx, userID, lastSeq)
+	if err != nil {
+		log.Warnw("Failed to replay notifications", "last_seq", lastSeq, "error", err)
+		return
+	}
+
+	if len(missed) == 0 {
+		return
 	}
+
+	log.Infow("Replaying missed notifications", "last_seq", lastSeq, "count", len(missed))
+	h.sendNotificationBatch(c, missed, log)
 }
 
 // deliverPendingNotifications sends any pending notifications on reconnect
@@ -248,9 +288,13 @@ func (h *NotificationWSHandler) deliverPendingNotifications(ctx context.Context,
 	}
 
 	log.Infow("Delivering pending notifications", "count", len(pending))
+	h.sendNotificationBatch(c, pending, log)
+}
 
+// sendNotificationBatch sends missed notifications as a single pending_delivery message
+func (h *NotificationWSHandler) sendNotificationBatch(c *websocket.Conn, notifs []models.Notification, log *zap.SugaredLogger) {
 	// Convert to DTOs
-	dtos := dto.NotificationsToDTOs(pending)
+	dtos := dto.NotificationsToDTOs(notifs)
 
 	wsMsg := WSMessage{
 		Type:    WSTypePendingDelivery,
@@ -2,14 +2,20 @@
 package middleware
 
 import (
+	"errors"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/aman1117/backend/internal/config"
 	"github.com/aman1117/backend/internal/constants"
 	"github.com/aman1117/backend/internal/handlers"
 	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/internal/response"
+	"github.com/aman1117/backend/pkg/redis"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/limiter"
 	"github.com/google/uuid"
@@ -20,163 +26,190 @@ import (
 
 // RateLimitConfig holds configuration for a rate limiter
 type RateLimitConfig struct {
+	Name       string // Namespaces the limiter's keys so groups don't share counters
 	Max        int
 	Expiration time.Duration
 	Message    string
 	KeyFunc    func(*fiber.Ctx) string
 }
 
-// NewRateLimiter creates a new rate limiter middleware with the given config
+// NewRateLimiter creates a sliding-window rate limiter middleware with the given config.
+// Counts are kept in Redis so every instance shares them; when Redis is not configured
+// or a Redis call fails, an in-memory limiter with the same limits takes over. Rejected
+// requests get 429 with a Retry-After header.
 func NewRateLimiter(cfg RateLimitConfig) fiber.Handler {
-	return limiter.New(limiter.Config{
-		Max:        cfg.Max,
-		Expiration: cfg.Expiration,
-		KeyGenerator: func(c *fiber.Ctx) string {
-			if cfg.KeyFunc != nil {
-				return cfg.KeyFunc(c)
-			}
-			// Default: rate limit by IP
-			return c.IP()
-		},
-		LimitReached: func(c *fiber.Ctx) error {
-			logger.Sugar.Warnw("Rate limit exceeded",
-				"ip", c.IP(),
-				"path", c.Path(),
-				"method", c.Method(),
-			)
-			return response.Error(c, fiber.StatusTooManyRequests, cfg.Message, constants.ErrCodeRateLimitExceeded)
-		},
+	keyFunc := cfg.KeyFunc
+	if keyFunc == nil {
+		// Default: rate limit by IP
+		keyFunc = func(c *fiber.Ctx) string { return c.IP() }
+	}
+
+	limitReached := func(c *fiber.Ctx) error {
+		logger.Sugar.Warnw("Rate limit exceeded",
+			"limiter", cfg.Name,
+			"ip", c.IP(),
+			"path", c.Path(),
+			"method", c.Method(),
+		)
+		return response.Error(c, fiber.StatusTooManyRequests, cfg.Message, constants.ErrCodeRateLimitExceeded)
+	}
+
+	local := limiter.New(limiter.Config{
+		Max:                    cfg.Max,
+		Expiration:             cfg.Expiration,
+		KeyGenerator:           keyFunc,
+		LimitReached:           limitReached,
+		LimiterMiddleware:      limiter.SlidingWindow{},
 		SkipFailedRequests:     false,
 		SkipSuccessfulRequests: false,
 	})
+
+	return func(c *fiber.Ctx) error {
+		if !redis.IsAvailable() {
+			return local(c)
+		}
+
+		allowed, retryAfter, err := redis.AllowRequest(c.Context(), cfg.Name+":"+keyFunc(c), cfg.Max, cfg.Expiration)
+		if err != nil {
+			logger.Sugar.Warnw("Rate limit check failed, using in-memory limiter", "limiter", cfg.Name, "error", err)
+			return local(c)
+		}
+		if !allowed {
+			c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+			return limitReached(c)
+		}
+		return c.Next()
+	}
+}
+
+// userOrIPKey rate limits by user ID if authenticated, otherwise by IP
+func userOrIPKey(c *fiber.Ctx) string {
+	if userID, ok := c.Locals("user_id").(uint); ok && userID > 0 {
+		return fmt.Sprintf("user:%d", userID)
+	}
+	return c.IP()
 }
 
 // AuthRateLimiter returns a rate limiter for authentication endpoints
-// Strict: 5 requests per minute per IP
-func AuthRateLimiter() fiber.Handler {
+// Strict, per IP (default 5 requests per minute)
+func AuthRateLimiter(rule config.RateLimitRule) fiber.Handler {
 	return NewRateLimiter(RateLimitConfig{
-		Max:        constants.RateLimitAuthMaxRequests,
-		Expiration: constants.RateLimitAuthWindow,
+		Name:       "auth",
+		Max:        rule.Max,
+		Expiration: rule.Window,
 		Message:    constants.MsgRateLimitAuth,
 	})
 }
 
 // PasswordResetRateLimiter returns a rate limiter for password reset endpoints
-// Very strict: 3 requests per hour per IP
-func PasswordResetRateLimiter() fiber.Handler {
+// Very strict, per IP (default 5 requests per 15 minutes)
+func PasswordResetRateLimiter(rule config.RateLimitRule) fiber.Handler {
 	return NewRateLimiter(RateLimitConfig{
-		Max:        constants.RateLimitPasswordMaxRequests,
-		Expiration: constants.RateLimitPasswordWindow,
+		Name:       "password",
+		Max:        rule.Max,
+		Expiration: rule.Window,
 		Message:    constants.MsgRateLimitPassword,
 	})
 }
 
 // APIRateLimiter returns a rate limiter for general API endpoints
-// Moderate: 100 requests per minute per user (or IP if not authenticated)
-func APIRateLimiter() fiber.Handler {
+// Moderate, per user or IP if not authenticated (default 200 requests per minute)
+func APIRateLimiter(rule config.RateLimitRule) fiber.Handler {
 	return NewRateLimiter(RateLimitConfig{
-		Max:        constants.RateLimitAPIMaxRequests,
-		Expiration: constants.RateLimitAPIWindow,
+		Name:       "api",
+		Max:        rule.Max,
+		Expiration: rule.Window,
 		Message:    constants.MsgRateLimitAPI,
-		KeyFunc: func(c *fiber.Ctx) string {
-			// Rate limit by user ID if authenticated, otherwise by IP
-			if userID, ok := c.Locals("user_id").(uint); ok && userID > 0 {
-				return fmt.Sprintf("user:%d", userID)
-			}
-			return c.IP()
-		},
+		KeyFunc:    userOrIPKey,
 	})
 }
 
 // UploadRateLimiter returns a rate limiter for file upload endpoints
-// Strict: 10 uploads per minute per user
-func UploadRateLimiter() fiber.Handler {
+// Strict, per user (default 20 uploads per minute)
+func UploadRateLimiter(rule config.RateLimitRule) fiber.Handler {
 	return NewRateLimiter(RateLimitConfig{
-		Max:        constants.RateLimitUploadMaxRequests,
-		Expiration: constants.RateLimitUploadWindow,
+		Name:       "upload",
+		Max:        rule.Max,
+		Expiration: rule.Window,
 		Message:    constants.MsgRateLimitUpload,
-		KeyFunc: func(c *fiber.Ctx) string {
-			if userID, ok := c.Locals("user_id").(uint); ok && userID > 0 {
-				return fmt.Sprintf("upload:%d", userID)
-			}
-			return c.IP()
-		},
+		KeyFunc:    userOrIPKey,
 	})
 }
 
 // FollowRateLimiter returns a rate limiter for follow/unfollow endpoints
-// Moderate: 60 follow actions per minute per user
-func FollowRateLimiter() fiber.Handler {
+// Moderate, per user (default 120 follow actions per minute)
+func FollowRateLimiter(rule config.RateLimitRule) fiber.Handler {
 	return NewRateLimiter(RateLimitConfig{
-		Max:        constants.RateLimitFollowMaxRequests,
-		Expiration: constants.RateLimitFollowWindow,
+		Name:       "follow",
+		Max:        rule.Max,
+		Expiration: rule.Window,
 		Message:    "Too many follow actions. Please slow down.",
-		KeyFunc: func(c *fiber.Ctx) string {
-			if userID, ok := c.Locals("user_id").(uint); ok && userID > 0 {
-				return fmt.Sprintf("follow:%d", userID)
-			}
-			return c.IP()
-		},
+		KeyFunc:    userOrIPKey,
 	})
 }
 
 // AutocompleteRateLimiter returns a lenient rate limiter for autocomplete endpoints
-// Lenient: 60 requests per minute per user (supports rapid typing)
-func AutocompleteRateLimiter() fiber.Handler {
+// Lenient, per user to support rapid typing (default 60 requests per minute)
+func AutocompleteRateLimiter(rule config.RateLimitRule) fiber.Handler {
 	return NewRateLimiter(RateLimitConfig{
-		Max:        constants.RateLimitAutocompleteMaxRequests,
-		Expiration: constants.RateLimitAutocompleteWindow,
+		Name:       "autocomplete",
+		Max:        rule.Max,
+		Expiration: rule.Window,
 		Message:    constants.MsgRateLimitAutocomplete,
-		KeyFunc: func(c *fiber.Ctx) string {
-			if userID, ok := c.Locals("user_id").(uint); ok && userID > 0 {
-				return fmt.Sprintf("autocomplete:%d", userID)
-			}
-			return c.IP()
-		},
+		KeyFunc:    userOrIPKey,
 	})
 }
 
 // CommentRateLimiter returns a rate limiter for comment creation endpoints
-// Moderate: 15 comments per minute per user
-func CommentRateLimiter() fiber.Handler {
+// Moderate, per user (default 15 comments per minute)
+func CommentRateLimiter(rule config.RateLimitRule) fiber.Handler {
 	return NewRateLimiter(RateLimitConfig{
-		Max:        constants.RateLimitCommentMaxRequests,
-		Expiration: constants.RateLimitCommentWindow,
+		Name:       "comment",
+		Max:        rule.Max,
+		Expiration: rule.Window,
 		Message:    constants.MsgRateLimitComment,
-		KeyFunc: func(c *fiber.Ctx) string {
-			if userID, ok := c.Locals("user_id").(uint); ok && userID > 0 {
-				return fmt.Sprintf("comment:%d", userID)
-			}
-			return c.IP()
-		},
+		KeyFunc:    userOrIPKey,
+	})
+}
+
+// PushTestRateLimiter returns a rate limiter for the test-push endpoint
+// Strict, per user (default 3 test pushes per minute)
+func PushTestRateLimiter(rule config.RateLimitRule) fiber.Handler {
+	return NewRateLimiter(RateLimitConfig{
+		Name:       "push_test",
+		Max:        rule.Max,
+		Expiration: rule.Window,
+		Message:    constants.MsgRateLimitPushTest,
+		KeyFunc:    userOrIPKey,
 	})
 }
 
 // CommentLikeRateLimiter returns a rate limiter for comment like endpoints
-// Moderate: 60 like actions per minute per user
-func CommentLikeRateLimiter() fiber.Handler {
+// Moderate, per user (default 60 like actions per minute)
+func CommentLikeRateLimiter(rule config.RateLimitRule) fiber.Handler {
 	return NewRateLimiter(RateLimitConfig{
-		Max:        constants.RateLimitCommentLikeMaxRequests,
-		Expiration: constants.RateLimitCommentLikeWindow,
+		Name:       "comment_like",
+		Max:        rule.Max,
+		Expiration: rule.Window,
 		Message:    constants.MsgRateLimitCommentLike,
-		KeyFunc: func(c *fiber.Ctx) string {
-			if userID, ok := c.Locals("user_id").(uint); ok && userID > 0 {
-				return fmt.Sprintf("comment_like:%d", userID)
-			}
-			return c.IP()
-		},
+		KeyFunc:    userOrIPKey,
 	})
 }
 
 // ==================== Request Logging ====================
 
-// RequestLogger logs all incoming HTTP requests with timing and trace_id
+// RequestLogger logs all incoming HTTP requests with timing and trace_id. The trace_id
+// comes from the caller's X-Request-ID header when it is a sane value, otherwise one is
+// generated; either way it is echoed back in X-Request-ID so clients can quote it.
 func RequestLogger(c *fiber.Ctx) error {
 	start := time.Now()
 
-	// Generate trace_id for this request
-	traceID := uuid.New().String()[:constants.TraceIDLength]
+	traceID := c.Get(constants.TraceIDHeader)
+	if !isValidTraceID(traceID) {
+		traceID = uuid.New().String()[:constants.TraceIDLength]
+	}
 	c.Locals("trace_id", traceID)
+	c.Set(constants.TraceIDHeader, traceID)
 
 	// Process request
 	err := c.Next()
@@ -184,8 +217,16 @@ func RequestLogger(c *fiber.Ctx) error {
 	// Calculate duration
 	duration := time.Since(start)
 
-	// Get status code
+	// Get status code. A returned error is turned into a response by the app's error
+	// handler after this middleware, so take the status from the error instead.
 	status := c.Response().StatusCode()
+	if err != nil {
+		status = fiber.StatusInternalServerError
+		var fiberErr *fiber.Error
+		if errors.As(err, &fiberErr) {
+			status = fiberErr.Code
+		}
+	}
 
 	// Get user_id if available
 	userID, _ := c.Locals("user_id").(uint)
@@ -220,6 +261,20 @@ func RequestLogger(c *fiber.Ctx) error {
 	return err
 }
 
+// isValidTraceID accepts caller-supplied request IDs made of letters, digits, '-', '_'
+// and '.', so they are safe to log and echo back
+func isValidTraceID(id string) bool {
+	if id == "" || len(id) > constants.TraceIDHeaderMaxLen {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
+
 // Auth validates JWT tokens and sets user context
 func Auth(tokenSvc *handlers.TokenService) fiber.Handler {
 	return func(c *fiber.Ctx) error {
@@ -241,6 +296,58 @@ func Auth(tokenSvc *handlers.TokenService) fiber.Handler {
 
 		c.Locals("user_id", claims.UserID)
 		c.Locals("username", claims.Username)
+		c.Locals("session_id", claims.SessionID)
+
+		return c.Next()
+	}
+}
+
+// RequireVerifiedEmail blocks the request with 403 EMAIL_NOT_VERIFIED until the user has
+// verified their email address. Must run after Auth. When enabled is false it lets every
+// request through, so routes can be wired unconditionally.
+func RequireVerifiedEmail(userRepo *repository.UserRepository, enabled bool) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		if !enabled {
+			return c.Next()
+		}
+
+		userID, _ := c.Locals("user_id").(uint)
+		if userID == 0 {
+			return response.UnauthorizedAccess(c)
+		}
+
+		verified, err := userRepo.IsEmailVerified(userID)
+		if err != nil {
+			logger.LogWithUserID(userID).Errorw("Email verification check failed", "error", err)
+			return response.ServerError(c)
+		}
+		if !verified {
+			return response.Forbidden(c, constants.MsgVerificationPending, constants.ErrCodeEmailNotVerified)
+		}
+
+		return c.Next()
+	}
+}
+
+// RequireAdmin allows the request through only if the authenticated user is an admin.
+// Must run after Auth; the admin flag is read from the database on every request so
+// revoking it takes effect immediately.
+func RequireAdmin(userRepo *repository.UserRepository) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		userID, _ := c.Locals("user_id").(uint)
+		if userID == 0 {
+			return response.UnauthorizedAccess(c)
+		}
+
+		isAdmin, err := userRepo.IsAdmin(userID)
+		if err != nil {
+			logger.LogWithUserID(userID).Errorw("Admin check failed", "error", err)
+			return response.ServerError(c)
+		}
+		if !isAdmin {
+			logger.LogWithUserID(userID).Warnw("Non-admin denied admin endpoint", "path", c.Path())
+			return response.Forbidden(c, "Admin access required", constants.ErrCodeAdminRequired)
+		}
 
 		return c.Next()
 	}
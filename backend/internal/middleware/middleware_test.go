@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"testing"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/dto"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/internal/testutil"
+	"github.com/gofiber/fiber/v2"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	// Middleware logs through the package-level logger, which main normally initializes
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	os.Exit(m.Run())
+}
+
+// newAdminTestApp mounts RequireAdmin on a route. Auth is stood in for by a handler that
+// sets user_id from the X-Test-User header, as Auth does from the token.
+func newAdminTestApp(userRepo *repository.UserRepository) *fiber.App {
+	app := fiber.New()
+	fakeAuth := func(c *fiber.Ctx) error {
+		if id, err := strconv.ParseUint(c.Get("X-Test-User"), 10, 32); err == nil {
+			c.Locals("user_id", uint(id))
+		}
+		return c.Next()
+	}
+	app.Get("/admin", fakeAuth, RequireAdmin(userRepo), func(c *fiber.Ctx) error {
+		return c.SendString("ok")
+	})
+	return app
+}
+
+// getAdmin requests the admin route as userID (0 for no user) and returns the status
+// and, for rejections, the error code
+func getAdmin(t *testing.T, app *fiber.App, userID uint) (int, string) {
+	t.Helper()
+	req := httptest.NewRequest(fiber.MethodGet, "/admin", nil)
+	if userID != 0 {
+		req.Header.Set("X-Test-User", strconv.FormatUint(uint64(userID), 10))
+	}
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatalf("app.Test: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == fiber.StatusOK {
+		return resp.StatusCode, ""
+	}
+	var body dto.ErrorResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("decode error response: %v", err)
+	}
+	return resp.StatusCode, body.ErrorCode
+}
+
+func TestRequireAdminRejectsUnauthenticated(t *testing.T) {
+	// The user lookup is never reached, so no database is needed
+	app := newAdminTestApp(nil)
+
+	status, code := getAdmin(t, app, 0)
+	if status != fiber.StatusUnauthorized || code != constants.ErrCodeUnauthorized {
+		t.Errorf("unauthenticated request = %d %s, want %d %s", status, code, fiber.StatusUnauthorized, constants.ErrCodeUnauthorized)
+	}
+}
+
+func TestRequireAdmin(t *testing.T) {
+	db := testutil.OpenDB(t)
+	admin := testutil.CreateUser(t, db, "adminuser")
+	if err := db.Model(admin).Update("is_admin", true).Error; err != nil {
+		t.Fatalf("grant admin: %v", err)
+	}
+	member := testutil.CreateUser(t, db, "regularuser")
+
+	app := newAdminTestApp(repository.NewUserRepository(db))
+
+	tests := []struct {
+		name       string
+		userID     uint
+		wantStatus int
+		wantCode   string
+	}{
+		{"admin passes", admin.ID, fiber.StatusOK, ""},
+		{"non-admin is forbidden", member.ID, fiber.StatusForbidden, constants.ErrCodeAdminRequired},
+		{"unknown user is forbidden", member.ID + 1000, fiber.StatusForbidden, constants.ErrCodeAdminRequired},
+		{"unauthenticated is rejected", 0, fiber.StatusUnauthorized, constants.ErrCodeUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, code := getAdmin(t, app, tt.userID)
+			if status != tt.wantStatus || code != tt.wantCode {
+				t.Errorf("got %d %q, want %d %q", status, code, tt.wantStatus, tt.wantCode)
+			}
+		})
+	}
+
+	t.Run("revoking admin applies to the next request", func(t *testing.T) {
+		if err := db.Model(admin).Update("is_admin", false).Error; err != nil {
+			t.Fatalf("revoke admin: %v", err)
+		}
+		if status, code := getAdmin(t, app, admin.ID); status != fiber.StatusForbidden {
+			t.Errorf("got %d %q after revoking admin, want %d", status, code, fiber.StatusForbidden)
+		}
+	})
+
+	t.Run("database errors fail closed", func(t *testing.T) {
+		sqlDB, err := db.DB()
+		if err != nil {
+			t.Fatalf("get sql.DB: %v", err)
+		}
+		sqlDB.Close()
+		if status, _ := getAdmin(t, app, admin.ID); status != fiber.StatusInternalServerError {
+			t.Errorf("got %d with the database down, want %d", status, fiber.StatusInternalServerError)
+		}
+	})
+}
@@ -3,7 +3,6 @@ package repository
 
 import (
 	"errors"
-	"strings"
 	"time"
 
 	"github.com/aman1117/backend/pkg/models"
@@ -77,6 +76,17 @@ func (r *ActivityPhotoRepository) GetByActivityName(userID uint, activityName st
 	return photos, err
 }
 
+// closeFriendsVisibilityFilter hides close-friends-only photos (alias ap) unless the viewer
+// (bound parameter) is on the owner's close friends list.
+const closeFriendsVisibilityFilter = `(ap.visibility <> 'close_friends' OR EXISTS (
+			SELECT 1 FROM close_friends cf WHERE cf.owner_id = ap.user_id AND cf.friend_id = ?
+		))`
+
+// activeOwnerFilter hides stories of deactivated accounts from feeds
+const activeOwnerFilter = `NOT EXISTS (
+			SELECT 1 FROM users u WHERE u.id = ap.user_id AND u.is_deactivated = true
+		)`
+
 // GetFollowingPhotos retrieves photos from users that the viewer follows for a specific date
 func (r *ActivityPhotoRepository) GetFollowingPhotos(viewerID uint, photoDate time.Time, limit, offset int) ([]models.ActivityPhoto, error) {
 	var photos []models.ActivityPhoto
@@ -86,94 +96,169 @@ func (r *ActivityPhotoRepository) GetFollowingPhotos(viewerID uint, photoDate ti
 		WHERE fe.follower_id = ? 
 		AND fe.state = 'ACTIVE'
 		AND ap.photo_date = ?
-		ORDER BY ap.created_at DESC
+		AND `+closeFriendsVisibilityFilter+`
+		AND `+activeOwnerFilter+`
+		ORDER BY ap.created_at DESC, ap.id DESC
 		LIMIT ? OFFSET ?
-	`, viewerID, photoDate, limit, offset).Scan(&photos).Error
+	`, viewerID, photoDate, viewerID, limit, offset).Scan(&photos).Error
 	return photos, err
 }
 
-// GetFollowingPhotosGrouped retrieves photos grouped by user for a specific date
-func (r *ActivityPhotoRepository) GetFollowingPhotosGrouped(viewerID uint, photoDate time.Time, limit int) ([]models.UserStoryGroup, error) {
+// StoryFeedCursor represents a cursor for the grouped following stories feed.
+// Owners are ordered by their most recent upload, with user_id as a tiebreaker.
+type StoryFeedCursor struct {
+	LatestUpload time.Time
+	UserID       uint
+}
+
+// GetFollowingPhotosGrouped retrieves photos grouped by user for a specific date.
+// Owners with newer uploads come first; pass the last group's (LatestUploadAt, UserID) as cursor for the next page.
+func (r *ActivityPhotoRepository) GetFollowingPhotosGrouped(viewerID uint, photoDate time.Time, limit int, cursor *StoryFeedCursor) ([]models.UserStoryGroup, error) {
+	args := []interface{}{viewerID, photoDate, viewerID}
+	cursorFilter := ""
+	if cursor != nil {
+		cursorFilter = "WHERE (latest_upload, user_id) < (?, ?)"
+		args = append(args, cursor.LatestUpload, cursor.UserID)
+	}
+	args = append(args, limit)
+
 	// First get unique users with photos, ordered by most recent upload
-	var userIDs []uint
+	var owners []struct {
+		UserID       uint
+		LatestUpload time.Time
+	}
 	err := r.db.Raw(`
-		SELECT user_id FROM (
+		SELECT user_id, latest_upload FROM (
 			SELECT ap.user_id, MAX(ap.created_at) as latest_upload
 			FROM activity_photos ap
 			INNER JOIN follow_edges_by_follower fe ON ap.user_id = fe.followee_id
 			WHERE fe.follower_id = ? 
 			AND fe.state = 'ACTIVE'
 			AND ap.photo_date = ?
+			AND `+closeFriendsVisibilityFilter+`
+			AND `+activeOwnerFilter+`
 			GROUP BY ap.user_id
-			ORDER BY latest_upload DESC
-			LIMIT ?
 		) sub
-	`, viewerID, photoDate, limit).Scan(&userIDs).Error
+		`+cursorFilter+`
+		ORDER BY latest_upload DESC, user_id DESC
+		LIMIT ?
+	`, args...).Scan(&owners).Error
 	if err != nil {
 		return nil, err
 	}
 
-	// For each user, get their photos and user info
-	var groups []models.UserStoryGroup
-	for _, userID := range userIDs {
-		var user struct {
-			ID              uint
-			Username        string
-			ProfilePic      *string
-			ProfilePicThumb *string
-		}
-		if err := r.db.Raw("SELECT id, username, profile_pic, profile_pic_thumb FROM users WHERE id = ?", userID).Scan(&user).Error; err != nil {
-			continue
-		}
+	if len(owners) == 0 {
+		return []models.UserStoryGroup{}, nil
+	}
 
-		var photos []models.ActivityPhoto
-		if err := r.db.Where("user_id = ? AND photo_date = ?", userID, photoDate).
-			Order("created_at ASC"). // Order by time: older first, newer last
-			Find(&photos).Error; err != nil {
-			continue
-		}
+	ownerIDs := make([]uint, len(owners))
+	for i, owner := range owners {
+		ownerIDs[i] = owner.UserID
+	}
 
-		// Get viewed photo IDs for this viewer
-		var viewedPhotoIDs []uint
-		r.db.Raw(`
-			SELECT photo_id FROM story_views 
-			WHERE viewer_id = ? AND photo_id IN (
-				SELECT id FROM activity_photos WHERE user_id = ? AND photo_date = ?
-			)
-		`, viewerID, userID, photoDate).Scan(&viewedPhotoIDs)
-
-		viewedSet := make(map[uint]bool)
-		for _, id := range viewedPhotoIDs {
-			viewedSet[id] = true
-		}
+	// Batch-load owner info
+	var users []struct {
+		ID              uint
+		Username        string
+		ProfilePic      *string
+		ProfilePicThumb *string
+	}
+	if err := r.db.Raw("SELECT id, username, profile_pic, profile_pic_thumb FROM users WHERE id IN ?", ownerIDs).
+		Scan(&users).Error; err != nil {
+		return nil, err
+	}
 
-		// Convert photos to ActivityPhotoInStory with view status
-		photosWithViewed := make([]models.ActivityPhotoInStory, len(photos))
-		hasUnseen := false
-		for i, photo := range photos {
-			viewed := viewedSet[photo.ID]
-			if !viewed {
-				hasUnseen = true
-			}
-			photosWithViewed[i] = models.ActivityPhotoInStory{
-				ActivityPhoto: photo,
-				Viewed:        viewed,
-			}
-		}
+	// Load all visible photos for the page in one query, with like counts and the viewer's seen state.
+	// Ordered by time: older first, newer last
+	var photos []models.ActivityPhotoInStory
+	err = r.db.Raw(`
+		SELECT ap.*,
+			COUNT(DISTINCT sl.id) AS like_count,
+			COUNT(sv.id) > 0 AS viewed
+		FROM activity_photos ap
+		LEFT JOIN story_likes sl ON sl.photo_id = ap.id
+		LEFT JOIN story_views sv ON sv.photo_id = ap.id AND sv.viewer_id = ?
+		WHERE ap.user_id IN ?
+		AND ap.photo_date = ?
+		AND `+closeFriendsVisibilityFilter+`
+		GROUP BY ap.id
+		ORDER BY ap.created_at ASC
+	`, viewerID, ownerIDs, photoDate, viewerID).Scan(&photos).Error
+	if err != nil {
+		return nil, err
+	}
+
+	photosByOwner := make(map[uint][]models.ActivityPhotoInStory, len(owners))
+	for _, photo := range photos {
+		photosByOwner[photo.UserID] = append(photosByOwner[photo.UserID], photo)
+	}
 
-		groups = append(groups, models.UserStoryGroup{
+	groupsByOwner := make(map[uint]*models.UserStoryGroup, len(users))
+	for _, user := range users {
+		groupsByOwner[user.ID] = &models.UserStoryGroup{
 			UserID:          user.ID,
 			Username:        user.Username,
 			ProfilePic:      user.ProfilePic,
 			ProfilePicThumb: user.ProfilePicThumb,
-			Photos:          photosWithViewed,
-			HasUnseen:       hasUnseen,
-		})
+		}
+	}
+
+	// Assemble groups in feed order
+	groups := make([]models.UserStoryGroup, 0, len(owners))
+	for _, owner := range owners {
+		group, ok := groupsByOwner[owner.UserID]
+		if !ok {
+			continue
+		}
+		group.Photos = photosByOwner[owner.UserID]
+		group.LatestUploadAt = owner.LatestUpload
+		for _, photo := range group.Photos {
+			if photo.Viewed {
+				group.ViewedCount++
+			}
+		}
+		group.HasUnseen = group.ViewedCount < len(group.Photos)
+		group.AllViewed = len(group.Photos) > 0 && !group.HasUnseen
+		groups = append(groups, *group)
 	}
 
 	return groups, nil
 }
 
+// Replace atomically deletes the old photo (with its views, likes and replies) and creates the new one.
+// Interactions are removed explicitly so the replacement starts fresh even without FK cascades.
+func (r *ActivityPhotoRepository) Replace(oldID uint, photo *models.ActivityPhoto) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("photo_id = ?", oldID).Delete(&models.StoryView{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id = ?", oldID).Delete(&models.StoryLike{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("photo_id = ?", oldID).Delete(&models.StoryReply{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Delete(&models.ActivityPhoto{}, oldID).Error; err != nil {
+			return err
+		}
+		return tx.Create(photo).Error
+	})
+}
+
+// CreateReply stores a story reply
+func (r *ActivityPhotoRepository) CreateReply(reply *models.StoryReply) error {
+	return r.db.Create(reply).Error
+}
+
+// CountRecentReplies counts replies from a sender to a photo since the given time
+func (r *ActivityPhotoRepository) CountRecentReplies(senderID, photoID uint, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.StoryReply{}).
+		Where("photo_id = ? AND sender_id = ? AND created_at >= ?", photoID, senderID, since).
+		Count(&count).Error
+	return count, err
+}
+
 // Delete deletes a photo by ID
 func (r *ActivityPhotoRepository) Delete(id uint) error {
 	return r.db.Delete(&models.ActivityPhoto{}, id).Error
@@ -186,6 +271,37 @@ func (r *ActivityPhotoRepository) DeleteByUserAndActivity(userID uint, activityN
 		Delete(&models.ActivityPhoto{}).Error
 }
 
+// DeleteAllForUser deletes every photo a user owns, with their views, likes and replies,
+// in one transaction. Returns the deleted photos so the caller can remove their blobs.
+func (r *ActivityPhotoRepository) DeleteAllForUser(userID uint) ([]models.ActivityPhoto, error) {
+	var photos []models.ActivityPhoto
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).
+			Find(&photos).Error; err != nil {
+			return err
+		}
+		if len(photos) == 0 {
+			return nil
+		}
+
+		ids := make([]uint, len(photos))
+		for i, photo := range photos {
+			ids[i] = photo.ID
+		}
+		for _, model := range []interface{}{&models.StoryView{}, &models.StoryLike{}, &models.StoryReply{}} {
+			if err := tx.Where("photo_id IN ?", ids).Delete(model).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Where("id IN ?", ids).Delete(&models.ActivityPhoto{}).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return photos, nil
+}
+
 // Exists checks if a photo exists for a user, activity, and date
 func (r *ActivityPhotoRepository) Exists(userID uint, activityName string, photoDate time.Time) (bool, error) {
 	var count int64
@@ -217,6 +333,21 @@ func (r *ActivityPhotoRepository) RecordView(viewerID, photoID uint) error {
 	return r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(view).Error
 }
 
+// RecordViewsBatch records views for multiple photos in a single statement (idempotent).
+// Photos owned by the viewer are skipped.
+func (r *ActivityPhotoRepository) RecordViewsBatch(viewerID uint, photoIDs []uint) error {
+	if len(photoIDs) == 0 {
+		return nil
+	}
+	return r.db.Exec(`
+		INSERT INTO story_views (viewer_id, photo_id, viewed_at)
+		SELECT ?, ap.id, NOW()
+		FROM activity_photos ap
+		WHERE ap.id IN ? AND ap.user_id <> ?
+		ON CONFLICT (viewer_id, photo_id) DO NOTHING
+	`, viewerID, photoIDs, viewerID).Error
+}
+
 // HasViewed checks if a user has viewed a photo
 func (r *ActivityPhotoRepository) HasViewed(viewerID, photoID uint) (bool, error) {
 	var count int64
@@ -226,8 +357,18 @@ func (r *ActivityPhotoRepository) HasViewed(viewerID, photoID uint) (bool, error
 	return count > 0, err
 }
 
-// GetViewers retrieves all viewers of a photo with their user info
-func (r *ActivityPhotoRepository) GetViewers(photoID uint, limit, offset int) ([]models.PhotoViewer, int64, error) {
+// GetViewers retrieves all viewers of a photo with their user info, most recent first.
+// With a cursor (last row's ViewedAt and UserID) offset is ignored.
+func (r *ActivityPhotoRepository) GetViewers(photoID uint, limit, offset int, cursor *ListCursor) ([]models.PhotoViewer, int64, error) {
+	args := []interface{}{photoID}
+	cursorFilter := ""
+	if cursor != nil {
+		cursorFilter = "AND (sv.viewed_at, sv.viewer_id) < (?, ?)"
+		args = append(args, cursor.CreatedAt, cursor.UserID)
+		offset = 0
+	}
+	args = append(args, limit, offset)
+
 	var viewers []models.PhotoViewer
 	err := r.db.Raw(`
 		SELECT 
@@ -239,9 +380,10 @@ func (r *ActivityPhotoRepository) GetViewers(photoID uint, limit, offset int) ([
 		FROM story_views sv
 		INNER JOIN users u ON sv.viewer_id = u.id
 		WHERE sv.photo_id = ?
-		ORDER BY sv.viewed_at DESC
+		`+cursorFilter+`
+		ORDER BY sv.viewed_at DESC, sv.viewer_id DESC
 		LIMIT ? OFFSET ?
-	`, photoID, limit, offset).Scan(&viewers).Error
+	`, args...).Scan(&viewers).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -252,6 +394,58 @@ func (r *ActivityPhotoRepository) GetViewers(photoID uint, limit, offset int) ([
 	return viewers, total, nil
 }
 
+// GetNonViewers retrieves the owner's active followers who have not viewed a photo, most
+// recent followers first. Uses an anti-join against story_views; close-friends-only photos
+// only consider close friends. With a cursor (last row's FollowedAt and UserID) offset is ignored.
+func (r *ActivityPhotoRepository) GetNonViewers(photo *models.ActivityPhoto, limit, offset int, cursor *ListCursor) ([]models.PhotoNonViewer, int64, error) {
+	audienceFilter := ""
+	if photo.Visibility == models.PhotoVisibilityCloseFriends {
+		audienceFilter = "AND EXISTS (SELECT 1 FROM close_friends cf WHERE cf.owner_id = fe.followee_id AND cf.friend_id = fe.follower_id)"
+	}
+
+	fromClause := `
+		FROM follow_edges_by_followee fe
+		INNER JOIN users u ON fe.follower_id = u.id
+		WHERE fe.followee_id = ?
+		AND fe.state = 'ACTIVE'
+		` + audienceFilter + `
+		AND NOT EXISTS (
+			SELECT 1 FROM story_views sv
+			WHERE sv.photo_id = ? AND sv.viewer_id = fe.follower_id
+		)`
+
+	args := []interface{}{photo.UserID, photo.ID}
+	cursorFilter := ""
+	if cursor != nil {
+		cursorFilter = "AND (fe.created_at, fe.follower_id) < (?, ?)"
+		args = append(args, cursor.CreatedAt, cursor.UserID)
+		offset = 0
+	}
+	args = append(args, limit, offset)
+
+	var nonViewers []models.PhotoNonViewer
+	err := r.db.Raw(`
+		SELECT 
+			u.id as user_id, 
+			u.username, 
+			u.profile_pic,
+			u.profile_pic_thumb,
+			fe.created_at as followed_at
+		`+fromClause+`
+		`+cursorFilter+`
+		ORDER BY fe.created_at DESC, fe.follower_id DESC
+		LIMIT ? OFFSET ?
+	`, args...).Scan(&nonViewers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var total int64
+	r.db.Raw(`SELECT COUNT(*) `+fromClause, photo.UserID, photo.ID).Scan(&total)
+
+	return nonViewers, total, nil
+}
+
 // GetViewCount returns the number of views for a photo
 func (r *ActivityPhotoRepository) GetViewCount(photoID uint) (int64, error) {
 	var count int64
@@ -274,21 +468,61 @@ func (r *ActivityPhotoRepository) GetViewedPhotoIDs(viewerID uint, photoIDs []ui
 
 // ==================== Story Likes ====================
 
-// LikePhoto records that a user liked a photo (upsert, handles duplicate gracefully)
-func (r *ActivityPhotoRepository) LikePhoto(likerID, photoID uint) error {
+// ReactToPhoto records a user's reaction to a photo, replacing any earlier reaction
+// (one per user per photo). Returns true if the user had not reacted before.
+func (r *ActivityPhotoRepository) ReactToPhoto(likerID, photoID uint, emoji string) (bool, error) {
 	like := &models.StoryLike{
 		LikerID: likerID,
 		PhotoID: photoID,
+		Emoji:   emoji,
 	}
-	err := r.db.Create(like).Error
-	if err != nil {
-		// Check if it's a duplicate key error - not an error, already liked
-		if strings.Contains(err.Error(), "23505") || strings.Contains(err.Error(), "duplicate") {
-			return nil // Already liked, not an error
-		}
-		return err
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(like)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	if result.RowsAffected > 0 {
+		return true, nil
+	}
+
+	// Already reacted - switch the emoji
+	err := r.db.Model(&models.StoryLike{}).
+		Where("liker_id = ? AND photo_id = ? AND emoji <> ?", likerID, photoID, emoji).
+		Update("emoji", emoji).Error
+	return false, err
+}
+
+// GetReaction returns the emoji a user reacted to a photo with, or "" if they haven't
+func (r *ActivityPhotoRepository) GetReaction(likerID, photoID uint) (string, error) {
+	var emojis []string
+	err := r.db.Model(&models.StoryLike{}).
+		Where("liker_id = ? AND photo_id = ?", likerID, photoID).
+		Limit(1).
+		Pluck("emoji", &emojis).Error
+	if err != nil || len(emojis) == 0 {
+		return "", err
+	}
+	return emojis[0], nil
+}
+
+// GetReactionCounts returns how many users reacted to a photo with each emoji
+func (r *ActivityPhotoRepository) GetReactionCounts(photoID uint) (map[string]int64, error) {
+	var rows []struct {
+		Emoji string
+		Count int64
 	}
-	return nil
+	if err := r.db.Model(&models.StoryLike{}).
+		Select("emoji, COUNT(*) AS count").
+		Where("photo_id = ?", photoID).
+		Group("emoji").
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	counts := make(map[string]int64, len(rows))
+	for _, row := range rows {
+		counts[row.Emoji] = row.Count
+	}
+	return counts, nil
 }
 
 // UnlikePhoto removes a user's like from a photo
@@ -306,8 +540,18 @@ func (r *ActivityPhotoRepository) HasLikedPhoto(likerID, photoID uint) (bool, er
 	return count > 0, err
 }
 
-// GetPhotoLikers retrieves all likers of a photo with their user info
-func (r *ActivityPhotoRepository) GetPhotoLikers(photoID uint, limit, offset int) ([]models.PhotoLiker, int64, error) {
+// GetPhotoLikers retrieves all likers of a photo with their user info, most recent first.
+// With a cursor (last row's LikedAt and UserID) offset is ignored.
+func (r *ActivityPhotoRepository) GetPhotoLikers(photoID uint, limit, offset int, cursor *ListCursor) ([]models.PhotoLiker, int64, error) {
+	args := []interface{}{photoID}
+	cursorFilter := ""
+	if cursor != nil {
+		cursorFilter = "AND (sl.liked_at, sl.liker_id) < (?, ?)"
+		args = append(args, cursor.CreatedAt, cursor.UserID)
+		offset = 0
+	}
+	args = append(args, limit, offset)
+
 	var likers []models.PhotoLiker
 	err := r.db.Raw(`
 		SELECT 
@@ -315,13 +559,15 @@ func (r *ActivityPhotoRepository) GetPhotoLikers(photoID uint, limit, offset int
 			u.username, 
 			u.profile_pic,
 			u.profile_pic_thumb,
-			sl.liked_at
+			sl.liked_at,
+			sl.emoji
 		FROM story_likes sl
 		INNER JOIN users u ON sl.liker_id = u.id
 		WHERE sl.photo_id = ?
-		ORDER BY sl.liked_at DESC
+		`+cursorFilter+`
+		ORDER BY sl.liked_at DESC, sl.liker_id DESC
 		LIMIT ? OFFSET ?
-	`, photoID, limit, offset).Scan(&likers).Error
+	`, args...).Scan(&likers).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -353,7 +599,18 @@ func (r *ActivityPhotoRepository) GetLikedPhotoIDs(likerID uint, photoIDs []uint
 }
 
 // GetPhotoInteractions retrieves combined viewers and likers of a photo (for owner-only modal)
-func (r *ActivityPhotoRepository) GetPhotoInteractions(photoID uint, limit, offset int) ([]models.PhotoInteraction, int64, error) {
+// Ordered by the latest interaction (the like if any, otherwise the view), newest first.
+// With a cursor (last row's SortTime() and UserID) offset is ignored.
+func (r *ActivityPhotoRepository) GetPhotoInteractions(photoID uint, limit, offset int, cursor *ListCursor) ([]models.PhotoInteraction, int64, error) {
+	args := []interface{}{photoID, photoID}
+	cursorFilter := ""
+	if cursor != nil {
+		cursorFilter = "WHERE (COALESCE(l.liked_at, v.viewed_at), COALESCE(v.user_id, l.user_id)) < (?, ?)"
+		args = append(args, cursor.CreatedAt, cursor.UserID)
+		offset = 0
+	}
+	args = append(args, limit, offset)
+
 	var interactions []models.PhotoInteraction
 
 	// Use a FULL OUTER JOIN equivalent to combine views and likes
@@ -369,17 +626,19 @@ func (r *ActivityPhotoRepository) GetPhotoInteractions(photoID uint, limit, offs
 				ELSE 'view'
 			END as interaction_type,
 			v.viewed_at,
-			l.liked_at
+			l.liked_at,
+			l.emoji
 		FROM (
 			SELECT viewer_id as user_id, viewed_at FROM story_views WHERE photo_id = ?
 		) v
 		FULL OUTER JOIN (
-			SELECT liker_id as user_id, liked_at FROM story_likes WHERE photo_id = ?
+			SELECT liker_id as user_id, liked_at, emoji FROM story_likes WHERE photo_id = ?
 		) l ON v.user_id = l.user_id
 		INNER JOIN users u ON u.id = COALESCE(v.user_id, l.user_id)
-		ORDER BY COALESCE(l.liked_at, v.viewed_at) DESC
+		`+cursorFilter+`
+		ORDER BY COALESCE(l.liked_at, v.viewed_at) DESC, COALESCE(v.user_id, l.user_id) DESC
 		LIMIT ? OFFSET ?
-	`, photoID, photoID, limit, offset).Scan(&interactions).Error
+	`, args...).Scan(&interactions).Error
 	if err != nil {
 		return nil, 0, err
 	}
@@ -396,3 +655,57 @@ func (r *ActivityPhotoRepository) GetPhotoInteractions(photoID uint, limit, offs
 
 	return interactions, total, nil
 }
+
+// PhotoExportRow is a user's own photo with aggregate interaction counts for data export.
+// Viewer, liker and reply sender identities are deliberately omitted. PhotoURL is the stored
+// blob URL; ExportService swaps it for a signed, expiring one before writing the export.
+type PhotoExportRow struct {
+	ActivityName string    `gorm:"column:activity_name" json:"activity_name"`
+	PhotoDate    time.Time `gorm:"column:photo_date" json:"photo_date"`
+	PhotoURL     string    `gorm:"column:photo_url" json:"photo_url"`
+	Visibility   string    `gorm:"column:visibility" json:"visibility"`
+	CreatedAt    time.Time `gorm:"column:created_at" json:"created_at"`
+	ViewCount    int64     `gorm:"column:view_count" json:"view_count"`
+	LikeCount    int64     `gorm:"column:like_count" json:"like_count"`
+	ReplyCount   int64     `gorm:"column:reply_count" json:"reply_count"`
+}
+
+// GetPhotosForExport returns all of a user's photos with view, like and reply counts, oldest first
+func (r *ActivityPhotoRepository) GetPhotosForExport(userID uint) ([]PhotoExportRow, error) {
+	var rows []PhotoExportRow
+	err := r.db.Raw(`
+		SELECT ap.activity_name, ap.photo_date, ap.photo_url, ap.visibility, ap.created_at,
+			(SELECT COUNT(*) FROM story_views sv WHERE sv.photo_id = ap.id) AS view_count,
+			(SELECT COUNT(*) FROM story_likes sl WHERE sl.photo_id = ap.id) AS like_count,
+			(SELECT COUNT(*) FROM story_replies sr WHERE sr.photo_id = ap.id) AS reply_count
+		FROM activity_photos ap
+		WHERE ap.user_id = ?
+		ORDER BY ap.photo_date ASC, ap.activity_name ASC`,
+		userID,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+// GetBlobURLsByUsers returns the full and thumbnail URLs of every photo owned by the given users
+func (r *ActivityPhotoRepository) GetBlobURLsByUsers(userIDs []uint) ([]string, error) {
+	if len(userIDs) == 0 {
+		return nil, nil
+	}
+
+	var rows []struct {
+		PhotoURL     string
+		ThumbnailURL string
+	}
+	if err := r.db.Model(&models.ActivityPhoto{}).
+		Select("photo_url, thumbnail_url").
+		Where("user_id IN ?", userIDs).
+		Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	urls := make([]string, 0, len(rows)*2)
+	for _, row := range rows {
+		urls = append(urls, row.PhotoURL, row.ThumbnailURL)
+	}
+	return urls, nil
+}
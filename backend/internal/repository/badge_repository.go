@@ -4,6 +4,7 @@ package repository
 import (
 	"github.com/aman1117/backend/pkg/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // BadgeRepository handles badge data operations
@@ -29,6 +30,16 @@ func (r *BadgeRepository) CreateBatch(badges []models.UserBadge) error {
 	return r.db.Create(&badges).Error
 }
 
+// CreateIfMissing inserts a badge unless the user already has it.
+// Returns true if the row was inserted, so concurrent awarders don't both treat it as new.
+func (r *BadgeRepository) CreateIfMissing(badge *models.UserBadge) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(badge)
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
 // FindByUserID finds all badges for a user
 func (r *BadgeRepository) FindByUserID(userID uint) ([]models.UserBadge, error) {
 	var badges []models.UserBadge
@@ -206,6 +206,311 @@ func (r *FollowRepository) AcceptFollowWithCounters(followerID, followeeID uint)
 	})
 }
 
+// ResolvePendingRequestsWithCounters accepts or declines a batch of pending requests
+// addressed to followeeID in a single transaction. Each edge is transitioned with a
+// conditional update (state = PENDING), so requests that were already accepted,
+// declined or cancelled concurrently are skipped and never touch the counters.
+// Returns the requester IDs that actually transitioned.
+func (r *FollowRepository) ResolvePendingRequestsWithCounters(followeeID uint, requesterIDs []uint, accept bool) ([]uint, error) {
+	transitioned := make([]uint, 0, len(requesterIDs))
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		updates := map[string]interface{}{
+			"state":      models.FollowStateRemoved,
+			"updated_at": now,
+		}
+		if accept {
+			updates = map[string]interface{}{
+				"state":       models.FollowStateActive,
+				"accepted_at": now,
+				"updated_at":  now,
+			}
+		}
+
+		for _, requesterID := range requesterIDs {
+			// Conditional update on follow_edges_by_follower (row lock serializes concurrent writers)
+			result := tx.Model(&models.FollowEdgeByFollower{}).
+				Where("follower_id = ? AND followee_id = ? AND state = ?",
+					requesterID, followeeID, models.FollowStatePending).
+				Updates(updates)
+			if result.Error != nil {
+				return result.Error
+			}
+			if result.RowsAffected == 0 {
+				continue // No longer pending
+			}
+
+			// Mirror into follow_edges_by_followee
+			if err := tx.Model(&models.FollowEdgeByFollowee{}).
+				Where("followee_id = ? AND follower_id = ?", followeeID, requesterID).
+				Updates(updates).Error; err != nil {
+				return err
+			}
+
+			if accept {
+				r.incrementCounterInTx(tx, requesterID, "following_count", 1)
+				r.incrementCounterInTx(tx, followeeID, "followers_count", 1)
+			}
+			r.incrementCounterInTx(tx, followeeID, "pending_requests_count", -1)
+
+			transitioned = append(transitioned, requesterID)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return transitioned, nil
+}
+
+// CancelAllPendingWithCounters removes every PENDING edge where followerID is the follower
+// and decrements each target's pending_requests_count in a single transaction.
+// Only rows still PENDING at update time are touched, so requests accepted concurrently
+// are left ACTIVE. Returns the followee IDs whose requests were cancelled.
+func (r *FollowRepository) CancelAllPendingWithCounters(followerID uint) ([]uint, error) {
+	var followeeIDs []uint
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		// Conditional bulk update on follow_edges_by_follower, returning affected targets
+		if err := tx.Raw(
+			`UPDATE follow_edges_by_follower
+			 SET state = ?, updated_at = ?
+			 WHERE follower_id = ? AND state = ?
+			 RETURNING followee_id`,
+			models.FollowStateRemoved, now, followerID, models.FollowStatePending,
+		).Scan(&followeeIDs).Error; err != nil {
+			return err
+		}
+
+		if len(followeeIDs) == 0 {
+			return nil
+		}
+
+		// Mirror into follow_edges_by_followee
+		if err := tx.Model(&models.FollowEdgeByFollowee{}).
+			Where("follower_id = ? AND followee_id IN ?", followerID, followeeIDs).
+			Updates(map[string]interface{}{
+				"state":      models.FollowStateRemoved,
+				"updated_at": now,
+			}).Error; err != nil {
+			return err
+		}
+
+		for _, followeeID := range followeeIDs {
+			r.incrementCounterInTx(tx, followeeID, "pending_requests_count", -1)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return followeeIDs, nil
+}
+
+// BlockWithCounters removes any follow edges between blocker and blocked (both directions),
+// marks them BLOCKED, decrements counters for edges that were ACTIVE or PENDING and
+// writes the block record, all in a single transaction
+func (r *FollowRepository) BlockWithCounters(blockerID, blockedID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+
+		pairs := [][2]uint{{blockerID, blockedID}, {blockedID, blockerID}}
+		for _, pair := range pairs {
+			followerID, followeeID := pair[0], pair[1]
+
+			// Lock the existing edge (if any) so counter decrements match its state
+			var edge models.FollowEdgeByFollower
+			err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("follower_id = ? AND followee_id = ?", followerID, followeeID).
+				First(&edge).Error
+			if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+				return err
+			}
+			if err == nil {
+				switch edge.State {
+				case models.FollowStateActive:
+					r.incrementCounterInTx(tx, followerID, "following_count", -1)
+					r.incrementCounterInTx(tx, followeeID, "followers_count", -1)
+				case models.FollowStatePending:
+					r.incrementCounterInTx(tx, followeeID, "pending_requests_count", -1)
+				}
+			}
+
+			// Upsert BLOCKED edge into both tables
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "follower_id"}, {Name: "followee_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"state": models.FollowStateBlocked, "accepted_at": nil, "updated_at": now}),
+			}).Create(&models.FollowEdgeByFollower{
+				FollowerID: followerID,
+				FolloweeID: followeeID,
+				State:      models.FollowStateBlocked,
+			}).Error; err != nil {
+				return err
+			}
+
+			if err := tx.Clauses(clause.OnConflict{
+				Columns:   []clause.Column{{Name: "followee_id"}, {Name: "follower_id"}},
+				DoUpdates: clause.Assignments(map[string]interface{}{"state": models.FollowStateBlocked, "accepted_at": nil, "updated_at": now}),
+			}).Create(&models.FollowEdgeByFollowee{
+				FolloweeID: followeeID,
+				FollowerID: followerID,
+				State:      models.FollowStateBlocked,
+			}).Error; err != nil {
+				return err
+			}
+		}
+
+		// Drop close friend entries in both directions
+		if err := tx.Where("(owner_id = ? AND friend_id = ?) OR (owner_id = ? AND friend_id = ?)",
+			blockerID, blockedID, blockedID, blockerID).
+			Delete(&models.CloseFriend{}).Error; err != nil {
+			return err
+		}
+
+		// Write block record (idempotent)
+		return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.UserBlock{
+			BlockerID: blockerID,
+			BlockedID: blockedID,
+		}).Error
+	})
+}
+
+// Unblock removes the block record and, unless the other side still blocks,
+// turns the BLOCKED edges back into REMOVED tombstones.
+// Returns false if no block existed.
+func (r *FollowRepository) Unblock(blockerID, blockedID uint) (bool, error) {
+	var unblocked bool
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("blocker_id = ? AND blocked_id = ?", blockerID, blockedID).
+			Delete(&models.UserBlock{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		unblocked = true
+
+		// Keep edges BLOCKED if the other user still blocks this one
+		var reverseCount int64
+		if err := tx.Model(&models.UserBlock{}).
+			Where("blocker_id = ? AND blocked_id = ?", blockedID, blockerID).
+			Count(&reverseCount).Error; err != nil {
+			return err
+		}
+		if reverseCount > 0 {
+			return nil
+		}
+
+		updates := map[string]interface{}{
+			"state":      models.FollowStateRemoved,
+			"updated_at": time.Now(),
+		}
+		pairCond := "((follower_id = ? AND followee_id = ?) OR (follower_id = ? AND followee_id = ?)) AND state = ?"
+
+		if err := tx.Model(&models.FollowEdgeByFollower{}).
+			Where(pairCond, blockerID, blockedID, blockedID, blockerID, models.FollowStateBlocked).
+			Updates(updates).Error; err != nil {
+			return err
+		}
+
+		return tx.Model(&models.FollowEdgeByFollowee{}).
+			Where(pairCond, blockerID, blockedID, blockedID, blockerID, models.FollowStateBlocked).
+			Updates(updates).Error
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return unblocked, nil
+}
+
+// IsBlockedEither checks if either user has blocked the other
+func (r *FollowRepository) IsBlockedEither(userA, userB uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserBlock{}).
+		Where("(blocker_id = ? AND blocked_id = ?) OR (blocker_id = ? AND blocked_id = ?)",
+			userA, userB, userB, userA).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// HasAnyBlock reports whether userID has blocked anyone or been blocked by anyone
+func (r *FollowRepository) HasAnyBlock(userID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UserBlock{}).
+		Where("blocker_id = ? OR blocked_id = ?", userID, userID).
+		Limit(1).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetBlockedUsersPaginated returns users blocked by blockerID, most recent first
+func (r *FollowRepository) GetBlockedUsersPaginated(blockerID uint, limit int, cursor *ListCursor) ([]models.UserBlock, error) {
+	query := r.db.Where("blocker_id = ?", blockerID)
+
+	if cursor != nil {
+		query = query.Where("(created_at, blocked_id) < (?, ?)", cursor.CreatedAt, cursor.UserID)
+	}
+
+	var blocks []models.UserBlock
+	err := query.Order("created_at DESC, blocked_id DESC").Limit(limit).Find(&blocks).Error
+	return blocks, err
+}
+
+// ==================== Close Friends ====================
+
+// AddCloseFriend adds friendID to ownerID's close friends list (idempotent)
+func (r *FollowRepository) AddCloseFriend(ownerID, friendID uint) error {
+	return r.db.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&models.CloseFriend{OwnerID: ownerID, FriendID: friendID}).Error
+}
+
+// RemoveCloseFriend removes friendID from ownerID's close friends list.
+// Returns false if friendID was not on the list.
+func (r *FollowRepository) RemoveCloseFriend(ownerID, friendID uint) (bool, error) {
+	result := r.db.Where("owner_id = ? AND friend_id = ?", ownerID, friendID).
+		Delete(&models.CloseFriend{})
+	return result.RowsAffected > 0, result.Error
+}
+
+// IsCloseFriend checks if friendID is on ownerID's close friends list
+func (r *FollowRepository) IsCloseFriend(ownerID, friendID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.CloseFriend{}).
+		Where("owner_id = ? AND friend_id = ?", ownerID, friendID).
+		Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// GetCloseFriendFollowerIDs returns the members of ownerID's close friends list who actively
+// follow them: the audience of a close-friends-only story
+func (r *FollowRepository) GetCloseFriendFollowerIDs(ownerID uint) ([]uint, error) {
+	var friendIDs []uint
+	err := r.db.Model(&models.CloseFriend{}).
+		Joins("JOIN follow_edges_by_followee fe ON fe.followee_id = close_friends.owner_id AND fe.follower_id = close_friends.friend_id").
+		Where("close_friends.owner_id = ? AND fe.state = ?", ownerID, models.FollowStateActive).
+		Pluck("close_friends.friend_id", &friendIDs).Error
+	return friendIDs, err
+}
+
 // incrementCounterInTx increments a specific counter field within a transaction using upsert
 func (r *FollowRepository) incrementCounterInTx(tx *gorm.DB, userID uint, field string, delta int) {
 	// Use upsert to handle case where follow_counters row doesn't exist yet
@@ -292,15 +597,20 @@ func (r *FollowRepository) GetPendingEdge(followerID, followeeID uint) (*models.
 
 // ==================== List Operations (Cursor-based Pagination) ====================
 
-// FollowListCursor represents a cursor for paginated follow lists
-type FollowListCursor struct {
+// ListCursor is a keyset cursor for lists ordered by (timestamp DESC, user ID DESC):
+// follow lists, blocks and story viewers/likers/interactions
+type ListCursor struct {
 	CreatedAt time.Time
 	UserID    uint
 }
 
+// deactivatedUserIDs selects accounts hidden by deactivation; list queries exclude them
+const deactivatedUserIDs = "SELECT id FROM users WHERE is_deactivated = true"
+
 // GetFollowersPaginated returns paginated followers for a user
-func (r *FollowRepository) GetFollowersPaginated(followeeID uint, limit int, cursor *FollowListCursor) ([]models.FollowEdgeByFollowee, error) {
-	query := r.db.Where("followee_id = ? AND state = ?", followeeID, models.FollowStateActive)
+func (r *FollowRepository) GetFollowersPaginated(followeeID uint, limit int, cursor *ListCursor) ([]models.FollowEdgeByFollowee, error) {
+	query := r.db.Where("followee_id = ? AND state = ?", followeeID, models.FollowStateActive).
+		Where("follower_id NOT IN (" + deactivatedUserIDs + ")")
 
 	if cursor != nil {
 		query = query.Where("(created_at, follower_id) < (?, ?)", cursor.CreatedAt, cursor.UserID)
@@ -311,6 +621,25 @@ func (r *FollowRepository) GetFollowersPaginated(followeeID uint, limit int, cur
 	return edges, err
 }
 
+// SearchFollowersPaginated returns paginated followers whose username matches query (case-insensitive substring).
+// Joins users so the filter is applied in SQL rather than over-fetching edges.
+func (r *FollowRepository) SearchFollowersPaginated(followeeID uint, query string, limit int, cursor *ListCursor) ([]models.FollowEdgeByFollowee, error) {
+	db := r.db.Model(&models.FollowEdgeByFollowee{}).
+		Select("follow_edges_by_followee.*").
+		Joins("JOIN users ON users.id = follow_edges_by_followee.follower_id").
+		Where("follow_edges_by_followee.followee_id = ? AND follow_edges_by_followee.state = ?", followeeID, models.FollowStateActive).
+		Where("users.username ILIKE ? AND users.is_deactivated = false", "%"+escapeLike(query)+"%")
+
+	if cursor != nil {
+		db = db.Where("(follow_edges_by_followee.created_at, follow_edges_by_followee.follower_id) < (?, ?)", cursor.CreatedAt, cursor.UserID)
+	}
+
+	var edges []models.FollowEdgeByFollowee
+	err := db.Order("follow_edges_by_followee.created_at DESC, follow_edges_by_followee.follower_id DESC").
+		Limit(limit).Find(&edges).Error
+	return edges, err
+}
+
 // GetAllFollowerIDs returns all active follower IDs for a user (for notifications).
 // This is used when broadcasting notifications to all followers (e.g., day completion).
 // Note: For users with very large follower counts, consider batching in the caller.
@@ -323,8 +652,9 @@ func (r *FollowRepository) GetAllFollowerIDs(followeeID uint) ([]uint, error) {
 }
 
 // GetFollowingPaginated returns paginated following for a user
-func (r *FollowRepository) GetFollowingPaginated(followerID uint, limit int, cursor *FollowListCursor) ([]models.FollowEdgeByFollower, error) {
-	query := r.db.Where("follower_id = ? AND state = ?", followerID, models.FollowStateActive)
+func (r *FollowRepository) GetFollowingPaginated(followerID uint, limit int, cursor *ListCursor) ([]models.FollowEdgeByFollower, error) {
+	query := r.db.Where("follower_id = ? AND state = ?", followerID, models.FollowStateActive).
+		Where("followee_id NOT IN (" + deactivatedUserIDs + ")")
 
 	if cursor != nil {
 		query = query.Where("(created_at, followee_id) < (?, ?)", cursor.CreatedAt, cursor.UserID)
@@ -335,9 +665,29 @@ func (r *FollowRepository) GetFollowingPaginated(followerID uint, limit int, cur
 	return edges, err
 }
 
+// SearchFollowingPaginated returns paginated following whose username matches query (case-insensitive substring).
+// Joins users so the filter is applied in SQL rather than over-fetching edges.
+func (r *FollowRepository) SearchFollowingPaginated(followerID uint, query string, limit int, cursor *ListCursor) ([]models.FollowEdgeByFollower, error) {
+	db := r.db.Model(&models.FollowEdgeByFollower{}).
+		Select("follow_edges_by_follower.*").
+		Joins("JOIN users ON users.id = follow_edges_by_follower.followee_id").
+		Where("follow_edges_by_follower.follower_id = ? AND follow_edges_by_follower.state = ?", followerID, models.FollowStateActive).
+		Where("users.username ILIKE ? AND users.is_deactivated = false", "%"+escapeLike(query)+"%")
+
+	if cursor != nil {
+		db = db.Where("(follow_edges_by_follower.created_at, follow_edges_by_follower.followee_id) < (?, ?)", cursor.CreatedAt, cursor.UserID)
+	}
+
+	var edges []models.FollowEdgeByFollower
+	err := db.Order("follow_edges_by_follower.created_at DESC, follow_edges_by_follower.followee_id DESC").
+		Limit(limit).Find(&edges).Error
+	return edges, err
+}
+
 // GetPendingIncomingRequests returns paginated pending follow requests for a user
-func (r *FollowRepository) GetPendingIncomingRequests(followeeID uint, limit int, cursor *FollowListCursor) ([]models.FollowEdgeByFollowee, error) {
-	query := r.db.Where("followee_id = ? AND state = ?", followeeID, models.FollowStatePending)
+func (r *FollowRepository) GetPendingIncomingRequests(followeeID uint, limit int, cursor *ListCursor) ([]models.FollowEdgeByFollowee, error) {
+	query := r.db.Where("followee_id = ? AND state = ?", followeeID, models.FollowStatePending).
+		Where("follower_id NOT IN (" + deactivatedUserIDs + ")")
 
 	if cursor != nil {
 		query = query.Where("(created_at, follower_id) < (?, ?)", cursor.CreatedAt, cursor.UserID)
@@ -349,7 +699,7 @@ func (r *FollowRepository) GetPendingIncomingRequests(followeeID uint, limit int
 }
 
 // GetPendingOutgoingRequests returns paginated outgoing pending requests
-func (r *FollowRepository) GetPendingOutgoingRequests(followerID uint, limit int, cursor *FollowListCursor) ([]models.FollowEdgeByFollower, error) {
+func (r *FollowRepository) GetPendingOutgoingRequests(followerID uint, limit int, cursor *ListCursor) ([]models.FollowEdgeByFollower, error) {
 	query := r.db.Where("follower_id = ? AND state = ?", followerID, models.FollowStatePending)
 
 	if cursor != nil {
@@ -363,62 +713,77 @@ func (r *FollowRepository) GetPendingOutgoingRequests(followerID uint, limit int
 
 // ==================== Batch Operations ====================
 
-// BatchLookupRelationships returns the relationship states for viewer -> targets
-func (r *FollowRepository) BatchLookupRelationships(viewerID uint, targetIDs []uint) (map[uint]models.RelationshipState, error) {
-	result := make(map[uint]models.RelationshipState)
+// BatchLookupRelationships returns both relationship directions for viewer <-> targets.
+// Uses one query per direction.
+func (r *FollowRepository) BatchLookupRelationships(viewerID uint, targetIDs []uint) (map[uint]models.RelationshipPair, error) {
+	result := make(map[uint]models.RelationshipPair)
 	for _, id := range targetIDs {
-		result[id] = models.RelationshipNone
+		result[id] = models.RelationshipPair{
+			Outgoing: models.RelationshipNone,
+			Incoming: models.RelationshipNone,
+		}
 	}
 
 	if len(targetIDs) == 0 {
 		return result, nil
 	}
 
+	activeOrPending := []models.FollowState{models.FollowStateActive, models.FollowStatePending}
+
 	// Check outgoing: viewer -> targets (following or requested)
 	var outgoingEdges []models.FollowEdgeByFollower
 	err := r.db.Where("follower_id = ? AND followee_id IN ? AND state IN ?",
-		viewerID, targetIDs, []models.FollowState{models.FollowStateActive, models.FollowStatePending}).
+		viewerID, targetIDs, activeOrPending).
 		Find(&outgoingEdges).Error
 	if err != nil {
 		return nil, err
 	}
 
 	for _, edge := range outgoingEdges {
-		switch edge.State {
-		case models.FollowStateActive:
-			result[edge.FolloweeID] = models.RelationshipFollowing
-		case models.FollowStatePending:
-			result[edge.FolloweeID] = models.RelationshipRequested
-		}
+		pair := result[edge.FolloweeID]
+		pair.Outgoing = relationshipFromEdgeState(edge.State)
+		result[edge.FolloweeID] = pair
 	}
 
-	// Check incoming: targets -> viewer (pending requests to viewer)
-	var incomingEdges []models.FollowEdgeByFollower
-	err = r.db.Where("follower_id IN ? AND followee_id = ? AND state = ?",
-		targetIDs, viewerID, models.FollowStatePending).
+	// Check incoming: targets -> viewer (follows viewer or requested to)
+	var incomingEdges []models.FollowEdgeByFollowee
+	err = r.db.Where("followee_id = ? AND follower_id IN ? AND state IN ?",
+		viewerID, targetIDs, activeOrPending).
 		Find(&incomingEdges).Error
 	if err != nil {
 		return nil, err
 	}
 
 	for _, edge := range incomingEdges {
-		// Only set incoming pending if not already following
-		if result[edge.FollowerID] == models.RelationshipNone {
-			result[edge.FollowerID] = models.RelationshipIncomingPending
-		}
+		pair := result[edge.FollowerID]
+		pair.Incoming = relationshipFromEdgeState(edge.State)
+		result[edge.FollowerID] = pair
 	}
 
 	return result, nil
 }
 
+// relationshipFromEdgeState maps an edge state to a directional relationship state
+func relationshipFromEdgeState(state models.FollowState) models.RelationshipState {
+	switch state {
+	case models.FollowStateActive:
+		return models.RelationshipFollowing
+	case models.FollowStatePending:
+		return models.RelationshipRequested
+	default:
+		return models.RelationshipNone
+	}
+}
+
 // GetMutualFollowerIDs returns IDs of users who both follow targetUserID and viewerID follows
-func (r *FollowRepository) GetMutualFollowerIDs(viewerID, targetUserID uint, limit int, cursor *FollowListCursor) ([]uint, error) {
+func (r *FollowRepository) GetMutualFollowerIDs(viewerID, targetUserID uint, limit int, cursor *ListCursor) ([]uint, error) {
 	// Find users that viewerID follows AND who also follow targetUserID
 	query := r.db.Table("follow_edges_by_follower AS f1").
 		Select("f1.followee_id").
 		Joins("INNER JOIN follow_edges_by_followee AS f2 ON f1.followee_id = f2.follower_id").
 		Where("f1.follower_id = ? AND f1.state = ?", viewerID, models.FollowStateActive).
-		Where("f2.followee_id = ? AND f2.state = ?", targetUserID, models.FollowStateActive)
+		Where("f2.followee_id = ? AND f2.state = ?", targetUserID, models.FollowStateActive).
+		Where("f1.followee_id NOT IN (" + deactivatedUserIDs + ")")
 
 	if cursor != nil {
 		query = query.Where("f1.created_at < ?", cursor.CreatedAt)
@@ -436,13 +801,14 @@ type MutualEdgeRow struct {
 }
 
 // GetMutualFollowersWithTimestamps returns mutual followers with timestamps for cursor pagination
-func (r *FollowRepository) GetMutualFollowersWithTimestamps(viewerID, targetUserID uint, limit int, cursor *FollowListCursor) ([]MutualEdgeRow, error) {
+func (r *FollowRepository) GetMutualFollowersWithTimestamps(viewerID, targetUserID uint, limit int, cursor *ListCursor) ([]MutualEdgeRow, error) {
 	// Find users that viewerID follows AND who also follow targetUserID
 	query := r.db.Table("follow_edges_by_follower AS f1").
 		Select("f1.followee_id AS user_id, f1.created_at").
 		Joins("INNER JOIN follow_edges_by_followee AS f2 ON f1.followee_id = f2.follower_id").
 		Where("f1.follower_id = ? AND f1.state = ?", viewerID, models.FollowStateActive).
-		Where("f2.followee_id = ? AND f2.state = ?", targetUserID, models.FollowStateActive)
+		Where("f2.followee_id = ? AND f2.state = ?", targetUserID, models.FollowStateActive).
+		Where("f1.followee_id NOT IN (" + deactivatedUserIDs + ")")
 
 	if cursor != nil {
 		query = query.Where("(f1.created_at, f1.followee_id) < (?, ?)", cursor.CreatedAt, cursor.UserID)
@@ -453,6 +819,51 @@ func (r *FollowRepository) GetMutualFollowersWithTimestamps(viewerID, targetUser
 	return edges, err
 }
 
+// FollowedByRow represents a user who follows the target and is followed by the viewer
+type FollowedByRow struct {
+	UserID          uint      `gorm:"column:user_id"`
+	Username        string    `gorm:"column:username"`
+	ProfilePic      *string   `gorm:"column:profile_pic"`
+	ProfilePicThumb *string   `gorm:"column:profile_pic_thumb"`
+	Bio             *string   `gorm:"column:bio"`
+	IsPrivate       bool      `gorm:"column:is_private"`
+	IsVerified      bool      `gorm:"column:is_verified"`
+	FollowedAt      time.Time `gorm:"column:followed_at"`
+}
+
+// GetFollowedByMutuals returns users who actively follow targetUserID and whom viewerID
+// actively follows, most recent followers of the target first, plus the total match count
+func (r *FollowRepository) GetFollowedByMutuals(viewerID, targetUserID uint, limit int) ([]FollowedByRow, int64, error) {
+	// Start from the target's followers (by_followee) and keep those the viewer follows (by_follower)
+	base := r.db.Table("follow_edges_by_followee AS f2").
+		Joins("INNER JOIN follow_edges_by_follower AS f1 ON f1.followee_id = f2.follower_id").
+		Where("f2.followee_id = ? AND f2.state = ?", targetUserID, models.FollowStateActive).
+		Where("f1.follower_id = ? AND f1.state = ?", viewerID, models.FollowStateActive).
+		Where("f2.follower_id NOT IN (" + deactivatedUserIDs + ")")
+
+	var total int64
+	if err := base.Session(&gorm.Session{}).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if total == 0 {
+		return []FollowedByRow{}, 0, nil
+	}
+
+	var rows []FollowedByRow
+	err := base.Session(&gorm.Session{}).
+		Select(`u.id AS user_id, u.username, u.profile_pic, u.profile_pic_thumb,
+			u.bio, u.is_private, u.is_verified, f2.created_at AS followed_at`).
+		Joins("INNER JOIN users AS u ON u.id = f2.follower_id").
+		Order("f2.created_at DESC, f2.follower_id DESC").
+		Limit(limit).
+		Scan(&rows).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return rows, total, nil
+}
+
 // ==================== Counter Operations ====================
 
 // GetOrCreateCounter gets or creates a follow counter for a user
@@ -634,6 +1045,59 @@ func (r *FollowRepository) ReconcileCounters(userID uint) error {
 	).Error
 }
 
+// CounterRepairCandidate is a counter row flagged for reconciliation
+type CounterRepairCandidate struct {
+	UserID  uint `gorm:"column:user_id"`
+	Drifted bool `gorm:"column:drifted"` // true if cached counts diverge from edge counts beyond the threshold
+}
+
+// FindCounterRepairCandidates returns counters (ordered by user_id, after afterUserID) that are
+// stale or whose cached counts diverge from the actual edge counts by more than threshold.
+func (r *FollowRepository) FindCounterRepairCandidates(afterUserID uint, staleBefore time.Time, threshold int64, limit int) ([]CounterRepairCandidate, error) {
+	var candidates []CounterRepairCandidate
+	err := r.db.Raw(`
+		SELECT user_id, drifted FROM (
+			SELECT fc.user_id, fc.updated_at,
+				(ABS(fc.followers_count - (
+					SELECT COUNT(*) FROM follow_edges_by_followee e
+					WHERE e.followee_id = fc.user_id AND e.state = ?)) > ?
+				OR ABS(fc.following_count - (
+					SELECT COUNT(*) FROM follow_edges_by_follower e
+					WHERE e.follower_id = fc.user_id AND e.state = ?)) > ?
+				OR ABS(fc.pending_requests_count - (
+					SELECT COUNT(*) FROM follow_edges_by_followee e
+					WHERE e.followee_id = fc.user_id AND e.state = ?)) > ?) AS drifted
+			FROM follow_counters fc
+			WHERE fc.user_id > ?
+			ORDER BY fc.user_id
+			LIMIT ?
+		) page
+		WHERE drifted OR updated_at < ?
+		ORDER BY user_id
+	`,
+		models.FollowStateActive, threshold,
+		models.FollowStateActive, threshold,
+		models.FollowStatePending, threshold,
+		afterUserID, limit, staleBefore,
+	).Scan(&candidates).Error
+	return candidates, err
+}
+
+// GetMaxCounterUserID returns the highest user_id in follow_counters within the page after afterUserID.
+// Used to advance the scan cursor when a page yields no candidates.
+func (r *FollowRepository) GetMaxCounterUserID(afterUserID uint, limit int) (uint, error) {
+	var maxID uint
+	err := r.db.Raw(`
+		SELECT COALESCE(MAX(user_id), 0) FROM (
+			SELECT user_id FROM follow_counters
+			WHERE user_id > ?
+			ORDER BY user_id
+			LIMIT ?
+		) page
+	`, afterUserID, limit).Scan(&maxID).Error
+	return maxID, err
+}
+
 // ReconcileAllCounters recalculates counters for all users with counters
 func (r *FollowRepository) ReconcileAllCounters() (int64, error) {
 	var counters []models.FollowCounter
@@ -651,3 +1115,32 @@ func (r *FollowRepository) ReconcileAllCounters() (int64, error) {
 
 	return reconciled, nil
 }
+
+// FollowExportRow is a follow edge for data export. The other user is identified by ID
+// only, so the export carries no other user's profile data.
+type FollowExportRow struct {
+	UserID uint      `gorm:"column:user_id" json:"user_id"`
+	Since  time.Time `gorm:"column:since" json:"since"`
+}
+
+// GetFollowingForExport returns everyone a user actively follows, oldest first
+func (r *FollowRepository) GetFollowingForExport(followerID uint) ([]FollowExportRow, error) {
+	var rows []FollowExportRow
+	err := r.db.Model(&models.FollowEdgeByFollower{}).
+		Select("followee_id AS user_id, created_at AS since").
+		Where("follower_id = ? AND state = ?", followerID, models.FollowStateActive).
+		Order("created_at ASC").
+		Scan(&rows).Error
+	return rows, err
+}
+
+// GetFollowersForExport returns everyone actively following a user, oldest first
+func (r *FollowRepository) GetFollowersForExport(followeeID uint) ([]FollowExportRow, error) {
+	var rows []FollowExportRow
+	err := r.db.Model(&models.FollowEdgeByFollowee{}).
+		Select("follower_id AS user_id, created_at AS since").
+		Where("followee_id = ? AND state = ?", followeeID, models.FollowStateActive).
+		Order("created_at ASC").
+		Scan(&rows).Error
+	return rows, err
+}
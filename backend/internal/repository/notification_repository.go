@@ -2,6 +2,7 @@
 package repository
 
 import (
+	"strconv"
 	"time"
 
 	"github.com/aman1117/backend/pkg/models"
@@ -45,17 +46,52 @@ func (r *NotificationRepository) GetByID(id uint) (*models.Notification, error)
 }
 
 // GetByUserID retrieves notifications for a user with pagination
-// Returns notifications ordered by created_at DESC (newest first)
-func (r *NotificationRepository) GetByUserID(userID uint, limit, offset int) ([]models.Notification, error) {
+// Returns notifications ordered by created_at DESC (newest first).
+// When types is non-empty only notifications of those types are returned.
+func (r *NotificationRepository) GetByUserID(userID uint, types []models.NotificationType, limit, offset int) ([]models.Notification, error) {
 	var notifs []models.Notification
-	err := r.db.Where("user_id = ?", userID).
-		Order("created_at DESC").
+	err := r.byUserAndTypes(userID, types).
+		Order("created_at DESC, id DESC").
 		Limit(limit).
 		Offset(offset).
 		Find(&notifs).Error
 	return notifs, err
 }
 
+// StreamByUserID iterates all of a user's notifications (oldest first) from a cursor.
+// Iteration stops at the first error returned by fn.
+func (r *NotificationRepository) StreamByUserID(userID uint, fn func(models.Notification) error) error {
+	rows, err := r.db.Model(&models.Notification{}).
+		Where("user_id = ?", userID).
+		Order("created_at ASC").
+		Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var notif models.Notification
+		if err := r.db.ScanRows(rows, &notif); err != nil {
+			return err
+		}
+		if err := fn(notif); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+// GetAfterSeq retrieves a user's notifications with seq greater than afterSeq, oldest first
+func (r *NotificationRepository) GetAfterSeq(userID uint, afterSeq int64, limit int) ([]models.Notification, error) {
+	var notifs []models.Notification
+	err := r.db.Where("user_id = ? AND seq > ?", userID, afterSeq).
+		Order("seq ASC").
+		Limit(limit).
+		Find(&notifs).Error
+	return notifs, err
+}
+
 // GetUnreadByUserID retrieves unread notifications for a user
 func (r *NotificationRepository) GetUnreadByUserID(userID uint, limit int) ([]models.Notification, error) {
 	var notifs []models.Notification
@@ -84,6 +120,14 @@ func (r *NotificationRepository) MarkAsRead(id, userID uint) error {
 	return result.Error
 }
 
+// MarkAsUnread clears read_at on a single notification (must belong to user)
+func (r *NotificationRepository) MarkAsUnread(id, userID uint) (int64, error) {
+	result := r.db.Model(&models.Notification{}).
+		Where("id = ? AND user_id = ? AND read_at IS NOT NULL", id, userID).
+		Update("read_at", nil)
+	return result.RowsAffected, result.Error
+}
+
 // MarkAllAsRead marks all notifications as read for a user
 func (r *NotificationRepository) MarkAllAsRead(userID uint) error {
 	now := time.Now()
@@ -99,36 +143,74 @@ func (r *NotificationRepository) Delete(id, userID uint) error {
 	return result.Error
 }
 
-// DeleteOlderThan deletes notifications older than the specified time
+// DeleteOlderThan deletes expired notifications and returns the deleted count per type.
+// Types in retentionDaysByType are deleted once older than their retention, read or not.
+// All other types use the defaults:
 // readOlderThan: delete read notifications older than this
 // unreadOlderThan: delete unread notifications older than this
-func (r *NotificationRepository) DeleteOlderThan(readOlderThan, unreadOlderThan time.Time) (int64, error) {
-	// Delete read notifications older than readOlderThan
-	readResult := r.db.Where("read_at IS NOT NULL AND created_at < ?", readOlderThan).
-		Delete(&models.Notification{})
-	if readResult.Error != nil {
-		return 0, readResult.Error
+func (r *NotificationRepository) DeleteOlderThan(readOlderThan, unreadOlderThan time.Time, retentionDaysByType map[models.NotificationType]int) (map[models.NotificationType]int64, error) {
+	deleted := make(map[models.NotificationType]int64)
+	overridden := make([]models.NotificationType, 0, len(retentionDaysByType))
+
+	for notifType, days := range retentionDaysByType {
+		overridden = append(overridden, notifType)
+		cutoff := time.Now().AddDate(0, 0, -days)
+		if err := r.deleteCountingTypes(deleted, "type = ? AND created_at < ?", notifType, cutoff); err != nil {
+			return deleted, err
+		}
 	}
 
-	// Delete unread notifications older than unreadOlderThan
-	unreadResult := r.db.Where("read_at IS NULL AND created_at < ?", unreadOlderThan).
-		Delete(&models.Notification{})
-	if unreadResult.Error != nil {
-		return readResult.RowsAffected, unreadResult.Error
+	where := "((read_at IS NOT NULL AND created_at < ?) OR (read_at IS NULL AND created_at < ?))"
+	args := []interface{}{readOlderThan, unreadOlderThan}
+	if len(overridden) > 0 {
+		where += " AND type NOT IN ?"
+		args = append(args, overridden)
+	}
+	if err := r.deleteCountingTypes(deleted, where, args...); err != nil {
+		return deleted, err
 	}
 
-	return readResult.RowsAffected + unreadResult.RowsAffected, nil
+	return deleted, nil
 }
 
-// CountByUserID returns total notification count for a user
-func (r *NotificationRepository) CountByUserID(userID uint) (int64, error) {
+// deleteCountingTypes deletes matching notifications and adds the per-type counts to deleted
+func (r *NotificationRepository) deleteCountingTypes(deleted map[models.NotificationType]int64, where string, args ...interface{}) error {
+	var rows []struct {
+		Type    models.NotificationType
+		Deleted int64
+	}
+	err := r.db.Raw(`
+		WITH removed AS (
+			DELETE FROM notifications WHERE `+where+` RETURNING type
+		)
+		SELECT type, COUNT(*) AS deleted FROM removed GROUP BY type
+	`, args...).Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+	for _, row := range rows {
+		deleted[row.Type] += row.Deleted
+	}
+	return nil
+}
+
+// CountByUserID returns total notification count for a user, optionally limited to types
+func (r *NotificationRepository) CountByUserID(userID uint, types []models.NotificationType) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Notification{}).
-		Where("user_id = ?", userID).
+	err := r.byUserAndTypes(userID, types).
 		Count(&count).Error
 	return count, err
 }
 
+// byUserAndTypes scopes a notification query to a user and, if given, a set of types
+func (r *NotificationRepository) byUserAndTypes(userID uint, types []models.NotificationType) *gorm.DB {
+	q := r.db.Model(&models.Notification{}).Where("user_id = ?", userID)
+	if len(types) > 0 {
+		q = q.Where("type IN ?", types)
+	}
+	return q
+}
+
 // ExistsByTypeAndMetadata checks if a notification with given type and metadata exists
 // Useful for deduplication (e.g., don't send duplicate like notifications)
 func (r *NotificationRepository) ExistsByTypeAndMetadata(
@@ -149,6 +231,25 @@ func (r *NotificationRepository) ExistsByTypeAndMetadata(
 	return count > 0, err
 }
 
+// DeleteDayCompleted removes the day-completed notifications sent to followers for a
+// user's date and returns the recipients whose deleted notification was still unread
+func (r *NotificationRepository) DeleteDayCompleted(completedUserID uint, completedDate string) ([]uint, error) {
+	var unreadRecipients []uint
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		query := func() *gorm.DB {
+			return tx.Model(&models.Notification{}).
+				Where("type = ?", models.NotifTypeStreakMilestone).
+				Where("metadata->>'completed_user_id' = ? AND metadata->>'completed_date' = ?",
+					strconv.FormatUint(uint64(completedUserID), 10), completedDate)
+		}
+		if err := query().Where("read_at IS NULL").Pluck("user_id", &unreadRecipients).Error; err != nil {
+			return err
+		}
+		return query().Delete(&models.Notification{}).Error
+	})
+	return unreadRecipients, err
+}
+
 // CreateDedupeRecord attempts to insert a dedupe record using ON CONFLICT DO NOTHING.
 // Returns (true, nil) if a new record was created, (false, nil) if already exists,
 // or (false, error) on database error.
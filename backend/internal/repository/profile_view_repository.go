@@ -0,0 +1,77 @@
+// Package repository provides data access layer for profile views.
+package repository
+
+import (
+	"time"
+
+	"github.com/aman1117/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+// ProfileViewRepository handles profile view data operations
+type ProfileViewRepository struct {
+	db *gorm.DB
+}
+
+// NewProfileViewRepository creates a new ProfileViewRepository
+func NewProfileViewRepository(db *gorm.DB) *ProfileViewRepository {
+	return &ProfileViewRepository{db: db}
+}
+
+// RecordView upserts a profile view, refreshing viewed_at only if the last recorded
+// view is older than throttle
+func (r *ProfileViewRepository) RecordView(viewerID, targetID uint, throttle time.Duration) error {
+	return r.db.Exec(`
+		INSERT INTO profile_views (viewer_id, target_id, viewed_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (viewer_id, target_id) DO UPDATE
+		SET viewed_at = NOW()
+		WHERE profile_views.viewed_at < NOW() - make_interval(secs => $3)
+	`, viewerID, targetID, throttle.Seconds()).Error
+}
+
+// profileViewersFilter keeps viewers who are active, have not since hidden their
+// profile views, and are not blocked in either direction with the profile owner ($1)
+const profileViewersFilter = `
+	pv.target_id = $1
+	AND u.is_deactivated = false
+	AND u.hide_profile_views = false
+	AND NOT EXISTS (
+		SELECT 1 FROM user_blocks b
+		WHERE (b.blocker_id = $1 AND b.blocked_id = u.id)
+			OR (b.blocker_id = u.id AND b.blocked_id = $1)
+	)`
+
+// GetViewers returns a page of the users who viewed targetID's profile, most recent first,
+// along with the total number of visible viewers
+func (r *ProfileViewRepository) GetViewers(targetID uint, limit, offset int) ([]models.ProfileViewer, int64, error) {
+	var total int64
+	if err := r.db.Raw(`
+		SELECT COUNT(*)
+		FROM profile_views pv
+		INNER JOIN users u ON pv.viewer_id = u.id
+		WHERE `+profileViewersFilter, targetID).Scan(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var viewers []models.ProfileViewer
+	err := r.db.Raw(`
+		SELECT
+			u.id as user_id,
+			u.username,
+			u.profile_pic,
+			u.profile_pic_thumb,
+			u.is_verified,
+			pv.viewed_at
+		FROM profile_views pv
+		INNER JOIN users u ON pv.viewer_id = u.id
+		WHERE `+profileViewersFilter+`
+		ORDER BY pv.viewed_at DESC, pv.id DESC
+		LIMIT $2 OFFSET $3
+	`, targetID, limit, offset).Scan(&viewers).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return viewers, total, nil
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/aman1117/backend/pkg/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 var (
@@ -171,6 +172,20 @@ func (r *PushRepository) GetOrCreatePreference(userID uint) (*models.PushPrefere
 	return &pref, nil
 }
 
+// GetPreference retrieves preferences for a user without creating defaults.
+// Returns nil if the user never saved preferences.
+func (r *PushRepository) GetPreference(userID uint) (*models.PushPreference, error) {
+	var pref models.PushPreference
+	err := r.db.Where("user_id = ?", userID).First(&pref).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	return &pref, nil
+}
+
 // UpdatePreference updates push preferences for a user
 func (r *PushRepository) UpdatePreference(pref *models.PushPreference) error {
 	return r.db.Save(pref).Error
@@ -190,6 +205,19 @@ func (r *PushRepository) CreateDeliveryLog(log *models.PushDeliveryLog) error {
 	return r.db.Create(log).Error
 }
 
+// CreateDeliveryLogsBatch inserts delivery log entries in multi-row inserts
+func (r *PushRepository) CreateDeliveryLogsBatch(logs []*models.PushDeliveryLog) error {
+	if len(logs) == 0 {
+		return nil
+	}
+	return r.db.CreateInBatches(logs, 100).Error
+}
+
+// CreateDeadLetter records a push message the worker stopped retrying
+func (r *PushRepository) CreateDeadLetter(deadLetter *models.PushDeadLetter) error {
+	return r.db.Create(deadLetter).Error
+}
+
 // CheckIdempotency checks if a message has already been delivered to a subscription
 func (r *PushRepository) CheckIdempotency(messageID string, subscriptionID uint) (bool, error) {
 	var count int64
@@ -199,6 +227,34 @@ func (r *PushRepository) CheckIdempotency(messageID string, subscriptionID uint)
 	return count > 0, err
 }
 
+// ClaimSend records that messageID is about to be sent to a subscription.
+// Returns false if a claim already exists, i.e. an earlier attempt sent it or
+// crashed while sending.
+func (r *PushRepository) ClaimSend(messageID string, subscriptionID, userID uint) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&models.PushSendClaim{
+		MessageID:      messageID,
+		SubscriptionID: subscriptionID,
+		UserID:         userID,
+	})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected > 0, nil
+}
+
+// ReleaseSendClaim removes a claim so a retry can send again
+func (r *PushRepository) ReleaseSendClaim(messageID string, subscriptionID uint) error {
+	return r.db.Where("message_id = ? AND subscription_id = ?", messageID, subscriptionID).
+		Delete(&models.PushSendClaim{}).Error
+}
+
+// CleanupOldSendClaims deletes claims older than any message could still be retried
+func (r *PushRepository) CleanupOldSendClaims(daysOld int) (int64, error) {
+	cutoff := time.Now().AddDate(0, 0, -daysOld)
+	result := r.db.Where("created_at < ?", cutoff).Delete(&models.PushSendClaim{})
+	return result.RowsAffected, result.Error
+}
+
 // CheckDedupeWindow checks if a similar notification was sent recently
 func (r *PushRepository) CheckDedupeWindow(userID uint, dedupeKey string, windowSeconds int) (bool, error) {
 	if dedupeKey == "" {
@@ -213,6 +269,34 @@ func (r *PushRepository) CheckDedupeWindow(userID uint, dedupeKey string, window
 	return count > 0, err
 }
 
+// PushDeliveryStats summarises a user's delivery attempts by outcome
+type PushDeliveryStats struct {
+	Delivered       int64      // 2xx
+	Gone            int64      // 404/410 - subscription expired or revoked
+	RateLimited     int64      // 429
+	Failed          int64      // Network errors, 5xx and other 4xx
+	LastDeliveredAt *time.Time // Most recent 2xx, nil if none in the window
+}
+
+// GetDeliveryStats counts a user's delivery attempts by outcome since the given time
+func (r *PushRepository) GetDeliveryStats(userID uint, since time.Time) (*PushDeliveryStats, error) {
+	var stats PushDeliveryStats
+	err := r.db.Raw(`
+		SELECT
+			COUNT(*) FILTER (WHERE status_code >= 200 AND status_code < 300) AS delivered,
+			COUNT(*) FILTER (WHERE status_code IN (404, 410)) AS gone,
+			COUNT(*) FILTER (WHERE status_code = 429) AS rate_limited,
+			COUNT(*) FILTER (WHERE status_code < 200 OR (status_code >= 300 AND status_code NOT IN (404, 410, 429))) AS failed,
+			MAX(created_at) FILTER (WHERE status_code >= 200 AND status_code < 300) AS last_delivered_at
+		FROM push_delivery_logs
+		WHERE user_id = ? AND created_at >= ?
+	`, userID, since).Scan(&stats).Error
+	if err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
 // CleanupOldDeliveryLogs deletes old delivery logs
 func (r *PushRepository) CleanupOldDeliveryLogs(daysOld int) (int64, error) {
 	cutoff := time.Now().AddDate(0, 0, -daysOld)
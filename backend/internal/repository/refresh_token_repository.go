@@ -0,0 +1,72 @@
+// Package repository provides data access layer for refresh tokens.
+package repository
+
+import (
+	"errors"
+	"time"
+
+	"github.com/aman1117/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+// ErrRefreshTokenUsed is returned when a refresh token was revoked before it could be rotated
+var ErrRefreshTokenUsed = errors.New("refresh token already used")
+
+// RefreshTokenRepository handles refresh token data operations
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository creates a new RefreshTokenRepository
+func NewRefreshTokenRepository(db *gorm.DB) *RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create stores a new refresh token
+func (r *RefreshTokenRepository) Create(token *models.RefreshToken) error {
+	return r.db.Create(token).Error
+}
+
+// FindByHash finds a refresh token by its hash, or nil if none matches
+func (r *RefreshTokenRepository) FindByHash(tokenHash string) (*models.RefreshToken, error) {
+	var token models.RefreshToken
+	result := r.db.Where("token_hash = ?", tokenHash).Limit(1).Find(&token)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &token, nil
+}
+
+// Rotate revokes oldID and stores next in one transaction. Returns ErrRefreshTokenUsed if
+// oldID was already revoked, so two concurrent refreshes can't both succeed.
+func (r *RefreshTokenRepository) Rotate(oldID uint, next *models.RefreshToken) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		res := tx.Model(&models.RefreshToken{}).
+			Where("id = ? AND revoked_at IS NULL", oldID).
+			Update("revoked_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrRefreshTokenUsed
+		}
+		return tx.Create(next).Error
+	})
+}
+
+// RevokeAllForUser revokes every outstanding refresh token of a user
+func (r *RefreshTokenRepository) RevokeAllForUser(userID uint) error {
+	return r.db.Model(&models.RefreshToken{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired removes tokens that expired before cutoff. Revoked tokens are kept until
+// they expire so reuse of a rotated token can still be detected.
+func (r *RefreshTokenRepository) DeleteExpired(cutoff time.Time) (int64, error) {
+	res := r.db.Where("expires_at < ?", cutoff).Delete(&models.RefreshToken{})
+	return res.RowsAffected, res.Error
+}
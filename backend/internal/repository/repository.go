@@ -2,12 +2,16 @@
 package repository
 
 import (
+	"database/sql"
+	"encoding/json"
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 
 	"github.com/aman1117/backend/pkg/models"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 // UserRepository handles user data operations
@@ -111,10 +115,80 @@ func (r *UserRepository) FindByEmail(email string) (*models.User, error) {
 	return &user, nil
 }
 
-// UpdateUsername updates a user's username
-func (r *UserRepository) UpdateUsername(userID uint, newUsername string) error {
-	result := r.db.Model(&models.User{}).Where("id = ?", userID).Update("username", newUsername)
-	return result.Error
+// ErrUsernameReserved is returned when a username was recently given up by another user
+var ErrUsernameReserved = errors.New("username is reserved")
+
+// UsernameCooldownError is returned by ChangeUsername when the last change is too recent
+type UsernameCooldownError struct {
+	NextAllowedAt time.Time
+}
+
+func (e *UsernameCooldownError) Error() string {
+	return fmt.Sprintf("username can be changed again at %s", e.NextAllowedAt.Format(time.RFC3339))
+}
+
+// ChangeUsername renames a user and records the old name in username_history, reserved
+// for them until now+releaseGrace. Returns *UsernameCooldownError if the previous change
+// was less than cooldown ago, and ErrUsernameReserved if another user gave the name up
+// recently. The user row is locked so concurrent renames can't bypass the cooldown.
+func (r *UserRepository) ChangeUsername(userID uint, newUsername string, cooldown, releaseGrace time.Duration) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Select("id", "username", "username_changed_at").
+			First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		now := time.Now()
+		if user.UsernameChangedAt != nil && now.Before(user.UsernameChangedAt.Add(cooldown)) {
+			return &UsernameCooldownError{NextAllowedAt: user.UsernameChangedAt.Add(cooldown)}
+		}
+		if user.Username == newUsername {
+			return nil
+		}
+
+		var reserved int64
+		if err := tx.Model(&models.UsernameHistory{}).
+			Where("username = ? AND user_id <> ? AND released_at > ?", newUsername, userID, now).
+			Count(&reserved).Error; err != nil {
+			return err
+		}
+		if reserved > 0 {
+			return ErrUsernameReserved
+		}
+
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"username":            newUsername,
+				"username_changed_at": now,
+			}).Error; err != nil {
+			return err
+		}
+
+		// Reclaiming one of your own old names ends its reservation
+		if err := tx.Where("user_id = ? AND username = ?", userID, newUsername).
+			Delete(&models.UsernameHistory{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Create(&models.UsernameHistory{
+			UserID:     userID,
+			Username:   user.Username,
+			ChangedAt:  now,
+			ReleasedAt: now.Add(releaseGrace),
+		}).Error
+	})
+}
+
+// IsUsernameReserved reports whether a username was given up recently and is still held
+// for its previous owner
+func (r *UserRepository) IsUsernameReserved(username string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.UsernameHistory{}).
+		Where("username = ? AND released_at > ?", username, time.Now()).
+		Count(&count).Error
+	return count > 0, err
 }
 
 // UpdatePassword updates a user's password hash
@@ -138,6 +212,89 @@ func (r *UserRepository) GetPrivacy(userID uint) (bool, error) {
 	return user.IsPrivate, nil
 }
 
+// UpdateTimezone updates a user's timezone
+func (r *UserRepository) UpdateTimezone(userID uint, timezone string) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", userID).Update("timezone", timezone)
+	return result.Error
+}
+
+// GetTimezone gets a user's timezone
+func (r *UserRepository) GetTimezone(userID uint) (string, error) {
+	var timezone string
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Select("timezone").Scan(&timezone).Error
+	return timezone, err
+}
+
+// UpdateGhostMode updates a user's story ghost mode setting
+func (r *UserRepository) UpdateGhostMode(userID uint, enabled bool) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", userID).Update("ghost_mode", enabled)
+	return result.Error
+}
+
+// GetGhostMode gets a user's story ghost mode setting
+func (r *UserRepository) GetGhostMode(userID uint) (bool, error) {
+	var enabled bool
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Select("ghost_mode").Scan(&enabled).Error
+	return enabled, err
+}
+
+// UpdateWeeklyDigestOptOut updates whether a user receives the weekly digest email
+func (r *UserRepository) UpdateWeeklyDigestOptOut(userID uint, optOut bool) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", userID).Update("weekly_digest_opt_out", optOut)
+	return result.Error
+}
+
+// GetWeeklyDigestOptOut gets whether a user opted out of the weekly digest email
+func (r *UserRepository) GetWeeklyDigestOptOut(userID uint) (bool, error) {
+	var optOut bool
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Select("weekly_digest_opt_out").Scan(&optOut).Error
+	return optOut, err
+}
+
+// UpdateHideProfileViews updates whether a user's profile views are hidden from others
+func (r *UserRepository) UpdateHideProfileViews(userID uint, hidden bool) error {
+	result := r.db.Model(&models.User{}).Where("id = ?", userID).Update("hide_profile_views", hidden)
+	return result.Error
+}
+
+// GetHideProfileViews gets whether a user's profile views are hidden from others
+func (r *UserRepository) GetHideProfileViews(userID uint) (bool, error) {
+	var hidden bool
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Select("hide_profile_views").Scan(&hidden).Error
+	return hidden, err
+}
+
+// GetTokenVersion returns the user's current token version
+func (r *UserRepository) GetTokenVersion(userID uint) (int, error) {
+	var version int
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Select("token_version").Scan(&version).Error
+	return version, err
+}
+
+// IncrementTokenVersion bumps the user's token version, invalidating all issued access tokens,
+// and returns the new version
+func (r *UserRepository) IncrementTokenVersion(userID uint) (int, error) {
+	var user models.User
+	err := r.db.Model(&user).Clauses(clause.Returning{Columns: []clause.Column{{Name: "token_version"}}}).
+		Where("id = ?", userID).
+		UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error
+	return user.TokenVersion, err
+}
+
+// IsAdmin reports whether the user has the admin flag (false if the user does not exist)
+func (r *UserRepository) IsAdmin(userID uint) (bool, error) {
+	var isAdmin bool
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Select("is_admin").Scan(&isAdmin).Error
+	return isAdmin, err
+}
+
+// IsEmailVerified reports whether the user has verified their email address
+func (r *UserRepository) IsEmailVerified(userID uint) (bool, error) {
+	var verified bool
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Select("email_verified").Scan(&verified).Error
+	return verified, err
+}
+
 // UpdateBio updates a user's bio
 func (r *UserRepository) UpdateBio(userID uint, bio string) error {
 	var bioPtr *string
@@ -166,16 +323,61 @@ func (r *UserRepository) UpdateProfilePic(userID uint, url *string, thumbURL *st
 	return result.Error
 }
 
+// ErrEmailTaken is returned when an email address already belongs to another account
+var ErrEmailTaken = errors.New("email already in use")
+
+// UpdateEmail replaces a user's email address and marks it verified.
+// Returns ErrEmailTaken if the address was claimed by another account in the meantime.
+func (r *UserRepository) UpdateEmail(userID uint, email string) error {
+	err := r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"email":          email,
+		"email_verified": true,
+	}).Error
+	if err != nil && (strings.Contains(err.Error(), "23505") || strings.Contains(err.Error(), "duplicate")) {
+		return ErrEmailTaken
+	}
+	return err
+}
+
 // UpdateEmailVerified updates a user's email verification status
 func (r *UserRepository) UpdateEmailVerified(userID uint, verified bool) error {
 	result := r.db.Model(&models.User{}).Where("id = ?", userID).Update("email_verified", verified)
 	return result.Error
 }
 
-// SearchByUsername searches for users by username (case-insensitive, includes all users)
-func (r *UserRepository) SearchByUsername(query string) ([]models.User, error) {
+// UpdateDeactivated marks a user as deactivated (hidden from others) or reactivates them
+func (r *UserRepository) UpdateDeactivated(userID uint, deactivated bool) error {
+	var deactivatedAt *time.Time
+	if deactivated {
+		now := time.Now()
+		deactivatedAt = &now
+	}
+	result := r.db.Model(&models.User{}).Where("id = ?", userID).Updates(map[string]interface{}{
+		"is_deactivated": deactivated,
+		"deactivated_at": deactivatedAt,
+	})
+	return result.Error
+}
+
+// IsDeactivated reports whether a user has deactivated their account
+func (r *UserRepository) IsDeactivated(userID uint) (bool, error) {
+	var deactivated bool
+	err := r.db.Model(&models.User{}).Select("is_deactivated").Where("id = ?", userID).Scan(&deactivated).Error
+	return deactivated, err
+}
+
+// SearchByUsername searches for users by username (case-insensitive, includes private
+// users, excludes deactivated ones), most-followed first, then by username
+func (r *UserRepository) SearchByUsername(query string, limit, offset int) ([]models.User, error) {
 	var users []models.User
-	result := r.db.Where("username ILIKE ?", "%"+query+"%").Find(&users)
+	result := r.db.Model(&models.User{}).
+		Select("users.*").
+		Joins("LEFT JOIN follow_counters fc ON fc.user_id = users.id").
+		Where("users.username ILIKE ? AND users.is_deactivated = false", "%"+escapeLike(query)+"%").
+		Order("COALESCE(fc.followers_count, 0) DESC, users.username ASC").
+		Limit(limit).
+		Offset(offset).
+		Find(&users)
 	return users, result.Error
 }
 
@@ -194,20 +396,14 @@ type AutocompleteResult struct {
 // AutocompleteUsers performs ranked autocomplete search on usernames
 // Ranking: exact match > prefix match > trigram similarity, then by followers_count DESC, username ASC
 // Uses pg_trgm extension for fuzzy matching
-func (r *UserRepository) AutocompleteUsers(query string, limit int) ([]AutocompleteResult, error) {
+// Users who blocked viewerID, or whom viewerID blocked, are excluded
+func (r *UserRepository) AutocompleteUsers(viewerID uint, query string, limit int) ([]AutocompleteResult, error) {
 	if limit <= 0 {
 		limit = 12
 	}
-	if limit > 20 {
-		limit = 20
-	}
 
 	// Escape LIKE special characters (%, _, \) to prevent wildcard injection
-	escapedQuery := strings.NewReplacer(
-		`\`, `\\`,
-		`%`, `\%`,
-		`_`, `\_`,
-	).Replace(query)
+	escapedQuery := escapeLike(query)
 
 	var results []AutocompleteResult
 
@@ -217,6 +413,8 @@ func (r *UserRepository) AutocompleteUsers(query string, limit int) ([]Autocompl
 	// - CASE 3: Trigram similarity > 0.15 (score = similarity * 30)
 	// - ORDER BY: score DESC, followers_count DESC, username ASC
 	// - LEFT JOIN follow_counters to get follower count (default 0 if not found)
+	// - Deactivated accounts never appear
+	// - NOT EXISTS drops users blocked in either direction with the viewer ($4)
 	// Note: $1 is the original query (for exact match and similarity), $3 is escaped (for LIKE)
 	err := r.db.Raw(`
 		SELECT 
@@ -236,15 +434,23 @@ func (r *UserRepository) AutocompleteUsers(query string, limit int) ([]Autocompl
 		FROM users u
 		LEFT JOIN follow_counters fc ON fc.user_id = u.id
 		WHERE 
-			lower(u.username) = lower($1)
-			OR lower(u.username) LIKE lower($3) || '%' ESCAPE '\'
-			OR similarity(u.username, $1) > 0.15
+			u.is_deactivated = false
+			AND NOT EXISTS (
+				SELECT 1 FROM user_blocks b
+				WHERE (b.blocker_id = $4 AND b.blocked_id = u.id)
+					OR (b.blocker_id = u.id AND b.blocked_id = $4)
+			)
+			AND (
+				lower(u.username) = lower($1)
+				OR lower(u.username) LIKE lower($3) || '%' ESCAPE '\'
+				OR similarity(u.username, $1) > 0.15
+			)
 		ORDER BY 
 			score DESC,
 			followers_count DESC,
 			u.username ASC
 		LIMIT $2
-	`, query, limit, escapedQuery).Scan(&results).Error
+	`, query, limit, escapedQuery, viewerID).Scan(&results).Error
 
 	if err != nil {
 		return nil, err
@@ -253,6 +459,15 @@ func (r *UserRepository) AutocompleteUsers(query string, limit int) ([]Autocompl
 	return results, nil
 }
 
+// escapeLike escapes LIKE special characters (%, _, \) so user input matches literally
+func escapeLike(s string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		`%`, `\%`,
+		`_`, `\_`,
+	).Replace(s)
+}
+
 // GetAll returns all users
 func (r *UserRepository) GetAll() ([]models.User, error) {
 	var users []models.User
@@ -260,6 +475,146 @@ func (r *UserRepository) GetAll() ([]models.User, error) {
 	return users, result.Error
 }
 
+// AccountDeletionResult reports what DeleteAccount removed
+type AccountDeletionResult struct {
+	// RowsDeleted maps table name to the number of rows removed from it
+	RowsDeleted map[string]int64
+	// BlobURLs are the profile picture and story photo URLs that must be removed from storage
+	BlobURLs []string
+	// CountersAdjusted is the number of other users whose follow counters were decremented
+	CountersAdjusted int64
+}
+
+// accountCleanupStep is a single DELETE in the account deletion cascade
+type accountCleanupStep struct {
+	table string
+	where string
+}
+
+// accountCleanupSteps are ordered so child rows go before the rows they reference.
+// Each where clause refers to the deleted user as @id.
+var accountCleanupSteps = []accountCleanupStep{
+	{"story_views", "viewer_id = @id OR photo_id IN (SELECT id FROM activity_photos WHERE user_id = @id)"},
+	{"profile_views", "viewer_id = @id OR target_id = @id"},
+	{"story_likes", "liker_id = @id OR photo_id IN (SELECT id FROM activity_photos WHERE user_id = @id)"},
+	{"story_replies", "sender_id = @id OR photo_id IN (SELECT id FROM activity_photos WHERE user_id = @id)"},
+	{"activity_photos", "user_id = @id"},
+	{"comment_likes", "user_id = @id OR comment_id IN (SELECT id FROM comments WHERE author_id = @id OR day_owner_id = @id)"},
+	{"comment_mentions", "mentioned_user_id = @id OR comment_id IN (SELECT id FROM comments WHERE author_id = @id OR day_owner_id = @id)"},
+	{"comment_dedupes", "user_id = @id OR day_owner_id = @id"},
+	{"comments", "author_id = @id OR day_owner_id = @id"},
+	{"likes", "liker_id = @id OR liked_user_id = @id"},
+	{"activities", "user_id = @id"},
+	{"streaks", "user_id = @id"},
+	{"streak_freezes", "user_id = @id"},
+	{"user_badges", "user_id = @id"},
+	{"notifications", "user_id = @id"},
+	{"notification_dedupes", "user_id = @id OR actor_id = @id"},
+	{"notification_sequences", "user_id = @id"},
+	{"push_delivery_logs", "user_id = @id"},
+	{"push_dead_letters", "user_id = @id"},
+	{"push_send_claims", "user_id = @id"},
+	{"push_subscriptions", "user_id = @id"},
+	{"push_preferences", "user_id = @id"},
+	{"follow_edges_by_follower", "follower_id = @id OR followee_id = @id"},
+	{"follow_edges_by_followee", "follower_id = @id OR followee_id = @id"},
+	{"follow_counters", "user_id = @id"},
+	{"user_blocks", "blocker_id = @id OR blocked_id = @id"},
+	{"close_friends", "owner_id = @id OR friend_id = @id"},
+	{"custom_activity_types", "user_id = @id"},
+	{"tile_configs", "user_id = @id"},
+	{"recent_searches", "user_id = @id OR searched_user_id = @id"},
+	{"verification_requests", "user_id = @id"},
+	{"refresh_tokens", "user_id = @id"},
+	{"sessions", "user_id = @id"},
+	{"two_factor_backup_codes", "user_id = @id"},
+	{"username_history", "user_id = @id"},
+	{"users", "id = @id"},
+}
+
+// DeleteAccount permanently removes a user and every row that references them in a
+// single transaction. Follow counters of the other side of each edge are decremented
+// first so nobody is left counting a deleted account, and like counts on comments the
+// user liked are rolled back. Blob URLs are collected before the rows go away so the
+// caller can clean up storage once the transaction has committed.
+func (r *UserRepository) DeleteAccount(userID uint) (*AccountDeletionResult, error) {
+	result := &AccountDeletionResult{RowsDeleted: make(map[string]int64)}
+
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("id = ?", userID).First(&user).Error; err != nil {
+			return err
+		}
+		if user.ProfilePic != nil && *user.ProfilePic != "" {
+			result.BlobURLs = append(result.BlobURLs, *user.ProfilePic)
+		}
+		if user.ProfilePicThumb != nil && *user.ProfilePicThumb != "" {
+			result.BlobURLs = append(result.BlobURLs, *user.ProfilePicThumb)
+		}
+
+		var photos []models.ActivityPhoto
+		if err := tx.Select("photo_url", "thumbnail_url").Where("user_id = ?", userID).Find(&photos).Error; err != nil {
+			return err
+		}
+		for _, p := range photos {
+			result.BlobURLs = append(result.BlobURLs, p.PhotoURL, p.ThumbnailURL)
+		}
+
+		// People this user followed lose a follower (or a pending request)
+		res := tx.Exec(`
+			UPDATE follow_counters fc
+			SET followers_count = GREATEST(fc.followers_count - CASE WHEN e.state = ? THEN 1 ELSE 0 END, 0),
+			    pending_requests_count = GREATEST(fc.pending_requests_count - CASE WHEN e.state = ? THEN 1 ELSE 0 END, 0),
+			    updated_at = NOW()
+			FROM follow_edges_by_follower e
+			WHERE e.follower_id = ? AND e.state IN (?, ?) AND fc.user_id = e.followee_id
+		`, models.FollowStateActive, models.FollowStatePending, userID, models.FollowStateActive, models.FollowStatePending)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.CountersAdjusted += res.RowsAffected
+
+		// People following this user lose a following
+		res = tx.Exec(`
+			UPDATE follow_counters fc
+			SET following_count = GREATEST(fc.following_count - 1, 0),
+			    updated_at = NOW()
+			FROM follow_edges_by_followee e
+			WHERE e.followee_id = ? AND e.state = ? AND fc.user_id = e.follower_id
+		`, userID, models.FollowStateActive)
+		if res.Error != nil {
+			return res.Error
+		}
+		result.CountersAdjusted += res.RowsAffected
+
+		// Comments on other people's days lose this user's likes
+		if err := tx.Exec(`
+			UPDATE comments c
+			SET like_count = GREATEST(c.like_count - 1, 0)
+			FROM comment_likes cl
+			WHERE cl.user_id = ? AND cl.comment_id = c.id
+			  AND c.author_id <> ? AND c.day_owner_id <> ?
+		`, userID, userID, userID).Error; err != nil {
+			return err
+		}
+
+		for _, step := range accountCleanupSteps {
+			res := tx.Exec("DELETE FROM "+step.table+" WHERE "+step.where, sql.Named("id", userID))
+			if res.Error != nil {
+				return fmt.Errorf("delete from %s: %w", step.table, res.Error)
+			}
+			if res.RowsAffected > 0 {
+				result.RowsDeleted[step.table] = res.RowsAffected
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
 // ==================== Activity Repository ====================
 
 // ActivityRepository handles activity data operations
@@ -299,9 +654,282 @@ func (r *ActivityRepository) FindByUserAndDateRange(userID uint, startDate, endD
 	return activities, result.Error
 }
 
+// FindUnarchivedByUserAndDateRange finds a user's activities within a date range, leaving
+// out those archived with a deleted custom tile
+func (r *ActivityRepository) FindUnarchivedByUserAndDateRange(userID uint, startDate, endDate time.Time) ([]models.Activity, error) {
+	var activities []models.Activity
+	result := r.db.Where(
+		"user_id = ? AND activity_date BETWEEN ? AND ? AND archived_at IS NULL",
+		userID, startDate, endDate,
+	).Find(&activities)
+	return activities, result.Error
+}
+
+// StreamByUser iterates a user's activities ordered by date without loading them all into memory.
+// Nil bounds are open-ended. Iteration stops at the first error returned by fn.
+func (r *ActivityRepository) StreamByUser(userID uint, startDate, endDate *time.Time, fn func(models.Activity) error) error {
+	query := r.db.Model(&models.Activity{}).Where("user_id = ?", userID)
+	if startDate != nil {
+		query = query.Where("activity_date >= ?", *startDate)
+	}
+	if endDate != nil {
+		query = query.Where("activity_date <= ?", *endDate)
+	}
+
+	rows, err := query.Order("activity_date ASC, name ASC").Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var activity models.Activity
+		if err := r.db.ScanRows(rows, &activity); err != nil {
+			return err
+		}
+		if err := fn(activity); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+var (
+	// ErrDailyHoursExceeded is returned when a write would push a day's total past the daily limit
+	ErrDailyHoursExceeded = errors.New("total hours cannot be more than 24")
+	// ErrActivityNotFound is returned when deleting an activity the user has not logged
+	ErrActivityNotFound = errors.New("activity not found")
+)
+
+// ActivityEntry is one activity to write for a day
+type ActivityEntry struct {
+	Name  models.ActivityName
+	Hours float32
+	Note  *string
+}
+
+// UpsertForDay creates or updates the user's activity called name on date, rejecting the
+// write with ErrDailyHoursExceeded if the day's total would exceed maxHours. Returns the
+// day's total before and after the write.
+func (r *ActivityRepository) UpsertForDay(userID uint, date time.Time, name models.ActivityName, hours float32, note *string, maxHours float32) (previousTotal, newTotal float32, err error) {
+	previousTotal, newTotal, _, err = r.UpsertManyForDay(userID, date, []ActivityEntry{{Name: name, Hours: hours, Note: note}}, maxHours)
+	return previousTotal, newTotal, err
+}
+
+// UpsertManyForDay creates or updates several of the user's activities on date in one
+// transaction. Entries must have distinct names. The combined day total is checked once
+// and nothing is written if it would exceed maxHours (ErrDailyHoursExceeded).
+// Returns the day's total before and after the write and the saved activities.
+// The user's row is locked for the duration of the transaction so concurrent writes for the
+// same user are serialised; locking the day's activity rows alone would not stop two first
+// inserts of the day from both passing the check.
+func (r *ActivityRepository) UpsertManyForDay(userID uint, date time.Time, entries []ActivityEntry, maxHours float32) (previousTotal, newTotal float32, saved []models.Activity, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUserForActivityWrite(tx, userID); err != nil {
+			return err
+		}
+
+		var dayActivities []models.Activity
+		if err := tx.Where("user_id = ? AND activity_date = ?", userID, date).Find(&dayActivities).Error; err != nil {
+			return err
+		}
+
+		existing := make(map[models.ActivityName]*models.Activity, len(dayActivities))
+		for i := range dayActivities {
+			a := &dayActivities[i]
+			previousTotal += a.DurationHours
+			existing[a.Name] = a
+		}
+
+		newTotal = previousTotal
+		for _, e := range entries {
+			if a, ok := existing[e.Name]; ok {
+				newTotal -= a.DurationHours
+			}
+			newTotal += e.Hours
+		}
+		if newTotal > maxHours {
+			return ErrDailyHoursExceeded
+		}
+
+		saved = make([]models.Activity, 0, len(entries))
+		for _, e := range entries {
+			if a, ok := existing[e.Name]; ok {
+				a.DurationHours = e.Hours
+				a.Note = e.Note
+				if err := tx.Save(a).Error; err != nil {
+					return err
+				}
+				saved = append(saved, *a)
+				continue
+			}
+			a := models.Activity{
+				UserID:        userID,
+				Name:          e.Name,
+				DurationHours: e.Hours,
+				ActivityDate:  date,
+				Note:          e.Note,
+			}
+			if err := tx.Create(&a).Error; err != nil {
+				return err
+			}
+			saved = append(saved, a)
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, 0, nil, err
+	}
+	return previousTotal, newTotal, saved, nil
+}
+
+// DeleteForDay deletes the user's activity called name on date, under the same lock as
+// UpsertManyForDay. Returns ErrActivityNotFound if there is no such activity; otherwise
+// the day's total before and after the delete and how many activities remain that day.
+func (r *ActivityRepository) DeleteForDay(userID uint, date time.Time, name models.ActivityName) (previousTotal, newTotal float32, remaining int, err error) {
+	err = r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUserForActivityWrite(tx, userID); err != nil {
+			return err
+		}
+
+		var dayActivities []models.Activity
+		if err := tx.Where("user_id = ? AND activity_date = ?", userID, date).Find(&dayActivities).Error; err != nil {
+			return err
+		}
+
+		var target *models.Activity
+		for i := range dayActivities {
+			a := &dayActivities[i]
+			previousTotal += a.DurationHours
+			if a.Name == name {
+				target = a
+			}
+		}
+		if target == nil {
+			return ErrActivityNotFound
+		}
+
+		newTotal = previousTotal - target.DurationHours
+		remaining = len(dayActivities) - 1
+		return tx.Delete(target).Error
+	})
+	if err != nil {
+		return 0, 0, 0, err
+	}
+	return previousTotal, newTotal, remaining, nil
+}
+
+// DeleteAllByName deletes every activity the user logged under name, under the same lock
+// as UpsertManyForDay, and returns how many rows were removed
+func (r *ActivityRepository) DeleteAllByName(userID uint, name models.ActivityName) (int64, error) {
+	var deleted int64
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := lockUserForActivityWrite(tx, userID); err != nil {
+			return err
+		}
+		res := tx.Where("user_id = ? AND name = ?", userID, name).Delete(&models.Activity{})
+		deleted = res.RowsAffected
+		return res.Error
+	})
+	return deleted, err
+}
+
+// lockUserForActivityWrite takes a row lock on the user that serialises activity writes.
+// NO KEY UPDATE still lets foreign-key checks against the user row proceed.
+func lockUserForActivityWrite(tx *gorm.DB, userID uint) error {
+	return tx.Clauses(clause.Locking{Strength: "NO KEY UPDATE"}).
+		Select("id").
+		First(&models.User{}, userID).Error
+}
+
+// SumHoursByUser returns the total hours a user has logged across all activities
+func (r *ActivityRepository) SumHoursByUser(userID uint) (float64, error) {
+	var total float64
+	result := r.db.Model(&models.Activity{}).
+		Where("user_id = ?", userID).
+		Select("COALESCE(SUM(duration_hours), 0)").
+		Scan(&total)
+	return total, result.Error
+}
+
+// SearchNotes returns a page of a user's activities whose note contains query
+// (case-insensitive), newest first, along with the total number of matches
+func (r *ActivityRepository) SearchNotes(userID uint, query string, limit, offset int) ([]models.Activity, int64, error) {
+	base := r.db.Model(&models.Activity{}).
+		Where("user_id = ? AND note ILIKE ?", userID, "%"+escapeLike(query)+"%")
+
+	var total int64
+	if err := base.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var activities []models.Activity
+	err := base.Order("activity_date DESC, id DESC").
+		Limit(limit).
+		Offset(offset).
+		Find(&activities).Error
+	return activities, total, err
+}
+
+// ActivityTypeTotal aggregates one activity type across users
+type ActivityTypeTotal struct {
+	Name       models.ActivityName
+	TotalHours float64
+	EntryCount int64
+	UserCount  int64
+}
+
+// TopActivityTypesSince returns the most-logged built-in activity types since the given
+// date across public, active accounts, ordered by total hours. Custom tiles are per-user
+// and left out.
+func (r *ActivityRepository) TopActivityTypesSince(since time.Time, limit int) ([]ActivityTypeTotal, error) {
+	var totals []ActivityTypeTotal
+	err := r.db.Model(&models.Activity{}).
+		Select("activities.name, SUM(activities.duration_hours) AS total_hours, COUNT(*) AS entry_count, COUNT(DISTINCT activities.user_id) AS user_count").
+		Joins("JOIN users u ON u.id = activities.user_id").
+		Where("activities.activity_date >= ? AND activities.duration_hours > 0", since).
+		Where("activities.name NOT LIKE ?", "custom:%").
+		Where("u.is_private = false AND u.is_deactivated = false").
+		Group("activities.name").
+		Order("total_hours DESC, user_count DESC").
+		Limit(limit).
+		Scan(&totals).Error
+	return totals, err
+}
+
+// FindLastLoggedDate returns the most recent date on which the user logged any hours,
+// or nil if they never have
+func (r *ActivityRepository) FindLastLoggedDate(userID uint) (*time.Time, error) {
+	var activity models.Activity
+	result := r.db.Select("activity_date").
+		Where("user_id = ? AND duration_hours > 0", userID).
+		Order("activity_date DESC").
+		Limit(1).
+		Find(&activity)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &activity.ActivityDate, nil
+}
+
+// FindActiveDates returns the distinct dates on which a user logged any activity, oldest first
+func (r *ActivityRepository) FindActiveDates(userID uint) ([]time.Time, error) {
+	var dates []time.Time
+	result := r.db.Model(&models.Activity{}).
+		Where("user_id = ?", userID).
+		Distinct("activity_date").
+		Order("activity_date ASC").
+		Pluck("activity_date", &dates)
+	return dates, result.Error
+}
+
 // ==================== Streak Repository ====================
 
-// StreakRepository handles streak data operations
+// StreakRepository handles streak data operations.
+// Unless named otherwise, methods operate on the aggregate streak (activity_name IS NULL).
 type StreakRepository struct {
 	db *gorm.DB
 }
@@ -321,10 +949,35 @@ func (r *StreakRepository) Update(streak *models.Streak) error {
 	return r.db.Save(streak).Error
 }
 
+// Delete removes a streak record
+func (r *StreakRepository) Delete(streak *models.Streak) error {
+	return r.db.Delete(streak).Error
+}
+
+// DeleteByActivity removes every per-activity streak record for name
+func (r *StreakRepository) DeleteByActivity(userID uint, name models.ActivityName) error {
+	return r.db.Where("user_id = ? AND activity_name = ?", userID, name).Delete(&models.Streak{}).Error
+}
+
+// SaveAll creates or updates a set of streak records in a single transaction
+func (r *StreakRepository) SaveAll(streaks []*models.Streak) error {
+	if len(streaks) == 0 {
+		return nil
+	}
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, streak := range streaks {
+			if err := tx.Save(streak).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 // FindLatestByUser finds the most recent streak for a user
 func (r *StreakRepository) FindLatestByUser(userID uint) (*models.Streak, error) {
 	var streak models.Streak
-	result := r.db.Where("user_id = ?", userID).Order("activity_date DESC").Limit(1).Find(&streak)
+	result := r.db.Where("user_id = ? AND activity_name IS NULL", userID).Order("activity_date DESC").Limit(1).Find(&streak)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -337,7 +990,7 @@ func (r *StreakRepository) FindLatestByUser(userID uint) (*models.Streak, error)
 // FindLatestActiveByUser finds the most recent streak where user actually logged activity (current > 0)
 func (r *StreakRepository) FindLatestActiveByUser(userID uint) (*models.Streak, error) {
 	var streak models.Streak
-	result := r.db.Where("user_id = ? AND current > 0", userID).Order("activity_date DESC").Limit(1).Find(&streak)
+	result := r.db.Where("user_id = ? AND activity_name IS NULL AND current > 0", userID).Order("activity_date DESC").Limit(1).Find(&streak)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -350,7 +1003,7 @@ func (r *StreakRepository) FindLatestActiveByUser(userID uint) (*models.Streak,
 // FindPreviousByUser finds the second most recent streak for a user
 func (r *StreakRepository) FindPreviousByUser(userID uint) (*models.Streak, error) {
 	var streak models.Streak
-	result := r.db.Where("user_id = ?", userID).Order("activity_date DESC").Offset(1).Limit(1).Find(&streak)
+	result := r.db.Where("user_id = ? AND activity_name IS NULL", userID).Order("activity_date DESC").Offset(1).Limit(1).Find(&streak)
 	if result.Error != nil {
 		return nil, result.Error
 	}
@@ -363,7 +1016,7 @@ func (r *StreakRepository) FindPreviousByUser(userID uint) (*models.Streak, erro
 // FindByUserAndDate finds a streak for a user on a specific date
 func (r *StreakRepository) FindByUserAndDate(userID uint, date time.Time) (*models.Streak, error) {
 	var streak models.Streak
-	result := r.db.Where("user_id = ? AND activity_date = ?", userID, date).Last(&streak)
+	result := r.db.Where("user_id = ? AND activity_name IS NULL AND activity_date = ?", userID, date).Last(&streak)
 	if result.Error != nil {
 		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
 			return nil, nil
@@ -376,16 +1029,157 @@ func (r *StreakRepository) FindByUserAndDate(userID uint, date time.Time) (*mode
 // FindAllByUser finds all streaks for a user ordered by date
 func (r *StreakRepository) FindAllByUser(userID uint) ([]models.Streak, error) {
 	var streaks []models.Streak
-	result := r.db.Where("user_id = ?", userID).Order("activity_date ASC").Find(&streaks)
+	result := r.db.Where("user_id = ? AND activity_name IS NULL", userID).Order("activity_date ASC").Find(&streaks)
 	return streaks, result.Error
 }
 
+// FindByUserAndDateRange finds streaks for a user between two dates (inclusive), oldest first.
+// Served by idx_streaks_user_date (user_id, activity_date).
+func (r *StreakRepository) FindByUserAndDateRange(userID uint, startDate, endDate time.Time) ([]models.Streak, error) {
+	var streaks []models.Streak
+	result := r.db.Where(
+		"user_id = ? AND activity_name IS NULL AND activity_date BETWEEN ? AND ?",
+		userID, startDate, endDate,
+	).Order("activity_date ASC").Find(&streaks)
+	return streaks, result.Error
+}
+
+// FindLatestBeforeDate finds the most recent streak strictly before a date
+func (r *StreakRepository) FindLatestBeforeDate(userID uint, date time.Time) (*models.Streak, error) {
+	var streak models.Streak
+	result := r.db.Where("user_id = ? AND activity_name IS NULL AND activity_date < ?", userID, date).
+		Order("activity_date DESC").Limit(1).Find(&streak)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &streak, nil
+}
+
+// FindLatestByUserAndActivity finds the most recent streak for a single activity type
+func (r *StreakRepository) FindLatestByUserAndActivity(userID uint, name models.ActivityName) (*models.Streak, error) {
+	var streak models.Streak
+	result := r.db.Where("user_id = ? AND activity_name = ?", userID, name).Order("activity_date DESC").Limit(1).Find(&streak)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &streak, nil
+}
+
+// FindLatestPerActivity finds the most recent streak for each activity type a user has logged
+func (r *StreakRepository) FindLatestPerActivity(userID uint) ([]models.Streak, error) {
+	var streaks []models.Streak
+	result := r.db.Raw(`
+		SELECT DISTINCT ON (activity_name) *
+		FROM streaks
+		WHERE user_id = ? AND activity_name IS NOT NULL
+		ORDER BY activity_name, activity_date DESC`,
+		userID,
+	).Scan(&streaks)
+	return streaks, result.Error
+}
+
+// StreakLeaderboardRow is a followee with their current aggregate streak
+type StreakLeaderboardRow struct {
+	UserID        uint    `gorm:"column:user_id"`
+	Username      string  `gorm:"column:username"`
+	ProfilePic    *string `gorm:"column:profile_pic"`
+	IsVerified    bool    `gorm:"column:is_verified"`
+	CurrentStreak int     `gorm:"column:current_streak"`
+}
+
+// GetFollowingLeaderboard ranks a viewer's active followees by current streak, highest first.
+// A streak counts as current if it was extended on or after since (typically yesterday),
+// so users who haven't logged yet today keep yesterday's count.
+func (r *StreakRepository) GetFollowingLeaderboard(viewerID uint, since time.Time, limit int) ([]StreakLeaderboardRow, error) {
+	var rows []StreakLeaderboardRow
+	err := r.db.Raw(`
+		SELECT u.id AS user_id, u.username, u.profile_pic, u.is_verified,
+			COALESCE(s.current, 0) AS current_streak
+		FROM follow_edges_by_follower f
+		JOIN users u ON u.id = f.followee_id
+		LEFT JOIN LATERAL (
+			SELECT MAX(current) AS current
+			FROM streaks
+			WHERE user_id = u.id AND activity_name IS NULL AND activity_date >= ?
+		) s ON true
+		WHERE f.follower_id = ? AND f.state = ? AND u.is_deactivated = false
+		ORDER BY current_streak DESC, u.username ASC
+		LIMIT ?`,
+		since, viewerID, models.FollowStateActive, limit,
+	).Scan(&rows).Error
+	return rows, err
+}
+
+// GetFreeze returns a user's streak freeze balance, or nil if none has been granted
+func (r *StreakRepository) GetFreeze(userID uint) (*models.StreakFreeze, error) {
+	var freeze models.StreakFreeze
+	result := r.db.Where("user_id = ?", userID).Limit(1).Find(&freeze)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &freeze, nil
+}
+
+// GrantFreezes adds freezes to a user's balance, capped at maxBalance
+func (r *StreakRepository) GrantFreezes(userID uint, count, maxBalance int) error {
+	now := time.Now()
+	return r.db.Exec(`
+		INSERT INTO streak_freezes (user_id, available, last_granted_at, updated_at)
+		VALUES (?, LEAST(?, ?), ?, ?)
+		ON CONFLICT (user_id) DO UPDATE
+		SET available = LEAST(streak_freezes.available + EXCLUDED.available, ?),
+			last_granted_at = EXCLUDED.last_granted_at,
+			updated_at = EXCLUDED.updated_at`,
+		userID, count, maxBalance, now, now, maxBalance,
+	).Error
+}
+
+// ConsumeFreeze atomically uses one freeze to forgive missedDate.
+// Marks the missed day's streak row as frozen. Returns false if no freeze was available.
+func (r *StreakRepository) ConsumeFreeze(userID uint, missedDate time.Time) (bool, error) {
+	consumed := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		result := tx.Model(&models.StreakFreeze{}).
+			Where("user_id = ? AND available > 0", userID).
+			Updates(map[string]interface{}{
+				"available":     gorm.Expr("available - 1"),
+				"last_used_at":  now,
+				"last_used_for": missedDate,
+				"updated_at":    now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		if err := tx.Model(&models.Streak{}).
+			Where("user_id = ? AND activity_name IS NULL AND activity_date = ?", userID, missedDate).
+			Update("frozen", true).Error; err != nil {
+			return err
+		}
+		consumed = true
+		return nil
+	})
+	return consumed, err
+}
+
 // FindUsersMissedStreak finds users who had a zero streak on a specific date
 func (r *StreakRepository) FindUsersMissedStreak(date string) ([]uint, error) {
 	var userIDs []uint
 	result := r.db.Table("streaks").
 		Select("DISTINCT user_id").
-		Where("current = 0 AND DATE(activity_date) = ?", date).
+		Where("current = 0 AND activity_name IS NULL AND DATE(activity_date) = ?", date).
 		Pluck("user_id", &userIDs)
 	return userIDs, result.Error
 }
@@ -402,15 +1196,11 @@ func NewCronJobLogRepository(db *gorm.DB) *CronJobLogRepository {
 	return &CronJobLogRepository{db: db}
 }
 
-// Create creates a new cron job log entry
-func (r *CronJobLogRepository) Create(log *models.CronJobLog) error {
-	return r.db.Create(log).Error
-}
-
 // TryClaimJob atomically attempts to claim a job for execution using INSERT ... ON CONFLICT DO NOTHING.
 // Returns (jobLog, true) if this instance successfully claimed the job.
 // Returns (existingLog, false) if another instance already claimed it.
-// This prevents race conditions in multi-replica environments.
+// This prevents race conditions in multi-replica environments; it is the only way jobs
+// create their log rows, so there is no separate check-then-insert path.
 func (r *CronJobLogRepository) TryClaimJob(jobName string, jobDate time.Time, instanceID string) (*models.CronJobLog, bool, error) {
 	log := &models.CronJobLog{
 		JobName:    jobName,
@@ -434,6 +1224,15 @@ func (r *CronJobLogRepository) TryClaimJob(jobName string, jobDate time.Time, in
 
 	// If no rows affected, another replica already claimed this job
 	if result.RowsAffected == 0 {
+		// Record the losing attempt so the job history shows every replica that tried
+		if err := r.db.Exec(`
+			UPDATE cron_job_logs
+			SET skip_count = skip_count + 1, last_skipped_by = ?, last_skipped_at = ?
+			WHERE job_name = ? AND job_date = ?
+		`, instanceID, time.Now(), jobName, jobDate).Error; err != nil {
+			return nil, false, err
+		}
+
 		// Fetch the existing log to return info about who claimed it
 		var existingLog models.CronJobLog
 		if err := r.db.Where("job_name = ? AND job_date = ?", jobName, jobDate).First(&existingLog).Error; err != nil {
@@ -451,33 +1250,36 @@ func (r *CronJobLogRepository) TryClaimJob(jobName string, jobDate time.Time, in
 	return &createdLog, true, nil
 }
 
-// Update updates an existing cron job log entry
-func (r *CronJobLogRepository) Update(log *models.CronJobLog) error {
-	return r.db.Save(log).Error
+// DeleteFailed removes a failed run's log so the job can be claimed again for that date
+func (r *CronJobLogRepository) DeleteFailed(jobName string, jobDate time.Time) error {
+	return r.db.Where("job_name = ? AND job_date = ? AND status = ?", jobName, jobDate, models.CronJobStatusFailed).
+		Delete(&models.CronJobLog{}).Error
 }
 
-// FindByJobNameAndDate finds a cron job log by job name and date
-func (r *CronJobLogRepository) FindByJobNameAndDate(jobName string, jobDate time.Time) (*models.CronJobLog, error) {
-	var log models.CronJobLog
-	result := r.db.Where("job_name = ? AND job_date = ? AND status = ?", jobName, jobDate, models.CronJobStatusCompleted).First(&log)
-	if result.Error != nil {
-		if errors.Is(result.Error, gorm.ErrRecordNotFound) {
-			return nil, nil
-		}
-		return nil, result.Error
-	}
-	return &log, nil
+// Update writes the outcome of a job run. Only the outcome columns are written so
+// skip counts recorded by other replicas while the job ran are kept.
+func (r *CronJobLogRepository) Update(log *models.CronJobLog) error {
+	return r.db.Model(log).Select("status", "users_count", "completed_at", "error").Updates(log).Error
 }
 
-// FindRecentByJobName finds recent cron job logs by job name
+// FindRecentByJobName finds recent cron job logs by job name, most recent first.
+// Per-timezone runs ("daily_streak@America/New_York") are included with their base job;
+// an empty job name returns recent runs of every job.
 func (r *CronJobLogRepository) FindRecentByJobName(jobName string, limit int) ([]models.CronJobLog, error) {
 	var logs []models.CronJobLog
-	result := r.db.Where("job_name = ?", jobName).Order("started_at DESC").Limit(limit).Find(&logs)
+	query := r.db.Order("started_at DESC").Limit(limit)
+	if jobName != "" {
+		query = query.Where("job_name = ? OR job_name LIKE ?", jobName, escapeLike(jobName)+"@%")
+	}
+	result := query.Find(&logs)
 	return logs, result.Error
 }
 
 // ==================== Tile Config Repository ====================
 
+// ErrCustomTileNotFound is returned when the user's tile config has no custom tile with the given ID
+var ErrCustomTileNotFound = errors.New("custom tile not found")
+
 // TileConfigRepository handles tile configuration data operations
 type TileConfigRepository struct {
 	db *gorm.DB
@@ -501,23 +1303,195 @@ func (r *TileConfigRepository) FindByUserID(userID uint) (*models.TileConfig, er
 	return &config, nil
 }
 
-// Save creates or updates tile config for a user
+// Save creates or updates tile config for a user, and syncs their custom activity types
+// with its custom tiles
 func (r *TileConfigRepository) Save(userID uint, config models.JSONB) error {
-	var existing models.TileConfig
-	result := r.db.Where("user_id = ?", userID).First(&existing)
+	data, err := (&models.TileConfig{Config: config}).GetConfigData()
+	if err != nil {
+		return err
+	}
 
-	if result.Error != nil {
-		// Create new record
-		newConfig := models.TileConfig{
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.TileConfig
+		result := tx.Where("user_id = ?", userID).First(&existing)
+
+		if result.Error != nil {
+			// Create new record
+			newConfig := models.TileConfig{
+				UserID: userID,
+				Config: config,
+			}
+			if err := tx.Create(&newConfig).Error; err != nil {
+				return err
+			}
+		} else {
+			// Update existing record
+			existing.Config = config
+			if err := tx.Save(&existing).Error; err != nil {
+				return err
+			}
+		}
+
+		return syncCustomActivityTypes(tx, userID, data.CustomTiles)
+	})
+}
+
+// syncCustomActivityTypes makes the user's custom activity types match tiles: each tile's
+// type is created or updated, and types of tiles no longer listed are removed. Activities
+// logged under a removed tile are archived, and un-archived if the tile comes back.
+func syncCustomActivityTypes(tx *gorm.DB, userID uint, tiles []models.CustomTile) error {
+	tileIDs := make([]string, 0, len(tiles))
+	names := make([]string, 0, len(tiles))
+	for _, tile := range tiles {
+		activityType := models.CustomActivityType{
 			UserID: userID,
-			Config: config,
+			TileID: tile.ID,
+			Name:   tile.Name,
+			Icon:   tile.Icon,
+			Color:  tile.Color,
+		}
+		if err := tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "tile_id"}},
+			DoUpdates: clause.AssignmentColumns([]string{"name", "icon", "color", "updated_at"}),
+		}).Create(&activityType).Error; err != nil {
+			return err
 		}
-		return r.db.Create(&newConfig).Error
+		tileIDs = append(tileIDs, tile.ID)
+		names = append(names, string(activityType.ActivityName()))
 	}
 
-	// Update existing record
-	existing.Config = config
-	return r.db.Save(&existing).Error
+	var removed []models.CustomActivityType
+	query := tx.Clauses(clause.Returning{Columns: []clause.Column{{Name: "tile_id"}}}).Where("user_id = ?", userID)
+	if len(tileIDs) > 0 {
+		query = query.Where("tile_id NOT IN ?", tileIDs)
+	}
+	if err := query.Delete(&removed).Error; err != nil {
+		return err
+	}
+
+	if len(removed) > 0 {
+		removedNames := make([]string, len(removed))
+		for i := range removed {
+			removedNames[i] = string(removed[i].ActivityName())
+		}
+		if err := tx.Model(&models.Activity{}).
+			Where("user_id = ? AND name IN ? AND archived_at IS NULL", userID, removedNames).
+			UpdateColumn("archived_at", time.Now()).Error; err != nil {
+			return err
+		}
+	}
+	if len(names) > 0 {
+		return tx.Model(&models.Activity{}).
+			Where("user_id = ? AND name IN ? AND archived_at IS NOT NULL", userID, names).
+			UpdateColumn("archived_at", nil).Error
+	}
+	return nil
+}
+
+// CreateIfAbsent stores config as the user's tile config unless they already have one,
+// and returns whichever config ends up stored
+func (r *TileConfigRepository) CreateIfAbsent(userID uint, config models.JSONB) (*models.TileConfig, error) {
+	newConfig := models.TileConfig{
+		UserID: userID,
+		Config: config,
+	}
+	if err := r.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&newConfig).Error; err != nil {
+		return nil, err
+	}
+	return r.FindByUserID(userID)
+}
+
+// RemoveCustomTile deletes a custom tile from the user's config along with every reference
+// to it in order, sizes, hidden and colors. Returns ErrCustomTileNotFound if the user has
+// no such tile.
+func (r *TileConfigRepository) RemoveCustomTile(userID uint, tileID string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.TileConfig
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("user_id = ?", userID).First(&existing).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrCustomTileNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		data, err := existing.GetConfigData()
+		if err != nil {
+			return err
+		}
+		if !data.HasCustomTile(tileID) {
+			return ErrCustomTileNotFound
+		}
+		data.RemoveCustomTile(tileID)
+
+		if err := existing.SetConfigData(data); err != nil {
+			return err
+		}
+		if err := tx.Save(&existing).Error; err != nil {
+			return err
+		}
+		return syncCustomActivityTypes(tx, userID, data.CustomTiles)
+	})
+}
+
+// ==================== Custom Activity Type Repository ====================
+
+// CustomActivityTypeRepository handles custom activity type data operations. Types are
+// written through TileConfigRepository, which keeps them in sync with the tile config.
+type CustomActivityTypeRepository struct {
+	db *gorm.DB
+}
+
+// NewCustomActivityTypeRepository creates a new CustomActivityTypeRepository
+func NewCustomActivityTypeRepository(db *gorm.DB) *CustomActivityTypeRepository {
+	return &CustomActivityTypeRepository{db: db}
+}
+
+// FindByUserID returns the user's custom activity types, oldest first
+func (r *CustomActivityTypeRepository) FindByUserID(userID uint) ([]models.CustomActivityType, error) {
+	var types []models.CustomActivityType
+	err := r.db.Where("user_id = ?", userID).Order("created_at ASC, id ASC").Find(&types).Error
+	return types, err
+}
+
+// Exists reports whether the user has defined a custom activity type for tileID
+func (r *CustomActivityTypeRepository) Exists(userID uint, tileID string) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.CustomActivityType{}).
+		Where("user_id = ? AND tile_id = ?", userID, tileID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// UpdateOrder replaces only the order field of the user's tile config. The row is locked
+// while validate checks the order against the stored config, so concurrent saves of other
+// fields are neither clobbered nor validated against stale data. Returns
+// gorm.ErrRecordNotFound if the user has no config.
+func (r *TileConfigRepository) UpdateOrder(userID uint, order []string, validate func(*models.TileConfigData) error) error {
+	orderJSON, err := json.Marshal(order)
+	if err != nil {
+		return err
+	}
+
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.TileConfig
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ?", userID).First(&existing).Error; err != nil {
+			return err
+		}
+
+		data, err := existing.GetConfigData()
+		if err != nil {
+			return err
+		}
+		if err := validate(data); err != nil {
+			return err
+		}
+
+		return tx.Exec(`UPDATE tile_configs
+			SET config = jsonb_set(COALESCE(config, '{}'::jsonb), '{order}', ?::jsonb), updated_at = NOW()
+			WHERE id = ?`, string(orderJSON), existing.ID).Error
+	})
 }
 
 // ==================== Recent Search Repository ====================
@@ -542,8 +1516,10 @@ type RecentSearchResult struct {
 	FollowersCount  int64   `json:"followers_count"`
 }
 
-// SaveRecentSearch upserts a recent search, only if last search was >60s ago (throttle)
-func (r *RecentSearchRepository) SaveRecentSearch(userID, searchedUserID uint) error {
+// SaveRecentSearch upserts a recent search, only if last search was >60s ago (throttle).
+// Each write also trims the user's history to the newest maxPerUser rows and drops rows
+// older than maxAgeDays, so the table stays bounded per user.
+func (r *RecentSearchRepository) SaveRecentSearch(userID, searchedUserID uint, maxPerUser, maxAgeDays int) error {
 	// Validate input
 	if userID == 0 || searchedUserID == 0 {
 		return nil // Invalid IDs, silently skip
@@ -554,14 +1530,33 @@ func (r *RecentSearchRepository) SaveRecentSearch(userID, searchedUserID uint) e
 		return nil
 	}
 
-	// Use upsert with throttle: only update if last save was >60 seconds ago
-	return r.db.Exec(`
-		INSERT INTO recent_searches (user_id, searched_user_id, searched_at)
-		VALUES ($1, $2, NOW())
-		ON CONFLICT (user_id, searched_user_id) DO UPDATE
-		SET searched_at = NOW()
-		WHERE recent_searches.searched_at < NOW() - INTERVAL '60 seconds'
-	`, userID, searchedUserID).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Use upsert with throttle: only update if last save was >60 seconds ago
+		result := tx.Exec(`
+			INSERT INTO recent_searches (user_id, searched_user_id, searched_at)
+			VALUES ($1, $2, NOW())
+			ON CONFLICT (user_id, searched_user_id) DO UPDATE
+			SET searched_at = NOW()
+			WHERE recent_searches.searched_at < NOW() - INTERVAL '60 seconds'
+		`, userID, searchedUserID)
+		if result.Error != nil || result.RowsAffected == 0 {
+			return result.Error // Throttled: nothing new to trim
+		}
+
+		// Rank the user's rows newest first and drop those past the cap or too old
+		return tx.Exec(`
+			DELETE FROM recent_searches rs
+			USING (
+				SELECT searched_user_id,
+					ROW_NUMBER() OVER (ORDER BY searched_at DESC) AS rn
+				FROM recent_searches
+				WHERE user_id = $1
+			) ranked
+			WHERE rs.user_id = $1
+			AND rs.searched_user_id = ranked.searched_user_id
+			AND (ranked.rn > $2 OR rs.searched_at < NOW() - make_interval(days => $3))
+		`, userID, maxPerUser, maxAgeDays).Error
+	})
 }
 
 // GetRecentSearches returns the user's most recent profile searches with user details
@@ -788,3 +1783,81 @@ func (r *RecentSearchRepository) GetTrendingUsersForUser(userID uint, limit int)
 
 	return results, nil
 }
+
+// MutualSuggestionResult represents a suggested user with the number of the viewer's
+// followees who follow them
+type MutualSuggestionResult struct {
+	ID              uint    `json:"id"`
+	Username        string  `json:"username"`
+	ProfilePic      *string `json:"profile_pic"`
+	ProfilePicThumb *string `json:"profile_pic_thumb"`
+	IsVerified      bool    `json:"is_verified"`
+	IsPrivate       bool    `json:"is_private"`
+	FollowersCount  int64   `json:"followers_count"`
+	MutualCount     int     `json:"mutual_count"`
+}
+
+// GetMutualSuggestions returns friend-of-friend suggestions: users followed by the people
+// userID follows, ranked by how many of them do. Private accounts qualify under the same
+// rule as trending (someone the viewer follows follows them), which every candidate meets.
+// Excludes self, deactivated accounts, users already followed or requested, and users
+// blocked in either direction.
+func (r *RecentSearchRepository) GetMutualSuggestions(userID uint, limit int) ([]MutualSuggestionResult, error) {
+	if userID == 0 {
+		return nil, nil // Invalid user, return empty
+	}
+
+	var results []MutualSuggestionResult
+	err := r.db.Raw(`
+		WITH my_following AS (
+			SELECT followee_id
+			FROM follow_edges_by_follower
+			WHERE follower_id = $1 AND state = 'ACTIVE'
+		),
+		candidates AS (
+			SELECT fe.followee_id AS user_id, COUNT(*) AS mutual_count
+			FROM follow_edges_by_follower fe
+			WHERE fe.follower_id IN (SELECT followee_id FROM my_following)
+			AND fe.state = 'ACTIVE'
+			AND fe.followee_id != $1
+			GROUP BY fe.followee_id
+		)
+		SELECT
+			u.id,
+			u.username,
+			u.profile_pic,
+			u.profile_pic_thumb,
+			u.is_verified,
+			u.is_private,
+			COALESCE(fc.followers_count, 0) as followers_count,
+			c.mutual_count
+		FROM candidates c
+		JOIN users u ON u.id = c.user_id
+		LEFT JOIN follow_counters fc ON fc.user_id = u.id
+		WHERE u.is_deactivated = false
+		-- Exclude users I already follow or have a pending request to
+		AND NOT EXISTS (
+			SELECT 1 FROM follow_edges_by_follower mine
+			WHERE mine.follower_id = $1
+			AND mine.followee_id = u.id
+			AND mine.state IN ('ACTIVE', 'PENDING')
+		)
+		-- Exclude users blocked in either direction
+		AND NOT EXISTS (
+			SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_id = $1 AND b.blocked_id = u.id)
+				OR (b.blocker_id = u.id AND b.blocked_id = $1)
+		)
+		ORDER BY
+			c.mutual_count DESC,
+			followers_count DESC,
+			u.username ASC
+		LIMIT $2
+	`, userID, limit).Scan(&results).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
@@ -0,0 +1,128 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/testutil"
+	"github.com/aman1117/backend/pkg/models"
+)
+
+func TestUpsertForDayConcurrentWritesKeepDailyCap(t *testing.T) {
+	db := testutil.OpenDB(t)
+	user := testutil.CreateUser(t, db, "concurrentdays")
+	repo := NewActivityRepository(db)
+
+	// Each round races the first two writes of a fresh day: 15h + 15h only fits once
+	const rounds = 10
+	start := time.Date(2025, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for round := 0; round < rounds; round++ {
+		date := start.AddDate(0, 0, round)
+		t.Run(fmt.Sprintf("day_%d", round+1), func(t *testing.T) {
+			names := []models.ActivityName{models.ActivityStudy, models.ActivitySleep}
+			errs := make([]error, len(names))
+
+			var ready, done sync.WaitGroup
+			begin := make(chan struct{})
+			for i, name := range names {
+				ready.Add(1)
+				done.Add(1)
+				go func() {
+					defer done.Done()
+					ready.Done()
+					<-begin
+					_, _, errs[i] = repo.UpsertForDay(user.ID, date, name, 15, nil, constants.MaxDailyHours)
+				}()
+			}
+			ready.Wait()
+			close(begin)
+			done.Wait()
+
+			succeeded := 0
+			for i, err := range errs {
+				switch {
+				case err == nil:
+					succeeded++
+				case !errors.Is(err, ErrDailyHoursExceeded):
+					t.Fatalf("%s: unexpected error %v", names[i], err)
+				}
+			}
+			if succeeded != 1 {
+				t.Fatalf("%d of 2 concurrent 15h writes succeeded, want exactly 1 (errors: %v)", succeeded, errs)
+			}
+
+			var total float64
+			if err := db.Model(&models.Activity{}).
+				Where("user_id = ? AND activity_date = ?", user.ID, date).
+				Select("COALESCE(SUM(duration_hours), 0)").
+				Scan(&total).Error; err != nil {
+				t.Fatalf("sum day total: %v", err)
+			}
+			if total != 15 {
+				t.Errorf("stored day total = %vh, want 15h", total)
+			}
+		})
+	}
+}
+
+func TestGetCloseFriendFollowerIDs(t *testing.T) {
+	db := testutil.OpenDB(t)
+	owner := testutil.CreateUser(t, db, "storyowner")
+	closeFollower := testutil.CreateUser(t, db, "closefollower")
+	closeNonFollower := testutil.CreateUser(t, db, "closestranger")
+	follower := testutil.CreateUser(t, db, "plainfollower")
+	repo := NewFollowRepository(db)
+
+	for _, u := range []*models.User{closeFollower, follower} {
+		edge := models.FollowEdgeByFollowee{FolloweeID: owner.ID, FollowerID: u.ID, State: models.FollowStateActive}
+		if err := db.Create(&edge).Error; err != nil {
+			t.Fatalf("create follow edge: %v", err)
+		}
+	}
+	for _, u := range []*models.User{closeFollower, closeNonFollower} {
+		if err := repo.AddCloseFriend(owner.ID, u.ID); err != nil {
+			t.Fatalf("AddCloseFriend: %v", err)
+		}
+	}
+
+	ids, err := repo.GetCloseFriendFollowerIDs(owner.ID)
+	if err != nil {
+		t.Fatalf("GetCloseFriendFollowerIDs: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != closeFollower.ID {
+		t.Errorf("close friend followers = %v, want only %d", ids, closeFollower.ID)
+	}
+}
+
+func TestAutocompleteUsersExcludesBlockedEitherWay(t *testing.T) {
+	db := testutil.OpenDB(t)
+	if err := db.Exec("CREATE EXTENSION IF NOT EXISTS pg_trgm").Error; err != nil {
+		t.Skipf("pg_trgm unavailable: %v", err)
+	}
+	viewer := testutil.CreateUser(t, db, "searcher")
+	blockedByViewer := testutil.CreateUser(t, db, "matchone")
+	blockerOfViewer := testutil.CreateUser(t, db, "matchtwo")
+	visible := testutil.CreateUser(t, db, "matchthree")
+
+	for _, block := range []models.UserBlock{
+		{BlockerID: viewer.ID, BlockedID: blockedByViewer.ID},
+		{BlockerID: blockerOfViewer.ID, BlockedID: viewer.ID},
+	} {
+		if err := db.Create(&block).Error; err != nil {
+			t.Fatalf("create block: %v", err)
+		}
+	}
+
+	results, err := NewUserRepository(db).AutocompleteUsers(viewer.ID, "match", 2)
+	if err != nil {
+		t.Fatalf("AutocompleteUsers: %v", err)
+	}
+	// Both blocked matches are excluded, whichever side made the block
+	if len(results) != 1 || results[0].ID != visible.ID {
+		t.Errorf("results = %+v, want only %s", results, visible.Username)
+	}
+}
@@ -0,0 +1,97 @@
+// Package repository provides data access layer for login sessions.
+package repository
+
+import (
+	"time"
+
+	"github.com/aman1117/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+// SessionRepository handles login session data operations
+type SessionRepository struct {
+	db *gorm.DB
+}
+
+// NewSessionRepository creates a new SessionRepository
+func NewSessionRepository(db *gorm.DB) *SessionRepository {
+	return &SessionRepository{db: db}
+}
+
+// Create stores a new session
+func (r *SessionRepository) Create(session *models.Session) error {
+	return r.db.Create(session).Error
+}
+
+// IsActive reports whether the session exists and has not been revoked
+func (r *SessionRepository) IsActive(sessionID uint) (bool, error) {
+	var count int64
+	err := r.db.Model(&models.Session{}).
+		Where("id = ? AND revoked_at IS NULL", sessionID).
+		Count(&count).Error
+	return count > 0, err
+}
+
+// ListActiveByUser returns the user's unrevoked sessions, most recently used first
+func (r *SessionRepository) ListActiveByUser(userID uint) ([]models.Session, error) {
+	var sessions []models.Session
+	err := r.db.Where("user_id = ? AND revoked_at IS NULL", userID).
+		Order("last_seen_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+// Revoke revokes one of the user's sessions along with its refresh tokens.
+// Returns false if the session does not belong to the user or was already revoked.
+func (r *SessionRepository) Revoke(userID, sessionID uint) (bool, error) {
+	revoked := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&models.Session{}).
+			Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+			Update("revoked_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return nil
+		}
+		revoked = true
+		return tx.Model(&models.RefreshToken{}).
+			Where("session_id = ? AND revoked_at IS NULL", sessionID).
+			Update("revoked_at", now).Error
+	})
+	return revoked, err
+}
+
+// RevokeAllForUser revokes every active session of a user and returns their IDs
+func (r *SessionRepository) RevokeAllForUser(userID uint) ([]uint, error) {
+	var ids []uint
+	err := r.db.Model(&models.Session{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Pluck("id", &ids).Error
+	if err != nil || len(ids) == 0 {
+		return nil, err
+	}
+
+	err = r.db.Model(&models.Session{}).
+		Where("id IN ?", ids).
+		Update("revoked_at", time.Now()).Error
+	return ids, err
+}
+
+// TouchLastSeen records that the session was just used
+func (r *SessionRepository) TouchLastSeen(sessionID uint, ip string) error {
+	updates := map[string]interface{}{"last_seen_at": time.Now()}
+	if ip != "" {
+		updates["ip_address"] = ip
+	}
+	return r.db.Model(&models.Session{}).Where("id = ?", sessionID).Updates(updates).Error
+}
+
+// DeleteStale removes sessions revoked or last used before cutoff; their refresh
+// tokens are removed by the foreign key cascade
+func (r *SessionRepository) DeleteStale(cutoff time.Time) (int64, error) {
+	res := r.db.Where("revoked_at < ? OR last_seen_at < ?", cutoff, cutoff).Delete(&models.Session{})
+	return res.RowsAffected, res.Error
+}
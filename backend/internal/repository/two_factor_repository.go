@@ -0,0 +1,84 @@
+// Package repository provides data access layer for two-factor authentication.
+package repository
+
+import (
+	"time"
+
+	"github.com/aman1117/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+// TwoFactorRepository handles TOTP secrets and backup codes
+type TwoFactorRepository struct {
+	db *gorm.DB
+}
+
+// NewTwoFactorRepository creates a new TwoFactorRepository
+func NewTwoFactorRepository(db *gorm.DB) *TwoFactorRepository {
+	return &TwoFactorRepository{db: db}
+}
+
+// SetPendingSecret stores an encrypted secret for an enrollment that is not yet verified.
+// It does nothing if two-factor is already enabled, so an active secret is never replaced.
+// Returns false in that case.
+func (r *TwoFactorRepository) SetPendingSecret(userID uint, encryptedSecret string) (bool, error) {
+	res := r.db.Model(&models.User{}).
+		Where("id = ? AND two_factor_enabled = false", userID).
+		Update("two_factor_secret", encryptedSecret)
+	return res.RowsAffected > 0, res.Error
+}
+
+// Enable turns on two-factor for the user and replaces their backup codes
+func (r *TwoFactorRepository) Enable(userID uint, codeHashes []string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).
+			Update("two_factor_enabled", true).Error; err != nil {
+			return err
+		}
+		return replaceBackupCodes(tx, userID, codeHashes)
+	})
+}
+
+// Disable turns off two-factor, clearing the secret and all backup codes
+func (r *TwoFactorRepository) Disable(userID uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&models.User{}).Where("id = ?", userID).
+			Updates(map[string]interface{}{
+				"two_factor_enabled": false,
+				"two_factor_secret":  nil,
+			}).Error; err != nil {
+			return err
+		}
+		return tx.Where("user_id = ?", userID).Delete(&models.TwoFactorBackupCode{}).Error
+	})
+}
+
+// ConsumeBackupCode marks a matching unused backup code as used.
+// Returns false if no unused code matches; a code can only be consumed once, even concurrently.
+func (r *TwoFactorRepository) ConsumeBackupCode(userID uint, codeHash string) (bool, error) {
+	res := r.db.Model(&models.TwoFactorBackupCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", time.Now())
+	return res.RowsAffected > 0, res.Error
+}
+
+// CountUnusedBackupCodes returns how many backup codes the user has left
+func (r *TwoFactorRepository) CountUnusedBackupCodes(userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.TwoFactorBackupCode{}).
+		Where("user_id = ? AND used_at IS NULL", userID).
+		Count(&count).Error
+	return count, err
+}
+
+func replaceBackupCodes(tx *gorm.DB, userID uint, codeHashes []string) error {
+	if err := tx.Where("user_id = ?", userID).Delete(&models.TwoFactorBackupCode{}).Error; err != nil {
+		return err
+	}
+
+	codes := make([]models.TwoFactorBackupCode, 0, len(codeHashes))
+	for _, hash := range codeHashes {
+		codes = append(codes, models.TwoFactorBackupCode{UserID: userID, CodeHash: hash})
+	}
+	return tx.Create(&codes).Error
+}
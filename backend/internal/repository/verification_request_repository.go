@@ -0,0 +1,113 @@
+// Package repository provides data access layer for verification requests.
+package repository
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/aman1117/backend/pkg/models"
+	"gorm.io/gorm"
+)
+
+var (
+	// ErrVerificationRequestPending is returned when the user already has a request awaiting review
+	ErrVerificationRequestPending = errors.New("verification request already pending")
+	// ErrVerificationRequestNotFound is returned when no pending request matches
+	ErrVerificationRequestNotFound = errors.New("verification request not found")
+)
+
+// VerificationRequestRepository handles verification request data operations
+type VerificationRequestRepository struct {
+	db *gorm.DB
+}
+
+// NewVerificationRequestRepository creates a new VerificationRequestRepository
+func NewVerificationRequestRepository(db *gorm.DB) *VerificationRequestRepository {
+	return &VerificationRequestRepository{db: db}
+}
+
+// Create stores a new pending request. The partial unique index on pending requests
+// makes concurrent submissions safe; the loser gets ErrVerificationRequestPending.
+func (r *VerificationRequestRepository) Create(req *models.VerificationRequest) error {
+	err := r.db.Create(req).Error
+	if err != nil && (strings.Contains(err.Error(), "23505") || strings.Contains(err.Error(), "duplicate")) {
+		return ErrVerificationRequestPending
+	}
+	return err
+}
+
+// FindLatestByUser returns the user's most recent request, or nil if they have none
+func (r *VerificationRequestRepository) FindLatestByUser(userID uint) (*models.VerificationRequest, error) {
+	var req models.VerificationRequest
+	result := r.db.Where("user_id = ?", userID).Order("created_at DESC").Limit(1).Find(&req)
+	if result.Error != nil {
+		return nil, result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil, nil
+	}
+	return &req, nil
+}
+
+// ListByStatus returns a page of requests with the given status, oldest first,
+// along with the total number of such requests
+func (r *VerificationRequestRepository) ListByStatus(status models.VerificationRequestStatus, limit, offset int) ([]models.VerificationRequestWithUser, int64, error) {
+	var total int64
+	if err := r.db.Model(&models.VerificationRequest{}).Where("status = ?", status).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	var requests []models.VerificationRequestWithUser
+	err := r.db.Table("verification_requests vr").
+		Select("vr.*, u.username, u.email_verified").
+		Joins("INNER JOIN users u ON u.id = vr.user_id").
+		Where("vr.status = ?", status).
+		Order("vr.created_at ASC, vr.id ASC").
+		Limit(limit).
+		Offset(offset).
+		Scan(&requests).Error
+	return requests, total, err
+}
+
+// Review resolves a pending request. Approving it sets the requester's is_verified flag
+// in the same transaction. Returns ErrVerificationRequestNotFound if the request does not
+// exist or was already reviewed.
+func (r *VerificationRequestRepository) Review(requestID, reviewerID uint, approve bool, note *string) (*models.VerificationRequest, error) {
+	status := models.VerificationStatusDenied
+	if approve {
+		status = models.VerificationStatusApproved
+	}
+
+	var req models.VerificationRequest
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		now := time.Now()
+		res := tx.Model(&models.VerificationRequest{}).
+			Where("id = ? AND status = ?", requestID, models.VerificationStatusPending).
+			Updates(map[string]interface{}{
+				"status":      status,
+				"reviewed_by": reviewerID,
+				"review_note": note,
+				"reviewed_at": now,
+			})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrVerificationRequestNotFound
+		}
+
+		if err := tx.First(&req, requestID).Error; err != nil {
+			return err
+		}
+
+		if approve {
+			return tx.Model(&models.User{}).Where("id = ?", req.UserID).Update("is_verified", true).Error
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &req, nil
+}
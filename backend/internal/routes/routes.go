@@ -4,33 +4,43 @@ package routes
 import (
 	_ "github.com/aman1117/backend/docs" // swagger docs
 
+	"github.com/aman1117/backend/internal/config"
 	"github.com/aman1117/backend/internal/handlers"
 	"github.com/aman1117/backend/internal/middleware"
+	"github.com/aman1117/backend/internal/repository"
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/swagger"
 )
 
 // Router holds all route handlers
 type Router struct {
-	authHandler              *handlers.AuthHandler
-	profileHandler           *handlers.ProfileHandler
-	activityHandler          *handlers.ActivityHandler
-	streakHandler            *handlers.StreakHandler
-	analyticsHandler         *handlers.AnalyticsHandler
-	tileConfigHandler        *handlers.TileConfigHandler
-	passwordResetHandler     *handlers.PasswordResetHandler
-	verificationHandler      *handlers.VerificationHandler
-	blobHandler              *handlers.BlobHandler
-	likeHandler              *handlers.LikeHandler
-	badgeHandler             *handlers.BadgeHandler
-	notificationHandler      *handlers.NotificationHandler
-	notificationWSHandler    *handlers.NotificationWSHandler
-	pushHandler              *handlers.PushHandler
-	followHandler            *handlers.FollowHandler
-	activityPhotoHandler     *handlers.ActivityPhotoHandler
-	searchSuggestionsHandler *handlers.SearchSuggestionsHandler
-	commentHandler           *handlers.CommentHandler
-	tokenSvc                 *handlers.TokenService
+	authHandler                *handlers.AuthHandler
+	profileHandler             *handlers.ProfileHandler
+	activityHandler            *handlers.ActivityHandler
+	streakHandler              *handlers.StreakHandler
+	analyticsHandler           *handlers.AnalyticsHandler
+	tileConfigHandler          *handlers.TileConfigHandler
+	passwordResetHandler       *handlers.PasswordResetHandler
+	verificationHandler        *handlers.VerificationHandler
+	blobHandler                *handlers.BlobHandler
+	likeHandler                *handlers.LikeHandler
+	badgeHandler               *handlers.BadgeHandler
+	notificationHandler        *handlers.NotificationHandler
+	notificationWSHandler      *handlers.NotificationWSHandler
+	pushHandler                *handlers.PushHandler
+	followHandler              *handlers.FollowHandler
+	activityPhotoHandler       *handlers.ActivityPhotoHandler
+	searchSuggestionsHandler   *handlers.SearchSuggestionsHandler
+	commentHandler             *handlers.CommentHandler
+	exportHandler              *handlers.ExportHandler
+	verificationRequestHandler *handlers.VerificationRequestHandler
+	sessionHandler             *handlers.SessionHandler
+	twoFactorHandler           *handlers.TwoFactorHandler
+	adminHandler               *handlers.AdminHandler
+	tokenSvc                   *handlers.TokenService
+	userRepo                   *repository.UserRepository
+	requireVerifiedEmail       bool
+	rateLimits                 *config.RateLimitConfig
 }
 
 // NewRouter creates a new Router with all handlers
@@ -53,28 +63,44 @@ func NewRouter(
 	activityPhotoHandler *handlers.ActivityPhotoHandler,
 	searchSuggestionsHandler *handlers.SearchSuggestionsHandler,
 	commentHandler *handlers.CommentHandler,
+	exportHandler *handlers.ExportHandler,
+	verificationRequestHandler *handlers.VerificationRequestHandler,
+	sessionHandler *handlers.SessionHandler,
+	twoFactorHandler *handlers.TwoFactorHandler,
+	adminHandler *handlers.AdminHandler,
 	tokenSvc *handlers.TokenService,
+	userRepo *repository.UserRepository,
+	requireVerifiedEmail bool,
+	rateLimits *config.RateLimitConfig,
 ) *Router {
 	return &Router{
-		authHandler:              authHandler,
-		profileHandler:           profileHandler,
-		activityHandler:          activityHandler,
-		streakHandler:            streakHandler,
-		analyticsHandler:         analyticsHandler,
-		tileConfigHandler:        tileConfigHandler,
-		passwordResetHandler:     passwordResetHandler,
-		verificationHandler:      verificationHandler,
-		blobHandler:              blobHandler,
-		likeHandler:              likeHandler,
-		badgeHandler:             badgeHandler,
-		notificationHandler:      notificationHandler,
-		notificationWSHandler:    notificationWSHandler,
-		pushHandler:              pushHandler,
-		followHandler:            followHandler,
-		activityPhotoHandler:     activityPhotoHandler,
-		searchSuggestionsHandler: searchSuggestionsHandler,
-		commentHandler:           commentHandler,
-		tokenSvc:                 tokenSvc,
+		authHandler:                authHandler,
+		profileHandler:             profileHandler,
+		activityHandler:            activityHandler,
+		streakHandler:              streakHandler,
+		analyticsHandler:           analyticsHandler,
+		tileConfigHandler:          tileConfigHandler,
+		passwordResetHandler:       passwordResetHandler,
+		verificationHandler:        verificationHandler,
+		blobHandler:                blobHandler,
+		likeHandler:                likeHandler,
+		badgeHandler:               badgeHandler,
+		notificationHandler:        notificationHandler,
+		notificationWSHandler:      notificationWSHandler,
+		pushHandler:                pushHandler,
+		followHandler:              followHandler,
+		activityPhotoHandler:       activityPhotoHandler,
+		searchSuggestionsHandler:   searchSuggestionsHandler,
+		commentHandler:             commentHandler,
+		exportHandler:              exportHandler,
+		verificationRequestHandler: verificationRequestHandler,
+		sessionHandler:             sessionHandler,
+		twoFactorHandler:           twoFactorHandler,
+		adminHandler:               adminHandler,
+		tokenSvc:                   tokenSvc,
+		userRepo:                   userRepo,
+		requireVerifiedEmail:       requireVerifiedEmail,
+		rateLimits:                 rateLimits,
 	}
 }
 
@@ -90,11 +116,14 @@ func (r *Router) Setup(app *fiber.App) {
 
 	// Middleware
 	authMiddleware := middleware.Auth(r.tokenSvc)
-	authRateLimiter := middleware.AuthRateLimiter()
-	passwordRateLimiter := middleware.PasswordResetRateLimiter()
-	apiRateLimiter := middleware.APIRateLimiter()
-	uploadRateLimiter := middleware.UploadRateLimiter()
-	autocompleteRateLimiter := middleware.AutocompleteRateLimiter()
+	adminMiddleware := middleware.RequireAdmin(r.userRepo)
+	// Gates actions that reach other users; a no-op unless REQUIRE_VERIFIED_EMAIL is set
+	verifiedEmail := middleware.RequireVerifiedEmail(r.userRepo, r.requireVerifiedEmail)
+	authRateLimiter := middleware.AuthRateLimiter(r.rateLimits.Auth)
+	passwordRateLimiter := middleware.PasswordResetRateLimiter(r.rateLimits.Password)
+	apiRateLimiter := middleware.APIRateLimiter(r.rateLimits.API)
+	uploadRateLimiter := middleware.UploadRateLimiter(r.rateLimits.Upload)
+	autocompleteRateLimiter := middleware.AutocompleteRateLimiter(r.rateLimits.Autocomplete)
 
 	// API group - all routes under /api prefix
 	api := app.Group("/api")
@@ -115,6 +144,21 @@ func (r *Router) Setup(app *fiber.App) {
 	auth.Post("/verify-email", r.verificationHandler.VerifyEmail)
 	auth.Post("/resend-verification", authMiddleware, authRateLimiter, r.verificationHandler.ResendVerificationEmail)
 
+	// Token refresh and logout (refresh tokens are rotated on every use)
+	auth.Post("/refresh", authRateLimiter, r.authHandler.Refresh)
+	auth.Post("/logout", authRateLimiter, r.authHandler.Logout)
+	auth.Post("/logout-all", authMiddleware, authRateLimiter, r.authHandler.LogoutAll)
+
+	// Two-factor authentication (TOTP)
+	auth.Get("/2fa", authMiddleware, apiRateLimiter, r.twoFactorHandler.GetStatus)
+	auth.Post("/2fa/enroll", authMiddleware, authRateLimiter, r.twoFactorHandler.Enroll)
+	auth.Post("/2fa/verify", authMiddleware, authRateLimiter, r.twoFactorHandler.ConfirmEnrollment)
+	auth.Post("/2fa/disable", authMiddleware, authRateLimiter, r.twoFactorHandler.Disable)
+
+	// Email Change (confirmation link goes to the new address)
+	auth.Post("/change-email", authMiddleware, authRateLimiter, r.authHandler.ChangeEmail)
+	auth.Post("/confirm-email-change", authRateLimiter, r.authHandler.ConfirmEmailChange)
+
 	// ==================== Protected Routes ====================
 	// All protected routes have: auth middleware + API rate limiter (100 req/min)
 
@@ -127,6 +171,7 @@ func (r *Router) Setup(app *fiber.App) {
 	// ==================== Search Suggestions ====================
 	// Get recent + trending suggestions (called on search focus)
 	api.Get("/search/suggestions", authMiddleware, autocompleteRateLimiter, r.searchSuggestionsHandler.GetSearchSuggestions)
+	api.Get("/me/suggestions/mutual-based", authMiddleware, apiRateLimiter, r.searchSuggestionsHandler.GetMutualSuggestions)
 	// Delete a specific recent search
 	api.Delete("/search/recent/:userId", authMiddleware, apiRateLimiter, r.searchSuggestionsHandler.DeleteRecentSearch)
 	// Clear all recent searches
@@ -134,19 +179,34 @@ func (r *Router) Setup(app *fiber.App) {
 
 	// Activities
 	api.Post("/create-activity", authMiddleware, apiRateLimiter, r.activityHandler.CreateActivity)
+	api.Post("/me/activities/bulk", authMiddleware, apiRateLimiter, r.activityHandler.BulkLogActivities)
+	api.Delete("/me/activities", authMiddleware, apiRateLimiter, r.activityHandler.DeleteActivity)
 	api.Post("/get-activities", authMiddleware, apiRateLimiter, r.activityHandler.GetActivities)
 	api.Post("/get-daily-totals", authMiddleware, apiRateLimiter, r.activityHandler.GetDailyTotals)
+	api.Get("/me/activities/export", authMiddleware, apiRateLimiter, r.activityHandler.ExportActivities)
+	api.Get("/me/activities/search", authMiddleware, apiRateLimiter, r.activityHandler.SearchActivityNotes)
+	api.Get("/me/export", authMiddleware, apiRateLimiter, r.exportHandler.ExportAccount)
 
 	// Streaks
 	api.Post("/get-streak", authMiddleware, apiRateLimiter, r.streakHandler.GetStreak)
+	api.Get("/streak-freezes", authMiddleware, apiRateLimiter, r.streakHandler.GetStreakFreezes)
+	api.Get("/streaks/by-activity", authMiddleware, apiRateLimiter, r.streakHandler.GetActivityStreaks)
+	api.Get("/streaks/series", authMiddleware, apiRateLimiter, r.streakHandler.GetStreakSeries)
+	api.Get("/streaks/leaderboard", authMiddleware, apiRateLimiter, r.streakHandler.GetFollowingLeaderboard)
 
 	// Analytics
 	api.Post("/get-week-analytics", authMiddleware, apiRateLimiter, r.analyticsHandler.GetWeekAnalytics)
+	api.Post("/get-month-analytics", authMiddleware, apiRateLimiter, r.analyticsHandler.GetMonthAnalytics)
+	api.Post("/get-year-analytics", authMiddleware, apiRateLimiter, r.analyticsHandler.GetYearAnalytics)
+	api.Post("/get-weekday-analytics", authMiddleware, apiRateLimiter, r.analyticsHandler.GetWeekdayAnalytics)
+	api.Get("/trending/activities", authMiddleware, apiRateLimiter, r.analyticsHandler.GetTrendingActivities)
 
 	// Tile Configuration
 	api.Get("/tile-config", authMiddleware, apiRateLimiter, r.tileConfigHandler.GetConfig)
 	api.Post("/tile-config", authMiddleware, apiRateLimiter, r.tileConfigHandler.SaveConfig)
 	api.Post("/tile-config/user", authMiddleware, apiRateLimiter, r.tileConfigHandler.GetConfigByUsername)
+	api.Patch("/me/tiles/order", authMiddleware, apiRateLimiter, r.tileConfigHandler.ReorderTiles)
+	api.Delete("/me/tiles/custom/:id", authMiddleware, apiRateLimiter, r.tileConfigHandler.DeleteCustomTile)
 
 	// Likes
 	api.Post("/like-day", authMiddleware, apiRateLimiter, r.likeHandler.LikeDay)
@@ -161,9 +221,21 @@ func (r *Router) Setup(app *fiber.App) {
 	api.Post("/update-username", authMiddleware, apiRateLimiter, r.authHandler.UpdateUsername)
 	api.Post("/update-privacy", authMiddleware, apiRateLimiter, r.profileHandler.UpdatePrivacy)
 	api.Get("/get-privacy", authMiddleware, apiRateLimiter, r.profileHandler.GetPrivacy)
+	api.Post("/update-timezone", authMiddleware, apiRateLimiter, r.profileHandler.UpdateTimezone)
+	api.Get("/get-timezone", authMiddleware, apiRateLimiter, r.profileHandler.GetTimezone)
+	api.Get("/me/weekly-digest", authMiddleware, apiRateLimiter, r.profileHandler.GetWeeklyDigest)
+	api.Put("/me/weekly-digest", authMiddleware, apiRateLimiter, r.profileHandler.UpdateWeeklyDigest)
+	api.Get("/me/profile-viewers", authMiddleware, apiRateLimiter, r.profileHandler.GetProfileViewers)
+	api.Get("/me/profile-view-privacy", authMiddleware, apiRateLimiter, r.profileHandler.GetProfileViewPrivacy)
+	api.Put("/me/profile-view-privacy", authMiddleware, apiRateLimiter, r.profileHandler.UpdateProfileViewPrivacy)
 	api.Post("/update-bio", authMiddleware, apiRateLimiter, r.profileHandler.UpdateBio)
 	api.Get("/get-bio", authMiddleware, apiRateLimiter, r.profileHandler.GetBio)
 	api.Post("/change-password", authMiddleware, authRateLimiter, r.authHandler.ChangePassword) // Strict rate limit for password change
+	api.Delete("/me/account", authMiddleware, authRateLimiter, r.authHandler.DeleteAccount)
+	api.Post("/me/deactivate", authMiddleware, authRateLimiter, r.authHandler.DeactivateAccount)
+	api.Post("/me/reactivate", authMiddleware, apiRateLimiter, r.authHandler.ReactivateAccount)
+	api.Get("/me/sessions", authMiddleware, apiRateLimiter, r.sessionHandler.ListSessions)
+	api.Delete("/me/sessions/:id", authMiddleware, authRateLimiter, r.sessionHandler.RevokeSession)
 
 	// Profile Picture (with upload-specific rate limiting)
 	profile := api.Group("/profile", authMiddleware)
@@ -178,6 +250,7 @@ func (r *Router) Setup(app *fiber.App) {
 	notifications.Get("", r.notificationHandler.GetNotifications)
 	notifications.Get("/unread-count", r.notificationHandler.GetUnreadCount)
 	notifications.Patch("/:id/read", r.notificationHandler.MarkAsRead)
+	notifications.Post("/:id/unread", r.notificationHandler.MarkAsUnread)
 	notifications.Patch("/read-all", r.notificationHandler.MarkAllAsRead)
 	notifications.Delete("/:id", r.notificationHandler.DeleteNotification)
 
@@ -190,22 +263,36 @@ func (r *Router) Setup(app *fiber.App) {
 	push.Delete("/subscriptions", authMiddleware, apiRateLimiter, r.pushHandler.UnregisterSubscription)
 	push.Get("/preferences", authMiddleware, apiRateLimiter, r.pushHandler.GetPreferences)
 	push.Put("/preferences", authMiddleware, apiRateLimiter, r.pushHandler.UpdatePreferences)
+	push.Post("/test", authMiddleware, middleware.PushTestRateLimiter(r.rateLimits.PushTest), r.pushHandler.SendTestPush)
+	api.Get("/me/push/stats", authMiddleware, apiRateLimiter, r.pushHandler.GetDeliveryStats)
 	// Admin/maintenance endpoint - cleanup stale data
-	push.Post("/cleanup", authMiddleware, r.pushHandler.RunCleanup)
+	push.Post("/cleanup", authMiddleware, adminMiddleware, r.pushHandler.RunCleanup)
 
 	// ==================== Follow System ====================
-	followRateLimiter := middleware.FollowRateLimiter()
+	followRateLimiter := middleware.FollowRateLimiter(r.rateLimits.Follow)
 
 	// Follow/Unfollow actions
-	api.Post("/users/:targetId/follow", authMiddleware, followRateLimiter, r.followHandler.FollowUser)
+	api.Post("/users/:targetId/follow", authMiddleware, followRateLimiter, verifiedEmail, r.followHandler.FollowUser)
 	api.Delete("/users/:targetId/follow", authMiddleware, followRateLimiter, r.followHandler.UnfollowUser)
 
 	// Follow request management
 	api.Post("/follow-requests/:targetId/cancel", authMiddleware, apiRateLimiter, r.followHandler.CancelFollowRequest)
 	api.Get("/me/follow-requests/incoming", authMiddleware, apiRateLimiter, r.followHandler.GetIncomingRequests)
+	api.Get("/me/follow-requests/outgoing", authMiddleware, apiRateLimiter, r.followHandler.GetOutgoingRequests)
+	api.Post("/me/follow-requests/batch", authMiddleware, followRateLimiter, verifiedEmail, r.followHandler.BatchFollowRequests)
+	api.Post("/me/follow-requests/outgoing/cancel-all", authMiddleware, followRateLimiter, r.followHandler.CancelAllFollowRequests)
 	api.Post("/me/follow-requests/:requesterId/accept", authMiddleware, apiRateLimiter, r.followHandler.AcceptFollowRequest)
 	api.Post("/me/follow-requests/:requesterId/decline", authMiddleware, apiRateLimiter, r.followHandler.DeclineFollowRequest)
 
+	// Blocking
+	api.Post("/users/:targetId/block", authMiddleware, followRateLimiter, r.followHandler.BlockUser)
+	api.Delete("/users/:targetId/block", authMiddleware, followRateLimiter, r.followHandler.UnblockUser)
+	api.Get("/me/blocked", authMiddleware, apiRateLimiter, r.followHandler.GetBlockedUsers)
+
+	// Close friends
+	api.Post("/me/close-friends/:userId", authMiddleware, followRateLimiter, r.followHandler.AddCloseFriend)
+	api.Delete("/me/close-friends/:userId", authMiddleware, followRateLimiter, r.followHandler.RemoveCloseFriend)
+
 	// Follower management
 	api.Delete("/me/followers/:followerId", authMiddleware, apiRateLimiter, r.followHandler.RemoveFollower)
 
@@ -213,9 +300,11 @@ func (r *Router) Setup(app *fiber.App) {
 	api.Get("/users/:userId/followers", authMiddleware, apiRateLimiter, r.followHandler.GetFollowers)
 	api.Get("/users/:userId/following", authMiddleware, apiRateLimiter, r.followHandler.GetFollowing)
 	api.Get("/users/:userId/mutuals", authMiddleware, apiRateLimiter, r.followHandler.GetMutuals)
+	api.Get("/users/:userId/followed-by", authMiddleware, apiRateLimiter, r.followHandler.GetFollowedBy)
 	api.Get("/users/:userId/follow-counts", authMiddleware, apiRateLimiter, r.followHandler.GetFollowCounts)
 	api.Post("/me/follow-counts/reconcile", authMiddleware, apiRateLimiter, r.followHandler.ReconcileMyCounters)
 	api.Get("/users/:userId/profile", authMiddleware, apiRateLimiter, r.profileHandler.GetUserProfile)
+	api.Get("/users/:userId/badges", authMiddleware, apiRateLimiter, r.badgeHandler.GetUserBadges)
 
 	// Relationship lookup (batch) - no rate limit, read-only and needed frequently for UI
 	api.Post("/relationships/lookup", authMiddleware, r.followHandler.LookupRelationships)
@@ -223,22 +312,35 @@ func (r *Router) Setup(app *fiber.App) {
 	// ==================== Activity Photos (Stories) ====================
 	if r.activityPhotoHandler != nil {
 		// Upload photo (with upload-specific rate limiting)
-		api.Post("/activity-photo", authMiddleware, uploadRateLimiter, r.activityPhotoHandler.UploadPhoto)
+		api.Post("/activity-photo", authMiddleware, uploadRateLimiter, verifiedEmail, r.activityPhotoHandler.UploadPhoto)
 		// Delete photo
 		api.Delete("/activity-photo/:id", authMiddleware, apiRateLimiter, r.activityPhotoHandler.DeletePhoto)
+		// Delete the whole story history of the current user
+		api.Delete("/me/stories", authMiddleware, apiRateLimiter, r.activityPhotoHandler.DeleteAllPhotos)
 		// Get photos for a user on a date
 		api.Get("/activity-photos", authMiddleware, apiRateLimiter, r.activityPhotoHandler.GetPhotos)
 		// Get stories from followed users
 		api.Get("/activity-photos/following", authMiddleware, apiRateLimiter, r.activityPhotoHandler.GetFollowingStories)
+		// Get own story archive across a date range
+		api.Get("/activity-photos/archive", authMiddleware, apiRateLimiter, r.activityPhotoHandler.GetStoryArchive)
 		// Record photo view
 		api.Post("/activity-photo/:id/view", authMiddleware, apiRateLimiter, r.activityPhotoHandler.RecordView)
 		// Get photo viewers (owner only)
 		api.Get("/activity-photo/:id/viewers", authMiddleware, apiRateLimiter, r.activityPhotoHandler.GetPhotoViewers)
+		// Get followers who haven't viewed a photo (owner only)
+		api.Get("/activity-photo/:id/non-viewers", authMiddleware, apiRateLimiter, r.activityPhotoHandler.GetPhotoNonViewers)
+		// Get users who reacted to a photo (owner only)
+		api.Get("/activity-photo/:id/likers", authMiddleware, apiRateLimiter, r.activityPhotoHandler.GetPhotoLikers)
 		// Like/unlike photo
 		api.Post("/activity-photo/:id/like", authMiddleware, apiRateLimiter, r.activityPhotoHandler.LikePhoto)
 		api.Delete("/activity-photo/:id/like", authMiddleware, apiRateLimiter, r.activityPhotoHandler.UnlikePhoto)
+		// Reply to a story (DM-style, notifies owner)
+		api.Post("/activity-photo/:id/reply", authMiddleware, apiRateLimiter, verifiedEmail, r.activityPhotoHandler.ReplyToPhoto)
 		// Get like status (liked + count)
 		api.Get("/activity-photo/:id/like-status", authMiddleware, apiRateLimiter, r.activityPhotoHandler.GetPhotoLikeStatus)
+		// Story ghost mode (stop recording own views)
+		api.Get("/me/ghost-mode", authMiddleware, apiRateLimiter, r.activityPhotoHandler.GetGhostMode)
+		api.Put("/me/ghost-mode", authMiddleware, apiRateLimiter, r.activityPhotoHandler.UpdateGhostMode)
 		// Get combined interactions (views + likes) for owner
 		api.Get("/activity-photo/:id/interactions", authMiddleware, apiRateLimiter, r.activityPhotoHandler.GetPhotoInteractions)
 	}
@@ -250,12 +352,12 @@ func (r *Router) Setup(app *fiber.App) {
 	api.Get("/ws/notifications", r.notificationWSHandler.HandleConnection())
 
 	// ==================== Day Comments ====================
-	commentRateLimiter := middleware.CommentRateLimiter()
-	commentLikeRateLimiter := middleware.CommentLikeRateLimiter()
+	commentRateLimiter := middleware.CommentRateLimiter(r.rateLimits.Comment)
+	commentLikeRateLimiter := middleware.CommentLikeRateLimiter(r.rateLimits.CommentLike)
 
 	// Day comment CRUD
-	api.Post("/days/:username/:date/comments", authMiddleware, commentRateLimiter, r.commentHandler.CreateComment)
-	api.Post("/days/:username/:date/comments/:commentId/replies", authMiddleware, commentRateLimiter, r.commentHandler.CreateReply)
+	api.Post("/days/:username/:date/comments", authMiddleware, commentRateLimiter, verifiedEmail, r.commentHandler.CreateComment)
+	api.Post("/days/:username/:date/comments/:commentId/replies", authMiddleware, commentRateLimiter, verifiedEmail, r.commentHandler.CreateReply)
 	api.Get("/days/:username/:date/comments", authMiddleware, apiRateLimiter, r.commentHandler.GetComments)
 	api.Get("/days/:username/:date/comments/count", authMiddleware, apiRateLimiter, r.commentHandler.GetCommentCount)
 
@@ -265,4 +367,15 @@ func (r *Router) Setup(app *fiber.App) {
 	api.Post("/comments/:commentId/like", authMiddleware, commentLikeRateLimiter, r.commentHandler.LikeComment)
 	api.Delete("/comments/:commentId/like", authMiddleware, commentLikeRateLimiter, r.commentHandler.UnlikeComment)
 	api.Get("/comments/:commentId/replies", authMiddleware, apiRateLimiter, r.commentHandler.GetReplies)
+
+	// ==================== Verification Requests ====================
+	api.Post("/me/verification-request", authMiddleware, apiRateLimiter, r.verificationRequestHandler.SubmitRequest)
+	api.Get("/me/verification-request", authMiddleware, apiRateLimiter, r.verificationRequestHandler.GetMyRequest)
+
+	// ==================== Admin ====================
+	admin := api.Group("/admin", authMiddleware, apiRateLimiter, adminMiddleware)
+	admin.Get("/verification-requests", r.verificationRequestHandler.ListPending)
+	admin.Post("/verification-requests/:id/review", r.verificationRequestHandler.ReviewRequest)
+	admin.Get("/cron-logs", r.adminHandler.GetCronLogs)
+	admin.Post("/cron/:job/run", r.adminHandler.RunCronJob)
 }
@@ -3,15 +3,24 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"io"
 	"mime/multipart"
+	"net"
+	"net/http"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode/utf8"
 
+	"github.com/Azure/azure-sdk-for-go/sdk/azcore"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
 	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/service"
 	"github.com/aman1117/backend/internal/config"
 	"github.com/aman1117/backend/internal/constants"
 	"github.com/aman1117/backend/internal/logger"
@@ -19,6 +28,7 @@ import (
 	"github.com/aman1117/backend/pkg/models"
 	"github.com/aman1117/backend/pkg/redis"
 	"github.com/google/uuid"
+	"golang.org/x/time/rate"
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -46,13 +56,36 @@ type ActivityPhotoService struct {
 	container       string
 	accountName     string
 
+	// Lifetime of the read-only SAS URLs returned to viewers; 0 returns plain URLs (see signBlobURL)
+	sasExpiry time.Duration
+
+	// Largest accepted upload file size in bytes
+	maxUploadBytes int64
+
+	// Story windows in IST calendar days (see storyCutoff)
+	uploadWindowDays int
+	visibleDays      int
+
+	// Per-user upload throttle (token bucket of uploadsPerHour, refilled evenly)
+	uploadsPerHour int
+	uploadLimiters map[uint]*rate.Limiter
+	uploadMu       sync.Mutex
+
 	// Debounce notification state
-	pendingNotifications map[uint]*pendingPhotoNotification
+	pendingNotifications map[photoNotificationKey]*pendingPhotoNotification
 	notificationMutex    sync.Mutex
 }
 
+// photoNotificationKey debounces uploads per uploader and audience, so close-friends-only
+// photos are never counted into, or announced with, a notification to all followers
+type photoNotificationKey struct {
+	uploaderID   uint
+	closeFriends bool
+}
+
 // pendingPhotoNotification tracks photos uploaded within the debounce window
 type pendingPhotoNotification struct {
+	key              photoNotificationKey
 	uploaderID       uint
 	uploaderUsername string
 	uploaderAvatar   string
@@ -68,6 +101,7 @@ func NewActivityPhotoService(
 	followRepo *repository.FollowRepository,
 	notificationSvc *NotificationService,
 	cfg *config.AzureStorageConfig,
+	storyCfg *config.StoryConfig,
 ) (*ActivityPhotoService, error) {
 	svc := &ActivityPhotoService{
 		repo:                 repo,
@@ -77,7 +111,29 @@ func NewActivityPhotoService(
 		imageProcessor:       NewImageProcessor(),
 		container:            cfg.ContainerName,
 		accountName:          cfg.AccountName,
-		pendingNotifications: make(map[uint]*pendingPhotoNotification),
+		pendingNotifications: make(map[photoNotificationKey]*pendingPhotoNotification),
+		uploadWindowDays:     constants.DefaultStoryUploadWindowDays,
+		visibleDays:          constants.DefaultStoryVisibleDays,
+		uploadsPerHour:       constants.DefaultStoryUploadsPerHour,
+		uploadLimiters:       make(map[uint]*rate.Limiter),
+		maxUploadBytes:       constants.MaxStoryPhotoSize,
+	}
+
+	if storyCfg != nil {
+		if storyCfg.UploadWindowDays > 0 {
+			svc.uploadWindowDays = storyCfg.UploadWindowDays
+		}
+		if storyCfg.VisibleDays > 0 {
+			svc.visibleDays = storyCfg.VisibleDays
+		}
+		if storyCfg.UploadsPerHour > 0 {
+			svc.uploadsPerHour = storyCfg.UploadsPerHour
+		}
+		svc.imageProcessor.outputFormat = ParseImageFormat(storyCfg.ImageFormat)
+		if storyCfg.ImageLimit.MaxBytes > 0 {
+			svc.maxUploadBytes = storyCfg.ImageLimit.MaxBytes
+		}
+		svc.imageProcessor.WithMaxDimensions(storyCfg.ImageLimit.MaxWidth, storyCfg.ImageLimit.MaxHeight)
 	}
 
 	if cfg.ConnectionString != "" {
@@ -86,11 +142,57 @@ func NewActivityPhotoService(
 			return nil, fmt.Errorf("failed to create blob client: %w", err)
 		}
 		svc.blobClient = client
+
+		if cfg.SASExpiry > 0 {
+			// Signing is local, so a probe tells us up front whether the credential has an account key
+			if _, err := svc.blobSASClient(constants.ActivityPhotoBlobPrefix).GetSASURL(sas.BlobPermissions{Read: true}, time.Now().Add(cfg.SASExpiry), nil); err != nil {
+				logger.Sugar.Warnw("Story photo SAS URLs disabled, storage credential cannot sign", "error", err)
+			} else {
+				svc.sasExpiry = cfg.SASExpiry
+			}
+		}
 	}
 
 	return svc, nil
 }
 
+// ErrUploadRateLimited is returned when a user exceeds their hourly photo upload budget
+var ErrUploadRateLimited = errors.New("upload rate limit exceeded")
+
+// ErrStorageUnavailable is returned when blob storage is not configured or an upload fails after retries
+var ErrStorageUnavailable = errors.New("photo storage is unavailable")
+
+// ErrInvalidImage is returned when the uploaded file cannot be decoded or processed as an image
+var ErrInvalidImage = errors.New("failed to process image")
+
+// reserveUpload takes one token from the user's upload bucket, returning the reservation
+// so the token can be refunded if the upload is rejected during validation.
+// Buckets are per process; idle full buckets are dropped so the map stays small.
+func (s *ActivityPhotoService) reserveUpload(userID uint) (*rate.Reservation, bool) {
+	s.uploadMu.Lock()
+	defer s.uploadMu.Unlock()
+
+	limiter, ok := s.uploadLimiters[userID]
+	if !ok {
+		if len(s.uploadLimiters) >= constants.StoryUploadLimiterPruneSize {
+			for id, l := range s.uploadLimiters {
+				if l.Tokens() >= float64(l.Burst()) {
+					delete(s.uploadLimiters, id)
+				}
+			}
+		}
+		limiter = rate.NewLimiter(rate.Every(time.Hour/time.Duration(s.uploadsPerHour)), s.uploadsPerHour)
+		s.uploadLimiters[userID] = limiter
+	}
+
+	reservation := limiter.Reserve()
+	if reservation.Delay() > 0 {
+		reservation.Cancel()
+		return nil, false
+	}
+	return reservation, true
+}
+
 // Upload uploads a new activity photo
 func (s *ActivityPhotoService) Upload(
 	ctx context.Context,
@@ -102,15 +204,36 @@ func (s *ActivityPhotoService) Upload(
 	activityIcon string,
 	activityColor string,
 	activityLabel string,
+	visibility models.PhotoVisibility,
+	replace bool,
 ) (*models.ActivityPhoto, error) {
-	// Validate date is within 7 days (IST)
+	if visibility == "" {
+		visibility = models.PhotoVisibilityAllFollowers
+	}
+	if !visibility.IsValid() {
+		return nil, fmt.Errorf("invalid visibility, use all_followers or close_friends")
+	}
+
+	// Validate date is within the upload window (IST)
 	if err := s.validatePhotoDate(photoDate); err != nil {
 		return nil, err
 	}
 
-	// Validate file size (5MB max)
-	if fileHeader.Size > constants.MaxProfilePicSize {
-		return nil, fmt.Errorf("image size must be less than 5MB")
+	reservation, ok := s.reserveUpload(userID)
+	if !ok {
+		return nil, ErrUploadRateLimited
+	}
+	// Only uploads that pass validation count against the hourly budget
+	validated := false
+	defer func() {
+		if !validated {
+			reservation.Cancel()
+		}
+	}()
+
+	// Validate file size
+	if fileHeader.Size > s.maxUploadBytes {
+		return nil, fmt.Errorf("%w: the maximum file size is %s", ErrImageTooLarge, formatMB(s.maxUploadBytes))
 	}
 
 	// Check for existing photo
@@ -118,26 +241,30 @@ func (s *ActivityPhotoService) Upload(
 	if err != nil {
 		return nil, fmt.Errorf("failed to check existing photo: %w", err)
 	}
-	if existing != nil {
+	if existing != nil && !replace {
 		return nil, fmt.Errorf("photo already exists for this activity on this date")
 	}
 
 	// Process image (validate, resize, generate thumbnail)
 	processed, err := s.imageProcessor.Process(file, fileHeader)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process image: %w", err)
+		if errors.Is(err, ErrImageTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImage, err)
 	}
+	validated = true
 
 	// Generate blob names
 	dateStr := photoDate.Format("2006-01-02")
 	photoUUID := uuid.New().String()
-	basePath := fmt.Sprintf("activity-photos/%d/%s/%s", userID, dateStr, activityName)
-	fullBlobName := fmt.Sprintf("%s/%s.jpg", basePath, photoUUID)
-	thumbBlobName := fmt.Sprintf("%s/%s_thumb.jpg", basePath, photoUUID)
+	basePath := fmt.Sprintf("%s%d/%s/%s", constants.ActivityPhotoBlobPrefix, userID, dateStr, activityName)
+	fullBlobName := fmt.Sprintf("%s/%s%s", basePath, photoUUID, processed.Extension)
+	thumbBlobName := fmt.Sprintf("%s/%s_thumb%s", basePath, photoUUID, processed.Extension)
 
 	// Upload to Azure Blob Storage
 	if s.blobClient == nil {
-		return nil, fmt.Errorf("blob storage is not configured")
+		return nil, fmt.Errorf("%w: blob storage is not configured", ErrStorageUnavailable)
 	}
 
 	// Upload full image
@@ -161,6 +288,8 @@ func (s *ActivityPhotoService) Upload(
 		PhotoDate:    photoDate,
 		PhotoURL:     fullURL,
 		ThumbnailURL: thumbURL,
+		Visibility:   visibility,
+		MimeType:     processed.MimeType,
 	}
 
 	// Store custom tile metadata if provided (for custom activities)
@@ -174,6 +303,49 @@ func (s *ActivityPhotoService) Upload(
 		photo.ActivityLabel = &activityLabel
 	}
 
+	if existing != nil {
+		// Replace: keep the existing tile metadata unless new values were provided
+		if photo.ActivityIcon == nil {
+			photo.ActivityIcon = existing.ActivityIcon
+		}
+		if photo.ActivityColor == nil {
+			photo.ActivityColor = existing.ActivityColor
+		}
+		if photo.ActivityLabel == nil {
+			photo.ActivityLabel = existing.ActivityLabel
+		}
+
+		if err := s.repo.Replace(existing.ID, photo); err != nil {
+			// Clean up new blobs on DB failure; the old photo is left untouched
+			s.deleteBlob(ctx, fullBlobName)
+			s.deleteBlob(ctx, thumbBlobName)
+			return nil, fmt.Errorf("failed to replace photo: %w", err)
+		}
+
+		// Remove old blobs and like caches now that the new photo is saved
+		if oldFull := s.extractBlobName(existing.PhotoURL); oldFull != "" {
+			s.deleteBlob(ctx, oldFull)
+		}
+		if oldThumb := s.extractBlobName(existing.ThumbnailURL); oldThumb != "" {
+			s.deleteBlob(ctx, oldThumb)
+		}
+		if err := redis.InvalidateStoryLikeCount(ctx, existing.ID); err != nil {
+			logger.Sugar.Warnw("Failed to invalidate like count cache", "photo_id", existing.ID, "error", err)
+		}
+
+		// No follower notification on replace - the story was already announced
+		logger.Sugar.Infow("Activity photo replaced",
+			"user_id", userID,
+			"activity_name", activityName,
+			"photo_date", dateStr,
+			"old_photo_id", existing.ID,
+			"photo_id", photo.ID,
+		)
+
+		s.signPhoto(photo)
+		return photo, nil
+	}
+
 	if err := s.repo.Create(photo); err != nil {
 		// Clean up blobs on DB failure
 		s.deleteBlob(ctx, fullBlobName)
@@ -182,7 +354,7 @@ func (s *ActivityPhotoService) Upload(
 	}
 
 	// Trigger debounced notification to followers
-	go s.scheduleNotification(ctx, userID, dateStr)
+	go s.scheduleNotification(ctx, userID, dateStr, photo.Visibility)
 
 	logger.Sugar.Infow("Activity photo uploaded",
 		"user_id", userID,
@@ -191,26 +363,54 @@ func (s *ActivityPhotoService) Upload(
 		"photo_id", photo.ID,
 	)
 
+	s.signPhoto(photo)
 	return photo, nil
 }
 
-// validatePhotoDate checks if the photo date is within 7 days (IST timezone)
-func (s *ActivityPhotoService) validatePhotoDate(photoDate time.Time) error {
+// ==================== Story Windows (IST) ====================
+//
+// Photo dates are calendar dates ("2006-01-02") parsed as UTC midnight. They are compared by
+// their Y/M/D fields only - never converted with In(), which would shift the date.
+// "Today" is the current IST calendar date, so the window rolls over at IST midnight,
+// not UTC midnight (18:30 UTC the previous day).
+//
+// A window of N days allows photo dates in [today-N, today]; with N=7 a photo dated last
+// Monday is still allowed all day on the following Monday (IST) and rejected from 00:00 IST
+// Tuesday. Dates after today are always rejected.
+
+// istToday returns midnight of the current IST calendar date
+func istToday() time.Time {
 	now := time.Now().In(istLocation)
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, istLocation)
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, istLocation)
+}
+
+// istDate returns midnight IST for a photo date's calendar Y/M/D
+func istDate(photoDate time.Time) time.Time {
+	return time.Date(photoDate.Year(), photoDate.Month(), photoDate.Day(), 0, 0, 0, 0, istLocation)
+}
 
-	// Photo date should be normalized to IST midnight
-	photoDateIST := time.Date(photoDate.Year(), photoDate.Month(), photoDate.Day(), 0, 0, 0, 0, istLocation)
+// storyCutoff returns the earliest IST calendar date inside a window of the given days
+func storyCutoff(days int) time.Time {
+	return istToday().AddDate(0, 0, -days)
+}
+
+// isStoryVisible reports whether a photo date is still within the visibility window for non-owners
+func (s *ActivityPhotoService) isStoryVisible(photoDate time.Time) bool {
+	return !istDate(photoDate).Before(storyCutoff(s.visibleDays))
+}
+
+// validatePhotoDate checks if the photo date is within the upload window (IST timezone)
+func (s *ActivityPhotoService) validatePhotoDate(photoDate time.Time) error {
+	photoDateIST := istDate(photoDate)
 
 	// Cannot be in the future
-	if photoDateIST.After(today) {
+	if photoDateIST.After(istToday()) {
 		return fmt.Errorf("cannot upload photo for future date")
 	}
 
-	// Must be within last 7 days
-	sevenDaysAgo := today.AddDate(0, 0, -7)
-	if photoDateIST.Before(sevenDaysAgo) {
-		return fmt.Errorf("can only upload photos for the last 7 days")
+	// Must be within the upload window
+	if photoDateIST.Before(storyCutoff(s.uploadWindowDays)) {
+		return fmt.Errorf("can only upload photos for the last %d days", s.uploadWindowDays)
 	}
 
 	return nil
@@ -218,19 +418,83 @@ func (s *ActivityPhotoService) validatePhotoDate(photoDate time.Time) error {
 
 // uploadBlob uploads data to Azure Blob Storage and returns the URL
 func (s *ActivityPhotoService) uploadBlob(ctx context.Context, blobName string, data io.Reader, contentType string) (string, error) {
-	uploadCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	if err := uploadBlobWithRetry(ctx, s.blobClient, s.container, blobName, data, contentType); err != nil {
+		return "", err
+	}
+	return s.generateBlobURL(blobName), nil
+}
+
+// uploadBlobWithRetry uploads data to a blob in containerName.
+// Transient failures (timeouts, throttling, 5xx, network errors) are retried with exponential
+// backoff when data can be rewound; any final failure wraps ErrStorageUnavailable.
+func uploadBlobWithRetry(ctx context.Context, client *azblob.Client, containerName, blobName string, data io.Reader, contentType string) error {
+	attempts := constants.BlobUploadMaxAttempts
+	seeker, seekable := data.(io.ReadSeeker)
+	if !seekable {
+		attempts = 1
+	}
+
+	delay := constants.BlobUploadRetryBaseDelay
+	var err error
+	for attempt := 1; attempt <= attempts; attempt++ {
+		if attempt > 1 {
+			select {
+			case <-ctx.Done():
+				return fmt.Errorf("%w: %w", ErrStorageUnavailable, ctx.Err())
+			case <-time.After(delay):
+			}
+			delay *= 2
+
+			if _, seekErr := seeker.Seek(0, io.SeekStart); seekErr != nil {
+				break
+			}
+		}
+
+		if err = putBlob(ctx, client, containerName, blobName, data, contentType); err == nil {
+			return nil
+		}
+		if ctx.Err() != nil || !isTransientStorageError(err) {
+			break
+		}
+		logger.Sugar.Warnw("Blob upload failed, retrying",
+			"blob_name", blobName,
+			"attempt", attempt,
+			"max_attempts", attempts,
+			"error", err,
+		)
+	}
+
+	return fmt.Errorf("%w: %w", ErrStorageUnavailable, err)
+}
+
+// putBlob makes a single upload attempt bounded by BlobUploadAttemptTimeout
+func putBlob(ctx context.Context, client *azblob.Client, containerName, blobName string, data io.Reader, contentType string) error {
+	uploadCtx, cancel := context.WithTimeout(ctx, constants.BlobUploadAttemptTimeout)
 	defer cancel()
 
-	_, err := s.blobClient.UploadStream(uploadCtx, s.container, blobName, data, &azblob.UploadStreamOptions{
+	_, err := client.UploadStream(uploadCtx, containerName, blobName, data, &azblob.UploadStreamOptions{
 		HTTPHeaders: &blob.HTTPHeaders{
 			BlobContentType: &contentType,
 		},
 	})
-	if err != nil {
-		return "", err
+	return err
+}
+
+// isTransientStorageError reports whether a failed upload is worth retrying
+func isTransientStorageError(err error) bool {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return true
 	}
 
-	return s.generateBlobURL(blobName), nil
+	var respErr *azcore.ResponseError
+	if errors.As(err, &respErr) {
+		return respErr.StatusCode >= http.StatusInternalServerError ||
+			respErr.StatusCode == http.StatusTooManyRequests ||
+			respErr.StatusCode == http.StatusRequestTimeout
+	}
+
+	var netErr net.Error
+	return errors.As(err, &netErr)
 }
 
 // deleteBlob removes a blob from storage
@@ -246,22 +510,260 @@ func (s *ActivityPhotoService) deleteBlob(ctx context.Context, blobName string)
 
 // generateBlobURL creates the public URL for a blob
 func (s *ActivityPhotoService) generateBlobURL(blobName string) string {
+	return blobURL(s.accountName, s.container, blobName)
+}
+
+// extractBlobName extracts blob name from URL
+func (s *ActivityPhotoService) extractBlobName(url string) string {
+	return blobNameFromURL(url, s.container)
+}
+
+// blobURL creates the public URL for a blob.
+// Uses the Azurite endpoint in development, Azure in production.
+func blobURL(accountName, containerName, blobName string) string {
 	cfg := config.AppConfig
 	if cfg != nil && cfg.IsDevelopment() {
-		return fmt.Sprintf("http://localhost:10000/devstoreaccount1/%s/%s", s.container, blobName)
+		return fmt.Sprintf("http://localhost:10000/devstoreaccount1/%s/%s", containerName, blobName)
 	}
-	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", s.accountName, s.container, blobName)
+	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", accountName, containerName, blobName)
 }
 
-// extractBlobName extracts blob name from URL
-func (s *ActivityPhotoService) extractBlobName(url string) string {
-	parts := strings.Split(url, s.container+"/")
+// blobNameFromURL extracts the blob name from an Azure or Azurite blob URL
+func blobNameFromURL(url, containerName string) string {
+	parts := strings.Split(url, containerName+"/")
 	if len(parts) >= 2 {
 		return parts[len(parts)-1]
 	}
 	return ""
 }
 
+// deleteBlobs removes blobs with batch requests of up to BlobBatchDeleteMaxSize, falling
+// back to one request per blob when a batch can't be submitted. Failures are logged and
+// counted; the orphan blob cleanup job removes whatever is left behind.
+func (s *ActivityPhotoService) deleteBlobs(ctx context.Context, blobNames []string) int {
+	failed := 0
+	svcClient := s.blobClient.ServiceClient()
+
+	for start := 0; start < len(blobNames); start += constants.BlobBatchDeleteMaxSize {
+		end := min(start+constants.BlobBatchDeleteMaxSize, len(blobNames))
+		chunk := blobNames[start:end]
+
+		resp, err := s.submitDeleteBatch(ctx, svcClient, chunk)
+		if err != nil {
+			logger.Sugar.Warnw("Blob batch delete failed, deleting one by one", "count", len(chunk), "error", err)
+			for _, blobName := range chunk {
+				deleteCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+				_, err := s.blobClient.DeleteBlob(deleteCtx, s.container, blobName, nil)
+				cancel()
+				if err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+					logger.Sugar.Warnw("Failed to delete blob", "blob_name", blobName, "error", err)
+					failed++
+				}
+			}
+			continue
+		}
+
+		for _, item := range resp.Responses {
+			if item.Error == nil || bloberror.HasCode(item.Error, bloberror.BlobNotFound) {
+				continue
+			}
+			blobName := ""
+			if item.BlobName != nil {
+				blobName = *item.BlobName
+			}
+			logger.Sugar.Warnw("Failed to delete blob", "blob_name", blobName, "error", item.Error)
+			failed++
+		}
+	}
+
+	return failed
+}
+
+// submitDeleteBatch sends one Blob Batch request deleting the given blobs
+func (s *ActivityPhotoService) submitDeleteBatch(ctx context.Context, svcClient *service.Client, blobNames []string) (service.SubmitBatchResponse, error) {
+	builder, err := svcClient.NewBatchBuilder()
+	if err != nil {
+		return service.SubmitBatchResponse{}, err
+	}
+	for _, blobName := range blobNames {
+		if err := builder.Delete(s.container, blobName, nil); err != nil {
+			return service.SubmitBatchResponse{}, err
+		}
+	}
+
+	batchCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	return svcClient.SubmitBatch(batchCtx, builder, nil)
+}
+
+// blobSASClient returns a client for a single blob in the story container
+func (s *ActivityPhotoService) blobSASClient(blobName string) *blob.Client {
+	return s.blobClient.ServiceClient().NewContainerClient(s.container).NewBlobClient(blobName)
+}
+
+// signBlobURL turns a stored blob URL into a read-only SAS URL. The expiry is rounded to a
+// sasExpiry boundary, so every request in the same window gets the same URL (browsers can
+// cache it) and each URL stays valid for between one and two sasExpiry periods.
+// Falls back to the stored URL when signing is disabled or fails.
+func (s *ActivityPhotoService) signBlobURL(url string) string {
+	if s.sasExpiry <= 0 || s.blobClient == nil {
+		return url
+	}
+	blobName := s.extractBlobName(url)
+	if blobName == "" {
+		return url
+	}
+
+	expiry := time.Now().Truncate(s.sasExpiry).Add(2 * s.sasExpiry)
+	signed, err := s.blobSASClient(blobName).GetSASURL(sas.BlobPermissions{Read: true}, expiry, nil)
+	if err != nil {
+		logger.Sugar.Warnw("Failed to sign story photo URL", "blob_name", blobName, "error", err)
+		return url
+	}
+	return signed
+}
+
+// signPhoto replaces a photo's stored URLs with SAS URLs for the response.
+// Only call it once the viewer has passed CanViewStories/CanViewPhoto, and never save the result.
+func (s *ActivityPhotoService) signPhoto(photo *models.ActivityPhoto) {
+	photo.PhotoURL = s.signBlobURL(photo.PhotoURL)
+	photo.ThumbnailURL = s.signBlobURL(photo.ThumbnailURL)
+}
+
+// signPhotos signs every photo in a slice in place
+func (s *ActivityPhotoService) signPhotos(photos []models.ActivityPhoto) {
+	for i := range photos {
+		s.signPhoto(&photos[i])
+	}
+}
+
+// DeleteBlobsByURL removes the blobs behind the given URLs in the background.
+// Failures are logged and otherwise ignored; a leftover blob is harmless once
+// no row references it.
+func (s *ActivityPhotoService) DeleteBlobsByURL(urls []string) {
+	if len(urls) == 0 {
+		return
+	}
+	go func() {
+		ctx := context.Background()
+		for _, url := range urls {
+			if blobName := s.extractBlobName(url); blobName != "" {
+				s.deleteBlob(ctx, blobName)
+			}
+		}
+	}()
+}
+
+// OrphanCleanupResult summarises a CleanupOrphanedBlobs run
+type OrphanCleanupResult struct {
+	Scanned        int
+	Deleted        int
+	Failed         int
+	BytesReclaimed int64
+}
+
+// CleanupOrphanedBlobs deletes story photo blobs that no ActivityPhoto row references.
+// Uploads can leave these behind when the process dies between the blob upload and the
+// database write. Blobs modified within gracePeriod are skipped so uploads still being
+// saved are never touched, and blobs outside the expected {userID}/... layout are left alone.
+func (s *ActivityPhotoService) CleanupOrphanedBlobs(ctx context.Context, gracePeriod time.Duration) (*OrphanCleanupResult, error) {
+	if s.blobClient == nil {
+		return nil, ErrStorageUnavailable
+	}
+
+	result := &OrphanCleanupResult{}
+	cutoff := time.Now().Add(-gracePeriod)
+	prefix := constants.ActivityPhotoBlobPrefix
+	pager := s.blobClient.NewListBlobsFlatPager(s.container, &azblob.ListBlobsFlatOptions{Prefix: &prefix})
+
+	for pager.More() {
+		page, err := pager.NextPage(ctx)
+		if err != nil {
+			return result, fmt.Errorf("failed to list blobs: %w", err)
+		}
+
+		// Collect old-enough blobs per owner, then check them against the owners' rows
+		type candidate struct {
+			name string
+			size int64
+		}
+		var candidates []candidate
+		userIDs := make(map[uint]bool)
+		for _, item := range page.Segment.BlobItems {
+			if item.Name == nil || item.Properties == nil || item.Properties.LastModified == nil {
+				continue
+			}
+			result.Scanned++
+			if item.Properties.LastModified.After(cutoff) {
+				continue
+			}
+			userID, ok := blobOwnerID(*item.Name)
+			if !ok {
+				continue
+			}
+			var size int64
+			if item.Properties.ContentLength != nil {
+				size = *item.Properties.ContentLength
+			}
+			candidates = append(candidates, candidate{name: *item.Name, size: size})
+			userIDs[userID] = true
+		}
+		if len(candidates) == 0 {
+			continue
+		}
+
+		ids := make([]uint, 0, len(userIDs))
+		for id := range userIDs {
+			ids = append(ids, id)
+		}
+		urls, err := s.repo.GetBlobURLsByUsers(ids)
+		if err != nil {
+			return result, fmt.Errorf("failed to load photo URLs: %w", err)
+		}
+		referenced := make(map[string]bool, len(urls))
+		for _, url := range urls {
+			if name := s.extractBlobName(url); name != "" {
+				referenced[name] = true
+			}
+		}
+
+		for _, c := range candidates {
+			if referenced[c.name] {
+				continue
+			}
+			deleteCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+			_, err := s.blobClient.DeleteBlob(deleteCtx, s.container, c.name, nil)
+			cancel()
+			if err != nil {
+				logger.Sugar.Warnw("Failed to delete orphaned blob", "blob_name", c.name, "error", err)
+				result.Failed++
+				continue
+			}
+			result.Deleted++
+			result.BytesReclaimed += c.size
+		}
+	}
+
+	return result, nil
+}
+
+// blobOwnerID parses the user ID from an "activity-photos/{userID}/..." blob name
+func blobOwnerID(blobName string) (uint, bool) {
+	rest, ok := strings.CutPrefix(blobName, constants.ActivityPhotoBlobPrefix)
+	if !ok {
+		return 0, false
+	}
+	idStr, _, ok := strings.Cut(rest, "/")
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil || id == 0 {
+		return 0, false
+	}
+	return uint(id), true
+}
+
 // Delete removes a photo and its blobs
 func (s *ActivityPhotoService) Delete(ctx context.Context, photoID, userID uint) error {
 	photo, err := s.repo.GetByID(photoID)
@@ -296,6 +798,44 @@ func (s *ActivityPhotoService) Delete(ctx context.Context, photoID, userID uint)
 	return nil
 }
 
+// DeleteAllForUser deletes every photo the user has uploaded and returns how many were removed.
+// Rows (with their views, likes and replies) go first in one transaction; blob deletion
+// failures after that are logged and left for the orphan blob cleanup job.
+func (s *ActivityPhotoService) DeleteAllForUser(ctx context.Context, userID uint) (int, error) {
+	photos, err := s.repo.DeleteAllForUser(userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete photos: %w", err)
+	}
+	if len(photos) == 0 {
+		return 0, nil
+	}
+
+	blobNames := make([]string, 0, len(photos)*2)
+	for _, photo := range photos {
+		if err := redis.InvalidateStoryLikeCount(ctx, photo.ID); err != nil {
+			logger.Sugar.Warnw("Failed to invalidate like count cache", "photo_id", photo.ID, "error", err)
+		}
+		for _, url := range []string{photo.PhotoURL, photo.ThumbnailURL} {
+			if blobName := s.extractBlobName(url); blobName != "" {
+				blobNames = append(blobNames, blobName)
+			}
+		}
+	}
+
+	failed := 0
+	if s.blobClient != nil {
+		failed = s.deleteBlobs(ctx, blobNames)
+	}
+
+	logger.Sugar.Infow("All activity photos deleted",
+		"user_id", userID,
+		"count", len(photos),
+		"blobs_failed", failed,
+	)
+
+	return len(photos), nil
+}
+
 // DeleteByActivity deletes all photos for a specific activity (used when custom tile is deleted)
 func (s *ActivityPhotoService) DeleteByActivity(ctx context.Context, userID uint, activityName string) error {
 	// Get all photos for this activity
@@ -330,12 +870,130 @@ func (s *ActivityPhotoService) DeleteByActivity(ctx context.Context, userID uint
 
 // GetByUserAndDate retrieves photos for a user on a specific date
 func (s *ActivityPhotoService) GetByUserAndDate(ctx context.Context, userID uint, photoDate time.Time) ([]models.ActivityPhoto, error) {
-	return s.repo.GetByUserAndDate(userID, photoDate)
+	photos, err := s.repo.GetByUserAndDate(userID, photoDate)
+	if err != nil {
+		return nil, err
+	}
+	s.signPhotos(photos)
+	return photos, nil
 }
 
-// GetFollowingStories retrieves story groups from users the viewer follows
-func (s *ActivityPhotoService) GetFollowingStories(ctx context.Context, viewerID uint, photoDate time.Time, limit int) ([]models.UserStoryGroup, error) {
-	return s.repo.GetFollowingPhotosGrouped(viewerID, photoDate, limit)
+// GetByUserAndDateRange retrieves a user's story archive between start and end (inclusive),
+// grouped by date with the newest date first. Only the owner can view their archive.
+func (s *ActivityPhotoService) GetByUserAndDateRange(ctx context.Context, viewerID, userID uint, start, end time.Time) ([]models.StoryArchiveDay, error) {
+	if viewerID != userID {
+		return nil, fmt.Errorf("not authorized to view story archive")
+	}
+	if end.Before(start) {
+		return nil, fmt.Errorf("end date must not be before start date")
+	}
+	if end.Sub(start) >= constants.StoryArchiveMaxDays*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed %d days", constants.StoryArchiveMaxDays)
+	}
+
+	photos, err := s.repo.GetByUserAndDateRange(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Photos are ordered by photo_date DESC, so consecutive rows share a date
+	days := make([]models.StoryArchiveDay, 0)
+	for _, photo := range photos {
+		date := photo.PhotoDate.Format(constants.DateFormat)
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, models.StoryArchiveDay{Date: date})
+		}
+		days[len(days)-1].Photos = append(days[len(days)-1].Photos, photo)
+	}
+	for i := range days {
+		s.signPhotos(days[i].Photos)
+	}
+
+	return days, nil
+}
+
+// GetVisibleByUserAndDate retrieves a user's photos for a date, hiding close-friends-only
+// photos unless the viewer is on the owner's close friends list, and expired dates for non-owners
+func (s *ActivityPhotoService) GetVisibleByUserAndDate(ctx context.Context, viewerID, userID uint, photoDate time.Time) ([]models.ActivityPhoto, error) {
+	// Owners keep access to older photos; others only see dates inside the visibility window
+	if viewerID != userID && !s.isStoryVisible(photoDate) {
+		return []models.ActivityPhoto{}, nil
+	}
+
+	photos, err := s.repo.GetByUserAndDate(userID, photoDate)
+	if err != nil {
+		return nil, err
+	}
+	if viewerID == userID {
+		s.signPhotos(photos)
+		return photos, nil
+	}
+
+	var isCloseFriend *bool
+	visible := make([]models.ActivityPhoto, 0, len(photos))
+	for _, photo := range photos {
+		if photo.Visibility == models.PhotoVisibilityCloseFriends {
+			if isCloseFriend == nil {
+				member, err := s.followRepo.IsCloseFriend(userID, viewerID)
+				if err != nil {
+					return nil, fmt.Errorf("failed to check close friends: %w", err)
+				}
+				isCloseFriend = &member
+			}
+			if !*isCloseFriend {
+				continue
+			}
+		}
+		visible = append(visible, photo)
+	}
+	s.signPhotos(visible)
+
+	return visible, nil
+}
+
+// GetFollowingStories retrieves a page of story groups from users the viewer follows.
+// Returns whether more groups exist after this page.
+func (s *ActivityPhotoService) GetFollowingStories(ctx context.Context, viewerID uint, photoDate time.Time, limit int, cursor *repository.StoryFeedCursor) ([]models.UserStoryGroup, bool, error) {
+	// The feed only contains other users' stories, so expired dates are empty
+	if !s.isStoryVisible(photoDate) {
+		return []models.UserStoryGroup{}, false, nil
+	}
+
+	// Fetch one extra to check for more
+	groups, err := s.repo.GetFollowingPhotosGrouped(viewerID, photoDate, limit+1, cursor)
+	if err != nil {
+		return nil, false, err
+	}
+
+	hasMore := len(groups) > limit
+	if hasMore {
+		groups = groups[:limit]
+	}
+
+	// Redis like counts are authoritative when cached (updated incrementally on like/unlike).
+	// Fetch them for the whole page in one round-trip.
+	var photoIDs []uint
+	for i := range groups {
+		for j := range groups[i].Photos {
+			photoIDs = append(photoIDs, groups[i].Photos[j].ID)
+		}
+	}
+	cachedCounts, err := redis.GetStoryLikeCounts(ctx, photoIDs)
+	if err != nil {
+		logger.Sugar.Warnw("Failed to get like counts from cache", "error", err)
+	}
+
+	for i := range groups {
+		for j := range groups[i].Photos {
+			photo := &groups[i].Photos[j]
+			s.signPhoto(&photo.ActivityPhoto)
+			if cached, ok := cachedCounts[photo.ID]; ok {
+				photo.LikeCount = cached
+			}
+		}
+	}
+
+	return groups, hasMore, nil
 }
 
 // RecordView records that a user viewed a photo
@@ -352,11 +1010,71 @@ func (s *ActivityPhotoService) RecordView(ctx context.Context, viewerID, photoID
 		return nil // Don't record viewing own photo
 	}
 
+	if s.isGhostMode(viewerID) {
+		return nil // Viewer opted out of being recorded
+	}
+
 	return s.repo.RecordView(viewerID, photoID)
 }
 
-// GetViewers retrieves viewers of a photo
-func (s *ActivityPhotoService) GetViewers(ctx context.Context, photoID, ownerID uint, limit, offset int) ([]models.PhotoViewer, int64, error) {
+// RecordViewsBatch records that a user viewed several photos in one round-trip.
+// The viewer's own photos are skipped.
+func (s *ActivityPhotoService) RecordViewsBatch(ctx context.Context, viewerID uint, photoIDs []uint) error {
+	if len(photoIDs) == 0 || s.isGhostMode(viewerID) {
+		return nil
+	}
+	return s.repo.RecordViewsBatch(viewerID, photoIDs)
+}
+
+// HideFromViewers enables or disables ghost mode for a user. While enabled, the user's
+// story views are not recorded, so they never appear in owners' viewer lists.
+// Applies prospectively only: existing story_views rows are kept.
+func (s *ActivityPhotoService) HideFromViewers(ctx context.Context, userID uint, enabled bool) error {
+	if err := s.userRepo.UpdateGhostMode(userID, enabled); err != nil {
+		return fmt.Errorf("failed to update ghost mode: %w", err)
+	}
+
+	logger.Sugar.Infow("Story ghost mode updated",
+		"user_id", userID,
+		"enabled", enabled,
+	)
+	return nil
+}
+
+// IsGhostMode returns whether a user has ghost mode enabled
+func (s *ActivityPhotoService) IsGhostMode(ctx context.Context, userID uint) (bool, error) {
+	return s.userRepo.GetGhostMode(userID)
+}
+
+// isGhostMode checks ghost mode for view recording; lookup failures fall back to recording
+func (s *ActivityPhotoService) isGhostMode(viewerID uint) bool {
+	enabled, err := s.userRepo.GetGhostMode(viewerID)
+	if err != nil {
+		logger.Sugar.Warnw("Failed to check ghost mode", "viewer_id", viewerID, "error", err)
+		return false
+	}
+	return enabled
+}
+
+// GetViewers retrieves viewers of a photo; a non-nil cursor takes precedence over offset
+func (s *ActivityPhotoService) GetViewers(ctx context.Context, photoID, ownerID uint, limit, offset int, cursor *repository.ListCursor) ([]models.PhotoViewer, int64, error) {
+	// Verify ownership
+	photo, err := s.repo.GetByID(photoID)
+	if err != nil {
+		return nil, 0, err
+	}
+	if photo == nil {
+		return nil, 0, fmt.Errorf("photo not found")
+	}
+	if photo.UserID != ownerID {
+		return nil, 0, fmt.Errorf("not authorized to view photo viewers")
+	}
+
+	return s.repo.GetViewers(photoID, limit, offset, cursor)
+}
+
+// GetNonViewers retrieves the owner's followers who have not viewed a photo; a non-nil cursor takes precedence over offset
+func (s *ActivityPhotoService) GetNonViewers(ctx context.Context, photoID, ownerID uint, limit, offset int, cursor *repository.ListCursor) ([]models.PhotoNonViewer, int64, error) {
 	// Verify ownership
 	photo, err := s.repo.GetByID(photoID)
 	if err != nil {
@@ -369,7 +1087,7 @@ func (s *ActivityPhotoService) GetViewers(ctx context.Context, photoID, ownerID
 		return nil, 0, fmt.Errorf("not authorized to view photo viewers")
 	}
 
-	return s.repo.GetViewers(photoID, limit, offset)
+	return s.repo.GetNonViewers(photo, limit, offset, cursor)
 }
 
 // GetViewCount returns the view count for a photo
@@ -388,6 +1106,24 @@ func (s *ActivityPhotoService) CanViewStories(ctx context.Context, viewerID, tar
 		return true, nil // Can always view own stories
 	}
 
+	// Deactivated accounts' stories are hidden
+	deactivated, err := s.userRepo.IsDeactivated(targetUserID)
+	if err != nil {
+		return false, err
+	}
+	if deactivated {
+		return false, nil
+	}
+
+	// Blocked users (either direction) cannot view stories
+	blocked, err := s.followRepo.IsBlockedEither(viewerID, targetUserID)
+	if err != nil {
+		return false, err
+	}
+	if blocked {
+		return false, nil
+	}
+
 	// Check if viewer follows the target
 	isFollowing, err := s.followRepo.IsFollowing(viewerID, targetUserID)
 	if err != nil {
@@ -397,11 +1133,46 @@ func (s *ActivityPhotoService) CanViewStories(ctx context.Context, viewerID, tar
 	return isFollowing, nil
 }
 
+// CanViewPhoto checks if a viewer can view a specific photo, honoring close-friends visibility
+// and the story visibility window
+func (s *ActivityPhotoService) CanViewPhoto(ctx context.Context, viewerID uint, photo *models.ActivityPhoto) (bool, error) {
+	canView, err := s.CanViewStories(ctx, viewerID, photo.UserID)
+	if err != nil || !canView {
+		return false, err
+	}
+
+	if viewerID == photo.UserID {
+		return true, nil
+	}
+	if !s.isStoryVisible(photo.PhotoDate) {
+		return false, nil
+	}
+	if photo.Visibility != models.PhotoVisibilityCloseFriends {
+		return true, nil
+	}
+
+	return s.followRepo.IsCloseFriend(photo.UserID, viewerID)
+}
+
 // ==================== Story Likes ====================
 
-// LikePhoto likes a photo (also records view) and sends notification to owner
-// Follows the same idempotency pattern as LikeDay - check first, return early if already liked
+// LikePhoto likes a photo with the default ❤️ reaction (see ReactToPhoto)
 func (s *ActivityPhotoService) LikePhoto(ctx context.Context, likerID, photoID uint) error {
+	return s.ReactToPhoto(ctx, likerID, photoID, models.DefaultStoryReaction)
+}
+
+// ErrInvalidReaction is returned for an emoji outside models.StoryReactions
+var ErrInvalidReaction = errors.New("unsupported reaction")
+
+// ReactToPhoto records an emoji reaction to a photo (also records view) and notifies the owner.
+// A user has one reaction per photo; reacting again with another emoji replaces it without a
+// second notification. Reactions count as likes for like counts and liked status.
+// Follows the same idempotency pattern as LikeDay - check first, return early if already reacted.
+func (s *ActivityPhotoService) ReactToPhoto(ctx context.Context, likerID, photoID uint, emoji string) error {
+	if !models.IsValidStoryReaction(emoji) {
+		return ErrInvalidReaction
+	}
+
 	// Get the photo
 	photo, err := s.repo.GetByID(photoID)
 	if err != nil {
@@ -416,8 +1187,8 @@ func (s *ActivityPhotoService) LikePhoto(ctx context.Context, likerID, photoID u
 		return fmt.Errorf("cannot like own photo")
 	}
 
-	// Check if liker follows the photo owner
-	canView, err := s.CanViewStories(ctx, likerID, photo.UserID)
+	// Check if liker follows the photo owner (and is a close friend for close-friends photos)
+	canView, err := s.CanViewPhoto(ctx, likerID, photo)
 	if err != nil {
 		return fmt.Errorf("failed to check permissions: %w", err)
 	}
@@ -425,24 +1196,34 @@ func (s *ActivityPhotoService) LikePhoto(ctx context.Context, likerID, photoID u
 		return fmt.Errorf("must follow user to like their photos")
 	}
 
-	// Check if already liked - return early if so (no notification, no cache update)
-	alreadyLiked, err := s.repo.HasLikedPhoto(likerID, photoID)
+	// Check for an existing reaction - same emoji is a no-op (no notification, no cache update)
+	existing, err := s.repo.GetReaction(likerID, photoID)
 	if err != nil {
 		logger.Sugar.Warnw("Failed to check existing like", "error", err, "photo_id", photoID, "liker_id", likerID)
 	}
 
-	if alreadyLiked {
+	if existing == emoji {
 		logger.Sugar.Infow("Photo already liked, returning early",
 			"photo_id", photoID,
 			"liker_id", likerID,
+			"emoji", emoji,
 		)
 		return nil // Already liked - idempotent, no error
 	}
 
-	// Create the like (handles duplicate gracefully at DB level too)
-	if err := s.repo.LikePhoto(likerID, photoID); err != nil {
+	// Create or switch the reaction (handles a concurrent first like at DB level too)
+	created, err := s.repo.ReactToPhoto(likerID, photoID, emoji)
+	if err != nil {
 		return fmt.Errorf("failed to like photo: %w", err)
 	}
+	if !created {
+		logger.Sugar.Infow("Photo reaction changed",
+			"photo_id", photoID,
+			"liker_id", likerID,
+			"emoji", emoji,
+		)
+		return nil
+	}
 
 	// Also record a view (liking counts as viewing)
 	if err := s.repo.RecordView(likerID, photoID); err != nil {
@@ -454,12 +1235,13 @@ func (s *ActivityPhotoService) LikePhoto(ctx context.Context, likerID, photoID u
 	redis.SetStoryLikedByUser(ctx, photoID, likerID, true)
 
 	// Send notification to photo owner (async)
-	go s.sendLikeNotification(ctx, likerID, photo)
+	go s.sendLikeNotification(ctx, likerID, photo, emoji)
 
 	logger.Sugar.Infow("Photo liked",
 		"photo_id", photoID,
 		"liker_id", likerID,
 		"owner_id", photo.UserID,
+		"emoji", emoji,
 	)
 
 	return nil
@@ -539,8 +1321,18 @@ func (s *ActivityPhotoService) GetPhotoLikeCount(ctx context.Context, photoID ui
 	return count, nil
 }
 
-// GetPhotoLikers retrieves likers of a photo (owner only)
-func (s *ActivityPhotoService) GetPhotoLikers(ctx context.Context, photoID, ownerID uint, limit, offset int) ([]models.PhotoLiker, int64, error) {
+// GetReaction returns the emoji the user reacted to a photo with, or "" if they haven't
+func (s *ActivityPhotoService) GetReaction(ctx context.Context, likerID, photoID uint) (string, error) {
+	return s.repo.GetReaction(likerID, photoID)
+}
+
+// GetReactionCounts returns the number of reactions to a photo per emoji
+func (s *ActivityPhotoService) GetReactionCounts(ctx context.Context, photoID uint) (map[string]int64, error) {
+	return s.repo.GetReactionCounts(photoID)
+}
+
+// GetPhotoLikers retrieves likers of a photo (owner only); a non-nil cursor takes precedence over offset
+func (s *ActivityPhotoService) GetPhotoLikers(ctx context.Context, photoID, ownerID uint, limit, offset int, cursor *repository.ListCursor) ([]models.PhotoLiker, int64, error) {
 	// Verify ownership
 	photo, err := s.repo.GetByID(photoID)
 	if err != nil {
@@ -553,11 +1345,11 @@ func (s *ActivityPhotoService) GetPhotoLikers(ctx context.Context, photoID, owne
 		return nil, 0, fmt.Errorf("not authorized to view photo likers")
 	}
 
-	return s.repo.GetPhotoLikers(photoID, limit, offset)
+	return s.repo.GetPhotoLikers(photoID, limit, offset, cursor)
 }
 
-// GetPhotoInteractions retrieves combined viewers and likers (owner only)
-func (s *ActivityPhotoService) GetPhotoInteractions(ctx context.Context, photoID, ownerID uint, limit, offset int) ([]models.PhotoInteraction, int64, error) {
+// GetPhotoInteractions retrieves combined viewers and likers (owner only); a non-nil cursor takes precedence over offset
+func (s *ActivityPhotoService) GetPhotoInteractions(ctx context.Context, photoID, ownerID uint, limit, offset int, cursor *repository.ListCursor) ([]models.PhotoInteraction, int64, error) {
 	// Verify ownership
 	photo, err := s.repo.GetByID(photoID)
 	if err != nil {
@@ -570,13 +1362,13 @@ func (s *ActivityPhotoService) GetPhotoInteractions(ctx context.Context, photoID
 		return nil, 0, fmt.Errorf("not authorized to view photo interactions")
 	}
 
-	return s.repo.GetPhotoInteractions(photoID, limit, offset)
+	return s.repo.GetPhotoInteractions(photoID, limit, offset, cursor)
 }
 
 // sendLikeNotification sends push notification to photo owner when someone likes their photo
 // Uses NotificationDedupe table to ensure "only once ever" delivery per (recipient, liker, photo) combination.
 // Safe for unlike/re-like scenarios - user will only receive one notification ever per photo+liker pair.
-func (s *ActivityPhotoService) sendLikeNotification(ctx context.Context, likerID uint, photo *models.ActivityPhoto) {
+func (s *ActivityPhotoService) sendLikeNotification(ctx context.Context, likerID uint, photo *models.ActivityPhoto, emoji string) {
 	// Create fresh context for background operation
 	sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -633,7 +1425,7 @@ func (s *ActivityPhotoService) sendLikeNotification(ctx context.Context, likerID
 		}
 
 		// 3. Create the notification
-		body := fmt.Sprintf("%s liked your story", liker.Username)
+		body := fmt.Sprintf("%s reacted %s to your story", liker.Username, emoji)
 		notif = &models.Notification{
 			UserID: photo.UserID,
 			Type:   models.NotifTypeStoryLiked,
@@ -645,6 +1437,7 @@ func (s *ActivityPhotoService) sendLikeNotification(ctx context.Context, likerID
 				LikerAvatar:   likerAvatar,
 				PhotoID:       photo.ID,
 				PhotoDate:     photoDateStr,
+				Emoji:         emoji,
 			}.ToMap(),
 		}
 
@@ -714,16 +1507,126 @@ func (s *ActivityPhotoService) sendLikeNotification(ctx context.Context, likerID
 	}
 }
 
+// ==================== Story Replies ====================
+
+// ReplyToPhoto sends a short reply to a story owner and notifies them
+func (s *ActivityPhotoService) ReplyToPhoto(ctx context.Context, senderID, photoID uint, text string) (*models.StoryReply, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("reply text is required")
+	}
+	if utf8.RuneCountInString(text) > constants.StoryReplyMaxLength {
+		return nil, fmt.Errorf("reply text is too long")
+	}
+
+	photo, err := s.repo.GetByID(photoID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get photo: %w", err)
+	}
+	if photo == nil {
+		return nil, fmt.Errorf("photo not found")
+	}
+	if photo.UserID == senderID {
+		return nil, fmt.Errorf("cannot reply to own photo")
+	}
+
+	canView, err := s.CanViewPhoto(ctx, senderID, photo)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check permissions: %w", err)
+	}
+	if !canView {
+		return nil, fmt.Errorf("must follow user to reply to their photos")
+	}
+
+	// Per-sender, per-photo rate limit
+	recent, err := s.repo.CountRecentReplies(senderID, photoID, time.Now().Add(-constants.StoryReplyRateWindow))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check reply rate limit: %w", err)
+	}
+	if recent >= constants.StoryReplyRateLimit {
+		return nil, fmt.Errorf("too many replies to this photo")
+	}
+
+	reply := &models.StoryReply{
+		PhotoID:  photoID,
+		SenderID: senderID,
+		Text:     text,
+	}
+	if err := s.repo.CreateReply(reply); err != nil {
+		return nil, fmt.Errorf("failed to save reply: %w", err)
+	}
+
+	go s.sendReplyNotification(senderID, photo, reply)
+
+	logger.Sugar.Infow("Story reply sent",
+		"reply_id", reply.ID,
+		"photo_id", photoID,
+		"sender_id", senderID,
+		"owner_id", photo.UserID,
+	)
+
+	return reply, nil
+}
+
+// sendReplyNotification notifies the story owner about a reply (runs in background)
+func (s *ActivityPhotoService) sendReplyNotification(senderID uint, photo *models.ActivityPhoto, reply *models.StoryReply) {
+	if s.notificationSvc == nil {
+		return
+	}
+
+	sendCtx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	sender, err := s.userRepo.FindByID(senderID)
+	if err != nil || sender == nil {
+		logger.Sugar.Warnw("Failed to get reply sender for notification", "sender_id", senderID, "error", err)
+		return
+	}
+	owner, err := s.userRepo.FindByID(photo.UserID)
+	if err != nil || owner == nil {
+		logger.Sugar.Warnw("Failed to get story owner for notification", "owner_id", photo.UserID, "error", err)
+		return
+	}
+
+	var senderAvatar string
+	if sender.ProfilePic != nil {
+		senderAvatar = *sender.ProfilePic
+	}
+
+	preview := reply.Text
+	if runes := []rune(preview); len(runes) > constants.StoryReplyPreviewLength {
+		preview = string(runes[:constants.StoryReplyPreviewLength]) + "…"
+	}
+
+	if err := s.notificationSvc.NotifyStoryReply(
+		sendCtx,
+		photo.UserID, senderID,
+		sender.Username, senderAvatar,
+		owner.Username,
+		reply.ID, photo.ID, photo.PhotoDate.Format(constants.DateFormat), preview,
+	); err != nil {
+		logger.Sugar.Warnw("Failed to send story reply notification",
+			"reply_id", reply.ID,
+			"owner_id", photo.UserID,
+			"error", err,
+		)
+	}
+}
+
 // ==================== Debounced Notifications ====================
 
 const notificationDebounceWindow = 30 * time.Second
 
-// scheduleNotification schedules a debounced notification for photo uploads
-func (s *ActivityPhotoService) scheduleNotification(ctx context.Context, uploaderID uint, photoDate string) {
+// scheduleNotification schedules a debounced notification for photo uploads. Uploads
+// visible to close friends only are batched separately and announced only to them.
+func (s *ActivityPhotoService) scheduleNotification(ctx context.Context, uploaderID uint, photoDate string, visibility models.PhotoVisibility) {
 	s.notificationMutex.Lock()
 	defer s.notificationMutex.Unlock()
 
-	key := uploaderID
+	key := photoNotificationKey{
+		uploaderID:   uploaderID,
+		closeFriends: visibility == models.PhotoVisibilityCloseFriends,
+	}
 
 	// Check if there's already a pending notification
 	if pending, exists := s.pendingNotifications[key]; exists {
@@ -755,6 +1658,7 @@ func (s *ActivityPhotoService) scheduleNotification(ctx context.Context, uploade
 
 	// Create new pending notification
 	pending := &pendingPhotoNotification{
+		key:              key,
 		uploaderID:       uploaderID,
 		uploaderUsername: uploader.Username,
 		uploaderAvatar:   avatar,
@@ -777,17 +1681,24 @@ func (s *ActivityPhotoService) scheduleNotification(ctx context.Context, uploade
 	logger.Sugar.Debugw("Scheduled debounced notification",
 		"uploader_id", uploaderID,
 		"photo_date", photoDate,
+		"close_friends", key.closeFriends,
 	)
 }
 
-// sendPhotoNotification sends the actual notification to followers
+// sendPhotoNotification sends the actual notification to the photos' audience: every
+// follower, or only followers on the close friends list for close-friends-only photos
 func (s *ActivityPhotoService) sendPhotoNotification(ctx context.Context, pending *pendingPhotoNotification) {
 	s.notificationMutex.Lock()
-	delete(s.pendingNotifications, pending.uploaderID)
+	delete(s.pendingNotifications, pending.key)
 	s.notificationMutex.Unlock()
 
-	// Get all follower IDs of the uploader
-	followerIDs, err := s.followRepo.GetAllFollowerIDs(pending.uploaderID)
+	var followerIDs []uint
+	var err error
+	if pending.key.closeFriends {
+		followerIDs, err = s.followRepo.GetCloseFriendFollowerIDs(pending.uploaderID)
+	} else {
+		followerIDs, err = s.followRepo.GetAllFollowerIDs(pending.uploaderID)
+	}
 	if err != nil {
 		logger.Sugar.Errorw("Failed to get followers for notification",
 			"uploader_id", pending.uploaderID,
@@ -840,9 +1751,12 @@ func (s *ActivityPhotoService) sendPhotoNotification(ctx context.Context, pendin
 			continue
 		}
 
-		// Publish push notification (bypasses push preferences for story notifications)
+		// Publish push notification; photo-upload pushes follow the follower's push preferences and quiet hours
 		if publisher := GetPushPublisher(); publisher != nil && publisher.IsAvailable() {
 			pushDedupeKey := fmt.Sprintf("photo_uploaded:%d:%d:%s", followerID, pending.uploaderID, pending.photoDate)
+			if pending.key.closeFriends {
+				pushDedupeKey += ":close_friends"
+			}
 			ttlSeconds := 14400 // 4 hours
 
 			data := notif.Metadata
@@ -876,6 +1790,7 @@ func (s *ActivityPhotoService) sendPhotoNotification(ctx context.Context, pendin
 		"uploader_id", pending.uploaderID,
 		"photo_count", pending.photoCount,
 		"follower_count", len(followerIDs),
+		"close_friends", pending.key.closeFriends,
 		"date", formattedDate,
 	)
 }
@@ -1,6 +1,7 @@
 package services
 
 import (
+	"fmt"
 	"sort"
 	"time"
 
@@ -30,6 +31,29 @@ func NewAnalyticsService(
 	}
 }
 
+// GetWeekAnalyticsByOffset retrieves weekly analytics for the week weekOffset weeks
+// before the current IST week (0 = this week). Percentage change compares the
+// requested week to the one before it.
+func (s *AnalyticsService) GetWeekAnalyticsByOffset(userID uint, weekOffset int) (*dto.WeekAnalyticsResponse, error) {
+	if weekOffset < 0 || weekOffset > constants.WeekAnalyticsMaxOffset {
+		return nil, fmt.Errorf("week_offset must be between 0 and %d", constants.WeekAnalyticsMaxOffset)
+	}
+	weekStart := currentISTWeekStart().AddDate(0, 0, -7*weekOffset)
+	return s.GetWeekAnalytics(userID, weekStart)
+}
+
+// currentISTWeekStart returns the Monday of the current IST week as a UTC date,
+// matching how YYYY-MM-DD request dates are parsed.
+func currentISTWeekStart() time.Time {
+	today := istTodayDate()
+	weekday := int(today.Weekday())
+	// Adjust for Monday start
+	if weekday == 0 {
+		weekday = 7
+	}
+	return today.AddDate(0, 0, -(weekday - 1))
+}
+
 // GetWeekAnalytics retrieves weekly analytics for a user
 func (s *AnalyticsService) GetWeekAnalytics(userID uint, weekStart time.Time) (*dto.WeekAnalyticsResponse, error) {
 	// Calculate week end (Sunday)
@@ -39,28 +63,21 @@ func (s *AnalyticsService) GetWeekAnalytics(userID uint, weekStart time.Time) (*
 	prevWeekStart := weekStart.AddDate(0, 0, -7)
 	prevWeekEnd := weekStart.AddDate(0, 0, -1)
 
-	// Current week dates (based on today)
-	now := time.Now()
-	currentWeekday := int(now.Weekday())
-	// Adjust for Monday start
-	if currentWeekday == 0 {
-		currentWeekday = 7
-	}
-	currentWeekStart := now.AddDate(0, 0, -(currentWeekday - 1))
-	currentWeekStart = time.Date(currentWeekStart.Year(), currentWeekStart.Month(), currentWeekStart.Day(), 0, 0, 0, 0, time.UTC)
+	// Current week dates (based on today in IST)
+	currentWeekStart := currentISTWeekStart()
 	currentWeekEnd := currentWeekStart.AddDate(0, 0, 6)
 
 	// Check if selected week is current week
 	isCurrentWeek := weekStart.Equal(currentWeekStart)
 
 	// Fetch this week's activities
-	thisWeekActivities, err := s.activityRepo.FindByUserAndDateRange(userID, weekStart, weekEnd)
+	thisWeekActivities, err := s.activityRepo.FindUnarchivedByUserAndDateRange(userID, weekStart, weekEnd)
 	if err != nil {
 		return nil, err
 	}
 
 	// Fetch previous week's activities
-	prevWeekActivities, err := s.activityRepo.FindByUserAndDateRange(userID, prevWeekStart, prevWeekEnd)
+	prevWeekActivities, err := s.activityRepo.FindUnarchivedByUserAndDateRange(userID, prevWeekStart, prevWeekEnd)
 	if err != nil {
 		return nil, err
 	}
@@ -68,7 +85,7 @@ func (s *AnalyticsService) GetWeekAnalytics(userID uint, weekStart time.Time) (*
 	// Fetch current week's activities (for comparison with past weeks)
 	var totalCurrentWeek float32
 	if !isCurrentWeek {
-		currentWeekActivities, err := s.activityRepo.FindByUserAndDateRange(userID, currentWeekStart, currentWeekEnd)
+		currentWeekActivities, err := s.activityRepo.FindUnarchivedByUserAndDateRange(userID, currentWeekStart, currentWeekEnd)
 		if err != nil {
 			return nil, err
 		}
@@ -180,12 +197,249 @@ func (s *AnalyticsService) GetWeekAnalytics(userID uint, weekStart time.Time) (*
 		PercentageChange:      percentageChange,
 		PercentageVsCurrent:   percentageVsCurrent,
 		IsCurrentWeek:         isCurrentWeek,
+		WeekStart:             weekStart.Format(constants.DateFormat),
 		Streak:                streakInfo,
 		DailyBreakdown:        dailyBreakdown,
 		ActivitySummary:       activitySummary,
 	}, nil
 }
 
+// periodAggregate holds totals computed over a date range
+type periodAggregate struct {
+	totalHours      float32
+	activeDays      int
+	bestDay         *dto.BestDay
+	activitySummary []dto.ActivitySummary
+	dailyTotals     map[string]float32
+}
+
+// aggregateRange groups a user's activities between start and end (inclusive) by day and activity
+func (s *AnalyticsService) aggregateRange(userID uint, start, end time.Time) (*periodAggregate, error) {
+	activities, err := s.activityRepo.FindUnarchivedByUserAndDateRange(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	agg := &periodAggregate{dailyTotals: make(map[string]float32)}
+	activityTotals := make(map[models.ActivityName]float32)
+	for _, a := range activities {
+		if a.DurationHours <= 0 {
+			continue
+		}
+		agg.totalHours += a.DurationHours
+		agg.dailyTotals[a.ActivityDate.Format(constants.DateFormat)] += a.DurationHours
+		activityTotals[a.Name] += a.DurationHours
+	}
+
+	agg.activeDays = len(agg.dailyTotals)
+	for date, hours := range agg.dailyTotals {
+		// Ties go to the earliest date so results are stable
+		if agg.bestDay == nil || hours > agg.bestDay.TotalHours ||
+			(hours == agg.bestDay.TotalHours && date < agg.bestDay.Date) {
+			agg.bestDay = &dto.BestDay{Date: date, TotalHours: hours}
+		}
+	}
+
+	agg.activitySummary = make([]dto.ActivitySummary, 0, len(activityTotals))
+	for name, hours := range activityTotals {
+		agg.activitySummary = append(agg.activitySummary, dto.ActivitySummary{
+			Name:       name,
+			TotalHours: hours,
+		})
+	}
+	sort.Slice(agg.activitySummary, func(i, j int) bool {
+		return agg.activitySummary[i].TotalHours > agg.activitySummary[j].TotalHours
+	})
+
+	return agg, nil
+}
+
+// istTodayDate returns today's IST calendar date as a UTC date,
+// matching how activity dates are parsed and stored.
+func istTodayDate() time.Time {
+	today := istToday()
+	return time.Date(today.Year(), today.Month(), today.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// clampToISTToday caps end at today's IST date so partial periods only count elapsed days.
+// Returns the capped end and whether the period includes today.
+func clampToISTToday(end time.Time) (time.Time, bool) {
+	todayDate := istTodayDate()
+	if end.Before(todayDate) {
+		return end, false
+	}
+	return todayDate, true
+}
+
+// AggregateMonth computes totals, per-activity summary, best day and active days for a
+// calendar month. Days are IST calendar dates; the current month only counts elapsed days.
+func (s *AnalyticsService) AggregateMonth(userID uint, year, month int) (*dto.MonthAnalyticsResponse, error) {
+	if month < 1 || month > 12 {
+		return nil, fmt.Errorf("month must be between 1 and 12")
+	}
+	start := time.Date(year, time.Month(month), 1, 0, 0, 0, 0, time.UTC)
+	if start.After(istTodayDate()) {
+		return nil, fmt.Errorf("period is in the future")
+	}
+	end, isCurrent := clampToISTToday(start.AddDate(0, 1, -1))
+
+	agg, err := s.aggregateRange(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MonthAnalyticsResponse{
+		Success:         true,
+		Year:            year,
+		Month:           month,
+		IsCurrentMonth:  isCurrent,
+		DaysCounted:     end.Day(),
+		TotalHours:      agg.totalHours,
+		ActiveDays:      agg.activeDays,
+		BestDay:         agg.bestDay,
+		ActivitySummary: agg.activitySummary,
+	}, nil
+}
+
+// AggregateYear computes totals, monthly totals, per-activity summary, best day and active
+// days for a calendar year. Days are IST calendar dates; the current year only counts elapsed days.
+func (s *AnalyticsService) AggregateYear(userID uint, year int) (*dto.YearAnalyticsResponse, error) {
+	start := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	if start.After(istTodayDate()) {
+		return nil, fmt.Errorf("period is in the future")
+	}
+	end, isCurrent := clampToISTToday(time.Date(year, time.December, 31, 0, 0, 0, 0, time.UTC))
+
+	agg, err := s.aggregateRange(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	monthlyTotals := make([]dto.MonthlyTotal, int(end.Month()))
+	for i := range monthlyTotals {
+		monthlyTotals[i].Month = i + 1
+	}
+	for date, hours := range agg.dailyTotals {
+		d, err := time.Parse(constants.DateFormat, date)
+		if err != nil {
+			continue
+		}
+		monthlyTotals[d.Month()-1].TotalHours += hours
+		monthlyTotals[d.Month()-1].ActiveDays++
+	}
+
+	return &dto.YearAnalyticsResponse{
+		Success:         true,
+		Year:            year,
+		IsCurrentYear:   isCurrent,
+		DaysCounted:     end.YearDay(),
+		TotalHours:      agg.totalHours,
+		ActiveDays:      agg.activeDays,
+		BestDay:         agg.bestDay,
+		MonthlyTotals:   monthlyTotals,
+		ActivitySummary: agg.activitySummary,
+	}, nil
+}
+
+// AggregateByWeekday computes the average total hours per weekday over the last lookbackDays
+// (including today). Activity dates are stored as IST calendar dates, so a stored date's
+// Weekday() is already its IST weekday. Days with no activity are excluded from the
+// average unless includeZeroDays is set, in which case every calendar day in the window counts.
+// Returns seven entries, Monday first.
+func (s *AnalyticsService) AggregateByWeekday(userID uint, lookbackDays int, includeZeroDays bool) (*dto.WeekdayAnalyticsResponse, error) {
+	if lookbackDays <= 0 {
+		lookbackDays = constants.WeekdayInsightDefaultLookbackDays
+	}
+	if lookbackDays > constants.WeekdayInsightMaxLookbackDays {
+		lookbackDays = constants.WeekdayInsightMaxLookbackDays
+	}
+
+	end := istTodayDate()
+	start := end.AddDate(0, 0, -(lookbackDays - 1))
+	agg, err := s.aggregateRange(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	// Index 0 = Monday ... 6 = Sunday
+	var totals [7]float32
+	var samples [7]int
+	weekdayIndex := func(d time.Time) int {
+		return (int(d.Weekday()) + 6) % 7
+	}
+
+	if includeZeroDays {
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			samples[weekdayIndex(day)]++
+		}
+	}
+	for date, hours := range agg.dailyTotals {
+		d, err := time.Parse(constants.DateFormat, date)
+		if err != nil {
+			continue
+		}
+		idx := weekdayIndex(d)
+		totals[idx] += hours
+		if !includeZeroDays {
+			samples[idx]++
+		}
+	}
+
+	dayNames := []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+	weekdays := make([]dto.WeekdayAverage, 7)
+	bestWeekday := ""
+	var bestAverage float32
+	for i := range weekdays {
+		var average float32
+		if samples[i] > 0 {
+			average = totals[i] / float32(samples[i])
+		}
+		weekdays[i] = dto.WeekdayAverage{
+			Weekday:      dayNames[i],
+			AverageHours: average,
+			SampleCount:  samples[i],
+		}
+		if average > bestAverage {
+			bestAverage = average
+			bestWeekday = dayNames[i]
+		}
+	}
+
+	return &dto.WeekdayAnalyticsResponse{
+		Success:         true,
+		LookbackDays:    lookbackDays,
+		IncludeZeroDays: includeZeroDays,
+		BestWeekday:     bestWeekday,
+		Weekdays:        weekdays,
+	}, nil
+}
+
+// GetTrendingActivities returns the top activity types across public users over the last
+// TrendingActivitiesDays days (IST, including today)
+func (s *AnalyticsService) GetTrendingActivities() (*dto.TrendingActivitiesResponse, error) {
+	since := istTodayDate().AddDate(0, 0, -(constants.TrendingActivitiesDays - 1))
+	totals, err := s.activityRepo.TopActivityTypesSince(since, constants.TrendingActivitiesLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	activities := make([]dto.TrendingActivityDTO, 0, len(totals))
+	for _, t := range totals {
+		activities = append(activities, dto.TrendingActivityDTO{
+			Name:       t.Name,
+			TotalHours: t.TotalHours,
+			EntryCount: t.EntryCount,
+			UserCount:  t.UserCount,
+		})
+	}
+	return &dto.TrendingActivitiesResponse{
+		Success:    true,
+		Days:       constants.TrendingActivitiesDays,
+		Since:      since.Format(constants.DateFormat),
+		Activities: activities,
+	}, nil
+}
+
 func (s *AnalyticsService) getStreakInfo(userID uint) dto.StreakInfo {
 	var streakInfo dto.StreakInfo
 
@@ -2,33 +2,53 @@
 package services
 
 import (
+	"context"
 	"time"
 
 	"github.com/aman1117/backend/internal/constants"
 	"github.com/aman1117/backend/internal/dto"
+	"github.com/aman1117/backend/internal/logger"
 	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/pkg/models"
 )
 
 // BadgeService handles badge-related business logic
 type BadgeService struct {
-	badgeRepo *repository.BadgeRepository
-	userRepo  *repository.UserRepository
+	badgeRepo    *repository.BadgeRepository
+	userRepo     *repository.UserRepository
+	activityRepo *repository.ActivityRepository
+	notifSvc     *NotificationService
 }
 
 // NewBadgeService creates a new BadgeService
-func NewBadgeService(badgeRepo *repository.BadgeRepository, userRepo *repository.UserRepository) *BadgeService {
+func NewBadgeService(
+	badgeRepo *repository.BadgeRepository,
+	userRepo *repository.UserRepository,
+	activityRepo *repository.ActivityRepository,
+	notifSvc *NotificationService,
+) *BadgeService {
 	return &BadgeService{
-		badgeRepo: badgeRepo,
-		userRepo:  userRepo,
+		badgeRepo:    badgeRepo,
+		userRepo:     userRepo,
+		activityRepo: activityRepo,
+		notifSvc:     notifSvc,
 	}
 }
 
-// CheckAndAwardBadges checks if user qualifies for new badges and awards them
+// CheckAndAwardBadges checks if user qualifies for new streak or hour badges and awards them.
+// Each newly earned family sends one badge notification, for its highest badge, so a
+// backfill for a long-time user doesn't send several at once.
 // Returns newly awarded badges
-func (s *BadgeService) CheckAndAwardBadges(userID uint, longestStreak int) ([]dto.BadgeDTO, error) {
+func (s *BadgeService) CheckAndAwardBadges(ctx context.Context, userID uint, longestStreak int) ([]dto.BadgeDTO, error) {
 	// Get all badge keys the user qualifies for
 	eligibleKeys := constants.GetEligibleBadgeKeys(longestStreak)
+	if s.activityRepo != nil {
+		totalHours, err := s.activityRepo.SumHoursByUser(userID)
+		if err != nil {
+			return nil, err
+		}
+		eligibleKeys = append(eligibleKeys, constants.GetEligibleHourBadgeKeys(totalHours)...)
+	}
 	if len(eligibleKeys) == 0 {
 		return nil, nil
 	}
@@ -45,47 +65,64 @@ func (s *BadgeService) CheckAndAwardBadges(userID uint, longestStreak int) ([]dt
 		earnedSet[key] = true
 	}
 
-	// Find new badges to award
-	var newBadges []models.UserBadge
+	// Award missing badges. Inserts skip conflicts, so a badge awarded concurrently
+	// (e.g. by the daily job) isn't returned or notified twice.
+	var newBadgeDTOs []dto.BadgeDTO
+	topByFamily := make(map[string]*constants.Badge)
 	now := time.Now()
 	for _, key := range eligibleKeys {
-		if !earnedSet[key] {
-			newBadges = append(newBadges, models.UserBadge{
-				UserID:   userID,
-				BadgeKey: key,
-				EarnedAt: now,
-			})
+		if earnedSet[key] {
+			continue
+		}
+		badgeDef := constants.GetBadgeByKey(key)
+		if badgeDef == nil {
+			continue
 		}
-	}
 
-	if len(newBadges) == 0 {
-		return nil, nil
-	}
+		inserted, err := s.badgeRepo.CreateIfMissing(&models.UserBadge{
+			UserID:   userID,
+			BadgeKey: key,
+			EarnedAt: now,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if !inserted {
+			continue
+		}
 
-	// Save new badges
-	if err := s.badgeRepo.CreateBatch(newBadges); err != nil {
-		return nil, err
+		newBadgeDTOs = append(newBadgeDTOs, dto.BadgeDTO{
+			Key:       badgeDef.Key,
+			Name:      badgeDef.Name,
+			Icon:      badgeDef.Icon,
+			Color:     badgeDef.Color,
+			Threshold: badgeDef.Threshold,
+			Family:    badgeDef.Family,
+			Earned:    true,
+			EarnedAt:  now.Format(constants.DateFormat),
+		})
+		if top := topByFamily[badgeDef.Family]; top == nil || badgeDef.Threshold > top.Threshold {
+			topByFamily[badgeDef.Family] = badgeDef
+		}
 	}
 
-	// Convert to DTOs
-	var newBadgeDTOs []dto.BadgeDTO
-	for _, badge := range newBadges {
-		badgeDef := constants.GetBadgeByKey(badge.BadgeKey)
-		if badgeDef != nil {
-			newBadgeDTOs = append(newBadgeDTOs, dto.BadgeDTO{
-				Key:       badgeDef.Key,
-				Name:      badgeDef.Name,
-				Icon:      badgeDef.Icon,
-				Color:     badgeDef.Color,
-				Threshold: badgeDef.Threshold,
-				EarnedAt:  badge.EarnedAt.Format(constants.DateFormat),
-			})
-		}
+	for _, badge := range topByFamily {
+		s.notifyUnlocked(ctx, userID, badge)
 	}
 
 	return newBadgeDTOs, nil
 }
 
+// notifyUnlocked sends a badge notification. Failures are logged; the badge is already saved.
+func (s *BadgeService) notifyUnlocked(ctx context.Context, userID uint, badge *constants.Badge) {
+	if s.notifSvc == nil {
+		return
+	}
+	if err := s.notifSvc.NotifyBadgeUnlocked(ctx, userID, badge.Key, badge.Name, badge.Icon); err != nil {
+		logger.Sugar.Warnw("Failed to send badge notification", "user_id", userID, "badge", badge.Key, "error", err)
+	}
+}
+
 // GetUserBadges returns all badges for a user with their definitions
 func (s *BadgeService) GetUserBadges(userID uint) ([]dto.BadgeDTO, error) {
 	earnedBadges, err := s.badgeRepo.FindByUserID(userID)
@@ -108,6 +145,7 @@ func (s *BadgeService) GetUserBadges(userID uint) ([]dto.BadgeDTO, error) {
 			Icon:      badgeDef.Icon,
 			Color:     badgeDef.Color,
 			Threshold: badgeDef.Threshold,
+			Family:    badgeDef.Family,
 			Earned:    false,
 		}
 		if earnedAt, ok := earnedMap[badgeDef.Key]; ok {
@@ -120,7 +158,36 @@ func (s *BadgeService) GetUserBadges(userID uint) ([]dto.BadgeDTO, error) {
 	return badges, nil
 }
 
-// GetBadgesByUsername returns all badges for a user by username (always public)
+// GetEarnedBadges returns only the badges a user has earned, oldest first, without
+// any progress towards unearned ones
+func (s *BadgeService) GetEarnedBadges(userID uint) ([]dto.BadgeDTO, error) {
+	earnedBadges, err := s.badgeRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	badges := make([]dto.BadgeDTO, 0, len(earnedBadges))
+	for _, earned := range earnedBadges {
+		badgeDef := constants.GetBadgeByKey(earned.BadgeKey)
+		if badgeDef == nil {
+			continue
+		}
+		badges = append(badges, dto.BadgeDTO{
+			Key:       badgeDef.Key,
+			Name:      badgeDef.Name,
+			Icon:      badgeDef.Icon,
+			Color:     badgeDef.Color,
+			Threshold: badgeDef.Threshold,
+			Family:    badgeDef.Family,
+			Earned:    true,
+			EarnedAt:  earned.EarnedAt.Format(constants.DateFormat),
+		})
+	}
+	return badges, nil
+}
+
+// GetBadgesByUsername returns all badges for a user by username. It does not check
+// profile access; handlers must call FollowService.CheckProfileAccess first.
 func (s *BadgeService) GetBadgesByUsername(username string) ([]dto.BadgeDTO, error) {
 	user, err := s.userRepo.FindByUsername(username)
 	if err != nil {
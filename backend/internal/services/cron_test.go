@@ -0,0 +1,93 @@
+package services
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/internal/testutil"
+	"github.com/aman1117/backend/pkg/models"
+)
+
+func TestRunDailyJobConcurrentReplicasClaimOnce(t *testing.T) {
+	db := testutil.OpenDB(t)
+	users := []*models.User{
+		testutil.CreateUser(t, db, "replicauser1"),
+		testutil.CreateUser(t, db, "replicauser2"),
+	}
+
+	userRepo := repository.NewUserRepository(db)
+	streakRepo := repository.NewStreakRepository(db)
+	streakSvc := NewStreakService(streakRepo, repository.NewActivityRepository(db), userRepo)
+	newReplica := func(instanceID string) *CronService {
+		return &CronService{
+			userRepo:       userRepo,
+			streakRepo:     streakRepo,
+			cronJobLogRepo: repository.NewCronJobLogRepository(db),
+			streakSvc:      streakSvc,
+			instanceID:     instanceID,
+		}
+	}
+	replicas := []*CronService{newReplica("replica-a"), newReplica("replica-b")}
+
+	errs := make([]error, len(replicas))
+	var ready, done sync.WaitGroup
+	begin := make(chan struct{})
+	for i, replica := range replicas {
+		ready.Add(1)
+		done.Add(1)
+		go func() {
+			defer done.Done()
+			ready.Done()
+			<-begin
+			errs[i] = replica.RunDailyJob(context.Background())
+		}()
+	}
+	ready.Wait()
+	close(begin)
+	done.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("%s: RunDailyJob: %v", replicas[i].instanceID, err)
+		}
+	}
+
+	// All test users share the default zone, so there is a single bucket and job row
+	var logs []models.CronJobLog
+	if err := db.Where("job_name = ?", models.CronJobDailyStreak).Find(&logs).Error; err != nil {
+		t.Fatalf("load job logs: %v", err)
+	}
+	if len(logs) != 1 {
+		t.Fatalf("%d daily streak job logs, want 1", len(logs))
+	}
+	jobLog := logs[0]
+
+	winner, loser := "replica-a", "replica-b"
+	if jobLog.InstanceID == loser {
+		winner, loser = loser, winner
+	}
+	if jobLog.InstanceID != winner {
+		t.Fatalf("job claimed by %q, want one of the replicas", jobLog.InstanceID)
+	}
+	if jobLog.SkipCount != 1 || jobLog.LastSkippedBy != loser {
+		t.Errorf("skip_count = %d, last_skipped_by = %q, want 1 and %q", jobLog.SkipCount, jobLog.LastSkippedBy, loser)
+	}
+	if jobLog.Status != models.CronJobStatusCompleted || jobLog.UsersCount != len(users) {
+		t.Errorf("job status = %s with %d users, want %s with %d", jobLog.Status, jobLog.UsersCount, models.CronJobStatusCompleted, len(users))
+	}
+
+	// Only the winner processed users: each has a single streak row for the day
+	for _, user := range users {
+		var count int64
+		if err := db.Model(&models.Streak{}).
+			Where("user_id = ? AND activity_date = ? AND activity_name IS NULL", user.ID, jobLog.JobDate).
+			Count(&count).Error; err != nil {
+			t.Fatalf("count streaks: %v", err)
+		}
+		if count != 1 {
+			t.Errorf("user %s has %d streak rows for the job date, want 1", user.Username, count)
+		}
+	}
+}
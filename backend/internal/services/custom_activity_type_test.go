@@ -0,0 +1,100 @@
+package services
+
+import (
+	"context"
+	"testing"
+
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/internal/testutil"
+	"github.com/aman1117/backend/pkg/models"
+)
+
+const (
+	readingTileID = "3f1c2a4e-8b7d-4c6e-9a1f-0d2e3b4c5a6f"
+	guitarTileID  = "7a9b8c6d-5e4f-4a3b-8c2d-1e0f9a8b7c6d"
+)
+
+func customTile(id, name string) map[string]interface{} {
+	return map[string]interface{}{"id": id, "name": name, "icon": "BookOpen", "color": "#22c55e"}
+}
+
+func TestCustomActivityTypesFollowTileConfig(t *testing.T) {
+	db := testutil.OpenDB(t)
+	owner := testutil.CreateUser(t, db, "tileowner")
+	other := testutil.CreateUser(t, db, "otheruser")
+
+	typeRepo := repository.NewCustomActivityTypeRepository(db)
+	tileSvc := NewTileConfigService(
+		repository.NewTileConfigRepository(db),
+		repository.NewUserRepository(db),
+		repository.NewActivityPhotoRepository(db),
+		repository.NewActivityRepository(db),
+		nil, nil,
+	)
+	activitySvc := &ActivityService{typeRepo: typeRepo}
+
+	expectTypes := func(t *testing.T, want map[string]string) {
+		t.Helper()
+		types, err := typeRepo.FindByUserID(owner.ID)
+		if err != nil {
+			t.Fatalf("FindByUserID: %v", err)
+		}
+		got := make(map[string]string, len(types))
+		for _, ct := range types {
+			got[ct.TileID] = ct.Name
+		}
+		if len(got) != len(want) {
+			t.Fatalf("custom activity types = %v, want %v", got, want)
+		}
+		for id, name := range want {
+			if got[id] != name {
+				t.Fatalf("custom activity types = %v, want %v", got, want)
+			}
+		}
+	}
+	expectValid := func(t *testing.T, userID uint, tileID string, want bool) {
+		t.Helper()
+		err := activitySvc.validateCustomActivity(userID, models.ActivityName(models.CustomTilePrefix+tileID))
+		if got := err == nil; got != want {
+			t.Fatalf("validateCustomActivity(user %d, %s) error = %v, want valid = %v", userID, tileID, err, want)
+		}
+	}
+
+	t.Run("saving the tile config defines the types", func(t *testing.T) {
+		config := models.JSONB{"customTiles": []interface{}{
+			customTile(readingTileID, "Reading"),
+			customTile(guitarTileID, "Guitar"),
+		}}
+		if err := tileSvc.SaveConfig(owner.ID, config); err != nil {
+			t.Fatalf("SaveConfig: %v", err)
+		}
+		expectTypes(t, map[string]string{readingTileID: "Reading", guitarTileID: "Guitar"})
+		expectValid(t, owner.ID, readingTileID, true)
+		expectValid(t, owner.ID, guitarTileID, true)
+	})
+
+	t.Run("types are per user", func(t *testing.T) {
+		expectValid(t, other.ID, readingTileID, false)
+	})
+
+	t.Run("undefined types are rejected", func(t *testing.T) {
+		expectValid(t, owner.ID, "00000000-0000-4000-8000-000000000000", false)
+	})
+
+	t.Run("renames update and dropped tiles remove the type", func(t *testing.T) {
+		config := models.JSONB{"customTiles": []interface{}{customTile(readingTileID, "Books")}}
+		if err := tileSvc.SaveConfig(owner.ID, config); err != nil {
+			t.Fatalf("SaveConfig: %v", err)
+		}
+		expectTypes(t, map[string]string{readingTileID: "Books"})
+		expectValid(t, owner.ID, guitarTileID, false)
+	})
+
+	t.Run("deleting the tile removes the type", func(t *testing.T) {
+		if _, err := tileSvc.DeleteCustomTile(context.Background(), owner.ID, readingTileID, false); err != nil {
+			t.Fatalf("DeleteCustomTile: %v", err)
+		}
+		expectTypes(t, map[string]string{})
+		expectValid(t, owner.ID, readingTileID, false)
+	})
+}
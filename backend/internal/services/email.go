@@ -2,7 +2,9 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"html"
 	"net/smtp"
 	"os"
 	"strings"
@@ -10,6 +12,7 @@ import (
 
 	"github.com/aman1117/backend/internal/config"
 	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
 	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/pkg/models"
@@ -134,6 +137,15 @@ func (s *EmailService) SendVerificationEmail(email, username, token string) erro
 	return s.sender.Send([]string{email}, "Verify Your Email - Growth Tracker", htmlContent)
 }
 
+// SendEmailChangeVerification sends a confirmation link to the address a user wants to switch to
+func (s *EmailService) SendEmailChangeVerification(newEmail, username, token string) error {
+	confirmLink := fmt.Sprintf("%s/confirm-email-change?token=%s", s.frontendURL, token)
+
+	htmlContent := s.buildEmailChangeHTML(username, newEmail, confirmLink)
+
+	return s.sender.Send([]string{newEmail}, "Confirm Your New Email - Growth Tracker", htmlContent)
+}
+
 func (s *EmailService) buildVerificationEmailHTML(username, verifyLink string) string {
 	return fmt.Sprintf(`
 <!DOCTYPE html>
@@ -268,6 +280,179 @@ func (s *EmailService) buildPasswordResetHTML(username, resetLink string) string
 `, username, resetLink, resetLink, resetLink, s.frontendURL)
 }
 
+func (s *EmailService) buildEmailChangeHTML(username, newEmail, confirmLink string) string {
+	return fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f5f5f5;">
+    <table width="100%%" cellpadding="0" cellspacing="0" style="background-color: #f5f5f5; padding: 40px 20px;">
+        <tr>
+            <td align="center">
+                <table width="100%%" style="max-width: 480px; background-color: #ffffff; border-radius: 12px; box-shadow: 0 2px 8px rgba(0,0,0,0.08);">
+                    <tr>
+                        <td style="padding: 40px 32px;">
+                            <div style="text-align: center; margin-bottom: 32px;">
+                                <h1 style="margin: 0; font-size: 24px; font-weight: 700; color: #1a1a1a;">
+                                    📧 Confirm Your New Email
+                                </h1>
+                            </div>
+                            
+                            <p style="margin: 0 0 16px; font-size: 16px; color: #333; line-height: 1.5;">
+                                Hi <strong>%s</strong>,
+                            </p>
+                            <p style="margin: 0 0 24px; font-size: 16px; color: #333; line-height: 1.5;">
+                                We received a request to change the email address on your Growth Tracker account to <strong>%s</strong>. Click the button below to confirm:
+                            </p>
+                            
+                            <div style="text-align: center; margin: 32px 0;">
+                                <a href="%s" style="display: inline-block; padding: 14px 32px; background-color: #0066ff; color: #ffffff; text-decoration: none; font-weight: 600; font-size: 16px; border-radius: 8px;">
+                                    Confirm Email Change
+                                </a>
+                            </div>
+                            
+                            <p style="margin: 0 0 16px; font-size: 14px; color: #666; line-height: 1.5;">
+                                ⏰ This link will expire in <strong>1 hour</strong>.
+                            </p>
+                            
+                            <div style="background-color: #f8f9fa; border-radius: 8px; padding: 16px; margin-top: 24px;">
+                                <p style="margin: 0; font-size: 14px; color: #666; line-height: 1.5;">
+                                    If you didn't request this change, you can safely ignore this email. Your account email will remain unchanged.
+                                </p>
+                            </div>
+                            
+                            <p style="margin: 24px 0 0; font-size: 12px; color: #999; line-height: 1.5; word-break: break-all;">
+                                If the button doesn't work, copy and paste this link into your browser:<br>
+                                <a href="%s" style="color: #0066ff;">%s</a>
+                            </p>
+                        </td>
+                    </tr>
+                    
+                    <tr>
+                        <td style="padding: 24px 32px; border-top: 1px solid #eee; text-align: center;">
+                            <p style="margin: 0; font-size: 12px; color: #999;">
+                                <a href="%s" style="color: #0066ff; text-decoration: none;">Growth Tracker</a> • Track your daily activities
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`, username, newEmail, confirmLink, confirmLink, confirmLink, s.frontendURL)
+}
+
+// SendWeeklyDigest sends the Monday "here's your week" summary for the week in analytics.
+// newBadges are the badges earned during that week.
+func (s *EmailService) SendWeeklyDigest(user *models.User, analytics *dto.WeekAnalyticsResponse, newBadges []constants.Badge) error {
+	htmlContent := s.buildWeeklyDigestHTML(user.Username, analytics, newBadges)
+
+	return s.sender.Send([]string{user.Email}, "Your Week in Review - Growth Tracker", htmlContent)
+}
+
+func (s *EmailService) buildWeeklyDigestHTML(username string, analytics *dto.WeekAnalyticsResponse, newBadges []constants.Badge) string {
+	var activityRows strings.Builder
+	for i, a := range analytics.ActivitySummary {
+		if i >= constants.WeeklyDigestTopActivities {
+			break
+		}
+		name := strings.TrimPrefix(string(a.Name), models.CustomTilePrefix)
+		activityRows.WriteString(fmt.Sprintf(`
+                                <tr>
+                                    <td style="padding: 8px 0; font-size: 15px; color: #333;">%s</td>
+                                    <td style="padding: 8px 0; font-size: 15px; color: #333; text-align: right;"><strong>%.1fh</strong></td>
+                                </tr>`, html.EscapeString(name), a.TotalHours))
+	}
+
+	badgeSection := ""
+	if len(newBadges) > 0 {
+		names := make([]string, len(newBadges))
+		for i, b := range newBadges {
+			names[i] = b.Name
+		}
+		badgeSection = fmt.Sprintf(`
+                            <div style="background-color: #fefce8; border-radius: 8px; padding: 16px; margin-top: 24px;">
+                                <p style="margin: 0; font-size: 14px; color: #333; line-height: 1.5;">
+                                    🏅 New badges this week: <strong>%s</strong>
+                                </p>
+                            </div>`, strings.Join(names, ", "))
+	}
+
+	return fmt.Sprintf(`
+<!DOCTYPE html>
+<html>
+<head>
+    <meta charset="UTF-8">
+    <meta name="viewport" content="width=device-width, initial-scale=1.0">
+</head>
+<body style="margin: 0; padding: 0; font-family: -apple-system, BlinkMacSystemFont, 'Segoe UI', Roboto, 'Helvetica Neue', Arial, sans-serif; background-color: #f5f5f5;">
+    <table width="100%%" cellpadding="0" cellspacing="0" style="background-color: #f5f5f5; padding: 40px 20px;">
+        <tr>
+            <td align="center">
+                <table width="100%%" style="max-width: 480px; background-color: #ffffff; border-radius: 12px; box-shadow: 0 2px 8px rgba(0,0,0,0.08);">
+                    <tr>
+                        <td style="padding: 40px 32px;">
+                            <div style="text-align: center; margin-bottom: 32px;">
+                                <h1 style="margin: 0; font-size: 24px; font-weight: 700; color: #1a1a1a;">
+                                    📊 Your Week in Review
+                                </h1>
+                                <p style="margin: 8px 0 0; font-size: 14px; color: #666;">Week of %s</p>
+                            </div>
+                            
+                            <p style="margin: 0 0 24px; font-size: 16px; color: #333; line-height: 1.5;">
+                                Hi <strong>%s</strong>, here's how your week went:
+                            </p>
+                            
+                            <table width="100%%" cellpadding="0" cellspacing="0" style="margin-bottom: 24px;">
+                                <tr>
+                                    <td align="center" style="padding: 16px; background-color: #f0fdf4; border-radius: 8px;">
+                                        <div style="font-size: 28px; font-weight: 700; color: #22c55e;">%.1fh</div>
+                                        <div style="font-size: 13px; color: #666;">Total hours</div>
+                                    </td>
+                                    <td width="16"></td>
+                                    <td align="center" style="padding: 16px; background-color: #fff7ed; border-radius: 8px;">
+                                        <div style="font-size: 28px; font-weight: 700; color: #f97316;">🔥 %d</div>
+                                        <div style="font-size: 13px; color: #666;">Current streak</div>
+                                    </td>
+                                </tr>
+                            </table>
+                            
+                            <p style="margin: 0 0 8px; font-size: 14px; font-weight: 600; color: #666; text-transform: uppercase;">
+                                Top activities
+                            </p>
+                            <table width="100%%" cellpadding="0" cellspacing="0">%s
+                            </table>
+                            %s
+                            <div style="text-align: center; margin: 32px 0 0;">
+                                <a href="%s" style="display: inline-block; padding: 14px 32px; background-color: #22c55e; color: #ffffff; text-decoration: none; font-weight: 600; font-size: 16px; border-radius: 8px;">
+                                    Keep It Going
+                                </a>
+                            </div>
+                        </td>
+                    </tr>
+                    
+                    <tr>
+                        <td style="padding: 24px 32px; border-top: 1px solid #eee; text-align: center;">
+                            <p style="margin: 0; font-size: 12px; color: #999;">
+                                <a href="%s" style="color: #22c55e; text-decoration: none;">Growth Tracker</a> • You can turn off weekly digests in your settings
+                            </p>
+                        </td>
+                    </tr>
+                </table>
+            </td>
+        </tr>
+    </table>
+</body>
+</html>
+`, analytics.WeekStart, username, analytics.TotalHoursThisWeek, analytics.Streak.Current,
+		activityRows.String(), badgeSection, s.frontendURL, s.frontendURL)
+}
+
 // ==================== Cron Service ====================
 
 // CronService handles scheduled job logic
@@ -278,7 +463,15 @@ type CronService struct {
 	streakSvc      *StreakService
 	emailSvc       *EmailService
 	notifSvc       *NotificationService
+	followSvc      *FollowService
+	analyticsSvc   *AnalyticsService
+	badgeRepo      *repository.BadgeRepository
+	badgeSvc       *BadgeService
+	photoSvc       *ActivityPhotoService // optional; nil when blob storage is not configured
 	instanceID     string
+
+	// Notification retention policy used by CleanupOldNotifications
+	notifRetention *config.NotificationConfig
 }
 
 // NewCronService creates a new CronService
@@ -289,6 +482,12 @@ func NewCronService(
 	streakSvc *StreakService,
 	emailSvc *EmailService,
 	notifSvc *NotificationService,
+	followSvc *FollowService,
+	notifRetention *config.NotificationConfig,
+	analyticsSvc *AnalyticsService,
+	badgeRepo *repository.BadgeRepository,
+	badgeSvc *BadgeService,
+	photoSvc *ActivityPhotoService,
 ) *CronService {
 	// Generate instance ID from hostname or random string for tracking
 	instanceID := os.Getenv("HOSTNAME")
@@ -302,69 +501,230 @@ func NewCronService(
 		streakSvc:      streakSvc,
 		emailSvc:       emailSvc,
 		notifSvc:       notifSvc,
+		followSvc:      followSvc,
+		analyticsSvc:   analyticsSvc,
+		badgeRepo:      badgeRepo,
+		badgeSvc:       badgeSvc,
+		photoSvc:       photoSvc,
 		instanceID:     instanceID,
+		notifRetention: notifRetention,
 	}
 }
 
 // RunDailyJob runs the daily streak processing job.
-// Uses atomic job claiming to prevent duplicate execution in multi-replica environments.
+// Scheduled hourly: users are bucketed by timezone, and each bucket is processed
+// on the first run after its local midnight. Uses atomic job claiming per
+// (timezone, local date) to prevent duplicate execution in multi-replica environments.
 func (s *CronService) RunDailyJob(ctx context.Context) error {
-	// Compute today's date in IST
-	loc, _ := time.LoadLocation(constants.TimezoneIST)
-	nowIST := time.Now().In(loc)
-	todayIST := time.Date(
-		nowIST.Year(),
-		nowIST.Month(),
-		nowIST.Day(),
-		0, 0, 0, 0,
-		loc,
-	)
+	_, err := s.runDailyJob(ctx, nil, false)
+	return err
+}
+
+// runDailyJob runs the daily streak job for every timezone bucket, on date if given
+// (buckets where date is still in the future are left alone) or on each bucket's local
+// today otherwise. Manual runs first release failed claims so the date can be retried.
+// Returns the job log of every bucket that was claimed or found already claimed.
+func (s *CronService) runDailyJob(ctx context.Context, date *time.Time, manual bool) ([]models.CronJobLog, error) {
+	users, err := s.userRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []models.CronJobLog
+	var firstErr error
+	for timezone, bucket := range bucketUsersByTimezone(users) {
+		loc := userLocation(timezone)
+		day := localToday(loc)
+		if date != nil {
+			bucketDay := startOfDay(date.Year(), date.Month(), date.Day(), loc)
+			if bucketDay.After(day) {
+				continue
+			}
+			day = bucketDay
+		}
+		if manual {
+			s.releaseFailedClaim(timezoneJobName(models.CronJobDailyStreak, timezone), day)
+		}
+
+		jobLog, err := s.runDailyJobForTimezone(ctx, timezone, bucket, day)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if jobLog != nil {
+			runs = append(runs, *jobLog)
+		}
+	}
+	return runs, firstErr
+}
+
+// runDailyJobForTimezone creates the day's streak records for users sharing a timezone.
+// Returns the job log it claimed, or the other replica's log if the job was already claimed.
+func (s *CronService) runDailyJobForTimezone(ctx context.Context, timezone string, users []models.User, today time.Time) (*models.CronJobLog, error) {
+	jobName := timezoneJobName(models.CronJobDailyStreak, timezone)
 
 	// Atomically try to claim this job - only one replica will succeed
 	var jobLog *models.CronJobLog
 	if s.cronJobLogRepo != nil {
-		claimedLog, claimed, err := s.cronJobLogRepo.TryClaimJob(models.CronJobDailyStreak, todayIST, s.instanceID)
+		claimedLog, claimed, err := s.cronJobLogRepo.TryClaimJob(jobName, today, s.instanceID)
 		if err != nil {
-			logger.Sugar.Warnw("Failed to claim daily streak job", "error", err)
+			logger.Sugar.Warnw("Failed to claim daily streak job", "timezone", timezone, "error", err)
 			// Continue without job logging - better to risk duplicate than skip entirely
 		} else if !claimed {
-			logger.Sugar.Infow("Daily streak job already claimed by another instance, skipping",
-				"job_date", todayIST.Format(constants.DateFormat),
+			logger.Sugar.Debugw("Daily streak job already claimed, skipping",
+				"timezone", timezone,
+				"job_date", today.Format(constants.DateFormat),
 				"claimed_by", claimedLog.InstanceID,
-				"claimed_at", claimedLog.StartedAt,
 			)
-			return nil
+			return claimedLog, nil
 		} else {
 			jobLog = claimedLog
 			logger.Sugar.Infow("Successfully claimed daily streak job",
-				"job_date", todayIST.Format(constants.DateFormat),
+				"timezone", timezone,
+				"job_date", today.Format(constants.DateFormat),
 				"instance_id", s.instanceID,
 			)
 		}
 	}
 
-	users, err := s.userRepo.GetAll()
-	if err != nil {
-		s.updateJobLog(jobLog, models.CronJobStatusFailed, 0, err.Error())
-		return err
-	}
-
 	processedCount := 0
 	for _, user := range users {
-		if err := s.streakSvc.AddStreak(user.ID, todayIST, true); err != nil {
+		if err := s.streakSvc.AddStreak(user.ID, today, true, nil); err != nil {
 			s.updateJobLog(jobLog, models.CronJobStatusFailed, processedCount, err.Error())
-			return err
+			return jobLog, err
 		}
+		s.notifyStreakMilestones(ctx, user.ID, addLocalDays(today, -1))
+		s.awardBadges(ctx, user.ID, today)
 		processedCount++
 	}
 
 	s.updateJobLog(jobLog, models.CronJobStatusCompleted, processedCount, "")
 	logger.Sugar.Infow("Daily streak job completed",
+		"timezone", timezone,
 		"users_processed", processedCount,
 		"instance_id", s.instanceID,
 	)
 
-	return nil
+	return jobLog, nil
+}
+
+// releaseFailedClaim drops a failed run's claim so the job can be claimed again
+func (s *CronService) releaseFailedClaim(jobName string, jobDate time.Time) {
+	if s.cronJobLogRepo == nil {
+		return
+	}
+	if err := s.cronJobLogRepo.DeleteFailed(jobName, jobDate); err != nil {
+		logger.Sugar.Warnw("Failed to release failed cron job claim", "job_name", jobName, "error", err)
+	}
+}
+
+// bucketUsersByTimezone groups users by their resolved timezone name.
+// Empty or unknown zones resolve to IST.
+func bucketUsersByTimezone(users []models.User) map[string][]models.User {
+	buckets := make(map[string][]models.User)
+	for _, user := range users {
+		timezone := userLocation(user.Timezone).String()
+		buckets[timezone] = append(buckets[timezone], user)
+	}
+	return buckets
+}
+
+// timezoneJobName returns the cron job log name for a timezone bucket.
+// IST keeps the bare job name so existing job history stays continuous.
+func timezoneJobName(jobName, timezone string) string {
+	if timezone == istLocation.String() {
+		return jobName
+	}
+	return jobName + "@" + timezone
+}
+
+// notifyStreakMilestones sends milestone notifications for streaks that closed on date
+// (yesterday, now final) at a milestone length. Failures are logged and never fail the job.
+func (s *CronService) notifyStreakMilestones(ctx context.Context, userID uint, date time.Time) {
+	if s.notifSvc == nil {
+		return
+	}
+	dateStr := date.Format(constants.DateFormat)
+
+	if streak, err := s.streakRepo.FindByUserAndDate(userID, date); err == nil && streak != nil &&
+		constants.IsStreakMilestone(streak.Current) {
+		if err := s.notifSvc.NotifyStreakMilestone(ctx, userID, constants.StreakMilestoneAggregateType, streak.Current, dateStr); err != nil {
+			logger.Sugar.Warnw("Failed to send streak milestone notification", "user_id", userID, "error", err)
+		}
+	}
+
+	activityStreaks, err := s.streakRepo.FindLatestPerActivity(userID)
+	if err != nil {
+		return
+	}
+	for _, streak := range activityStreaks {
+		if streak.ActivityName == nil || streak.ActivityDate.Format(constants.DateFormat) != dateStr ||
+			!constants.IsStreakMilestone(streak.Current) {
+			continue
+		}
+		if err := s.notifSvc.NotifyStreakMilestone(ctx, userID, string(*streak.ActivityName), streak.Current, dateStr); err != nil {
+			logger.Sugar.Warnw("Failed to send streak milestone notification",
+				"user_id", userID,
+				"activity", *streak.ActivityName,
+				"error", err,
+			)
+		}
+	}
+}
+
+// awardBadges awards any streak or hour badges the user has newly qualified for, so
+// badges unlock even if the user never opens their own streak. Failures are logged only.
+func (s *CronService) awardBadges(ctx context.Context, userID uint, date time.Time) {
+	if s.badgeSvc == nil {
+		return
+	}
+	longest := 0
+	if streak, err := s.streakRepo.FindByUserAndDate(userID, date); err == nil && streak != nil {
+		longest = streak.Longest
+	}
+	if _, err := s.badgeSvc.CheckAndAwardBadges(ctx, userID, longest); err != nil {
+		logger.Sugar.Warnw("Failed to award badges", "user_id", userID, "error", err)
+	}
+}
+
+// ErrUnknownCronJob is returned when a manual run names a job that can't be triggered
+var ErrUnknownCronJob = errors.New("unknown cron job")
+
+// CronRunSummary is the outcome of a manually triggered cron job
+type CronRunSummary struct {
+	Job string
+	// Runs holds the job log of each timezone bucket the job claimed or found already claimed
+	Runs []models.CronJobLog
+	// Deleted is the number of notifications removed (notification_cleanup only)
+	Deleted int64
+}
+
+// RunJobNow runs a cron job on demand. Claims are still taken through TryClaimJob, so a
+// run that already completed is reported rather than repeated; failed runs are released
+// first so they can be retried. date is only supported for the daily streak job.
+func (s *CronService) RunJobNow(ctx context.Context, job string, date *time.Time) (*CronRunSummary, error) {
+	summary := &CronRunSummary{Job: job}
+	var err error
+
+	logger.Sugar.Infow("Manual cron job run", "job", job, "instance_id", s.instanceID)
+	switch job {
+	case models.CronJobDailyStreak:
+		summary.Runs, err = s.runDailyJob(ctx, date, true)
+	case models.CronJobStreakReminder:
+		summary.Runs, err = s.sendStreakReminders(ctx, true)
+	case models.CronJobNotificationCleanup:
+		summary.Deleted, err = s.cleanupOldNotifications(ctx)
+	default:
+		return nil, ErrUnknownCronJob
+	}
+	return summary, err
+}
+
+// GetJobHistory returns recent runs of a cron job (all jobs if jobName is empty), most recent first
+func (s *CronService) GetJobHistory(jobName string, limit int) ([]models.CronJobLog, error) {
+	if s.cronJobLogRepo == nil {
+		return nil, nil
+	}
+	return s.cronJobLogRepo.FindRecentByJobName(jobName, limit)
 }
 
 // updateJobLog updates a job log with completion status
@@ -382,59 +742,109 @@ func (s *CronService) updateJobLog(log *models.CronJobLog, status string, usersC
 }
 
 // SendStreakReminders sends push and in-app notifications to users who haven't logged today.
-// Runs at 10 PM IST to remind users while they still have time to log.
-// Uses atomic job claiming to prevent duplicate execution in multi-replica environments.
+// Scheduled hourly: each timezone bucket is reminded on the first run at or after 10 PM
+// local time, while users still have time to log.
+// Uses atomic job claiming per (timezone, local date) to prevent duplicate execution in multi-replica environments.
 func (s *CronService) SendStreakReminders(ctx context.Context) error {
+	_, err := s.sendStreakReminders(ctx, false)
+	return err
+}
+
+// sendStreakReminders reminds every timezone bucket that is due. Manual runs remind every
+// bucket regardless of local time and first release failed claims so they can be retried.
+// Returns the job log of every bucket that was claimed or found already claimed.
+func (s *CronService) sendStreakReminders(ctx context.Context, manual bool) ([]models.CronJobLog, error) {
 	if s.notifSvc == nil {
-		return fmt.Errorf("notification service not configured")
+		return nil, fmt.Errorf("notification service not configured")
 	}
 
-	loc, err := time.LoadLocation(constants.TimezoneIST)
+	users, err := s.userRepo.GetAll()
 	if err != nil {
-		return fmt.Errorf("failed to load timezone: %v", err)
+		return nil, err
 	}
 
-	nowIST := time.Now().In(loc)
-	todayIST := time.Date(nowIST.Year(), nowIST.Month(), nowIST.Day(), 0, 0, 0, 0, loc)
-	today := nowIST.Format(constants.DateFormat)
+	var runs []models.CronJobLog
+	var firstErr error
+	for timezone, bucket := range bucketUsersByTimezone(users) {
+		loc := userLocation(timezone)
+		if manual {
+			s.releaseFailedClaim(timezoneJobName(models.CronJobStreakReminder, timezone), localToday(loc))
+		} else if !streakReminderDue(time.Now(), loc) {
+			continue
+		}
+
+		jobLog, err := s.sendStreakRemindersForTimezone(ctx, timezone, bucket)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		if jobLog != nil {
+			runs = append(runs, *jobLog)
+		}
+	}
+	return runs, firstErr
+}
+
+// streakReminderDue reports whether now is at or past the reminder hour in loc
+func streakReminderDue(now time.Time, loc *time.Location) bool {
+	return now.In(loc).Hour() >= constants.StreakReminderLocalHour
+}
+
+// sendStreakRemindersForTimezone reminds users in one timezone who haven't logged today.
+// Returns the job log it claimed, or the other replica's log if the job was already claimed.
+func (s *CronService) sendStreakRemindersForTimezone(ctx context.Context, timezone string, users []models.User) (*models.CronJobLog, error) {
+	todayLocal := localToday(userLocation(timezone))
+	today := todayLocal.Format(constants.DateFormat)
+	jobName := timezoneJobName(models.CronJobStreakReminder, timezone)
 
 	// Atomically try to claim this job - only one replica will succeed
 	var jobLog *models.CronJobLog
 	if s.cronJobLogRepo != nil {
-		claimedLog, claimed, err := s.cronJobLogRepo.TryClaimJob(models.CronJobStreakReminder, todayIST, s.instanceID)
+		claimedLog, claimed, err := s.cronJobLogRepo.TryClaimJob(jobName, todayLocal, s.instanceID)
 		if err != nil {
-			logger.Sugar.Warnw("Failed to claim streak reminder job", "error", err)
+			logger.Sugar.Warnw("Failed to claim streak reminder job", "timezone", timezone, "error", err)
 			// Continue without job logging - better to risk duplicate than skip entirely
 		} else if !claimed {
-			logger.Sugar.Infow("Streak reminder job already claimed by another instance, skipping",
+			logger.Sugar.Debugw("Streak reminder job already claimed, skipping",
+				"timezone", timezone,
 				"job_date", today,
 				"claimed_by", claimedLog.InstanceID,
-				"claimed_at", claimedLog.StartedAt,
 			)
-			return nil
+			return claimedLog, nil
 		} else {
 			jobLog = claimedLog
 			logger.Sugar.Infow("Successfully claimed streak reminder job",
+				"timezone", timezone,
 				"job_date", today,
 				"instance_id", s.instanceID,
 			)
 		}
 	}
 
-	// Find users who haven't logged today (streak = 0 for today)
-	userIDs, err := s.streakRepo.FindUsersMissedStreak(today)
+	// Find users who haven't logged today (streak = 0 for today), limited to this bucket
+	missedIDs, err := s.streakRepo.FindUsersMissedStreak(today)
 	if err != nil {
 		s.updateJobLog(jobLog, models.CronJobStatusFailed, 0, err.Error())
-		return fmt.Errorf("failed to find users who missed streak: %w", err)
+		return jobLog, fmt.Errorf("failed to find users who missed streak: %w", err)
+	}
+	inBucket := make(map[uint]bool, len(users))
+	for _, user := range users {
+		inBucket[user.ID] = true
+	}
+	userIDs := make([]uint, 0, len(missedIDs))
+	for _, id := range missedIDs {
+		if inBucket[id] {
+			userIDs = append(userIDs, id)
+		}
 	}
 
 	if len(userIDs) == 0 {
-		logger.Sugar.Info("All users have logged today")
+		logger.Sugar.Infow("All users have logged today", "timezone", timezone)
 		s.updateJobLog(jobLog, models.CronJobStatusCompleted, 0, "")
-		return nil
+		return jobLog, nil
 	}
 
 	logger.Sugar.Infow("Sending streak reminders",
+		"timezone", timezone,
 		"user_count", len(userIDs),
 		"date", today,
 		"instance_id", s.instanceID,
@@ -455,73 +865,284 @@ func (s *CronService) SendStreakReminders(ctx context.Context) error {
 	}
 
 	logger.Sugar.Infow("Streak reminders completed",
+		"timezone", timezone,
 		"success", successCount,
 		"failed", failCount,
 		"instance_id", s.instanceID,
 	)
 
 	s.updateJobLog(jobLog, models.CronJobStatusCompleted, successCount, "")
-	return nil
+	return jobLog, nil
 }
 
 // CleanupOldNotifications removes old notifications based on retention policy
 // Should be called daily at off-peak hours (e.g., 3 AM)
 func (s *CronService) CleanupOldNotifications(ctx context.Context) error {
+	_, err := s.cleanupOldNotifications(ctx)
+	return err
+}
+
+// cleanupOldNotifications runs the retention cleanup and returns how many notifications it deleted
+func (s *CronService) cleanupOldNotifications(ctx context.Context) (int64, error) {
 	if s.notifSvc == nil {
-		return nil // Notification service not configured
+		return 0, nil // Notification service not configured
 	}
 
-	deleted, err := s.notifSvc.CleanupOldNotifications(ctx)
+	var readDays, unreadDays int
+	byType := make(map[models.NotificationType]int)
+	if s.notifRetention != nil {
+		readDays = s.notifRetention.ReadRetentionDays
+		unreadDays = s.notifRetention.UnreadRetentionDays
+		for name, days := range s.notifRetention.RetentionDaysByType {
+			if days > 0 {
+				byType[models.NotificationType(name)] = days
+			}
+		}
+	}
+
+	deleted, err := s.notifSvc.CleanupOldNotifications(ctx, readDays, unreadDays, byType)
 	if err != nil {
-		return fmt.Errorf("notification cleanup failed: %w", err)
+		return 0, fmt.Errorf("notification cleanup failed: %w", err)
 	}
 
-	if deleted > 0 {
-		logger.Sugar.Infow("Notification cleanup completed",
-			"deleted_count", deleted,
+	var total int64
+	for notifType, count := range deleted {
+		total += count
+		logger.Sugar.Infow("Notification cleanup deleted",
+			"type", notifType,
+			"deleted_count", count,
 		)
 	}
+	logger.Sugar.Infow("Notification cleanup completed",
+		"deleted_count", total,
+		"types", len(deleted),
+	)
 
-	return nil
+	return total, nil
 }
 
-// ==================== Blob Service ====================
+// RepairFollowCounters reconciles follow counters that are stale or have drifted from the edge tables.
+// Counters can drift when the async FollowEvent subscriber drops events.
+// Uses atomic job claiming to prevent duplicate execution in multi-replica environments.
+func (s *CronService) RepairFollowCounters(ctx context.Context) error {
+	if s.followSvc == nil {
+		return nil // Follow service not configured
+	}
 
-// BlobService handles profile picture storage
-type BlobService struct {
-	userRepo    *repository.UserRepository
-	accountName string
-	container   string
-	// blobClient would be the Azure SDK client
-	enabled bool
-}
+	loc, err := time.LoadLocation(constants.TimezoneIST)
+	if err != nil {
+		return fmt.Errorf("failed to load timezone: %v", err)
+	}
+	nowIST := time.Now().In(loc)
+	todayIST := time.Date(nowIST.Year(), nowIST.Month(), nowIST.Day(), 0, 0, 0, 0, loc)
 
-// NewBlobService creates a new BlobService
-func NewBlobService(userRepo *repository.UserRepository, cfg *config.AzureStorageConfig) *BlobService {
-	return &BlobService{
-		userRepo:    userRepo,
-		accountName: cfg.AccountName,
-		container:   cfg.ContainerName,
-		enabled:     cfg.ConnectionString != "",
+	// Atomically try to claim this job - only one replica will succeed
+	var jobLog *models.CronJobLog
+	if s.cronJobLogRepo != nil {
+		claimedLog, claimed, err := s.cronJobLogRepo.TryClaimJob(models.CronJobFollowCounterRepair, todayIST, s.instanceID)
+		if err != nil {
+			logger.Sugar.Warnw("Failed to claim follow counter repair job", "error", err)
+			// Continue without job logging - repair is idempotent
+		} else if !claimed {
+			logger.Sugar.Infow("Follow counter repair job already claimed by another instance, skipping",
+				"job_date", todayIST.Format(constants.DateFormat),
+				"claimed_by", claimedLog.InstanceID,
+				"claimed_at", claimedLog.StartedAt,
+			)
+			return nil
+		} else {
+			jobLog = claimedLog
+		}
 	}
-}
 
-// IsEnabled returns whether blob storage is configured
-func (s *BlobService) IsEnabled() bool {
-	return s.enabled
+	var checked, corrected, failed int
+	var cursor uint
+	for {
+		candidates, nextCursor, err := s.followSvc.FindCounterRepairCandidates(ctx, cursor, constants.FollowCounterRepairBatchSize)
+		if err != nil {
+			s.updateJobLog(jobLog, models.CronJobStatusFailed, corrected, err.Error())
+			return err
+		}
+
+		for _, candidate := range candidates {
+			checked++
+			if err := s.followSvc.ReconcileCounters(ctx, candidate.UserID); err != nil {
+				logger.Sugar.Warnw("Failed to reconcile follow counters",
+					"user_id", candidate.UserID,
+					"error", err,
+				)
+				failed++
+				continue
+			}
+			if candidate.Drifted {
+				corrected++
+			}
+		}
+
+		if nextCursor == 0 || nextCursor <= cursor {
+			break
+		}
+		cursor = nextCursor
+	}
+
+	logger.Sugar.Infow("Follow counter repair completed",
+		"checked", checked,
+		"corrected", corrected,
+		"failed", failed,
+		"instance_id", s.instanceID,
+	)
+
+	s.updateJobLog(jobLog, models.CronJobStatusCompleted, corrected, "")
+	return nil
 }
 
-// GetUser retrieves a user by ID
-func (s *BlobService) GetUser(userID uint) (*models.User, error) {
-	return s.userRepo.FindByID(userID)
+// CleanupOrphanedBlobs deletes story photo blobs left without an ActivityPhoto row, e.g. by a
+// crash between upload and save. Uses atomic job claiming per IST day to prevent duplicate
+// execution in multi-replica environments.
+func (s *CronService) CleanupOrphanedBlobs(ctx context.Context) error {
+	if s.photoSvc == nil {
+		return nil // Blob storage not configured
+	}
+
+	todayIST := localToday(istLocation)
+
+	// Atomically try to claim this job - only one replica will succeed
+	var jobLog *models.CronJobLog
+	if s.cronJobLogRepo != nil {
+		claimedLog, claimed, err := s.cronJobLogRepo.TryClaimJob(models.CronJobOrphanBlobCleanup, todayIST, s.instanceID)
+		if err != nil {
+			logger.Sugar.Warnw("Failed to claim orphan blob cleanup job", "error", err)
+			// Continue without job logging - the grace period keeps reruns safe
+		} else if !claimed {
+			logger.Sugar.Infow("Orphan blob cleanup job already claimed by another instance, skipping",
+				"job_date", todayIST.Format(constants.DateFormat),
+				"claimed_by", claimedLog.InstanceID,
+			)
+			return nil
+		} else {
+			jobLog = claimedLog
+		}
+	}
+
+	result, err := s.photoSvc.CleanupOrphanedBlobs(ctx, constants.OrphanBlobGracePeriod)
+	if err != nil {
+		deleted := 0
+		if result != nil {
+			deleted = result.Deleted
+		}
+		s.updateJobLog(jobLog, models.CronJobStatusFailed, deleted, err.Error())
+		return fmt.Errorf("orphan blob cleanup failed: %w", err)
+	}
+
+	logger.Sugar.Infow("Orphan blob cleanup completed",
+		"scanned", result.Scanned,
+		"deleted", result.Deleted,
+		"failed", result.Failed,
+		"bytes_reclaimed", result.BytesReclaimed,
+		"instance_id", s.instanceID,
+	)
+
+	s.updateJobLog(jobLog, models.CronJobStatusCompleted, result.Deleted, "")
+	return nil
 }
 
-// UpdateProfilePic updates the user's profile picture URL and thumbnail
-func (s *BlobService) UpdateProfilePic(userID uint, url *string, thumbURL *string) error {
-	return s.userRepo.UpdateProfilePic(userID, url, thumbURL)
+// SendWeeklyDigests emails each user a summary of the previous IST week (Monday-Sunday).
+// Skips users who opted out, deactivated accounts and users with no hours logged that week.
+// Uses atomic job claiming per week to prevent duplicate execution in multi-replica environments.
+func (s *CronService) SendWeeklyDigests(ctx context.Context) error {
+	if s.emailSvc == nil || s.analyticsSvc == nil {
+		return nil // Email or analytics not configured
+	}
+
+	weekStart := currentISTWeekStart().AddDate(0, 0, -7)
+	weekStartStr := weekStart.Format(constants.DateFormat)
+
+	// Atomically try to claim this job - only one replica will succeed
+	var jobLog *models.CronJobLog
+	if s.cronJobLogRepo != nil {
+		claimedLog, claimed, err := s.cronJobLogRepo.TryClaimJob(models.CronJobWeeklyDigest, weekStart, s.instanceID)
+		if err != nil {
+			logger.Sugar.Warnw("Failed to claim weekly digest job", "error", err)
+			// Continue without job logging - better to risk duplicate than skip entirely
+		} else if !claimed {
+			logger.Sugar.Infow("Weekly digest job already claimed by another instance, skipping",
+				"week_start", weekStartStr,
+				"claimed_by", claimedLog.InstanceID,
+			)
+			return nil
+		} else {
+			jobLog = claimedLog
+		}
+	}
+
+	users, err := s.userRepo.GetAll()
+	if err != nil {
+		s.updateJobLog(jobLog, models.CronJobStatusFailed, 0, err.Error())
+		return err
+	}
+
+	// Badge earned_at is a timestamp; the analytics week is an IST calendar week
+	badgesFrom := time.Date(weekStart.Year(), weekStart.Month(), weekStart.Day(), 0, 0, 0, 0, istLocation)
+	badgesTo := badgesFrom.AddDate(0, 0, 7)
+
+	var sent, skipped, failed int
+	for i := range users {
+		user := &users[i]
+		if user.WeeklyDigestOptOut || user.IsDeactivated {
+			skipped++
+			continue
+		}
+
+		analytics, err := s.analyticsSvc.GetWeekAnalytics(user.ID, weekStart)
+		if err != nil {
+			logger.Sugar.Warnw("Failed to build weekly digest analytics", "user_id", user.ID, "error", err)
+			failed++
+			continue
+		}
+		if analytics.TotalHoursThisWeek <= 0 {
+			skipped++
+			continue
+		}
+
+		if err := s.emailSvc.SendWeeklyDigest(user, analytics, s.badgesEarnedBetween(user.ID, badgesFrom, badgesTo)); err != nil {
+			logger.Sugar.Warnw("Failed to send weekly digest", "user_id", user.ID, "error", err)
+			failed++
+			continue
+		}
+		sent++
+	}
+
+	logger.Sugar.Infow("Weekly digest job completed",
+		"week_start", weekStartStr,
+		"sent", sent,
+		"skipped", skipped,
+		"failed", failed,
+		"instance_id", s.instanceID,
+	)
+
+	s.updateJobLog(jobLog, models.CronJobStatusCompleted, sent, "")
+	return nil
 }
 
-// GeneratePublicURL generates the public URL for a blob
-func (s *BlobService) GeneratePublicURL(blobName string) string {
-	return fmt.Sprintf("https://%s.blob.core.windows.net/%s/%s", s.accountName, s.container, blobName)
+// badgesEarnedBetween returns the badge definitions a user earned in [from, to).
+// Lookup failures just leave the badge section out of the digest.
+func (s *CronService) badgesEarnedBetween(userID uint, from, to time.Time) []constants.Badge {
+	if s.badgeRepo == nil {
+		return nil
+	}
+	earned, err := s.badgeRepo.FindByUserID(userID)
+	if err != nil {
+		return nil
+	}
+
+	var badges []constants.Badge
+	for _, ub := range earned {
+		if ub.EarnedAt.Before(from) || !ub.EarnedAt.Before(to) {
+			continue
+		}
+		if badge := constants.GetBadgeByKey(ub.BadgeKey); badge != nil {
+			badges = append(badges, *badge)
+		}
+	}
+	return badges
 }
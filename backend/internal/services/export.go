@@ -0,0 +1,302 @@
+package services
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/pkg/models"
+)
+
+// ExportService assembles a portable JSON dump of a user's account data
+type ExportService struct {
+	userRepo     *repository.UserRepository
+	activityRepo *repository.ActivityRepository
+	streakRepo   *repository.StreakRepository
+	badgeRepo    *repository.BadgeRepository
+	followRepo   *repository.FollowRepository
+	notifRepo    *repository.NotificationRepository
+	photoRepo    *repository.ActivityPhotoRepository
+	photoSvc     *ActivityPhotoService // optional; signs exported photo URLs
+}
+
+// NewExportService creates a new ExportService
+func NewExportService(
+	userRepo *repository.UserRepository,
+	activityRepo *repository.ActivityRepository,
+	streakRepo *repository.StreakRepository,
+	badgeRepo *repository.BadgeRepository,
+	followRepo *repository.FollowRepository,
+	notifRepo *repository.NotificationRepository,
+	photoRepo *repository.ActivityPhotoRepository,
+	photoSvc *ActivityPhotoService,
+) *ExportService {
+	return &ExportService{
+		userRepo:     userRepo,
+		activityRepo: activityRepo,
+		streakRepo:   streakRepo,
+		badgeRepo:    badgeRepo,
+		followRepo:   followRepo,
+		notifRepo:    notifRepo,
+		photoRepo:    photoRepo,
+		photoSvc:     photoSvc,
+	}
+}
+
+// exportProfile is the account section of the export (credentials excluded)
+type exportProfile struct {
+	ID            uint      `json:"id"`
+	Username      string    `json:"username"`
+	Email         string    `json:"email"`
+	Bio           *string   `json:"bio"`
+	ProfilePic    *string   `json:"profile_pic"`
+	IsPrivate     bool      `json:"is_private"`
+	IsVerified    bool      `json:"is_verified"`
+	EmailVerified bool      `json:"email_verified"`
+	GhostMode     bool      `json:"ghost_mode"`
+	Timezone      string    `json:"timezone"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+type exportActivity struct {
+	Date  string              `json:"date"`
+	Name  models.ActivityName `json:"activity_name"`
+	Hours float32             `json:"hours"`
+	Note  *string             `json:"note,omitempty"`
+}
+
+type exportStreak struct {
+	Date    string `json:"date"`
+	Current int    `json:"current"`
+	Longest int    `json:"longest"`
+	Frozen  bool   `json:"frozen,omitempty"`
+}
+
+type exportBadge struct {
+	Key      string    `json:"key"`
+	EarnedAt time.Time `json:"earned_at"`
+}
+
+// exportNotification omits the title and body, which quote other users' names and text
+type exportNotification struct {
+	Type      models.NotificationType     `json:"type"`
+	Metadata  models.NotificationMetadata `json:"metadata"`
+	ReadAt    *time.Time                  `json:"read_at"`
+	CreatedAt time.Time                   `json:"created_at"`
+}
+
+// exportNotificationMetadata drops the other users' profile data and message previews
+// notifications carry, keeping IDs, dates and the user's own badge/streak details
+func exportNotificationMetadata(m models.NotificationMetadata) models.NotificationMetadata {
+	if m == nil {
+		return nil
+	}
+	out := make(models.NotificationMetadata, len(m))
+	for k, v := range m {
+		if strings.HasSuffix(k, "_username") || strings.HasSuffix(k, "_avatar") || strings.HasSuffix(k, "_preview") {
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// jsonStreamWriter writes a single JSON object section by section, remembering the first error
+type jsonStreamWriter struct {
+	w       *bufio.Writer
+	enc     *json.Encoder
+	err     error
+	started bool
+}
+
+func newJSONStreamWriter(w io.Writer) *jsonStreamWriter {
+	bw := bufio.NewWriter(w)
+	return &jsonStreamWriter{w: bw, enc: json.NewEncoder(bw)}
+}
+
+func (j *jsonStreamWriter) raw(s string) {
+	if j.err == nil {
+		_, j.err = j.w.WriteString(s)
+	}
+}
+
+func (j *jsonStreamWriter) value(v interface{}) {
+	if j.err == nil {
+		j.err = j.enc.Encode(v)
+	}
+}
+
+// key starts a new top-level field
+func (j *jsonStreamWriter) key(name string) {
+	if j.started {
+		j.raw(",")
+	}
+	j.started = true
+	j.raw(fmt.Sprintf("%q:", name))
+}
+
+// field writes a complete top-level field
+func (j *jsonStreamWriter) field(name string, v interface{}) {
+	j.key(name)
+	j.value(v)
+}
+
+// array streams a top-level array field; each yields one element via emit
+func (j *jsonStreamWriter) array(name string, each func(emit func(interface{}) error) error) {
+	j.key(name)
+	j.raw("[")
+	first := true
+	if j.err == nil {
+		err := each(func(v interface{}) error {
+			if !first {
+				j.raw(",")
+			}
+			first = false
+			j.value(v)
+			return j.err
+		})
+		if j.err == nil {
+			j.err = err
+		}
+	}
+	j.raw("]")
+}
+
+// WriteAccountExport streams a JSON export of the user's profile, activities, streaks,
+// badges, follows, story photos and notifications to w. Large sections are read from
+// database cursors so memory stays flat regardless of account age.
+// Other users appear only by user ID (follows, notification actors); story views, likes
+// and replies are exported as counts, never as viewer identities.
+func (s *ExportService) WriteAccountExport(userID uint, w io.Writer) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return fmt.Errorf("user not found")
+	}
+
+	j := newJSONStreamWriter(w)
+	j.raw("{")
+	j.field("exported_at", time.Now().UTC())
+	j.field("profile", exportProfile{
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Bio:           user.Bio,
+		ProfilePic:    user.ProfilePic,
+		IsPrivate:     user.IsPrivate,
+		IsVerified:    user.IsVerified,
+		EmailVerified: user.EmailVerified,
+		GhostMode:     user.GhostMode,
+		Timezone:      user.Timezone,
+		CreatedAt:     user.CreatedAt,
+	})
+
+	j.array("activities", func(emit func(interface{}) error) error {
+		return s.activityRepo.StreamByUser(userID, nil, nil, func(a models.Activity) error {
+			return emit(exportActivity{
+				Date:  a.ActivityDate.Format(constants.DateFormat),
+				Name:  a.Name,
+				Hours: a.DurationHours,
+				Note:  a.Note,
+			})
+		})
+	})
+
+	j.array("streaks", func(emit func(interface{}) error) error {
+		streaks, err := s.streakRepo.FindAllByUser(userID)
+		if err != nil {
+			return err
+		}
+		for _, st := range streaks {
+			if err := emit(exportStreak{
+				Date:    st.ActivityDate.Format(constants.DateFormat),
+				Current: st.Current,
+				Longest: st.Longest,
+				Frozen:  st.Frozen,
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	j.array("badges", func(emit func(interface{}) error) error {
+		badges, err := s.badgeRepo.FindByUserID(userID)
+		if err != nil {
+			return err
+		}
+		for _, b := range badges {
+			if err := emit(exportBadge{Key: b.BadgeKey, EarnedAt: b.EarnedAt}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	j.array("following", func(emit func(interface{}) error) error {
+		rows, err := s.followRepo.GetFollowingForExport(userID)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := emit(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	j.array("followers", func(emit func(interface{}) error) error {
+		rows, err := s.followRepo.GetFollowersForExport(userID)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			if err := emit(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	j.array("photos", func(emit func(interface{}) error) error {
+		rows, err := s.photoRepo.GetPhotosForExport(userID)
+		if err != nil {
+			return err
+		}
+		for _, row := range rows {
+			// Stored URLs point into a private container; hand out read-only SAS URLs instead
+			if s.photoSvc != nil {
+				row.PhotoURL = s.photoSvc.signBlobURL(row.PhotoURL)
+			}
+			if err := emit(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	j.array("notifications", func(emit func(interface{}) error) error {
+		return s.notifRepo.StreamByUserID(userID, func(n models.Notification) error {
+			return emit(exportNotification{
+				Type:      n.Type,
+				Metadata:  exportNotificationMetadata(n.Metadata),
+				ReadAt:    n.ReadAt,
+				CreatedAt: n.CreatedAt,
+			})
+		})
+	})
+
+	j.raw("}")
+	if j.err != nil {
+		return j.err
+	}
+	return j.w.Flush()
+}
@@ -5,10 +5,12 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/aman1117/backend/internal/config"
 	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
 	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/pkg/models"
@@ -82,6 +84,8 @@ func (s *FollowService) Follow(ctx context.Context, followerID, followeeID uint)
 		case models.FollowStateRemoved:
 			// Can re-follow, continue with the flow
 			previousState = &existingEdge.State
+		case models.FollowStateBlocked:
+			return nil, fmt.Errorf("%s: cannot follow this user", constants.ErrCodeBlocked)
 		}
 	}
 
@@ -172,6 +176,28 @@ func (s *FollowService) CancelRequest(ctx context.Context, followerID, targetID
 	return nil
 }
 
+// CancelAllPendingRequests cancels every outgoing pending follow request of the follower.
+// Requests accepted between the list load and this call are left untouched.
+// Returns the number of requests cancelled.
+func (s *FollowService) CancelAllPendingRequests(ctx context.Context, followerID uint) (int, error) {
+	targetIDs, err := s.repo.CancelAllPendingWithCounters(followerID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to cancel pending requests: %w", err)
+	}
+
+	// Invalidate caches for all affected targets
+	for _, targetID := range targetIDs {
+		s.invalidateRelationshipCache(ctx, followerID, targetID)
+	}
+
+	logger.Sugar.Infow("All pending follow requests cancelled",
+		"follower_id", followerID,
+		"cancelled", len(targetIDs),
+	)
+
+	return len(targetIDs), nil
+}
+
 // ==================== Request Management ====================
 
 // AcceptRequest accepts an incoming follow request
@@ -228,6 +254,48 @@ func (s *FollowService) DeclineRequest(ctx context.Context, viewerID, requesterI
 	return nil
 }
 
+// ResolvePendingRequests accepts or declines a batch of incoming follow requests atomically.
+// Requests that are no longer pending (e.g. accepted concurrently) are skipped.
+// Returns the requester IDs that actually transitioned.
+func (s *FollowService) ResolvePendingRequests(ctx context.Context, viewerID uint, requesterIDs []uint, accept bool) ([]uint, error) {
+	// Deduplicate and cap batch size
+	seen := make(map[uint]bool, len(requesterIDs))
+	uniqueIDs := make([]uint, 0, len(requesterIDs))
+	for _, id := range requesterIDs {
+		if id == 0 || id == viewerID || seen[id] {
+			continue
+		}
+		seen[id] = true
+		uniqueIDs = append(uniqueIDs, id)
+		if len(uniqueIDs) >= constants.FollowBatchMaxSize {
+			break
+		}
+	}
+
+	if len(uniqueIDs) == 0 {
+		return []uint{}, nil
+	}
+
+	transitioned, err := s.repo.ResolvePendingRequestsWithCounters(viewerID, uniqueIDs, accept)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve follow requests: %w", err)
+	}
+
+	// Invalidate caches for transitioned edges only
+	for _, requesterID := range transitioned {
+		s.invalidateRelationshipCache(ctx, requesterID, viewerID)
+	}
+
+	logger.Sugar.Infow("Follow requests resolved in batch",
+		"viewer_id", viewerID,
+		"accept", accept,
+		"requested", len(uniqueIDs),
+		"transitioned", len(transitioned),
+	)
+
+	return transitioned, nil
+}
+
 // RemoveFollower removes a follower from the viewer's followers list
 func (s *FollowService) RemoveFollower(ctx context.Context, viewerID, followerID uint) error {
 	// Check if the user is actually following us
@@ -255,10 +323,156 @@ func (s *FollowService) RemoveFollower(ctx context.Context, viewerID, followerID
 	return nil
 }
 
+// ==================== Blocking ====================
+
+// Block blocks a user: removes follow edges in both directions (adjusting counters),
+// records the block and prevents either side from following the other
+func (s *FollowService) Block(ctx context.Context, viewerID, targetID uint) error {
+	if viewerID == targetID {
+		return fmt.Errorf("%s: cannot block yourself", constants.ErrCodeCannotBlockSelf)
+	}
+
+	targetUser, err := s.userRepo.FindByID(targetID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch target user: %w", err)
+	}
+	if targetUser == nil {
+		return fmt.Errorf("%s: target user not found", constants.ErrCodeUserNotFound)
+	}
+
+	if err := s.repo.BlockWithCounters(viewerID, targetID); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+
+	// Invalidate caches in both directions
+	s.invalidateRelationshipCache(ctx, viewerID, targetID)
+	s.invalidateRelationshipCache(ctx, targetID, viewerID)
+
+	logger.Sugar.Infow("User blocked",
+		"blocker_id", viewerID,
+		"blocked_id", targetID,
+	)
+
+	return nil
+}
+
+// Unblock removes a block. Previous follow relationships are not restored.
+func (s *FollowService) Unblock(ctx context.Context, viewerID, targetID uint) error {
+	unblocked, err := s.repo.Unblock(viewerID, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	if !unblocked {
+		return fmt.Errorf("%s: user is not blocked", constants.ErrCodeNotBlocked)
+	}
+
+	s.invalidateRelationshipCache(ctx, viewerID, targetID)
+	s.invalidateRelationshipCache(ctx, targetID, viewerID)
+
+	logger.Sugar.Infow("User unblocked",
+		"blocker_id", viewerID,
+		"blocked_id", targetID,
+	)
+
+	return nil
+}
+
+// IsBlocked checks if either user has blocked the other
+func (s *FollowService) IsBlocked(ctx context.Context, userA, userB uint) (bool, error) {
+	if userA == userB {
+		return false, nil
+	}
+	return s.repo.IsBlockedEither(userA, userB)
+}
+
+// GetBlockedUsers returns paginated users blocked by the viewer
+func (s *FollowService) GetBlockedUsers(ctx context.Context, viewerID uint, limit int, cursor *repository.ListCursor) ([]models.UserBlock, bool, error) {
+	// Clamp limit
+	if limit <= 0 || limit > constants.FollowListMaxLimit {
+		limit = constants.FollowListDefaultLimit
+	}
+
+	// Fetch one extra to check for more
+	blocks, err := s.repo.GetBlockedUsersPaginated(viewerID, limit+1, cursor)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get blocked users: %w", err)
+	}
+
+	hasMore := len(blocks) > limit
+	if hasMore {
+		blocks = blocks[:limit]
+	}
+
+	return blocks, hasMore, nil
+}
+
+// ==================== Close Friends ====================
+
+// AddCloseFriend adds a user to the viewer's close friends list
+func (s *FollowService) AddCloseFriend(ctx context.Context, ownerID, friendID uint) error {
+	if ownerID == friendID {
+		return fmt.Errorf("%s: cannot add yourself to close friends", constants.ErrCodeCannotCloseFriend)
+	}
+
+	friend, err := s.userRepo.FindByID(friendID)
+	if err != nil {
+		return fmt.Errorf("failed to fetch user: %w", err)
+	}
+	if friend == nil {
+		return fmt.Errorf("%s: user not found", constants.ErrCodeUserNotFound)
+	}
+
+	blocked, err := s.repo.IsBlockedEither(ownerID, friendID)
+	if err != nil {
+		return fmt.Errorf("failed to check block status: %w", err)
+	}
+	if blocked {
+		return fmt.Errorf("%s: cannot add a blocked user to close friends", constants.ErrCodeBlocked)
+	}
+
+	if err := s.repo.AddCloseFriend(ownerID, friendID); err != nil {
+		return fmt.Errorf("failed to add close friend: %w", err)
+	}
+
+	logger.Sugar.Infow("Close friend added",
+		"owner_id", ownerID,
+		"friend_id", friendID,
+	)
+
+	return nil
+}
+
+// RemoveCloseFriend removes a user from the viewer's close friends list
+func (s *FollowService) RemoveCloseFriend(ctx context.Context, ownerID, friendID uint) error {
+	removed, err := s.repo.RemoveCloseFriend(ownerID, friendID)
+	if err != nil {
+		return fmt.Errorf("failed to remove close friend: %w", err)
+	}
+	if !removed {
+		return fmt.Errorf("%s: user is not in close friends", constants.ErrCodeNotCloseFriend)
+	}
+
+	logger.Sugar.Infow("Close friend removed",
+		"owner_id", ownerID,
+		"friend_id", friendID,
+	)
+
+	return nil
+}
+
+// IsCloseFriend checks if friendID is on ownerID's close friends list
+func (s *FollowService) IsCloseFriend(ctx context.Context, ownerID, friendID uint) (bool, error) {
+	if ownerID == friendID {
+		return true, nil // Owners always see their own close-friends content
+	}
+	return s.repo.IsCloseFriend(ownerID, friendID)
+}
+
 // ==================== List Operations ====================
 
-// GetFollowers returns paginated followers for a user
-func (s *FollowService) GetFollowers(ctx context.Context, viewerID, targetUserID uint, limit int, cursor *repository.FollowListCursor) ([]models.FollowEdgeByFollowee, bool, error) {
+// GetFollowers returns paginated followers for a user.
+// When query is non-empty, only followers whose username contains it are returned.
+func (s *FollowService) GetFollowers(ctx context.Context, viewerID, targetUserID uint, query string, limit int, cursor *repository.ListCursor) ([]models.FollowEdgeByFollowee, bool, error) {
 	// Check privacy
 	if err := s.checkListAccess(ctx, viewerID, targetUserID); err != nil {
 		return nil, false, err
@@ -270,7 +484,13 @@ func (s *FollowService) GetFollowers(ctx context.Context, viewerID, targetUserID
 	}
 
 	// Fetch one extra to check for more
-	edges, err := s.repo.GetFollowersPaginated(targetUserID, limit+1, cursor)
+	var edges []models.FollowEdgeByFollowee
+	var err error
+	if query != "" {
+		edges, err = s.repo.SearchFollowersPaginated(targetUserID, query, limit+1, cursor)
+	} else {
+		edges, err = s.repo.GetFollowersPaginated(targetUserID, limit+1, cursor)
+	}
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to get followers: %w", err)
 	}
@@ -283,8 +503,9 @@ func (s *FollowService) GetFollowers(ctx context.Context, viewerID, targetUserID
 	return edges, hasMore, nil
 }
 
-// GetFollowing returns paginated following for a user
-func (s *FollowService) GetFollowing(ctx context.Context, viewerID, targetUserID uint, limit int, cursor *repository.FollowListCursor) ([]models.FollowEdgeByFollower, bool, error) {
+// GetFollowing returns paginated following for a user.
+// When query is non-empty, only users whose username contains it are returned.
+func (s *FollowService) GetFollowing(ctx context.Context, viewerID, targetUserID uint, query string, limit int, cursor *repository.ListCursor) ([]models.FollowEdgeByFollower, bool, error) {
 	// Check privacy
 	if err := s.checkListAccess(ctx, viewerID, targetUserID); err != nil {
 		return nil, false, err
@@ -296,7 +517,13 @@ func (s *FollowService) GetFollowing(ctx context.Context, viewerID, targetUserID
 	}
 
 	// Fetch one extra to check for more
-	edges, err := s.repo.GetFollowingPaginated(targetUserID, limit+1, cursor)
+	var edges []models.FollowEdgeByFollower
+	var err error
+	if query != "" {
+		edges, err = s.repo.SearchFollowingPaginated(targetUserID, query, limit+1, cursor)
+	} else {
+		edges, err = s.repo.GetFollowingPaginated(targetUserID, limit+1, cursor)
+	}
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to get following: %w", err)
 	}
@@ -310,7 +537,7 @@ func (s *FollowService) GetFollowing(ctx context.Context, viewerID, targetUserID
 }
 
 // GetPendingIncomingRequests returns paginated incoming follow requests
-func (s *FollowService) GetPendingIncomingRequests(ctx context.Context, viewerID uint, limit int, cursor *repository.FollowListCursor) ([]models.FollowEdgeByFollowee, bool, error) {
+func (s *FollowService) GetPendingIncomingRequests(ctx context.Context, viewerID uint, limit int, cursor *repository.ListCursor) ([]models.FollowEdgeByFollowee, bool, error) {
 	// Clamp limit
 	if limit <= 0 || limit > constants.FollowListMaxLimit {
 		limit = constants.FollowListDefaultLimit
@@ -330,12 +557,49 @@ func (s *FollowService) GetPendingIncomingRequests(ctx context.Context, viewerID
 	return edges, hasMore, nil
 }
 
+// GetPendingOutgoingRequests returns paginated outgoing follow requests that are still pending
+func (s *FollowService) GetPendingOutgoingRequests(ctx context.Context, viewerID uint, limit int, cursor *repository.ListCursor) ([]models.FollowEdgeByFollower, bool, error) {
+	// Clamp limit
+	if limit <= 0 || limit > constants.FollowListMaxLimit {
+		limit = constants.FollowListDefaultLimit
+	}
+
+	// Fetch one extra to check for more
+	edges, err := s.repo.GetPendingOutgoingRequests(viewerID, limit+1, cursor)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get outgoing requests: %w", err)
+	}
+
+	hasMore := len(edges) > limit
+	if hasMore {
+		edges = edges[:limit]
+	}
+
+	return edges, hasMore, nil
+}
+
 // ==================== Relationship Lookup ====================
 
 // LookupRelationships returns relationship states for multiple targets
 func (s *FollowService) LookupRelationships(ctx context.Context, viewerID uint, targetIDs []uint) (map[uint]models.RelationshipState, error) {
+	pairs, err := s.LookupRelationshipPairs(ctx, viewerID, targetIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[uint]models.RelationshipState, len(pairs))
+	for targetID, pair := range pairs {
+		result[targetID] = pair.Combined()
+	}
+
+	return result, nil
+}
+
+// LookupRelationshipPairs returns both relationship directions (outgoing and incoming)
+// for multiple targets. Results are cached per viewer:target as "outgoing|incoming".
+func (s *FollowService) LookupRelationshipPairs(ctx context.Context, viewerID uint, targetIDs []uint) (map[uint]models.RelationshipPair, error) {
 	// Try cache first for each target
-	result := make(map[uint]models.RelationshipState)
+	result := make(map[uint]models.RelationshipPair)
 	uncachedIDs := make([]uint, 0)
 
 	if redis.IsAvailable() {
@@ -343,10 +607,12 @@ func (s *FollowService) LookupRelationships(ctx context.Context, viewerID uint,
 			cacheKey := fmt.Sprintf("%s%d:%d", constants.FollowRelCachePrefix, viewerID, targetID)
 			cached, err := redis.Get().Get(ctx, cacheKey).Result()
 			if err == nil {
-				result[targetID] = models.RelationshipState(cached)
-			} else {
-				uncachedIDs = append(uncachedIDs, targetID)
+				if pair, ok := decodeRelationshipPair(cached); ok {
+					result[targetID] = pair
+					continue
+				}
 			}
+			uncachedIDs = append(uncachedIDs, targetID)
 		}
 	} else {
 		uncachedIDs = targetIDs
@@ -360,11 +626,11 @@ func (s *FollowService) LookupRelationships(ctx context.Context, viewerID uint,
 		}
 
 		// Merge and cache results
-		for targetID, state := range dbResult {
-			result[targetID] = state
+		for targetID, pair := range dbResult {
+			result[targetID] = pair
 			if redis.IsAvailable() {
 				cacheKey := fmt.Sprintf("%s%d:%d", constants.FollowRelCachePrefix, viewerID, targetID)
-				redis.Get().Set(ctx, cacheKey, string(state), constants.FollowRelCacheTTL)
+				redis.Get().Set(ctx, cacheKey, encodeRelationshipPair(pair), constants.FollowRelCacheTTL)
 			}
 		}
 	}
@@ -372,6 +638,23 @@ func (s *FollowService) LookupRelationships(ctx context.Context, viewerID uint,
 	return result, nil
 }
 
+// encodeRelationshipPair serializes a pair for the relationship cache ("FOLLOWING|NONE")
+func encodeRelationshipPair(pair models.RelationshipPair) string {
+	return string(pair.Outgoing) + "|" + string(pair.Incoming)
+}
+
+// decodeRelationshipPair parses a cached pair; legacy single-state values are treated as a miss
+func decodeRelationshipPair(value string) (models.RelationshipPair, bool) {
+	parts := strings.SplitN(value, "|", 2)
+	if len(parts) != 2 {
+		return models.RelationshipPair{}, false
+	}
+	return models.RelationshipPair{
+		Outgoing: models.RelationshipState(parts[0]),
+		Incoming: models.RelationshipState(parts[1]),
+	}, true
+}
+
 // GetRelationshipState returns the relationship state for viewer -> target
 func (s *FollowService) GetRelationshipState(ctx context.Context, viewerID, targetID uint) (models.RelationshipState, error) {
 	relationships, err := s.LookupRelationships(ctx, viewerID, []uint{targetID})
@@ -389,7 +672,7 @@ func (s *FollowService) GetRelationshipState(ctx context.Context, viewerID, targ
 // GetMutuals returns users that both viewer follows and who follow the target user
 // Note: Mutuals are allowed even for private accounts because we're showing users YOU follow
 // who also follow the target - this doesn't expose the private account's follower list
-func (s *FollowService) GetMutuals(ctx context.Context, viewerID, targetUserID uint, limit int, cursor *repository.FollowListCursor) ([]uint, bool, error) {
+func (s *FollowService) GetMutuals(ctx context.Context, viewerID, targetUserID uint, limit int, cursor *repository.ListCursor) ([]uint, bool, error) {
 	// No privacy check needed - mutuals show YOUR friends, not the target's data
 
 	// Clamp limit
@@ -414,7 +697,7 @@ func (s *FollowService) GetMutuals(ctx context.Context, viewerID, targetUserID u
 // GetMutualsWithTimestamps returns mutual followers with timestamps for cursor pagination
 // Note: Mutuals are allowed even for private accounts because we're showing users YOU follow
 // who also follow the target - this doesn't expose the private account's follower list
-func (s *FollowService) GetMutualsWithTimestamps(ctx context.Context, viewerID, targetUserID uint, limit int, cursor *repository.FollowListCursor) ([]MutualEdge, bool, error) {
+func (s *FollowService) GetMutualsWithTimestamps(ctx context.Context, viewerID, targetUserID uint, limit int, cursor *repository.ListCursor) ([]MutualEdge, bool, error) {
 	// No privacy check needed - mutuals show YOUR friends, not the target's data
 
 	// Clamp limit
@@ -445,6 +728,49 @@ func (s *FollowService) GetMutualsWithTimestamps(ctx context.Context, viewerID,
 	return edges, hasMore, nil
 }
 
+// GetFollowedByMutuals returns users who follow the target AND whom the viewer follows
+// ("Followed by alice, bob and 3 others"), along with the total count for the "+N" suffix.
+// Respects checkListAccess since it reveals part of the target's follower list.
+func (s *FollowService) GetFollowedByMutuals(ctx context.Context, viewerID, targetID uint, limit int) ([]dto.FollowUserDTO, int64, error) {
+	// No social proof for your own profile
+	if viewerID == targetID {
+		return []dto.FollowUserDTO{}, 0, nil
+	}
+
+	if err := s.checkListAccess(ctx, viewerID, targetID); err != nil {
+		return nil, 0, err
+	}
+
+	// Clamp limit
+	if limit <= 0 {
+		limit = constants.FollowedByDefaultLimit
+	}
+	if limit > constants.FollowListMaxLimit {
+		limit = constants.FollowListMaxLimit
+	}
+
+	rows, total, err := s.repo.GetFollowedByMutuals(viewerID, targetID, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to get followed-by mutuals: %w", err)
+	}
+
+	users := make([]dto.FollowUserDTO, len(rows))
+	for i, row := range rows {
+		users[i] = dto.FollowUserDTO{
+			ID:              row.UserID,
+			Username:        row.Username,
+			ProfilePic:      row.ProfilePic,
+			ProfilePicThumb: row.ProfilePicThumb,
+			Bio:             row.Bio,
+			IsPrivate:       row.IsPrivate,
+			IsVerified:      row.IsVerified,
+			FollowedAt:      row.FollowedAt.Format(time.RFC3339),
+		}
+	}
+
+	return users, total, nil
+}
+
 // ==================== Counts ====================
 
 // GetFollowCounts returns cached or fresh follow counts for a user
@@ -504,11 +830,29 @@ func (s *FollowService) ReconcileCounters(ctx context.Context, userID uint) erro
 		cacheKey := fmt.Sprintf("%s%d", constants.FollowCountCachePrefix, userID)
 		redis.Get().Del(ctx, cacheKey)
 	}
+	s.publishCountChanges(ctx, userID)
 
 	logger.Sugar.Infow("Counters reconciled", "user_id", userID)
 	return nil
 }
 
+// FindCounterRepairCandidates scans one page of follow counters after afterUserID and returns those
+// that are stale or drifted, along with the cursor for the next page (0 when the scan is complete).
+func (s *FollowService) FindCounterRepairCandidates(ctx context.Context, afterUserID uint, limit int) ([]repository.CounterRepairCandidate, uint, error) {
+	staleBefore := time.Now().Add(-constants.FollowCounterStaleAfter)
+	candidates, err := s.repo.FindCounterRepairCandidates(afterUserID, staleBefore, constants.FollowCounterDriftThreshold, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to find counter repair candidates: %w", err)
+	}
+
+	nextCursor, err := s.repo.GetMaxCounterUserID(afterUserID, limit)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to advance counter scan: %w", err)
+	}
+
+	return candidates, nextCursor, nil
+}
+
 // ==================== Helper Methods ====================
 
 // checkFollowLimits validates that the user hasn't exceeded follow limits
@@ -543,6 +887,15 @@ func (s *FollowService) checkListAccess(ctx context.Context, viewerID, targetUse
 		return nil
 	}
 
+	// Blocked users (either direction) have no access
+	blocked, err := s.repo.IsBlockedEither(viewerID, targetUserID)
+	if err != nil {
+		return fmt.Errorf("failed to check block status: %w", err)
+	}
+	if blocked {
+		return fmt.Errorf("%s: access denied", constants.ErrCodeBlocked)
+	}
+
 	// Check if target is private
 	targetUser, err := s.userRepo.FindByID(targetUserID)
 	if err != nil {
@@ -569,6 +922,13 @@ func (s *FollowService) checkListAccess(ctx context.Context, viewerID, targetUse
 	return nil
 }
 
+// CheckProfileAccess applies the follower-list privacy rules to other per-user data
+// (block check, then private accounts visible only to approved followers).
+// Errors carry the same error codes as the list endpoints.
+func (s *FollowService) CheckProfileAccess(ctx context.Context, viewerID, targetUserID uint) error {
+	return s.checkListAccess(ctx, viewerID, targetUserID)
+}
+
 // publishFollowEvent publishes a follow event to Redis for async processing
 func (s *FollowService) publishFollowEvent(ctx context.Context, eventType models.FollowEventType, followerID, followeeID uint, state models.FollowState) {
 	if !redis.IsAvailable() {
@@ -640,14 +1000,47 @@ func (s *FollowService) invalidateRelationshipCache(ctx context.Context, followe
 		return
 	}
 
-	// Invalidate relationship cache
+	// Invalidate relationship cache in both directions (entries hold outgoing and incoming state)
 	relCacheKey := fmt.Sprintf("%s%d:%d", constants.FollowRelCachePrefix, followerID, followeeID)
-	redis.Get().Del(ctx, relCacheKey)
+	reverseRelCacheKey := fmt.Sprintf("%s%d:%d", constants.FollowRelCachePrefix, followeeID, followerID)
+	redis.Get().Del(ctx, relCacheKey, reverseRelCacheKey)
 
 	// Invalidate count caches
 	followerCountKey := fmt.Sprintf("%s%d", constants.FollowCountCachePrefix, followerID)
 	followeeCountKey := fmt.Sprintf("%s%d", constants.FollowCountCachePrefix, followeeID)
 	redis.Get().Del(ctx, followerCountKey, followeeCountKey)
+
+	s.publishCountChanges(ctx, followerID, followeeID)
+}
+
+// publishCountChanges pushes the current follow counts of each user to their live
+// WebSocket connections. Best-effort: skipped without Redis, failures are only logged.
+func (s *FollowService) publishCountChanges(ctx context.Context, userIDs ...uint) {
+	if !redis.IsAvailable() {
+		return
+	}
+
+	for _, userID := range userIDs {
+		followersCount, followingCount, err := s.repo.GetFollowCounts(userID)
+		if err != nil {
+			logger.Sugar.Warnw("Failed to load follow counts for live update", "user_id", userID, "error", err)
+			continue
+		}
+
+		payload, err := json.Marshal(dto.FollowCountsEventDTO{
+			UserID:         userID,
+			FollowersCount: followersCount,
+			FollowingCount: followingCount,
+		})
+		if err != nil {
+			continue
+		}
+
+		channel := fmt.Sprintf("%s%d", constants.FollowCountChannelPrefix, userID)
+		if err := redis.Get().Publish(ctx, channel, payload).Err(); err != nil {
+			logger.Sugar.Warnw("Failed to publish follow count change", "user_id", userID, "error", err)
+		}
+	}
 }
 
 // ==================== Counter Event Subscriber ====================
@@ -3,25 +3,125 @@ package services
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"image"
 	"image/jpeg"
 	"io"
 	"mime/multipart"
 	"path/filepath"
+	"strconv"
 	"strings"
 
 	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/pkg/webp"
 	"github.com/disintegration/imaging"
 	_ "golang.org/x/image/webp" // Register WebP decoder for image.Decode
 )
 
+// ImageFormat is an output encoding for processed images
+type ImageFormat string
+
+const (
+	ImageFormatJPEG ImageFormat = "jpeg"
+	ImageFormatWebP ImageFormat = "webp"
+	ImageFormatAVIF ImageFormat = "avif"
+)
+
+// MimeType returns the Content-Type for the format
+func (f ImageFormat) MimeType() string {
+	switch f {
+	case ImageFormatWebP:
+		return "image/webp"
+	case ImageFormatAVIF:
+		return "image/avif"
+	default:
+		return "image/jpeg"
+	}
+}
+
+// Extension returns the blob file extension for the format, including the dot
+func (f ImageFormat) Extension() string {
+	switch f {
+	case ImageFormatWebP:
+		return ".webp"
+	case ImageFormatAVIF:
+		return ".avif"
+	default:
+		return ".jpg"
+	}
+}
+
+// ImageEncoder writes img to w in a specific format at the given quality (1-100)
+type ImageEncoder func(w io.Writer, img image.Image, quality int) error
+
+// encodeJPEG is the JPEG ImageEncoder
+func encodeJPEG(w io.Writer, img image.Image, quality int) error {
+	return jpeg.Encode(w, img, &jpeg.Options{Quality: quality})
+}
+
+// encodeWebP is the WebP ImageEncoder. The encoder is lossless, so quality is ignored.
+func encodeWebP(w io.Writer, img image.Image, _ int) error {
+	return webp.Encode(w, img)
+}
+
+// imageEncoderFor returns the encoder for an output format. JPEG and WebP are linked into
+// this build; AVIF has no encoder yet.
+func imageEncoderFor(format ImageFormat) (ImageEncoder, bool) {
+	switch format {
+	case ImageFormatJPEG:
+		return encodeJPEG, true
+	case ImageFormatWebP:
+		return encodeWebP, true
+	default:
+		return nil, false
+	}
+}
+
+// ParseImageFormat maps a configured format name to an output format with an encoder.
+// A format without an encoder logs a warning and falls back to JPEG.
+func ParseImageFormat(name string) ImageFormat {
+	format := ImageFormat(strings.ToLower(strings.TrimSpace(name)))
+	if format == "" || format == "jpg" {
+		return ImageFormatJPEG
+	}
+	if _, ok := imageEncoderFor(format); !ok {
+		logger.Sugar.Warnw("No encoder for image format, falling back to JPEG", "format", name)
+		return ImageFormatJPEG
+	}
+	return format
+}
+
 // ImageProcessor handles image validation, processing, and thumbnail generation
 type ImageProcessor struct {
 	maxFullSize      int // Max dimension for full-size image
 	thumbnailSize    int // Dimension for thumbnail
-	jpegQuality      int // JPEG quality (1-100)
+	jpegQuality      int // Encoder quality (1-100), used for every output format
+	outputFormat     ImageFormat
 	allowedMimeTypes map[string]bool
+
+	// Largest accepted input dimensions, checked from the header before decoding (0 = no limit)
+	maxInputWidth  int
+	maxInputHeight int
+
+	// squareFull center-crops the full image to a square before resizing (avatars)
+	squareFull bool
+}
+
+// ErrImageTooLarge is returned when an upload exceeds the file size or pixel dimension limits
+var ErrImageTooLarge = errors.New("image is too large")
+
+// WithSquareCrop makes Process center-crop the full image to a square, like the thumbnail
+func (p *ImageProcessor) WithSquareCrop() *ImageProcessor {
+	p.squareFull = true
+	return p
+}
+
+// WithMaxDimensions sets the largest input width and height Process will decode
+func (p *ImageProcessor) WithMaxDimensions(maxWidth, maxHeight int) *ImageProcessor {
+	p.maxInputWidth = maxWidth
+	p.maxInputHeight = maxHeight
+	return p
 }
 
 // NewImageProcessor creates a new ImageProcessor with default settings (for activity photos)
@@ -30,6 +130,7 @@ func NewImageProcessor() *ImageProcessor {
 		maxFullSize:   1080,
 		thumbnailSize: 150,
 		jpegQuality:   85,
+		outputFormat:  ImageFormatJPEG,
 		allowedMimeTypes: map[string]bool{
 			"image/jpeg": true,
 			"image/png":  true,
@@ -46,6 +147,7 @@ func NewImageProcessorWithOptions(maxFullSize, thumbnailSize, jpegQuality int) *
 		maxFullSize:   maxFullSize,
 		thumbnailSize: thumbnailSize,
 		jpegQuality:   jpegQuality,
+		outputFormat:  ImageFormatJPEG,
 		allowedMimeTypes: map[string]bool{
 			"image/jpeg": true,
 			"image/png":  true,
@@ -56,6 +158,11 @@ func NewImageProcessorWithOptions(maxFullSize, thumbnailSize, jpegQuality int) *
 	}
 }
 
+// formatMB renders a byte limit for error messages, e.g. "5MB"
+func formatMB(size int64) string {
+	return strconv.FormatFloat(float64(size)/(1024*1024), 'f', -1, 64) + "MB"
+}
+
 // ProcessedImages contains both full-size and thumbnail versions
 type ProcessedImages struct {
 	Full      io.Reader
@@ -63,6 +170,7 @@ type ProcessedImages struct {
 	FullSize  int64
 	ThumbSize int64
 	MimeType  string
+	Extension string // Blob file extension matching MimeType, e.g. ".jpg"
 }
 
 // ValidateMagicBytes checks if the file is a valid image by reading magic bytes
@@ -95,8 +203,32 @@ func (p *ImageProcessor) ValidateMagicBytes(file multipart.File) (string, error)
 	}
 }
 
+// validateDimensions reads the width and height from the image header and rejects images
+// over the configured maximum, before a full decode allocates the pixel buffer
+func (p *ImageProcessor) validateDimensions(file multipart.File) error {
+	if p.maxInputWidth <= 0 && p.maxInputHeight <= 0 {
+		return nil
+	}
+
+	cfg, _, err := image.DecodeConfig(file)
+	if _, seekErr := file.Seek(0, io.SeekStart); seekErr != nil {
+		return fmt.Errorf("failed to reset file position: %w", seekErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read image dimensions: %w", err)
+	}
+
+	if (p.maxInputWidth > 0 && cfg.Width > p.maxInputWidth) || (p.maxInputHeight > 0 && cfg.Height > p.maxInputHeight) {
+		return fmt.Errorf("%w: %dx%d exceeds the maximum of %dx%d pixels",
+			ErrImageTooLarge, cfg.Width, cfg.Height, p.maxInputWidth, p.maxInputHeight)
+	}
+	return nil
+}
+
 // Process validates and processes an image, returning full-size and thumbnail versions
-// It validates magic bytes, strips EXIF, resizes, and generates thumbnail
+// It validates magic bytes, applies the EXIF Orientation tag (all 8 values) before resizing
+// and thumbnailing, and re-encodes to the output format (JPEG by default) so no EXIF
+// metadata (e.g. GPS) reaches the output
 func (p *ImageProcessor) Process(file multipart.File, fileHeader *multipart.FileHeader) (*ProcessedImages, error) {
 	// Validate file extension
 	ext := strings.ToLower(filepath.Ext(fileHeader.Filename))
@@ -114,7 +246,8 @@ func (p *ImageProcessor) Process(file multipart.File, fileHeader *multipart.File
 		return nil, fmt.Errorf("unsupported mime type: %s", mimeType)
 	}
 
-	// Decode image with EXIF auto-orientation (fixes portrait photos from phones)
+	// Decode image with EXIF auto-orientation (fixes portrait photos from phones).
+	// Rotation/flip happens here, so the full image and thumbnail below are both upright.
 	// imaging.Decode handles JPEG, PNG, GIF, BMP, and TIFF natively.
 	// For WebP, we need to ensure the decoder is registered.
 	var img image.Image
@@ -122,6 +255,9 @@ func (p *ImageProcessor) Process(file multipart.File, fileHeader *multipart.File
 	case "image/heic", "image/heif":
 		return nil, fmt.Errorf("HEIC/HEIF images are not yet supported, please convert to JPEG or PNG")
 	default:
+		if err := p.validateDimensions(file); err != nil {
+			return nil, err
+		}
 		img, err = imaging.Decode(file, imaging.AutoOrientation(true))
 	}
 
@@ -140,38 +276,48 @@ func (p *ImageProcessor) Process(file multipart.File, fileHeader *multipart.File
 		"mime_type", mimeType,
 	)
 
+	// Crop to square from center (thumbnail, and the full image when squareFull is set)
+	minDim := width
+	if height < width {
+		minDim = height
+	}
+	square := imaging.CropCenter(img, minDim, minDim)
+
+	fullSrc := img
+	if p.squareFull {
+		fullSrc, width, height = square, minDim, minDim
+	}
+
 	// Resize full image if needed (maintain aspect ratio)
 	var fullImg image.Image
 	if width > p.maxFullSize || height > p.maxFullSize {
 		if width > height {
-			fullImg = imaging.Resize(img, p.maxFullSize, 0, imaging.Lanczos)
+			fullImg = imaging.Resize(fullSrc, p.maxFullSize, 0, imaging.Lanczos)
 		} else {
-			fullImg = imaging.Resize(img, 0, p.maxFullSize, imaging.Lanczos)
+			fullImg = imaging.Resize(fullSrc, 0, p.maxFullSize, imaging.Lanczos)
 		}
 	} else {
-		fullImg = img
+		fullImg = fullSrc
 	}
 
-	// Generate thumbnail (square crop from center, then resize)
-	var thumbImg image.Image
-	minDim := width
-	if height < width {
-		minDim = height
+	// Generate thumbnail (resize the square crop)
+	thumbImg := imaging.Resize(square, p.thumbnailSize, p.thumbnailSize, imaging.Lanczos)
+
+	format := p.outputFormat
+	encode, ok := imageEncoderFor(format)
+	if !ok {
+		return nil, fmt.Errorf("no encoder available for image format %q", format)
 	}
-	// Crop to square from center
-	thumbImg = imaging.CropCenter(img, minDim, minDim)
-	// Resize to thumbnail size
-	thumbImg = imaging.Resize(thumbImg, p.thumbnailSize, p.thumbnailSize, imaging.Lanczos)
 
-	// Encode full image to JPEG (strips EXIF automatically)
+	// Encode full image (re-encoding strips EXIF automatically)
 	var fullBuf bytes.Buffer
-	if err := jpeg.Encode(&fullBuf, fullImg, &jpeg.Options{Quality: p.jpegQuality}); err != nil {
+	if err := encode(&fullBuf, fullImg, p.jpegQuality); err != nil {
 		return nil, fmt.Errorf("failed to encode full image: %w", err)
 	}
 
-	// Encode thumbnail to JPEG
+	// Encode thumbnail
 	var thumbBuf bytes.Buffer
-	if err := jpeg.Encode(&thumbBuf, thumbImg, &jpeg.Options{Quality: p.jpegQuality}); err != nil {
+	if err := encode(&thumbBuf, thumbImg, p.jpegQuality); err != nil {
 		return nil, fmt.Errorf("failed to encode thumbnail: %w", err)
 	}
 
@@ -183,6 +329,7 @@ func (p *ImageProcessor) Process(file multipart.File, fileHeader *multipart.File
 		"thumb_size_bytes", thumbSize,
 		"full_dimensions", fmt.Sprintf("%dx%d", fullImg.Bounds().Dx(), fullImg.Bounds().Dy()),
 		"thumb_dimensions", fmt.Sprintf("%dx%d", thumbImg.Bounds().Dx(), thumbImg.Bounds().Dy()),
+		"format", format,
 	)
 
 	return &ProcessedImages{
@@ -190,6 +337,7 @@ func (p *ImageProcessor) Process(file multipart.File, fileHeader *multipart.File
 		Thumbnail: bytes.NewReader(thumbBuf.Bytes()),
 		FullSize:  fullSize,
 		ThumbSize: thumbSize,
-		MimeType:  "image/jpeg", // Always output JPEG
+		MimeType:  format.MimeType(),
+		Extension: format.Extension(),
 	}, nil
 }
@@ -0,0 +1,231 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"io"
+	"mime/multipart"
+	"testing"
+
+	"golang.org/x/image/webp"
+)
+
+// Upright fixture: a 40x20 landscape image split into four solid quadrants.
+// Four distinct corners tell apart all 8 rotations/flips an EXIF tag can describe.
+const (
+	fixtureWidth  = 40
+	fixtureHeight = 20
+)
+
+var (
+	quadTopLeft     = color.RGBA{255, 0, 0, 255}
+	quadTopRight    = color.RGBA{0, 255, 0, 255}
+	quadBottomLeft  = color.RGBA{0, 0, 255, 255}
+	quadBottomRight = color.RGBA{255, 255, 0, 255}
+)
+
+// memFile adapts an in-memory buffer to multipart.File
+type memFile struct {
+	*bytes.Reader
+}
+
+func (memFile) Close() error { return nil }
+
+// uprightColorAt returns the fixture color at (x, y) of the upright image
+func uprightColorAt(x, y int) color.RGBA {
+	left, top := x < fixtureWidth/2, y < fixtureHeight/2
+	switch {
+	case top && left:
+		return quadTopLeft
+	case top:
+		return quadTopRight
+	case left:
+		return quadBottomLeft
+	default:
+		return quadBottomRight
+	}
+}
+
+// storedImage returns the pixels a camera would store for the given EXIF orientation,
+// i.e. the upright fixture with the inverse of the orientation's display transform applied
+func storedImage(orientation int) *image.RGBA {
+	w, h := fixtureWidth, fixtureHeight
+	if orientation >= 5 {
+		// Orientations 5-8 swap the stored width and height
+		w, h = h, w
+	}
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			var ux, uy int // upright coordinates shown at stored (x, y)
+			switch orientation {
+			case 1:
+				ux, uy = x, y
+			case 2: // mirrored horizontally
+				ux, uy = w-1-x, y
+			case 3: // rotated 180
+				ux, uy = w-1-x, h-1-y
+			case 4: // mirrored vertically
+				ux, uy = x, h-1-y
+			case 5: // transposed
+				ux, uy = y, x
+			case 6: // displayed after rotating 90 clockwise
+				ux, uy = h-1-y, x
+			case 7: // transversed
+				ux, uy = h-1-y, w-1-x
+			case 8: // displayed after rotating 90 counter-clockwise
+				ux, uy = y, w-1-x
+			}
+			img.SetRGBA(x, y, uprightColorAt(ux, uy))
+		}
+	}
+	return img
+}
+
+// exifOrientationSegment builds a minimal APP1 Exif segment holding only the Orientation tag
+func exifOrientationSegment(orientation int) []byte {
+	var tiff bytes.Buffer
+	tiff.WriteString("II*\x00")                              // little-endian TIFF header
+	binary.Write(&tiff, binary.LittleEndian, uint32(8))      // offset of IFD0
+	binary.Write(&tiff, binary.LittleEndian, uint16(1))      // one entry
+	binary.Write(&tiff, binary.LittleEndian, uint16(0x0112)) // Orientation
+	binary.Write(&tiff, binary.LittleEndian, uint16(3))      // SHORT
+	binary.Write(&tiff, binary.LittleEndian, uint32(1))      // count
+	binary.Write(&tiff, binary.LittleEndian, uint16(orientation))
+	binary.Write(&tiff, binary.LittleEndian, uint16(0)) // value padding
+	binary.Write(&tiff, binary.LittleEndian, uint32(0)) // no next IFD
+
+	payload := append([]byte("Exif\x00\x00"), tiff.Bytes()...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(len(payload)+2))
+	return append(segment, payload...)
+}
+
+// orientedJPEG encodes the stored pixels for orientation and inserts the Exif segment after SOI
+func orientedJPEG(t *testing.T, orientation int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, storedImage(orientation), &jpeg.Options{Quality: 100}); err != nil {
+		t.Fatalf("encode fixture: %v", err)
+	}
+	raw := buf.Bytes()
+	out := append([]byte{}, raw[:2]...)
+	out = append(out, exifOrientationSegment(orientation)...)
+	return append(out, raw[2:]...)
+}
+
+func colorsClose(got color.Color, want color.RGBA) bool {
+	r, g, b, _ := got.RGBA()
+	diff := func(a uint32, b uint8) int {
+		d := int(a>>8) - int(b)
+		if d < 0 {
+			d = -d
+		}
+		return d
+	}
+	const tolerance = 48 // JPEG round trips blur solid colors slightly
+	return diff(r, want.R) <= tolerance && diff(g, want.G) <= tolerance && diff(b, want.B) <= tolerance
+}
+
+// assertQuadrants checks the center of each quadrant of img against the upright fixture
+func assertQuadrants(t *testing.T, name string, img image.Image) {
+	t.Helper()
+	b := img.Bounds()
+	qx, qy := b.Dx()/4, b.Dy()/4
+	checks := []struct {
+		label string
+		x, y  int
+		want  color.RGBA
+	}{
+		{"top-left", qx, qy, quadTopLeft},
+		{"top-right", 3 * qx, qy, quadTopRight},
+		{"bottom-left", qx, 3 * qy, quadBottomLeft},
+		{"bottom-right", 3 * qx, 3 * qy, quadBottomRight},
+	}
+	for _, c := range checks {
+		if got := img.At(b.Min.X+c.x, b.Min.Y+c.y); !colorsClose(got, c.want) {
+			t.Errorf("%s %s quadrant = %v, want %v", name, c.label, got, c.want)
+		}
+	}
+}
+
+func TestProcessAppliesEXIFOrientation(t *testing.T) {
+	for orientation := 1; orientation <= 8; orientation++ {
+		orientation := orientation
+		t.Run(fmt.Sprintf("orientation_%d", orientation), func(t *testing.T) {
+			data := orientedJPEG(t, orientation)
+			file := memFile{bytes.NewReader(data)}
+			header := &multipart.FileHeader{Filename: "photo.jpg", Size: int64(len(data))}
+
+			processed, err := NewImageProcessor().Process(file, header)
+			if err != nil {
+				t.Fatalf("Process: %v", err)
+			}
+
+			fullBytes, err := io.ReadAll(processed.Full)
+			if err != nil {
+				t.Fatalf("read full image: %v", err)
+			}
+			thumbBytes, err := io.ReadAll(processed.Thumbnail)
+			if err != nil {
+				t.Fatalf("read thumbnail: %v", err)
+			}
+
+			for name, out := range map[string][]byte{"full": fullBytes, "thumbnail": thumbBytes} {
+				if bytes.Contains(out, []byte("Exif\x00\x00")) {
+					t.Errorf("%s output still carries EXIF metadata", name)
+				}
+			}
+
+			full, err := jpeg.Decode(bytes.NewReader(fullBytes))
+			if err != nil {
+				t.Fatalf("decode full image: %v", err)
+			}
+			if got := full.Bounds().Size(); got.X != fixtureWidth || got.Y != fixtureHeight {
+				t.Fatalf("full image is %dx%d, want upright %dx%d", got.X, got.Y, fixtureWidth, fixtureHeight)
+			}
+			assertQuadrants(t, "full", full)
+
+			// The thumbnail is a center square crop, which still spans all four quadrants
+			thumb, err := jpeg.Decode(bytes.NewReader(thumbBytes))
+			if err != nil {
+				t.Fatalf("decode thumbnail: %v", err)
+			}
+			assertQuadrants(t, "thumbnail", thumb)
+		})
+	}
+}
+
+func TestProcessEncodesWebP(t *testing.T) {
+	data := orientedJPEG(t, 6)
+	file := memFile{bytes.NewReader(data)}
+	header := &multipart.FileHeader{Filename: "photo.jpg", Size: int64(len(data))}
+
+	processor := NewImageProcessor()
+	processor.outputFormat = ParseImageFormat("WebP")
+	processed, err := processor.Process(file, header)
+	if err != nil {
+		t.Fatalf("Process: %v", err)
+	}
+	if processed.MimeType != "image/webp" || processed.Extension != ".webp" {
+		t.Errorf("output is %s (%s), want image/webp (.webp)", processed.MimeType, processed.Extension)
+	}
+
+	full, err := webp.Decode(processed.Full)
+	if err != nil {
+		t.Fatalf("decode full image: %v", err)
+	}
+	assertQuadrants(t, "full", full)
+}
+
+func TestParseImageFormatFallsBackToJPEG(t *testing.T) {
+	for _, name := range []string{"", "jpg", "JPEG", "avif", "gif"} {
+		if got := ParseImageFormat(name); got != ImageFormatJPEG {
+			t.Errorf("ParseImageFormat(%q) = %q, want %q", name, got, ImageFormatJPEG)
+		}
+	}
+}
@@ -0,0 +1,16 @@
+package services
+
+import (
+	"os"
+	"testing"
+
+	"github.com/aman1117/backend/internal/logger"
+	"go.uber.org/zap"
+)
+
+func TestMain(m *testing.M) {
+	// Services log through the package-level logger, which main normally initializes
+	logger.Log = zap.NewNop()
+	logger.Sugar = logger.Log.Sugar()
+	os.Exit(m.Run())
+}
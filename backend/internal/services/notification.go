@@ -61,16 +61,17 @@ func (s *NotificationService) Create(ctx context.Context, notif *models.Notifica
 	return nil
 }
 
-// GetByUserID retrieves paginated notifications for a user
-func (s *NotificationService) GetByUserID(ctx context.Context, userID uint, page, pageSize int) ([]models.Notification, int64, error) {
+// GetByUserID retrieves paginated notifications for a user.
+// A non-empty types list restricts both the page and the total to those types.
+func (s *NotificationService) GetByUserID(ctx context.Context, userID uint, types []models.NotificationType, page, pageSize int) ([]models.Notification, int64, error) {
 	offset := (page - 1) * pageSize
 
-	notifs, err := s.repo.GetByUserID(userID, pageSize, offset)
+	notifs, err := s.repo.GetByUserID(userID, types, pageSize, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to get notifications: %w", err)
 	}
 
-	total, err := s.repo.CountByUserID(userID)
+	total, err := s.repo.CountByUserID(userID, types)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
 	}
@@ -78,6 +79,96 @@ func (s *NotificationService) GetByUserID(ctx context.Context, userID uint, page
 	return notifs, total, nil
 }
 
+// GetGroupedByUserID retrieves a page of notifications collapsed into groups.
+// Grouping is a pass over the paginated rows, so groups never span pages.
+func (s *NotificationService) GetGroupedByUserID(ctx context.Context, userID uint, types []models.NotificationType, page, pageSize int) ([]models.NotificationGroup, int64, error) {
+	notifs, total, err := s.GetByUserID(ctx, userID, types, page, pageSize)
+	if err != nil {
+		return nil, 0, err
+	}
+	return groupNotifications(notifs), total, nil
+}
+
+// notificationGroupKeys maps groupable notification types to their actor metadata
+// prefix and the metadata key identifying the entity acted upon ("" groups all rows of the type)
+var notificationGroupKeys = map[models.NotificationType]struct {
+	actorPrefix string
+	entityKey   string
+}{
+	models.NotifTypeLikeReceived:    {"liker", "liked_date"},
+	models.NotifTypeStoryLiked:      {"liker", "photo_id"},
+	models.NotifTypeStoryReply:      {"sender", "photo_id"},
+	models.NotifTypeNewFollower:     {"actor", ""},
+	models.NotifTypeCommentLiked:    {"author", "comment_id"},
+	models.NotifTypePhotoUploaded:   {"uploader", "photo_date"},
+	models.NotifTypeCommentReceived: {"author", "day_date"},
+}
+
+// groupNotifications collapses consecutive notifications (newest first) with the same type
+// and entity into one group. Types without a grouping rule always form single-member groups.
+func groupNotifications(notifs []models.Notification) []models.NotificationGroup {
+	groups := make([]models.NotificationGroup, 0, len(notifs))
+	lastKey := ""
+
+	for _, n := range notifs {
+		rule, groupable := notificationGroupKeys[n.Type]
+		key := ""
+		if groupable {
+			key = fmt.Sprintf("%s:%v", n.Type, n.Metadata[rule.entityKey])
+		}
+
+		if groupable && key == lastKey && len(groups) > 0 {
+			g := &groups[len(groups)-1]
+			g.IDs = append(g.IDs, n.ID)
+			g.Unread = g.Unread || !n.IsRead()
+			if actor, ok := notificationActor(n.Metadata, rule.actorPrefix); ok && !hasActor(g.Actors, actor.ID) {
+				g.Actors = append(g.Actors, actor)
+			}
+			continue
+		}
+
+		g := models.NotificationGroup{
+			Latest: n,
+			IDs:    []uint{n.ID},
+			Unread: !n.IsRead(),
+		}
+		if groupable {
+			if actor, ok := notificationActor(n.Metadata, rule.actorPrefix); ok {
+				g.Actors = []models.NotificationActor{actor}
+			}
+		}
+		groups = append(groups, g)
+		lastKey = key
+	}
+
+	return groups
+}
+
+// notificationActor reads <prefix>_id/_username/_avatar from notification metadata
+func notificationActor(m models.NotificationMetadata, prefix string) (models.NotificationActor, bool) {
+	var id uint
+	switch v := m[prefix+"_id"].(type) {
+	case float64:
+		id = uint(v)
+	case uint:
+		id = v
+	default:
+		return models.NotificationActor{}, false
+	}
+	username, _ := m[prefix+"_username"].(string)
+	avatar, _ := m[prefix+"_avatar"].(string)
+	return models.NotificationActor{ID: id, Username: username, Avatar: avatar}, true
+}
+
+func hasActor(actors []models.NotificationActor, id uint) bool {
+	for _, a := range actors {
+		if a.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
 // GetUnreadCount returns the unread notification count (with caching)
 func (s *NotificationService) GetUnreadCount(ctx context.Context, userID uint) (int64, error) {
 	// Try cache first
@@ -115,6 +206,31 @@ func (s *NotificationService) MarkAsRead(ctx context.Context, id, userID uint) e
 	return nil
 }
 
+// MarkAsUnread marks a single notification as unread again.
+// Marking an already-unread notification is a successful no-op.
+func (s *NotificationService) MarkAsUnread(ctx context.Context, id, userID uint) error {
+	notif, err := s.repo.GetByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to get notification: %w", err)
+	}
+	if notif == nil || notif.UserID != userID {
+		return fmt.Errorf("notification not found")
+	}
+	if !notif.IsRead() {
+		return nil
+	}
+
+	updated, err := s.repo.MarkAsUnread(id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to mark notification as unread: %w", err)
+	}
+
+	if updated > 0 {
+		s.invalidateUnreadCache(ctx, userID)
+	}
+	return nil
+}
+
 // MarkAllAsRead marks all notifications as read for a user
 func (s *NotificationService) MarkAllAsRead(ctx context.Context, userID uint) error {
 	if err := s.repo.MarkAllAsRead(userID); err != nil {
@@ -147,6 +263,21 @@ func (s *NotificationService) Delete(ctx context.Context, id, userID uint) error
 	return nil
 }
 
+// RetractDayCompleted removes the day-completed notifications followers received for a
+// user's date, used when the day drops back under 24 hours. The dedupe record is kept,
+// so completing the day again does not notify followers a second time.
+// Push notifications already delivered cannot be recalled.
+func (s *NotificationService) RetractDayCompleted(ctx context.Context, completedUserID uint, completedDate string) error {
+	unreadRecipients, err := s.repo.DeleteDayCompleted(completedUserID, completedDate)
+	if err != nil {
+		return fmt.Errorf("failed to delete day completed notifications: %w", err)
+	}
+	for _, recipientID := range unreadRecipients {
+		s.invalidateUnreadCache(ctx, recipientID)
+	}
+	return nil
+}
+
 // ==================== Notification Triggers ====================
 
 // NotifyLikeReceived creates a notification when someone likes a user's day.
@@ -293,25 +424,106 @@ func (s *NotificationService) NotifyBadgeUnlocked(
 	return nil
 }
 
-// NotifyStreakMilestone creates a notification for streak milestones
+// NotifyVerificationApproved tells a user their verification request was approved
+func (s *NotificationService) NotifyVerificationApproved(ctx context.Context, userID, requestID uint) error {
+	notif := &models.Notification{
+		UserID: userID,
+		Type:   models.NotifTypeVerificationApproved,
+		Title:  "You're Verified! ✅",
+		Body:   "Your verification request was approved. Your profile now shows the verified badge.",
+		Metadata: models.NotificationMetadata{
+			"request_id": requestID,
+		},
+	}
+
+	if err := s.Create(ctx, notif); err != nil {
+		return err
+	}
+
+	if publisher := GetPushPublisher(); publisher != nil && publisher.IsAvailable() {
+		dedupeKey := fmt.Sprintf("verification_approved:%d", requestID)
+		if err := publisher.PublishFromNotification(ctx, notif, dedupeKey, "/profile"); err != nil {
+			logger.Sugar.Warnw("Failed to publish push notification for verification approval",
+				"notif_id", notif.ID,
+				"error", err,
+			)
+		}
+	}
+
+	return nil
+}
+
+// NotifyStreakMilestone creates a notification for streak milestones.
+// Deduped per (user, activity type, count, streak date) so re-running the daily job is safe.
 func (s *NotificationService) NotifyStreakMilestone(
 	ctx context.Context,
 	userID uint,
 	activityType string,
 	streakCount int,
+	streakDate string,
 ) error {
-	notif := &models.Notification{
-		UserID: userID,
-		Type:   models.NotifTypeStreakMilestone,
-		Title:  "Streak Milestone! 🔥",
-		Body:   fmt.Sprintf("You've maintained a %d-day %s streak!", streakCount, activityType),
-		Metadata: models.StreakMetadata{
-			ActivityType: activityType,
-			StreakCount:  streakCount,
-		}.ToMap(),
-	}
+	entityKey := fmt.Sprintf("%s:%d:%s", activityType, streakCount, streakDate)
 
-	return s.Create(ctx, notif)
+	db := s.repo.GetDB()
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dedupe := &models.NotificationDedupe{
+			UserID:     userID,
+			ActorID:    userID,
+			Type:       models.NotifTypeStreakMilestone,
+			EntityType: "streak_milestone",
+			EntityKey:  entityKey,
+		}
+
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(dedupe)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			logger.Sugar.Debugw("Skipping duplicate streak milestone notification",
+				"user_id", userID,
+				"entity_key", entityKey,
+			)
+			return nil
+		}
+
+		notif := &models.Notification{
+			UserID: userID,
+			Type:   models.NotifTypeStreakMilestone,
+			Title:  "Streak Milestone! 🔥",
+			Body:   fmt.Sprintf("You've maintained a %d-day %s streak!", streakCount, activityType),
+			Metadata: models.StreakMetadata{
+				ActivityType: activityType,
+				StreakCount:  streakCount,
+			}.ToMap(),
+		}
+
+		if err := tx.Create(notif).Error; err != nil {
+			return err
+		}
+
+		logger.Sugar.Infow("Streak milestone notification created",
+			"id", notif.ID,
+			"user_id", userID,
+			"activity_type", activityType,
+			"streak_count", streakCount,
+		)
+
+		// Side effects
+		s.invalidateUnreadCache(ctx, userID)
+		s.publishNotification(ctx, notif)
+
+		if publisher := GetPushPublisher(); publisher != nil && publisher.IsAvailable() {
+			dedupeKey := fmt.Sprintf("streak_milestone:%d:%s", userID, entityKey)
+			if err := publisher.PublishFromNotification(ctx, notif, dedupeKey, "/"); err != nil {
+				logger.Sugar.Warnw("Failed to publish push notification for streak milestone",
+					"notif_id", notif.ID,
+					"error", err,
+				)
+			}
+		}
+
+		return nil
+	})
 }
 
 // NotifyStreakAtRisk creates a notification when a streak is about to break
@@ -877,6 +1089,64 @@ func (s *NotificationService) NotifyCommentReply(
 	})
 }
 
+// NotifyStoryReply creates a notification when someone replies to a user's story
+func (s *NotificationService) NotifyStoryReply(
+	ctx context.Context,
+	ownerID, senderID uint,
+	senderUsername, senderAvatar, ownerUsername string,
+	replyID, photoID uint, photoDate, replyPreview string,
+) error {
+	entityKey := fmt.Sprintf("story_reply:%d", replyID)
+
+	db := s.repo.GetDB()
+	return db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		dedupe := &models.NotificationDedupe{
+			UserID:     ownerID,
+			ActorID:    senderID,
+			Type:       models.NotifTypeStoryReply,
+			EntityType: "story_reply",
+			EntityKey:  entityKey,
+		}
+		result := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(dedupe)
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+
+		notif := &models.Notification{
+			UserID: ownerID,
+			Type:   models.NotifTypeStoryReply,
+			Title:  "New Reply",
+			Body:   fmt.Sprintf("%s replied to your story: %s", senderUsername, replyPreview),
+			Metadata: models.StoryReplyMetadata{
+				ReplyID:        replyID,
+				SenderID:       senderID,
+				SenderUsername: senderUsername,
+				SenderAvatar:   senderAvatar,
+				PhotoID:        photoID,
+				PhotoDate:      photoDate,
+				ReplyPreview:   replyPreview,
+			}.ToMap(),
+		}
+
+		if err := tx.Create(notif).Error; err != nil {
+			return err
+		}
+
+		s.invalidateUnreadCache(ctx, ownerID)
+		s.publishNotification(ctx, notif)
+
+		if publisher := GetPushPublisher(); publisher != nil && publisher.IsAvailable() {
+			deepLink := fmt.Sprintf("/user/%s?date=%s&photo=%d", ownerUsername, photoDate, photoID)
+			publisher.PublishFromNotification(ctx, notif, entityKey, deepLink)
+		}
+
+		return nil
+	})
+}
+
 // NotifyCommentMention creates a notification when a user is @mentioned in a comment
 func (s *NotificationService) NotifyCommentMention(
 	ctx context.Context,
@@ -1048,8 +1318,19 @@ func (s *NotificationService) queuePendingNotification(ctx context.Context, user
 	}
 }
 
+// GetNotificationsSince replays notifications newer than lastSeq from the database.
+// This is the source of truth on reconnect: unlike the Redis pending queue it is not
+// drained, so every tab resuming from its own seq gets the full gap.
+func (s *NotificationService) GetNotificationsSince(ctx context.Context, userID uint, lastSeq int64) ([]models.Notification, error) {
+	notifs, err := s.repo.GetAfterSeq(userID, lastSeq, constants.NotifReplayMaxCount)
+	if err != nil {
+		return nil, fmt.Errorf("failed to replay notifications: %w", err)
+	}
+	return notifs, nil
+}
+
 // GetPendingNotifications retrieves and clears pending notifications for a user
-// Called when WebSocket reconnects to deliver missed notifications
+// Fast path on WebSocket reconnect for clients that don't send a last-seen seq
 func (s *NotificationService) GetPendingNotifications(ctx context.Context, userID uint) ([]models.Notification, error) {
 	if !redis.IsAvailable() {
 		return nil, nil
@@ -1086,14 +1367,16 @@ func (s *NotificationService) GetPendingNotifications(ctx context.Context, userI
 	return notifications, nil
 }
 
-// SubscribeToNotifications returns a Redis pub/sub subscription for a user
+// SubscribeToNotifications returns a Redis pub/sub subscription for a user's
+// notification channel and follow-count change channel
 func (s *NotificationService) SubscribeToNotifications(ctx context.Context, userID uint) *goredis.PubSub {
 	if !redis.IsAvailable() {
 		return nil
 	}
 
 	channel := fmt.Sprintf("%s%d", constants.NotifChannelPrefix, userID)
-	return redis.Get().Subscribe(ctx, channel)
+	countChannel := fmt.Sprintf("%s%d", constants.FollowCountChannelPrefix, userID)
+	return redis.Get().Subscribe(ctx, channel, countChannel)
 }
 
 // ==================== WebSocket Connection Tracking ====================
@@ -1165,27 +1448,27 @@ func (s *NotificationService) GetWSConnectionCount(ctx context.Context, userID u
 
 // ==================== Cleanup Operations ====================
 
-// CleanupOldNotifications removes old notifications based on retention policy
-func (s *NotificationService) CleanupOldNotifications(ctx context.Context) (int64, error) {
-	readCutoff := time.Now().AddDate(0, 0, -constants.NotifReadRetentionDays)
-	unreadCutoff := time.Now().AddDate(0, 0, -constants.NotifUnreadRetentionDays)
+// CleanupOldNotifications removes old notifications based on retention policy.
+// Non-positive read/unread days fall back to the package defaults; retentionDaysByType
+// overrides both for the listed types. Returns the deleted count per type.
+func (s *NotificationService) CleanupOldNotifications(ctx context.Context, readDays, unreadDays int, retentionDaysByType map[models.NotificationType]int) (map[models.NotificationType]int64, error) {
+	if readDays <= 0 {
+		readDays = constants.NotifReadRetentionDays
+	}
+	if unreadDays <= 0 {
+		unreadDays = constants.NotifUnreadRetentionDays
+	}
+	readCutoff := time.Now().AddDate(0, 0, -readDays)
+	unreadCutoff := time.Now().AddDate(0, 0, -unreadDays)
 
-	deleted, err := s.repo.DeleteOlderThan(readCutoff, unreadCutoff)
+	deleted, err := s.repo.DeleteOlderThan(readCutoff, unreadCutoff, retentionDaysByType)
 	if err != nil {
 		logger.Sugar.Errorw("Failed to cleanup old notifications",
 			"read_cutoff", readCutoff,
 			"unread_cutoff", unreadCutoff,
 			"error", err,
 		)
-		return 0, err
-	}
-
-	if deleted > 0 {
-		logger.Sugar.Infow("Cleaned up old notifications",
-			"deleted_count", deleted,
-			"read_cutoff", readCutoff,
-			"unread_cutoff", unreadCutoff,
-		)
+		return deleted, err
 	}
 
 	return deleted, nil
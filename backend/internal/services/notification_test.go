@@ -0,0 +1,119 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/internal/testutil"
+	"github.com/aman1117/backend/pkg/models"
+	"github.com/aman1117/backend/pkg/redis"
+)
+
+func TestUnreadCountCache(t *testing.T) {
+	db := testutil.OpenDB(t)
+	testutil.UseRedis(t)
+
+	ctx := context.Background()
+	repo := repository.NewNotificationRepository(db)
+	svc := NewNotificationService(repo)
+
+	const userID uint = 4242
+	cacheKey := fmt.Sprintf("%s%d", constants.NotifUnreadPrefix, userID)
+	redis.Get().Del(ctx, cacheKey)
+	t.Cleanup(func() { redis.Get().Del(context.Background(), cacheKey) })
+
+	newNotif := func() *models.Notification {
+		return &models.Notification{UserID: userID, Type: models.NotifTypeSystemAnnounce, Title: "Hello"}
+	}
+	expectCount := func(t *testing.T, ctx context.Context, want int64) {
+		t.Helper()
+		got, err := svc.GetUnreadCount(ctx, userID)
+		if err != nil {
+			t.Fatalf("GetUnreadCount: %v", err)
+		}
+		if got != want {
+			t.Fatalf("unread count = %d, want %d", got, want)
+		}
+	}
+	expectCached := func(t *testing.T, want int64) {
+		t.Helper()
+		got, err := redis.Get().Get(ctx, cacheKey).Int64()
+		if err != nil {
+			t.Fatalf("read cached count: %v", err)
+		}
+		if got != want {
+			t.Fatalf("cached count = %d, want %d", got, want)
+		}
+	}
+
+	first, second := newNotif(), newNotif()
+	for _, n := range []*models.Notification{first, second} {
+		if err := svc.Create(ctx, n); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+	}
+
+	t.Run("miss loads from the database and caches", func(t *testing.T) {
+		expectCount(t, ctx, 2)
+		expectCached(t, 2)
+	})
+
+	t.Run("hit is served from the cache", func(t *testing.T) {
+		// Written straight to the repository, so nothing invalidates the cached count
+		if err := repo.Create(newNotif()); err != nil {
+			t.Fatalf("insert notification: %v", err)
+		}
+		expectCount(t, ctx, 2)
+	})
+
+	t.Run("mark as read invalidates", func(t *testing.T) {
+		if err := svc.MarkAsRead(ctx, first.ID, userID); err != nil {
+			t.Fatalf("MarkAsRead: %v", err)
+		}
+		expectCount(t, ctx, 2) // 3 stored, 1 read
+		expectCached(t, 2)
+	})
+
+	t.Run("mark as unread invalidates", func(t *testing.T) {
+		if err := svc.MarkAsUnread(ctx, first.ID, userID); err != nil {
+			t.Fatalf("MarkAsUnread: %v", err)
+		}
+		expectCount(t, ctx, 3)
+		expectCached(t, 3)
+	})
+
+	t.Run("marking an unread notification unread is a no-op", func(t *testing.T) {
+		if err := svc.MarkAsUnread(ctx, first.ID, userID); err != nil {
+			t.Fatalf("MarkAsUnread: %v", err)
+		}
+		expectCount(t, ctx, 3)
+	})
+
+	t.Run("someone else's notification is rejected", func(t *testing.T) {
+		if err := svc.MarkAsRead(ctx, second.ID, userID); err != nil {
+			t.Fatalf("MarkAsRead: %v", err)
+		}
+		if err := svc.MarkAsUnread(ctx, second.ID, userID+1); err == nil {
+			t.Fatal("MarkAsUnread on another user's notification succeeded")
+		}
+		expectCount(t, ctx, 2)
+	})
+
+	t.Run("new notification invalidates", func(t *testing.T) {
+		if err := svc.Create(ctx, newNotif()); err != nil {
+			t.Fatalf("Create: %v", err)
+		}
+		expectCount(t, ctx, 3)
+	})
+
+	t.Run("falls back to the database when redis errors", func(t *testing.T) {
+		// A cancelled context fails every redis call; the count must still come from the database
+		redis.Get().Set(ctx, cacheKey, 99, constants.NotifUnreadCacheTTL)
+		cancelled, cancel := context.WithCancel(ctx)
+		cancel()
+		expectCount(t, cancelled, 3)
+	})
+}
@@ -0,0 +1,172 @@
+// Package services contains business logic for profile pictures.
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/aman1117/backend/internal/config"
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/pkg/models"
+	"github.com/google/uuid"
+)
+
+// ErrProfilePicUserNotFound is returned when the uploading user no longer exists
+var ErrProfilePicUserNotFound = errors.New("user not found")
+
+// ErrUnsupportedImageType is returned for files without an allowed image extension
+var ErrUnsupportedImageType = errors.New("only JPG, PNG, WebP, and HEIC images are allowed")
+
+// ProfilePicService processes, stores and replaces user profile pictures
+type ProfilePicService struct {
+	userRepo       *repository.UserRepository
+	imageProcessor *ImageProcessor
+	blobClient     *azblob.Client
+	container      string
+	accountName    string
+	maxUploadBytes int64
+}
+
+// ProfilePicURLs holds the URLs of a stored profile picture
+type ProfilePicURLs struct {
+	Full      string
+	Thumbnail string
+}
+
+// NewProfilePicService creates a new ProfilePicService and makes sure its container exists
+func NewProfilePicService(userRepo *repository.UserRepository, cfg *config.AzureStorageConfig) (*ProfilePicService, error) {
+	client, err := azblob.NewClientFromConnectionString(cfg.ConnectionString, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blob client: %w", err)
+	}
+
+	svc := &ProfilePicService{
+		userRepo: userRepo,
+		imageProcessor: NewImageProcessorWithOptions(constants.ProfilePicFullSize, constants.ProfilePicThumbSize, constants.ProfilePicQuality).
+			WithSquareCrop().
+			WithMaxDimensions(cfg.ProfilePicLimit.MaxWidth, cfg.ProfilePicLimit.MaxHeight),
+		blobClient:     client,
+		container:      cfg.ContainerName,
+		accountName:    cfg.AccountName,
+		maxUploadBytes: cfg.ProfilePicLimit.MaxBytes,
+	}
+	if svc.maxUploadBytes <= 0 {
+		svc.maxUploadBytes = constants.MaxProfilePicSize
+	}
+
+	// Create container if it doesn't exist
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if _, err := client.CreateContainer(ctx, cfg.ContainerName, nil); err != nil && !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+		logger.Sugar.Warnf("Container creation warning: %v", err)
+	}
+
+	return svc, nil
+}
+
+// Upload square-crops and resizes an uploaded image, stores it with a thumbnail and points
+// the user's profile at the new blobs. The previous picture's blobs are only removed once
+// the user row is updated, so a failed upload leaves the old avatar intact.
+func (s *ProfilePicService) Upload(ctx context.Context, userID uint, file multipart.File, fileHeader *multipart.FileHeader) (*ProfilePicURLs, error) {
+	if fileHeader.Size > s.maxUploadBytes {
+		return nil, fmt.Errorf("%w: the maximum file size is %s", ErrImageTooLarge, formatMB(s.maxUploadBytes))
+	}
+	if !constants.AllowedImageExtensions[strings.ToLower(filepath.Ext(fileHeader.Filename))] {
+		return nil, ErrUnsupportedImageType
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, ErrProfilePicUserNotFound
+	}
+
+	processed, err := s.imageProcessor.Process(file, fileHeader)
+	if err != nil {
+		if errors.Is(err, ErrImageTooLarge) {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%w: %w", ErrInvalidImage, err)
+	}
+
+	photoUUID := uuid.New().String()
+	fullBlobName := fmt.Sprintf("%d/%s%s", userID, photoUUID, processed.Extension)
+	thumbBlobName := fmt.Sprintf("%d/%s_thumb%s", userID, photoUUID, processed.Extension)
+
+	if err := uploadBlobWithRetry(ctx, s.blobClient, s.container, fullBlobName, processed.Full, processed.MimeType); err != nil {
+		return nil, fmt.Errorf("failed to upload profile picture: %w", err)
+	}
+	if err := uploadBlobWithRetry(ctx, s.blobClient, s.container, thumbBlobName, processed.Thumbnail, processed.MimeType); err != nil {
+		s.deleteBlob(ctx, fullBlobName)
+		return nil, fmt.Errorf("failed to upload profile picture thumbnail: %w", err)
+	}
+
+	urls := &ProfilePicURLs{
+		Full:      blobURL(s.accountName, s.container, fullBlobName),
+		Thumbnail: blobURL(s.accountName, s.container, thumbBlobName),
+	}
+	if err := s.userRepo.UpdateProfilePic(userID, &urls.Full, &urls.Thumbnail); err != nil {
+		s.deleteBlob(ctx, fullBlobName)
+		s.deleteBlob(ctx, thumbBlobName)
+		return nil, fmt.Errorf("failed to update profile picture: %w", err)
+	}
+
+	s.deleteUserBlobs(ctx, user)
+
+	logger.Sugar.Infow("Profile picture uploaded",
+		"user_id", userID,
+		"full_url", urls.Full,
+		"thumb_url", urls.Thumbnail,
+		"full_size_bytes", processed.FullSize,
+		"thumb_size_bytes", processed.ThumbSize,
+	)
+
+	return urls, nil
+}
+
+// Delete clears the user's profile picture and removes its blobs
+func (s *ProfilePicService) Delete(ctx context.Context, userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return ErrProfilePicUserNotFound
+	}
+
+	if err := s.userRepo.UpdateProfilePic(userID, nil, nil); err != nil {
+		return fmt.Errorf("failed to clear profile picture: %w", err)
+	}
+	s.deleteUserBlobs(ctx, user)
+
+	logger.Sugar.Infow("Profile picture deleted", "user_id", userID)
+	return nil
+}
+
+// deleteUserBlobs removes the full and thumbnail blobs of the user's stored profile picture
+func (s *ProfilePicService) deleteUserBlobs(ctx context.Context, user *models.User) {
+	for _, url := range []*string{user.ProfilePic, user.ProfilePicThumb} {
+		if url == nil || *url == "" {
+			continue
+		}
+		if blobName := blobNameFromURL(*url, s.container); blobName != "" {
+			s.deleteBlob(ctx, blobName)
+		}
+	}
+}
+
+// deleteBlob removes a blob, logging failures
+func (s *ProfilePicService) deleteBlob(ctx context.Context, blobName string) {
+	deleteCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	if _, err := s.blobClient.DeleteBlob(deleteCtx, s.container, blobName, nil); err != nil && !bloberror.HasCode(err, bloberror.BlobNotFound) {
+		logger.Sugar.Warnw("Failed to delete profile picture blob", "blob_name", blobName, "error", err)
+	}
+}
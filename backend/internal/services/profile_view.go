@@ -0,0 +1,58 @@
+package services
+
+import (
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/pkg/models"
+)
+
+// ProfileViewService handles "who viewed your profile" tracking
+type ProfileViewService struct {
+	viewRepo *repository.ProfileViewRepository
+	userRepo *repository.UserRepository
+}
+
+// NewProfileViewService creates a new ProfileViewService
+func NewProfileViewService(viewRepo *repository.ProfileViewRepository, userRepo *repository.UserRepository) *ProfileViewService {
+	return &ProfileViewService{
+		viewRepo: viewRepo,
+		userRepo: userRepo,
+	}
+}
+
+// RecordView records that viewerID viewed targetID's profile (throttled per pair).
+// Self-views and viewers who hide their profile views are not recorded. Unlike story
+// ghost mode, a failed opt-out lookup skips recording rather than risk exposing the viewer.
+func (s *ProfileViewService) RecordView(viewerID, targetID uint) error {
+	if viewerID == 0 || viewerID == targetID {
+		return nil
+	}
+
+	hidden, err := s.userRepo.GetHideProfileViews(viewerID)
+	if err != nil {
+		logger.Sugar.Warnw("Failed to check profile view opt-out, skipping view", "viewer_id", viewerID, "error", err)
+		return nil
+	}
+	if hidden {
+		return nil
+	}
+
+	return s.viewRepo.RecordView(viewerID, targetID, constants.ProfileViewThrottle)
+}
+
+// GetViewers returns a page of the users who viewed ownerID's profile, most recent first
+func (s *ProfileViewService) GetViewers(ownerID uint, page, pageSize int) ([]models.ProfileViewer, int64, error) {
+	offset := (page - 1) * pageSize
+	return s.viewRepo.GetViewers(ownerID, pageSize, offset)
+}
+
+// SetHideProfileViews sets whether the user's own profile views are hidden from others
+func (s *ProfileViewService) SetHideProfileViews(userID uint, hidden bool) error {
+	return s.userRepo.UpdateHideProfileViews(userID, hidden)
+}
+
+// IsHidingProfileViews reports whether the user's own profile views are hidden from others
+func (s *ProfileViewService) IsHidingProfileViews(userID uint) (bool, error) {
+	return s.userRepo.GetHideProfileViews(userID)
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/Azure/azure-sdk-for-go/sdk/messaging/azservicebus"
 	"github.com/aman1117/backend/internal/config"
 	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
 	"github.com/aman1117/backend/pkg/models"
 )
 
@@ -28,6 +29,7 @@ type PushMessage struct {
 	Data             map[string]interface{} `json:"data,omitempty"`
 	TTLSeconds       int                    `json:"ttl_seconds"`
 	CreatedAt        time.Time              `json:"created_at"`
+	RetryAttempt     int                    `json:"retry_attempt,omitempty"` // Set by the worker on scheduled redelivery
 }
 
 // PushPublisher handles publishing push notifications to Azure Service Bus
@@ -43,6 +45,9 @@ type PushPublisher struct {
 	batchWait time.Duration
 	wg        sync.WaitGroup
 	closed    bool
+
+	// Used to honor per-type preferences and quiet hours before enqueuing
+	pushRepo *repository.PushRepository
 }
 
 // pushJob represents an async publish request
@@ -62,11 +67,22 @@ var (
 	pushPublisherOnce sync.Once
 )
 
+// PushTypeTest is the push type used by the test-push endpoint; it has no in-app notification
+const PushTypeTest models.NotificationType = "push_test"
+
+// criticalPushTypes are delivered regardless of per-type preferences and quiet hours
+var criticalPushTypes = map[models.NotificationType]bool{
+	models.NotifTypeSystemAnnounce: true,
+}
+
 // InitPushPublisher initializes the global push publisher
-func InitPushPublisher(cfg *config.Config) error {
+func InitPushPublisher(cfg *config.Config, pushRepo *repository.PushRepository) error {
 	var initErr error
 	pushPublisherOnce.Do(func() {
 		pushPublisher, initErr = NewPushPublisher(cfg)
+		if pushPublisher != nil {
+			pushPublisher.pushRepo = pushRepo
+		}
 	})
 	return initErr
 }
@@ -337,6 +353,42 @@ func (p *PushPublisher) PublishPushNotification(
 		return nil
 	}
 
+	if !criticalPushTypes[notificationType] && !p.allowedByPreferences(userID, notificationType) {
+		return nil
+	}
+
+	_, err := p.enqueue(ctx, userID, notificationType, title, body, dedupeKey, deepLink, data, ttlSeconds)
+	return err
+}
+
+// PublishTestPush enqueues a fixed test message for a user so their subscriptions can be
+// checked end-to-end. It skips the publish-time preference check but is otherwise delivered
+// by the worker like any other push. Returns the queued message ID.
+func (p *PushPublisher) PublishTestPush(ctx context.Context, userID uint) (string, error) {
+	if !p.IsAvailable() {
+		return "", fmt.Errorf("push publisher not available")
+	}
+
+	// Unique per request so the worker's dedupe window never swallows a retry
+	dedupeKey := fmt.Sprintf("push_test:%d:%d", userID, time.Now().UnixNano())
+	return p.enqueue(ctx, userID, PushTypeTest,
+		"Test notification 🔔",
+		"Push notifications are working on this device.",
+		dedupeKey, "/", nil, 300,
+	)
+}
+
+// enqueue builds a push message and queues it for the background worker, returning its ID
+func (p *PushPublisher) enqueue(
+	ctx context.Context,
+	userID uint,
+	notificationType models.NotificationType,
+	title, body string,
+	dedupeKey string,
+	deepLink string,
+	data map[string]interface{},
+	ttlSeconds int,
+) (string, error) {
 	// Validate deep link
 	if deepLink != "" && !isValidDeepLink(deepLink) {
 		logger.Sugar.Warnw("Invalid deep link, clearing",
@@ -363,7 +415,7 @@ func (p *PushPublisher) PublishPushNotification(
 	// Serialize
 	payload, err := json.Marshal(msg)
 	if err != nil {
-		return fmt.Errorf("failed to marshal push message: %w", err)
+		return "", fmt.Errorf("failed to marshal push message: %w", err)
 	}
 
 	// Validate payload size (max 4KB for Web Push)
@@ -407,10 +459,41 @@ func (p *PushPublisher) PublishPushNotification(
 			"type", notificationType,
 			"queue_size", len(p.queue),
 		)
-		return fmt.Errorf("push notification queue full")
+		return "", fmt.Errorf("push notification queue full")
 	}
 
-	return nil
+	return msg.MessageID, nil
+}
+
+// allowedByPreferences reports whether the user's push preferences permit this type right now.
+// Skipped pushes are dropped rather than delayed; the in-app notification is still delivered.
+// Fails open when preferences can't be loaded.
+func (p *PushPublisher) allowedByPreferences(userID uint, notificationType models.NotificationType) bool {
+	if p.pushRepo == nil {
+		return true
+	}
+
+	pref, err := p.pushRepo.GetPreference(userID)
+	if err != nil {
+		logger.Sugar.Warnw("Failed to load push preferences, publishing anyway",
+			"user_id", userID,
+			"error", err,
+		)
+		return true
+	}
+	if pref == nil {
+		return true // Defaults: everything enabled, no quiet hours
+	}
+
+	if !pref.IsTypeEnabled(string(notificationType)) {
+		logger.Sugar.Debugw("Push skipped", "user_id", userID, "type", notificationType, "reason", "disabled_by_preference")
+		return false
+	}
+	if pref.IsInQuietHours(time.Now()) {
+		logger.Sugar.Debugw("Push skipped", "user_id", userID, "type", notificationType, "reason", "quiet_hours")
+		return false
+	}
+	return true
 }
 
 // PublishFromNotification publishes a push notification from a Notification model
@@ -0,0 +1,195 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/pkg/models"
+	"github.com/aman1117/backend/pkg/redis"
+)
+
+// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired, or revoked
+var ErrInvalidRefreshToken = errors.New("invalid refresh token")
+
+// RefreshTokenService issues, rotates and revokes refresh tokens, and tracks each
+// user's token version used to invalidate outstanding access tokens
+type RefreshTokenService struct {
+	refreshRepo *repository.RefreshTokenRepository
+	userRepo    *repository.UserRepository
+	sessionSvc  *SessionService
+	refreshTTL  time.Duration
+}
+
+// NewRefreshTokenService creates a new RefreshTokenService
+func NewRefreshTokenService(refreshRepo *repository.RefreshTokenRepository, userRepo *repository.UserRepository, sessionSvc *SessionService, refreshTTL time.Duration) *RefreshTokenService {
+	return &RefreshTokenService{
+		refreshRepo: refreshRepo,
+		userRepo:    userRepo,
+		sessionSvc:  sessionSvc,
+		refreshTTL:  refreshTTL,
+	}
+}
+
+// Issue creates a new refresh token in the given session and returns the raw token and its expiry
+func (s *RefreshTokenService) Issue(userID, sessionID uint) (string, time.Time, error) {
+	raw, token, err := s.newToken(userID, sessionID)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := s.refreshRepo.Create(token); err != nil {
+		return "", time.Time{}, err
+	}
+	return raw, token.ExpiresAt, nil
+}
+
+// RotateResult is the outcome of a successful refresh
+type RotateResult struct {
+	User         *models.User
+	SessionID    uint
+	RefreshToken string
+	ExpiresAt    time.Time
+}
+
+// Rotate exchanges a refresh token for a new one in the same session and returns its owner.
+// Presenting a token that was already rotated or revoked is treated as theft:
+// every refresh token of the user is revoked and ErrInvalidRefreshToken is returned.
+func (s *RefreshTokenService) Rotate(ctx context.Context, raw, ip string) (*RotateResult, error) {
+	current, err := s.refreshRepo.FindByHash(redis.HashToken(raw))
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+	if !current.IsActive() {
+		// Replaying a rotated or revoked token that has not yet expired signals theft
+		if current.RevokedAt != nil && time.Now().Before(current.ExpiresAt) {
+			s.revokeOnReuse(current.UserID)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	user, err := s.userRepo.FindByID(current.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrInvalidRefreshToken
+	}
+
+	nextRaw, next, err := s.newToken(user.ID, current.SessionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.refreshRepo.Rotate(current.ID, next); err != nil {
+		if errors.Is(err, repository.ErrRefreshTokenUsed) {
+			s.revokeOnReuse(current.UserID)
+			return nil, ErrInvalidRefreshToken
+		}
+		return nil, err
+	}
+
+	s.sessionSvc.Touch(ctx, current.SessionID, ip, true)
+	return &RotateResult{
+		User:         user,
+		SessionID:    current.SessionID,
+		RefreshToken: nextRaw,
+		ExpiresAt:    next.ExpiresAt,
+	}, nil
+}
+
+// Revoke logs out the session the refresh token belongs to, which also rejects the session's
+// outstanding access tokens (see TokenService.Parse); unknown tokens are ignored
+func (s *RefreshTokenService) Revoke(ctx context.Context, raw string) error {
+	token, err := s.refreshRepo.FindByHash(redis.HashToken(raw))
+	if err != nil || token == nil {
+		return err
+	}
+	_, err = s.sessionSvc.Revoke(ctx, token.UserID, token.SessionID)
+	return err
+}
+
+// LogoutEverywhere bumps the user's token version, which invalidates every access token
+// issued so far, and revokes all of their sessions and refresh tokens. The new version is
+// written through to the cache once the bump has committed; TokenVersion only fills cache
+// misses, so a concurrent read of the old version cannot overwrite it.
+func (s *RefreshTokenService) LogoutEverywhere(ctx context.Context, userID uint) error {
+	version, err := s.userRepo.IncrementTokenVersion(userID)
+	if err != nil {
+		return err
+	}
+	if err := redis.SetTokenVersion(ctx, userID, version); err != nil {
+		logger.Sugar.Warnw("Failed to cache new token version", "user_id", userID, "error", err)
+		if err := redis.InvalidateTokenVersion(ctx, userID); err != nil {
+			logger.Sugar.Warnw("Failed to invalidate token version cache", "user_id", userID, "error", err)
+		}
+	}
+	if err := s.sessionSvc.RevokeAll(ctx, userID); err != nil {
+		return err
+	}
+	return s.refreshRepo.RevokeAllForUser(userID)
+}
+
+// TokenVersion returns the user's current token version, served from Redis when cached
+func (s *RefreshTokenService) TokenVersion(ctx context.Context, userID uint) (int, error) {
+	if version, found, err := redis.GetTokenVersion(ctx, userID); err == nil && found {
+		return version, nil
+	}
+
+	version, err := s.userRepo.GetTokenVersion(userID)
+	if err != nil {
+		return 0, err
+	}
+	if err := redis.CacheTokenVersion(ctx, userID, version); err != nil {
+		logger.Sugar.Warnw("Failed to cache token version", "user_id", userID, "error", err)
+	}
+	return version, nil
+}
+
+// CleanupExpired deletes refresh tokens that expired more than the retention period ago,
+// and sessions that were revoked or could no longer be refreshed by then
+func (s *RefreshTokenService) CleanupExpired() (int64, error) {
+	cutoff := time.Now().Add(-constants.RefreshTokenRetention)
+	deleted, err := s.refreshRepo.DeleteExpired(cutoff)
+	if err != nil {
+		return 0, err
+	}
+
+	// A session unused for longer than the refresh TTL has no usable refresh token left
+	sessions, err := s.sessionSvc.DeleteStale(cutoff.Add(-s.refreshTTL))
+	if err != nil {
+		return deleted, err
+	}
+	return deleted + sessions, nil
+}
+
+// newToken generates a random token and the row storing its hash
+func (s *RefreshTokenService) newToken(userID, sessionID uint) (string, *models.RefreshToken, error) {
+	bytes := make([]byte, constants.RefreshTokenByteLen)
+	if _, err := rand.Read(bytes); err != nil {
+		return "", nil, fmt.Errorf("failed to generate random bytes: %w", err)
+	}
+	raw := hex.EncodeToString(bytes)
+
+	return raw, &models.RefreshToken{
+		UserID:    userID,
+		SessionID: sessionID,
+		TokenHash: redis.HashToken(raw),
+		ExpiresAt: time.Now().Add(s.refreshTTL),
+	}, nil
+}
+
+// revokeOnReuse revokes all of a user's refresh tokens after a rotated token was replayed
+func (s *RefreshTokenService) revokeOnReuse(userID uint) {
+	logger.Sugar.Warnw("Refresh token reuse detected, revoking all refresh tokens", "user_id", userID)
+	if err := s.refreshRepo.RevokeAllForUser(userID); err != nil {
+		logger.Sugar.Errorw("Failed to revoke refresh tokens after reuse", "user_id", userID, "error", err)
+	}
+}
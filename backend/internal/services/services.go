@@ -3,28 +3,73 @@ package services
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"strings"
 	"time"
 
+	"github.com/aman1117/backend/internal/config"
 	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/dto"
 	"github.com/aman1117/backend/internal/logger"
 	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/internal/validator"
 	"github.com/aman1117/backend/pkg/models"
+	"github.com/aman1117/backend/pkg/redis"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// AccountLockedError is returned by Authenticate while an account is locked after
+// repeated failed logins
+type AccountLockedError struct {
+	RetryAfter time.Duration
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("account locked, retry after %s", e.RetryAfter.Round(time.Second))
+}
+
 // AuthService handles authentication-related business logic
 type AuthService struct {
 	userRepo *repository.UserRepository
+	photoSvc *ActivityPhotoService // optional; nil when blob storage is not configured
+	emailSvc *EmailService         // optional; nil when email is not configured
+	loginCfg *config.LoginConfig
+	tfaSvc   *TwoFactorService
+	reserved validator.ReservedUsernames
 }
 
 // NewAuthService creates a new AuthService
-func NewAuthService(userRepo *repository.UserRepository) *AuthService {
-	return &AuthService{userRepo: userRepo}
+func NewAuthService(userRepo *repository.UserRepository, photoSvc *ActivityPhotoService, emailSvc *EmailService, loginCfg *config.LoginConfig, tfaSvc *TwoFactorService, usernameCfg *config.UsernameConfig) *AuthService {
+	return &AuthService{
+		userRepo: userRepo,
+		photoSvc: photoSvc,
+		emailSvc: emailSvc,
+		loginCfg: loginCfg,
+		tfaSvc:   tfaSvc,
+		reserved: validator.NewReservedUsernames(usernameCfg.Reserved),
+	}
 }
 
-// Register creates a new user account
+// Register creates a new user account.
+// Returns a *validator.ValidationError if the username or password does not meet policy.
 func (s *AuthService) Register(email, username, password string) error {
+	if verr := validator.ValidateUsernamePolicy(username, s.reserved); verr != nil {
+		return verr
+	}
+	if verr := validator.ValidatePasswordPolicy(password, username, email); verr != nil {
+		return verr
+	}
+
+	reserved, err := s.userRepo.IsUsernameReserved(username)
+	if err != nil {
+		return err
+	}
+	if reserved {
+		return repository.ErrUsernameReserved
+	}
+
 	// Hash the password
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
 	if err != nil {
@@ -34,8 +79,13 @@ func (s *AuthService) Register(email, username, password string) error {
 	return s.userRepo.Create(email, username, string(hash))
 }
 
-// Authenticate validates user credentials and returns the user if valid
-func (s *AuthService) Authenticate(identifier, password string) (*models.User, error) {
+// Authenticate validates user credentials and returns the user if valid.
+// For accounts with two-factor enabled, a correct password without a code returns
+// ErrTwoFactorRequired, and a wrong TOTP or backup code returns ErrInvalidTwoFactorCode.
+// Consecutive wrong passwords or codes lock the account per the login config; while
+// locked, even correct credentials return *AccountLockedError. Lockout is skipped when
+// Redis is unavailable.
+func (s *AuthService) Authenticate(ctx context.Context, identifier, password, twoFactorCode string) (*models.User, error) {
 	user, err := s.userRepo.FindByIdentifier(identifier)
 	if err != nil {
 		return nil, err
@@ -45,13 +95,58 @@ func (s *AuthService) Authenticate(identifier, password string) (*models.User, e
 		return nil, errors.New("user not found")
 	}
 
+	if ttl, err := redis.GetLoginLockTTL(ctx, user.ID); err != nil {
+		logger.Sugar.Warnw("Failed to check login lock", "user_id", user.ID, "error", err)
+	} else if ttl > 0 {
+		return nil, &AccountLockedError{RetryAfter: ttl}
+	}
+
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		if lockErr := s.recordLoginFailure(ctx, user.ID); lockErr != nil {
+			return nil, lockErr
+		}
 		return nil, errors.New("invalid password")
 	}
 
+	if user.TwoFactorEnabled && s.tfaSvc != nil {
+		if twoFactorCode == "" {
+			return nil, ErrTwoFactorRequired
+		}
+		if err := s.tfaSvc.Verify(ctx, user, twoFactorCode); err != nil {
+			if errors.Is(err, ErrInvalidTwoFactorCode) {
+				if lockErr := s.recordLoginFailure(ctx, user.ID); lockErr != nil {
+					return nil, lockErr
+				}
+			}
+			return nil, err
+		}
+	}
+
+	if err := redis.ClearLoginFailures(ctx, user.ID); err != nil {
+		logger.Sugar.Warnw("Failed to clear login failures", "user_id", user.ID, "error", err)
+	}
+
 	return user, nil
 }
 
+// recordLoginFailure counts a failed login and returns *AccountLockedError if it locked the account
+func (s *AuthService) recordLoginFailure(ctx context.Context, userID uint) error {
+	if s.loginCfg == nil {
+		return nil
+	}
+
+	locked, err := redis.RecordLoginFailure(ctx, userID, s.loginCfg.MaxFailedAttempts, s.loginCfg.FailureWindow, s.loginCfg.LockoutDuration)
+	if err != nil {
+		logger.Sugar.Warnw("Failed to record login failure", "user_id", userID, "error", err)
+		return nil
+	}
+	if locked {
+		logger.Sugar.Warnw("Account locked after repeated failed logins", "user_id", userID, "lockout", s.loginCfg.LockoutDuration)
+		return &AccountLockedError{RetryAfter: s.loginCfg.LockoutDuration}
+	}
+	return nil
+}
+
 // GetUserByID retrieves a user by their ID
 func (s *AuthService) GetUserByID(userID uint) (*models.User, error) {
 	return s.userRepo.FindByID(userID)
@@ -67,12 +162,19 @@ func (s *AuthService) GetUserByUsername(username string) (*models.User, error) {
 	return s.userRepo.FindByUsername(username)
 }
 
-// UpdateUsername updates a user's username
+// UpdateUsername changes a user's username, at most once per UsernameChangeCooldown.
+// Returns a *validator.ValidationError for invalid or reserved names,
+// *repository.UsernameCooldownError while on cooldown and
+// repository.ErrUsernameReserved if someone else recently gave the name up.
 func (s *AuthService) UpdateUsername(userID uint, newUsername string) error {
-	return s.userRepo.UpdateUsername(userID, newUsername)
+	if verr := validator.ValidateUsernamePolicy(newUsername, s.reserved); verr != nil {
+		return verr
+	}
+	return s.userRepo.ChangeUsername(userID, newUsername, constants.UsernameChangeCooldown, constants.UsernameReleaseGrace)
 }
 
-// ChangePassword validates the current password and updates to a new one
+// ChangePassword validates the current password and updates to a new one.
+// Returns a *validator.ValidationError if the new password does not meet the password policy.
 func (s *AuthService) ChangePassword(userID uint, currentPassword, newPassword string) error {
 	user, err := s.userRepo.FindByID(userID)
 	if err != nil || user == nil {
@@ -84,6 +186,10 @@ func (s *AuthService) ChangePassword(userID uint, currentPassword, newPassword s
 		return errors.New("current password is incorrect")
 	}
 
+	if verr := validator.ValidatePasswordPolicy(newPassword, user.Username, user.Email); verr != nil {
+		return verr
+	}
+
 	// Hash new password
 	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
@@ -93,8 +199,166 @@ func (s *AuthService) ChangePassword(userID uint, currentPassword, newPassword s
 	return s.userRepo.UpdatePassword(userID, string(hash))
 }
 
-// ResetPassword sets a new password without validating the old one
+// DeleteAccount verifies the password and permanently deletes the user along with
+// everything that references them. Profile picture and story photo blobs are deleted
+// in the background after the database transaction commits.
+func (s *AuthService) DeleteAccount(userID uint, password string) (*dto.AccountDeletionSummary, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return nil, errors.New("invalid password")
+	}
+
+	result, err := s.userRepo.DeleteAccount(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	summary := &dto.AccountDeletionSummary{
+		RowsDeleted:      result.RowsDeleted,
+		CountersAdjusted: result.CountersAdjusted,
+	}
+	if s.photoSvc != nil {
+		s.photoSvc.DeleteBlobsByURL(result.BlobURLs)
+		summary.BlobsScheduled = len(result.BlobURLs)
+	} else if len(result.BlobURLs) > 0 {
+		logger.Sugar.Warnw("Blob storage not configured, skipping blob cleanup for deleted account",
+			"user_id", userID, "blobs", len(result.BlobURLs))
+	}
+
+	return summary, nil
+}
+
+// RequestEmailChange verifies the password and emails a confirmation link to newEmail.
+// The address is not changed until ConfirmEmailChange is called with the token.
+func (s *AuthService) RequestEmailChange(ctx context.Context, userID uint, newEmail, password string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return errors.New("user not found")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return errors.New("invalid password")
+	}
+
+	if strings.EqualFold(user.Email, newEmail) {
+		return errors.New("email unchanged")
+	}
+
+	existing, err := s.userRepo.FindByEmail(newEmail)
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		return repository.ErrEmailTaken
+	}
+
+	if s.emailSvc == nil {
+		return errors.New("email service unavailable")
+	}
+
+	rawToken, tokenHash, err := redis.GenerateVerifyToken()
+	if err != nil {
+		return err
+	}
+	if err := redis.StoreEmailChangeToken(ctx, tokenHash, userID, newEmail); err != nil {
+		return err
+	}
+
+	return s.emailSvc.SendEmailChangeVerification(newEmail, user.Username, rawToken)
+}
+
+// ConfirmEmailChange applies a pending email change and marks the new address verified.
+// The address is re-checked because another account may have registered it since the request.
+func (s *AuthService) ConfirmEmailChange(ctx context.Context, token string) (*models.User, error) {
+	userID, newEmail, err := redis.ConsumeEmailChangeToken(ctx, token)
+	if err != nil {
+		return nil, err
+	}
+	if userID == 0 {
+		return nil, errors.New("invalid or expired token")
+	}
+
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return nil, errors.New("user not found")
+	}
+
+	existing, err := s.userRepo.FindByEmail(newEmail)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.ID != userID {
+		return nil, repository.ErrEmailTaken
+	}
+
+	if err := s.userRepo.UpdateEmail(userID, newEmail); err != nil {
+		return nil, err
+	}
+
+	user.Email = newEmail
+	user.EmailVerified = true
+	return user, nil
+}
+
+// Deactivate verifies the password and hides the account from everyone else.
+// Data is kept and the user can still log in to reactivate.
+func (s *AuthService) Deactivate(userID uint, password string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return errors.New("user not found")
+	}
+
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return errors.New("invalid password")
+	}
+
+	if user.IsDeactivated {
+		return nil
+	}
+	return s.userRepo.UpdateDeactivated(userID, true)
+}
+
+// Reactivate restores a deactivated account's visibility to other users
+func (s *AuthService) Reactivate(userID uint) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil || user == nil {
+		return errors.New("user not found")
+	}
+
+	if !user.IsDeactivated {
+		return nil
+	}
+	return s.userRepo.UpdateDeactivated(userID, false)
+}
+
+// CheckNewPassword checks a new password against the password policy for the user.
+// Returns a *validator.ValidationError if it is rejected.
+func (s *AuthService) CheckNewPassword(userID uint, newPassword string) error {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return err
+	}
+	if user == nil {
+		return errors.New("user not found")
+	}
+
+	if verr := validator.ValidatePasswordPolicy(newPassword, user.Username, user.Email); verr != nil {
+		return verr
+	}
+	return nil
+}
+
+// ResetPassword sets a new password without validating the old one.
+// Returns a *validator.ValidationError if the new password does not meet the password policy.
 func (s *AuthService) ResetPassword(userID uint, newPassword string) error {
+	if err := s.CheckNewPassword(userID, newPassword); err != nil {
+		return err
+	}
+
 	hash, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
 		return err
@@ -134,6 +398,43 @@ func (s *ProfileService) GetPrivacy(userID uint) (bool, error) {
 	return s.userRepo.GetPrivacy(userID)
 }
 
+// UpdateTimezone updates a user's timezone after validating it as an IANA zone name
+func (s *ProfileService) UpdateTimezone(userID uint, timezone string) error {
+	if timezone == "" {
+		return errors.New("invalid timezone")
+	}
+	if _, err := time.LoadLocation(timezone); err != nil {
+		return errors.New("invalid timezone")
+	}
+	return s.userRepo.UpdateTimezone(userID, timezone)
+}
+
+// GetTimezone gets a user's timezone, defaulting to IST
+func (s *ProfileService) GetTimezone(userID uint) (string, error) {
+	timezone, err := s.userRepo.GetTimezone(userID)
+	if err != nil {
+		return "", err
+	}
+	if timezone == "" {
+		timezone = constants.TimezoneIST
+	}
+	return timezone, nil
+}
+
+// SetWeeklyDigest enables or disables the weekly digest email for a user
+func (s *ProfileService) SetWeeklyDigest(userID uint, enabled bool) error {
+	return s.userRepo.UpdateWeeklyDigestOptOut(userID, !enabled)
+}
+
+// IsWeeklyDigestEnabled reports whether a user receives the weekly digest email
+func (s *ProfileService) IsWeeklyDigestEnabled(userID uint) (bool, error) {
+	optOut, err := s.userRepo.GetWeeklyDigestOptOut(userID)
+	if err != nil {
+		return false, err
+	}
+	return !optOut, nil
+}
+
 // UpdateBio updates a user's bio
 func (s *ProfileService) UpdateBio(userID uint, bio string) error {
 	return s.userRepo.UpdateBio(userID, bio)
@@ -149,15 +450,58 @@ func (s *ProfileService) UpdateProfilePic(userID uint, url *string, thumbURL *st
 	return s.userRepo.UpdateProfilePic(userID, url, thumbURL)
 }
 
-// SearchUsers searches for users by username (includes private users)
-func (s *ProfileService) SearchUsers(query string) ([]models.User, error) {
-	return s.userRepo.SearchByUsername(query)
+// SearchUsers searches for users by username (includes private users) and returns one
+// page of results along with whether more pages follow
+func (s *ProfileService) SearchUsers(query string, page, pageSize int) ([]models.User, bool, error) {
+	// Fetch one extra row to detect a further page without a COUNT over the ILIKE scan
+	users, err := s.userRepo.SearchByUsername(query, pageSize+1, (page-1)*pageSize)
+	if err != nil {
+		return nil, false, err
+	}
+	hasMore := len(users) > pageSize
+	if hasMore {
+		users = users[:pageSize]
+	}
+	return users, hasMore, nil
 }
 
 // AutocompleteUsers performs ranked autocomplete search on usernames
-// Returns results sorted by: exact match > prefix match > trigram similarity, then by followers count
-func (s *ProfileService) AutocompleteUsers(query string, limit int) ([]repository.AutocompleteResult, error) {
-	return s.userRepo.AutocompleteUsers(query, limit)
+// Returns results sorted by: exact match > prefix match > trigram similarity, then by followers count.
+// Users blocked in either direction with viewerID are filtered out in the query. Viewers with
+// no blocks see the same results, so theirs are cached per query; anyone with a block is
+// always served from the database, so a new block applies immediately.
+func (s *ProfileService) AutocompleteUsers(ctx context.Context, viewerID uint, query string, limit int) ([]repository.AutocompleteResult, error) {
+	hasBlocks, err := s.followRepo.HasAnyBlock(viewerID)
+	if err != nil {
+		return nil, err
+	}
+	if hasBlocks {
+		return s.userRepo.AutocompleteUsers(viewerID, query, limit)
+	}
+
+	if cached, err := redis.GetAutocompleteCache(ctx, query); err == nil && cached != "" {
+		var results []repository.AutocompleteResult
+		if err := json.Unmarshal([]byte(cached), &results); err == nil {
+			return trimAutocomplete(results, limit), nil
+		}
+	}
+
+	// Cache the largest page any request can ask for, so every limit is served from one entry
+	results, err := s.userRepo.AutocompleteUsers(viewerID, query, constants.AutocompleteMaxLimit)
+	if err != nil {
+		return nil, err
+	}
+	if data, err := json.Marshal(results); err == nil {
+		_ = redis.SetAutocompleteCache(ctx, query, string(data))
+	}
+	return trimAutocomplete(results, limit), nil
+}
+
+func trimAutocomplete(results []repository.AutocompleteResult, limit int) []repository.AutocompleteResult {
+	if len(results) > limit {
+		return results[:limit]
+	}
+	return results
 }
 
 // CanViewProfile checks if the current user can view another user's profile
@@ -169,6 +513,10 @@ func (s *ProfileService) CanViewProfile(targetUser *models.User, currentUserID u
 	if targetUser.ID == currentUserID {
 		return true
 	}
+	// Deactivated accounts are hidden from everyone else
+	if targetUser.IsDeactivated {
+		return false
+	}
 	// Public profiles are viewable by everyone
 	if !targetUser.IsPrivate {
 		return true
@@ -192,6 +540,8 @@ type ActivityService struct {
 	userRepo     *repository.UserRepository
 	followRepo   *repository.FollowRepository
 	notifSvc     *NotificationService
+	badgeSvc     *BadgeService
+	typeRepo     *repository.CustomActivityTypeRepository
 }
 
 // NewActivityService creates a new ActivityService
@@ -201,6 +551,8 @@ func NewActivityService(
 	userRepo *repository.UserRepository,
 	followRepo *repository.FollowRepository,
 	notifSvc *NotificationService,
+	badgeSvc *BadgeService,
+	typeRepo *repository.CustomActivityTypeRepository,
 ) *ActivityService {
 	return &ActivityService{
 		activityRepo: activityRepo,
@@ -208,64 +560,75 @@ func NewActivityService(
 		userRepo:     userRepo,
 		followRepo:   followRepo,
 		notifSvc:     notifSvc,
+		badgeSvc:     badgeSvc,
+		typeRepo:     typeRepo,
 	}
 }
 
-// CreateOrUpdateActivity creates or updates an activity for a user
-func (s *ActivityService) CreateOrUpdateActivity(userID uint, name models.ActivityName, hours float32, date time.Time, note *string) error {
-	// Get all activities for this day
-	dayActivities, err := s.activityRepo.FindByUserAndDate(userID, date)
-	if err != nil {
-		return err
+// CreateOrUpdateActivity creates or updates an activity for a user.
+// Returns the day's updated streak along with any badges it unlocked.
+func (s *ActivityService) CreateOrUpdateActivity(ctx context.Context, userID uint, name models.ActivityName, hours float32, date time.Time, note *string) (*dto.StreakDTO, error) {
+	if err := s.saveActivity(userID, name, hours, date, note); err != nil {
+		return nil, err
 	}
+	return s.streakWithNewBadges(ctx, userID, date), nil
+}
 
-	// Calculate total hours and find existing activity
-	var totalHours float32
-	var existing *models.Activity
-
-	for i := range dayActivities {
-		a := &dayActivities[i]
-		totalHours += a.DurationHours
-		if a.Name == name {
-			existing = a
+// saveActivity writes the activity and brings the user's streaks up to date
+func (s *ActivityService) saveActivity(userID uint, name models.ActivityName, hours float32, date time.Time, note *string) error {
+	if name.IsCustomTile() {
+		if err := s.validateCustomActivity(userID, name); err != nil {
+			return err
 		}
 	}
 
-	// Store previous total for completion detection
-	previousTotal := totalHours
-
-	// Calculate new total
-	var newTotal float32
-	if existing != nil {
-		newTotal = totalHours - existing.DurationHours + hours
-	} else {
-		newTotal = totalHours + hours
+	// The daily cap is checked and the write applied in one locked transaction,
+	// so concurrent requests cannot both pass the check
+	previousTotal, newTotal, err := s.activityRepo.UpsertForDay(userID, date, name, hours, note, constants.MaxDailyHours)
+	if err != nil {
+		return err
 	}
 
-	if newTotal > 24 {
-		return errors.New("total hours cannot be more than 24")
+	var logged []models.ActivityName
+	if hours > 0 {
+		logged = append(logged, name)
 	}
+	return s.afterDayUpdated(userID, date, previousTotal, newTotal, logged)
+}
 
-	// Update or create activity
-	if existing != nil {
-		existing.DurationHours = hours
-		existing.Note = note
-		if err := s.activityRepo.Update(existing); err != nil {
-			return err
-		}
-	} else {
-		activity := &models.Activity{
-			UserID:        userID,
-			Name:          name,
-			DurationHours: hours,
-			ActivityDate:  date,
-			Note:          note,
+// LogActivitiesForDay creates or updates several activities on one date in a single
+// transaction, checking the 24-hour cap against the combined total once.
+// Streaks are brought up to date once for the whole batch.
+// Returns the saved activities and the day's streak along with any badges it unlocked.
+func (s *ActivityService) LogActivitiesForDay(ctx context.Context, userID uint, date time.Time, entries []repository.ActivityEntry) ([]models.Activity, *dto.StreakDTO, error) {
+	for _, e := range entries {
+		if e.Name.IsCustomTile() {
+			if err := s.validateCustomActivity(userID, e.Name); err != nil {
+				return nil, nil, err
+			}
 		}
-		if err := s.activityRepo.Create(activity); err != nil {
-			return err
+	}
+
+	previousTotal, newTotal, saved, err := s.activityRepo.UpsertManyForDay(userID, date, entries, constants.MaxDailyHours)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var logged []models.ActivityName
+	for _, e := range entries {
+		if e.Hours > 0 {
+			logged = append(logged, e.Name)
 		}
 	}
+	if err := s.afterDayUpdated(userID, date, previousTotal, newTotal, logged); err != nil {
+		return nil, nil, err
+	}
+	return saved, s.streakWithNewBadges(ctx, userID, date), nil
+}
 
+// afterDayUpdated notifies followers if the day was just completed and brings the
+// overall streak, plus the per-activity streaks of logged, up to date
+func (s *ActivityService) afterDayUpdated(userID uint, date time.Time, previousTotal, newTotal float32, logged []models.ActivityName) error {
 	logger.Sugar.Debugw("Activity hours calculation",
 		"user_id", userID,
 		"previous_total", previousTotal,
@@ -273,14 +636,113 @@ func (s *ActivityService) CreateOrUpdateActivity(userID uint, name models.Activi
 		"threshold_check", previousTotal < 24 && newTotal >= 24,
 	)
 
+	s.streakSvc.invalidateLastLogged(userID)
+
 	// Check if user just completed 24 hours (crossed the threshold)
 	// Only triggers if: previousTotal < 24 AND newTotal >= 24
 	if previousTotal < 24 && newTotal >= 24 {
 		s.notifyFollowersOfDayCompletion(userID, date)
 	}
+	// Lowering hours can take a completed day back under 24
+	if previousTotal >= 24 && newTotal < 24 {
+		s.retractDayCompletion(userID, date)
+	}
+
+	// Backfilling a past day can join or extend every streak after it
+	if s.streakSvc.isBackfill(userID, date) {
+		return s.streakSvc.RecomputeStreaks(userID)
+	}
 
 	// Update streak
-	return s.streakSvc.AddStreak(userID, date, false)
+	if err := s.streakSvc.AddStreak(userID, date, false, nil); err != nil {
+		return err
+	}
+	for i := range logged {
+		if err := s.streakSvc.AddStreak(userID, date, false, &logged[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteActivity removes one of the user's activities for a date. Followers' day-completed
+// notifications are withdrawn if the day drops under 24 hours, and if no activities remain
+// the day stops counting towards the user's streak.
+func (s *ActivityService) DeleteActivity(userID uint, name models.ActivityName, date time.Time) error {
+	previousTotal, newTotal, remaining, err := s.activityRepo.DeleteForDay(userID, date, name)
+	if err != nil {
+		return err
+	}
+
+	logger.Sugar.Debugw("Activity deleted",
+		"user_id", userID,
+		"activity", name,
+		"date", date.Format(constants.DateFormat),
+		"previous_total", previousTotal,
+		"new_total", newTotal,
+		"remaining", remaining,
+	)
+
+	s.streakSvc.invalidateLastLogged(userID)
+
+	if previousTotal >= 24 && newTotal < 24 {
+		s.retractDayCompletion(userID, date)
+	}
+
+	if err := s.streakSvc.RemoveActivityDay(userID, name, date); err != nil {
+		return err
+	}
+	if remaining == 0 {
+		return s.streakSvc.RecomputeStreaks(userID)
+	}
+	return nil
+}
+
+// validateCustomActivity checks that a custom activity (custom:<uuid>) is one of the
+// custom activity types the user has defined. Activities logged before a type was
+// removed are kept; only new writes are checked.
+func (s *ActivityService) validateCustomActivity(userID uint, name models.ActivityName) error {
+	exists, err := s.typeRepo.Exists(userID, name.GetCustomTileID())
+	if err != nil {
+		return err
+	}
+	if !exists {
+		return errors.New("unknown custom activity")
+	}
+	return nil
+}
+
+// streakWithNewBadges returns the streak for date and awards any badges the user now
+// qualifies for. The activity is already saved, so lookup or award failures only
+// leave the streak or badges out of the response.
+func (s *ActivityService) streakWithNewBadges(ctx context.Context, userID uint, date time.Time) *dto.StreakDTO {
+	streak, err := s.streakSvc.GetStreak(userID, date)
+	if err != nil || streak == nil {
+		return nil
+	}
+	result := &dto.StreakDTO{
+		ID:      streak.ID,
+		Current: streak.Current,
+		Longest: streak.Longest,
+		Date:    streak.ActivityDate.Format(constants.DateFormat),
+	}
+
+	if s.badgeSvc == nil {
+		return result
+	}
+
+	// Longest is carried forward, so a backfilled day's row may trail the latest one
+	longest := streak.Longest
+	if latest, err := s.streakSvc.GetLatestStreak(userID); err == nil && latest != nil && latest.Longest > longest {
+		longest = latest.Longest
+	}
+	newBadges, err := s.badgeSvc.CheckAndAwardBadges(ctx, userID, longest)
+	if err != nil {
+		logger.Sugar.Warnw("Failed to award badges after activity update", "user_id", userID, "error", err)
+		return result
+	}
+	result.NewBadges = newBadges
+	return result
 }
 
 // notifyFollowersOfDayCompletion sends notifications to all followers when a user completes 24 hours.
@@ -349,8 +811,7 @@ func (s *ActivityService) notifyFollowersOfDayCompletion(userID uint, date time.
 		)
 
 		// Format date for notification
-		loc, _ := time.LoadLocation(constants.TimezoneIST)
-		dateStr := date.In(loc).Format(constants.DateFormat)
+		dateStr := dayCompletionDate(date)
 
 		// Get avatar URL (may be nil)
 		avatar := ""
@@ -376,11 +837,57 @@ func (s *ActivityService) notifyFollowersOfDayCompletion(userID uint, date time.
 	}()
 }
 
+// retractDayCompletion withdraws the day-completed notifications sent to followers for
+// date. Runs asynchronously like notifyFollowersOfDayCompletion.
+func (s *ActivityService) retractDayCompletion(userID uint, date time.Time) {
+	if s.notifSvc == nil {
+		return
+	}
+
+	dateStr := dayCompletionDate(date)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				logger.Sugar.Errorw("Panic in day completion retraction goroutine",
+					"user_id", userID,
+					"panic", r,
+				)
+			}
+		}()
+
+		if err := s.notifSvc.RetractDayCompleted(context.Background(), userID, dateStr); err != nil {
+			logger.Sugar.Warnw("Failed to retract day completion notifications",
+				"user_id", userID,
+				"date", dateStr,
+				"error", err,
+			)
+		}
+	}()
+}
+
+// dayCompletionDate formats date the way day-completed notifications record it
+func dayCompletionDate(date time.Time) string {
+	loc, _ := time.LoadLocation(constants.TimezoneIST)
+	return date.In(loc).Format(constants.DateFormat)
+}
+
 // GetActivities retrieves activities for a user within a date range
 func (s *ActivityService) GetActivities(userID uint, startDate, endDate time.Time) ([]models.Activity, error) {
 	return s.activityRepo.FindByUserAndDateRange(userID, startDate, endDate)
 }
 
+// StreamActivities calls fn for each of a user's activities in date order (nil bounds are open-ended).
+// Rows are read from a cursor so exports of large histories stay within constant memory.
+func (s *ActivityService) StreamActivities(userID uint, startDate, endDate *time.Time, fn func(models.Activity) error) error {
+	return s.activityRepo.StreamByUser(userID, startDate, endDate, fn)
+}
+
+// SearchNotes returns a page of the user's own activities whose note matches query
+func (s *ActivityService) SearchNotes(userID uint, query string, page, pageSize int) ([]models.Activity, int64, error) {
+	offset := (page - 1) * pageSize
+	return s.activityRepo.SearchNotes(userID, query, pageSize, offset)
+}
+
 // GetDailyTotals retrieves total hours per day for a user within a date range
 // Returns a map of date string (YYYY-MM-DD) to total hours
 func (s *ActivityService) GetDailyTotals(userID uint, startDate, endDate time.Time) (map[string]float32, error) {
@@ -403,24 +910,84 @@ func (s *ActivityService) GetDailyTotals(userID uint, startDate, endDate time.Ti
 
 // StreakService handles streak-related business logic
 type StreakService struct {
-	streakRepo *repository.StreakRepository
+	streakRepo   *repository.StreakRepository
+	activityRepo *repository.ActivityRepository
+	userRepo     *repository.UserRepository
 }
 
 // NewStreakService creates a new StreakService
-func NewStreakService(streakRepo *repository.StreakRepository) *StreakService {
-	return &StreakService{streakRepo: streakRepo}
+func NewStreakService(streakRepo *repository.StreakRepository, activityRepo *repository.ActivityRepository, userRepo *repository.UserRepository) *StreakService {
+	return &StreakService{streakRepo: streakRepo, activityRepo: activityRepo, userRepo: userRepo}
+}
+
+// userLocation loads an IANA timezone, falling back to IST for empty or unknown zones
+func userLocation(timezone string) *time.Location {
+	if timezone == "" || timezone == constants.TimezoneIST {
+		return istLocation
+	}
+	loc, err := time.LoadLocation(timezone)
+	if err != nil {
+		return istLocation
+	}
+	return loc
+}
+
+// localToday returns the start of the current calendar date in loc
+func localToday(loc *time.Location) time.Time {
+	return localDate(time.Now(), loc)
 }
 
-// AddStreak updates or creates a streak record
-func (s *StreakService) AddStreak(userID uint, date time.Time, isCron bool) error {
-	now := time.Now().In(date.Location())
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, date.Location())
+// localDate returns the start of t's calendar date in loc
+func localDate(t time.Time, loc *time.Location) time.Time {
+	t = t.In(loc)
+	return startOfDay(t.Year(), t.Month(), t.Day(), loc)
+}
+
+// addLocalDays moves a start-of-day time by days calendar days in its own location.
+// Unlike AddDate it never lands on the previous evening when DST skips midnight.
+func addLocalDays(day time.Time, days int) time.Time {
+	return startOfDay(day.Year(), day.Month(), day.Day()+days, day.Location())
+}
 
-	// Only process today's date for regular updates
-	if date.Before(today) && !isCron {
+// startOfDay returns the first instant of the given calendar date in loc. That is
+// midnight, except in zones where a DST change skips midnight (e.g. America/Santiago):
+// time.Date then resolves to the previous evening, and the day really starts at the
+// transition, one offset change later.
+func startOfDay(year int, month time.Month, day int, loc *time.Location) time.Time {
+	noon := time.Date(year, month, day, 12, 0, 0, 0, loc) // normalizes out-of-range days
+	start := time.Date(noon.Year(), noon.Month(), noon.Day(), 0, 0, 0, 0, loc)
+	if start.Day() != noon.Day() {
+		_, before := start.Zone()
+		_, after := noon.Zone()
+		start = start.Add(time.Duration(after-before) * time.Second)
+	}
+	return start
+}
+
+// userToday returns midnight of the current calendar date in the user's timezone
+func (s *StreakService) userToday(userID uint) time.Time {
+	timezone := ""
+	if s.userRepo != nil {
+		timezone, _ = s.userRepo.GetTimezone(userID)
+	}
+	return localToday(userLocation(timezone))
+}
+
+// AddStreak updates or creates a streak record.
+// A nil activity updates the aggregate streak; otherwise the streak for that activity type.
+func (s *StreakService) AddStreak(userID uint, date time.Time, isCron bool, activity *models.ActivityName) error {
+	// Only process today's date (in the user's timezone) for regular updates
+	if !isCron && date.Format(constants.DateFormat) < s.userToday(userID).Format(constants.DateFormat) {
 		return nil
 	}
 
+	if activity != nil {
+		if isCron {
+			return nil // Per-activity streaks only record active days
+		}
+		return s.addActivityStreak(userID, *activity, date)
+	}
+
 	// Get the latest streak
 	streak, err := s.streakRepo.FindLatestByUser(userID)
 	if err != nil {
@@ -471,13 +1038,20 @@ func (s *StreakService) AddStreak(userID uint, date time.Time, isCron bool) erro
 		if streakToUpdate.Current != 0 {
 			return nil // Already updated
 		}
+		if previousStreak.Current == 0 {
+			current = s.tryFreezeMissedDay(userID, previousStreak, current)
+		}
 		streakToUpdate.Current = current + 1
 		if streak != nil && streak.Longest > streakToUpdate.Current {
 			streakToUpdate.Longest = streak.Longest
 		} else {
 			streakToUpdate.Longest = streakToUpdate.Current
 		}
-		return s.streakRepo.Update(streakToUpdate)
+		if err := s.streakRepo.Update(streakToUpdate); err != nil {
+			return err
+		}
+		s.maybeEarnFreeze(userID, streakToUpdate.Current)
+		return nil
 	}
 
 	// No previous streak - check if there's a streak for today
@@ -496,6 +1070,319 @@ func (s *StreakService) AddStreak(userID uint, date time.Time, isCron bool) erro
 	return s.streakRepo.Create(newStreak)
 }
 
+// addActivityStreak records today's entry for a per-activity streak.
+// Unlike the aggregate streak there are no daily cron rows: a streak continues
+// only if the latest record for the activity is the previous day.
+func (s *StreakService) addActivityStreak(userID uint, name models.ActivityName, date time.Time) error {
+	latest, err := s.streakRepo.FindLatestByUserAndActivity(userID, name)
+	if err != nil {
+		return err
+	}
+
+	dateKey := date.Format(constants.DateFormat)
+	current, longest := 1, 0
+	if latest != nil {
+		latestKey := latest.ActivityDate.Format(constants.DateFormat)
+		if latestKey == dateKey {
+			return nil // Already recorded today
+		}
+		if latestKey == date.AddDate(0, 0, -1).Format(constants.DateFormat) {
+			current = latest.Current + 1
+		}
+		longest = latest.Longest
+	}
+	if current > longest {
+		longest = current
+	}
+
+	activityName := name
+	return s.streakRepo.Create(&models.Streak{
+		UserID:       userID,
+		ActivityName: &activityName,
+		Current:      current,
+		Longest:      longest,
+		ActivityDate: date,
+	})
+}
+
+// GetStreakSeries returns one point per day between start and end (inclusive) for charting.
+// Days with no streak record are filled as current=0, carrying longest forward.
+func (s *StreakService) GetStreakSeries(userID uint, start, end time.Time) ([]dto.StreakSeriesPoint, error) {
+	if end.Before(start) {
+		return nil, errors.New("end date must not be before start date")
+	}
+	if end.Sub(start) >= constants.StreakSeriesMaxDays*24*time.Hour {
+		return nil, fmt.Errorf("date range cannot exceed %d days", constants.StreakSeriesMaxDays)
+	}
+
+	streaks, err := s.streakRepo.FindByUserAndDateRange(userID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	byDate := make(map[string]models.Streak, len(streaks))
+	for _, streak := range streaks {
+		byDate[streak.ActivityDate.Format(constants.DateFormat)] = streak
+	}
+
+	// Seed longest from history so the series doesn't start at zero mid-streak
+	longest := 0
+	if prior, err := s.streakRepo.FindLatestBeforeDate(userID, start); err == nil && prior != nil {
+		longest = prior.Longest
+	}
+
+	series := make([]dto.StreakSeriesPoint, 0, int(end.Sub(start).Hours()/24)+1)
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format(constants.DateFormat)
+		point := dto.StreakSeriesPoint{Date: key, Longest: longest}
+		if streak, ok := byDate[key]; ok {
+			point.Current = streak.Current
+			if streak.Longest > longest {
+				longest = streak.Longest
+			}
+			point.Longest = longest
+		}
+		series = append(series, point)
+	}
+	return series, nil
+}
+
+// GetFollowingLeaderboard returns the viewer's followees ranked by current streak.
+// Followees are visible regardless of privacy, since an active follow grants access.
+func (s *StreakService) GetFollowingLeaderboard(viewerID uint, limit int) ([]dto.StreakLeaderboardEntryDTO, error) {
+	if limit <= 0 {
+		limit = constants.StreakLeaderboardDefaultLimit
+	}
+	if limit > constants.StreakLeaderboardMaxLimit {
+		limit = constants.StreakLeaderboardMaxLimit
+	}
+
+	yesterday := addLocalDays(s.userToday(viewerID), -1)
+	rows, err := s.streakRepo.GetFollowingLeaderboard(viewerID, yesterday, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]dto.StreakLeaderboardEntryDTO, 0, len(rows))
+	for i, row := range rows {
+		entries = append(entries, dto.StreakLeaderboardEntryDTO{
+			Rank:          i + 1,
+			UserID:        row.UserID,
+			Username:      row.Username,
+			ProfilePic:    row.ProfilePic,
+			IsVerified:    row.IsVerified,
+			CurrentStreak: row.CurrentStreak,
+		})
+	}
+	return entries, nil
+}
+
+// GetActivityStreaks returns current and longest streaks keyed by activity type.
+// A current streak counts as broken once its latest record is older than yesterday in the user's timezone.
+func (s *StreakService) GetActivityStreaks(userID uint) (map[models.ActivityName]dto.StreakInfo, error) {
+	streaks, err := s.streakRepo.FindLatestPerActivity(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	today := s.userToday(userID)
+	todayKey := today.Format(constants.DateFormat)
+	yesterdayKey := addLocalDays(today, -1).Format(constants.DateFormat)
+
+	result := make(map[models.ActivityName]dto.StreakInfo, len(streaks))
+	for _, streak := range streaks {
+		if streak.ActivityName == nil {
+			continue
+		}
+		info := dto.StreakInfo{Longest: streak.Longest}
+		if key := streak.ActivityDate.Format(constants.DateFormat); key == todayKey || key == yesterdayKey {
+			info.Current = streak.Current
+		}
+		result[*streak.ActivityName] = info
+	}
+	return result, nil
+}
+
+// isBackfill reports whether date is earlier than the user's latest streak record
+func (s *StreakService) isBackfill(userID uint, date time.Time) bool {
+	latest, err := s.streakRepo.FindLatestByUser(userID)
+	if err != nil || latest == nil {
+		return false
+	}
+	return date.Format(constants.DateFormat) < latest.ActivityDate.Format(constants.DateFormat)
+}
+
+// RecomputeStreaks rebuilds current and longest for every streak record of a user
+// from their logged activity, walking the timeline oldest first. Used after a past
+// day is backfilled, which can bridge two previously separate streaks into one run.
+// Frozen days keep the running streak intact; days with no record break it.
+func (s *StreakService) RecomputeStreaks(userID uint) error {
+	streaks, err := s.streakRepo.FindAllByUser(userID)
+	if err != nil {
+		return err
+	}
+	activeDates, err := s.activityRepo.FindActiveDates(userID)
+	if err != nil {
+		return err
+	}
+	if len(streaks) == 0 && len(activeDates) == 0 {
+		return nil
+	}
+
+	active := make(map[string]bool, len(activeDates))
+	for _, d := range activeDates {
+		active[d.Format(constants.DateFormat)] = true
+	}
+	byDate := make(map[string]*models.Streak, len(streaks))
+	for i := range streaks {
+		byDate[streaks[i].ActivityDate.Format(constants.DateFormat)] = &streaks[i]
+	}
+
+	// Timeline spans from the earliest record or activity to the latest of either
+	var start, end time.Time
+	if len(streaks) > 0 {
+		start, end = streaks[0].ActivityDate, streaks[len(streaks)-1].ActivityDate
+	}
+	if len(activeDates) > 0 {
+		if start.IsZero() || activeDates[0].Before(start) {
+			start = activeDates[0]
+		}
+		if last := activeDates[len(activeDates)-1]; end.IsZero() || last.After(end) {
+			end = last
+		}
+	}
+	start = time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, time.UTC)
+	end = time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, time.UTC)
+
+	var changed []*models.Streak
+	current, longest := 0, 0
+	for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format(constants.DateFormat)
+		row := byDate[key]
+		if row == nil {
+			if !active[key] {
+				current = 0
+				continue
+			}
+			row = &models.Streak{UserID: userID, ActivityDate: day}
+		}
+
+		rowCurrent := 0
+		switch {
+		case active[key]:
+			current++
+			rowCurrent = current
+		case row.Frozen:
+			// Forgiven day: the run carries over to the next day
+		default:
+			current = 0
+		}
+		if rowCurrent > longest {
+			longest = rowCurrent
+		}
+
+		if row.ID == 0 || row.Current != rowCurrent || row.Longest != longest {
+			row.Current = rowCurrent
+			row.Longest = longest
+			changed = append(changed, row)
+		}
+	}
+
+	if err := s.streakRepo.SaveAll(changed); err != nil {
+		return err
+	}
+
+	logger.Sugar.Infow("Streaks recomputed",
+		"user_id", userID,
+		"rows_updated", len(changed),
+		"longest", longest,
+	)
+	return nil
+}
+
+// RemoveActivityDay drops the per-activity streak record for date after the activity is
+// deleted. Only the latest record for the activity is removed; records on later days have
+// already counted this one and are left as they are.
+func (s *StreakService) RemoveActivityDay(userID uint, name models.ActivityName, date time.Time) error {
+	latest, err := s.streakRepo.FindLatestByUserAndActivity(userID, name)
+	if err != nil || latest == nil {
+		return err
+	}
+	if latest.ActivityDate.Format(constants.DateFormat) != date.Format(constants.DateFormat) {
+		return nil
+	}
+	return s.streakRepo.Delete(latest)
+}
+
+// RemoveActivity brings streaks up to date after every activity called name was deleted:
+// the activity's own streak records are dropped and the overall streak is recomputed,
+// since days that only had this activity no longer count
+func (s *StreakService) RemoveActivity(userID uint, name models.ActivityName) error {
+	if err := s.streakRepo.DeleteByActivity(userID, name); err != nil {
+		return err
+	}
+	s.invalidateLastLogged(userID)
+	return s.RecomputeStreaks(userID)
+}
+
+// tryFreezeMissedDay consumes a streak freeze when exactly one day was missed,
+// returning the streak count to continue from. Falls back to current on any miss.
+func (s *StreakService) tryFreezeMissedDay(userID uint, missed *models.Streak, current int) int {
+	if missed.Frozen {
+		return current
+	}
+
+	beforeGap, err := s.streakRepo.FindByUserAndDate(userID, missed.ActivityDate.AddDate(0, 0, -1))
+	if err != nil || beforeGap == nil || beforeGap.Current == 0 {
+		return current // Gap is longer than one day, or nothing to protect
+	}
+
+	consumed, err := s.streakRepo.ConsumeFreeze(userID, missed.ActivityDate)
+	if err != nil {
+		logger.Sugar.Warnw("Failed to consume streak freeze", "user_id", userID, "error", err)
+		return current
+	}
+	if !consumed {
+		return current
+	}
+
+	logger.Sugar.Infow("Streak freeze consumed",
+		"user_id", userID,
+		"missed_date", missed.ActivityDate.Format(constants.DateFormat),
+		"streak", beforeGap.Current,
+	)
+	return beforeGap.Current
+}
+
+// maybeEarnFreeze grants a freeze each time the streak reaches a multiple of StreakFreezeEarnInterval
+func (s *StreakService) maybeEarnFreeze(userID uint, current int) {
+	if current <= 0 || current%constants.StreakFreezeEarnInterval != 0 {
+		return
+	}
+	if err := s.streakRepo.GrantFreezes(userID, 1, constants.StreakFreezeMaxBalance); err != nil {
+		logger.Sugar.Warnw("Failed to grant streak freeze", "user_id", userID, "error", err)
+	}
+}
+
+// GrantFreezes adds freezes to a user's balance (capped at StreakFreezeMaxBalance)
+func (s *StreakService) GrantFreezes(userID uint, count int) error {
+	if count <= 0 {
+		return nil
+	}
+	return s.streakRepo.GrantFreezes(userID, count, constants.StreakFreezeMaxBalance)
+}
+
+// GetFreezes returns a user's streak freeze balance; users with no grants have zero available
+func (s *StreakService) GetFreezes(userID uint) (*models.StreakFreeze, error) {
+	freeze, err := s.streakRepo.GetFreeze(userID)
+	if err != nil {
+		return nil, err
+	}
+	if freeze == nil {
+		return &models.StreakFreeze{UserID: userID}, nil
+	}
+	return freeze, nil
+}
+
 // GetStreak retrieves streak data for a user on a specific date
 func (s *StreakService) GetStreak(userID uint, date time.Time) (*models.Streak, error) {
 	return s.streakRepo.FindByUserAndDate(userID, date)
@@ -511,6 +1398,41 @@ func (s *StreakService) GetLatestActiveStreak(userID uint) (*models.Streak, erro
 	return s.streakRepo.FindLatestActiveByUser(userID)
 }
 
+// GetLastLoggedAt returns the most recent date the user logged any hours, or nil if
+// they never have. The result is cached in Redis and invalidated on every activity write.
+func (s *StreakService) GetLastLoggedAt(ctx context.Context, userID uint) (*time.Time, error) {
+	if cached, err := redis.GetLastLoggedCache(ctx, userID); err != nil {
+		logger.Sugar.Warnw("Last logged cache read failed", "user_id", userID, "error", err)
+	} else if cached == constants.LastLoggedNone {
+		return nil, nil
+	} else if cached != "" {
+		if date, err := time.Parse(constants.DateFormat, cached); err == nil {
+			return &date, nil
+		}
+	}
+
+	date, err := s.activityRepo.FindLastLoggedDate(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	value := constants.LastLoggedNone
+	if date != nil {
+		value = date.Format(constants.DateFormat)
+	}
+	if err := redis.SetLastLoggedCache(ctx, userID, value); err != nil {
+		logger.Sugar.Warnw("Last logged cache write failed", "user_id", userID, "error", err)
+	}
+	return date, nil
+}
+
+// invalidateLastLogged drops the cached last logged date after the user's activities change
+func (s *StreakService) invalidateLastLogged(userID uint) {
+	if err := redis.InvalidateLastLoggedCache(context.Background(), userID); err != nil {
+		logger.Sugar.Warnw("Last logged cache invalidation failed", "user_id", userID, "error", err)
+	}
+}
+
 // GetAllStreaks retrieves all streaks for a user
 func (s *StreakService) GetAllStreaks(userID uint) ([]models.Streak, error) {
 	return s.streakRepo.FindAllByUser(userID)
@@ -520,17 +1442,30 @@ func (s *StreakService) GetAllStreaks(userID uint) ([]models.Streak, error) {
 
 // TileConfigService handles tile configuration business logic
 type TileConfigService struct {
-	tileRepo  *repository.TileConfigRepository
-	userRepo  *repository.UserRepository
-	photoRepo *repository.ActivityPhotoRepository
+	tileRepo     *repository.TileConfigRepository
+	userRepo     *repository.UserRepository
+	photoRepo    *repository.ActivityPhotoRepository
+	activityRepo *repository.ActivityRepository
+	streakSvc    *StreakService
+	photoSvc     *ActivityPhotoService // Optional: nil when blob storage is not configured
 }
 
 // NewTileConfigService creates a new TileConfigService
-func NewTileConfigService(tileRepo *repository.TileConfigRepository, userRepo *repository.UserRepository, photoRepo *repository.ActivityPhotoRepository) *TileConfigService {
+func NewTileConfigService(
+	tileRepo *repository.TileConfigRepository,
+	userRepo *repository.UserRepository,
+	photoRepo *repository.ActivityPhotoRepository,
+	activityRepo *repository.ActivityRepository,
+	streakSvc *StreakService,
+	photoSvc *ActivityPhotoService,
+) *TileConfigService {
 	return &TileConfigService{
-		tileRepo:  tileRepo,
-		userRepo:  userRepo,
-		photoRepo: photoRepo,
+		tileRepo:     tileRepo,
+		userRepo:     userRepo,
+		photoRepo:    photoRepo,
+		activityRepo: activityRepo,
+		streakSvc:    streakSvc,
+		photoSvc:     photoSvc,
 	}
 }
 
@@ -548,6 +1483,28 @@ func (s *TileConfigService) GetConfigByUsername(username string) (*models.TileCo
 	return s.tileRepo.FindByUserID(user.ID)
 }
 
+// GetOrCreateDefault returns the user's tile config, falling back to the default layout
+// when they have never saved one. With persist set, the default is stored so later
+// reads find a real row.
+func (s *TileConfigService) GetOrCreateDefault(userID uint, persist bool) (*models.TileConfig, error) {
+	existing, err := s.tileRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return existing, nil
+	}
+
+	tc := &models.TileConfig{UserID: userID}
+	if err := tc.SetConfigData(models.DefaultTileConfigData()); err != nil {
+		return nil, err
+	}
+	if !persist {
+		return tc, nil
+	}
+	return s.tileRepo.CreateIfAbsent(userID, tc.Config)
+}
+
 // TileConfigValidationError represents a validation error with a specific code
 type TileConfigValidationError struct {
 	Message string
@@ -558,8 +1515,23 @@ func (e *TileConfigValidationError) Error() string {
 	return e.Message
 }
 
+// invalidTileConfig builds an INVALID_TILE_CONFIG validation error
+func invalidTileConfig(format string, args ...interface{}) *TileConfigValidationError {
+	return &TileConfigValidationError{
+		Message: fmt.Sprintf(format, args...),
+		Code:    constants.ErrCodeInvalidTileConfig,
+	}
+}
+
 // ValidateConfig validates the tile configuration
 func (s *TileConfigService) ValidateConfig(config models.JSONB) *TileConfigValidationError {
+	// Reject unknown top-level keys so the stored shape stays predictable
+	for key := range config {
+		if !models.TileConfigKeys[key] {
+			return invalidTileConfig("Unknown tile config key: %s", key)
+		}
+	}
+
 	// Parse config to structured data
 	tc := &models.TileConfig{Config: config}
 	configData, err := tc.GetConfigData()
@@ -570,6 +1542,41 @@ func (s *TileConfigService) ValidateConfig(config models.JSONB) *TileConfigValid
 		}
 	}
 
+	// Order must list valid tile names, each at most once
+	seen := make(map[string]bool, len(configData.Order))
+	for _, name := range configData.Order {
+		if !models.ActivityName(name).IsValid() {
+			return invalidTileConfig("Invalid tile in order: %s", name)
+		}
+		if seen[name] {
+			return invalidTileConfig("Duplicate tile in order: %s", name)
+		}
+		seen[name] = true
+	}
+
+	// Sizes must map valid tile names to a supported size. Custom tiles are only checked
+	// for format since the client keeps sizes of deleted tiles around.
+	for name, size := range configData.Sizes {
+		if !models.ActivityName(name).IsValid() {
+			return invalidTileConfig("Invalid tile in sizes: %s", name)
+		}
+		if !models.TileSizes[size] {
+			return invalidTileConfig("Invalid size for %s: %s", name, size)
+		}
+	}
+
+	for _, name := range configData.Hidden {
+		if !models.ActivityName(name).IsValid() {
+			return invalidTileConfig("Invalid tile in hidden: %s", name)
+		}
+	}
+
+	for name := range configData.Colors {
+		if !models.ActivityName(name).IsValid() {
+			return invalidTileConfig("Invalid tile in colors: %s", name)
+		}
+	}
+
 	// Validate custom tiles limit (max 5)
 	if len(configData.CustomTiles) > 5 {
 		return &TileConfigValidationError{
@@ -648,6 +1655,76 @@ func (s *TileConfigService) SaveConfig(userID uint, config models.JSONB) error {
 	return s.tileRepo.Save(userID, config)
 }
 
+// ReorderTiles replaces the user's tile order without touching the rest of their config.
+// Every entry must be a predefined activity or one of the user's custom tiles, listed at
+// most once. Users without a saved config get the default one first.
+func (s *TileConfigService) ReorderTiles(userID uint, order []string) error {
+	if _, err := s.GetOrCreateDefault(userID, true); err != nil {
+		return err
+	}
+
+	return s.tileRepo.UpdateOrder(userID, order, func(data *models.TileConfigData) error {
+		seen := make(map[string]bool, len(order))
+		for _, name := range order {
+			activity := models.ActivityName(name)
+			if !activity.IsValid() || (activity.IsCustomTile() && !data.HasCustomTile(activity.GetCustomTileID())) {
+				return invalidTileConfig("Unknown tile in order: %s", name)
+			}
+			if seen[name] {
+				return invalidTileConfig("Duplicate tile in order: %s", name)
+			}
+			seen[name] = true
+		}
+		return nil
+	})
+}
+
+// DeleteCustomTile removes a custom tile from the user's config. History is kept by
+// default: activities logged under the tile are archived, which leaves them in the
+// user's history and streaks but out of analytics. With purge set they and their story
+// photos are deleted first and streaks are recomputed without them; a purge may be
+// repeated after the tile is gone, to clear history kept earlier or finish a failed
+// purge. Returns the number of activities deleted.
+func (s *TileConfigService) DeleteCustomTile(ctx context.Context, userID uint, tileID string, purge bool) (int64, error) {
+	if !purge {
+		return 0, s.tileRepo.RemoveCustomTile(userID, tileID)
+	}
+
+	name := models.ActivityName(models.CustomTilePrefix + tileID)
+
+	// Photos first: once the activities are gone nothing else points at their blobs
+	if s.photoSvc != nil {
+		if err := s.photoSvc.DeleteByActivity(ctx, userID, string(name)); err != nil {
+			return 0, err
+		}
+	} else if err := s.photoRepo.DeleteByUserAndActivity(userID, string(name)); err != nil {
+		return 0, err
+	}
+
+	deleted, err := s.activityRepo.DeleteAllByName(userID, name)
+	if err != nil {
+		return 0, err
+	}
+	if err := s.streakSvc.RemoveActivity(userID, name); err != nil {
+		return deleted, err
+	}
+
+	// The tile goes last, so a purge that fails part way leaves it in place to retry
+	err = s.tileRepo.RemoveCustomTile(userID, tileID)
+	if errors.Is(err, repository.ErrCustomTileNotFound) && deleted > 0 {
+		err = nil
+	}
+	if err != nil {
+		return deleted, err
+	}
+
+	logger.LogWithUserID(userID).Infow("Custom tile purged",
+		"activity_name", name,
+		"activities_deleted", deleted,
+	)
+	return deleted, nil
+}
+
 // ==================== Search Suggestions Service ====================
 
 // SearchSuggestionsService handles search suggestions business logic
@@ -662,9 +1739,10 @@ func NewSearchSuggestionsService(recentSearchRepo *repository.RecentSearchReposi
 	}
 }
 
-// SaveRecentSearch saves a recent profile search (throttled to 60s)
+// SaveRecentSearch saves a recent profile search (throttled to 60s), keeping only the
+// newest RecentSearchMaxPerUser entries from the last RecentSearchMaxAgeDays days
 func (s *SearchSuggestionsService) SaveRecentSearch(userID, searchedUserID uint) error {
-	return s.recentSearchRepo.SaveRecentSearch(userID, searchedUserID)
+	return s.recentSearchRepo.SaveRecentSearch(userID, searchedUserID, constants.RecentSearchMaxPerUser, constants.RecentSearchMaxAgeDays)
 }
 
 // GetRecentSearches returns the user's recent profile searches
@@ -686,3 +1764,14 @@ func (s *SearchSuggestionsService) ClearRecentSearches(userID uint) error {
 func (s *SearchSuggestionsService) GetTrendingUsersForUser(userID uint, limit int) ([]repository.TrendingUserResult, error) {
 	return s.recentSearchRepo.GetTrendingUsersForUser(userID, limit)
 }
+
+// GetMutualSuggestions returns users followed by many of the people userID follows
+func (s *SearchSuggestionsService) GetMutualSuggestions(userID uint, limit int) ([]repository.MutualSuggestionResult, error) {
+	if limit <= 0 {
+		limit = constants.MutualSuggestionsDefaultLimit
+	}
+	if limit > constants.MutualSuggestionsMaxLimit {
+		limit = constants.MutualSuggestionsMaxLimit
+	}
+	return s.recentSearchRepo.GetMutualSuggestions(userID, limit)
+}
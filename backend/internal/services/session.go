@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/pkg/models"
+	"github.com/aman1117/backend/pkg/redis"
+)
+
+// SessionService manages login sessions (one per logged-in device)
+type SessionService struct {
+	sessionRepo *repository.SessionRepository
+}
+
+// NewSessionService creates a new SessionService
+func NewSessionService(sessionRepo *repository.SessionRepository) *SessionService {
+	return &SessionService{sessionRepo: sessionRepo}
+}
+
+// Start creates a session for a new login
+func (s *SessionService) Start(userID uint, userAgent, ip string) (*models.Session, error) {
+	if len(userAgent) > constants.SessionUserAgentMaxLen {
+		userAgent = userAgent[:constants.SessionUserAgentMaxLen]
+	}
+
+	session := &models.Session{
+		UserID:     userID,
+		UserAgent:  userAgent,
+		IPAddress:  ip,
+		LastSeenAt: time.Now(),
+	}
+	if err := s.sessionRepo.Create(session); err != nil {
+		return nil, err
+	}
+	return session, nil
+}
+
+// List returns the user's active sessions, most recently used first
+func (s *SessionService) List(userID uint) ([]models.Session, error) {
+	return s.sessionRepo.ListActiveByUser(userID)
+}
+
+// Revoke logs out one of the user's sessions. Its refresh tokens stop working
+// immediately and its access tokens are rejected by TokenService.Parse.
+// Returns false if the session does not belong to the user or is already revoked.
+func (s *SessionService) Revoke(ctx context.Context, userID, sessionID uint) (bool, error) {
+	revoked, err := s.sessionRepo.Revoke(userID, sessionID)
+	if err != nil || !revoked {
+		return revoked, err
+	}
+
+	if err := redis.SetSessionActive(ctx, sessionID, false); err != nil {
+		logger.Sugar.Warnw("Failed to cache revoked session", "session_id", sessionID, "error", err)
+	}
+	return true, nil
+}
+
+// RevokeAll logs out every session of the user
+func (s *SessionService) RevokeAll(ctx context.Context, userID uint) error {
+	ids, err := s.sessionRepo.RevokeAllForUser(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if err := redis.SetSessionActive(ctx, id, false); err != nil {
+			logger.Sugar.Warnw("Failed to cache revoked session", "session_id", id, "error", err)
+		}
+	}
+	return nil
+}
+
+// IsActive reports whether a session has not been revoked, served from Redis when cached
+func (s *SessionService) IsActive(ctx context.Context, sessionID uint) (bool, error) {
+	if active, found, err := redis.GetSessionActive(ctx, sessionID); err == nil && found {
+		return active, nil
+	}
+
+	active, err := s.sessionRepo.IsActive(sessionID)
+	if err != nil {
+		return false, err
+	}
+	if err := redis.CacheSessionActive(ctx, sessionID, active); err != nil {
+		logger.Sugar.Warnw("Failed to cache session status", "session_id", sessionID, "error", err)
+	}
+	return active, nil
+}
+
+// Touch records that the session was used. Writes are throttled to one per
+// SessionSeenInterval; force bypasses the throttle (used on login and refresh).
+func (s *SessionService) Touch(ctx context.Context, sessionID uint, ip string, force bool) {
+	if !force {
+		due, err := redis.MarkSessionSeen(ctx, sessionID)
+		if err != nil || !due {
+			return
+		}
+	}
+
+	if err := s.sessionRepo.TouchLastSeen(sessionID, ip); err != nil {
+		logger.Sugar.Warnw("Failed to update session last seen", "session_id", sessionID, "error", err)
+	}
+}
+
+// DeleteStale removes sessions revoked or unused since cutoff
+func (s *SessionService) DeleteStale(cutoff time.Time) (int64, error) {
+	return s.sessionRepo.DeleteStale(cutoff)
+}
@@ -0,0 +1,122 @@
+package services
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata" // DST rules must not depend on the host's zoneinfo
+
+	"github.com/aman1117/backend/internal/constants"
+)
+
+func mustLoadLocation(t *testing.T, name string) *time.Location {
+	t.Helper()
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		t.Fatalf("load %s: %v", name, err)
+	}
+	return loc
+}
+
+func utc(year int, month time.Month, day, hour, min int) time.Time {
+	return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+}
+
+func TestStartOfDayAcrossDST(t *testing.T) {
+	tests := []struct {
+		name      string
+		zone      string
+		date      time.Time // calendar date only
+		wantStart time.Time // first instant of the date, in UTC
+		wantHours float64   // length of the local day
+	}{
+		{"new york spring forward", "America/New_York", utc(2024, 3, 10, 0, 0), utc(2024, 3, 10, 5, 0), 23},
+		{"new york fall back", "America/New_York", utc(2024, 11, 3, 0, 0), utc(2024, 11, 3, 4, 0), 25},
+		{"london spring forward", "Europe/London", utc(2024, 3, 31, 0, 0), utc(2024, 3, 31, 0, 0), 23},
+		{"london fall back", "Europe/London", utc(2024, 10, 27, 0, 0), utc(2024, 10, 26, 23, 0), 25},
+		// Santiago and Beirut skip midnight itself: the day starts at 01:00 local
+		{"santiago spring forward", "America/Santiago", utc(2024, 9, 8, 0, 0), utc(2024, 9, 8, 4, 0), 23},
+		{"santiago fall back", "America/Santiago", utc(2024, 4, 7, 0, 0), utc(2024, 4, 7, 4, 0), 24},
+		{"beirut spring forward", "Asia/Beirut", utc(2024, 3, 31, 0, 0), utc(2024, 3, 30, 22, 0), 23},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoadLocation(t, tt.zone)
+			wantDate := tt.date.Format(constants.DateFormat)
+
+			start := startOfDay(tt.date.Year(), tt.date.Month(), tt.date.Day(), loc)
+			if got := start.Format(constants.DateFormat); got != wantDate {
+				t.Fatalf("startOfDay date = %s, want %s (got %v)", got, wantDate, start)
+			}
+			if !start.Equal(tt.wantStart) {
+				t.Errorf("startOfDay = %v, want %v", start.UTC(), tt.wantStart)
+			}
+
+			next := addLocalDays(start, 1)
+			if got := next.Sub(start).Hours(); got != tt.wantHours {
+				t.Errorf("day length = %vh, want %vh", got, tt.wantHours)
+			}
+			if got, want := next.Format(constants.DateFormat), tt.date.AddDate(0, 0, 1).Format(constants.DateFormat); got != want {
+				t.Errorf("next day = %s, want %s", got, want)
+			}
+
+			// The day after a skipped midnight must look back to the right date
+			if got, want := addLocalDays(next, -1).Format(constants.DateFormat), wantDate; got != want {
+				t.Errorf("previous day of %s = %s, want %s", next.Format(constants.DateFormat), got, want)
+			}
+		})
+	}
+}
+
+func TestLocalDateAcrossDST(t *testing.T) {
+	tests := []struct {
+		name string
+		zone string
+		now  time.Time
+		want string
+	}{
+		{"before spring forward gap", "America/New_York", utc(2024, 3, 10, 6, 59), "2024-03-10"},
+		{"after spring forward gap", "America/New_York", utc(2024, 3, 10, 7, 0), "2024-03-10"},
+		{"last minute of short day", "America/New_York", utc(2024, 3, 11, 3, 59), "2024-03-10"},
+		{"first 01:30 on fall back", "America/New_York", utc(2024, 11, 3, 5, 30), "2024-11-03"},
+		{"repeated 01:30 on fall back", "America/New_York", utc(2024, 11, 3, 6, 30), "2024-11-03"},
+		{"last minute of long day", "America/New_York", utc(2024, 11, 4, 4, 59), "2024-11-03"},
+		{"evening before skipped midnight", "America/Santiago", utc(2024, 9, 8, 3, 59), "2024-09-07"},
+		{"first instant after skipped midnight", "America/Santiago", utc(2024, 9, 8, 4, 0), "2024-09-08"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			loc := mustLoadLocation(t, tt.zone)
+			day := localDate(tt.now, loc)
+			if got := day.Format(constants.DateFormat); got != tt.want {
+				t.Errorf("localDate(%v) = %s, want %s", tt.now, got, tt.want)
+			}
+			if day.After(tt.now) {
+				t.Errorf("localDate(%v) = %v starts after now", tt.now, day)
+			}
+		})
+	}
+}
+
+func TestStreakReminderDueAcrossDST(t *testing.T) {
+	loc := mustLoadLocation(t, "America/New_York")
+	tests := []struct {
+		name string
+		now  time.Time
+		want bool
+	}{
+		{"21:59 EDT on spring forward day", utc(2024, 3, 11, 1, 59), false},
+		{"22:00 EDT on spring forward day", utc(2024, 3, 11, 2, 0), true},
+		{"21:59 EST on fall back day", utc(2024, 11, 4, 2, 59), false},
+		{"22:00 EST on fall back day", utc(2024, 11, 4, 3, 0), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := streakReminderDue(tt.now, loc); got != tt.want {
+				t.Errorf("streakReminderDue(%v) = %v, want %v", tt.now.In(loc), got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/internal/testutil"
+	"github.com/aman1117/backend/pkg/models"
+)
+
+func TestDeleteCustomTileKeepHistoryVersusPurge(t *testing.T) {
+	db := testutil.OpenDB(t)
+	user := testutil.CreateUser(t, db, "tiledeleter")
+
+	userRepo := repository.NewUserRepository(db)
+	activityRepo := repository.NewActivityRepository(db)
+	streakRepo := repository.NewStreakRepository(db)
+	tileSvc := NewTileConfigService(
+		repository.NewTileConfigRepository(db),
+		userRepo,
+		repository.NewActivityPhotoRepository(db),
+		activityRepo,
+		NewStreakService(streakRepo, activityRepo, userRepo),
+		nil,
+	)
+	analyticsSvc := NewAnalyticsService(activityRepo, streakRepo, userRepo)
+
+	saveTiles := func(t *testing.T, tiles ...interface{}) {
+		t.Helper()
+		if err := tileSvc.SaveConfig(user.ID, models.JSONB{"customTiles": tiles}); err != nil {
+			t.Fatalf("SaveConfig: %v", err)
+		}
+	}
+	saveTiles(t, customTile(readingTileID, "Reading"), customTile(guitarTileID, "Guitar"))
+
+	reading := models.ActivityName(models.CustomTilePrefix + readingTileID)
+	guitar := models.ActivityName(models.CustomTilePrefix + guitarTileID)
+	date := time.Date(2025, time.March, 10, 0, 0, 0, 0, time.UTC)
+	for name, hours := range map[models.ActivityName]float32{models.ActivityStudy: 3, reading: 2, guitar: 1} {
+		if _, _, err := activityRepo.UpsertForDay(user.ID, date, name, hours, nil, constants.MaxDailyHours); err != nil {
+			t.Fatalf("log %s: %v", name, err)
+		}
+	}
+
+	// expectDay checks the day's hours in analytics and in the user's history
+	expectDay := func(t *testing.T, wantAnalytics, wantHistory float32) {
+		t.Helper()
+		agg, err := analyticsSvc.aggregateRange(user.ID, date, date)
+		if err != nil {
+			t.Fatalf("aggregateRange: %v", err)
+		}
+		if agg.totalHours != wantAnalytics {
+			t.Errorf("analytics total = %vh, want %vh", agg.totalHours, wantAnalytics)
+		}
+
+		history, err := activityRepo.FindByUserAndDateRange(user.ID, date, date)
+		if err != nil {
+			t.Fatalf("FindByUserAndDateRange: %v", err)
+		}
+		var total float32
+		for _, a := range history {
+			total += a.DurationHours
+		}
+		if total != wantHistory {
+			t.Errorf("history total = %vh, want %vh", total, wantHistory)
+		}
+	}
+	expectDay(t, 6, 6)
+
+	t.Run("keeping history archives the tile's activities", func(t *testing.T) {
+		deleted, err := tileSvc.DeleteCustomTile(context.Background(), user.ID, readingTileID, false)
+		if err != nil {
+			t.Fatalf("DeleteCustomTile: %v", err)
+		}
+		if deleted != 0 {
+			t.Errorf("deleted %d activities, want 0", deleted)
+		}
+		expectDay(t, 4, 6)
+	})
+
+	t.Run("adding the tile back restores it to analytics", func(t *testing.T) {
+		saveTiles(t, customTile(readingTileID, "Reading"), customTile(guitarTileID, "Guitar"))
+		expectDay(t, 6, 6)
+	})
+
+	t.Run("purging deletes the tile's activities", func(t *testing.T) {
+		deleted, err := tileSvc.DeleteCustomTile(context.Background(), user.ID, guitarTileID, true)
+		if err != nil {
+			t.Fatalf("DeleteCustomTile: %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("deleted %d activities, want 1", deleted)
+		}
+		expectDay(t, 5, 5)
+
+		config, err := tileSvc.GetConfig(user.ID)
+		if err != nil {
+			t.Fatalf("GetConfig: %v", err)
+		}
+		data, err := config.GetConfigData()
+		if err != nil {
+			t.Fatalf("GetConfigData: %v", err)
+		}
+		if data.HasCustomTile(guitarTileID) {
+			t.Error("purged tile is still in the config")
+		}
+	})
+
+	t.Run("purging a tile deleted with its history kept clears the history", func(t *testing.T) {
+		if _, err := tileSvc.DeleteCustomTile(context.Background(), user.ID, readingTileID, false); err != nil {
+			t.Fatalf("DeleteCustomTile: %v", err)
+		}
+		deleted, err := tileSvc.DeleteCustomTile(context.Background(), user.ID, readingTileID, true)
+		if err != nil {
+			t.Fatalf("purge after keeping history: %v", err)
+		}
+		if deleted != 1 {
+			t.Errorf("deleted %d activities, want 1", deleted)
+		}
+		expectDay(t, 3, 3)
+	})
+
+	t.Run("purging an unknown tile is not found", func(t *testing.T) {
+		_, err := tileSvc.DeleteCustomTile(context.Background(), user.ID, "00000000-0000-4000-8000-000000000000", true)
+		if !errors.Is(err, repository.ErrCustomTileNotFound) {
+			t.Errorf("error = %v, want ErrCustomTileNotFound", err)
+		}
+	})
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aman1117/backend/internal/config"
+	"github.com/aman1117/backend/internal/constants"
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/pkg/models"
+	"github.com/aman1117/backend/pkg/redis"
+	"github.com/aman1117/backend/pkg/totp"
+	"golang.org/x/crypto/bcrypt"
+)
+
+var (
+	// ErrTwoFactorRequired is returned by Authenticate when the password is correct but
+	// the account has two-factor enabled and no code was given
+	ErrTwoFactorRequired = errors.New("two-factor code required")
+	// ErrInvalidTwoFactorCode is returned when a TOTP or backup code does not match
+	ErrInvalidTwoFactorCode = errors.New("invalid two-factor code")
+	// ErrTwoFactorAlreadyEnabled is returned when enrolling an account that already has two-factor
+	ErrTwoFactorAlreadyEnabled = errors.New("two-factor already enabled")
+	// ErrTwoFactorNotEnabled is returned when disabling two-factor on an account without it
+	ErrTwoFactorNotEnabled = errors.New("two-factor not enabled")
+	// ErrTwoFactorNotEnrolled is returned when confirming before an enrollment was started
+	ErrTwoFactorNotEnrolled = errors.New("two-factor enrollment not started")
+	// ErrIncorrectPassword is returned when a sensitive change is confirmed with the wrong password
+	ErrIncorrectPassword = errors.New("incorrect password")
+)
+
+// TwoFactorEnrollment is a pending TOTP enrollment shown to the user once
+type TwoFactorEnrollment struct {
+	Secret string
+	URL    string
+}
+
+// TwoFactorService handles TOTP enrollment, verification and backup codes.
+// Secrets are stored AES-GCM encrypted; backup codes are stored as SHA-256 hashes.
+type TwoFactorService struct {
+	repo     *repository.TwoFactorRepository
+	userRepo *repository.UserRepository
+	aead     cipher.AEAD
+	issuer   string
+}
+
+// NewTwoFactorService creates a new TwoFactorService. The encryption key is derived from
+// cfg.EncryptionKey, or from fallbackKey (the JWT secret) when it is not set.
+func NewTwoFactorService(repo *repository.TwoFactorRepository, userRepo *repository.UserRepository, cfg *config.TwoFactorConfig, fallbackKey string) *TwoFactorService {
+	keyMaterial := cfg.EncryptionKey
+	if keyMaterial == "" {
+		logger.Sugar.Warn("TOTP_ENCRYPTION_KEY not set, deriving the two-factor key from the JWT secret")
+		keyMaterial = "totp:" + fallbackKey
+	}
+	key := sha256.Sum256([]byte(keyMaterial))
+
+	// A 32-byte key always yields a valid AES-256 block, and AES always supports GCM
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		panic(fmt.Sprintf("two-factor cipher: %v", err))
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		panic(fmt.Sprintf("two-factor cipher: %v", err))
+	}
+
+	return &TwoFactorService{
+		repo:     repo,
+		userRepo: userRepo,
+		aead:     aead,
+		issuer:   cfg.Issuer,
+	}
+}
+
+// Enroll starts (or restarts) enrollment by generating a new secret. Two-factor is not
+// enforced until ConfirmEnrollment verifies a code from the authenticator app.
+func (s *TwoFactorService) Enroll(userID uint) (*TwoFactorEnrollment, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := s.encrypt(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	stored, err := s.repo.SetPendingSecret(userID, encrypted)
+	if err != nil {
+		return nil, err
+	}
+	if !stored {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+
+	return &TwoFactorEnrollment{
+		Secret: secret,
+		URL:    totp.URL(s.issuer, user.Username, secret),
+	}, nil
+}
+
+// ConfirmEnrollment verifies the first code from the authenticator app, enables
+// two-factor and returns freshly generated backup codes (shown to the user once)
+func (s *TwoFactorService) ConfirmEnrollment(ctx context.Context, userID uint, code string) ([]string, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user.TwoFactorEnabled {
+		return nil, ErrTwoFactorAlreadyEnabled
+	}
+	if user.TwoFactorSecret == nil {
+		return nil, ErrTwoFactorNotEnrolled
+	}
+
+	if err := s.verifyTOTP(ctx, user, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateBackupCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.repo.Enable(userID, hashes); err != nil {
+		return nil, err
+	}
+
+	logger.LogWithUserID(userID).Info("Two-factor authentication enabled")
+	return codes, nil
+}
+
+// Disable turns two-factor off after checking the password and a current TOTP or backup code
+func (s *TwoFactorService) Disable(ctx context.Context, userID uint, password, code string) error {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return err
+	}
+	if !user.TwoFactorEnabled {
+		return ErrTwoFactorNotEnabled
+	}
+	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
+		return ErrIncorrectPassword
+	}
+	if err := s.Verify(ctx, user, code); err != nil {
+		return err
+	}
+
+	if err := s.repo.Disable(userID); err != nil {
+		return err
+	}
+
+	logger.LogWithUserID(userID).Info("Two-factor authentication disabled")
+	return nil
+}
+
+// Status reports whether two-factor is enabled and how many backup codes remain
+func (s *TwoFactorService) Status(userID uint) (bool, int64, error) {
+	user, err := s.findUser(userID)
+	if err != nil {
+		return false, 0, err
+	}
+	if !user.TwoFactorEnabled {
+		return false, 0, nil
+	}
+
+	remaining, err := s.repo.CountUnusedBackupCodes(userID)
+	if err != nil {
+		return false, 0, err
+	}
+	return true, remaining, nil
+}
+
+// Verify checks a login code for a user with two-factor enabled. A 6-digit code is
+// checked as TOTP; anything else is treated as a backup code, which is consumed on use.
+func (s *TwoFactorService) Verify(ctx context.Context, user *models.User, code string) error {
+	code = strings.TrimSpace(code)
+	if code == "" || len(code) > constants.TwoFactorCodeMaxLen {
+		return ErrInvalidTwoFactorCode
+	}
+
+	if len(code) == totp.Digits {
+		return s.verifyTOTP(ctx, user, code)
+	}
+
+	consumed, err := s.repo.ConsumeBackupCode(user.ID, redis.HashToken(normalizeBackupCode(code)))
+	if err != nil {
+		return err
+	}
+	if !consumed {
+		return ErrInvalidTwoFactorCode
+	}
+
+	logger.LogWithUserID(user.ID).Info("Two-factor backup code used")
+	return nil
+}
+
+// verifyTOTP checks a TOTP code against the user's stored secret, rejecting reuse of a
+// code that was already accepted
+func (s *TwoFactorService) verifyTOTP(ctx context.Context, user *models.User, code string) error {
+	if user.TwoFactorSecret == nil {
+		return ErrInvalidTwoFactorCode
+	}
+	secret, err := s.decrypt(*user.TwoFactorSecret)
+	if err != nil {
+		return err
+	}
+
+	step, ok := totp.Validate(secret, code, time.Now(), constants.TOTPSkewSteps)
+	if !ok {
+		return ErrInvalidTwoFactorCode
+	}
+
+	ttl := time.Duration(2*constants.TOTPSkewSteps+1) * totp.Period
+	fresh, err := redis.MarkTOTPStepUsed(ctx, user.ID, step, ttl)
+	if err != nil {
+		logger.Sugar.Warnw("Failed to record TOTP use", "user_id", user.ID, "error", err)
+	} else if !fresh {
+		return ErrInvalidTwoFactorCode
+	}
+	return nil
+}
+
+func (s *TwoFactorService) findUser(userID uint) (*models.User, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	return user, nil
+}
+
+// encrypt seals a secret as base64(nonce || ciphertext)
+func (s *TwoFactorService) encrypt(plaintext string) (string, error) {
+	nonce := make([]byte, s.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := s.aead.Seal(nonce, nonce, []byte(plaintext), nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+func (s *TwoFactorService) decrypt(encoded string) (string, error) {
+	sealed, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", fmt.Errorf("failed to decode two-factor secret: %w", err)
+	}
+	if len(sealed) < s.aead.NonceSize() {
+		return "", errors.New("two-factor secret is malformed")
+	}
+
+	nonce, ciphertext := sealed[:s.aead.NonceSize()], sealed[s.aead.NonceSize():]
+	plaintext, err := s.aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt two-factor secret: %w", err)
+	}
+	return string(plaintext), nil
+}
+
+// generateBackupCodes returns display codes (xxxxx-xxxxx) and the hashes to store
+func generateBackupCodes() ([]string, []string, error) {
+	codes := make([]string, 0, constants.BackupCodeCount)
+	hashes := make([]string, 0, constants.BackupCodeCount)
+
+	for i := 0; i < constants.BackupCodeCount; i++ {
+		bytes := make([]byte, constants.BackupCodeByteLen)
+		if _, err := rand.Read(bytes); err != nil {
+			return nil, nil, fmt.Errorf("failed to generate random bytes: %w", err)
+		}
+		raw := hex.EncodeToString(bytes)
+		codes = append(codes, raw[:len(raw)/2]+"-"+raw[len(raw)/2:])
+		hashes = append(hashes, redis.HashToken(raw))
+	}
+	return codes, hashes, nil
+}
+
+// normalizeBackupCode strips separators and case so "ABCDE-12345" matches "abcde12345"
+func normalizeBackupCode(code string) string {
+	code = strings.ToLower(strings.TrimSpace(code))
+	return strings.NewReplacer("-", "", " ", "").Replace(code)
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aman1117/backend/internal/logger"
+	"github.com/aman1117/backend/internal/repository"
+	"github.com/aman1117/backend/pkg/models"
+)
+
+// ErrAlreadyVerified is returned when a verified user submits a verification request
+var ErrAlreadyVerified = errors.New("user is already verified")
+
+// VerificationRequestService handles verified-badge requests and their admin review
+type VerificationRequestService struct {
+	requestRepo *repository.VerificationRequestRepository
+	userRepo    *repository.UserRepository
+	notifSvc    *NotificationService
+}
+
+// NewVerificationRequestService creates a new VerificationRequestService
+func NewVerificationRequestService(requestRepo *repository.VerificationRequestRepository, userRepo *repository.UserRepository, notifSvc *NotificationService) *VerificationRequestService {
+	return &VerificationRequestService{
+		requestRepo: requestRepo,
+		userRepo:    userRepo,
+		notifSvc:    notifSvc,
+	}
+}
+
+// Submit files a pending verification request for the user.
+// Returns ErrAlreadyVerified or repository.ErrVerificationRequestPending when a request is not allowed.
+func (s *VerificationRequestService) Submit(userID uint, justification string) (*models.VerificationRequest, error) {
+	user, err := s.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	if user.IsVerified {
+		return nil, ErrAlreadyVerified
+	}
+
+	req := &models.VerificationRequest{
+		UserID:        userID,
+		Justification: justification,
+		Status:        models.VerificationStatusPending,
+	}
+	if err := s.requestRepo.Create(req); err != nil {
+		return nil, err
+	}
+	return req, nil
+}
+
+// GetLatest returns the user's most recent request, or nil if they never submitted one
+func (s *VerificationRequestService) GetLatest(userID uint) (*models.VerificationRequest, error) {
+	return s.requestRepo.FindLatestByUser(userID)
+}
+
+// ListPending returns a page of requests awaiting review, oldest first
+func (s *VerificationRequestService) ListPending(page, pageSize int) ([]models.VerificationRequestWithUser, int64, error) {
+	return s.requestRepo.ListByStatus(models.VerificationStatusPending, pageSize, (page-1)*pageSize)
+}
+
+// Review approves or denies a pending request. Approval sets the requester's verified
+// badge and notifies them. Returns repository.ErrVerificationRequestNotFound if the
+// request does not exist or was already reviewed.
+func (s *VerificationRequestService) Review(ctx context.Context, reviewerID, requestID uint, approve bool, note *string) (*models.VerificationRequest, error) {
+	req, err := s.requestRepo.Review(requestID, reviewerID, approve, note)
+	if err != nil {
+		return nil, err
+	}
+
+	logger.Sugar.Infow("Verification request reviewed",
+		"request_id", req.ID,
+		"user_id", req.UserID,
+		"reviewer_id", reviewerID,
+		"status", req.Status,
+	)
+
+	if approve && s.notifSvc != nil {
+		if err := s.notifSvc.NotifyVerificationApproved(ctx, req.UserID, req.ID); err != nil {
+			logger.Sugar.Warnw("Failed to send verification approved notification", "user_id", req.UserID, "error", err)
+		}
+	}
+	return req, nil
+}
@@ -0,0 +1,62 @@
+package validator
+
+// commonPasswords holds widely used passwords that meet the length rule but are
+// among the first guesses of any credential-stuffing list. Compared case-insensitively.
+var commonPasswords = map[string]struct{}{
+	"password":      {},
+	"password1":     {},
+	"password12":    {},
+	"password123":   {},
+	"passw0rd":      {},
+	"p@ssw0rd":      {},
+	"12345678":      {},
+	"123456789":     {},
+	"1234567890":    {},
+	"87654321":      {},
+	"11111111":      {},
+	"00000000":      {},
+	"12341234":      {},
+	"11223344":      {},
+	"qwertyui":      {},
+	"qwerty123":     {},
+	"qwertyuiop":    {},
+	"1q2w3e4r":      {},
+	"1qaz2wsx":      {},
+	"zaq12wsx":      {},
+	"asdfghjkl":     {},
+	"abcd1234":      {},
+	"abc12345":      {},
+	"abcdefgh":      {},
+	"iloveyou":      {},
+	"iloveyou1":     {},
+	"sunshine":      {},
+	"princess":      {},
+	"football":      {},
+	"baseball":      {},
+	"basketball":    {},
+	"superman":      {},
+	"batman123":     {},
+	"welcome1":      {},
+	"welcome123":    {},
+	"letmein1":      {},
+	"letmein123":    {},
+	"trustno1":      {},
+	"starwars":      {},
+	"whatever":      {},
+	"computer":      {},
+	"internet":      {},
+	"master123":     {},
+	"admin123":      {},
+	"changeme":      {},
+	"secret123":     {},
+	"michael1":      {},
+	"jennifer":      {},
+	"charlie1":      {},
+	"monkey123":     {},
+	"dragon123":     {},
+	"shadow123":     {},
+	"liverpool":     {},
+	"chelsea1":      {},
+	"india123":      {},
+	"growthtracker": {},
+}
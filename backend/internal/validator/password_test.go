@@ -0,0 +1,71 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aman1117/backend/internal/constants"
+)
+
+func TestValidatePasswordPolicy(t *testing.T) {
+	tests := []struct {
+		name     string
+		password string
+		username string
+		email    string
+		wantCode string // empty when the password is accepted
+	}{
+		{"accepts a long passphrase", "correct horse battery", "alice", "alice@example.com", ""},
+		{"accepts exactly the minimum length", "k9#vQ2mz", "alice", "alice@example.com", ""},
+		{"accepts the maximum byte length", strings.Repeat("x", constants.PasswordMaxBytes), "alice", "alice@example.com", ""},
+		{"accepts a password containing the username", "alice-in-chains", "alice", "alice@example.com", ""},
+
+		{"rejects empty", "", "alice", "alice@example.com", constants.ErrCodeMissingFields},
+		{"rejects whitespace only", "          ", "alice", "alice@example.com", constants.ErrCodeMissingFields},
+		{"rejects too short", "k9#vQ2m", "alice", "alice@example.com", constants.ErrCodePasswordTooShort},
+		{"rejects short once trimmed", "   k9#vQ2   ", "alice", "alice@example.com", constants.ErrCodePasswordTooShort},
+		{"rejects over the bcrypt limit", strings.Repeat("x", constants.PasswordMaxBytes+1), "alice", "alice@example.com", constants.ErrCodePasswordTooLong},
+
+		// The minimum counts characters, the maximum counts bytes
+		{"accepts eight multibyte characters", "пароль日本", "alice", "alice@example.com", ""},
+		{"rejects seven multibyte characters", "пароль日", "alice", "alice@example.com", constants.ErrCodePasswordTooShort},
+		{"rejects multibyte characters over the byte limit", strings.Repeat("日", constants.PasswordMaxBytes/3+1), "alice", "alice@example.com", constants.ErrCodePasswordTooLong},
+
+		{"rejects a common password", "password123", "alice", "alice@example.com", constants.ErrCodeCommonPassword},
+		{"rejects a common password in any case", "PassWord123", "alice", "alice@example.com", constants.ErrCodeCommonPassword},
+		{"rejects a common password with surrounding spaces", "  iloveyou  ", "alice", "alice@example.com", constants.ErrCodeCommonPassword},
+		{"rejects a common numeric password", "12345678", "alice", "alice@example.com", constants.ErrCodeCommonPassword},
+
+		{"rejects the username", "alicewonder", "alicewonder", "alice@example.com", constants.ErrCodePasswordSameAsUser},
+		{"rejects the username in another case", "AliceWonder", "alicewonder", "alice@example.com", constants.ErrCodePasswordSameAsUser},
+		{"rejects the email", "alice@example.com", "alice", "alice@example.com", constants.ErrCodePasswordSameAsUser},
+		{"rejects the email name", "alice.wonder", "alice", "Alice.Wonder@example.com", constants.ErrCodePasswordSameAsUser},
+		{"accepts without account details", "alice.wonder", "", "", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidatePasswordPolicy(tt.password, tt.username, tt.email)
+			switch {
+			case tt.wantCode == "" && err != nil:
+				t.Errorf("ValidatePasswordPolicy(%q) = %s (%s), want accepted", tt.password, err.ErrorCode, err.Message)
+			case tt.wantCode != "" && err == nil:
+				t.Errorf("ValidatePasswordPolicy(%q) accepted, want %s", tt.password, tt.wantCode)
+			case tt.wantCode != "" && err.ErrorCode != tt.wantCode:
+				t.Errorf("ValidatePasswordPolicy(%q) = %s, want %s", tt.password, err.ErrorCode, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestCommonPasswordsMeetLengthRule(t *testing.T) {
+	// Entries shorter than the minimum are already rejected by length and would never match
+	for password := range commonPasswords {
+		if err := ValidatePassword(password); err != nil {
+			t.Errorf("common password %q fails the length rule (%s), so the entry is dead", password, err.ErrorCode)
+		}
+		if password != strings.ToLower(password) {
+			t.Errorf("common password %q must be lowercase to match", password)
+		}
+	}
+}
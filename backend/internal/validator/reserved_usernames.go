@@ -0,0 +1,43 @@
+package validator
+
+import "strings"
+
+// defaultReservedUsernames are names nobody may take: ones that could pass for the app
+// or its staff, route-like words, and a few obvious slurs/profanity. Deployments can
+// add more with RESERVED_USERNAMES.
+var defaultReservedUsernames = []string{
+	"admin", "administrator", "root", "sysadmin", "superuser", "moderator", "mod", "staff",
+	"support", "help", "helpdesk", "contact", "security", "abuse", "official", "team",
+	"system", "service", "bot", "noreply", "postmaster", "webmaster", "hostmaster", "info",
+	"growthtracker", "growth", "tracker",
+	"api", "app", "www", "mail", "email", "auth", "login", "logout", "signup", "register",
+	"settings", "profile", "me", "home", "explore", "search", "notifications", "privacy",
+	"terms", "about", "null", "undefined", "anonymous", "everyone", "here",
+	"fuck", "shit", "bitch", "cunt", "nigger", "faggot", "whore", "slut", "porn",
+}
+
+// ReservedUsernames is a set of usernames nobody may register or change to.
+// Matching ignores case and the separators "_" and ".", so "ad.min" and "Admin_" are caught too.
+type ReservedUsernames map[string]struct{}
+
+// NewReservedUsernames builds the reserved set from the defaults plus extra names
+func NewReservedUsernames(extra []string) ReservedUsernames {
+	reserved := make(ReservedUsernames, len(defaultReservedUsernames)+len(extra))
+	for _, name := range append(append([]string{}, defaultReservedUsernames...), extra...) {
+		if key := normalizeReserved(name); key != "" {
+			reserved[key] = struct{}{}
+		}
+	}
+	return reserved
+}
+
+// Contains reports whether the username is reserved
+func (r ReservedUsernames) Contains(username string) bool {
+	_, ok := r[normalizeReserved(username)]
+	return ok
+}
+
+func normalizeReserved(username string) string {
+	username = strings.ToLower(strings.TrimSpace(username))
+	return strings.NewReplacer("_", "", ".", "").Replace(username)
+}
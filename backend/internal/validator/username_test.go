@@ -0,0 +1,80 @@
+package validator
+
+import (
+	"testing"
+
+	"github.com/aman1117/backend/internal/constants"
+)
+
+func TestValidateUsernamePolicy(t *testing.T) {
+	// Extra names as RESERVED_USERNAMES supplies them: split on commas, any case and separators
+	reserved := NewReservedUsernames([]string{"Acme", "beta_tester", "Growth.Labs"})
+
+	tests := []struct {
+		name     string
+		username string
+		wantCode string // empty when the username is accepted
+	}{
+		{"accepts letters and digits", "alice42", ""},
+		{"accepts inner separators", "alice_w.42", ""},
+		{"accepts the minimum length", "abc", ""},
+		{"accepts the maximum length", "abcdefghijklmnopqrst", ""},
+		{"accepts a name containing a reserved word", "adminfan", ""},
+
+		{"rejects empty", "", constants.ErrCodeMissingFields},
+		{"rejects whitespace only", "   ", constants.ErrCodeMissingFields},
+		{"rejects too short", "ab", constants.ErrCodeInvalidUsernameLen},
+		{"rejects too long", "abcdefghijklmnopqrstu", constants.ErrCodeInvalidUsernameLen},
+		{"rejects spaces", "alice w", constants.ErrCodeInvalidUsernameFmt},
+		{"rejects symbols", "alice-w", constants.ErrCodeInvalidUsernameFmt},
+		{"rejects non-ASCII letters", "alicé", constants.ErrCodeInvalidUsernameFmt},
+		{"rejects a leading separator", "_alice", constants.ErrCodeInvalidUsernameFmt},
+		{"rejects a trailing separator", "alice.", constants.ErrCodeInvalidUsernameFmt},
+		{"rejects repeated underscores", "alice__w", constants.ErrCodeInvalidUsernameFmt},
+		{"rejects repeated dots", "alice..w", constants.ErrCodeInvalidUsernameFmt},
+		{"rejects mixed repeated separators", "alice._w", constants.ErrCodeInvalidUsernameFmt},
+
+		{"rejects a default reserved name", "admin", constants.ErrCodeUsernameReserved},
+		{"rejects a reserved name in another case", "AdMiN", constants.ErrCodeUsernameReserved},
+		{"rejects a reserved name with surrounding spaces", "  support  ", constants.ErrCodeUsernameReserved},
+		{"rejects a reserved name split by a dot", "ad.min", constants.ErrCodeUsernameReserved},
+		{"rejects a reserved name split by an underscore", "no_reply", constants.ErrCodeUsernameReserved},
+
+		{"rejects a configured name", "acme", constants.ErrCodeUsernameReserved},
+		{"rejects a configured name in another case", "ACME", constants.ErrCodeUsernameReserved},
+		{"rejects a configured name without its separator", "betatester", constants.ErrCodeUsernameReserved},
+		{"rejects a configured name with another separator", "growth_labs", constants.ErrCodeUsernameReserved},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateUsernamePolicy(tt.username, reserved)
+			switch {
+			case tt.wantCode == "" && err != nil:
+				t.Errorf("ValidateUsernamePolicy(%q) = %s (%s), want accepted", tt.username, err.ErrorCode, err.Message)
+			case tt.wantCode != "" && err == nil:
+				t.Errorf("ValidateUsernamePolicy(%q) accepted, want %s", tt.username, tt.wantCode)
+			case tt.wantCode != "" && err.ErrorCode != tt.wantCode:
+				t.Errorf("ValidateUsernamePolicy(%q) = %s, want %s", tt.username, err.ErrorCode, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestReservedUsernamesOverridesAreAdditive(t *testing.T) {
+	// Configuring extra names keeps the defaults reserved; blank entries reserve nothing
+	reserved := NewReservedUsernames([]string{"acme", "", " _ "})
+	for _, name := range []string{"acme", "admin", "growthtracker"} {
+		if !reserved.Contains(name) {
+			t.Errorf("%q is not reserved, want reserved", name)
+		}
+	}
+	if reserved.Contains("") {
+		t.Error("the empty name is reserved, want blank entries ignored")
+	}
+
+	// Without overrides only the defaults apply
+	if NewReservedUsernames(nil).Contains("acme") {
+		t.Error(`"acme" is reserved without being configured`)
+	}
+}
@@ -2,9 +2,11 @@
 package validator
 
 import (
+	"fmt"
 	"regexp"
 	"strings"
 	"unicode"
+	"unicode/utf8"
 
 	"github.com/aman1117/backend/internal/constants"
 )
@@ -30,7 +32,8 @@ func NewValidationError(message, code string) *ValidationError {
 	}
 }
 
-// ValidateUsername validates a username for format and length
+// ValidateUsername validates a username for format and length.
+// Separators ("_" and ".") may not lead, trail, or repeat.
 func ValidateUsername(username string) *ValidationError {
 	username = strings.ToLower(strings.TrimSpace(username))
 
@@ -52,24 +55,93 @@ func ValidateUsername(username string) *ValidationError {
 		)
 	}
 
+	if strings.Trim(username, "_.") != username {
+		return NewValidationError(
+			"Username cannot start or end with _ or .",
+			constants.ErrCodeInvalidUsernameFmt,
+		)
+	}
+
+	if strings.Contains(username, "..") || strings.Contains(username, "__") ||
+		strings.Contains(username, "._") || strings.Contains(username, "_.") {
+		return NewValidationError(
+			"Username cannot contain consecutive _ or .",
+			constants.ErrCodeInvalidUsernameFmt,
+		)
+	}
+
 	return nil
 }
 
-// ValidatePassword validates a password for minimum length
+// ValidateUsernamePolicy validates a new username's format and rejects reserved names
+func ValidateUsernamePolicy(username string, reserved ReservedUsernames) *ValidationError {
+	if err := ValidateUsername(username); err != nil {
+		return err
+	}
+
+	if reserved.Contains(username) {
+		return NewValidationError(
+			"This username is not available",
+			constants.ErrCodeUsernameReserved,
+		)
+	}
+
+	return nil
+}
+
+// ValidatePassword validates a password for minimum and maximum length.
+// The minimum counts characters, so non-ASCII passwords aren't favoured; the maximum
+// counts bytes because bcrypt only uses the first 72.
 func ValidatePassword(password string) *ValidationError {
-	password = strings.TrimSpace(password)
+	trimmed := strings.TrimSpace(password)
 
-	if password == "" {
+	if trimmed == "" {
 		return NewValidationError("Password is required", constants.ErrCodeMissingFields)
 	}
 
-	if len(password) < constants.PasswordMinLength {
+	if utf8.RuneCountInString(trimmed) < constants.PasswordMinLength {
 		return NewValidationError(
-			"Password must be at least 8 characters long",
+			fmt.Sprintf("Password must be at least %d characters long", constants.PasswordMinLength),
 			constants.ErrCodePasswordTooShort,
 		)
 	}
 
+	if len(password) > constants.PasswordMaxBytes {
+		return NewValidationError(
+			"Password is too long",
+			constants.ErrCodePasswordTooLong,
+		)
+	}
+
+	return nil
+}
+
+// ValidatePasswordPolicy validates a new password: length, not a common password,
+// and not the account's username, email, or email name
+func ValidatePasswordPolicy(password, username, email string) *ValidationError {
+	if err := ValidatePassword(password); err != nil {
+		return err
+	}
+
+	normalized := strings.ToLower(strings.TrimSpace(password))
+
+	if _, common := commonPasswords[normalized]; common {
+		return NewValidationError(
+			"This password is too common. Please choose a less predictable one",
+			constants.ErrCodeCommonPassword,
+		)
+	}
+
+	username = strings.ToLower(strings.TrimSpace(username))
+	email = strings.ToLower(strings.TrimSpace(email))
+	emailName, _, _ := strings.Cut(email, "@")
+	if (username != "" && normalized == username) || (email != "" && normalized == email) || (emailName != "" && normalized == emailName) {
+		return NewValidationError(
+			"Password must not be the same as your username or email",
+			constants.ErrCodePasswordSameAsUser,
+		)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,72 @@
+//go:build ignore
+// +build ignore
+
+// Migration script to add the reaction emoji column to story_likes.
+// Run with: go run migrations/add_story_like_emoji.go
+//
+// Required environment variables:
+// - DB_HOST: Database host
+// - DB_PORT: Database port (default: 5432)
+// - DB_NAME: Database name
+// - DB_USER: Database user
+// - DB_PASSWORD: Database password
+// - DB_SSL_MODE: SSL mode (default: require)
+//
+// story_likes is created by add_story_likes.go rather than by the server's auto-migration,
+// so run this before deploying story reactions. Existing likes become ❤️ reactions.
+//
+// This migration:
+// 1. Adds the emoji column to story_likes (if not exists)
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func main() {
+	dbHost := getEnv("DB_HOST", "")
+	dbPort := getEnv("DB_PORT", "5432")
+	dbName := getEnv("DB_NAME", "")
+	dbUser := getEnv("DB_USER", "")
+	dbPassword := getEnv("DB_PASSWORD", "")
+	dbSSLMode := getEnv("DB_SSL_MODE", "require")
+
+	if dbHost == "" || dbName == "" || dbUser == "" || dbPassword == "" {
+		log.Fatal("Missing required environment variables: DB_HOST, DB_NAME, DB_USER, DB_PASSWORD")
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s",
+		dbHost, dbPort, dbName, dbUser, dbPassword, dbSSLMode)
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	log.Println("Connected to database, starting migration...")
+
+	log.Println("Step 1: Adding emoji column to story_likes...")
+	if err := db.Exec(`
+		ALTER TABLE story_likes ADD COLUMN IF NOT EXISTS emoji varchar(16) NOT NULL DEFAULT '❤️'
+	`).Error; err != nil {
+		log.Fatalf("Failed to add emoji column: %v", err)
+	}
+	log.Println("✓ emoji column added")
+
+	log.Println("Migration completed successfully!")
+}
@@ -0,0 +1,76 @@
+//go:build ignore
+// +build ignore
+
+// Migration script to pin existing push subscriptions to a VAPID key ID.
+// Run with: go run migrations/backfill_push_vapid_key_id.go
+//
+// Required environment variables:
+// - DB_HOST: Database host
+// - DB_PORT: Database port (default: 5432)
+// - DB_NAME: Database name
+// - DB_USER: Database user
+// - DB_PASSWORD: Database password
+// - DB_SSL_MODE: SSL mode (default: require)
+// - VAPID_KEY_ID: Key ID of the keypair these subscriptions were created with (default: default)
+//
+// Run this BEFORE rotating keys for the first time, while VAPID_KEY_ID still names the
+// key existing subscriptions were created with. Afterwards move that keypair into
+// VAPID_PREVIOUS_KEYS under the same ID and the worker keeps signing their pushes with it.
+//
+// This migration:
+// 1. Sets vapid_key_id on push_subscriptions rows where it is empty
+package main
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func getEnv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+func main() {
+	dbHost := getEnv("DB_HOST", "")
+	dbPort := getEnv("DB_PORT", "5432")
+	dbName := getEnv("DB_NAME", "")
+	dbUser := getEnv("DB_USER", "")
+	dbPassword := getEnv("DB_PASSWORD", "")
+	dbSSLMode := getEnv("DB_SSL_MODE", "require")
+	keyID := getEnv("VAPID_KEY_ID", "default")
+
+	if dbHost == "" || dbName == "" || dbUser == "" || dbPassword == "" {
+		log.Fatal("Missing required environment variables: DB_HOST, DB_NAME, DB_USER, DB_PASSWORD")
+	}
+
+	dsn := fmt.Sprintf("host=%s port=%s dbname=%s user=%s password=%s sslmode=%s",
+		dbHost, dbPort, dbName, dbUser, dbPassword, dbSSLMode)
+
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Info),
+	})
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+
+	log.Println("Connected to database, starting migration...")
+
+	log.Printf("Step 1: Setting vapid_key_id to %q where empty...", keyID)
+	result := db.Exec(`
+		UPDATE push_subscriptions SET vapid_key_id = ? WHERE vapid_key_id IS NULL OR vapid_key_id = ''
+	`, keyID)
+	if result.Error != nil {
+		log.Fatalf("Failed to backfill vapid_key_id: %v", result.Error)
+	}
+	log.Printf("✓ %d subscriptions updated", result.RowsAffected)
+
+	log.Println("Migration completed successfully!")
+}
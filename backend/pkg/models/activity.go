@@ -68,6 +68,10 @@ type Activity struct {
 	CreatedAt    time.Time `gorm:"not null;default:now();autoCreateTime"`
 	UpdatedAt    time.Time `gorm:"not null;default:now();autoUpdateTime"`
 	ActivityDate time.Time `gorm:"type:date;default:CURRENT_DATE;index:idx_activities_user_date"`
+
+	// Set when the custom tile the activity was logged under is deleted with its history
+	// kept. Archived activities stay in history and exports but are left out of analytics.
+	ArchivedAt *time.Time `gorm:"default:null"`
 }
 
 // TableName specifies the table name for Activity
@@ -5,6 +5,19 @@ import (
 	"time"
 )
 
+// PhotoVisibility controls who can see an activity photo
+type PhotoVisibility string
+
+const (
+	PhotoVisibilityAllFollowers PhotoVisibility = "all_followers"
+	PhotoVisibilityCloseFriends PhotoVisibility = "close_friends"
+)
+
+// IsValid checks if the visibility is a known value
+func (v PhotoVisibility) IsValid() bool {
+	return v == PhotoVisibilityAllFollowers || v == PhotoVisibilityCloseFriends
+}
+
 // ActivityPhoto represents a photo uploaded for an activity on a specific day.
 // Each user can upload one photo per activity per day.
 type ActivityPhoto struct {
@@ -20,6 +33,12 @@ type ActivityPhoto struct {
 	ActivityColor *string   `gorm:"type:varchar(20)" json:"activity_color,omitempty"`
 	ActivityLabel *string   `gorm:"type:varchar(50)" json:"activity_label,omitempty"`
 	CreatedAt     time.Time `gorm:"not null;default:now();autoCreateTime" json:"created_at"`
+
+	// Visibility restricts the photo to close friends; existing rows default to all_followers
+	Visibility PhotoVisibility `gorm:"type:varchar(20);not null;default:'all_followers'" json:"visibility"`
+
+	// MimeType is the encoding of both the full image and thumbnail blobs
+	MimeType string `gorm:"type:varchar(20);not null;default:'image/jpeg'" json:"mime_type"`
 }
 
 // TableName specifies the table name for ActivityPhoto
@@ -43,6 +62,22 @@ func (StoryView) TableName() string {
 	return "story_views"
 }
 
+// StoryReply is a short DM-style reply sent to a story owner
+type StoryReply struct {
+	ID        uint          `gorm:"primaryKey" json:"id"`
+	PhotoID   uint          `gorm:"not null;index:idx_story_reply_photo_sender,priority:1" json:"photo_id"`
+	Photo     ActivityPhoto `gorm:"foreignKey:PhotoID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	SenderID  uint          `gorm:"not null;index:idx_story_reply_photo_sender,priority:2" json:"sender_id"`
+	Sender    User          `gorm:"foreignKey:SenderID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Text      string        `gorm:"type:varchar(500);not null" json:"text"`
+	CreatedAt time.Time     `gorm:"not null;default:now();autoCreateTime;index:idx_story_reply_photo_sender,priority:3" json:"created_at"`
+}
+
+// TableName specifies the table name for StoryReply
+func (StoryReply) TableName() string {
+	return "story_replies"
+}
+
 // PhotoViewer represents a user who viewed a photo (for API responses)
 type PhotoViewer struct {
 	UserID          uint      `json:"user_id"`
@@ -52,16 +87,26 @@ type PhotoViewer struct {
 	ViewedAt        time.Time `json:"viewed_at"`
 }
 
+// PhotoNonViewer represents a follower who has not viewed a photo (for API responses)
+type PhotoNonViewer struct {
+	UserID          uint      `json:"user_id"`
+	Username        string    `json:"username"`
+	ProfilePic      *string   `json:"profile_pic,omitempty"`
+	ProfilePicThumb *string   `json:"profile_pic_thumb,omitempty"`
+	FollowedAt      time.Time `json:"followed_at"`
+}
+
 // ActivityPhotoWithViews combines a photo with its view count
 type ActivityPhotoWithViews struct {
 	ActivityPhoto
 	ViewCount int64 `json:"view_count"`
 }
 
-// ActivityPhotoInStory extends ActivityPhoto with view status (for following stories)
+// ActivityPhotoInStory extends ActivityPhoto with view status and like count (for following stories)
 type ActivityPhotoInStory struct {
 	ActivityPhoto
-	Viewed bool `json:"viewed"`
+	Viewed    bool  `json:"viewed"`
+	LikeCount int64 `json:"like_count"`
 }
 
 // UserStoryGroup represents all photos from a single user for a date
@@ -72,6 +117,18 @@ type UserStoryGroup struct {
 	ProfilePicThumb *string                `json:"profile_pic_thumb,omitempty"`
 	Photos          []ActivityPhotoInStory `json:"photos"`
 	HasUnseen       bool                   `json:"has_unseen"`
+	LatestUploadAt  time.Time              `json:"latest_upload_at"`
+
+	// AllViewed is true once the viewer has seen every photo in the group (seen ring);
+	// ViewedCount tells a partially seen group apart from an unseen one
+	AllViewed   bool `json:"all_viewed"`
+	ViewedCount int  `json:"viewed_count"`
+}
+
+// StoryArchiveDay groups a user's photos for a single date (for the story archive)
+type StoryArchiveDay struct {
+	Date   string          `json:"date"`
+	Photos []ActivityPhoto `json:"photos"`
 }
 
 // StoryLike tracks who has liked a story photo.
@@ -83,6 +140,27 @@ type StoryLike struct {
 	PhotoID uint          `gorm:"not null;uniqueIndex:idx_story_like_unique,priority:2;index:idx_story_like_photo" json:"photo_id"`
 	Photo   ActivityPhoto `gorm:"foreignKey:PhotoID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
 	LikedAt time.Time     `gorm:"not null;default:now();autoCreateTime" json:"liked_at"`
+
+	// Emoji is the reaction; one per user per photo. Likes from before reactions are hearts.
+	Emoji string `gorm:"type:varchar(16);not null;default:'❤️'" json:"emoji"`
+}
+
+// DefaultStoryReaction is the reaction recorded by a plain like
+const DefaultStoryReaction = "❤️"
+
+// StoryReactions is the set of emoji a story can be reacted to with
+var StoryReactions = map[string]bool{
+	"❤️": true,
+	"🔥":  true,
+	"👏":  true,
+	"💪":  true,
+	"😍":  true,
+	"🎉":  true,
+}
+
+// IsValidStoryReaction checks if an emoji is an allowed story reaction
+func IsValidStoryReaction(emoji string) bool {
+	return StoryReactions[emoji]
 }
 
 // TableName specifies the table name for StoryLike
@@ -97,6 +175,7 @@ type PhotoLiker struct {
 	ProfilePic      *string   `json:"profile_pic,omitempty"`
 	ProfilePicThumb *string   `json:"profile_pic_thumb,omitempty"`
 	LikedAt         time.Time `json:"liked_at"`
+	Emoji           string    `json:"emoji"`
 }
 
 // PhotoInteraction represents a combined view/like entry (for API responses)
@@ -108,6 +187,15 @@ type PhotoInteraction struct {
 	InteractionType string    `json:"interaction_type"` // "view", "like", or "both"
 	ViewedAt        time.Time `json:"viewed_at,omitempty"`
 	LikedAt         time.Time `json:"liked_at,omitempty"`
+	Emoji           string    `json:"emoji,omitempty"` // Reaction, set when the user liked the photo
+}
+
+// SortTime is the time interactions are ordered by: the like if there is one, otherwise the view
+func (p *PhotoInteraction) SortTime() time.Time {
+	if !p.LikedAt.IsZero() {
+		return p.LikedAt
+	}
+	return p.ViewedAt
 }
 
 // PhotoUploadedMetadata holds data for photo_uploaded notifications
@@ -126,6 +214,7 @@ type StoryLikedMetadata struct {
 	LikerAvatar   string `json:"liker_avatar,omitempty"`
 	PhotoID       uint   `json:"photo_id"`
 	PhotoDate     string `json:"photo_date"`
+	Emoji         string `json:"emoji"`
 }
 
 // ToMap converts StoryLikedMetadata to NotificationMetadata
@@ -136,6 +225,31 @@ func (m StoryLikedMetadata) ToMap() NotificationMetadata {
 		"liker_avatar":   m.LikerAvatar,
 		"photo_id":       m.PhotoID,
 		"photo_date":     m.PhotoDate,
+		"emoji":          m.Emoji,
+	}
+}
+
+// StoryReplyMetadata holds data for story_reply notifications
+type StoryReplyMetadata struct {
+	ReplyID        uint   `json:"reply_id"`
+	SenderID       uint   `json:"sender_id"`
+	SenderUsername string `json:"sender_username"`
+	SenderAvatar   string `json:"sender_avatar,omitempty"`
+	PhotoID        uint   `json:"photo_id"`
+	PhotoDate      string `json:"photo_date"`
+	ReplyPreview   string `json:"reply_preview"`
+}
+
+// ToMap converts StoryReplyMetadata to NotificationMetadata
+func (m StoryReplyMetadata) ToMap() NotificationMetadata {
+	return NotificationMetadata{
+		"reply_id":        m.ReplyID,
+		"sender_id":       m.SenderID,
+		"sender_username": m.SenderUsername,
+		"sender_avatar":   m.SenderAvatar,
+		"photo_id":        m.PhotoID,
+		"photo_date":      m.PhotoDate,
+		"reply_preview":   m.ReplyPreview,
 	}
 }
 
@@ -15,6 +15,11 @@ type CronJobLog struct {
 	UsersCount  int       `gorm:"default:0"`                                                     // Number of users processed
 	Error       string    `gorm:"type:text"`                                                     // Error message if failed
 	InstanceID  string    `gorm:"size:100"`                                                      // Container/instance identifier for debugging multi-replica issues
+
+	// Claim attempts by other replicas that lost to InstanceID
+	SkipCount     int        `gorm:"not null;default:0"`
+	LastSkippedBy string     `gorm:"size:100"`
+	LastSkippedAt *time.Time `gorm:"default:null"`
 }
 
 // TableName specifies the table name for CronJobLog
@@ -28,6 +33,9 @@ const (
 	CronJobStreakReminder       = "streak_reminder"
 	CronJobNotificationCleanup  = "notification_cleanup"
 	CronJobFollowTombstoneClean = "follow_tombstone_cleanup"
+	CronJobFollowCounterRepair  = "follow_counter_repair"
+	CronJobWeeklyDigest         = "weekly_digest"
+	CronJobOrphanBlobCleanup    = "orphan_blob_cleanup"
 )
 
 // CronJobStatus constants
@@ -0,0 +1,34 @@
+package models
+
+import (
+	"time"
+)
+
+// CustomActivityType is an activity a user defined for their own tracking, beyond the
+// fixed ActivityNames. Activities logged under it are named custom:<TileID>. Rows mirror
+// the customTiles list of the user's tile config and are kept in sync when it is saved.
+type CustomActivityType struct {
+	ID     uint   `gorm:"primaryKey" json:"id"`
+	UserID uint   `gorm:"not null;uniqueIndex:idx_custom_activity_type_user_tile" json:"user_id"`
+	TileID string `gorm:"type:varchar(36);not null;uniqueIndex:idx_custom_activity_type_user_tile" json:"tile_id"` // UUID v4
+	Name   string `gorm:"type:varchar(20);not null" json:"name"`
+	Icon   string `gorm:"type:varchar(100);not null" json:"icon"`
+	Color  string `gorm:"type:varchar(9);not null" json:"color"`
+
+	// Timestamps
+	CreatedAt time.Time `gorm:"not null;default:now();autoCreateTime" json:"created_at"`
+	UpdatedAt time.Time `gorm:"not null;default:now();autoUpdateTime" json:"updated_at"`
+
+	// Foreign key relationship
+	User User `gorm:"foreignKey:UserID;constraint:OnDelete:CASCADE" json:"-"`
+}
+
+// TableName specifies the table name for CustomActivityType
+func (CustomActivityType) TableName() string {
+	return "custom_activity_types"
+}
+
+// ActivityName returns the name activities of this type are logged under
+func (t *CustomActivityType) ActivityName() ActivityName {
+	return ActivityName(CustomTilePrefix + t.TileID)
+}
@@ -12,6 +12,7 @@ const (
 	FollowStateActive  FollowState = "ACTIVE"
 	FollowStatePending FollowState = "PENDING"
 	FollowStateRemoved FollowState = "REMOVED"
+	FollowStateBlocked FollowState = "BLOCKED" // Either side blocked the other; cannot follow
 )
 
 // RelationshipState represents the relationship state for UI display
@@ -24,6 +25,24 @@ const (
 	RelationshipIncomingPending RelationshipState = "INCOMING_PENDING"
 )
 
+// RelationshipPair holds both directions of a relationship between a viewer and a target.
+// Outgoing is viewer -> target, Incoming is target -> viewer (FOLLOWING, REQUESTED or NONE).
+type RelationshipPair struct {
+	Outgoing RelationshipState `json:"outgoing_state"`
+	Incoming RelationshipState `json:"incoming_state"`
+}
+
+// Combined collapses the pair into the single legacy relationship state
+func (p RelationshipPair) Combined() RelationshipState {
+	if p.Outgoing != RelationshipNone && p.Outgoing != "" {
+		return p.Outgoing
+	}
+	if p.Incoming == RelationshipRequested {
+		return RelationshipIncomingPending
+	}
+	return RelationshipNone
+}
+
 // FollowEdgeByFollower represents a follow relationship indexed by follower
 // This table is optimized for queries like "who does user X follow?"
 type FollowEdgeByFollower struct {
@@ -56,6 +75,32 @@ func (FollowEdgeByFollowee) TableName() string {
 	return "follow_edges_by_followee"
 }
 
+// UserBlock records that BlockerID has blocked BlockedID.
+// While any block exists between two users, their edges in both directions are BLOCKED.
+type UserBlock struct {
+	BlockerID uint      `gorm:"primaryKey;not null;index:idx_user_block_blocker_created,priority:1" json:"blocker_id"`
+	BlockedID uint      `gorm:"primaryKey;not null;index:idx_user_block_blocked" json:"blocked_id"`
+	CreatedAt time.Time `gorm:"not null;default:now();autoCreateTime;index:idx_user_block_blocker_created,priority:2,sort:desc" json:"created_at"`
+}
+
+// TableName specifies the table name for UserBlock
+func (UserBlock) TableName() string {
+	return "user_blocks"
+}
+
+// CloseFriend records that FriendID is on OwnerID's close friends list.
+// Close-friends-only stories are visible to these users only.
+type CloseFriend struct {
+	OwnerID   uint      `gorm:"primaryKey;not null;index:idx_close_friend_owner_created,priority:1" json:"owner_id"`
+	FriendID  uint      `gorm:"primaryKey;not null" json:"friend_id"`
+	CreatedAt time.Time `gorm:"not null;default:now();autoCreateTime;index:idx_close_friend_owner_created,priority:2,sort:desc" json:"created_at"`
+}
+
+// TableName specifies the table name for CloseFriend
+func (CloseFriend) TableName() string {
+	return "close_friends"
+}
+
 // FollowCounter stores aggregated follow counts for a user
 // Updated asynchronously via events for eventual consistency
 type FollowCounter struct {
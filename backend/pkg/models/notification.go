@@ -6,6 +6,8 @@ import (
 	"encoding/json"
 	"errors"
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // NotificationType represents the type of notification
@@ -22,12 +24,28 @@ const (
 	NotifTypeNewFollower     NotificationType = "new_follower"
 	NotifTypePhotoUploaded   NotificationType = "photo_uploaded"
 	NotifTypeStoryLiked      NotificationType = "story_liked"
+	NotifTypeStoryReply      NotificationType = "story_reply"
 	NotifTypeCommentReceived NotificationType = "comment_received"
 	NotifTypeCommentReply    NotificationType = "comment_reply"
 	NotifTypeCommentMention  NotificationType = "comment_mention"
 	NotifTypeCommentLiked    NotificationType = "comment_liked"
+
+	// NotifTypeVerificationApproved is sent when an admin approves a verification request
+	NotifTypeVerificationApproved NotificationType = "verification_approved"
 )
 
+// IsValid checks if the notification type is a known value
+func (t NotificationType) IsValid() bool {
+	switch t {
+	case NotifTypeLikeReceived, NotifTypeBadgeUnlocked, NotifTypeStreakMilestone, NotifTypeStreakAtRisk,
+		NotifTypeSystemAnnounce, NotifTypeFollowRequest, NotifTypeFollowAccepted, NotifTypeNewFollower,
+		NotifTypePhotoUploaded, NotifTypeStoryLiked, NotifTypeStoryReply, NotifTypeCommentReceived,
+		NotifTypeCommentReply, NotifTypeCommentMention, NotifTypeCommentLiked, NotifTypeVerificationApproved:
+		return true
+	}
+	return false
+}
+
 // NotificationMetadata is a flexible JSON field for notification-specific data
 type NotificationMetadata map[string]interface{}
 
@@ -55,13 +73,16 @@ func (m *NotificationMetadata) Scan(value interface{}) error {
 // Notification represents a user notification
 type Notification struct {
 	ID        uint                 `gorm:"primaryKey" json:"id"`
-	UserID    uint                 `gorm:"not null;index:idx_notif_user_created;index:idx_notif_user_unread" json:"user_id"`
+	UserID    uint                 `gorm:"not null;index:idx_notif_user_created;index:idx_notif_user_unread;index:idx_notif_user_seq,priority:1" json:"user_id"`
 	Type      NotificationType     `gorm:"type:varchar(50);not null" json:"type"`
 	Title     string               `gorm:"type:varchar(255);not null" json:"title"`
 	Body      string               `gorm:"type:text" json:"body"`
 	Metadata  NotificationMetadata `gorm:"type:jsonb" json:"metadata"`
 	ReadAt    *time.Time           `gorm:"index:idx_notif_user_unread" json:"read_at"`
 	CreatedAt time.Time            `gorm:"not null;default:now();autoCreateTime;index:idx_notif_user_created,sort:desc" json:"created_at"`
+
+	// Seq increases monotonically per user; clients resume from the last seq they saw
+	Seq int64 `gorm:"not null;default:0;index:idx_notif_user_seq,priority:2" json:"seq"`
 }
 
 // TableName specifies the table name for Notification
@@ -69,11 +90,47 @@ func (Notification) TableName() string {
 	return "notifications"
 }
 
+// BeforeCreate is a GORM hook that assigns the next per-user sequence number.
+// The upsert holds the user's sequence row lock until the surrounding transaction
+// commits, so sequence order always matches commit order.
+func (n *Notification) BeforeCreate(tx *gorm.DB) error {
+	return tx.Raw(`INSERT INTO notification_sequences (user_id, seq) VALUES (?, 1)
+		ON CONFLICT (user_id) DO UPDATE SET seq = notification_sequences.seq + 1
+		RETURNING seq`, n.UserID).Scan(&n.Seq).Error
+}
+
+// NotificationSequence stores the last notification sequence number issued per user
+type NotificationSequence struct {
+	UserID uint  `gorm:"primaryKey;autoIncrement:false"`
+	Seq    int64 `gorm:"not null;default:0"`
+}
+
+// TableName specifies the table name for NotificationSequence
+func (NotificationSequence) TableName() string {
+	return "notification_sequences"
+}
+
 // IsRead returns whether the notification has been read
 func (n *Notification) IsRead() bool {
 	return n.ReadAt != nil
 }
 
+// NotificationActor identifies a user who triggered a notification
+type NotificationActor struct {
+	ID       uint
+	Username string
+	Avatar   string
+}
+
+// NotificationGroup collapses consecutive notifications of the same type about the same
+// entity (e.g. several people liking the same day). Latest is the most recent member.
+type NotificationGroup struct {
+	Latest Notification
+	IDs    []uint
+	Actors []NotificationActor
+	Unread bool
+}
+
 // ==================== Metadata Helper Types ====================
 
 // LikeMetadata holds data for like_received notifications
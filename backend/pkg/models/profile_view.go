@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// ProfileView records the latest time ViewerID viewed TargetID's profile.
+// One row per viewer/target pair; repeat views refresh ViewedAt once the throttle window has passed.
+type ProfileView struct {
+	ID       uint      `gorm:"primaryKey" json:"id"`
+	ViewerID uint      `gorm:"not null;uniqueIndex:idx_profile_view_unique,priority:1" json:"viewer_id"`
+	Viewer   User      `gorm:"foreignKey:ViewerID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	TargetID uint      `gorm:"not null;uniqueIndex:idx_profile_view_unique,priority:2;index:idx_profile_view_target_time,priority:1" json:"target_id"`
+	Target   User      `gorm:"foreignKey:TargetID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	ViewedAt time.Time `gorm:"not null;default:now();index:idx_profile_view_target_time,priority:2,sort:desc" json:"viewed_at"`
+}
+
+// TableName specifies the table name for ProfileView
+func (ProfileView) TableName() string {
+	return "profile_views"
+}
+
+// ProfileViewer represents a user who viewed a profile (for API responses)
+type ProfileViewer struct {
+	UserID          uint      `json:"user_id"`
+	Username        string    `json:"username"`
+	ProfilePic      *string   `json:"profile_pic,omitempty"`
+	ProfilePicThumb *string   `json:"profile_pic_thumb,omitempty"`
+	IsVerified      bool      `json:"is_verified"`
+	ViewedAt        time.Time `json:"viewed_at"`
+}
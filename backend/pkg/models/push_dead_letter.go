@@ -0,0 +1,34 @@
+// Package models defines the domain entities for the application.
+package models
+
+import (
+	"time"
+)
+
+// PushDeadLetter keeps a push message the worker gave up on, so operators can inspect repeated failures
+type PushDeadLetter struct {
+	ID               uint   `gorm:"primaryKey" json:"id"`
+	MessageID        string `gorm:"type:varchar(100);index:idx_push_dead_letter_message" json:"message_id"` // Service Bus message ID
+	UserID           uint   `gorm:"index:idx_push_dead_letter_user" json:"user_id"`                         // 0 when the body couldn't be parsed
+	NotificationType string `gorm:"type:varchar(50)" json:"notification_type"`
+	Body             string `gorm:"type:text;not null" json:"body"` // Raw message body as received
+
+	// Why the message was dropped
+	Reason        string `gorm:"type:varchar(50);not null;index:idx_push_dead_letter_reason" json:"reason"`
+	Error         string `gorm:"type:text" json:"error"`
+	DeliveryCount uint32 `gorm:"not null" json:"delivery_count"`
+
+	// Timestamp
+	CreatedAt time.Time `gorm:"not null;default:now();autoCreateTime;index:idx_push_dead_letter_created,sort:desc" json:"created_at"`
+}
+
+// TableName specifies the table name for PushDeadLetter
+func (PushDeadLetter) TableName() string {
+	return "push_dead_letters"
+}
+
+// PushDeadLetter reasons
+const (
+	PushDeadLetterMaxDeliveries = "max_delivery_attempts"
+	PushDeadLetterUnmarshal     = "unmarshal_failed"
+)
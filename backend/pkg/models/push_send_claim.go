@@ -0,0 +1,23 @@
+// Package models defines the domain entities for the application.
+package models
+
+import (
+	"time"
+)
+
+// PushSendClaim is written before a push is sent to a subscription, so a worker that
+// crashes between the HTTP send and the delivery log write doesn't resend on restart.
+// Claims are released again when the send fails with a retryable error.
+type PushSendClaim struct {
+	MessageID      string `gorm:"type:varchar(100);primaryKey" json:"message_id"` // PushMessage ID, stable across retries
+	SubscriptionID uint   `gorm:"primaryKey" json:"subscription_id"`
+	UserID         uint   `gorm:"not null;index:idx_push_claim_user" json:"user_id"`
+
+	// Timestamp
+	CreatedAt time.Time `gorm:"not null;default:now();autoCreateTime;index:idx_push_claim_created" json:"created_at"`
+}
+
+// TableName specifies the table name for PushSendClaim
+func (PushSendClaim) TableName() string {
+	return "push_send_claims"
+}
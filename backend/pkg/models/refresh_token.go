@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// RefreshToken is a long-lived credential exchanged for new access tokens.
+// Only the SHA-256 hash of the token is stored; each refresh rotates the token,
+// revoking the old row and issuing a new one in the same session.
+type RefreshToken struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`
+	User      User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	SessionID uint       `gorm:"not null;index"`
+	Session   Session    `gorm:"foreignKey:SessionID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	TokenHash string     `gorm:"size:64;not null;uniqueIndex"`
+	ExpiresAt time.Time  `gorm:"not null"`
+	RevokedAt *time.Time `gorm:"default:null"`
+	CreatedAt time.Time  `gorm:"not null;default:now();autoCreateTime"`
+}
+
+// TableName specifies the table name for RefreshToken
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
+
+// IsActive reports whether the token can still be exchanged
+func (t *RefreshToken) IsActive() bool {
+	return t.RevokedAt == nil && time.Now().Before(t.ExpiresAt)
+}
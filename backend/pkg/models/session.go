@@ -0,0 +1,21 @@
+package models
+
+import "time"
+
+// Session is one logged-in device. It is created at login and lives as long as its
+// refresh token chain; revoking it invalidates the session's refresh and access tokens.
+type Session struct {
+	ID         uint       `gorm:"primaryKey" json:"id"`
+	UserID     uint       `gorm:"not null;index" json:"-"`
+	User       User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	UserAgent  string     `gorm:"size:255;not null;default:''" json:"user_agent"`
+	IPAddress  string     `gorm:"size:45;not null;default:''" json:"ip_address"`
+	CreatedAt  time.Time  `gorm:"not null;default:now();autoCreateTime" json:"created_at"`
+	LastSeenAt time.Time  `gorm:"not null;default:now()" json:"last_seen_at"`
+	RevokedAt  *time.Time `gorm:"default:null" json:"-"`
+}
+
+// TableName specifies the table name for Session
+func (Session) TableName() string {
+	return "sessions"
+}
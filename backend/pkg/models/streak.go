@@ -11,9 +11,32 @@ type Streak struct {
 	Current      int       `gorm:"not null;default:0"`
 	Longest      int       `gorm:"not null;default:0"`
 	ActivityDate time.Time `gorm:"type:date;default:CURRENT_DATE;index:idx_streaks_user_date"`
+
+	// ActivityName scopes the streak to a single activity type; nil is the aggregate (any activity) streak
+	ActivityName *ActivityName `gorm:"type:varchar(50);index"`
+
+	// Frozen marks a missed day that was forgiven by consuming a streak freeze
+	Frozen bool `gorm:"not null;default:false"`
 }
 
 // TableName specifies the table name for Streak
 func (Streak) TableName() string {
 	return "streaks"
 }
+
+// StreakFreeze tracks a user's balance of streak freezes.
+// A freeze forgives a single missed day so the current streak carries over.
+type StreakFreeze struct {
+	UserID        uint       `gorm:"primaryKey"`
+	User          User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Available     int        `gorm:"not null;default:0"`
+	LastUsedAt    *time.Time `gorm:"default:null"`
+	LastUsedFor   *time.Time `gorm:"type:date;default:null"` // The missed day that was forgiven
+	LastGrantedAt *time.Time `gorm:"default:null"`
+	UpdatedAt     time.Time
+}
+
+// TableName specifies the table name for StreakFreeze
+func (StreakFreeze) TableName() string {
+	return "streak_freezes"
+}
@@ -49,6 +49,22 @@ type TileConfigData struct {
 	CustomTiles []CustomTile      `json:"customTiles,omitempty"` // User-defined custom tiles
 }
 
+// TileConfigKeys are the top-level keys allowed in a saved tile config
+var TileConfigKeys = map[string]bool{
+	"order":       true,
+	"sizes":       true,
+	"hidden":      true,
+	"colors":      true,
+	"customTiles": true,
+}
+
+// TileSizes are the tile sizes supported by the dashboard grid
+var TileSizes = map[string]bool{
+	"small":  true,
+	"medium": true,
+	"wide":   true,
+}
+
 // ValidateColor checks if a color is a valid hex color
 func ValidateColor(color string) bool {
 	if len(color) == 0 {
@@ -126,6 +142,69 @@ func (tc *TileConfig) GetConfigData() (*TileConfigData, error) {
 	return &configData, nil
 }
 
+// HasCustomTile reports whether the config defines a custom tile with the given ID
+func (d *TileConfigData) HasCustomTile(id string) bool {
+	for _, tile := range d.CustomTiles {
+		if tile.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// DefaultTileConfigData returns the dashboard layout a user gets before they customize it:
+// every predefined activity in its standard order, using the same default sizes as the client
+func DefaultTileConfigData() *TileConfigData {
+	defaultSizes := map[ActivityName]string{
+		ActivitySleep:  "medium",
+		ActivityStudy:  "wide",
+		ActivityEating: "wide",
+	}
+
+	data := &TileConfigData{
+		Order: make([]string, 0, len(ActivityNames)),
+		Sizes: make(map[string]string, len(ActivityNames)),
+	}
+	for _, name := range ActivityNames {
+		data.Order = append(data.Order, string(name))
+		size, ok := defaultSizes[name]
+		if !ok {
+			size = "small"
+		}
+		data.Sizes[string(name)] = size
+	}
+	return data
+}
+
+// RemoveCustomTile drops the custom tile with the given ID and every reference to it
+func (d *TileConfigData) RemoveCustomTile(id string) {
+	name := CustomTilePrefix + id
+
+	tiles := d.CustomTiles[:0]
+	for _, tile := range d.CustomTiles {
+		if tile.ID != id {
+			tiles = append(tiles, tile)
+		}
+	}
+	d.CustomTiles = tiles
+
+	d.Order = removeString(d.Order, name)
+	d.Hidden = removeString(d.Hidden, name)
+	delete(d.Sizes, name)
+	delete(d.Colors, name)
+}
+
+// removeString returns list without any occurrence of s
+func removeString(list []string, s string) []string {
+	out := list[:0]
+	for _, v := range list {
+		if v != s {
+			out = append(out, v)
+		}
+	}
+	return out
+}
+
 // SetConfigData converts TileConfigData to JSONB and sets it
 func (tc *TileConfig) SetConfigData(data *TileConfigData) error {
 	jsonData, err := json.Marshal(data)
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// TwoFactorBackupCode is a single-use recovery code for signing in without the
+// authenticator app. Only the SHA-256 hash of the code is stored.
+type TwoFactorBackupCode struct {
+	ID        uint       `gorm:"primaryKey"`
+	UserID    uint       `gorm:"not null;index"`
+	User      User       `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	CodeHash  string     `gorm:"size:64;not null"`
+	UsedAt    *time.Time `gorm:"default:null"`
+	CreatedAt time.Time  `gorm:"not null;default:now();autoCreateTime"`
+}
+
+// TableName specifies the table name for TwoFactorBackupCode
+func (TwoFactorBackupCode) TableName() string {
+	return "two_factor_backup_codes"
+}
@@ -15,8 +15,38 @@ type User struct {
 	IsPrivate       bool      `gorm:"default:false"`
 	IsVerified      bool      `gorm:"default:false"` // Whether user has verified badge (Instagram-like)
 	EmailVerified   bool      `gorm:"default:false"` // Whether user has verified their email address
+	GhostMode       bool      `gorm:"default:false"` // When true, this user's story views are not recorded (prospective only)
 	CreatedAt       time.Time `gorm:"not null;default:now();autoCreateTime"`
 	UpdatedAt       time.Time `gorm:"not null;default:now();autoUpdateTime"`
+
+	// Timezone is the IANA zone used for streak day boundaries and reminders
+	Timezone string `gorm:"type:varchar(64);not null;default:'Asia/Kolkata'"`
+
+	// IsDeactivated hides the account from everyone else while keeping its data;
+	// the owner can still log in and reactivate
+	IsDeactivated bool       `gorm:"not null;default:false;index"`
+	DeactivatedAt *time.Time `gorm:"default:null"`
+
+	// WeeklyDigestOptOut stops the Monday weekly progress email
+	WeeklyDigestOptOut bool `gorm:"not null;default:false"`
+
+	// HideProfileViews keeps this user out of others' profile viewer lists
+	HideProfileViews bool `gorm:"not null;default:false"`
+
+	// IsAdmin grants access to admin-only endpoints; set directly in the database
+	IsAdmin bool `gorm:"not null;default:false"`
+
+	// TokenVersion is embedded in access tokens; bumping it ("log out everywhere")
+	// invalidates every token issued before
+	TokenVersion int `gorm:"not null;default:0"`
+
+	// TwoFactorSecret is the AES-GCM encrypted TOTP secret. It is set at enrollment and
+	// only enforced at login once TwoFactorEnabled is true (after the first code is verified).
+	TwoFactorSecret  *string `gorm:"type:text;default:null" json:"-"`
+	TwoFactorEnabled bool    `gorm:"not null;default:false"`
+
+	// UsernameChangedAt is when the username was last changed; changes are rate limited
+	UsernameChangedAt *time.Time `gorm:"default:null"`
 }
 
 // TableName specifies the table name for User
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// UsernameHistory records a username a user gave up. Until ReleasedAt the old name
+// stays reserved for its previous owner, so nobody else can pick it up to impersonate them.
+type UsernameHistory struct {
+	ID         uint      `gorm:"primaryKey"`
+	UserID     uint      `gorm:"not null;index"`
+	User       User      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE"`
+	Username   string    `gorm:"not null;index"`
+	ChangedAt  time.Time `gorm:"not null;default:now()"`
+	ReleasedAt time.Time `gorm:"not null;index"`
+}
+
+// TableName specifies the table name for UsernameHistory
+func (UsernameHistory) TableName() string {
+	return "username_history"
+}
@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// VerificationRequestStatus represents the review state of a verification request
+type VerificationRequestStatus string
+
+const (
+	VerificationStatusPending  VerificationRequestStatus = "pending"
+	VerificationStatusApproved VerificationRequestStatus = "approved"
+	VerificationStatusDenied   VerificationRequestStatus = "denied"
+)
+
+// VerificationRequest is a user's request for the verified badge, reviewed by an admin.
+// A user can have at most one pending request; reviewed requests are kept as history.
+type VerificationRequest struct {
+	ID            uint                      `gorm:"primaryKey" json:"id"`
+	UserID        uint                      `gorm:"not null;index;uniqueIndex:idx_verification_request_pending,where:status = 'pending'" json:"user_id"`
+	User          User                      `gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE" json:"-"`
+	Justification string                    `gorm:"type:text;not null" json:"justification"`
+	Status        VerificationRequestStatus `gorm:"type:varchar(20);not null;default:'pending';index" json:"status"`
+	ReviewedBy    *uint                     `gorm:"default:null" json:"reviewed_by,omitempty"`
+	ReviewNote    *string                   `gorm:"type:text;default:null" json:"review_note,omitempty"`
+	CreatedAt     time.Time                 `gorm:"not null;default:now();autoCreateTime" json:"created_at"`
+	ReviewedAt    *time.Time                `gorm:"default:null" json:"reviewed_at,omitempty"`
+}
+
+// TableName specifies the table name for VerificationRequest
+func (VerificationRequest) TableName() string {
+	return "verification_requests"
+}
+
+// VerificationRequestWithUser is a verification request joined with the requester (admin review queue)
+type VerificationRequestWithUser struct {
+	VerificationRequest
+	Username      string `json:"username"`
+	EmailVerified bool   `json:"email_verified"`
+}
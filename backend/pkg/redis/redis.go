@@ -7,6 +7,8 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/aman1117/backend/internal/constants"
@@ -159,6 +161,221 @@ func DeleteResetToken(ctx context.Context, rawToken string) error {
 	return client.Del(ctx, key).Err()
 }
 
+// ==================== Token Version Functions ====================
+
+// GetTokenVersion returns a user's cached token version; found is false on a cache miss
+func GetTokenVersion(ctx context.Context, userID uint) (version int, found bool, err error) {
+	if client == nil {
+		return 0, false, nil
+	}
+
+	version, err = client.Get(ctx, fmt.Sprintf("%s%d", constants.TokenVersionPrefix, userID)).Int()
+	if err == goredis.Nil {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to get token version: %w", err)
+	}
+	return version, true, nil
+}
+
+// SetTokenVersion writes a user's new token version through to the cache after it changes,
+// replacing any cached value
+func SetTokenVersion(ctx context.Context, userID uint, version int) error {
+	if client == nil {
+		return nil
+	}
+	return client.Set(ctx, fmt.Sprintf("%s%d", constants.TokenVersionPrefix, userID), version, constants.TokenVersionCacheTTL).Err()
+}
+
+// CacheTokenVersion fills a cache miss with a token version read from the database. It never
+// overwrites a cached value, so a read that raced a version bump cannot replace the newer
+// version SetTokenVersion wrote.
+func CacheTokenVersion(ctx context.Context, userID uint, version int) error {
+	if client == nil {
+		return nil
+	}
+	return client.SetNX(ctx, fmt.Sprintf("%s%d", constants.TokenVersionPrefix, userID), version, constants.TokenVersionCacheTTL).Err()
+}
+
+// InvalidateTokenVersion drops a user's cached token version after it changes
+func InvalidateTokenVersion(ctx context.Context, userID uint) error {
+	if client == nil {
+		return nil
+	}
+	return client.Del(ctx, fmt.Sprintf("%s%d", constants.TokenVersionPrefix, userID)).Err()
+}
+
+// ==================== Session Functions ====================
+
+// GetSessionActive returns a session's cached status; found is false on a cache miss
+func GetSessionActive(ctx context.Context, sessionID uint) (active bool, found bool, err error) {
+	if client == nil {
+		return false, false, nil
+	}
+
+	val, err := client.Get(ctx, fmt.Sprintf("%s%d", constants.SessionStatusPrefix, sessionID)).Result()
+	if err == goredis.Nil {
+		return false, false, nil
+	}
+	if err != nil {
+		return false, false, fmt.Errorf("failed to get session status: %w", err)
+	}
+	return val == "1", true, nil
+}
+
+// SetSessionActive writes a session's new status through to the cache, replacing any cached value
+func SetSessionActive(ctx context.Context, sessionID uint, active bool) error {
+	if client == nil {
+		return nil
+	}
+	return client.Set(ctx, fmt.Sprintf("%s%d", constants.SessionStatusPrefix, sessionID), sessionStatusValue(active), constants.SessionStatusCacheTTL).Err()
+}
+
+// CacheSessionActive fills a cache miss with a session status read from the database. It never
+// overwrites a cached value, so a read that raced a revocation cannot mark the session active again.
+func CacheSessionActive(ctx context.Context, sessionID uint, active bool) error {
+	if client == nil {
+		return nil
+	}
+	return client.SetNX(ctx, fmt.Sprintf("%s%d", constants.SessionStatusPrefix, sessionID), sessionStatusValue(active), constants.SessionStatusCacheTTL).Err()
+}
+
+func sessionStatusValue(active bool) string {
+	if active {
+		return "1"
+	}
+	return "0"
+}
+
+// MarkSessionSeen returns true at most once per SessionSeenInterval for a session, so
+// callers only write last_seen_at when it is due. Always false without Redis.
+func MarkSessionSeen(ctx context.Context, sessionID uint) (bool, error) {
+	if client == nil {
+		return false, nil
+	}
+
+	ok, err := client.SetNX(ctx, fmt.Sprintf("%s%d", constants.SessionSeenPrefix, sessionID), 1, constants.SessionSeenInterval).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark session seen: %w", err)
+	}
+	return ok, nil
+}
+
+// ==================== Two-Factor Functions ====================
+
+// MarkTOTPStepUsed records that a user's TOTP code for a time step was used.
+// Returns false if it was already used, so a code cannot be replayed within its validity.
+// Always true without Redis.
+func MarkTOTPStepUsed(ctx context.Context, userID uint, step int64, ttl time.Duration) (bool, error) {
+	if client == nil {
+		return true, nil
+	}
+
+	ok, err := client.SetNX(ctx, fmt.Sprintf("%s%d:%d", constants.TOTPUsedPrefix, userID, step), 1, ttl).Result()
+	if err != nil {
+		return false, fmt.Errorf("failed to mark totp step used: %w", err)
+	}
+	return ok, nil
+}
+
+// ==================== Login Lockout Functions ====================
+
+// GetLoginLockTTL returns how long a user's account stays locked, or 0 if it is not locked
+func GetLoginLockTTL(ctx context.Context, userID uint) (time.Duration, error) {
+	if client == nil {
+		return 0, nil
+	}
+
+	ttl, err := client.PTTL(ctx, fmt.Sprintf("%s%d", constants.LoginLockPrefix, userID)).Result()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get login lock: %w", err)
+	}
+	if ttl < 0 {
+		// -2: no lock, -1: lock without expiry (never set by us)
+		return 0, nil
+	}
+	return ttl, nil
+}
+
+// RecordLoginFailure counts a failed login attempt. Once maxAttempts consecutive failures
+// accumulate within window, the account is locked for lockout and the counter resets.
+// Returns true if this attempt triggered the lock.
+func RecordLoginFailure(ctx context.Context, userID uint, maxAttempts int, window, lockout time.Duration) (bool, error) {
+	if client == nil || maxAttempts <= 0 {
+		return false, nil
+	}
+
+	failuresKey := fmt.Sprintf("%s%d", constants.LoginFailuresPrefix, userID)
+	lockKey := fmt.Sprintf("%s%d", constants.LoginLockPrefix, userID)
+
+	// Increment and lock atomically so concurrent guesses can't overshoot the threshold unlocked
+	script := `
+		local n = redis.call("INCR", KEYS[1])
+		if n == 1 then
+			redis.call("PEXPIRE", KEYS[1], ARGV[1])
+		end
+		if n >= tonumber(ARGV[2]) then
+			redis.call("SET", KEYS[2], "1", "PX", ARGV[3])
+			redis.call("DEL", KEYS[1])
+			return 1
+		end
+		return 0
+	`
+	locked, err := client.Eval(ctx, script, []string{failuresKey, lockKey},
+		window.Milliseconds(), maxAttempts, lockout.Milliseconds()).Int()
+	if err != nil {
+		return false, fmt.Errorf("failed to record login failure: %w", err)
+	}
+	return locked == 1, nil
+}
+
+// ClearLoginFailures resets a user's failed attempt counter after a successful login
+func ClearLoginFailures(ctx context.Context, userID uint) error {
+	if client == nil {
+		return nil
+	}
+	return client.Del(ctx, fmt.Sprintf("%s%d", constants.LoginFailuresPrefix, userID)).Err()
+}
+
+// ==================== Rate Limit Functions ====================
+
+// AllowRequest records a request against a sliding-window limit of max requests per window
+// for key. Returns whether the request is allowed and, if not, how long until the oldest
+// request in the window expires. Timestamps come from the Redis clock so every instance
+// agrees on the window. Returns an error when Redis is not configured so callers can fall
+// back to a local limiter.
+func AllowRequest(ctx context.Context, key string, max int, window time.Duration) (bool, time.Duration, error) {
+	if client == nil {
+		return false, 0, fmt.Errorf("redis not configured")
+	}
+
+	member := make([]byte, 8)
+	if _, err := rand.Read(member); err != nil {
+		return false, 0, fmt.Errorf("failed to generate rate limit member: %w", err)
+	}
+
+	script := `
+		local t = redis.call("TIME")
+		local now = tonumber(t[1]) * 1000 + math.floor(tonumber(t[2]) / 1000)
+		local window = tonumber(ARGV[1])
+		redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - window)
+		if redis.call("ZCARD", KEYS[1]) < tonumber(ARGV[2]) then
+			redis.call("ZADD", KEYS[1], now, ARGV[3])
+			redis.call("PEXPIRE", KEYS[1], window)
+			return {1, 0}
+		end
+		local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+		return {0, tonumber(oldest[2]) + window - now}
+	`
+	res, err := client.Eval(ctx, script, []string{constants.RateLimitPrefix + key},
+		window.Milliseconds(), max, hex.EncodeToString(member)).Int64Slice()
+	if err != nil {
+		return false, 0, fmt.Errorf("failed to check rate limit: %w", err)
+	}
+	return res[0] == 1, time.Duration(res[1]) * time.Millisecond, nil
+}
+
 // ==================== Likes Cache Functions ====================
 
 // LikesCacheKey generates the Redis key for likes cache
@@ -306,19 +523,81 @@ func CheckVerifyResendCooldown(ctx context.Context, userID uint) (bool, error) {
 	return true, nil // In cooldown
 }
 
+// ==================== Email Change Token Functions ====================
+
+// StoreEmailChangeToken stores a pending email change for a user.
+// The token hash is also recorded as the user's latest request, so issuing a
+// new token invalidates any earlier one.
+func StoreEmailChangeToken(ctx context.Context, tokenHash string, userID uint, newEmail string) error {
+	if client == nil {
+		return fmt.Errorf("redis client not initialized")
+	}
+
+	key := constants.EmailChangeTokenPrefix + tokenHash
+	value := fmt.Sprintf("%d:%s", userID, newEmail)
+	pendingKey := fmt.Sprintf("%s%d", constants.EmailChangePendingPrefix, userID)
+
+	pipe := client.TxPipeline()
+	pipe.Set(ctx, key, value, constants.EmailChangeTokenTTL)
+	pipe.Set(ctx, pendingKey, tokenHash, constants.EmailChangeTokenTTL)
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+// ConsumeEmailChangeToken validates and deletes an email change token (single-use).
+// Returns 0 and an empty email if the token is unknown, expired or superseded.
+func ConsumeEmailChangeToken(ctx context.Context, rawToken string) (uint, string, error) {
+	if client == nil {
+		return 0, "", fmt.Errorf("redis client not initialized")
+	}
+
+	tokenHash := HashToken(rawToken)
+	key := constants.EmailChangeTokenPrefix + tokenHash
+
+	value, err := client.GetDel(ctx, key).Result()
+	if err == goredis.Nil {
+		return 0, "", nil // Token not found or expired
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get/delete email change token: %w", err)
+	}
+
+	var userID uint
+	var newEmail string
+	if _, err := fmt.Sscanf(value, "%d:%s", &userID, &newEmail); err != nil {
+		return 0, "", fmt.Errorf("failed to parse email change token: %w", err)
+	}
+
+	// Only the latest request for this user may be confirmed
+	pendingKey := fmt.Sprintf("%s%d", constants.EmailChangePendingPrefix, userID)
+	latest, err := client.Get(ctx, pendingKey).Result()
+	if err == goredis.Nil || (err == nil && latest != tokenHash) {
+		return 0, "", nil
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to check pending email change: %w", err)
+	}
+	client.Del(ctx, pendingKey)
+
+	return userID, newEmail, nil
+}
+
 // ==================== Autocomplete Cache Functions ====================
 
 const (
 	// AutocompleteCachePrefix is the key prefix for autocomplete results
 	AutocompleteCachePrefix = "autocomplete:users:"
-	// AutocompleteCacheTTL is the cache duration for autocomplete results
-	AutocompleteCacheTTL = 60 * time.Second
+	// AutocompleteCacheTTL is the cache duration for autocomplete results.
+	// Entries are not invalidated on username changes or follows; the short TTL bounds how
+	// long stale names or ordering can be served. Only viewers with no blocks use the cache.
+	AutocompleteCacheTTL = 45 * time.Second
 )
 
-// AutocompleteCacheKey generates the Redis key for autocomplete cache
-// Normalizes the query to lowercase and trims whitespace
+// AutocompleteCacheKey generates the Redis key for autocomplete cache.
+// Normalizes the query to lowercase and trims whitespace. Cached results are shared by
+// every viewer with no blocks and hold enough matches for any limit.
 func AutocompleteCacheKey(query string) string {
-	return AutocompleteCachePrefix + query
+	return AutocompleteCachePrefix + strings.ToLower(strings.TrimSpace(query))
 }
 
 // GetAutocompleteCache retrieves cached autocomplete results from Redis
@@ -356,6 +635,57 @@ func SetAutocompleteCache(ctx context.Context, query string, data string) error
 	return nil
 }
 
+// ==================== Last Logged Cache Functions ====================
+
+// LastLoggedCacheKey generates the Redis key for a user's last logged date
+func LastLoggedCacheKey(userID uint) string {
+	return fmt.Sprintf("%s%d", constants.LastLoggedCachePrefix, userID)
+}
+
+// GetLastLoggedCache retrieves a user's cached last logged date
+// Returns empty string on cache miss (not an error)
+func GetLastLoggedCache(ctx context.Context, userID uint) (string, error) {
+	if client == nil {
+		return "", nil
+	}
+
+	value, err := client.Get(ctx, LastLoggedCacheKey(userID)).Result()
+	if err == goredis.Nil {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to get last logged cache: %w", err)
+	}
+
+	return value, nil
+}
+
+// SetLastLoggedCache stores a user's last logged date (or constants.LastLoggedNone)
+func SetLastLoggedCache(ctx context.Context, userID uint, value string) error {
+	if client == nil {
+		return nil
+	}
+
+	if err := client.Set(ctx, LastLoggedCacheKey(userID), value, constants.LastLoggedCacheTTL).Err(); err != nil {
+		return fmt.Errorf("failed to set last logged cache: %w", err)
+	}
+
+	return nil
+}
+
+// InvalidateLastLoggedCache removes a user's cached last logged date
+func InvalidateLastLoggedCache(ctx context.Context, userID uint) error {
+	if client == nil {
+		return nil
+	}
+
+	if err := client.Del(ctx, LastLoggedCacheKey(userID)).Err(); err != nil {
+		return fmt.Errorf("failed to invalidate last logged cache: %w", err)
+	}
+
+	return nil
+}
+
 // ==================== Story Likes Cache Functions ====================
 
 const (
@@ -396,6 +726,39 @@ func GetStoryLikeCount(ctx context.Context, photoID uint) (int64, error) {
 	return value, nil
 }
 
+// GetStoryLikeCounts retrieves cached like counts for several photos in one MGET.
+// Photos missing from the cache are absent from the returned map.
+func GetStoryLikeCounts(ctx context.Context, photoIDs []uint) (map[uint]int64, error) {
+	counts := make(map[uint]int64, len(photoIDs))
+	if client == nil || len(photoIDs) == 0 {
+		return counts, nil // Redis not available, skip cache
+	}
+
+	keys := make([]string, len(photoIDs))
+	for i, id := range photoIDs {
+		keys[i] = StoryLikeCountCacheKey(id)
+	}
+
+	values, err := client.MGet(ctx, keys...).Result()
+	if err != nil {
+		return counts, fmt.Errorf("failed to get story like count cache: %w", err)
+	}
+
+	for i, value := range values {
+		str, ok := value.(string)
+		if !ok {
+			continue // Cache miss
+		}
+		count, err := strconv.ParseInt(str, 10, 64)
+		if err != nil {
+			continue
+		}
+		counts[photoIDs[i]] = count
+	}
+
+	return counts, nil
+}
+
 // SetStoryLikeCount stores like count in Redis cache
 func SetStoryLikeCount(ctx context.Context, photoID uint, count int64) error {
 	if client == nil {
@@ -525,6 +888,8 @@ func InvalidateStoryLikedByUser(ctx context.Context, photoID, userID uint) error
 const (
 	// TrendingCacheTTL is the cache duration for trending users (5 minutes)
 	TrendingCacheTTL = 5 * time.Minute
+	// TrendingActivitiesCacheKey holds the platform-wide trending activity types
+	TrendingActivitiesCacheKey = "trending:activities"
 )
 
 // GetTrendingCache retrieves cached trending users from Redis
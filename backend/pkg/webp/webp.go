@@ -0,0 +1,497 @@
+// Package webp implements a lossless WebP (VP8L) encoder in pure Go, so story images can
+// be served as WebP without cgo. It applies the subtract-green and predictor transforms and
+// Huffman-codes the residuals; it does not use backward references or a color cache.
+package webp
+
+import (
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/draw"
+	"io"
+	"sort"
+)
+
+const (
+	// MaxDimension is the largest width or height a VP8L bitstream can describe
+	MaxDimension = 1 << 14
+
+	vp8lSignature = 0x2f
+
+	transformPredictor     = 0
+	transformSubtractGreen = 2
+
+	// predictorBits is the log2 tile size of the predictor transform (16x16 tiles)
+	predictorBits = 4
+	// numPredictors is the number of predictor modes a tile can pick from
+	numPredictors = 14
+
+	numLiteralCodes  = 256
+	numLengthCodes   = 24
+	numDistanceCodes = 40
+
+	maxCodeLength           = 15
+	maxCodeLengthCodeLength = 7
+	numCodeLengthCodes      = 19
+)
+
+// codeLengthCodeOrder is the order code length code lengths are written in (VP8L spec 3.7.2.1.2)
+var codeLengthCodeOrder = [numCodeLengthCodes]int{17, 18, 0, 1, 2, 3, 4, 5, 16, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15}
+
+// Encode writes img to w as a lossless WebP image
+func Encode(w io.Writer, img image.Image) error {
+	bounds := img.Bounds()
+	width, height := bounds.Dx(), bounds.Dy()
+	if width < 1 || height < 1 || width > MaxDimension || height > MaxDimension {
+		return fmt.Errorf("webp: invalid image size %dx%d", width, height)
+	}
+
+	nrgba, ok := img.(*image.NRGBA)
+	if !ok || nrgba.Stride != 4*width || nrgba.Rect.Min != (image.Point{}) {
+		nrgba = image.NewNRGBA(image.Rect(0, 0, width, height))
+		draw.Draw(nrgba, nrgba.Rect, img, bounds.Min, draw.Src)
+	}
+	pix := make([]byte, len(nrgba.Pix))
+	copy(pix, nrgba.Pix)
+
+	bw := &bitWriter{}
+	bw.writeBits(vp8lSignature, 8)
+	bw.writeBits(uint32(width-1), 14)
+	bw.writeBits(uint32(height-1), 14)
+	if hasAlpha(pix) {
+		bw.writeBits(1, 1)
+	} else {
+		bw.writeBits(0, 1)
+	}
+	bw.writeBits(0, 3) // version
+
+	// The decoder inverts transforms in reverse order, so they are applied in the order written
+	bw.writeBits(1, 1)
+	bw.writeBits(transformSubtractGreen, 2)
+	subtractGreen(pix)
+
+	bw.writeBits(1, 1)
+	bw.writeBits(transformPredictor, 2)
+	bw.writeBits(predictorBits-2, 3)
+	modes := applyPredictor(pix, width, height)
+	encodePixels(bw, modes, false)
+
+	bw.writeBits(0, 1) // no more transforms
+	encodePixels(bw, pix, true)
+
+	payload := bw.bytes()
+	chunkSize := len(payload)
+	padding := chunkSize & 1
+
+	header := make([]byte, 20)
+	copy(header[0:4], "RIFF")
+	binary.LittleEndian.PutUint32(header[4:8], uint32(4+8+chunkSize+padding))
+	copy(header[8:12], "WEBP")
+	copy(header[12:16], "VP8L")
+	binary.LittleEndian.PutUint32(header[16:20], uint32(chunkSize))
+
+	if _, err := w.Write(header); err != nil {
+		return err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return err
+	}
+	if padding == 1 {
+		if _, err := w.Write([]byte{0}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hasAlpha reports whether any pixel is not fully opaque
+func hasAlpha(pix []byte) bool {
+	for p := 3; p < len(pix); p += 4 {
+		if pix[p] != 0xff {
+			return true
+		}
+	}
+	return false
+}
+
+// subtractGreen replaces red and blue with their difference from green
+func subtractGreen(pix []byte) {
+	for p := 0; p < len(pix); p += 4 {
+		pix[p+0] -= pix[p+1]
+		pix[p+2] -= pix[p+1]
+	}
+}
+
+// applyPredictor picks a prediction mode per tile, replaces pix with the residuals and
+// returns the mode sub-image (the mode is stored in the green channel)
+func applyPredictor(pix []byte, width, height int) []byte {
+	tileSize := 1 << predictorBits
+	tilesX := (width + tileSize - 1) >> predictorBits
+	tilesY := (height + tileSize - 1) >> predictorBits
+	modes := make([]byte, 4*tilesX*tilesY)
+
+	// Residuals are computed against the original pixels, which the decoder has already
+	// reconstructed when it predicts each pixel
+	orig := make([]byte, len(pix))
+	copy(orig, pix)
+
+	for ty := 0; ty < tilesY; ty++ {
+		for tx := 0; tx < tilesX; tx++ {
+			x0, y0 := tx*tileSize, ty*tileSize
+			x1, y1 := min(x0+tileSize, width), min(y0+tileSize, height)
+
+			best, bestCost := 0, -1
+			for mode := 0; mode < numPredictors; mode++ {
+				cost := 0
+				for y := y0; y < y1; y++ {
+					for x := x0; x < x1; x++ {
+						pred := predict(orig, width, x, y, mode)
+						p := 4 * (y*width + x)
+						for c := 0; c < 4; c++ {
+							cost += absInt(int(int8(orig[p+c] - pred[c])))
+						}
+					}
+				}
+				if bestCost < 0 || cost < bestCost {
+					best, bestCost = mode, cost
+				}
+			}
+			modes[4*(ty*tilesX+tx)+1] = byte(best)
+		}
+	}
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			mode := int(modes[4*((y>>predictorBits)*tilesX+(x>>predictorBits))+1])
+			pred := predict(orig, width, x, y, mode)
+			p := 4 * (y*width + x)
+			for c := 0; c < 4; c++ {
+				pix[p+c] = orig[p+c] - pred[c]
+			}
+		}
+	}
+	return modes
+}
+
+// predict returns the RGBA prediction for pixel (x, y). The first pixel predicts opaque
+// black, the rest of the top row predicts L and the left column predicts T. For the
+// rightmost column, TR is the first pixel of the current row, which the flat index gives.
+func predict(pix []byte, width, x, y, mode int) [4]byte {
+	p := 4 * (y*width + x)
+	switch {
+	case x == 0 && y == 0:
+		return [4]byte{0, 0, 0, 0xff}
+	case y == 0:
+		mode = 1
+	case x == 0:
+		mode = 2
+	}
+
+	var l, t, tl, tr [4]byte
+	if x > 0 {
+		copy(l[:], pix[p-4:p])
+	}
+	if y > 0 {
+		top := p - 4*width
+		copy(t[:], pix[top:top+4])
+		if x > 0 {
+			copy(tl[:], pix[top-4:top])
+		}
+		copy(tr[:], pix[top+4:top+8])
+	}
+
+	var out [4]byte
+	switch mode {
+	case 0:
+		return [4]byte{0, 0, 0, 0xff}
+	case 1:
+		return l
+	case 2:
+		return t
+	case 3:
+		return tr
+	case 4:
+		return tl
+	case 11:
+		return selectPredictor(l, t, tl)
+	}
+	for c := 0; c < 4; c++ {
+		switch mode {
+		case 5:
+			out[c] = avg2(avg2(l[c], tr[c]), t[c])
+		case 6:
+			out[c] = avg2(l[c], tl[c])
+		case 7:
+			out[c] = avg2(l[c], t[c])
+		case 8:
+			out[c] = avg2(tl[c], t[c])
+		case 9:
+			out[c] = avg2(t[c], tr[c])
+		case 10:
+			out[c] = avg2(avg2(l[c], tl[c]), avg2(t[c], tr[c]))
+		case 12:
+			out[c] = clamp(int(l[c]) + int(t[c]) - int(tl[c]))
+		case 13:
+			a := int(avg2(l[c], t[c]))
+			out[c] = clamp(a + (a-int(tl[c]))/2)
+		}
+	}
+	return out
+}
+
+// selectPredictor returns whichever of L and T is closer to the gradient estimate L+T-TL
+func selectPredictor(l, t, tl [4]byte) [4]byte {
+	distL, distT := 0, 0
+	for c := 0; c < 4; c++ {
+		distL += absInt(int(tl[c]) - int(t[c]))
+		distT += absInt(int(tl[c]) - int(l[c]))
+	}
+	if distL < distT {
+		return l
+	}
+	return t
+}
+
+func avg2(a, b byte) byte {
+	return byte((int(a) + int(b)) / 2)
+}
+
+func clamp(v int) byte {
+	return byte(max(0, min(255, v)))
+}
+
+func absInt(v int) int {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// encodePixels writes an entropy-coded image: no color cache, a single prefix code group
+// (the meta prefix bit only exists for the main image), then every pixel as literals
+func encodePixels(bw *bitWriter, pix []byte, mainImage bool) {
+	bw.writeBits(0, 1) // no color cache
+	if mainImage {
+		bw.writeBits(0, 1) // no meta prefix codes
+	}
+
+	// Literal channels in the order pixels are written: green, red, blue, alpha
+	channels := [4]int{1, 0, 2, 3}
+	var histograms [4][]int
+	for i, c := range channels {
+		size := numLiteralCodes
+		if i == 0 {
+			size += numLengthCodes
+		}
+		histograms[i] = make([]int, size)
+		for p := c; p < len(pix); p += 4 {
+			histograms[i][pix[p]]++
+		}
+	}
+
+	var codes [4]prefixCode
+	for i := range channels {
+		codes[i] = writePrefixCode(bw, histograms[i])
+	}
+	writePrefixCode(bw, make([]int, numDistanceCodes)) // unused: no backward references
+
+	for p := 0; p < len(pix); p += 4 {
+		for i, c := range channels {
+			codes[i].write(bw, int(pix[p+c]))
+		}
+	}
+}
+
+// prefixCode holds the canonical Huffman code for each symbol
+type prefixCode struct {
+	lengths []int
+	codes   []uint32
+}
+
+func (pc prefixCode) write(bw *bitWriter, symbol int) {
+	// Codes are read from the stream most significant bit first
+	for i := pc.lengths[symbol] - 1; i >= 0; i-- {
+		bw.writeBits((pc.codes[symbol]>>uint(i))&1, 1)
+	}
+}
+
+// writePrefixCode writes the prefix code for histogram and returns it for encoding symbols
+func writePrefixCode(bw *bitWriter, histogram []int) prefixCode {
+	var used []int
+	for symbol, count := range histogram {
+		if count > 0 {
+			used = append(used, symbol)
+		}
+	}
+
+	// Simple code: one or two symbols below 256, each written with 1 or 8 bits
+	if len(used) <= 2 && (len(used) == 0 || used[len(used)-1] < numLiteralCodes) {
+		if len(used) == 0 {
+			used = []int{0}
+		}
+		bw.writeBits(1, 1)
+		bw.writeBits(uint32(len(used)-1), 1)
+		if used[0] < 2 {
+			bw.writeBits(0, 1)
+			bw.writeBits(uint32(used[0]), 1)
+		} else {
+			bw.writeBits(1, 1)
+			bw.writeBits(uint32(used[0]), 8)
+		}
+		if len(used) == 2 {
+			bw.writeBits(uint32(used[1]), 8)
+		}
+
+		lengths := make([]int, len(histogram))
+		if len(used) == 2 {
+			lengths[used[0]], lengths[used[1]] = 1, 1
+		}
+		return prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+	}
+
+	lengths := codeLengths(histogram, maxCodeLength)
+
+	// The code lengths are themselves Huffman-coded with the code length code
+	codeLengthHistogram := make([]int, numCodeLengthCodes)
+	for _, length := range lengths {
+		codeLengthHistogram[length]++
+	}
+	clLengths := codeLengths(codeLengthHistogram, maxCodeLengthCodeLength)
+	clCode := prefixCode{lengths: clLengths, codes: canonicalCodes(clLengths)}
+
+	bw.writeBits(0, 1)                    // normal code
+	bw.writeBits(numCodeLengthCodes-4, 4) // write all 19 code length code lengths
+	for _, symbol := range codeLengthCodeOrder {
+		bw.writeBits(uint32(clLengths[symbol]), 3)
+	}
+	bw.writeBits(0, 1) // code lengths cover the whole alphabet
+	for _, length := range lengths {
+		clCode.write(bw, length)
+	}
+
+	return prefixCode{lengths: lengths, codes: canonicalCodes(lengths)}
+}
+
+// codeLengths builds Huffman code lengths no longer than maxLength for histogram. At
+// least two symbols always get a code, so the tree is complete for every decoder.
+func codeLengths(histogram []int, maxLength int) []int {
+	counts := make([]int, len(histogram))
+	copy(counts, histogram)
+
+	used := 0
+	for _, count := range counts {
+		if count > 0 {
+			used++
+		}
+	}
+	for symbol := 0; used < 2 && symbol < len(counts); symbol++ {
+		if counts[symbol] == 0 {
+			counts[symbol] = 1
+			used++
+		}
+	}
+
+	for {
+		lengths := huffmanLengths(counts)
+		longest := 0
+		for _, length := range lengths {
+			longest = max(longest, length)
+		}
+		if longest <= maxLength {
+			return lengths
+		}
+		// Flatten the distribution until the tree is shallow enough
+		for symbol, count := range counts {
+			if count > 0 {
+				counts[symbol] = max(1, count>>1)
+			}
+		}
+	}
+}
+
+// huffmanLengths returns unrestricted Huffman code lengths for the nonzero counts
+func huffmanLengths(counts []int) []int {
+	type node struct {
+		count       int
+		symbol      int // -1 for internal nodes
+		left, right int
+	}
+	var nodes []node
+	var queue []int
+	for symbol, count := range counts {
+		if count > 0 {
+			nodes = append(nodes, node{count: count, symbol: symbol, left: -1, right: -1})
+			queue = append(queue, len(nodes)-1)
+		}
+	}
+
+	for len(queue) > 1 {
+		sort.SliceStable(queue, func(i, j int) bool { return nodes[queue[i]].count < nodes[queue[j]].count })
+		a, b := queue[0], queue[1]
+		nodes = append(nodes, node{count: nodes[a].count + nodes[b].count, symbol: -1, left: a, right: b})
+		queue = append(queue[2:], len(nodes)-1)
+	}
+
+	lengths := make([]int, len(counts))
+	var walk func(n, depth int)
+	walk = func(n, depth int) {
+		if nodes[n].symbol >= 0 {
+			lengths[nodes[n].symbol] = depth
+			return
+		}
+		walk(nodes[n].left, depth+1)
+		walk(nodes[n].right, depth+1)
+	}
+	walk(queue[0], 0)
+	return lengths
+}
+
+// canonicalCodes assigns canonical codes: shorter codes first, then by symbol value
+func canonicalCodes(lengths []int) []uint32 {
+	var lengthCounts [maxCodeLength + 1]uint32
+	for _, length := range lengths {
+		lengthCounts[length]++
+	}
+	lengthCounts[0] = 0
+
+	var next [maxCodeLength + 1]uint32
+	code := uint32(0)
+	for length := 1; length <= maxCodeLength; length++ {
+		code = (code + lengthCounts[length-1]) << 1
+		next[length] = code
+	}
+
+	codes := make([]uint32, len(lengths))
+	for symbol, length := range lengths {
+		if length > 0 {
+			codes[symbol] = next[length]
+			next[length]++
+		}
+	}
+	return codes
+}
+
+// bitWriter packs bits least significant first, as VP8L reads them
+type bitWriter struct {
+	buf   []byte
+	acc   uint64
+	nbits uint
+}
+
+func (b *bitWriter) writeBits(value uint32, n uint) {
+	b.acc |= uint64(value&(1<<n-1)) << b.nbits
+	b.nbits += n
+	for b.nbits >= 8 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc >>= 8
+		b.nbits -= 8
+	}
+}
+
+func (b *bitWriter) bytes() []byte {
+	if b.nbits > 0 {
+		b.buf = append(b.buf, byte(b.acc))
+		b.acc, b.nbits = 0, 0
+	}
+	return b.buf
+}
@@ -0,0 +1,84 @@
+package webp
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"testing"
+
+	xwebp "golang.org/x/image/webp"
+)
+
+func TestEncodeRoundTrip(t *testing.T) {
+	gradient := func(w, h int, alpha bool) image.Image {
+		img := image.NewNRGBA(image.Rect(0, 0, w, h))
+		for y := 0; y < h; y++ {
+			for x := 0; x < w; x++ {
+				a := uint8(255)
+				if alpha {
+					a = uint8(x * 7)
+				}
+				img.SetNRGBA(x, y, color.NRGBA{uint8(x * 3), uint8(y * 5), uint8((x + y) * 11), a})
+			}
+		}
+		return img
+	}
+	noise := image.NewNRGBA(image.Rect(0, 0, 37, 29))
+	seed := uint32(1)
+	for i := range noise.Pix {
+		seed = seed*1664525 + 1013904223
+		noise.Pix[i] = uint8(seed >> 24)
+	}
+	solid := image.NewRGBA(image.Rect(0, 0, 20, 20))
+	for i := range solid.Pix {
+		solid.Pix[i] = 0x80
+	}
+
+	tests := []struct {
+		name string
+		img  image.Image
+	}{
+		{"single pixel", gradient(1, 1, false)},
+		{"single column", gradient(1, 40, false)},
+		{"single row", gradient(40, 1, false)},
+		{"opaque gradient", gradient(67, 45, false)},
+		{"translucent gradient", gradient(33, 50, true)},
+		{"noise", noise},
+		{"solid premultiplied", solid},
+		{"offset sub-image", gradient(50, 50, false).(*image.NRGBA).SubImage(image.Rect(10, 5, 41, 30))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Encode(&buf, tt.img); err != nil {
+				t.Fatalf("Encode: %v", err)
+			}
+			got, err := xwebp.Decode(&buf)
+			if err != nil {
+				t.Fatalf("Decode: %v", err)
+			}
+
+			b := tt.img.Bounds()
+			if got.Bounds().Dx() != b.Dx() || got.Bounds().Dy() != b.Dy() {
+				t.Fatalf("decoded size = %v, want %dx%d", got.Bounds().Size(), b.Dx(), b.Dy())
+			}
+			for y := 0; y < b.Dy(); y++ {
+				for x := 0; x < b.Dx(); x++ {
+					want := color.NRGBAModel.Convert(tt.img.At(b.Min.X+x, b.Min.Y+y))
+					have := color.NRGBAModel.Convert(got.At(x, y))
+					if want != have {
+						t.Fatalf("pixel (%d,%d) = %v, want %v", x, y, have, want)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEncodeRejectsOversizedImages(t *testing.T) {
+	img := image.NewNRGBA(image.Rect(0, 0, MaxDimension+1, 1))
+	if err := Encode(&bytes.Buffer{}, img); err == nil {
+		t.Error("Encode accepted an image wider than MaxDimension")
+	}
+}
@@ -43,7 +43,7 @@ export const useActivityData = ({
   const [activityNotes, setActivityNotes] = useState<Record<string, string>>({});
   const [loading, setLoading] = useState(true);
 
-  // Fetch badges for private accounts (badges are always public)
+  // Fetch badges for private accounts; the API only returns them to approved followers
   const fetchBadgesForPrivateAccount = useCallback(
     async (username: string) => {
       try {
@@ -96,14 +96,14 @@ export const useActivityData = ({
         }
       } else if (res.error_code === 'ACCOUNT_PRIVATE') {
         onPrivateAccount(true);
-        // Fetch badges for private accounts (badges are always public)
+        // Fetch badges for private accounts; the API only returns them to approved followers
         fetchBadgesForPrivateAccount(targetUsername);
       }
     } catch (err: unknown) {
       // Check if it's a private account error
       if (err instanceof ApiError && err.errorCode === 'ACCOUNT_PRIVATE') {
         onPrivateAccount(true);
-        // Fetch badges for private accounts (badges are always public)
+        // Fetch badges for private accounts; the API only returns them to approved followers
         fetchBadgesForPrivateAccount(targetUsername);
       } else {
         console.error('[useActivityData] Failed to fetch activities', err);
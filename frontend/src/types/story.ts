@@ -43,6 +43,8 @@ export interface UserStoryGroup {
   profile_pic_thumb?: string;
   photos: ActivityPhoto[];
   has_unseen: boolean;
+  all_viewed: boolean;
+  viewed_count: number;
 }
 
 // Photo viewer info
@@ -61,6 +63,7 @@ export interface PhotoLiker {
   profile_pic?: string;
   profile_pic_thumb?: string;
   liked_at: string;
+  emoji: string;
 }
 
 // Photo interaction (combined view + like)
@@ -72,6 +75,7 @@ export interface PhotoInteraction {
   interaction_type: 'view' | 'like' | 'both';
   viewed_at?: string;
   liked_at?: string;
+  emoji?: string; // Reaction, present when the user liked the photo
 }
 
 // API response types
@@ -104,6 +108,8 @@ export interface GetPhotoLikeStatusResponse {
   success: boolean;
   liked: boolean;
   like_count: number;
+  reaction: string; // Current user's reaction emoji, '' if none
+  reactions: Record<string, number>; // Reaction counts by emoji
   error?: string;
 }
 